@@ -0,0 +1,294 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const fs = require("fs");
+const path = require("path");
+const { getErrorMessage } = require("./error_helpers.cjs");
+
+const BUDGET_REPORT_PATH = "/tmp/gh-aw/budget.json";
+const BUDGET_WATCHER_PID_PATH = "/tmp/gh-aw/budget-watcher.pid";
+const BUDGET_STOP_PATH = "/tmp/gh-aw/budget-stopped.json";
+const BUDGET_EXCEEDED_CLASSIFICATION = "budget_exceeded";
+
+/**
+ * @typedef {Object} BudgetUsage
+ * @property {number} tokens - Total tokens (input + output + cache)
+ * @property {number} cost_usd - Estimated cost in US dollars
+ * @property {number} turns - Number of agent turns
+ */
+
+/**
+ * @typedef {Object} BudgetLimits
+ * @property {number} [max-tokens] - Maximum total tokens
+ * @property {number} [max-cost-usd] - Maximum estimated cost in US dollars
+ * @property {number} [max-turns] - Maximum number of agent turns
+ */
+
+/**
+ * Parses a positive number from an environment variable
+ * @param {string | undefined} value - Environment variable value
+ * @returns {number} The parsed number, or 0 if unset or invalid
+ */
+function parseLimit(value) {
+  const parsed = Number(value);
+  return Number.isFinite(parsed) && parsed > 0 ? parsed : 0;
+}
+
+/**
+ * Reads the budget limits from the environment
+ * @returns {BudgetLimits} Limits that are set
+ */
+function readLimits() {
+  /** @type {BudgetLimits} */
+  const limits = {};
+  const maxTokens = parseLimit(process.env.GH_AW_BUDGET_MAX_TOKENS);
+  const maxCost = parseLimit(process.env.GH_AW_BUDGET_MAX_COST_USD);
+  const maxTurns = parseLimit(process.env.GH_AW_BUDGET_MAX_TURNS);
+  if (maxTokens) limits["max-tokens"] = maxTokens;
+  if (maxCost) limits["max-cost-usd"] = maxCost;
+  if (maxTurns) limits["max-turns"] = maxTurns;
+  return limits;
+}
+
+/**
+ * Sums the tokens in a usage block (Claude and OpenAI formats).
+ * Mirrors ExtractJSONTokenUsage in pkg/workflow/metrics.go.
+ * @param {any} usage - Usage object
+ * @returns {number} Total tokens
+ */
+function sumUsageTokens(usage) {
+  if (!usage || typeof usage !== "object") {
+    return 0;
+  }
+  const input = Number(usage.input_tokens || usage.prompt_tokens || 0);
+  const output = Number(usage.output_tokens || usage.completion_tokens || 0);
+  const cacheCreation = Number(usage.cache_creation_input_tokens || 0);
+  const cacheRead = Number(usage.cache_read_input_tokens || 0);
+  const total = input + output + cacheCreation + cacheRead;
+  if (total > 0) {
+    return total;
+  }
+  return Number(usage.total_tokens || usage.tokens || 0);
+}
+
+/**
+ * Parses a log line as a JSON object, tolerating prefixes such as timestamps
+ * @param {string} line - Log line
+ * @returns {any} Parsed object or null
+ */
+function parseJSONLine(line) {
+  const start = line.indexOf("{");
+  const end = line.lastIndexOf("}");
+  if (start === -1 || end <= start) {
+    return null;
+  }
+  try {
+    return JSON.parse(line.slice(start, end + 1));
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Extracts token, cost, and turn usage from agent logs of any engine.
+ * A final "result" entry (Claude and Copilot session logs) carries the run totals and wins;
+ * otherwise usage blocks and Codex "tokens used" lines are summed.
+ * @param {string} content - Agent log content
+ * @returns {BudgetUsage} Measured usage
+ */
+function extractUsage(content) {
+  let summedTokens = 0;
+  let summedCost = 0;
+  let assistantTurns = 0;
+  /** @type {BudgetUsage | null} */
+  let resultUsage = null;
+
+  for (const rawLine of content.split("\n")) {
+    const line = rawLine.trim();
+    if (!line) {
+      continue;
+    }
+
+    const codexTokens = line.match(/tokens\s+used[:\s]+(\d+)/i) || line.match(/total_tokens:\s*(\d+)/);
+    if (codexTokens && !line.startsWith("{")) {
+      summedTokens += parseInt(codexTokens[1], 10);
+      continue;
+    }
+
+    const entry = parseJSONLine(line);
+    if (!entry || typeof entry !== "object") {
+      continue;
+    }
+
+    if (entry.type === "result") {
+      resultUsage = {
+        tokens: sumUsageTokens(entry.usage),
+        cost_usd: Number(entry.total_cost_usd || 0),
+        turns: Number(entry.num_turns || 0),
+      };
+      continue;
+    }
+
+    if (entry.type === "assistant") {
+      assistantTurns++;
+    }
+    // Claude stream-json carries the usage of each assistant message in message.usage
+    summedTokens += sumUsageTokens(entry.usage || (entry.message && entry.message.usage));
+    summedCost += Number(entry.total_cost_usd || entry.cost || 0);
+  }
+
+  if (resultUsage) {
+    return resultUsage;
+  }
+  return { tokens: summedTokens, cost_usd: summedCost, turns: assistantTurns };
+}
+
+/**
+ * Reads the agent log, concatenating .log/.txt/.jsonl files when the path is a directory
+ * @param {string} logPath - Agent log file or directory
+ * @returns {string} Log content (empty if not found)
+ */
+function readAgentLog(logPath) {
+  if (!logPath || !fs.existsSync(logPath)) {
+    return "";
+  }
+  if (!fs.statSync(logPath).isDirectory()) {
+    return fs.readFileSync(logPath, "utf8");
+  }
+  return fs
+    .readdirSync(logPath)
+    .filter(file => file.endsWith(".log") || file.endsWith(".txt") || file.endsWith(".jsonl"))
+    .sort()
+    .map(file => fs.readFileSync(path.join(logPath, file), "utf8"))
+    .join("\n");
+}
+
+/**
+ * Compares usage against the limits
+ * @param {BudgetLimits} limits - Budget limits
+ * @param {BudgetUsage} usage - Measured usage
+ * @returns {string[]} Names of the exceeded limits
+ */
+function evaluateBudget(limits, usage) {
+  const exceeded = [];
+  if (limits["max-tokens"] && usage.tokens > limits["max-tokens"]) exceeded.push("max-tokens");
+  if (limits["max-cost-usd"] && usage.cost_usd > limits["max-cost-usd"]) exceeded.push("max-cost-usd");
+  if (limits["max-turns"] && usage.turns > limits["max-turns"]) exceeded.push("max-turns");
+  return exceeded;
+}
+
+/**
+ * Formats one limit for messages and the step summary
+ * @param {string} name - Limit name
+ * @param {BudgetUsage} usage - Measured usage
+ * @param {BudgetLimits} limits - Budget limits
+ * @returns {string} e.g. "max-tokens: 120000 / 100000"
+ */
+function formatLimit(name, usage, limits) {
+  switch (name) {
+    case "max-tokens":
+      return `max-tokens: ${usage.tokens} / ${limits["max-tokens"]}`;
+    case "max-cost-usd":
+      return `max-cost-usd: $${usage.cost_usd.toFixed(4)} / $${limits["max-cost-usd"]}`;
+    default:
+      return `max-turns: ${usage.turns} / ${limits["max-turns"]}`;
+  }
+}
+
+/**
+ * Stops the budget watcher started before the agent ran and returns what it recorded
+ * @param {string} pidPath - File holding the watcher PID
+ * @param {string} stopPath - File the watcher writes when it stops the agent
+ * @returns {{usage: BudgetUsage, exceeded: string[], stopped: boolean} | null} Stop record, or null if the watcher did not stop the agent
+ */
+function stopBudgetWatcher(pidPath, stopPath) {
+  if (fs.existsSync(pidPath)) {
+    const pid = parseInt(fs.readFileSync(pidPath, "utf8"), 10);
+    if (pid > 0) {
+      try {
+        process.kill(pid, "SIGTERM");
+      } catch {
+        // The watcher already exited after stopping the agent
+      }
+    }
+  }
+  if (!fs.existsSync(stopPath)) {
+    return null;
+  }
+  try {
+    return JSON.parse(fs.readFileSync(stopPath, "utf8"));
+  } catch (error) {
+    core.warning(`Failed to read budget watcher record: ${getErrorMessage(error)}`);
+    return null;
+  }
+}
+
+/**
+ * Checks the agent's usage against the frontmatter budget. When the budget is exceeded the
+ * safe outputs are discarded and the step fails with the budget_exceeded classification.
+ */
+async function main() {
+  const limits = readLimits();
+  if (Object.keys(limits).length === 0) {
+    core.info("No budget limits configured");
+    return;
+  }
+
+  const watcherRecord = stopBudgetWatcher(BUDGET_WATCHER_PID_PATH, BUDGET_STOP_PATH);
+
+  let usage;
+  try {
+    const content = readAgentLog(process.env.GH_AW_AGENT_OUTPUT || "");
+    if (!content) {
+      core.warning(`Agent log not found at ${process.env.GH_AW_AGENT_OUTPUT}; budget usage cannot be determined`);
+    }
+    usage = extractUsage(content);
+  } catch (error) {
+    core.warning(`Failed to read agent log for budget enforcement: ${getErrorMessage(error)}`);
+    usage = { tokens: 0, cost_usd: 0, turns: 0 };
+  }
+
+  let exceeded = evaluateBudget(limits, usage);
+  if (exceeded.length === 0 && watcherRecord && watcherRecord.exceeded.length > 0) {
+    // The agent log was cut short when the watcher stopped the agent; keep what the watcher measured
+    usage = watcherRecord.usage;
+    exceeded = watcherRecord.exceeded;
+  }
+  const stoppedEarly = Boolean(watcherRecord && watcherRecord.stopped);
+  const report = { limits, usage, exceeded, stopped_early: stoppedEarly };
+
+  fs.mkdirSync(path.dirname(BUDGET_REPORT_PATH), { recursive: true });
+  fs.writeFileSync(BUDGET_REPORT_PATH, JSON.stringify(report, null, 2));
+
+  const lines = Object.keys(limits).map(name => `${exceeded.includes(name) ? "❌" : "✅"} ${formatLimit(name, usage, limits)}`);
+  if (stoppedEarly) {
+    lines.push("⏹️ The budget watcher stopped the agent during the run");
+  }
+  core.info(`Budget usage:\n${lines.join("\n")}`);
+  await core.summary.addRaw(`### Agent budget\n\n${lines.map(line => `- ${line}`).join("\n")}\n`).write();
+
+  core.setOutput("budget_exceeded", exceeded.length > 0 ? "true" : "false");
+  if (exceeded.length === 0) {
+    return;
+  }
+
+  const safeOutputsPath = process.env.GH_AW_SAFE_OUTPUTS;
+  if (safeOutputsPath && fs.existsSync(safeOutputsPath)) {
+    fs.writeFileSync(safeOutputsPath, "");
+    core.info(`Discarded safe outputs in ${safeOutputsPath} because the run exceeded its budget`);
+  }
+
+  const details = exceeded.map(name => formatLimit(name, usage, limits)).join(", ");
+  core.setFailed(`Agent exceeded its budget (${BUDGET_EXCEEDED_CLASSIFICATION}): ${details}`);
+}
+
+module.exports = {
+  main,
+  extractUsage,
+  evaluateBudget,
+  readAgentLog,
+  readLimits,
+  stopBudgetWatcher,
+  BUDGET_WATCHER_PID_PATH,
+  BUDGET_STOP_PATH,
+};
@@ -0,0 +1,136 @@
+// @ts-check
+
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+import os from "os";
+
+const { main, extractUsage, evaluateBudget, readAgentLog, stopBudgetWatcher } = require("./check_agent_budget.cjs");
+
+describe("check_agent_budget", () => {
+  let mockCore;
+  let originalEnv;
+  let tempDir;
+
+  const claudeLog = [
+    JSON.stringify({ type: "system", subtype: "init", tools: ["Bash"] }),
+    JSON.stringify({ type: "assistant", message: { content: [{ type: "text", text: "hi" }] } }),
+    JSON.stringify({
+      type: "result",
+      num_turns: 7,
+      total_cost_usd: 0.42,
+      usage: { input_tokens: 1000, output_tokens: 200, cache_creation_input_tokens: 50, cache_read_input_tokens: 250 },
+    }),
+  ].join("\n");
+
+  beforeEach(() => {
+    originalEnv = { ...process.env };
+    tempDir = fs.mkdtempSync(path.join(os.tmpdir(), "agent-budget-test-"));
+    mockCore = {
+      info: vi.fn(),
+      warning: vi.fn(),
+      setOutput: vi.fn(),
+      setFailed: vi.fn(),
+      summary: { addRaw: vi.fn().mockReturnThis(), write: vi.fn().mockResolvedValue(undefined) },
+    };
+    global.core = mockCore;
+  });
+
+  afterEach(() => {
+    process.env = originalEnv;
+    fs.rmSync(tempDir, { recursive: true, force: true });
+    delete global.core;
+  });
+
+  describe("extractUsage", () => {
+    it("uses the totals from a result entry", () => {
+      expect(extractUsage(claudeLog)).toEqual({ tokens: 1500, cost_usd: 0.42, turns: 7 });
+    });
+
+    it("sums usage blocks when there is no result entry", () => {
+      const log = ['2025-01-01T00:00:00Z {"usage":{"prompt_tokens":100,"completion_tokens":20}}', '{"type":"assistant","usage":{"input_tokens":5,"output_tokens":5}}'].join("\n");
+      expect(extractUsage(log)).toEqual({ tokens: 130, cost_usd: 0, turns: 1 });
+    });
+
+    it("sums Claude assistant message usage while the agent is still running", () => {
+      const log = [JSON.stringify({ type: "assistant", message: { usage: { input_tokens: 100, output_tokens: 50 } } }), JSON.stringify({ type: "assistant", message: { usage: { input_tokens: 200, output_tokens: 50 } } })].join("\n");
+      expect(extractUsage(log)).toEqual({ tokens: 400, cost_usd: 0, turns: 2 });
+    });
+
+    it("sums Codex token usage lines", () => {
+      expect(extractUsage("[2025-01-01] tokens used: 1200\nthinking\n[2025-01-01] tokens used: 300").tokens).toBe(1500);
+    });
+  });
+
+  it("reports each exceeded limit", () => {
+    const usage = { tokens: 1500, cost_usd: 0.42, turns: 7 };
+    expect(evaluateBudget({ "max-tokens": 2000, "max-cost-usd": 0.5, "max-turns": 10 }, usage)).toEqual([]);
+    expect(evaluateBudget({ "max-tokens": 1000, "max-cost-usd": 0.4, "max-turns": 7 }, usage)).toEqual(["max-tokens", "max-cost-usd"]);
+  });
+
+  it("concatenates log files from a directory", () => {
+    fs.writeFileSync(path.join(tempDir, "b.log"), "second");
+    fs.writeFileSync(path.join(tempDir, "a.log"), "first");
+    fs.writeFileSync(path.join(tempDir, "notes.md"), "ignored");
+    expect(readAgentLog(tempDir)).toBe("first\nsecond");
+  });
+
+  describe("stopBudgetWatcher", () => {
+    it("returns null when the watcher did not stop the agent", () => {
+      expect(stopBudgetWatcher(path.join(tempDir, "watcher.pid"), path.join(tempDir, "stopped.json"))).toBeNull();
+    });
+
+    it("signals the watcher and returns its stop record", () => {
+      const pidPath = path.join(tempDir, "watcher.pid");
+      const stopPath = path.join(tempDir, "stopped.json");
+      const record = { usage: { tokens: 5000, cost_usd: 0, turns: 3 }, exceeded: ["max-tokens"], stopped: true };
+      fs.writeFileSync(pidPath, "4242");
+      fs.writeFileSync(stopPath, JSON.stringify(record));
+      const killSpy = vi.spyOn(process, "kill").mockImplementation(() => true);
+
+      expect(stopBudgetWatcher(pidPath, stopPath)).toEqual(record);
+      expect(killSpy).toHaveBeenCalledWith(4242, "SIGTERM");
+      killSpy.mockRestore();
+    });
+  });
+
+  it("does nothing when no limits are configured", async () => {
+    delete process.env.GH_AW_BUDGET_MAX_TOKENS;
+    delete process.env.GH_AW_BUDGET_MAX_COST_USD;
+    delete process.env.GH_AW_BUDGET_MAX_TURNS;
+
+    await main();
+
+    expect(mockCore.setOutput).not.toHaveBeenCalled();
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+  });
+
+  it("passes a run within budget", async () => {
+    const logPath = path.join(tempDir, "agent-stdio.log");
+    fs.writeFileSync(logPath, claudeLog);
+    process.env.GH_AW_AGENT_OUTPUT = logPath;
+    process.env.GH_AW_BUDGET_MAX_TOKENS = "5000";
+
+    await main();
+
+    expect(mockCore.setOutput).toHaveBeenCalledWith("budget_exceeded", "false");
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+  });
+
+  it("fails and discards safe outputs when the budget is exceeded", async () => {
+    const logPath = path.join(tempDir, "agent-stdio.log");
+    const safeOutputsPath = path.join(tempDir, "outputs.jsonl");
+    fs.writeFileSync(logPath, claudeLog);
+    fs.writeFileSync(safeOutputsPath, '{"type":"create_issue","title":"x"}\n');
+    process.env.GH_AW_AGENT_OUTPUT = logPath;
+    process.env.GH_AW_SAFE_OUTPUTS = safeOutputsPath;
+    process.env.GH_AW_BUDGET_MAX_COST_USD = "0.25";
+
+    await main();
+
+    expect(mockCore.setOutput).toHaveBeenCalledWith("budget_exceeded", "true");
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining("budget_exceeded"));
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining("max-cost-usd: $0.4200 / $0.25"));
+    expect(fs.readFileSync(safeOutputsPath, "utf8")).toBe("");
+  });
+});
@@ -0,0 +1,235 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const { loadAgentOutput } = require("./load_agent_output.cjs");
+const { generateStagedPreview } = require("./staged_preview.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+const { resolveTarget } = require("./safe_output_helpers.cjs");
+const { sanitizeContent } = require("./sanitize_content.cjs");
+
+/**
+ * Number of annotations GitHub accepts in a single check run request
+ */
+const ANNOTATIONS_PER_REQUEST = 50;
+
+/**
+ * Maximum length of an annotation message accepted by GitHub
+ */
+const MAX_ANNOTATION_MESSAGE_LENGTH = 64 * 1024;
+
+/**
+ * Annotation levels accepted by GitHub
+ */
+const ANNOTATION_LEVELS = ["notice", "warning", "failure"];
+
+/**
+ * Normalizes an agent annotation into the shape expected by the checks API
+ * @param {any} annotation - Annotation from the agent output
+ * @returns {{success: true, annotation: any} | {success: false, error: string}} Normalized annotation or error
+ */
+function normalizeAnnotation(annotation) {
+  if (!annotation || typeof annotation !== "object") {
+    return { success: false, error: "annotation must be an object" };
+  }
+  if (!annotation.path || typeof annotation.path !== "string") {
+    return { success: false, error: 'annotation is missing "path"' };
+  }
+
+  const startLine = parseInt(String(annotation.start_line), 10);
+  if (isNaN(startLine) || startLine <= 0) {
+    return { success: false, error: `invalid start_line ${annotation.start_line} for ${annotation.path}` };
+  }
+  const endLine = annotation.end_line === undefined || annotation.end_line === null ? startLine : parseInt(String(annotation.end_line), 10);
+  if (isNaN(endLine) || endLine < startLine) {
+    return { success: false, error: `invalid end_line ${annotation.end_line} for ${annotation.path} (must be >= start_line ${startLine})` };
+  }
+
+  if (!ANNOTATION_LEVELS.includes(annotation.annotation_level)) {
+    return { success: false, error: `invalid annotation_level ${annotation.annotation_level} for ${annotation.path} (must be one of ${ANNOTATION_LEVELS.join(", ")})` };
+  }
+  if (!annotation.message || typeof annotation.message !== "string") {
+    return { success: false, error: `annotation for ${annotation.path} is missing "message"` };
+  }
+
+  /** @type {any} */
+  const normalized = {
+    path: annotation.path.replace(/^\.?\//, ""),
+    start_line: startLine,
+    end_line: endLine,
+    annotation_level: annotation.annotation_level,
+    message: sanitizeContent(annotation.message, MAX_ANNOTATION_MESSAGE_LENGTH),
+  };
+  if (annotation.title && typeof annotation.title === "string") {
+    normalized.title = sanitizeContent(annotation.title, 255);
+  }
+  return { success: true, annotation: normalized };
+}
+
+/**
+ * Resolves the head commit SHA of the pull request that receives the check run
+ * @param {any} item - The create_check_run item
+ * @param {string} targetConfig - Target configuration ("triggering", "*", or explicit number)
+ * @returns {Promise<{success: true, sha: string, pullRequestNumber: number} | {success: false, error: string, shouldFail: boolean}>}
+ */
+async function resolveHeadSha(item, targetConfig) {
+  const targetResult = resolveTarget({
+    targetConfig,
+    item,
+    context,
+    itemType: "check run",
+    supportsPR: false,
+  });
+  if (!targetResult.success) {
+    return targetResult;
+  }
+  const pullRequestNumber = targetResult.number;
+
+  // The triggering pull request carries its head commit in the payload
+  const payloadPR = context.payload.pull_request;
+  if (payloadPR && payloadPR.number === pullRequestNumber && payloadPR.head?.sha) {
+    return { success: true, sha: payloadPR.head.sha, pullRequestNumber };
+  }
+
+  try {
+    const { data: pullRequest } = await github.rest.pulls.get({
+      owner: context.repo.owner,
+      repo: context.repo.repo,
+      pull_number: pullRequestNumber,
+    });
+    return { success: true, sha: pullRequest.head.sha, pullRequestNumber };
+  } catch (error) {
+    return { success: false, error: `Failed to fetch pull request #${pullRequestNumber}: ${getErrorMessage(error)}`, shouldFail: true };
+  }
+}
+
+async function main() {
+  const result = loadAgentOutput();
+  if (!result.success) {
+    return;
+  }
+
+  const checkRunItems = result.items.filter(/** @param {any} item */ item => item.type === "create_check_run");
+  if (checkRunItems.length === 0) {
+    core.info("No create_check_run items found in agent output");
+    return;
+  }
+
+  core.info(`Found ${checkRunItems.length} create_check_run item(s)`);
+
+  const checkName = process.env.GH_AW_CHECK_RUN_NAME || "agentic workflow";
+  const maxCount = parseInt(process.env.GH_AW_CHECK_RUN_MAX || "1", 10);
+  const maxAnnotations = parseInt(process.env.GH_AW_CHECK_RUN_MAX_ANNOTATIONS || String(ANNOTATIONS_PER_REQUEST), 10);
+  const allowedConclusions = (process.env.GH_AW_CHECK_RUN_CONCLUSIONS || "")
+    .split(",")
+    .map(c => c.trim())
+    .filter(c => c);
+  const targetConfig = process.env.GH_AW_CHECK_RUN_TARGET?.trim() || "triggering";
+  core.info(`Check run name: ${checkName}, max: ${maxCount}, max annotations: ${maxAnnotations}, target: ${targetConfig}`);
+  core.info(`Allowed conclusions: ${allowedConclusions.join(", ")}`);
+
+  let items = checkRunItems;
+  if (maxCount > 0 && items.length > maxCount) {
+    core.warning(`Found ${items.length} create_check_run items, but max is ${maxCount}. Processing first ${maxCount}.`);
+    items = items.slice(0, maxCount);
+  }
+
+  // Validate every item before creating anything, so a bad item does not leave a partial set of checks
+  const checkRuns = [];
+  for (const item of items) {
+    if (allowedConclusions.length > 0 && !allowedConclusions.includes(item.conclusion)) {
+      core.setFailed(`Conclusion "${item.conclusion}" is not allowed. Allowed conclusions: ${allowedConclusions.join(", ")}`);
+      return;
+    }
+
+    const rawAnnotations = Array.isArray(item.annotations) ? item.annotations : [];
+    const annotations = [];
+    for (const rawAnnotation of rawAnnotations) {
+      const normalized = normalizeAnnotation(rawAnnotation);
+      if (!normalized.success) {
+        core.warning(`Skipping check run annotation: ${normalized.error}`);
+        continue;
+      }
+      annotations.push(normalized.annotation);
+    }
+    if (annotations.length > maxAnnotations) {
+      core.warning(`Check run has ${annotations.length} annotations, but max-annotations is ${maxAnnotations}. Keeping first ${maxAnnotations}.`);
+      annotations.splice(maxAnnotations);
+    }
+
+    checkRuns.push({ item, annotations });
+  }
+
+  if (process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true") {
+    await generateStagedPreview({
+      title: "Create Check Runs",
+      description: "The following check runs would be created if staged mode was disabled:",
+      items: checkRuns,
+      renderItem: ({ item, annotations }) => {
+        let content = `### ${checkName}\n\n`;
+        content += `**Conclusion:** ${item.conclusion}\n\n`;
+        content += `**Title:** ${item.title || checkName}\n\n`;
+        content += `**Summary:**\n\n${item.summary}\n\n`;
+        if (annotations.length > 0) {
+          content += `**Annotations:**\n\n`;
+          for (const annotation of annotations) {
+            content += `- \`${annotation.path}:${annotation.start_line}\` (${annotation.annotation_level}): ${annotation.message}\n`;
+          }
+          content += "\n";
+        }
+        return content;
+      },
+    });
+    return;
+  }
+
+  for (const { item, annotations } of checkRuns) {
+    const headResult = await resolveHeadSha(item, targetConfig);
+    if (!headResult.success) {
+      if (headResult.shouldFail) {
+        core.setFailed(headResult.error);
+      } else {
+        core.info(headResult.error);
+      }
+      return;
+    }
+
+    const output = {
+      title: item.title || checkName,
+      summary: item.summary,
+      ...(item.text ? { text: item.text } : {}),
+    };
+
+    try {
+      // GitHub accepts a limited number of annotations per request, so the rest are added by updates
+      const { data: checkRun } = await github.rest.checks.create({
+        owner: context.repo.owner,
+        repo: context.repo.repo,
+        name: checkName,
+        head_sha: headResult.sha,
+        status: "completed",
+        conclusion: item.conclusion,
+        output: { ...output, annotations: annotations.slice(0, ANNOTATIONS_PER_REQUEST) },
+      });
+      for (let i = ANNOTATIONS_PER_REQUEST; i < annotations.length; i += ANNOTATIONS_PER_REQUEST) {
+        await github.rest.checks.update({
+          owner: context.repo.owner,
+          repo: context.repo.repo,
+          check_run_id: checkRun.id,
+          output: { ...output, annotations: annotations.slice(i, i + ANNOTATIONS_PER_REQUEST) },
+        });
+      }
+
+      core.info(`Created check run ${checkName} (${item.conclusion}) with ${annotations.length} annotation(s) on PR #${headResult.pullRequestNumber}: ${checkRun.html_url}`);
+      core.setOutput("check_run_id", String(checkRun.id));
+      core.setOutput("check_run_url", checkRun.html_url || "");
+      await core.summary
+        .addRaw(`## Check Run\n\nCreated check run [${checkName}](${checkRun.html_url}) with conclusion **${item.conclusion}** and ${annotations.length} annotation(s) on PR #${headResult.pullRequestNumber}.\n`)
+        .write();
+    } catch (error) {
+      core.setFailed(`Failed to create check run: ${getErrorMessage(error)}`);
+      return;
+    }
+  }
+}
+
+module.exports = { main, normalizeAnnotation };
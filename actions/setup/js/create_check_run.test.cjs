@@ -0,0 +1,170 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+
+const mockCore = {
+  debug: vi.fn(),
+  info: vi.fn(),
+  warning: vi.fn(),
+  error: vi.fn(),
+  setFailed: vi.fn(),
+  setOutput: vi.fn(),
+  summary: {
+    addRaw: vi.fn().mockReturnThis(),
+    write: vi.fn().mockResolvedValue(),
+  },
+};
+
+const mockContext = {
+  repo: {
+    owner: "test-owner",
+    repo: "test-repo",
+  },
+};
+
+const mockGithub = {
+  rest: {
+    checks: {
+      create: vi.fn(),
+      update: vi.fn(),
+    },
+    pulls: {
+      get: vi.fn(),
+    },
+  },
+};
+
+global.core = mockCore;
+global.context = mockContext;
+global.github = mockGithub;
+
+const { main, normalizeAnnotation } = require("./create_check_run.cjs");
+
+/**
+ * @param {number} count
+ * @returns {any[]}
+ */
+function makeAnnotations(count) {
+  return Array.from({ length: count }, (_, i) => ({ path: "src/app.js", start_line: i + 1, annotation_level: "warning", message: `Finding ${i + 1}` }));
+}
+
+describe("create_check_run", () => {
+  let tempFilePath;
+
+  const setAgentOutput = data => {
+    tempFilePath = path.join("/tmp", `test_agent_output_${Date.now()}_${Math.random().toString(36).slice(2)}.json`);
+    fs.writeFileSync(tempFilePath, JSON.stringify(data));
+    process.env.GH_AW_AGENT_OUTPUT = tempFilePath;
+  };
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockGithub.rest.checks.create.mockResolvedValue({ data: { id: 99, html_url: "https://github.com/test-owner/test-repo/runs/99" } });
+    mockGithub.rest.checks.update.mockResolvedValue({ data: {} });
+    mockGithub.rest.pulls.get.mockResolvedValue({ data: { head: { sha: "fetched-sha" } } });
+
+    process.env.GH_AW_CHECK_RUN_NAME = "AI Review";
+    process.env.GH_AW_CHECK_RUN_MAX = "1";
+    process.env.GH_AW_CHECK_RUN_MAX_ANNOTATIONS = "50";
+    process.env.GH_AW_CHECK_RUN_CONCLUSIONS = "success,failure,neutral";
+    delete process.env.GH_AW_CHECK_RUN_TARGET;
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+
+    mockContext.eventName = "pull_request";
+    mockContext.payload = { pull_request: { number: 7, head: { sha: "head-sha" } } };
+  });
+
+  afterEach(() => {
+    if (tempFilePath && fs.existsSync(tempFilePath)) {
+      fs.unlinkSync(tempFilePath);
+    }
+  });
+
+  it("should create a completed check run on the triggering pull request head", async () => {
+    setAgentOutput({
+      items: [{ type: "create_check_run", conclusion: "failure", title: "2 issues", summary: "Found issues", annotations: makeAnnotations(2) }],
+    });
+
+    await main();
+
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+    expect(mockGithub.rest.pulls.get).not.toHaveBeenCalled();
+    expect(mockGithub.rest.checks.create).toHaveBeenCalledWith(
+      expect.objectContaining({
+        name: "AI Review",
+        head_sha: "head-sha",
+        status: "completed",
+        conclusion: "failure",
+        output: expect.objectContaining({ title: "2 issues", summary: "Found issues" }),
+      })
+    );
+    expect(mockGithub.rest.checks.create.mock.calls[0][0].output.annotations).toHaveLength(2);
+    expect(mockCore.setOutput).toHaveBeenCalledWith("check_run_id", "99");
+  });
+
+  it("should reject conclusions that are not allowed", async () => {
+    setAgentOutput({ items: [{ type: "create_check_run", conclusion: "skipped", summary: "Nothing to do" }] });
+
+    await main();
+
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining('Conclusion "skipped" is not allowed'));
+    expect(mockGithub.rest.checks.create).not.toHaveBeenCalled();
+  });
+
+  it("should cap annotations and send them in batches", async () => {
+    process.env.GH_AW_CHECK_RUN_MAX_ANNOTATIONS = "120";
+    setAgentOutput({ items: [{ type: "create_check_run", conclusion: "neutral", summary: "Many findings", annotations: makeAnnotations(130) }] });
+
+    await main();
+
+    expect(mockCore.warning).toHaveBeenCalledWith(expect.stringContaining("Keeping first 120"));
+    expect(mockGithub.rest.checks.create.mock.calls[0][0].output.annotations).toHaveLength(50);
+    expect(mockGithub.rest.checks.update).toHaveBeenCalledTimes(2);
+    expect(mockGithub.rest.checks.update.mock.calls[1][0].output.annotations).toHaveLength(20);
+  });
+
+  it("should fetch the head commit when targeting any pull request", async () => {
+    process.env.GH_AW_CHECK_RUN_TARGET = "*";
+    setAgentOutput({ items: [{ type: "create_check_run", conclusion: "success", summary: "Looks good", pull_request_number: 12 }] });
+
+    await main();
+
+    expect(mockGithub.rest.pulls.get).toHaveBeenCalledWith(expect.objectContaining({ pull_number: 12 }));
+    expect(mockGithub.rest.checks.create).toHaveBeenCalledWith(expect.objectContaining({ head_sha: "fetched-sha" }));
+  });
+
+  it("should skip outside pull request context when targeting the triggering pull request", async () => {
+    mockContext.eventName = "push";
+    mockContext.payload = {};
+    setAgentOutput({ items: [{ type: "create_check_run", conclusion: "success", summary: "Looks good" }] });
+
+    await main();
+
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+    expect(mockGithub.rest.checks.create).not.toHaveBeenCalled();
+  });
+
+  it("should write a preview instead of creating the check run in staged mode", async () => {
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+    setAgentOutput({ items: [{ type: "create_check_run", conclusion: "success", summary: "Looks good", annotations: makeAnnotations(1) }] });
+
+    await main();
+
+    expect(mockGithub.rest.checks.create).not.toHaveBeenCalled();
+    const summary = mockCore.summary.addRaw.mock.calls[0][0];
+    expect(summary).toContain("🎭 Staged Mode: Create Check Runs Preview");
+    expect(summary).toContain("`src/app.js:1` (warning): Finding 1");
+  });
+
+  describe("normalizeAnnotation", () => {
+    it("should default end_line to start_line", () => {
+      const result = normalizeAnnotation({ path: "./src/app.js", start_line: "3", annotation_level: "notice", message: "Note" });
+      expect(result).toEqual({ success: true, annotation: { path: "src/app.js", start_line: 3, end_line: 3, annotation_level: "notice", message: "Note" } });
+    });
+
+    it("should reject invalid ranges and levels", () => {
+      expect(normalizeAnnotation({ path: "a.js", start_line: 5, end_line: 2, annotation_level: "notice", message: "x" }).success).toBe(false);
+      expect(normalizeAnnotation({ path: "a.js", start_line: 1, annotation_level: "error", message: "x" }).success).toBe(false);
+    });
+  });
+});
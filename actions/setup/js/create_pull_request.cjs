@@ -15,6 +15,7 @@ const { replaceTemporaryIdReferences } = require("./temporary_id.cjs");
 const { resolveTargetRepoConfig, resolveAndValidateRepo } = require("./repo_helpers.cjs");
 const { createExpirationLine, generateFooterWithExpiration } = require("./ephemerals.cjs");
 const { generateWorkflowIdMarker } = require("./generate_footer.cjs");
+const { getPatchPathPolicy, hasPatchPathPolicy, checkPatchPaths, reportPatchPathViolations } = require("./patch_path_policy.cjs");
 
 /**
  * @typedef {import('./types/handler-factory').HandlerFactoryFunction} HandlerFactoryFunction
@@ -73,6 +74,7 @@ async function main(config = {}) {
   const { defaultTargetRepo, allowedRepos } = resolveTargetRepoConfig(config);
   const includeFooter = config.footer !== false; // Default to true (include footer)
   const fallbackAsIssue = config.fallback_as_issue !== false; // Default to true (fallback enabled)
+  const pathPolicy = getPatchPathPolicy(config);
 
   // Environment validation - fail early if required variables are missing
   const workflowId = process.env.GH_AW_WORKFLOW_ID;
@@ -110,6 +112,12 @@ async function main(config = {}) {
   }
   core.info(`Max count: ${maxCount}`);
   core.info(`Max patch size: ${maxSizeKb} KB`);
+  if (pathPolicy.allowedPaths.length > 0) {
+    core.info(`Allowed paths: ${pathPolicy.allowedPaths.join(", ")}`);
+  }
+  if (pathPolicy.blockedPaths.length > 0) {
+    core.info(`Blocked paths: ${pathPolicy.blockedPaths.join(", ")}`);
+  }
 
   // Track how many items we've processed for max limit
   let processedCount = 0;
@@ -260,6 +268,31 @@ async function main(config = {}) {
       }
 
       core.info("Patch size validation passed");
+
+      // Validate the paths modified by the patch
+      if (hasPatchPathPolicy(pathPolicy)) {
+        const violations = checkPatchPaths(patchContent, pathPolicy);
+        if (violations.length > 0) {
+          const message = reportPatchPathViolations(HANDLER_TYPE, violations);
+
+          // If in staged mode, still show preview with error
+          if (isStaged) {
+            let summaryContent = "## 🎭 Staged Mode: Create Pull Request Preview\n\n";
+            summaryContent += "The following pull request would be created if staged mode was disabled:\n\n";
+            summaryContent += `**Status:** ❌ Patch path restrictions violated\n\n`;
+            summaryContent += `**Message:** ${message}\n\n`;
+
+            // Write to step summary
+            await core.summary.addRaw(summaryContent).write();
+            core.info("📝 Pull request creation preview written to step summary (patch path error)");
+            return { success: true, staged: true };
+          }
+
+          return { success: false, error: message };
+        }
+
+        core.info("Patch path validation passed");
+      }
     }
 
     if (isEmpty && !isStaged && !allowEmpty) {
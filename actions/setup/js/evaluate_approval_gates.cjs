@@ -0,0 +1,158 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const fs = require("fs");
+const { loadAgentOutput } = require("./load_agent_output.cjs");
+const { generateStagedPreview } = require("./staged_preview.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+const { extractPatchPaths } = require("./patch_path_policy.cjs");
+
+/**
+ * Patch produced by the agent for create_pull_request and push_to_pull_request_branch
+ */
+const PATCH_PATH = "/tmp/gh-aw/aw.patch";
+
+/**
+ * Matches a require-approval condition such as "files-changed > 20"
+ */
+const WHEN_PATTERN = /^\s*(items|files-changed|lines-changed)\s*(>=|<=|==|!=|>|<)\s*(\d+)\s*$/;
+
+/**
+ * Count the lines added and removed by a patch
+ * @param {string} patchContent - Patch content in git format-patch or git diff format
+ * @returns {number} Number of added plus removed lines
+ */
+function countChangedLines(patchContent) {
+  let count = 0;
+  let inHunk = false;
+  for (const line of patchContent.split("\n")) {
+    if (line.startsWith("diff --git ")) {
+      inHunk = false;
+    } else if (line.startsWith("@@")) {
+      inHunk = true;
+    } else if (inHunk && (line.startsWith("+") || line.startsWith("-"))) {
+      count++;
+    }
+  }
+  return count;
+}
+
+/**
+ * Compute the metrics a require-approval condition can refer to
+ * @param {string} type - Safe output type
+ * @param {number} items - Number of agent output items of the type
+ * @returns {{items: number, "files-changed": number, "lines-changed": number}}
+ */
+function computeMetrics(type, items) {
+  const metrics = { items, "files-changed": 0, "lines-changed": 0 };
+  if ((type === "create_pull_request" || type === "push_to_pull_request_branch") && fs.existsSync(PATCH_PATH)) {
+    const patchContent = fs.readFileSync(PATCH_PATH, "utf8");
+    metrics["files-changed"] = extractPatchPaths(patchContent).length;
+    metrics["lines-changed"] = countChangedLines(patchContent);
+  }
+  return metrics;
+}
+
+/**
+ * Evaluate a require-approval condition against the metrics of a safe output type
+ * @param {string} when - Condition such as "files-changed > 20"; empty means always
+ * @param {Record<string, number>} metrics - Metrics computed for the type
+ * @returns {{success: true, requiresApproval: boolean, reason: string} | {success: false, error: string}}
+ */
+function evaluateCondition(when, metrics) {
+  if (!when) {
+    return { success: true, requiresApproval: true, reason: "approval is always required" };
+  }
+  const match = when.match(WHEN_PATTERN);
+  if (!match) {
+    return { success: false, error: `Invalid require-approval condition "${when}"` };
+  }
+  const [, metric, operator, rawThreshold] = match;
+  const value = metrics[metric];
+  const threshold = parseInt(rawThreshold, 10);
+  /** @type {Record<string, (a: number, b: number) => boolean>} */
+  const operators = {
+    ">": (a, b) => a > b,
+    ">=": (a, b) => a >= b,
+    "<": (a, b) => a < b,
+    "<=": (a, b) => a <= b,
+    "==": (a, b) => a === b,
+    "!=": (a, b) => a !== b,
+  };
+  const requiresApproval = operators[operator](value, threshold);
+  return { success: true, requiresApproval, reason: `${when} (${metric} = ${value})` };
+}
+
+async function main() {
+  /** @type {Array<{type: string, environment: string, when: string}>} */
+  let gates;
+  try {
+    gates = JSON.parse(process.env.GH_AW_APPROVAL_GATES || "[]");
+  } catch (error) {
+    core.setFailed(`Invalid GH_AW_APPROVAL_GATES: ${getErrorMessage(error)}`);
+    return;
+  }
+
+  const result = loadAgentOutput();
+  const items = result.success ? result.items : [];
+  const isStaged = process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true";
+
+  const decisions = [];
+  for (const gate of gates) {
+    const count = items.filter(/** @param {any} item */ item => item.type === gate.type).length;
+    let requiresApproval = false;
+    let reason = "no items";
+    if (count > 0) {
+      const evaluation = evaluateCondition(gate.when, computeMetrics(gate.type, count));
+      if (!evaluation.success) {
+        core.setFailed(evaluation.error);
+        return;
+      }
+      requiresApproval = evaluation.requiresApproval;
+      reason = evaluation.reason;
+    }
+    core.info(`${gate.type}: ${count} item(s), ${requiresApproval ? `requires approval from environment ${gate.environment}` : "no approval required"} (${reason})`);
+    decisions.push({ ...gate, count, requiresApproval, reason });
+  }
+
+  if (isStaged) {
+    // Staged runs never wait for approval; the safe_outputs job previews the gated outputs
+    for (const decision of decisions) {
+      core.setOutput(`${decision.type}_requires_approval`, "false");
+    }
+    core.setOutput("awaiting_approval", "");
+
+    const gatedDecisions = decisions.filter(d => d.requiresApproval);
+    if (gatedDecisions.length > 0) {
+      await generateStagedPreview({
+        title: "Approval Gates",
+        description: "The following safe outputs would have waited for environment approval if staged mode was disabled:",
+        items: gatedDecisions,
+        renderItem: decision => {
+          let content = `### ${decision.type}\n\n`;
+          content += `**Environment:** ${decision.environment}\n\n`;
+          content += `**Items:** ${decision.count}\n\n`;
+          content += `**Reason:** ${decision.reason}\n\n`;
+          return content;
+        },
+      });
+    }
+    return;
+  }
+
+  const awaitingApproval = decisions.filter(d => d.requiresApproval).map(d => d.type);
+  for (const decision of decisions) {
+    core.setOutput(`${decision.type}_requires_approval`, decision.requiresApproval ? "true" : "false");
+  }
+  core.setOutput("awaiting_approval", awaitingApproval.join(","));
+
+  if (awaitingApproval.length > 0) {
+    let summary = "## Approval Gates\n\n| Safe output | Environment | Items | Reason |\n| --- | --- | --- | --- |\n";
+    for (const decision of decisions.filter(d => d.requiresApproval)) {
+      summary += `| ${decision.type} | ${decision.environment} | ${decision.count} | ${decision.reason} |\n`;
+    }
+    await core.summary.addRaw(summary).write();
+  }
+}
+
+module.exports = { main, countChangedLines, evaluateCondition };
@@ -0,0 +1,109 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+
+const mockCore = {
+  debug: vi.fn(),
+  info: vi.fn(),
+  warning: vi.fn(),
+  error: vi.fn(),
+  setFailed: vi.fn(),
+  setOutput: vi.fn(),
+  summary: {
+    addRaw: vi.fn().mockReturnThis(),
+    write: vi.fn().mockResolvedValue(),
+  },
+};
+
+global.core = mockCore;
+
+const { main, countChangedLines, evaluateCondition } = require("./evaluate_approval_gates.cjs");
+
+describe("evaluate_approval_gates", () => {
+  let tempFilePath;
+
+  const setAgentOutput = data => {
+    tempFilePath = path.join("/tmp", `test_agent_output_${Date.now()}_${Math.random().toString(36).slice(2)}.json`);
+    fs.writeFileSync(tempFilePath, JSON.stringify(data));
+    process.env.GH_AW_AGENT_OUTPUT = tempFilePath;
+  };
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+    process.env.GH_AW_APPROVAL_GATES = JSON.stringify([
+      { type: "create_issue", environment: "triage", when: "" },
+      { type: "add_comment", environment: "review", when: "items > 2" },
+    ]);
+  });
+
+  afterEach(() => {
+    if (tempFilePath && fs.existsSync(tempFilePath)) {
+      fs.unlinkSync(tempFilePath);
+    }
+  });
+
+  it("should require approval for gated types with matching items", async () => {
+    setAgentOutput({
+      items: [
+        { type: "create_issue", title: "Issue", body: "Body" },
+        { type: "add_comment", body: "Comment" },
+      ],
+    });
+
+    await main();
+
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+    expect(mockCore.setOutput).toHaveBeenCalledWith("create_issue_requires_approval", "true");
+    expect(mockCore.setOutput).toHaveBeenCalledWith("add_comment_requires_approval", "false");
+    expect(mockCore.setOutput).toHaveBeenCalledWith("awaiting_approval", "create_issue");
+    expect(mockCore.summary.addRaw.mock.calls[0][0]).toContain("| create_issue | triage | 1 | approval is always required |");
+  });
+
+  it("should not require approval when the agent produced no items of the type", async () => {
+    setAgentOutput({ items: [{ type: "add_comment", body: "Comment" }] });
+
+    await main();
+
+    expect(mockCore.setOutput).toHaveBeenCalledWith("create_issue_requires_approval", "false");
+    expect(mockCore.setOutput).toHaveBeenCalledWith("awaiting_approval", "");
+    expect(mockCore.summary.addRaw).not.toHaveBeenCalled();
+  });
+
+  it("should never wait for approval in staged mode", async () => {
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+    setAgentOutput({ items: [{ type: "create_issue", title: "Issue", body: "Body" }] });
+
+    await main();
+
+    expect(mockCore.setOutput).toHaveBeenCalledWith("create_issue_requires_approval", "false");
+    expect(mockCore.setOutput).toHaveBeenCalledWith("awaiting_approval", "");
+    expect(mockCore.summary.addRaw.mock.calls[0][0]).toContain("🎭 Staged Mode: Approval Gates Preview");
+  });
+
+  it("should fail on invalid gate configuration", async () => {
+    process.env.GH_AW_APPROVAL_GATES = "not json";
+
+    await main();
+
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining("Invalid GH_AW_APPROVAL_GATES"));
+  });
+
+  describe("evaluateCondition", () => {
+    it("should compare the metric against the threshold", () => {
+      const metrics = { items: 1, "files-changed": 25, "lines-changed": 40 };
+      expect(evaluateCondition("files-changed > 20", metrics)).toEqual({ success: true, requiresApproval: true, reason: "files-changed > 20 (files-changed = 25)" });
+      expect(evaluateCondition("lines-changed >= 500", metrics).requiresApproval).toBe(false);
+      expect(evaluateCondition("items == 1", metrics).requiresApproval).toBe(true);
+      expect(evaluateCondition("", metrics).requiresApproval).toBe(true);
+      expect(evaluateCondition("files > 1", metrics).success).toBe(false);
+    });
+  });
+
+  describe("countChangedLines", () => {
+    it("should count added and removed lines but not file headers", () => {
+      const patch = ["diff --git a/a.txt b/a.txt", "--- a/a.txt", "+++ b/a.txt", "@@ -1,2 +1,2 @@", " keep", "-old", "+new", "+extra"].join("\n");
+      expect(countChangedLines(patch)).toBe(3);
+    });
+  });
+});
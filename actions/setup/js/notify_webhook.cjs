@@ -0,0 +1,266 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const { loadAgentOutput } = require("./load_agent_output.cjs");
+const { generateStagedPreview } = require("./staged_preview.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+const { sanitizeContent } = require("./sanitize_content.cjs");
+const { redactSecrets, redactBuiltInPatterns } = require("./redact_secrets.cjs");
+
+/**
+ * Severities an agent may attach to a notification
+ */
+const SEVERITIES = ["info", "warning", "critical"];
+
+/**
+ * Hosts that may be reached over plain HTTP (local stand-ins used in tests)
+ */
+const LOOPBACK_HOSTS = ["localhost", "127.0.0.1", "[::1]", "::1"];
+
+/**
+ * Request timeout for a single webhook delivery
+ */
+const REQUEST_TIMEOUT_MS = 10000;
+
+/**
+ * Emoji shown next to each severity in chat payloads
+ */
+const SEVERITY_EMOJI = { info: "ℹ️", warning: "⚠️", critical: "🚨" };
+
+/**
+ * Checks whether a hostname matches an allowed-domains entry.
+ * Entries may carry a protocol prefix and a leading "*." wildcard that matches subdomains.
+ * @param {string} hostname - Hostname of the webhook URL
+ * @param {string} pattern - Allowed domain pattern
+ * @returns {boolean}
+ */
+function hostMatchesDomain(hostname, pattern) {
+  const domain = pattern
+    .trim()
+    .toLowerCase()
+    .replace(/^https?:\/\//, "");
+  if (!domain) {
+    return false;
+  }
+  const host = hostname.toLowerCase();
+  if (domain.startsWith("*.")) {
+    const suffix = domain.slice(1);
+    return host.endsWith(suffix) && host.length > suffix.length;
+  }
+  return host === domain;
+}
+
+/**
+ * Validates a webhook URL against the allowed domains
+ * @param {string} rawUrl - Webhook URL from the endpoint secret
+ * @param {string[]} allowedDomains - Allowed domain patterns
+ * @returns {{success: true, url: URL} | {success: false, error: string}}
+ */
+function validateWebhookURL(rawUrl, allowedDomains) {
+  let url;
+  try {
+    url = new URL(rawUrl);
+  } catch {
+    return { success: false, error: "webhook URL is not a valid URL" };
+  }
+  if (url.protocol !== "https:" && !(url.protocol === "http:" && LOOPBACK_HOSTS.includes(url.hostname))) {
+    return { success: false, error: `webhook URL must use https (got ${url.protocol})` };
+  }
+  if (!allowedDomains.some(domain => hostMatchesDomain(url.hostname, domain))) {
+    return { success: false, error: `webhook host ${url.hostname} is not in allowed-domains (${allowedDomains.join(", ")})` };
+  }
+  return { success: true, url };
+}
+
+/**
+ * Builds the request body for an endpoint format
+ * @param {string} format - Payload template: "slack", "teams" or "json"
+ * @param {{title: string, body: string, severity: string}} notification - Sanitized notification
+ * @param {{workflow: string, repository: string, runUrl: string}} run - Workflow run metadata
+ * @returns {any} JSON payload
+ */
+function buildPayload(format, notification, run) {
+  const { title, body, severity } = notification;
+  const emoji = SEVERITY_EMOJI[/** @type {keyof typeof SEVERITY_EMOJI} */ (severity)] || "";
+
+  switch (format) {
+    case "slack":
+      return {
+        text: `${emoji} ${title}`,
+        blocks: [
+          { type: "header", text: { type: "plain_text", text: `${emoji} ${title}`.slice(0, 150), emoji: true } },
+          { type: "section", text: { type: "mrkdwn", text: body.slice(0, 3000) } },
+          { type: "context", elements: [{ type: "mrkdwn", text: `*${severity}* · ${run.workflow} · <${run.runUrl}|${run.repository}>` }] },
+        ],
+      };
+    case "teams":
+      return {
+        type: "message",
+        attachments: [
+          {
+            contentType: "application/vnd.microsoft.card.adaptive",
+            content: {
+              $schema: "http://adaptivecards.io/schemas/adaptive-card.json",
+              type: "AdaptiveCard",
+              version: "1.4",
+              body: [
+                { type: "TextBlock", text: `${emoji} ${title}`, weight: "Bolder", size: "Medium", wrap: true, color: severity === "critical" ? "Attention" : severity === "warning" ? "Warning" : "Default" },
+                { type: "TextBlock", text: body, wrap: true },
+                {
+                  type: "FactSet",
+                  facts: [
+                    { title: "Severity", value: severity },
+                    { title: "Workflow", value: run.workflow },
+                    { title: "Repository", value: run.repository },
+                  ],
+                },
+              ],
+              actions: [{ type: "Action.OpenUrl", title: "View run", url: run.runUrl }],
+            },
+          },
+        ],
+      };
+    default:
+      return { title, body, severity, workflow: run.workflow, repository: run.repository, run_url: run.runUrl };
+  }
+}
+
+/**
+ * Removes webhook URLs and credential-looking strings from text before it leaves the job
+ * @param {string} text - Text to redact
+ * @param {string[]} secretValues - Webhook URLs to redact
+ * @returns {string}
+ */
+function redactText(text, secretValues) {
+  return redactBuiltInPatterns(redactSecrets(text, secretValues).content).content;
+}
+
+async function main() {
+  const result = loadAgentOutput();
+  if (!result.success) {
+    return;
+  }
+
+  const notifyItems = result.items.filter(/** @param {any} item */ item => item.type === "notify_webhook");
+  if (notifyItems.length === 0) {
+    core.info("No notify_webhook items found in agent output");
+    return;
+  }
+
+  core.info(`Found ${notifyItems.length} notify_webhook item(s)`);
+
+  /** @type {Record<string, {format: string, env: string}>} */
+  let endpoints;
+  try {
+    endpoints = JSON.parse(process.env.GH_AW_WEBHOOK_ENDPOINTS || "{}");
+  } catch (error) {
+    core.setFailed(`Invalid GH_AW_WEBHOOK_ENDPOINTS: ${getErrorMessage(error)}`);
+    return;
+  }
+  const endpointNames = Object.keys(endpoints);
+  const allowedDomains = (process.env.GH_AW_WEBHOOK_ALLOWED_DOMAINS || "")
+    .split(",")
+    .map(d => d.trim())
+    .filter(d => d);
+  const maxCount = parseInt(process.env.GH_AW_WEBHOOK_MAX || "1", 10);
+  core.info(`Endpoints: ${endpointNames.join(", ")}, allowed domains: ${allowedDomains.join(", ")}, max: ${maxCount}`);
+
+  if (endpointNames.length === 0) {
+    core.setFailed("No webhook endpoints are configured");
+    return;
+  }
+
+  let items = notifyItems;
+  if (maxCount > 0 && items.length > maxCount) {
+    core.warning(`Found ${items.length} notify_webhook items, but max is ${maxCount}. Processing first ${maxCount}.`);
+    items = items.slice(0, maxCount);
+  }
+
+  // Webhook URLs are secrets; they are redacted from anything this job posts or logs
+  const secretValues = endpointNames.map(name => process.env[endpoints[name].env] || "").filter(v => v);
+
+  // Validate every item before sending anything, so a bad item does not leave a partial set of notifications
+  const deliveries = [];
+  for (const item of items) {
+    const severity = item.severity || "info";
+    if (!SEVERITIES.includes(severity)) {
+      core.setFailed(`Invalid notification severity "${severity}". Allowed severities: ${SEVERITIES.join(", ")}`);
+      return;
+    }
+    if (item.endpoint && !endpoints[item.endpoint]) {
+      core.setFailed(`Unknown webhook endpoint "${item.endpoint}". Configured endpoints: ${endpointNames.join(", ")}`);
+      return;
+    }
+
+    const notification = {
+      title: redactText(sanitizeContent(item.title || "", 256), secretValues),
+      body: redactText(sanitizeContent(item.body || "", 10000), secretValues),
+      severity,
+    };
+    for (const name of item.endpoint ? [item.endpoint] : endpointNames) {
+      deliveries.push({ name, format: endpoints[name].format, env: endpoints[name].env, notification });
+    }
+  }
+
+  if (process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true") {
+    await generateStagedPreview({
+      title: "Webhook Notifications",
+      description: "The following notifications would be sent if staged mode was disabled:",
+      items: deliveries,
+      renderItem: ({ name, format, notification }) => {
+        let content = `### ${notification.title}\n\n`;
+        content += `**Endpoint:** ${name} (${format})\n\n`;
+        content += `**Severity:** ${notification.severity}\n\n`;
+        content += `${notification.body}\n\n`;
+        return content;
+      },
+    });
+    return;
+  }
+
+  const runUrl = `${process.env.GITHUB_SERVER_URL || "https://github.com"}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
+  const run = {
+    workflow: process.env.GH_AW_WORKFLOW_NAME || "Workflow",
+    repository: `${context.repo.owner}/${context.repo.repo}`,
+    runUrl,
+  };
+
+  let sent = 0;
+  for (const { name, format, env, notification } of deliveries) {
+    const rawUrl = process.env[env] || "";
+    if (!rawUrl) {
+      core.setFailed(`Webhook URL for endpoint "${name}" is empty; check that the secret is set`);
+      return;
+    }
+    const urlResult = validateWebhookURL(rawUrl, allowedDomains);
+    if (!urlResult.success) {
+      core.setFailed(`Endpoint "${name}": ${urlResult.error}`);
+      return;
+    }
+
+    try {
+      const response = await fetch(urlResult.url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(buildPayload(format, notification, run)),
+        signal: AbortSignal.timeout(REQUEST_TIMEOUT_MS),
+      });
+      if (!response.ok) {
+        const responseText = await response.text().catch(() => "");
+        core.setFailed(`Endpoint "${name}" returned HTTP ${response.status}: ${redactText(responseText.slice(0, 500), secretValues)}`);
+        return;
+      }
+    } catch (error) {
+      core.setFailed(`Failed to notify endpoint "${name}": ${redactText(getErrorMessage(error), secretValues)}`);
+      return;
+    }
+
+    sent++;
+    core.info(`Sent ${notification.severity} notification "${notification.title}" to endpoint ${name} (${format})`);
+  }
+
+  core.setOutput("notifications_sent", String(sent));
+  await core.summary.addRaw(`## Webhook Notifications\n\nSent ${sent} notification(s) to ${[...new Set(deliveries.map(d => d.name))].join(", ")}.\n`).write();
+}
+
+module.exports = { main, hostMatchesDomain, validateWebhookURL, buildPayload };
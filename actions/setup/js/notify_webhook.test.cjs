@@ -0,0 +1,202 @@
+import { describe, it, expect, beforeAll, afterAll, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import http from "http";
+import path from "path";
+
+const mockCore = {
+  debug: vi.fn(),
+  info: vi.fn(),
+  warning: vi.fn(),
+  error: vi.fn(),
+  setFailed: vi.fn(),
+  setOutput: vi.fn(),
+  summary: {
+    addRaw: vi.fn().mockReturnThis(),
+    write: vi.fn().mockResolvedValue(),
+  },
+};
+
+const mockContext = {
+  repo: {
+    owner: "test-owner",
+    repo: "test-repo",
+  },
+  runId: 1234,
+};
+
+global.core = mockCore;
+global.context = mockContext;
+
+const { main, hostMatchesDomain, validateWebhookURL, buildPayload } = require("./notify_webhook.cjs");
+
+describe("notify_webhook", () => {
+  /** @type {http.Server} */
+  let server;
+  /** @type {{url: string, body: any}[]} */
+  let requests;
+  let responseStatus;
+  let baseUrl;
+  let tempFilePath;
+
+  const setAgentOutput = data => {
+    tempFilePath = path.join("/tmp", `test_agent_output_${Date.now()}_${Math.random().toString(36).slice(2)}.json`);
+    fs.writeFileSync(tempFilePath, JSON.stringify(data));
+    process.env.GH_AW_AGENT_OUTPUT = tempFilePath;
+  };
+
+  beforeAll(async () => {
+    // Local stand-in for the chat webhook services
+    server = http.createServer((req, res) => {
+      let data = "";
+      req.on("data", chunk => (data += chunk));
+      req.on("end", () => {
+        requests.push({ url: req.url || "", body: JSON.parse(data) });
+        res.writeHead(responseStatus);
+        res.end(responseStatus === 200 ? "ok" : `bad request for ${baseUrl}${req.url}`);
+      });
+    });
+    await new Promise(resolve => server.listen(0, "127.0.0.1", () => resolve(undefined)));
+    const address = /** @type {import("net").AddressInfo} */ (server.address());
+    baseUrl = `http://127.0.0.1:${address.port}`;
+  });
+
+  afterAll(async () => {
+    await new Promise(resolve => server.close(() => resolve(undefined)));
+  });
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    requests = [];
+    responseStatus = 200;
+
+    process.env.GH_AW_WEBHOOK_ENDPOINTS = JSON.stringify({
+      ops: { format: "slack", env: "GH_AW_WEBHOOK_URL_OPS" },
+      oncall: { format: "teams", env: "GH_AW_WEBHOOK_URL_ONCALL" },
+    });
+    process.env.GH_AW_WEBHOOK_URL_OPS = `${baseUrl}/slack/T000/B000/secret-token`;
+    process.env.GH_AW_WEBHOOK_URL_ONCALL = `${baseUrl}/teams/secret-token`;
+    process.env.GH_AW_WEBHOOK_ALLOWED_DOMAINS = "127.0.0.1";
+    process.env.GH_AW_WEBHOOK_MAX = "3";
+    process.env.GH_AW_WORKFLOW_NAME = "Nightly";
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+  });
+
+  afterEach(() => {
+    if (tempFilePath && fs.existsSync(tempFilePath)) {
+      fs.unlinkSync(tempFilePath);
+    }
+  });
+
+  it("should post a Slack payload to the selected endpoint", async () => {
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Build failed", body: "The nightly build failed.", severity: "critical", endpoint: "ops" }] });
+
+    await main();
+
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+    expect(requests).toHaveLength(1);
+    expect(requests[0].url).toBe("/slack/T000/B000/secret-token");
+    expect(requests[0].body.text).toBe("🚨 Build failed");
+    expect(requests[0].body.blocks[1].text.text).toBe("The nightly build failed.");
+    expect(requests[0].body.blocks[2].elements[0].text).toContain("/test-owner/test-repo/actions/runs/1234");
+    expect(mockCore.setOutput).toHaveBeenCalledWith("notifications_sent", "1");
+  });
+
+  it("should notify every endpoint when no endpoint is given", async () => {
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Heads up", body: "Details" }] });
+
+    await main();
+
+    expect(requests.map(r => r.url).sort()).toEqual(["/slack/T000/B000/secret-token", "/teams/secret-token"]);
+    const teams = requests.find(r => r.url.startsWith("/teams")).body;
+    expect(teams.attachments[0].contentType).toBe("application/vnd.microsoft.card.adaptive");
+    expect(teams.attachments[0].content.body[2].facts).toContainEqual({ title: "Severity", value: "info" });
+  });
+
+  it("should redact webhook URLs from the notification text", async () => {
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Leak", body: `URL is ${baseUrl}/slack/T000/B000/secret-token`, endpoint: "ops" }] });
+
+    await main();
+
+    expect(requests).toHaveLength(1);
+    expect(JSON.stringify(requests[0].body)).not.toContain("secret-token");
+  });
+
+  it("should refuse hosts outside allowed-domains", async () => {
+    process.env.GH_AW_WEBHOOK_ALLOWED_DOMAINS = "hooks.slack.com";
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Hi", body: "There", endpoint: "ops" }] });
+
+    await main();
+
+    expect(requests).toHaveLength(0);
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining("not in allowed-domains"));
+  });
+
+  it("should fail on unknown endpoints before sending anything", async () => {
+    setAgentOutput({
+      items: [
+        { type: "notify_webhook", title: "One", body: "First", endpoint: "ops" },
+        { type: "notify_webhook", title: "Two", body: "Second", endpoint: "security" },
+      ],
+    });
+
+    await main();
+
+    expect(requests).toHaveLength(0);
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining('Unknown webhook endpoint "security"'));
+  });
+
+  it("should fail without leaking the URL when the endpoint rejects the request", async () => {
+    responseStatus = 400;
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Hi", body: "There", endpoint: "ops" }] });
+
+    await main();
+
+    const message = mockCore.setFailed.mock.calls[0][0];
+    expect(message).toContain("HTTP 400");
+    expect(message).not.toContain("secret-token");
+  });
+
+  it("should write a preview instead of posting in staged mode", async () => {
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+    setAgentOutput({ items: [{ type: "notify_webhook", title: "Build failed", body: "Details", severity: "warning", endpoint: "oncall" }] });
+
+    await main();
+
+    expect(requests).toHaveLength(0);
+    const summary = mockCore.summary.addRaw.mock.calls[0][0];
+    expect(summary).toContain("🎭 Staged Mode: Webhook Notifications Preview");
+    expect(summary).toContain("**Endpoint:** oncall (teams)");
+  });
+
+  describe("hostMatchesDomain", () => {
+    it("should match exact hosts and wildcard subdomains", () => {
+      expect(hostMatchesDomain("hooks.slack.com", "hooks.slack.com")).toBe(true);
+      expect(hostMatchesDomain("hooks.slack.com", "https://hooks.slack.com")).toBe(true);
+      expect(hostMatchesDomain("acme.webhook.office.com", "*.webhook.office.com")).toBe(true);
+      expect(hostMatchesDomain("webhook.office.com", "*.webhook.office.com")).toBe(false);
+      expect(hostMatchesDomain("evilslack.com", "slack.com")).toBe(false);
+    });
+  });
+
+  describe("validateWebhookURL", () => {
+    it("should require https for non-loopback hosts", () => {
+      expect(validateWebhookURL("http://hooks.slack.com/x", ["hooks.slack.com"]).success).toBe(false);
+      expect(validateWebhookURL("https://hooks.slack.com/x", ["hooks.slack.com"]).success).toBe(true);
+      expect(validateWebhookURL("not a url", ["hooks.slack.com"]).success).toBe(false);
+    });
+  });
+
+  describe("buildPayload", () => {
+    it("should build a raw JSON payload by default", () => {
+      const run = { workflow: "Nightly", repository: "o/r", runUrl: "https://github.com/o/r/actions/runs/1" };
+      expect(buildPayload("json", { title: "T", body: "B", severity: "info" }, run)).toEqual({
+        title: "T",
+        body: "B",
+        severity: "info",
+        workflow: "Nightly",
+        repository: "o/r",
+        run_url: "https://github.com/o/r/actions/runs/1",
+      });
+    });
+  });
+});
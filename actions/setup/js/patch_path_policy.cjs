@@ -0,0 +1,170 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const { globPatternToRegex } = require("./glob_pattern_helpers.cjs");
+
+/**
+ * @typedef {Object} PatchPathPolicy
+ * @property {string[]} allowedPaths - Globs the patch may touch (empty means any path)
+ * @property {string[]} blockedPaths - Globs the patch may never touch
+ * @property {string[]} protectedPaths - Built-in protected globs, touchable only when listed in allowedPaths without a wildcard
+ */
+
+/**
+ * @typedef {Object} PatchPathViolation
+ * @property {string} path - Path modified by the patch
+ * @property {string} reason - Why the path is rejected
+ */
+
+/**
+ * Build the patch path policy from a handler configuration
+ * @param {Object} config - Handler configuration with allowed_paths, blocked_paths and protected_paths
+ * @returns {PatchPathPolicy}
+ */
+function getPatchPathPolicy(config) {
+  /** @param {any} value */
+  const toList = value => (Array.isArray(value) ? value.map(String).filter(Boolean) : []);
+  return {
+    allowedPaths: toList(config.allowed_paths),
+    blockedPaths: toList(config.blocked_paths),
+    protectedPaths: toList(config.protected_paths),
+  };
+}
+
+/**
+ * Check whether a policy restricts any path
+ * @param {PatchPathPolicy} policy
+ * @returns {boolean}
+ */
+function hasPatchPathPolicy(policy) {
+  return policy.allowedPaths.length > 0 || policy.blockedPaths.length > 0 || policy.protectedPaths.length > 0;
+}
+
+/**
+ * Strip the quoting git applies to paths with special characters
+ * @param {string} path
+ * @returns {string}
+ */
+function unquoteGitPath(path) {
+  if (path.length >= 2 && path.startsWith('"') && path.endsWith('"')) {
+    return path.slice(1, -1).replace(/\\(["\\])/g, "$1");
+  }
+  return path;
+}
+
+/**
+ * Extract the paths modified by a patch in git format-patch or git diff format.
+ * Both the old and the new path of renames and copies are returned.
+ * @param {string} patchContent - Patch content
+ * @returns {string[]} Sorted unique paths
+ */
+function extractPatchPaths(patchContent) {
+  const paths = new Set();
+  for (const line of patchContent.split("\n")) {
+    if (line.startsWith("diff --git ")) {
+      // "a/<path> b/<path>" is ambiguous when the path contains " b/", but the two halves
+      // are identical unless the file is renamed, in which case the rename lines follow
+      const rest = line.slice("diff --git ".length);
+      const pathLength = (rest.length - "a/ b/".length) / 2;
+      if (Number.isInteger(pathLength) && pathLength > 0) {
+        const oldPath = unquoteGitPath(rest.slice(0, pathLength + 2));
+        const newPath = unquoteGitPath(rest.slice(pathLength + 3));
+        if (oldPath.startsWith("a/") && newPath.startsWith("b/") && oldPath.slice(2) === newPath.slice(2)) {
+          paths.add(oldPath.slice(2));
+        }
+      }
+      continue;
+    }
+
+    const renameMatch = line.match(/^(?:rename|copy) (?:from|to) (.+)$/);
+    if (renameMatch) {
+      paths.add(unquoteGitPath(renameMatch[1]));
+      continue;
+    }
+
+    const fileMatch = line.match(/^(?:---|\+\+\+) (.+?)\t?$/);
+    if (fileMatch) {
+      const path = unquoteGitPath(fileMatch[1]);
+      if (path.startsWith("a/") || path.startsWith("b/")) {
+        paths.add(path.slice(2));
+      }
+    }
+  }
+  return Array.from(paths).sort();
+}
+
+/**
+ * Find the first glob of a list that matches a path
+ * @param {string} path
+ * @param {string[]} patterns
+ * @returns {string | undefined}
+ */
+function findMatchingPattern(path, patterns) {
+  return patterns.find(pattern => globPatternToRegex(pattern).test(path));
+}
+
+/**
+ * Check whether allowed-paths lists a path explicitly, as an exact entry without a wildcard
+ * @param {string} path
+ * @param {string[]} patterns
+ * @returns {boolean}
+ */
+function isExplicitlyAllowed(path, patterns) {
+  return patterns.some(pattern => !pattern.includes("*") && pattern === path);
+}
+
+/**
+ * Check the paths modified by a patch against a policy.
+ * Blocked paths are always rejected, protected paths are rejected unless allowed-paths lists
+ * them exactly (a glob such as ** never unlocks them), and when allowed-paths is set every
+ * other path must match it.
+ * @param {string} patchContent - Patch content
+ * @param {PatchPathPolicy} policy
+ * @returns {PatchPathViolation[]}
+ */
+function checkPatchPaths(patchContent, policy) {
+  /** @type {PatchPathViolation[]} */
+  const violations = [];
+  for (const path of extractPatchPaths(patchContent)) {
+    const blocked = findMatchingPattern(path, policy.blockedPaths);
+    if (blocked) {
+      violations.push({ path, reason: `matches blocked-paths pattern ${blocked}` });
+      continue;
+    }
+
+    const allowed = findMatchingPattern(path, policy.allowedPaths);
+    const protectedPattern = findMatchingPattern(path, policy.protectedPaths);
+    if (protectedPattern && !isExplicitlyAllowed(path, policy.allowedPaths)) {
+      violations.push({ path, reason: `matches protected path ${protectedPattern}` });
+      continue;
+    }
+
+    if (policy.allowedPaths.length > 0 && !allowed) {
+      violations.push({ path, reason: "does not match allowed-paths" });
+    }
+  }
+  return violations;
+}
+
+/**
+ * Log the rejected paths of a patch and build the error message of the handler result.
+ * Each rejected path is logged on its own line so that `gh aw audit` can report it.
+ * @param {string} handlerType - Safe output type (e.g., "create_pull_request")
+ * @param {PatchPathViolation[]} violations
+ * @returns {string} Error message
+ */
+function reportPatchPathViolations(handlerType, violations) {
+  for (const violation of violations) {
+    core.warning(`Patch path rejected for ${handlerType}: ${violation.path} (${violation.reason})`);
+  }
+  const details = violations.map(violation => `${violation.path} (${violation.reason})`).join(", ");
+  return `Patch modifies ${violations.length} path(s) that are not allowed: ${details}`;
+}
+
+module.exports = {
+  getPatchPathPolicy,
+  hasPatchPathPolicy,
+  extractPatchPaths,
+  checkPatchPaths,
+  reportPatchPathViolations,
+};
@@ -0,0 +1,121 @@
+import { describe, it, expect, beforeEach, vi } from "vitest";
+import { getPatchPathPolicy, hasPatchPathPolicy, extractPatchPaths, checkPatchPaths, reportPatchPathViolations } from "./patch_path_policy.cjs";
+
+const patch = `From 1234567890abcdef Mon Sep 17 00:00:00 2001
+From: Agent <agent@example.com>
+Subject: [PATCH] Update docs
+
+---
+ docs/guide.md             | 2 +-
+ .github/workflows/ci.yml  | 1 +
+ 2 files changed, 2 insertions(+), 1 deletion(-)
+
+diff --git a/docs/guide.md b/docs/guide.md
+index 1111111..2222222 100644
+--- a/docs/guide.md
++++ b/docs/guide.md
+@@ -1 +1 @@
+-old
++new
+diff --git a/.github/workflows/ci.yml b/.github/workflows/ci.yml
+index 3333333..4444444 100644
+--- a/.github/workflows/ci.yml
++++ b/.github/workflows/ci.yml
+@@ -1 +1,2 @@
+ name: ci
++on: push
+diff --git a/src/old name.go b/src/new name.go
+similarity index 100%
+rename from src/old name.go
+rename to src/new name.go
+diff --git a/assets/logo.png b/assets/logo.png
+new file mode 100644
+index 0000000..5555555
+Binary files /dev/null and b/assets/logo.png differ
+--
+2.43.0
+`;
+
+describe("patch_path_policy.cjs", () => {
+  beforeEach(() => {
+    global.core = { warning: vi.fn() };
+  });
+
+  describe("extractPatchPaths", () => {
+    it("should extract modified, renamed and binary paths", () => {
+      expect(extractPatchPaths(patch)).toEqual([".github/workflows/ci.yml", "assets/logo.png", "docs/guide.md", "src/new name.go", "src/old name.go"]);
+    });
+
+    it("should unquote paths with special characters", () => {
+      const quoted = 'diff --git "a/docs/caf\\"e.md" "b/docs/caf\\"e.md"\n--- "a/docs/caf\\"e.md"\n+++ "b/docs/caf\\"e.md"\n';
+      expect(extractPatchPaths(quoted)).toEqual(['docs/caf"e.md']);
+    });
+
+    it("should ignore /dev/null for added and deleted files", () => {
+      const added = "diff --git a/new.md b/new.md\nnew file mode 100644\n--- /dev/null\n+++ b/new.md\n";
+      expect(extractPatchPaths(added)).toEqual(["new.md"]);
+    });
+  });
+
+  describe("getPatchPathPolicy", () => {
+    it("should read the policy from the handler config", () => {
+      const policy = getPatchPathPolicy({ allowed_paths: ["docs/**"], protected_paths: ["CODEOWNERS"] });
+      expect(policy).toEqual({ allowedPaths: ["docs/**"], blockedPaths: [], protectedPaths: ["CODEOWNERS"] });
+      expect(hasPatchPathPolicy(policy)).toBe(true);
+    });
+
+    it("should report no policy for lock files compiled without path restrictions", () => {
+      expect(hasPatchPathPolicy(getPatchPathPolicy({}))).toBe(false);
+    });
+  });
+
+  describe("checkPatchPaths", () => {
+    const protectedPaths = [".github/workflows/**", "CODEOWNERS"];
+
+    it("should reject protected paths by default", () => {
+      const violations = checkPatchPaths(patch, { allowedPaths: [], blockedPaths: [], protectedPaths });
+      expect(violations).toEqual([{ path: ".github/workflows/ci.yml", reason: "matches protected path .github/workflows/**" }]);
+    });
+
+    it("should reject paths outside allowed-paths", () => {
+      const violations = checkPatchPaths(patch, { allowedPaths: ["docs/**", "src/**"], blockedPaths: [], protectedPaths });
+      expect(violations.map(v => v.path)).toEqual([".github/workflows/ci.yml", "assets/logo.png"]);
+      expect(violations[1].reason).toBe("does not match allowed-paths");
+    });
+
+    it("should allow protected paths that allowed-paths lists exactly", () => {
+      const violations = checkPatchPaths(patch, { allowedPaths: [".github/workflows/ci.yml", "docs/**", "src/**", "assets/**"], blockedPaths: [], protectedPaths });
+      expect(violations).toEqual([]);
+    });
+
+    it("should not unlock protected paths with allowed-paths globs", () => {
+      for (const allowed of ["**", ".github/**", ".github/workflows/**", ".github/workflows/*.yml"]) {
+        const violations = checkPatchPaths(patch, { allowedPaths: [allowed, "docs/**", "src/**", "assets/**"], blockedPaths: [], protectedPaths });
+        expect(violations).toEqual([{ path: ".github/workflows/ci.yml", reason: "matches protected path .github/workflows/**" }]);
+      }
+    });
+
+    it("should not unlock CODEOWNERS with a catch-all glob", () => {
+      const codeowners = "diff --git a/CODEOWNERS b/CODEOWNERS\n--- a/CODEOWNERS\n+++ b/CODEOWNERS\n";
+      expect(checkPatchPaths(codeowners, { allowedPaths: ["**"], blockedPaths: [], protectedPaths })).toEqual([{ path: "CODEOWNERS", reason: "matches protected path CODEOWNERS" }]);
+      expect(checkPatchPaths(codeowners, { allowedPaths: ["CODEOWNERS"], blockedPaths: [], protectedPaths })).toEqual([]);
+    });
+
+    it("should reject blocked paths even when allowed", () => {
+      const violations = checkPatchPaths(patch, { allowedPaths: ["docs/**", "src/**", "assets/**"], blockedPaths: ["src/*.go"], protectedPaths: [] });
+      expect(violations).toEqual([
+        { path: ".github/workflows/ci.yml", reason: "does not match allowed-paths" },
+        { path: "src/new name.go", reason: "matches blocked-paths pattern src/*.go" },
+        { path: "src/old name.go", reason: "matches blocked-paths pattern src/*.go" },
+      ]);
+    });
+  });
+
+  describe("reportPatchPathViolations", () => {
+    it("should log each rejected path and build the error message", () => {
+      const message = reportPatchPathViolations("create_pull_request", [{ path: "CODEOWNERS", reason: "matches protected path CODEOWNERS" }]);
+      expect(message).toBe("Patch modifies 1 path(s) that are not allowed: CODEOWNERS (matches protected path CODEOWNERS)");
+      expect(global.core.warning).toHaveBeenCalledWith("Patch path rejected for create_pull_request: CODEOWNERS (matches protected path CODEOWNERS)");
+    });
+  });
+});
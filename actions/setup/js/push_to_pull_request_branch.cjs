@@ -7,6 +7,7 @@ const { generateStagedPreview } = require("./staged_preview.cjs");
 const { updateActivationCommentWithCommit } = require("./update_activation_comment.cjs");
 const { getErrorMessage } = require("./error_helpers.cjs");
 const { replaceTemporaryIdReferences } = require("./temporary_id.cjs");
+const { getPatchPathPolicy, hasPatchPathPolicy, checkPatchPaths, reportPatchPathViolations } = require("./patch_path_policy.cjs");
 
 /**
  * @typedef {import('./types/handler-factory').HandlerFactoryFunction} HandlerFactoryFunction
@@ -30,6 +31,7 @@ async function main(config = {}) {
   const maxSizeKb = config.max_patch_size ? parseInt(String(config.max_patch_size), 10) : 1024;
   const baseBranch = config.base_branch || "";
   const maxCount = config.max || 0; // 0 means no limit
+  const pathPolicy = getPatchPathPolicy(config);
 
   // Check if we're in staged mode
   const isStaged = process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true";
@@ -49,6 +51,12 @@ async function main(config = {}) {
     core.info(`Commit title suffix: ${commitTitleSuffix}`);
   }
   core.info(`Max patch size: ${maxSizeKb} KB`);
+  if (pathPolicy.allowedPaths.length > 0) {
+    core.info(`Allowed paths: ${pathPolicy.allowedPaths.join(", ")}`);
+  }
+  if (pathPolicy.blockedPaths.length > 0) {
+    core.info(`Blocked paths: ${pathPolicy.blockedPaths.join(", ")}`);
+  }
   core.info(`Max count: ${maxCount || "unlimited"}`);
 
   // Track how many items we've processed for max limit
@@ -113,6 +121,28 @@ async function main(config = {}) {
       }
 
       core.info("Patch size validation passed");
+
+      // Validate the paths modified by the patch
+      if (hasPatchPathPolicy(pathPolicy)) {
+        const violations = checkPatchPaths(patchContent, pathPolicy);
+        if (violations.length > 0) {
+          const error = reportPatchPathViolations(HANDLER_TYPE, violations);
+
+          // If in staged mode, still show preview with error
+          if (isStaged) {
+            await generateStagedPreview({
+              title: "Push to PR Branch",
+              description: "The following changes would be pushed if staged mode was disabled:",
+              items: [{ target, error }],
+              renderItem: item => `**Target:** ${item.target}\n\n**Status:** ❌ Patch path restrictions violated\n\n**Message:** ${item.error}\n\n`,
+            });
+            return { success: true, staged: true };
+          }
+
+          return { success: false, error };
+        }
+        core.info("Patch path validation passed");
+      }
     }
 
     if (isEmpty) {
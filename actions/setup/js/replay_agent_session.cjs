@@ -0,0 +1,165 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+const fs = require("fs");
+const path = require("path");
+const { getErrorMessage } = require("./error_helpers.cjs");
+
+const REPLAY_FIXTURE_VERSION = 1;
+
+/**
+ * @typedef {Object} ReplayToolCall
+ * @property {string} name - Tool name
+ * @property {string} [server] - MCP server name (empty for built-in tools)
+ * @property {Object} [input] - Tool input
+ * @property {any} [output] - Tool result or recorded MCP response
+ * @property {boolean} [is_error] - Whether the tool call failed
+ */
+
+/**
+ * @typedef {Object} ReplayFixture
+ * @property {number} version - Fixture format version
+ * @property {string} [model] - Model recorded in the session
+ * @property {ReplayToolCall[]} [tool_calls] - Recorded tool calls in order
+ * @property {Object[]} safe_outputs - Safe output items written to safe_output.jsonl
+ * @property {{input_tokens?: number, output_tokens?: number, turns?: number, cost_usd?: number, duration_ms?: number}} [usage] - Usage totals
+ */
+
+/**
+ * Validates a parsed replay fixture
+ * @param {any} fixture - Parsed fixture content
+ * @returns {ReplayFixture} The validated fixture
+ */
+function validateFixture(fixture) {
+  if (!fixture || typeof fixture !== "object") {
+    throw new Error("replay fixture must be a JSON object");
+  }
+  if (fixture.version !== REPLAY_FIXTURE_VERSION) {
+    throw new Error(`unsupported replay fixture version ${fixture.version} (expected ${REPLAY_FIXTURE_VERSION})`);
+  }
+  const toolCalls = fixture.tool_calls || [];
+  toolCalls.forEach((/** @type {any} */ call, /** @type {number} */ i) => {
+    if (!call || typeof call.name !== "string" || call.name.trim() === "") {
+      throw new Error(`replay fixture tool_calls[${i}] is missing 'name'`);
+    }
+  });
+  const safeOutputs = fixture.safe_outputs || [];
+  safeOutputs.forEach((/** @type {any} */ item, /** @type {number} */ i) => {
+    if (!item || typeof item.type !== "string" || item.type === "") {
+      throw new Error(`replay fixture safe_outputs[${i}] is missing 'type'`);
+    }
+  });
+  return fixture;
+}
+
+/**
+ * Returns the tool name as it appears in the session log (mcp__<server>__<tool> for MCP tools)
+ * @param {ReplayToolCall} call - Recorded tool call
+ * @returns {string} Tool name
+ */
+function toolName(call) {
+  return call.server ? `mcp__${call.server}__${call.name}` : call.name;
+}
+
+/**
+ * Converts a recorded tool output into tool_result text
+ * @param {any} output - Recorded output
+ * @returns {string} Output text
+ */
+function toolOutputText(output) {
+  if (output === undefined || output === null) {
+    return "";
+  }
+  return typeof output === "string" ? output : JSON.stringify(output);
+}
+
+/**
+ * Renders a fixture as a Claude stream-json session log.
+ * Must stay in sync with ReplayFixture.RenderSessionLog in pkg/workflow/replay_fixture.go.
+ * @param {ReplayFixture} fixture - Validated fixture
+ * @returns {string} JSONL session log
+ */
+function renderSessionLog(fixture) {
+  const toolCalls = fixture.tool_calls || [];
+  const usage = fixture.usage || {};
+
+  /** @type {string[]} */
+  const tools = [];
+  for (const call of toolCalls) {
+    const name = toolName(call);
+    if (!tools.includes(name)) {
+      tools.push(name);
+    }
+  }
+
+  const entries = [];
+  entries.push({ type: "system", subtype: "init", model: fixture.model || "", tools });
+
+  toolCalls.forEach((call, i) => {
+    const toolUseId = `replay_${i + 1}`;
+    entries.push({
+      type: "assistant",
+      message: { content: [{ type: "tool_use", id: toolUseId, name: toolName(call), input: call.input || {} }] },
+    });
+    entries.push({
+      type: "user",
+      message: { content: [{ type: "tool_result", tool_use_id: toolUseId, content: toolOutputText(call.output), is_error: !!call.is_error }] },
+    });
+  });
+
+  entries.push({
+    type: "result",
+    subtype: "success",
+    is_error: false,
+    num_turns: usage.turns || toolCalls.length + 1,
+    duration_ms: usage.duration_ms || 0,
+    total_cost_usd: usage.cost_usd || 0,
+    usage: { input_tokens: usage.input_tokens || 0, output_tokens: usage.output_tokens || 0 },
+  });
+
+  return entries.map(entry => JSON.stringify(entry)).join("\n") + "\n";
+}
+
+/**
+ * Replays a recorded agent session: writes the session log and appends the recorded
+ * safe outputs to the safe outputs file so the regular safe-output jobs process them.
+ */
+async function main() {
+  const fixturePath = process.env.GH_AW_REPLAY_FIXTURE;
+  const logPath = process.env.GH_AW_AGENT_LOG || "/tmp/gh-aw/agent-stdio.log";
+  const safeOutputsPath = process.env.GH_AW_SAFE_OUTPUTS;
+
+  if (!fixturePath) {
+    core.setFailed("Configuration error: GH_AW_REPLAY_FIXTURE not specified.");
+    return;
+  }
+
+  /** @type {ReplayFixture} */
+  let fixture;
+  try {
+    fixture = validateFixture(JSON.parse(fs.readFileSync(fixturePath, "utf8")));
+  } catch (error) {
+    core.setFailed(`Failed to load replay fixture ${fixturePath}: ${getErrorMessage(error)}`);
+    return;
+  }
+
+  const toolCalls = fixture.tool_calls || [];
+  const safeOutputs = fixture.safe_outputs || [];
+  core.info(`Replaying recorded session: ${toolCalls.length} tool call(s), ${safeOutputs.length} safe output(s)`);
+
+  fs.mkdirSync(path.dirname(logPath), { recursive: true });
+  fs.writeFileSync(logPath, renderSessionLog(fixture));
+  core.info(`Wrote session log to ${logPath}`);
+
+  if (safeOutputs.length > 0) {
+    if (!safeOutputsPath) {
+      core.setFailed("Replay fixture contains safe outputs but GH_AW_SAFE_OUTPUTS is not set. Configure safe-outputs in the workflow frontmatter.");
+      return;
+    }
+    fs.mkdirSync(path.dirname(safeOutputsPath), { recursive: true });
+    fs.appendFileSync(safeOutputsPath, safeOutputs.map(item => JSON.stringify(item)).join("\n") + "\n");
+    core.info(`Appended ${safeOutputs.length} safe output(s) to ${safeOutputsPath}`);
+  }
+}
+
+module.exports = { main, renderSessionLog, validateFixture };
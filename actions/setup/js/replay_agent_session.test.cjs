@@ -0,0 +1,81 @@
+// @ts-check
+
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+import os from "os";
+
+const { main, renderSessionLog, validateFixture } = require("./replay_agent_session.cjs");
+
+describe("replay_agent_session", () => {
+  let mockCore;
+  let originalEnv;
+  let tempDir;
+
+  const fixture = {
+    version: 1,
+    model: "claude-sonnet-4",
+    tool_calls: [
+      { name: "Bash", input: { command: "ls" }, output: "README.md" },
+      { server: "github", name: "get_issue", input: { issue_number: 1 }, output: { title: "Bug" } },
+    ],
+    safe_outputs: [{ type: "add_comment", body: "Triaged" }],
+    usage: { input_tokens: 100, output_tokens: 20, turns: 3, cost_usd: 0.01 },
+  };
+
+  beforeEach(() => {
+    originalEnv = { ...process.env };
+    tempDir = fs.mkdtempSync(path.join(os.tmpdir(), "replay-session-test-"));
+    mockCore = { info: vi.fn(), setFailed: vi.fn() };
+    global.core = mockCore;
+  });
+
+  afterEach(() => {
+    process.env = originalEnv;
+    fs.rmSync(tempDir, { recursive: true, force: true });
+    delete global.core;
+  });
+
+  it("renders a Claude stream-json session log", () => {
+    const lines = renderSessionLog(fixture)
+      .trim()
+      .split("\n")
+      .map(line => JSON.parse(line));
+
+    expect(lines).toHaveLength(6);
+    expect(lines[0]).toMatchObject({ type: "system", subtype: "init", tools: ["Bash", "mcp__github__get_issue"] });
+    expect(lines[3].message.content[0]).toMatchObject({ type: "tool_use", id: "replay_2", name: "mcp__github__get_issue" });
+    expect(lines[4].message.content[0]).toMatchObject({ type: "tool_result", tool_use_id: "replay_2", content: '{"title":"Bug"}' });
+    expect(lines[5]).toMatchObject({ type: "result", num_turns: 3, total_cost_usd: 0.01, usage: { input_tokens: 100, output_tokens: 20 } });
+  });
+
+  it("rejects fixtures with an unsupported version", () => {
+    expect(() => validateFixture({ version: 2, safe_outputs: [] })).toThrow("unsupported replay fixture version");
+    expect(() => validateFixture({ version: 1, safe_outputs: [{ body: "x" }] })).toThrow("safe_outputs[0] is missing 'type'");
+  });
+
+  it("writes the session log and appends safe outputs", async () => {
+    const fixturePath = path.join(tempDir, "fixture.json");
+    const logPath = path.join(tempDir, "logs", "agent-stdio.log");
+    const safeOutputsPath = path.join(tempDir, "safeoutputs", "outputs.jsonl");
+    fs.writeFileSync(fixturePath, JSON.stringify(fixture));
+
+    process.env.GH_AW_REPLAY_FIXTURE = fixturePath;
+    process.env.GH_AW_AGENT_LOG = logPath;
+    process.env.GH_AW_SAFE_OUTPUTS = safeOutputsPath;
+
+    await main();
+
+    expect(mockCore.setFailed).not.toHaveBeenCalled();
+    expect(fs.readFileSync(logPath, "utf8")).toContain('"type":"result"');
+    expect(fs.readFileSync(safeOutputsPath, "utf8")).toBe('{"type":"add_comment","body":"Triaged"}\n');
+  });
+
+  it("fails when the fixture is missing", async () => {
+    process.env.GH_AW_REPLAY_FIXTURE = path.join(tempDir, "missing.json");
+
+    await main();
+
+    expect(mockCore.setFailed).toHaveBeenCalledWith(expect.stringContaining("Failed to load replay fixture"));
+  });
+});
@@ -11,7 +11,7 @@
 
 const { loadAgentOutput } = require("./load_agent_output.cjs");
 const { getErrorMessage } = require("./error_helpers.cjs");
-const { hasUnresolvedTemporaryIds, replaceTemporaryIdReferences, normalizeTemporaryId } = require("./temporary_id.cjs");
+const { hasUnresolvedTemporaryIds, replaceTemporaryIdReferences, normalizeTemporaryId, loadTemporaryIdMap } = require("./temporary_id.cjs");
 const { generateMissingInfoSections } = require("./missing_info_formatter.cjs");
 const { setCollectedMissings } = require("./missing_messages_helper.cjs");
 const { writeSafeOutputSummaries } = require("./safe_output_summary.cjs");
@@ -63,10 +63,46 @@ const HANDLER_MAP = {
  * Message types handled by standalone steps (not through the handler manager)
  * These types should not trigger warnings when skipped by the handler manager
  *
- * Standalone types: assign_to_agent, create_agent_session, upload_asset, noop
+ * Standalone types: assign_to_agent, create_agent_session, upload_asset, create_check_run, notify_webhook, noop
  *   - Have dedicated processing steps with specialized logic
  */
-const STANDALONE_STEP_TYPES = new Set(["assign_to_agent", "create_agent_session", "upload_asset", "noop"]);
+const STANDALONE_STEP_TYPES = new Set(["assign_to_agent", "create_agent_session", "upload_asset", "create_check_run", "notify_webhook", "noop"]);
+
+/**
+ * Skip reasons for messages routed by require-approval gates
+ */
+const AWAITING_APPROVAL_REASON = "Awaiting approval";
+const OUTSIDE_APPROVAL_SCOPE_REASON = "Outside approval scope";
+
+/**
+ * Load the require-approval routing for this job
+ * - GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL: comma-separated types left to their approval-gated jobs
+ * - GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE: the only type processed by an approval-gated job
+ * @returns {{awaitingApproval: Set<string>, scope: string}}
+ */
+function loadApprovalRouting() {
+  const awaitingApproval = new Set(
+    (process.env.GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL || "")
+      .split(",")
+      .map(t => t.trim())
+      .filter(t => t)
+  );
+  const scope = (process.env.GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE || "").trim();
+  return { awaitingApproval, scope };
+}
+
+/**
+ * Returns the reason a message is skipped because of require-approval routing, if any
+ * @param {string} messageType - Safe output message type
+ * @param {{awaitingApproval: Set<string>, scope: string}} routing - Approval routing for this job
+ * @returns {string} Skip reason, or an empty string when the message is processed here
+ */
+function getApprovalSkipReason(messageType, routing) {
+  if (routing.scope) {
+    return messageType === routing.scope ? "" : OUTSIDE_APPROVAL_SCOPE_REASON;
+  }
+  return routing.awaitingApproval.has(messageType) ? AWAITING_APPROVAL_REASON : "";
+}
 
 /**
  * Load configuration for safe outputs
@@ -207,13 +243,22 @@ function collectMissingMessages(messages) {
 async function processMessages(messageHandlers, messages) {
   const results = [];
 
+  // Messages gated by require-approval are processed by their own job once approved
+  const approvalRouting = loadApprovalRouting();
+  if (approvalRouting.scope) {
+    core.info(`Processing only ${approvalRouting.scope} messages (approved through require-approval)`);
+  } else if (approvalRouting.awaitingApproval.size > 0) {
+    core.info(`Awaiting approval: ${[...approvalRouting.awaitingApproval].join(", ")}`);
+  }
+
   // Collect missing_tool and missing_data messages first
   const missings = collectMissingMessages(messages);
 
   // Initialize shared temporary ID map
-  // This will be populated by handlers as they create entities with temporary IDs
+  // This will be populated by handlers as they create entities with temporary IDs. Approval-gated
+  // jobs start from the map of the safe_outputs job (GH_AW_TEMPORARY_ID_MAP).
   /** @type {Map<string, {repo: string, number: number}>} */
-  const temporaryIdMap = new Map();
+  const temporaryIdMap = loadTemporaryIdMap();
 
   // Track outputs that were created with unresolved temporary IDs
   // Format: {type, message, result, originalTempIdMapSize}
@@ -237,6 +282,19 @@ async function processMessages(messageHandlers, messages) {
       continue;
     }
 
+    const approvalSkipReason = getApprovalSkipReason(messageType, approvalRouting);
+    if (approvalSkipReason) {
+      core.debug(`Message ${i + 1} (${messageType}) skipped: ${approvalSkipReason}`);
+      results.push({
+        type: messageType,
+        messageIndex: i,
+        success: false,
+        skipped: true,
+        reason: approvalSkipReason,
+      });
+      continue;
+    }
+
     const messageHandler = messageHandlers.get(messageType);
 
     if (!messageHandler) {
@@ -799,6 +857,7 @@ async function main() {
     const deferredCount = processingResult.results.filter(r => r.deferred).length;
     const skippedStandaloneResults = processingResult.results.filter(r => r.skipped && r.reason === "Handled by standalone step");
     const skippedNoHandlerResults = processingResult.results.filter(r => !r.success && !r.skipped && r.error?.includes("No handler loaded"));
+    const awaitingApprovalResults = processingResult.results.filter(r => r.skipped && r.reason === AWAITING_APPROVAL_REASON);
 
     core.info(`\n=== Processing Summary ===`);
     core.info(`Total messages: ${processingResult.results.length}`);
@@ -812,6 +871,11 @@ async function main() {
       const standaloneTypes = [...new Set(skippedStandaloneResults.map(r => r.type))];
       core.info(`  Types: ${standaloneTypes.join(", ")}`);
     }
+    if (awaitingApprovalResults.length > 0) {
+      core.info(`Awaiting approval: ${awaitingApprovalResults.length}`);
+      const approvalTypes = [...new Set(awaitingApprovalResults.map(r => r.type))];
+      core.info(`  Types: ${approvalTypes.join(", ")}`);
+    }
     if (skippedNoHandlerResults.length > 0) {
       core.warning(`Skipped (no handler): ${skippedNoHandlerResults.length}`);
       const noHandlerTypes = [...new Set(skippedNoHandlerResults.map(r => r.type))];
@@ -872,4 +936,4 @@ async function main() {
   }
 }
 
-module.exports = { main, loadConfig, loadHandlers, processMessages };
+module.exports = { main, loadConfig, loadHandlers, processMessages, getApprovalSkipReason };
@@ -1,7 +1,7 @@
 // @ts-check
 
 import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
-import { loadConfig, loadHandlers, processMessages } from "./safe_output_handler_manager.cjs";
+import { loadConfig, loadHandlers, processMessages, getApprovalSkipReason } from "./safe_output_handler_manager.cjs";
 
 describe("Safe Output Handler Manager", () => {
   beforeEach(() => {
@@ -20,6 +20,9 @@ describe("Safe Output Handler Manager", () => {
     // Clean up environment variables
     delete process.env.GH_AW_SAFE_OUTPUTS_HANDLER_CONFIG;
     delete process.env.GH_AW_TRACKER_LABEL;
+    delete process.env.GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL;
+    delete process.env.GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE;
+    delete process.env.GH_AW_TEMPORARY_ID_MAP;
   });
 
   describe("loadConfig", () => {
@@ -747,5 +750,52 @@ describe("Safe Output Handler Manager", () => {
       expect(result.missings.missingData).toHaveLength(0);
       expect(result.missings.noopMessages).toHaveLength(0);
     });
+
+    it("should leave messages awaiting approval to their approval-gated job", async () => {
+      process.env.GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL = "create_pull_request";
+      const messages = [
+        { type: "add_comment", body: "Comment" },
+        { type: "create_pull_request", title: "PR" },
+      ];
+
+      const mockHandler = vi.fn().mockResolvedValue({ success: true });
+      const handlers = new Map([
+        ["add_comment", mockHandler],
+        ["create_pull_request", mockHandler],
+      ]);
+
+      const result = await processMessages(handlers, messages);
+
+      expect(result.success).toBe(true);
+      expect(mockHandler).toHaveBeenCalledTimes(1);
+      expect(result.results[1]).toMatchObject({ type: "create_pull_request", skipped: true, reason: "Awaiting approval" });
+    }
+    it("should resolve temporary IDs created by the safe_outputs job in an approval-gated job", async () => {
+      process.env.GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE = "create_pull_request";
+      process.env.GH_AW_TEMPORARY_ID_MAP = JSON.stringify({ aw_abc123def456: { repo: "owner/repo", number: 42 } });
+      const messages = [{ type: "create_pull_request", title: "PR", body: "Fixes #aw_abc123def456" }];
+
+      const mockHandler = vi.fn().mockResolvedValue({ success: true });
+      const handlers = new Map([["create_pull_request", mockHandler]]);
+
+      const result = await processMessages(handlers, messages);
+
+      expect(result.success).toBe(true);
+      expect(mockHandler).toHaveBeenCalledWith(messages[0], { aw_abc123def456: { repo: "owner/repo", number: 42 } });
+    });
+  });
+
+  describe("getApprovalSkipReason", () => {
+    it("should only process the scoped type in an approval-gated job", () => {
+      const routing = { awaitingApproval: new Set(), scope: "create_issue" };
+      expect(getApprovalSkipReason("create_issue", routing)).toBe("");
+      expect(getApprovalSkipReason("missing_tool", routing)).toBe("Outside approval scope");
+    });
+
+    it("should skip types awaiting approval in the safe_outputs job", () => {
+      const routing = { awaitingApproval: new Set(["create_pull_request"]), scope: "" };
+      expect(getApprovalSkipReason("create_pull_request", routing)).toBe("Awaiting approval");
+      expect(getApprovalSkipReason("add_comment", routing)).toBe("");
+    });
   });
 });
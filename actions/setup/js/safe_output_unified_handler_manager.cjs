@@ -79,10 +79,10 @@ const PROJECT_HANDLER_MAP = {
  * Message types handled by standalone steps (not through the handler manager)
  * These types should not trigger warnings when skipped by the handler manager
  *
- * Other standalone types: assign_to_agent, create_agent_session, upload_asset, noop
+ * Other standalone types: assign_to_agent, create_agent_session, upload_asset, create_check_run, notify_webhook, noop
  *   - Have dedicated processing steps with specialized logic
  */
-const STANDALONE_STEP_TYPES = new Set(["assign_to_agent", "create_agent_session", "upload_asset", "noop"]);
+const STANDALONE_STEP_TYPES = new Set(["assign_to_agent", "create_agent_session", "upload_asset", "create_check_run", "notify_webhook", "noop"]);
 
 /**
  * Project-related message types that are handled by project handlers
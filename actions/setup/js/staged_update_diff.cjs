@@ -0,0 +1,213 @@
+// @ts-check
+/// <reference types="@actions/github-script" />
+
+/**
+ * Staged Mode Update Diff Module
+ *
+ * Renders before/after diffs for update-issue, update-pull-request, update-project and
+ * update-release in staged mode. The expected markdown of the fixtures in
+ * pkg/workflow/testdata/staged_update_diff is generated by the reference rendering in
+ * pkg/workflow/safe_outputs_staged_diff_test.go; this module must match it for every fixture.
+ */
+
+const { generateStagedPreview } = require("./staged_preview.cjs");
+
+/**
+ * @typedef {Object} StagedEntityState
+ * @property {string} [title] - Title (or release name)
+ * @property {string} [body] - Body
+ * @property {string[]} [labels] - Label names
+ * @property {Record<string, string>} [fields] - Other fields such as state, milestone or project field values
+ */
+
+/**
+ * @typedef {Object} StagedUpdatePreview
+ * @property {string} target - Heading for the entity (e.g., "Issue #42")
+ * @property {string} [url] - Link to the current entity
+ * @property {StagedEntityState | null} before - Current state; null when it could not be fetched
+ * @property {StagedEntityState} after - State after the update would be applied
+ */
+
+/**
+ * Format a value for a markdown table cell
+ * @param {string} value - Cell value
+ * @returns {string} Escaped cell content
+ */
+function formatCell(value) {
+  if (!value) {
+    return "_(none)_";
+  }
+  return value.replace(/\|/g, "\\|").replace(/\r\n/g, "\n").replace(/\n/g, "<br>");
+}
+
+/**
+ * Maximum number of lines per side diffed line by line; longer texts are shown as a plain
+ * before/after block to keep the diff table small. The long_body_* fixtures check it against
+ * maxStagedDiffLines in the Go reference rendering.
+ */
+const MAX_DIFF_LINES = 1000;
+
+/**
+ * Split text into lines; empty text has no lines
+ * @param {string} text - Text to split
+ * @returns {string[]} Lines
+ */
+function splitLines(text) {
+  if (!text) {
+    return [];
+  }
+  return text.replace(/\r\n/g, "\n").split("\n");
+}
+
+/**
+ * Compute a line diff of two texts using the longest common subsequence.
+ * Lines are prefixed with "-" when removed, "+" when added and " " when unchanged.
+ * Texts longer than MAX_DIFF_LINES are shown as all removed followed by all added.
+ * @param {string} before - Current text
+ * @param {string} after - Updated text
+ * @returns {string[]} Diff lines
+ */
+function diffLines(before, after) {
+  const a = splitLines(before);
+  const b = splitLines(after);
+  if (a.length > MAX_DIFF_LINES || b.length > MAX_DIFF_LINES) {
+    return [...a.map(line => "-" + line), ...b.map(line => "+" + line)];
+  }
+
+  // lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+  const lcs = Array.from({ length: a.length + 1 }, () => new Array(b.length + 1).fill(0));
+  for (let i = a.length - 1; i >= 0; i--) {
+    for (let j = b.length - 1; j >= 0; j--) {
+      lcs[i][j] = a[i] === b[j] ? lcs[i + 1][j + 1] + 1 : Math.max(lcs[i + 1][j], lcs[i][j + 1]);
+    }
+  }
+
+  const lines = [];
+  let i = 0;
+  let j = 0;
+  while (i < a.length && j < b.length) {
+    if (a[i] === b[j]) {
+      lines.push(" " + a[i]);
+      i++;
+      j++;
+    } else if (lcs[i + 1][j] >= lcs[i][j + 1]) {
+      lines.push("-" + a[i]);
+      i++;
+    } else {
+      lines.push("+" + b[j]);
+      j++;
+    }
+  }
+  for (; i < a.length; i++) {
+    lines.push("-" + a[i]);
+  }
+  for (; j < b.length; j++) {
+    lines.push("+" + b[j]);
+  }
+  return lines;
+}
+
+/**
+ * Return the length of the longest run of backticks in text
+ * @param {string} text - Text to scan
+ * @returns {number} Longest run length
+ */
+function longestBacktickRun(text) {
+  const runs = text.match(/`+/g) || [];
+  return runs.reduce((longest, run) => Math.max(longest, run.length), 0);
+}
+
+/**
+ * Render the markdown before/after diff of a staged update: a table of the changed
+ * title, labels and fields followed by a line diff of the body.
+ * @param {StagedUpdatePreview} preview - Staged update
+ * @returns {string} Markdown
+ */
+function renderStagedUpdateDiff(preview) {
+  let content = `### ${preview.target}\n\n`;
+  if (preview.url) {
+    content += `**URL:** ${preview.url}\n\n`;
+  }
+
+  if (!preview.before) {
+    content += "_The current state could not be fetched; all values are shown as additions._\n\n";
+  }
+  const before = preview.before || {};
+  const after = preview.after || {};
+  const beforeFields = before.fields || {};
+  const afterFields = after.fields || {};
+
+  /** @type {Array<[string, string, string]>} */
+  const rows = [];
+  if ((before.title || "") !== (after.title || "")) {
+    rows.push(["Title", before.title || "", after.title || ""]);
+  }
+  const beforeLabels = (before.labels || []).join(", ");
+  const afterLabels = (after.labels || []).join(", ");
+  if (beforeLabels !== afterLabels) {
+    rows.push(["Labels", beforeLabels, afterLabels]);
+  }
+  const fieldNames = [...new Set([...Object.keys(beforeFields), ...Object.keys(afterFields)])].sort();
+  for (const name of fieldNames) {
+    const beforeValue = beforeFields[name] || "";
+    const afterValue = afterFields[name] || "";
+    if (beforeValue !== afterValue) {
+      rows.push([name, beforeValue, afterValue]);
+    }
+  }
+
+  const bodyChanged = (before.body || "") !== (after.body || "");
+  if (rows.length === 0 && !bodyChanged) {
+    content += "_No changes: the update matches the current state._\n\n";
+    return content;
+  }
+
+  if (rows.length > 0) {
+    content += "| Field | Before | After |\n| --- | --- | --- |\n";
+    for (const [name, beforeValue, afterValue] of rows) {
+      content += `| ${formatCell(name)} | ${formatCell(beforeValue)} | ${formatCell(afterValue)} |\n`;
+    }
+    content += "\n";
+  }
+
+  if (bodyChanged) {
+    const diff = diffLines(before.body || "", after.body || "").join("\n");
+    const fence = "`".repeat(Math.max(3, longestBacktickRun(diff) + 1));
+    content += `**Body:**\n\n${fence}diff\n${diff}\n${fence}\n\n`;
+  }
+
+  return content;
+}
+
+/**
+ * Apply the changes of an update to the current state of an entity
+ * @param {StagedEntityState | null} before - Current state; null when it could not be fetched
+ * @param {StagedEntityState} changes - Values set by the update
+ * @returns {StagedEntityState} State after the update would be applied
+ */
+function applyStagedUpdate(before, changes) {
+  const current = before || {};
+  return {
+    title: changes.title !== undefined ? changes.title : current.title,
+    body: changes.body !== undefined ? changes.body : current.body,
+    labels: changes.labels !== undefined ? changes.labels : current.labels,
+    fields: { ...(current.fields || {}), ...(changes.fields || {}) },
+  };
+}
+
+/**
+ * Write the staged before/after preview of one update to the step summary
+ * @param {string} operation - Operation name (e.g., "Update Issue")
+ * @param {StagedUpdatePreview} preview - Staged update
+ * @returns {Promise<void>}
+ */
+async function writeStagedUpdatePreview(operation, preview) {
+  await generateStagedPreview({
+    title: operation,
+    description: "The following changes would be applied if staged mode was disabled:",
+    items: [preview],
+    renderItem: renderStagedUpdateDiff,
+  });
+}
+
+module.exports = { applyStagedUpdate, diffLines, renderStagedUpdateDiff, writeStagedUpdatePreview };
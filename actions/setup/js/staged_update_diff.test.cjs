@@ -0,0 +1,99 @@
+import { describe, it, expect, beforeEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+import { fileURLToPath } from "url";
+
+const __filename = fileURLToPath(import.meta.url);
+const __dirname = path.dirname(__filename);
+
+// Fixtures generated by the reference rendering in pkg/workflow/safe_outputs_staged_diff_test.go
+const fixturesDir = path.join(__dirname, "..", "..", "..", "pkg", "workflow", "testdata", "staged_update_diff");
+
+const mockCore = {
+  info: vi.fn(),
+  setFailed: vi.fn(),
+  summary: {
+    addRaw: vi.fn().mockReturnThis(),
+    write: vi.fn().mockResolvedValue(),
+  },
+};
+
+global.core = mockCore;
+
+describe("staged_update_diff.cjs", () => {
+  let renderStagedUpdateDiff;
+  let diffLines;
+  let applyStagedUpdate;
+  let writeStagedUpdatePreview;
+
+  beforeEach(async () => {
+    vi.clearAllMocks();
+    ({ renderStagedUpdateDiff, diffLines, applyStagedUpdate, writeStagedUpdatePreview } = await import("./staged_update_diff.cjs"));
+  });
+
+  describe("renderStagedUpdateDiff", () => {
+    const fixtures = fs.readdirSync(fixturesDir).filter(file => file.endsWith(".json"));
+
+    it("should have fixtures", () => {
+      expect(fixtures.length).toBeGreaterThan(0);
+    });
+
+    for (const fixture of fixtures) {
+      it(`should match the Go rendering for ${fixture}`, () => {
+        const preview = JSON.parse(fs.readFileSync(path.join(fixturesDir, fixture), "utf8"));
+        const expected = fs.readFileSync(path.join(fixturesDir, fixture.replace(/\.json$/, ".md")), "utf8");
+        expect(renderStagedUpdateDiff(preview)).toBe(expected);
+      });
+    }
+
+    it("should use a longer fence when the body contains backticks", () => {
+      const content = renderStagedUpdateDiff({ target: "Release v1", before: { body: "old" }, after: { body: "````\nnew\n````" } });
+      expect(content).toContain("`````diff\n");
+    });
+  });
+
+  describe("diffLines", () => {
+    it("should mark replaced lines", () => {
+      expect(diffLines("a\nb\nc", "a\nx\nc")).toEqual([" a", "-b", "+x", " c"]);
+    });
+
+    it("should mark appended and removed lines", () => {
+      expect(diffLines("a\nb", "a\nb\nc")).toEqual([" a", " b", "+c"]);
+      expect(diffLines("a\r\nb", "")).toEqual(["-a", "-b"]);
+    });
+
+    it("should treat empty text as no lines", () => {
+      expect(diffLines("", "")).toEqual([]);
+      expect(diffLines("", "a")).toEqual(["+a"]);
+    });
+
+    it("should show long texts as a plain before/after block", () => {
+      const before = Array.from({ length: 1001 }, (_, i) => `line ${i}`).join("\n");
+      const lines = diffLines(before, "line 0");
+      expect(lines).toHaveLength(1002);
+      expect(lines[0]).toBe("-line 0");
+      expect(lines[1001]).toBe("+line 0");
+    });
+  });
+
+  describe("applyStagedUpdate", () => {
+    it("should keep current values that the update does not set", () => {
+      const after = applyStagedUpdate({ title: "Old", body: "Body", labels: ["bug"], fields: { State: "open" } }, { title: "New", fields: { Milestone: "3" } });
+      expect(after).toEqual({ title: "New", body: "Body", labels: ["bug"], fields: { State: "open", Milestone: "3" } });
+    });
+
+    it("should only contain the update when the current state is unknown", () => {
+      expect(applyStagedUpdate(null, { body: "New" })).toEqual({ title: undefined, body: "New", labels: undefined, fields: {} });
+    });
+  });
+
+  describe("writeStagedUpdatePreview", () => {
+    it("should write the preview to the step summary", async () => {
+      await writeStagedUpdatePreview("Update Issue", { target: "Issue #1", before: { title: "Old" }, after: { title: "New" } });
+      const summary = mockCore.summary.addRaw.mock.calls[0][0];
+      expect(summary).toContain("## 🎭 Staged Mode: Update Issue Preview");
+      expect(summary).toContain("| Title | Old | New |");
+      expect(mockCore.summary.write).toHaveBeenCalled();
+    });
+  });
+});
@@ -160,6 +160,58 @@ interface CreateCodeScanningAlertItem extends BaseSafeOutputItem {
   ruleIdSuffix?: string;
 }
 
+/**
+ * Line annotation attached to a check run
+ */
+interface CheckRunAnnotation {
+  /** File path relative to the repository root */
+  path: string;
+  /** First line of the annotation */
+  start_line: number | string;
+  /** Last line of the annotation (defaults to start_line) */
+  end_line?: number | string;
+  /** Annotation level: "notice", "warning", or "failure" */
+  annotation_level: "notice" | "warning" | "failure";
+  /** Annotation message */
+  message: string;
+  /** Optional annotation title */
+  title?: string;
+}
+
+/**
+ * JSONL item for creating a check run with annotations on a pull request head commit
+ */
+interface CreateCheckRunItem extends BaseSafeOutputItem {
+  type: "create_check_run";
+  /** Check run conclusion */
+  conclusion: "success" | "failure" | "neutral" | "cancelled" | "skipped" | "timed_out";
+  /** Optional check run title (defaults to the check name) */
+  title?: string;
+  /** Summary in Markdown */
+  summary: string;
+  /** Optional details in Markdown */
+  text?: string;
+  /** Optional line annotations */
+  annotations?: CheckRunAnnotation[];
+  /** Pull request number, required when target is "*" */
+  pull_request_number?: number | string;
+}
+
+/**
+ * JSONL item for sending a notification to a configured webhook endpoint
+ */
+interface NotifyWebhookItem extends BaseSafeOutputItem {
+  type: "notify_webhook";
+  /** Notification headline */
+  title: string;
+  /** Notification message in Markdown */
+  body: string;
+  /** Optional severity (defaults to "info") */
+  severity?: "info" | "warning" | "critical";
+  /** Optional endpoint name; all endpoints are notified when omitted */
+  endpoint?: string;
+}
+
 /**
  * JSONL item for adding labels to an issue or PR
  */
@@ -365,6 +417,8 @@ type SafeOutputItem =
   | CreatePullRequestItem
   | CreatePullRequestReviewCommentItem
   | CreateCodeScanningAlertItem
+  | CreateCheckRunItem
+  | NotifyWebhookItem
   | AddLabelsItem
   | RemoveLabelsItem
   | AddReviewerItem
@@ -404,6 +458,9 @@ export {
   CreatePullRequestItem,
   CreatePullRequestReviewCommentItem,
   CreateCodeScanningAlertItem,
+  CheckRunAnnotation,
+  CreateCheckRunItem,
+  NotifyWebhookItem,
   AddLabelsItem,
   RemoveLabelsItem,
   AddReviewerItem,
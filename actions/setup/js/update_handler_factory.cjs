@@ -7,6 +7,7 @@
 
 const { getErrorMessage } = require("./error_helpers.cjs");
 const { resolveTarget } = require("./safe_output_helpers.cjs");
+const { writeStagedUpdatePreview } = require("./staged_update_diff.cjs");
 
 /**
  * @typedef {Object} UpdateHandlerConfig
@@ -17,6 +18,7 @@ const { resolveTarget } = require("./safe_output_helpers.cjs");
  * @property {Function} buildUpdateData - Function to build update data from message
  * @property {Function} executeUpdate - Function to execute the update API call
  * @property {Function} formatSuccessResult - Function to format success result
+ * @property {Function} [buildStagedPreview] - Function to fetch the current item and build its before/after preview in staged mode
  * @property {Object} [additionalConfig] - Additional configuration options specific to the handler
  */
 
@@ -27,13 +29,14 @@ const { resolveTarget } = require("./safe_output_helpers.cjs");
  * - Max count enforcement
  * - Target resolution
  * - Empty update validation
+ * - Staged mode before/after previews
  * - Success/error response shaping
  *
  * @param {UpdateHandlerConfig} handlerConfig - Configuration for the specific update handler
  * @returns {HandlerFactoryFunction} Handler factory function
  */
 function createUpdateHandlerFactory(handlerConfig) {
-  const { itemType, itemTypeName, supportsPR, resolveItemNumber, buildUpdateData, executeUpdate, formatSuccessResult, additionalConfig = {} } = handlerConfig;
+  const { itemType, itemTypeName, supportsPR, resolveItemNumber, buildUpdateData, executeUpdate, formatSuccessResult, buildStagedPreview, additionalConfig = {} } = handlerConfig;
 
   /**
    * Main handler factory
@@ -43,6 +46,7 @@ function createUpdateHandlerFactory(handlerConfig) {
     // Extract configuration with defaults
     const updateTarget = config.target || "triggering";
     const maxCount = config.max || 10;
+    const isStaged = process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true";
 
     // Build configuration log message
     const configParts = [`max=${maxCount}`, `target=${updateTarget}`];
@@ -131,6 +135,18 @@ function createUpdateHandlerFactory(handlerConfig) {
         };
       }
 
+      // In staged mode, preview the update against the current item instead of applying it
+      if (isStaged && buildStagedPreview) {
+        core.info(`Staged mode: previewing update of ${itemTypeName} #${itemNumber} with: ${JSON.stringify(updateFields)}`);
+        const preview = await buildStagedPreview(github, context, itemNumber, updateData);
+        await writeStagedUpdatePreview(`Update ${itemTypeName.replace(/\b\w/g, c => c.toUpperCase())}`, preview);
+        return {
+          success: true,
+          skipped: true,
+          reason: "staged_mode",
+        };
+      }
+
       core.info(`Updating ${itemTypeName} #${itemNumber} with: ${JSON.stringify(updateFields)}`);
 
       // Execute the update
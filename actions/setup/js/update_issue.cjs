@@ -13,58 +13,69 @@ const { createUpdateHandlerFactory } = require("./update_handler_factory.cjs");
 const { updateBody } = require("./update_pr_description_helpers.cjs");
 const { loadTemporaryProjectMap, replaceTemporaryProjectReferences } = require("./temporary_id.cjs");
 const { sanitizeTitle } = require("./sanitize_title.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+const { applyStagedUpdate } = require("./staged_update_diff.cjs");
 
 /**
- * Execute the issue update API call
- * @param {any} github - GitHub API client
+ * Build the updated issue body from the current body and the requested body operation
  * @param {any} context - GitHub Actions context
- * @param {number} issueNumber - Issue number to update
+ * @param {string} currentBody - Current issue body
  * @param {any} updateData - Data to update
- * @returns {Promise<any>} Updated issue
+ * @returns {string} Updated issue body
  */
-async function executeIssueUpdate(github, context, issueNumber, updateData) {
+function buildUpdatedIssueBody(context, currentBody, updateData) {
   // Handle body operation (append/prepend/replace/replace-island)
   // Default to "append" to add footer with AI attribution
   const operation = updateData._operation || "append";
   let rawBody = updateData._rawBody;
   const includeFooter = updateData._includeFooter !== false; // Default to true
 
+  // Load and apply temporary project URL replacements FIRST
+  // This resolves any temporary project IDs (e.g., #aw_abc123def456) to actual project URLs
+  const temporaryProjectMap = loadTemporaryProjectMap();
+  if (temporaryProjectMap.size > 0) {
+    rawBody = replaceTemporaryProjectReferences(rawBody, temporaryProjectMap);
+    core.debug(`Applied ${temporaryProjectMap.size} temporary project URL replacement(s)`);
+  }
+
+  // Get workflow run URL for AI attribution
+  const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
+  const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
+  const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
+
+  // Use helper to update body (handles all operations including replace)
+  return updateBody({
+    currentBody,
+    newContent: rawBody,
+    operation,
+    workflowName,
+    runUrl,
+    workflowId,
+    includeFooter, // Pass footer flag to helper
+  });
+}
+
+/**
+ * Execute the issue update API call
+ * @param {any} github - GitHub API client
+ * @param {any} context - GitHub Actions context
+ * @param {number} issueNumber - Issue number to update
+ * @param {any} updateData - Data to update
+ * @returns {Promise<any>} Updated issue
+ */
+async function executeIssueUpdate(github, context, issueNumber, updateData) {
   // Remove internal fields
   const { _operation, _rawBody, _includeFooter, ...apiData } = updateData;
 
   // If we have a body, process it with the appropriate operation
-  if (rawBody !== undefined) {
-    // Load and apply temporary project URL replacements FIRST
-    // This resolves any temporary project IDs (e.g., #aw_abc123def456) to actual project URLs
-    const temporaryProjectMap = loadTemporaryProjectMap();
-    if (temporaryProjectMap.size > 0) {
-      rawBody = replaceTemporaryProjectReferences(rawBody, temporaryProjectMap);
-      core.debug(`Applied ${temporaryProjectMap.size} temporary project URL replacement(s)`);
-    }
-
+  if (_rawBody !== undefined) {
     // Fetch current issue body for all operations (needed for append/prepend/replace-island/replace)
     const { data: currentIssue } = await github.rest.issues.get({
       owner: context.repo.owner,
       repo: context.repo.repo,
       issue_number: issueNumber,
     });
-    const currentBody = currentIssue.body || "";
-
-    // Get workflow run URL for AI attribution
-    const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
-    const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
-    const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
-
-    // Use helper to update body (handles all operations including replace)
-    apiData.body = updateBody({
-      currentBody,
-      newContent: rawBody,
-      operation,
-      workflowName,
-      runUrl,
-      workflowId,
-      includeFooter, // Pass footer flag to helper
-    });
+    apiData.body = buildUpdatedIssueBody(context, currentIssue.body || "", updateData);
 
     core.info(`Will update body (length: ${apiData.body.length})`);
   }
@@ -79,6 +90,70 @@ async function executeIssueUpdate(github, context, issueNumber, updateData) {
   return issue;
 }
 
+/**
+ * Convert an issue into the state compared by staged previews
+ * @param {any} issue - Issue returned by the GitHub API
+ * @returns {import('./staged_update_diff.cjs').StagedEntityState} Issue state
+ */
+function toIssueState(issue) {
+  return {
+    title: issue.title || "",
+    body: issue.body || "",
+    labels: (issue.labels || []).map(/** @param {any} label */ label => (typeof label === "string" ? label : label.name)),
+    fields: {
+      State: issue.state || "",
+      Milestone: issue.milestone ? String(issue.milestone.number) : "",
+      Assignees: (issue.assignees || []).map(/** @param {any} assignee */ assignee => assignee.login).join(", "),
+    },
+  };
+}
+
+/**
+ * Fetch the current issue and build the before/after preview of a staged update
+ * @param {any} github - GitHub API client
+ * @param {any} context - GitHub Actions context
+ * @param {number} issueNumber - Issue number to update
+ * @param {any} updateData - Data to update
+ * @returns {Promise<import('./staged_update_diff.cjs').StagedUpdatePreview>} Staged update preview
+ */
+async function buildIssueStagedPreview(github, context, issueNumber, updateData) {
+  let currentIssue = null;
+  try {
+    ({ data: currentIssue } = await github.rest.issues.get({
+      owner: context.repo.owner,
+      repo: context.repo.repo,
+      issue_number: issueNumber,
+    }));
+  } catch (error) {
+    core.warning(`Failed to fetch issue #${issueNumber} for staged preview: ${getErrorMessage(error)}`);
+  }
+
+  const before = currentIssue ? toIssueState(currentIssue) : null;
+  /** @type {Record<string, string>} */
+  const fields = {};
+  if (updateData.state !== undefined) {
+    fields.State = String(updateData.state);
+  }
+  if (updateData.milestone !== undefined) {
+    fields.Milestone = updateData.milestone === null ? "" : String(updateData.milestone);
+  }
+  if (updateData.assignees !== undefined) {
+    fields.Assignees = updateData.assignees.join(", ");
+  }
+
+  return {
+    target: `Issue #${issueNumber}`,
+    url: currentIssue?.html_url,
+    before,
+    after: applyStagedUpdate(before, {
+      title: updateData.title,
+      body: updateData._rawBody !== undefined ? buildUpdatedIssueBody(context, before?.body || "", updateData) : undefined,
+      labels: updateData.labels,
+      fields,
+    }),
+  };
+}
+
 /**
  * Resolve issue number from message and configuration
  * @param {Object} item - The message item
@@ -178,6 +253,7 @@ const main = createUpdateHandlerFactory({
   buildUpdateData: buildIssueUpdateData,
   executeUpdate: executeIssueUpdate,
   formatSuccessResult: formatIssueSuccessResult,
+  buildStagedPreview: buildIssueStagedPreview,
 });
 
-module.exports = { main, buildIssueUpdateData };
+module.exports = { main, buildIssueUpdateData, buildIssueStagedPreview };
@@ -1,4 +1,4 @@
-import { describe, it, expect, beforeEach, vi } from "vitest";
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
 
 // Mock the global objects that GitHub Actions provides
 const mockCore = {
@@ -611,3 +611,60 @@ describe("update_issue.cjs - allow_body configuration", () => {
     expect(mockCore.warning).toHaveBeenCalledWith("Body update not allowed by safe-outputs configuration");
   });
 });
+
+describe("update_issue.cjs - staged mode", () => {
+  beforeEach(async () => {
+    vi.clearAllMocks();
+    vi.resetModules();
+    process.env.GH_AW_WORKFLOW_NAME = "Test Workflow";
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+
+    mockGithub.rest.issues.get.mockResolvedValue({
+      data: {
+        number: 100,
+        title: "Test Issue",
+        body: "Original body content",
+        state: "open",
+        labels: [{ name: "bug" }],
+        assignees: [],
+        milestone: null,
+        html_url: "https://github.com/testowner/testrepo/issues/100",
+      },
+    });
+  });
+
+  afterEach(() => {
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+  });
+
+  it("should preview the update against the current issue without updating it", async () => {
+    const { main } = await import("./update_issue.cjs");
+    const handler = await main({});
+
+    const result = await handler({ type: "update_issue", title: "Renamed issue", labels: ["bug", "triage"], body: "Replacement", operation: "replace" }, {});
+
+    expect(result.success).toBe(true);
+    expect(result.skipped).toBe(true);
+    expect(result.reason).toBe("staged_mode");
+    expect(mockGithub.rest.issues.get).toHaveBeenCalledWith({ owner: "testowner", repo: "testrepo", issue_number: 100 });
+    expect(mockGithub.rest.issues.update).not.toHaveBeenCalled();
+
+    const summary = mockCore.summary.addRaw.mock.calls[0][0];
+    expect(summary).toContain("### Issue #100");
+    expect(summary).toContain("| Title | Test Issue | Renamed issue |");
+    expect(summary).toContain("| Labels | bug | bug, triage |");
+    expect(summary).toContain("-Original body content");
+    expect(summary).toContain("+Replacement");
+  });
+
+  it("should show proposed values when the issue cannot be fetched", async () => {
+    mockGithub.rest.issues.get.mockRejectedValue(new Error("Not Found"));
+    const { buildIssueStagedPreview } = await import("./update_issue.cjs");
+
+    const preview = await buildIssueStagedPreview(mockGithub, mockContext, 100, { state: "closed" });
+
+    expect(preview.before).toBeNull();
+    expect(preview.after.fields).toEqual({ State: "closed" });
+    expect(mockCore.warning).toHaveBeenCalledWith(expect.stringContaining("Failed to fetch issue #100 for staged preview"));
+  });
+});
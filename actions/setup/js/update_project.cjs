@@ -4,6 +4,7 @@
 const { loadAgentOutput } = require("./load_agent_output.cjs");
 const { getErrorMessage } = require("./error_helpers.cjs");
 const { loadTemporaryIdMap, resolveIssueNumber, isTemporaryId, normalizeTemporaryId } = require("./temporary_id.cjs");
+const { applyStagedUpdate, writeStagedUpdatePreview } = require("./staged_update_diff.cjs");
 
 /**
  * Normalize agent output keys for update_project.
@@ -1127,6 +1128,133 @@ async function updateProject(output, temporaryIdMap = new Map(), githubClient =
   }
 }
 
+/**
+ * Normalize a project field name the same way updateProject matches fields (e.g., "start_date" -> "Start Date")
+ * @param {string} fieldName - Field name from the agent output
+ * @returns {string} Normalized field name
+ */
+function normalizeProjectFieldName(fieldName) {
+  return fieldName
+    .split(/[\s_-]+/)
+    .map(word => word.charAt(0).toUpperCase() + word.slice(1).toLowerCase())
+    .join(" ");
+}
+
+/**
+ * Fetch the title and field values of an issue or pull request on a project
+ * @param {any} github - GitHub client (Octokit instance) to use for GraphQL queries
+ * @param {string} projectUrl - Project URL
+ * @param {number} contentNumber - Issue or pull request number in the current repository
+ * @returns {Promise<{title: string, fields: Record<string, string>}>} Current state; fields are empty when the content is not on the project
+ */
+async function fetchProjectItemState(github, projectUrl, contentNumber) {
+  const { owner, repo } = context.repo;
+  const itemsFragment = `title
+          projectItems(first: 50) {
+            nodes {
+              project { url }
+              fieldValues(first: 50) {
+                nodes {
+                  ... on ProjectV2ItemFieldTextValue { text field { ... on ProjectV2FieldCommon { name } } }
+                  ... on ProjectV2ItemFieldNumberValue { number field { ... on ProjectV2FieldCommon { name } } }
+                  ... on ProjectV2ItemFieldDateValue { date field { ... on ProjectV2FieldCommon { name } } }
+                  ... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+                  ... on ProjectV2ItemFieldIterationValue { title field { ... on ProjectV2FieldCommon { name } } }
+                }
+              }
+            }
+          }`;
+  const result = await github.graphql(
+    `query($owner: String!, $repo: String!, $number: Int!) {
+      repository(owner: $owner, name: $repo) {
+        issueOrPullRequest(number: $number) {
+          ... on Issue { ${itemsFragment} }
+          ... on PullRequest { ${itemsFragment} }
+        }
+      }
+    }`,
+    { owner, repo, number: contentNumber }
+  );
+
+  const content = result.repository.issueOrPullRequest;
+  const projectInfo = parseProjectUrl(projectUrl);
+  /** @type {Record<string, string>} */
+  const fields = {};
+  for (const item of content?.projectItems?.nodes || []) {
+    const itemProject = item?.project?.url ? parseProjectUrl(item.project.url) : null;
+    if (!itemProject || itemProject.scope !== projectInfo.scope || itemProject.ownerLogin.toLowerCase() !== projectInfo.ownerLogin.toLowerCase() || itemProject.projectNumber !== projectInfo.projectNumber) {
+      continue;
+    }
+    for (const value of item.fieldValues?.nodes || []) {
+      const fieldName = value?.field?.name;
+      // The Title field mirrors the content title, which is compared separately
+      if (!fieldName || fieldName === "Title") {
+        continue;
+      }
+      const fieldValue = value.text ?? value.number ?? value.date ?? value.name ?? value.title;
+      if (fieldValue !== undefined && fieldValue !== null) {
+        fields[fieldName] = String(fieldValue);
+      }
+    }
+  }
+
+  return { title: content?.title || "", fields };
+}
+
+/**
+ * Fetch the current project item and build the before/after preview of a staged update_project message
+ * @param {any} message - The update_project message with a resolved project URL
+ * @param {Map<string, any>} temporaryIdMap - Unified map of temporary IDs
+ * @param {any} github - GitHub client (Octokit instance) to use for GraphQL queries
+ * @returns {Promise<import('./staged_update_diff.cjs').StagedUpdatePreview>} Staged update preview
+ */
+async function buildProjectStagedPreview(message, temporaryIdMap, github) {
+  const isDraft = message.content_type === "draft_issue";
+  const rawContentNumber = message.content_number ?? message.issue ?? message.pull_request;
+  let contentNumber = null;
+  if (!isDraft && rawContentNumber !== undefined && rawContentNumber !== null) {
+    const resolved = resolveIssueNumber(String(rawContentNumber).trim(), temporaryIdMap);
+    contentNumber = resolved.resolved ? resolved.resolved.number : null;
+  }
+
+  let contentLabel = "Project item";
+  if (isDraft) {
+    contentLabel = `Draft issue "${message.draft_title || ""}"`;
+  } else if (contentNumber !== null) {
+    contentLabel = `${message.content_type === "pull_request" ? "Pull Request" : "Issue"} #${contentNumber}`;
+  }
+
+  let before = null;
+  if (contentNumber !== null) {
+    try {
+      before = await fetchProjectItemState(github, message.project, contentNumber);
+    } catch (error) {
+      core.warning(`Failed to fetch project item for staged preview: ${getErrorMessage(error)}`);
+    }
+  }
+
+  // Match the agent's field names to the project's field names case-insensitively
+  const currentFieldNames = Object.keys(before?.fields || {});
+  /** @type {Record<string, string>} */
+  const fields = {};
+  for (const [fieldName, fieldValue] of Object.entries(message.fields || {})) {
+    const normalizedFieldName = normalizeProjectFieldName(fieldName);
+    const currentFieldName = currentFieldNames.find(name => name.toLowerCase() === normalizedFieldName.toLowerCase());
+    fields[currentFieldName || normalizedFieldName] = String(fieldValue);
+  }
+
+  return {
+    target: `${contentLabel} in ${message.project}`,
+    url: message.project,
+    before,
+    after: applyStagedUpdate(before, {
+      title: isDraft ? message.draft_title : undefined,
+      body: isDraft ? message.draft_body : undefined,
+      fields,
+    }),
+  };
+}
+
 /**
  * Main entry point - handler factory that returns a message handler function
  * @param {Object} config - Handler configuration
@@ -1146,6 +1274,9 @@ async function main(config = {}, githubClient = null) {
     throw new Error("GitHub client is required but not provided. Either pass a github client to main() or ensure global.github is set by github-script action.");
   }
 
+  // Check if we're in staged mode
+  const isStaged = process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true";
+
   // Extract configuration
   // Default is intentionally configurable via safe-outputs.update-project.max,
   // but we keep a sane global default to avoid surprising truncation.
@@ -1240,6 +1371,21 @@ async function main(config = {}, githubClient = null) {
       // Create effective message with resolved project URL
       const resolvedMessage = { ...message, project: effectiveProjectUrl };
 
+      // In staged mode, preview the update against the current project item instead of applying it
+      if (isStaged) {
+        if (resolvedMessage.operation === "create_fields" || resolvedMessage.operation === "create_view") {
+          core.info(`Staged mode: Would run ${resolvedMessage.operation} on project ${effectiveProjectUrl}`);
+        } else {
+          core.info(`Staged mode: Would update project ${effectiveProjectUrl}`);
+          await writeStagedUpdatePreview("Update Project", await buildProjectStagedPreview(resolvedMessage, temporaryIdMap, github));
+        }
+        return {
+          success: true,
+          skipped: true,
+          reason: "staged_mode",
+        };
+      }
+
       // Store the first project URL for view creation
       if (!firstProjectUrl && effectiveProjectUrl) {
         firstProjectUrl = effectiveProjectUrl;
@@ -1339,4 +1485,4 @@ async function main(config = {}, githubClient = null) {
   };
 }
 
-module.exports = { updateProject, parseProjectInput, main };
+module.exports = { updateProject, parseProjectInput, buildProjectStagedPreview, main };
@@ -1721,3 +1721,67 @@ describe("updateProject", () => {
     expect(result.error).toContain("transient GitHub API error");
   });
 });
+
+describe("update_project staged mode", () => {
+  const projectUrl = "https://github.com/orgs/testowner/projects/60";
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+  });
+
+  afterEach(() => {
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+    mockGithub.graphql.mockReset();
+  });
+
+  it("previews field changes against the current project item without updating it", async () => {
+    mockGithub.graphql.mockResolvedValue({
+      repository: {
+        issueOrPullRequest: {
+          title: "Flaky test in CI",
+          projectItems: {
+            nodes: [
+              {
+                project: { url: "https://github.com/orgs/testowner/projects/7" },
+                fieldValues: { nodes: [{ name: "Done", field: { name: "Status" } }] },
+              },
+              {
+                project: { url: projectUrl },
+                fieldValues: {
+                  nodes: [
+                    { text: "Flaky test in CI", field: { name: "Title" } },
+                    { name: "Todo", field: { name: "Status" } },
+                    { number: 2, field: { name: "Priority" } },
+                  ],
+                },
+              },
+            ],
+          },
+        },
+      },
+    });
+
+    const handler = await updateProjectHandlerFactory({});
+    const result = await handler({ type: "update_project", project: projectUrl, content_type: "issue", content_number: 42, fields: { status: "In Progress", priority: 2 } }, new Map());
+
+    expect(result).toEqual({ success: true, skipped: true, reason: "staged_mode" });
+    expect(mockGithub.graphql).toHaveBeenCalledTimes(1);
+    expect(mockGithub.graphql.mock.calls[0][0]).toContain("issueOrPullRequest");
+
+    const summary = mockCore.summary.addRaw.mock.calls[0][0];
+    expect(summary).toContain(`### Issue #42 in ${projectUrl}`);
+    expect(summary).toContain("| Status | Todo | In Progress |");
+    expect(summary).not.toContain("| Priority |");
+    expect(summary).not.toContain("Done");
+  });
+
+  it("does not write a preview for create_fields operations", async () => {
+    const handler = await updateProjectHandlerFactory({});
+    const result = await handler({ type: "update_project", project: projectUrl, operation: "create_fields", field_definitions: [{ name: "Effort", data_type: "NUMBER" }] }, new Map());
+
+    expect(result.skipped).toBe(true);
+    expect(mockGithub.graphql).not.toHaveBeenCalled();
+    expect(mockCore.summary.addRaw).not.toHaveBeenCalled();
+  });
+});
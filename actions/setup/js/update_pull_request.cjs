@@ -12,6 +12,35 @@ const { updateBody } = require("./update_pr_description_helpers.cjs");
 const { resolveTarget } = require("./safe_output_helpers.cjs");
 const { createUpdateHandlerFactory } = require("./update_handler_factory.cjs");
 const { sanitizeTitle } = require("./sanitize_title.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+const { applyStagedUpdate } = require("./staged_update_diff.cjs");
+
+/**
+ * Build the updated pull request body from the current body and the requested body operation
+ * @param {any} context - GitHub Actions context
+ * @param {string} currentBody - Current pull request body
+ * @param {any} updateData - Data to update
+ * @returns {string} Updated pull request body
+ */
+function buildUpdatedPRBody(context, currentBody, updateData) {
+  // Handle body operation (append/prepend/replace/replace-island)
+  const operation = updateData._operation || "replace";
+
+  // Get workflow run URL for AI attribution
+  const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
+  const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
+  const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
+
+  // Use helper to update body (handles all operations including replace)
+  return updateBody({
+    currentBody,
+    newContent: updateData._rawBody,
+    operation,
+    workflowName,
+    runUrl,
+    workflowId,
+  });
+}
 
 /**
  * Execute the pull request update API call
@@ -22,37 +51,18 @@ const { sanitizeTitle } = require("./sanitize_title.cjs");
  * @returns {Promise<any>} Updated pull request
  */
 async function executePRUpdate(github, context, prNumber, updateData) {
-  // Handle body operation (append/prepend/replace/replace-island)
-  const operation = updateData._operation || "replace";
-  const rawBody = updateData._rawBody;
-
   // Remove internal fields
   const { _operation, _rawBody, ...apiData } = updateData;
 
   // If we have a body, process it with the appropriate operation
-  if (rawBody !== undefined) {
+  if (_rawBody !== undefined) {
     // Fetch current PR body for all operations (needed for append/prepend/replace-island/replace)
     const { data: currentPR } = await github.rest.pulls.get({
       owner: context.repo.owner,
       repo: context.repo.repo,
       pull_number: prNumber,
     });
-    const currentBody = currentPR.body || "";
-
-    // Get workflow run URL for AI attribution
-    const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
-    const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
-    const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
-
-    // Use helper to update body (handles all operations including replace)
-    apiData.body = updateBody({
-      currentBody,
-      newContent: rawBody,
-      operation,
-      workflowName,
-      runUrl,
-      workflowId,
-    });
+    apiData.body = buildUpdatedPRBody(context, currentPR.body || "", updateData);
 
     core.info(`Will update body (length: ${apiData.body.length})`);
   }
@@ -67,6 +77,58 @@ async function executePRUpdate(github, context, prNumber, updateData) {
   return pr;
 }
 
+/**
+ * Fetch the current pull request and build the before/after preview of a staged update
+ * @param {any} github - GitHub API client
+ * @param {any} context - GitHub Actions context
+ * @param {number} prNumber - PR number to update
+ * @param {any} updateData - Data to update
+ * @returns {Promise<import('./staged_update_diff.cjs').StagedUpdatePreview>} Staged update preview
+ */
+async function buildPRStagedPreview(github, context, prNumber, updateData) {
+  let currentPR = null;
+  try {
+    ({ data: currentPR } = await github.rest.pulls.get({
+      owner: context.repo.owner,
+      repo: context.repo.repo,
+      pull_number: prNumber,
+    }));
+  } catch (error) {
+    core.warning(`Failed to fetch pull request #${prNumber} for staged preview: ${getErrorMessage(error)}`);
+  }
+
+  const before = currentPR
+    ? {
+        title: currentPR.title || "",
+        body: currentPR.body || "",
+        labels: (currentPR.labels || []).map(/** @param {any} label */ label => label.name),
+        fields: {
+          State: currentPR.state || "",
+          Base: currentPR.base?.ref || "",
+        },
+      }
+    : null;
+  /** @type {Record<string, string>} */
+  const fields = {};
+  if (updateData.state !== undefined) {
+    fields.State = String(updateData.state);
+  }
+  if (updateData.base !== undefined) {
+    fields.Base = String(updateData.base);
+  }
+
+  return {
+    target: `Pull Request #${prNumber}`,
+    url: currentPR?.html_url,
+    before,
+    after: applyStagedUpdate(before, {
+      title: updateData.title,
+      body: updateData._rawBody !== undefined ? buildUpdatedPRBody(context, before?.body || "", updateData) : undefined,
+      fields,
+    }),
+  };
+}
+
 /**
  * Resolve PR number from message and configuration
  * @param {Object} item - The message item
@@ -169,10 +231,11 @@ const main = createUpdateHandlerFactory({
   buildUpdateData: buildPRUpdateData,
   executeUpdate: executePRUpdate,
   formatSuccessResult: formatPRSuccessResult,
+  buildStagedPreview: buildPRStagedPreview,
   additionalConfig: {
     allow_title: true,
     allow_body: true,
   },
 });
 
-module.exports = { main };
+module.exports = { main, buildPRStagedPreview };
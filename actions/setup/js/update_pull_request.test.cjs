@@ -1,4 +1,4 @@
-import { describe, it, expect, beforeEach, vi } from "vitest";
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
 
 // Import the helper functions we need to test
 let updatePRModule;
@@ -738,3 +738,56 @@ describe("update_pull_request.cjs - executePRUpdate function", () => {
     });
   });
 });
+
+describe("update_pull_request.cjs - staged mode", () => {
+  beforeEach(async () => {
+    vi.clearAllMocks();
+    vi.resetModules();
+    process.env.GH_AW_WORKFLOW_NAME = "Test Workflow";
+    process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+
+    mockGithub.rest.pulls.get.mockResolvedValue({
+      data: {
+        number: 100,
+        title: "Test PR",
+        body: "Original body content",
+        state: "open",
+        labels: [],
+        base: { ref: "main" },
+        html_url: "https://github.com/testowner/testrepo/pull/100",
+      },
+    });
+  });
+
+  afterEach(() => {
+    delete process.env.GH_AW_SAFE_OUTPUTS_STAGED;
+  });
+
+  it("should preview the update against the current pull request without updating it", async () => {
+    const { main } = await import("./update_pull_request.cjs");
+    const handler = await main({});
+
+    const result = await handler({ type: "update_pull_request", title: "Better title", body: "New description", operation: "replace" }, {});
+
+    expect(result.success).toBe(true);
+    expect(result.skipped).toBe(true);
+    expect(mockGithub.rest.pulls.get).toHaveBeenCalledWith({ owner: "testowner", repo: "testrepo", pull_number: 100 });
+    expect(mockGithub.rest.pulls.update).not.toHaveBeenCalled();
+
+    const summary = mockCore.summary.addRaw.mock.calls[0][0];
+    expect(summary).toContain("### Pull Request #100");
+    expect(summary).toContain("| Title | Test PR | Better title |");
+    expect(summary).toContain("-Original body content");
+    expect(summary).toContain("+New description");
+  });
+
+  it("should include state and base changes", async () => {
+    const { buildPRStagedPreview } = await import("./update_pull_request.cjs");
+
+    const preview = await buildPRStagedPreview(mockGithub, mockContext, 100, { state: "closed", base: "develop" });
+
+    expect(preview.before.fields).toEqual({ State: "open", Base: "main" });
+    expect(preview.after.fields).toEqual({ State: "closed", Base: "develop" });
+    expect(preview.after.body).toBe("Original body content");
+  });
+});
@@ -3,6 +3,108 @@
 
 const { getErrorMessage } = require("./error_helpers.cjs");
 const { updateBody } = require("./update_pr_description_helpers.cjs");
+const { applyStagedUpdate, writeStagedUpdatePreview } = require("./staged_update_diff.cjs");
+
+/**
+ * Resolve the tag of the release to update, inferring it from the event context when the
+ * message does not provide one
+ * @param {any} message - The update-release message
+ * @returns {Promise<string>} Release tag
+ */
+async function resolveReleaseTag(message) {
+  // Infer tag from event context if not provided
+  let releaseTag = message.tag;
+  if (!releaseTag) {
+    // Try to get tag from release event context
+    if (context.eventName === "release" && context.payload.release && context.payload.release.tag_name) {
+      releaseTag = context.payload.release.tag_name;
+      core.info(`Inferred release tag from event context: ${releaseTag}`);
+    } else if (context.eventName === "workflow_dispatch" && context.payload.inputs) {
+      // Try to extract from release_url input
+      const releaseUrl = context.payload.inputs.release_url;
+      if (releaseUrl) {
+        const urlMatch = releaseUrl.match(/github\.com\/[^\/]+\/[^\/]+\/releases\/tag\/([^\/\?#]+)/);
+        if (urlMatch && urlMatch[1]) {
+          releaseTag = decodeURIComponent(urlMatch[1]);
+          core.info(`Inferred release tag from release_url input: ${releaseTag}`);
+        }
+      }
+      // Try to fetch from release_id input
+      if (!releaseTag && context.payload.inputs.release_id) {
+        const releaseId = context.payload.inputs.release_id;
+        core.info(`Fetching release with ID: ${releaseId}`);
+        const { data: release } = await github.rest.repos.getRelease({
+          owner: context.repo.owner,
+          repo: context.repo.repo,
+          release_id: parseInt(releaseId, 10),
+        });
+        releaseTag = release.tag_name;
+        core.info(`Inferred release tag from release_id input: ${releaseTag}`);
+      }
+    }
+
+    if (!releaseTag) {
+      throw new Error("Release tag is required but not provided and cannot be inferred from event context");
+    }
+  }
+
+  return releaseTag;
+}
+
+/**
+ * Fetch the current release and build the before/after preview of a staged update
+ * @param {any} message - The update-release message
+ * @param {boolean} includeFooter - Whether the AI-generated footer is added to the body
+ * @returns {Promise<import('./staged_update_diff.cjs').StagedUpdatePreview>} Staged update preview
+ */
+async function buildReleaseStagedPreview(message, includeFooter) {
+  let release = null;
+  try {
+    const releaseTag = await resolveReleaseTag(message);
+    ({ data: release } = await github.rest.repos.getReleaseByTag({
+      owner: context.repo.owner,
+      repo: context.repo.repo,
+      tag: releaseTag,
+    }));
+  } catch (error) {
+    core.warning(`Failed to fetch release for staged preview: ${getErrorMessage(error)}`);
+  }
+
+  const before = release ? { title: release.name || "", body: release.body || "" } : null;
+  return {
+    target: `Release ${release?.tag_name || message.tag || "(inferred)"}`,
+    url: release?.html_url,
+    before,
+    after: applyStagedUpdate(before, {
+      body: message.body !== undefined ? buildUpdatedReleaseBody(before?.body || "", message, includeFooter) : undefined,
+    }),
+  };
+}
+
+/**
+ * Build the updated release body from the current body and the requested body operation
+ * @param {string} currentBody - Current release body
+ * @param {any} message - The update-release message
+ * @param {boolean} includeFooter - Whether the AI-generated footer is added to the body
+ * @returns {string} Updated release body
+ */
+function buildUpdatedReleaseBody(currentBody, message, includeFooter) {
+  // Get workflow run URL for AI attribution
+  const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
+  const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
+  const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
+
+  // Use shared helper to update body based on operation
+  return updateBody({
+    currentBody,
+    newContent: message.body,
+    operation: message.operation || "append",
+    workflowName,
+    runUrl,
+    workflowId,
+    includeFooter, // Pass footer flag to helper
+  });
+}
 
 /**
  * Create a handler for update-release messages
@@ -16,7 +118,6 @@ const { updateBody } = require("./update_pr_description_helpers.cjs");
 async function main(config = {}) {
   // Check if we're in staged mode
   const isStaged = process.env.GH_AW_SAFE_OUTPUTS_STAGED === "true";
-  const workflowName = process.env.GH_AW_WORKFLOW_NAME || "GitHub Agentic Workflow";
   const includeFooter = config.footer !== false; // Default to true (include footer)
 
   /**
@@ -26,50 +127,17 @@ async function main(config = {}) {
    * @returns {Promise<Object>} Result with release info
    */
   return async function handleUpdateRelease(message, resolvedTemporaryIds = {}) {
-    // In staged mode, skip actual processing (preview is handled elsewhere)
+    // In staged mode, preview the update against the current release instead of applying it
     if (isStaged) {
       core.info(`Staged mode: Would update release with tag ${message.tag || "(inferred)"}`);
+      await writeStagedUpdatePreview("Update Release", await buildReleaseStagedPreview(message, includeFooter));
       return { skipped: true, reason: "staged_mode" };
     }
 
     core.info(`Processing update-release message`);
 
     try {
-      // Infer tag from event context if not provided
-      let releaseTag = message.tag;
-      if (!releaseTag) {
-        // Try to get tag from release event context
-        if (context.eventName === "release" && context.payload.release && context.payload.release.tag_name) {
-          releaseTag = context.payload.release.tag_name;
-          core.info(`Inferred release tag from event context: ${releaseTag}`);
-        } else if (context.eventName === "workflow_dispatch" && context.payload.inputs) {
-          // Try to extract from release_url input
-          const releaseUrl = context.payload.inputs.release_url;
-          if (releaseUrl) {
-            const urlMatch = releaseUrl.match(/github\.com\/[^\/]+\/[^\/]+\/releases\/tag\/([^\/\?#]+)/);
-            if (urlMatch && urlMatch[1]) {
-              releaseTag = decodeURIComponent(urlMatch[1]);
-              core.info(`Inferred release tag from release_url input: ${releaseTag}`);
-            }
-          }
-          // Try to fetch from release_id input
-          if (!releaseTag && context.payload.inputs.release_id) {
-            const releaseId = context.payload.inputs.release_id;
-            core.info(`Fetching release with ID: ${releaseId}`);
-            const { data: release } = await github.rest.repos.getRelease({
-              owner: context.repo.owner,
-              repo: context.repo.repo,
-              release_id: parseInt(releaseId, 10),
-            });
-            releaseTag = release.tag_name;
-            core.info(`Inferred release tag from release_id input: ${releaseTag}`);
-          }
-        }
-
-        if (!releaseTag) {
-          throw new Error("Release tag is required but not provided and cannot be inferred from event context");
-        }
-      }
+      const releaseTag = await resolveReleaseTag(message);
 
       // Get the release by tag
       core.info(`Fetching release with tag: ${releaseTag}`);
@@ -81,20 +149,7 @@ async function main(config = {}) {
 
       core.info(`Found release: ${release.name || release.tag_name} (ID: ${release.id})`);
 
-      // Get workflow run URL for AI attribution
-      const runUrl = `${context.serverUrl}/${context.repo.owner}/${context.repo.repo}/actions/runs/${context.runId}`;
-      const workflowId = process.env.GH_AW_WORKFLOW_ID || "";
-
-      // Use shared helper to update body based on operation
-      const newBody = updateBody({
-        currentBody: release.body || "",
-        newContent: message.body,
-        operation: message.operation || "append",
-        workflowName,
-        runUrl,
-        workflowId,
-        includeFooter, // Pass footer flag to helper
-      });
+      const newBody = buildUpdatedReleaseBody(release.body || "", message, includeFooter);
 
       // Update the release
       const { data: updatedRelease } = await github.rest.repos.updateRelease({
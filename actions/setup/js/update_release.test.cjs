@@ -89,12 +89,27 @@ const mockCore = { debug: vi.fn(), info: vi.fn(), warning: vi.fn(), error: vi.fn
       }),
       it("should handle staged mode", async () => {
         process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+        const mockRelease = { id: 1, tag_name: "v1.0.0", name: "Release v1.0.0", body: "Old notes", html_url: "https://github.com/test-owner/test-repo/releases/tag/v1.0.0" };
+        mockGithub.rest.repos.getReleaseByTag.mockResolvedValue({ data: mockRelease });
         const message = { type: "update_release", tag: "v1.0.0", operation: "replace", body: "New notes" };
         const result = await eval(`(async () => { ${updateReleaseScript}; const handler = await main(); return await handler(${JSON.stringify(message)}); })()`);
         expect(result.skipped).toBe(true);
         expect(result.reason).toBe("staged_mode");
-        expect(mockGithub.rest.repos.getReleaseByTag).not.toHaveBeenCalled();
+        expect(mockGithub.rest.repos.getReleaseByTag).toHaveBeenCalledWith({ owner: "test-owner", repo: "test-repo", tag: "v1.0.0" });
         expect(mockGithub.rest.repos.updateRelease).not.toHaveBeenCalled();
+        const summary = mockCore.summary.addRaw.mock.calls[0][0];
+        expect(summary).toContain("### Release v1.0.0");
+        expect(summary).toContain("-Old notes");
+        expect(summary).toContain("+New notes");
+      }),
+      it("should preview staged mode when the release cannot be fetched", async () => {
+        process.env.GH_AW_SAFE_OUTPUTS_STAGED = "true";
+        mockGithub.rest.repos.getReleaseByTag.mockRejectedValue(new Error("Not Found"));
+        const message = { type: "update_release", tag: "v9.9.9", operation: "replace", body: "New notes" };
+        const result = await eval(`(async () => { ${updateReleaseScript}; const handler = await main(); return await handler(${JSON.stringify(message)}); })()`);
+        expect(result.skipped).toBe(true);
+        expect(mockCore.warning).toHaveBeenCalledWith(expect.stringContaining("Failed to fetch release for staged preview"));
+        expect(mockCore.summary.addRaw.mock.calls[0][0]).toContain("The current state could not be fetched");
       }),
       it("should handle release not found error", async () => {
         mockGithub.rest.repos.getReleaseByTag.mockRejectedValue(new Error("Not Found"));
@@ -0,0 +1,150 @@
+// @ts-check
+
+/**
+ * Agent Budget Watcher
+ *
+ * Started in the background by start_budget_watcher.sh before the agent runs. It polls the
+ * agent log and, as soon as the usage goes over a frontmatter budget limit, records the usage
+ * and stops the agent so that the run does not keep spending until it finishes on its own.
+ * check_agent_budget.cjs stops the watcher after the agent step and reports the result.
+ */
+
+const fs = require("fs");
+const path = require("path");
+const { execFileSync } = require("child_process");
+const { extractUsage, evaluateBudget, readAgentLog, readLimits, BUDGET_WATCHER_PID_PATH, BUDGET_STOP_PATH } = require("./check_agent_budget.cjs");
+const { getErrorMessage } = require("./error_helpers.cjs");
+
+const DEFAULT_INTERVAL_MS = 5000;
+
+// Written by the agent execution step with the PID of its shell (see recordAgentStepPID in budget.go)
+const AGENT_STEP_PID_PATH = "/tmp/gh-aw/agent-step.pid";
+
+/**
+ * Builds the pkill pattern that matches a process by its executable name, either at the
+ * start of the command line or as a separate word (e.g. "sudo -E awf ...")
+ * @param {string} name - Process name (e.g. "claude" or "awf")
+ * @returns {string} Extended regular expression for pkill -f
+ */
+function agentProcessPattern(name) {
+  const escaped = name.replace(/[.*+?^${}()|[\]\\]/g, "\\$&");
+  return `(^|[ /])${escaped}( |$)`;
+}
+
+/**
+ * Reads the PID of the shell of the agent execution step
+ * @param {string} pidPath - PID file written when the agent step starts
+ * @returns {number | null} PID, or null if the agent step has not started
+ */
+function readAgentStepPid(pidPath) {
+  try {
+    const pid = parseInt(fs.readFileSync(pidPath, "utf8").trim(), 10);
+    return pid > 0 ? pid : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Stops the agent by sending SIGTERM to the processes matching its name that were started by the
+ * agent execution step, so that unrelated processes with the same name are left alone. Firewalled
+ * agents run as root inside awf, so sudo is tried first.
+ * @param {string} name - Process name
+ * @param {string} [pidPath] - PID file of the agent step shell
+ * @returns {boolean} True if a process was signalled
+ */
+function stopAgentProcess(name, pidPath = AGENT_STEP_PID_PATH) {
+  const stepPid = readAgentStepPid(pidPath);
+  if (stepPid === null) {
+    console.log(`No agent step PID in ${pidPath}`);
+    return false;
+  }
+  const args = ["-TERM", "-P", String(stepPid), "-f", agentProcessPattern(name)];
+  for (const [command, commandArgs] of [
+    ["sudo", ["-n", "pkill", ...args]],
+    ["pkill", args],
+  ]) {
+    try {
+      execFileSync(command, commandArgs, { stdio: "ignore" });
+      return true;
+    } catch {
+      // No matching process, or sudo is not available
+    }
+  }
+  return false;
+}
+
+/**
+ * Polls the agent log until the usage exceeds the budget, then stops the agent and writes
+ * the stop record. Resolves with the record.
+ * @param {Object} options
+ * @param {import('./check_agent_budget.cjs').BudgetLimits} options.limits - Budget limits
+ * @param {string} options.logPath - Agent log file or directory
+ * @param {string} options.agentProcess - Name of the process to stop; empty if the agent cannot be identified
+ * @param {string} options.stopPath - Where to write the stop record
+ * @param {number} [options.intervalMs] - Poll interval
+ * @param {(name: string) => boolean} [options.stopAgent] - Stops the agent process
+ * @returns {Promise<{usage: import('./check_agent_budget.cjs').BudgetUsage, exceeded: string[], stopped: boolean}>} Stop record
+ */
+async function watchBudget({ limits, logPath, agentProcess, stopPath, intervalMs = DEFAULT_INTERVAL_MS, stopAgent = stopAgentProcess }) {
+  for (;;) {
+    await new Promise(resolve => setTimeout(resolve, intervalMs));
+
+    let usage;
+    try {
+      usage = extractUsage(readAgentLog(logPath));
+    } catch (error) {
+      console.log(`Failed to read agent log: ${getErrorMessage(error)}`);
+      continue;
+    }
+
+    const exceeded = evaluateBudget(limits, usage);
+    if (exceeded.length === 0) {
+      continue;
+    }
+
+    console.log(`Agent exceeded its budget (${exceeded.join(", ")}): ${JSON.stringify(usage)}`);
+    let stopped = false;
+    if (agentProcess) {
+      stopped = stopAgent(agentProcess);
+      console.log(stopped ? `Stopped agent process '${agentProcess}'` : `No running agent process matched '${agentProcess}'`);
+    } else {
+      console.log("The agent process cannot be identified for this engine; the budget is enforced after the run");
+    }
+
+    const record = { usage, exceeded, stopped };
+    fs.mkdirSync(path.dirname(stopPath), { recursive: true });
+    fs.writeFileSync(stopPath, JSON.stringify(record, null, 2));
+    return record;
+  }
+}
+
+/**
+ * Runs the watcher with the configuration from the environment
+ */
+async function main() {
+  const limits = readLimits();
+  if (Object.keys(limits).length === 0) {
+    console.log("No budget limits configured");
+    return;
+  }
+
+  fs.mkdirSync(path.dirname(BUDGET_WATCHER_PID_PATH), { recursive: true });
+  fs.writeFileSync(BUDGET_WATCHER_PID_PATH, String(process.pid));
+
+  await watchBudget({
+    limits,
+    logPath: process.env.GH_AW_AGENT_OUTPUT || "",
+    agentProcess: process.env.GH_AW_BUDGET_AGENT_PROCESS || "",
+    stopPath: BUDGET_STOP_PATH,
+  });
+}
+
+if (require.main === module) {
+  main().catch(error => {
+    console.error(`Budget watcher failed: ${getErrorMessage(error)}`);
+    process.exit(1);
+  });
+}
+
+module.exports = { main, watchBudget, agentProcessPattern, readAgentStepPid, stopAgentProcess };
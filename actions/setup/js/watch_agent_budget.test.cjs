@@ -0,0 +1,79 @@
+// @ts-check
+
+import { describe, it, expect, beforeEach, afterEach, vi } from "vitest";
+import fs from "fs";
+import path from "path";
+import os from "os";
+
+const { watchBudget, agentProcessPattern, readAgentStepPid, stopAgentProcess } = require("./watch_agent_budget.cjs");
+
+describe("watch_agent_budget", () => {
+  let tempDir;
+
+  beforeEach(() => {
+    tempDir = fs.mkdtempSync(path.join(os.tmpdir(), "budget-watcher-test-"));
+    vi.spyOn(console, "log").mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    fs.rmSync(tempDir, { recursive: true, force: true });
+    vi.restoreAllMocks();
+  });
+
+  const assistantEntry = tokens => JSON.stringify({ type: "assistant", message: { usage: { input_tokens: tokens, output_tokens: 0 } } });
+
+  it("matches the process name as a separate word", () => {
+    const pattern = new RegExp(agentProcessPattern("awf"));
+    expect(pattern.test("sudo -E awf --env-all -- claude")).toBe(true);
+    expect(pattern.test("/usr/local/bin/awf --env-all")).toBe(true);
+    expect(pattern.test("node /tmp/awful.js")).toBe(false);
+  });
+
+  it("escapes regular expression characters in the process name", () => {
+    expect(agentProcessPattern("my.agent")).toBe("(^|[ /])my\\.agent( |$)");
+  });
+
+  it("reads the PID of the agent step shell", () => {
+    const pidPath = path.join(tempDir, "agent-step.pid");
+    expect(readAgentStepPid(pidPath)).toBeNull();
+    fs.writeFileSync(pidPath, "4242\n");
+    expect(readAgentStepPid(pidPath)).toBe(4242);
+    fs.writeFileSync(pidPath, "not a pid");
+    expect(readAgentStepPid(pidPath)).toBeNull();
+  });
+
+  it("does not signal anything before the agent step has started", () => {
+    expect(stopAgentProcess("claude", path.join(tempDir, "missing.pid"))).toBe(false);
+  });
+
+  it("stops the agent once the log goes over budget", async () => {
+    const logPath = path.join(tempDir, "agent-stdio.log");
+    const stopPath = path.join(tempDir, "budget-stopped.json");
+    fs.writeFileSync(logPath, assistantEntry(400) + "\n");
+    const stopAgent = vi.fn().mockReturnValue(true);
+
+    const watching = watchBudget({ limits: { "max-tokens": 1000 }, logPath, agentProcess: "claude", stopPath, intervalMs: 5, stopAgent });
+    await new Promise(resolve => setTimeout(resolve, 20));
+    expect(stopAgent).not.toHaveBeenCalled();
+
+    fs.appendFileSync(logPath, assistantEntry(800) + "\n");
+    const record = await watching;
+
+    expect(stopAgent).toHaveBeenCalledWith("claude");
+    expect(record).toEqual({ usage: { tokens: 1200, cost_usd: 0, turns: 2 }, exceeded: ["max-tokens"], stopped: true });
+    expect(JSON.parse(fs.readFileSync(stopPath, "utf8"))).toEqual(record);
+  });
+
+  it("records the overrun without stopping anything when the agent cannot be identified", async () => {
+    const logPath = path.join(tempDir, "agent-stdio.log");
+    const stopPath = path.join(tempDir, "budget-stopped.json");
+    fs.writeFileSync(logPath, [assistantEntry(1), assistantEntry(1), assistantEntry(1)].join("\n"));
+    const stopAgent = vi.fn();
+
+    const record = await watchBudget({ limits: { "max-turns": 2 }, logPath, agentProcess: "", stopPath, intervalMs: 1, stopAgent });
+
+    expect(stopAgent).not.toHaveBeenCalled();
+    expect(record.stopped).toBe(false);
+    expect(record.exceeded).toEqual(["max-turns"]);
+  });
+});
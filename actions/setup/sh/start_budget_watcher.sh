@@ -0,0 +1,12 @@
+#!/usr/bin/env bash
+# Start the agent budget watcher
+# Runs watch_agent_budget.cjs in the background while the agent executes so that a run going
+# over its budget is stopped early. check_agent_budget.cjs stops the watcher after the agent step.
+
+set -e
+
+mkdir -p /tmp/gh-aw
+
+nohup node /opt/gh-aw/actions/watch_agent_budget.cjs >> /tmp/gh-aw/budget-watcher.log 2>&1 &
+
+echo "Started agent budget watcher with PID $!"
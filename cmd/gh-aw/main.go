@@ -42,8 +42,8 @@ func formatListWithOr(items []string) string {
 
 // validateEngine validates the engine flag value
 func validateEngine(engine string) error {
-	// Get the global engine registry
-	registry := workflow.GetGlobalEngineRegistry()
+	// Get the engine registry, including engines defined in the repository
+	registry := workflow.GetRepositoryEngineRegistry()
 	validEngines := registry.GetSupportedEngines()
 
 	if engine != "" && !registry.IsValidEngine(engine) {
@@ -240,7 +240,9 @@ Examples:
   ` + string(constants.CLIExtensionPrefix) + ` compile --watch ci-doctor     # Watch and auto-compile
   ` + string(constants.CLIExtensionPrefix) + ` compile --trial --logical-repo owner/repo  # Compile for trial mode
   ` + string(constants.CLIExtensionPrefix) + ` compile --dependabot        # Generate Dependabot manifests
-  ` + string(constants.CLIExtensionPrefix) + ` compile --dependabot --force  # Force overwrite existing dependabot.yml`,
+  ` + string(constants.CLIExtensionPrefix) + ` compile --dependabot --force  # Force overwrite existing dependabot.yml
+  ` + string(constants.CLIExtensionPrefix) + ` compile scout --explain-trigger event.json  # Explain which jobs run for an event
+  ` + string(constants.CLIExtensionPrefix) + ` compile --zizmor --sarif results.sarif  # Export findings for code scanning`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		engineOverride, _ := cmd.Flags().GetString("engine")
 		actionMode, _ := cmd.Flags().GetString("action-mode")
@@ -265,6 +267,8 @@ Examples:
 		fix, _ := cmd.Flags().GetBool("fix")
 		stats, _ := cmd.Flags().GetBool("stats")
 		failFast, _ := cmd.Flags().GetBool("fail-fast")
+		explainTrigger, _ := cmd.Flags().GetString("explain-trigger")
+		sarifFile, _ := cmd.Flags().GetString("sarif")
 		noCheckUpdate, _ := cmd.Flags().GetBool("no-check-update")
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		if err := validateEngine(engineOverride); err != nil {
@@ -317,6 +321,8 @@ Examples:
 			JSONOutput:             jsonOutput,
 			Stats:                  stats,
 			FailFast:               failFast,
+			ExplainTrigger:         explainTrigger,
+			SARIFFile:              sarifFile,
 		}
 		if _, err := cli.CompileWorkflows(cmd.Context(), config); err != nil {
 			// Return error as-is without additional formatting
@@ -359,7 +365,14 @@ Examples:
   gh aw run daily-perf-improver --auto-merge-prs # Auto-merge any PRs created during execution
   gh aw run daily-perf-improver -f name=value -f env=prod  # Pass workflow inputs
   gh aw run daily-perf-improver --push  # Commit and push workflow files before running
-  gh aw run daily-perf-improver --dry-run  # Validate without actually running`,
+  gh aw run daily-perf-improver --dry-run  # Validate without actually running
+  gh aw run daily-perf-improver --local --transcript session.jsonl  # Run locally with a recorded transcript
+  gh aw run daily-perf-improver --local --agent-command ./fake-agent.sh  # Run locally with a stand-in command
+
+With --local, the compiled .lock.yml is executed on this machine instead of GitHub Actions.
+The activation, agent, and safe output jobs run in order, the engine is replaced by a
+recorded transcript (--transcript) or a local command (--agent-command), and safe outputs
+are written as staged previews to a local directory instead of calling the GitHub API.`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repeatCount, _ := cmd.Flags().GetInt("repeat")
@@ -372,11 +385,33 @@ Examples:
 		inputs, _ := cmd.Flags().GetStringArray("raw-field")
 		push, _ := cmd.Flags().GetBool("push")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		local, _ := cmd.Flags().GetBool("local")
+		transcript, _ := cmd.Flags().GetString("transcript")
+		agentCommand, _ := cmd.Flags().GetString("agent-command")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
 
 		if err := validateEngine(engineOverride); err != nil {
 			return err
 		}
 
+		// Local offline execution does not touch GitHub Actions
+		if local {
+			// The engine is always replaced by a stand-in, so an engine override would be silently ignored
+			if repoOverride != "" || refOverride != "" || engineOverride != "" || push || pushSecrets || autoMergePRs || enable || dryRun || repeatCount > 0 {
+				return fmt.Errorf("--local cannot be combined with --repo, --ref, --engine, --push, --use-local-secrets, --auto-merge-prs, --enable-if-needed, --dry-run, or --repeat")
+			}
+			return cli.RunWorkflowsLocally(cmd.Context(), args, cli.LocalRunConfig{
+				Transcript:   transcript,
+				AgentCommand: agentCommand,
+				OutputDir:    outputDir,
+				Inputs:       inputs,
+				Verbose:      verboseFlag,
+			})
+		}
+		if transcript != "" || agentCommand != "" || outputDir != "" {
+			return fmt.Errorf("--transcript, --agent-command, and --output-dir require --local")
+		}
+
 		// If no arguments provided, enter interactive mode
 		if len(args) == 0 {
 			// Check if running in CI environment
@@ -548,6 +583,8 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	compileCmd.Flags().BoolP("json", "j", false, "Output results in JSON format")
 	compileCmd.Flags().Bool("stats", false, "Display statistics table sorted by file size (shows jobs, steps, scripts, and shells)")
 	compileCmd.Flags().Bool("fail-fast", false, "Stop at the first validation error instead of collecting all errors")
+	compileCmd.Flags().String("explain-trigger", "", "Show which jobs would run or be skipped, and why, for the webhook event payload in the given JSON file")
+	compileCmd.Flags().String("sarif", "", "Write validation errors and security scanner findings to the given SARIF 2.1.0 file for code scanning")
 	compileCmd.Flags().Bool("no-check-update", false, "Skip checking for gh-aw updates")
 	compileCmd.MarkFlagsMutuallyExclusive("dir", "workflows-dir")
 
@@ -581,6 +618,10 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	runCmd.Flags().StringArrayP("raw-field", "F", []string{}, "Add a string parameter in key=value format (can be used multiple times)")
 	runCmd.Flags().Bool("push", false, "Commit and push workflow files (including transitive imports) before running")
 	runCmd.Flags().Bool("dry-run", false, "Validate workflow without actually triggering execution on GitHub Actions")
+	runCmd.Flags().Bool("local", false, "Run the compiled workflow on this machine with an engine stand-in and staged safe outputs")
+	runCmd.Flags().String("transcript", "", "Recorded safe output transcript (JSONL or agent_output.json) to replay in place of the engine (requires --local)")
+	runCmd.Flags().String("agent-command", "", "Local command to run in place of the engine; receives GH_AW_PROMPT and GH_AW_SAFE_OUTPUTS (requires --local)")
+	runCmd.Flags().String("output-dir", "", "Directory for local run artifacts; must be new, empty or from a previous local run (requires --local, default: .github/aw/logs/local/<workflow>)")
 	// Register completions for run command
 	runCmd.ValidArgsFunction = cli.CompleteWorkflowNames
 	cli.RegisterEngineFlagCompletion(runCmd)
@@ -596,10 +637,16 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	logsCmd := cli.NewLogsCommand()
 	auditCmd := cli.NewAuditCommand()
 	healthCmd := cli.NewHealthCommand()
+	networkCmd := cli.NewNetworkCommand()
+	scheduleCmd := cli.NewScheduleCommand()
+	diffCmd := cli.NewDiffCommand()
 	mcpServerCmd := cli.NewMCPServerCommand()
+	lspCmd := cli.NewLSPCommand()
 	prCmd := cli.NewPRCommand()
 	secretsCmd := cli.NewSecretsCommand()
 	fixCmd := cli.NewFixCommand()
+	testCmd := cli.NewTestCommand()
+	verifyCmd := cli.NewVerifyCommand()
 	upgradeCmd := cli.NewUpgradeCommand()
 	completionCmd := cli.NewCompletionCommand()
 	hashCmd := cli.NewHashCommand()
@@ -621,6 +668,8 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	statusCmd.GroupID = "development"
 	listCmd.GroupID = "development"
 	fixCmd.GroupID = "development"
+	testCmd.GroupID = "development"
+	verifyCmd.GroupID = "development"
 
 	// Execution Commands
 	runCmd.GroupID = "execution"
@@ -632,9 +681,13 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	logsCmd.GroupID = "analysis"
 	auditCmd.GroupID = "analysis"
 	healthCmd.GroupID = "analysis"
+	networkCmd.GroupID = "analysis"
+	scheduleCmd.GroupID = "analysis"
+	diffCmd.GroupID = "analysis"
 
 	// Utilities
 	mcpServerCmd.GroupID = "utilities"
+	lspCmd.GroupID = "utilities"
 	prCmd.GroupID = "utilities"
 	completionCmd.GroupID = "utilities"
 	hashCmd.GroupID = "utilities"
@@ -659,12 +712,18 @@ Use "` + string(constants.CLIExtensionPrefix) + ` help all" to show help for all
 	rootCmd.AddCommand(logsCmd)
 	rootCmd.AddCommand(auditCmd)
 	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(networkCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(diffCmd)
 	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(mcpServerCmd)
+	rootCmd.AddCommand(lspCmd)
 	rootCmd.AddCommand(prCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(secretsCmd)
 	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(verifyCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(hashCmd)
 	rootCmd.AddCommand(projectCmd)
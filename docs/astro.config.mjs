@@ -216,6 +216,7 @@ export default defineConfig({
 						{ label: 'Markdown', link: '/reference/markdown/' },
 						{ label: 'MCP Gateway', link: '/reference/mcp-gateway/' },
 						{ label: 'Network Access', link: '/reference/network/' },
+						{ label: 'Organization Policy', link: '/reference/policy/' },
 						{ label: 'Permissions', link: '/reference/permissions/' },
 						{ label: 'Rate Limiting Controls', link: '/reference/rate-limiting-controls/' },
 						{ label: 'Safe Inputs', link: '/reference/safe-inputs/' },
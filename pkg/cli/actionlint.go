@@ -391,6 +391,21 @@ func parseAndDisplayActionlintOutput(stdout string, verbose bool) (int, map[stri
 		}
 
 		fmt.Fprint(os.Stderr, console.FormatError(compilerErr))
+
+		kind := err.Kind
+		if kind == "" {
+			kind = "error"
+		}
+		recordSARIFFinding(sarifFinding{
+			RuleID:      "actionlint/" + kind,
+			Description: fmt.Sprintf("actionlint %s check", kind),
+			HelpURI:     getActionlintDocsURL(err.Kind),
+			Level:       sarifLevel(errorType),
+			Message:     err.Message,
+			File:        err.Filepath,
+			Line:        err.Line,
+			Column:      err.Column,
+		})
 	}
 
 	return totalErrors, errorsByKind, nil
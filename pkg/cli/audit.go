@@ -162,9 +162,23 @@ func AuditWorkflowRun(ctx context.Context, runID int64, owner, repo, hostname st
 	// Check if we have locally cached artifacts first
 	hasLocalCache := fileutil.DirExists(runOutputDir) && !fileutil.IsDirEmpty(runOutputDir)
 
-	// Try to get run metadata from GitHub API
-	run, metadataErr := fetchWorkflowRunMetadata(runID, owner, repo, hostname, verbose)
+	// Completed runs recorded in the run index are audited from the local cache without
+	// calling the GitHub API; other runs get their metadata from the API
+	indexedRun, indexed := lookupIndexedRun(outputDir, runID)
+	var run WorkflowRun
+	var metadataErr error
 	var useLocalCache bool
+	if hasLocalCache && indexed && indexedRun.Status == "completed" {
+		auditLog.Printf("Using run metadata for run %d from the run index", runID)
+		run = indexedRun
+		run.LogsPath = runOutputDir
+		useLocalCache = true
+		if verbose {
+			fmt.Fprintln(os.Stderr, console.FormatInfoMessage("Using run metadata and artifacts from the local run index."))
+		}
+	} else {
+		run, metadataErr = fetchWorkflowRunMetadata(runID, owner, repo, hostname, verbose)
+	}
 
 	if metadataErr != nil {
 		// Check if it's a permission error
@@ -221,7 +235,15 @@ func AuditWorkflowRun(ctx context.Context, runID int64, owner, repo, hostname st
 		}
 	}
 
-	// If using local cache without metadata, create a minimal run structure
+	// If using local cache without metadata, restore the metadata recorded in the run index
+	if useLocalCache && run.DatabaseID == 0 && indexed {
+		auditLog.Printf("Restored run metadata for run %d from the run index", runID)
+		run = indexedRun
+		run.LogsPath = runOutputDir
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage("Using run metadata from the local run index."))
+	}
+
+	// If there is still no metadata, create a minimal run structure
 	if useLocalCache && run.DatabaseID == 0 {
 		run = WorkflowRun{
 			DatabaseID:   runID,
@@ -292,6 +314,12 @@ func AuditWorkflowRun(ctx context.Context, runID int64, owner, repo, hostname st
 		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to extract MCP failures: %v", err)))
 	}
 
+	// Extract patch paths rejected by the safe output path restrictions
+	patchRejections, err := extractPatchRejectionsFromRun(runOutputDir)
+	if err != nil && verbose {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to extract patch rejections: %v", err)))
+	}
+
 	// Analyze access logs if available
 	accessAnalysis, err := analyzeAccessLogs(runOutputDir, verbose)
 	if err != nil && verbose {
@@ -331,6 +359,7 @@ func AuditWorkflowRun(ctx context.Context, runID int64, owner, repo, hostname st
 		MissingData:             missingData,
 		Noops:                   noops,
 		MCPFailures:             mcpFailures,
+		PatchRejections:         patchRejections,
 		JobDetails:              jobDetails,
 	}
 
@@ -21,6 +21,7 @@ type AuditData struct {
 	KeyFindings             []Finding                `json:"key_findings,omitempty"`
 	Recommendations         []Recommendation         `json:"recommendations,omitempty"`
 	FailureAnalysis         *FailureAnalysis         `json:"failure_analysis,omitempty"`
+	Budget                  *BudgetData              `json:"budget,omitempty"`
 	PerformanceMetrics      *PerformanceMetrics      `json:"performance_metrics,omitempty"`
 	Jobs                    []JobData                `json:"jobs,omitempty"`
 	DownloadedFiles         []FileInfo               `json:"downloaded_files"`
@@ -28,6 +29,7 @@ type AuditData struct {
 	MissingData             []MissingDataReport      `json:"missing_data,omitempty"`
 	Noops                   []NoopReport             `json:"noops,omitempty"`
 	MCPFailures             []MCPFailureReport       `json:"mcp_failures,omitempty"`
+	PatchRejections         []PatchRejectionReport   `json:"patch_rejections,omitempty"`
 	FirewallAnalysis        *FirewallAnalysis        `json:"firewall_analysis,omitempty"`
 	RedactedDomainsAnalysis *RedactedDomainsAnalysis `json:"redacted_domains_analysis,omitempty"`
 	Errors                  []ErrorInfo              `json:"errors,omitempty"`
@@ -61,6 +63,22 @@ type FailureAnalysis struct {
 	RootCause      string   `json:"root_cause,omitempty"` // Identified root cause if determinable
 }
 
+// BudgetData shows the run's consumption against the frontmatter budget
+type BudgetData struct {
+	Exceeded     bool               `json:"exceeded"`
+	StoppedEarly bool               `json:"stopped_early,omitempty"` // The budget watcher stopped the agent during the run
+	Limits       []BudgetLimitUsage `json:"limits"`
+}
+
+// BudgetLimitUsage shows consumption against a single budget limit
+type BudgetLimitUsage struct {
+	Limit       string  `json:"limit"`        // e.g. "max-tokens"
+	Used        float64 `json:"used"`         // Measured usage
+	Max         float64 `json:"max"`          // Configured limit
+	PercentUsed float64 `json:"percent_used"` // Used as a percentage of Max
+	Exceeded    bool    `json:"exceeded"`
+}
+
 // PerformanceMetrics provides aggregated performance statistics
 type PerformanceMetrics struct {
 	TokensPerMinute float64 `json:"tokens_per_minute,omitempty"`
@@ -296,6 +314,13 @@ func buildAuditData(processedRun ProcessedRun, metrics LogMetrics, mcpToolUsage
 		failureAnalysis = generateFailureAnalysis(processedRun, errors)
 	}
 
+	// Load the budget report written by the budget enforcement step (if the workflow has a budget)
+	budget := loadBudgetData(run.LogsPath)
+	if budget != nil && budget.Exceeded && failureAnalysis != nil {
+		failureAnalysis.PrimaryFailure = workflow.BudgetExceededClassification
+		failureAnalysis.RootCause = "Agent exceeded its budget: " + describeExceededBudget(budget)
+	}
+
 	// Generate performance metrics
 	performanceMetrics := generatePerformanceMetrics(processedRun, metricsData, toolUsage)
 
@@ -310,6 +335,7 @@ func buildAuditData(processedRun ProcessedRun, metrics LogMetrics, mcpToolUsage
 		KeyFindings:             findings,
 		Recommendations:         recommendations,
 		FailureAnalysis:         failureAnalysis,
+		Budget:                  budget,
 		PerformanceMetrics:      performanceMetrics,
 		Jobs:                    jobs,
 		DownloadedFiles:         downloadedFiles,
@@ -317,6 +343,7 @@ func buildAuditData(processedRun ProcessedRun, metrics LogMetrics, mcpToolUsage
 		MissingData:             processedRun.MissingData,
 		Noops:                   processedRun.Noops,
 		MCPFailures:             processedRun.MCPFailures,
+		PatchRejections:         processedRun.PatchRejections,
 		FirewallAnalysis:        processedRun.FirewallAnalysis,
 		RedactedDomainsAnalysis: processedRun.RedactedDomainsAnalysis,
 		Errors:                  errors,
@@ -389,6 +416,7 @@ func describeFile(filename string) string {
 		"firewall.md":                 "Firewall log analysis report",
 		"run_summary.json":            "Cached summary of workflow run analysis",
 		"prompt.txt":                  "Input prompt for AI agent",
+		"budget.json":                 "Budget consumption against the frontmatter limits",
 	}
 
 	if desc, ok := descriptions[filename]; ok {
@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/github/gh-aw/pkg/console"
 	"github.com/github/gh-aw/pkg/timeutil"
+	"github.com/github/gh-aw/pkg/workflow"
 )
 
 // generateFindings creates key findings from workflow run data
@@ -103,6 +107,25 @@ func generateFindings(processedRun ProcessedRun, metrics MetricsData, errors []E
 		})
 	}
 
+	// Patch path restriction findings
+	if len(processedRun.PatchRejections) > 0 {
+		paths := make([]string, 0, min(3, len(processedRun.PatchRejections)))
+		for i := 0; i < len(processedRun.PatchRejections) && i < 3; i++ {
+			paths = append(paths, processedRun.PatchRejections[i].Path)
+		}
+		desc := fmt.Sprintf("Rejected paths: %s", strings.Join(paths, ", "))
+		if len(processedRun.PatchRejections) > 3 {
+			desc += fmt.Sprintf(" (and %d more)", len(processedRun.PatchRejections)-3)
+		}
+		findings = append(findings, Finding{
+			Category:    "security",
+			Severity:    "high",
+			Title:       "Patch Rejected by Path Restrictions",
+			Description: desc,
+			Impact:      "The agent modified files outside allowed-paths or in blocked or protected paths, so its changes were not applied",
+		})
+	}
+
 	// Missing tool findings
 	if len(processedRun.MissingTools) > 0 {
 		toolNames := make([]string, 0, min(3, len(processedRun.MissingTools)))
@@ -199,6 +222,16 @@ func generateRecommendations(processedRun ProcessedRun, metrics MetricsData, fin
 		})
 	}
 
+	// Recommendations for rejected patch paths
+	if len(processedRun.PatchRejections) > 0 {
+		recommendations = append(recommendations, Recommendation{
+			Priority: "high",
+			Action:   "Tell the agent which files it may change, or extend allowed-paths if the changes are intended",
+			Reason:   "Patches touching rejected paths are never applied",
+			Example:  fmt.Sprintf("Rejected %s: %s", processedRun.PatchRejections[0].Path, processedRun.PatchRejections[0].Reason),
+		})
+	}
+
 	// Recommendations for missing tools
 	if len(processedRun.MissingTools) > 0 {
 		recommendations = append(recommendations, Recommendation{
@@ -365,3 +398,74 @@ func generatePerformanceMetrics(processedRun ProcessedRun, metrics MetricsData,
 
 	return pm
 }
+
+// loadBudgetData reads the budget report uploaded with the agent artifacts.
+// Returns nil if the workflow has no budget or the report is missing.
+func loadBudgetData(logsPath string) *BudgetData {
+	if logsPath == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(logsPath, filepath.Base(workflow.BudgetReportPath)))
+	if err != nil {
+		return nil
+	}
+
+	var report workflow.BudgetReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		auditReportLog.Printf("Failed to parse budget report: %v", err)
+		return nil
+	}
+
+	return buildBudgetData(&report)
+}
+
+// buildBudgetData converts a budget report into consumption against each configured limit
+func buildBudgetData(report *workflow.BudgetReport) *BudgetData {
+	exceeded := make(map[string]bool, len(report.Exceeded))
+	for _, name := range report.Exceeded {
+		exceeded[name] = true
+	}
+
+	data := &BudgetData{Exceeded: report.IsExceeded(), StoppedEarly: report.StoppedEarly}
+	addLimit := func(name string, used, limit float64) {
+		if limit <= 0 {
+			return
+		}
+		data.Limits = append(data.Limits, BudgetLimitUsage{
+			Limit:       name,
+			Used:        used,
+			Max:         limit,
+			PercentUsed: used / limit * 100,
+			Exceeded:    exceeded[name],
+		})
+	}
+	addLimit("max-tokens", float64(report.Usage.Tokens), float64(report.Limits.MaxTokens))
+	addLimit("max-cost-usd", report.Usage.CostUSD, report.Limits.MaxCostUSD)
+	addLimit("max-turns", float64(report.Usage.Turns), float64(report.Limits.MaxTurns))
+
+	if len(data.Limits) == 0 {
+		return nil
+	}
+	auditReportLog.Printf("Loaded budget report: %d limits, exceeded=%v", len(data.Limits), data.Exceeded)
+	return data
+}
+
+// formatBudgetValue formats a budget usage or limit value for display
+func formatBudgetValue(limit string, value float64) string {
+	if limit == "max-cost-usd" {
+		return fmt.Sprintf("$%.4f", value)
+	}
+	return console.FormatNumber(int(value))
+}
+
+// describeExceededBudget summarizes the exceeded limits, e.g. "max-tokens (1.2M / 1M)"
+func describeExceededBudget(budget *BudgetData) string {
+	var parts []string
+	for _, limit := range budget.Limits {
+		if limit.Exceeded {
+			parts = append(parts, fmt.Sprintf("%s (%s / %s)", limit.Limit, formatBudgetValue(limit.Limit, limit.Used), formatBudgetValue(limit.Limit, limit.Max)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
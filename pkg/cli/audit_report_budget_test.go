@@ -0,0 +1,69 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBudgetData(t *testing.T) {
+	tmpDir := testutil.TempDir(t, "audit-budget-test-*")
+	report := `{
+  "limits": {"max-tokens": 100000, "max-cost-usd": 0.5},
+  "usage": {"tokens": 150000, "cost_usd": 0.25, "turns": 12},
+  "exceeded": ["max-tokens"],
+  "stopped_early": true
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "budget.json"), []byte(report), 0644))
+
+	budget := loadBudgetData(tmpDir)
+	require.NotNil(t, budget, "budget report should be loaded")
+	assert.True(t, budget.Exceeded)
+	assert.True(t, budget.StoppedEarly, "the watcher stopping the agent should be reported")
+	require.Len(t, budget.Limits, 2, "only configured limits should be listed")
+
+	assert.Equal(t, "max-tokens", budget.Limits[0].Limit)
+	assert.InDelta(t, 150.0, budget.Limits[0].PercentUsed, 0.001)
+	assert.True(t, budget.Limits[0].Exceeded)
+
+	assert.Equal(t, "max-cost-usd", budget.Limits[1].Limit)
+	assert.InDelta(t, 50.0, budget.Limits[1].PercentUsed, 0.001)
+	assert.False(t, budget.Limits[1].Exceeded)
+
+	assert.Equal(t, "max-tokens (150k / 100k)", describeExceededBudget(budget))
+}
+
+func TestLoadBudgetDataMissing(t *testing.T) {
+	assert.Nil(t, loadBudgetData(""), "empty logs path should not load a budget")
+	assert.Nil(t, loadBudgetData(testutil.TempDir(t, "audit-budget-missing-*")), "workflows without a budget have no report")
+}
+
+func TestBuildAuditDataBudgetExceeded(t *testing.T) {
+	tmpDir := testutil.TempDir(t, "audit-budget-failure-*")
+	report := `{"limits":{"max-turns":10},"usage":{"tokens":0,"cost_usd":0,"turns":25},"exceeded":["max-turns"]}`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "budget.json"), []byte(report), 0644))
+
+	processedRun := ProcessedRun{
+		Run: WorkflowRun{
+			DatabaseID:   42,
+			WorkflowName: "Budgeted",
+			Status:       "completed",
+			Conclusion:   "failure",
+			LogsPath:     tmpDir,
+		},
+	}
+
+	auditData := buildAuditData(processedRun, workflow.LogMetrics{Turns: 25}, nil)
+
+	require.NotNil(t, auditData.Budget, "budget section should be present")
+	require.NotNil(t, auditData.FailureAnalysis, "failed run should have failure analysis")
+	assert.Equal(t, workflow.BudgetExceededClassification, auditData.FailureAnalysis.PrimaryFailure)
+	assert.Contains(t, auditData.FailureAnalysis.RootCause, "max-turns (25 / 10)")
+}
@@ -53,6 +53,13 @@ func renderConsole(data AuditData, logsPath string) {
 		renderFailureAnalysis(data.FailureAnalysis)
 	}
 
+	// Budget Section
+	if data.Budget != nil {
+		fmt.Fprintln(os.Stderr, console.FormatSectionHeader("Budget"))
+		fmt.Fprintln(os.Stderr)
+		renderBudget(data.Budget)
+	}
+
 	// Performance Metrics Section - NEW
 	if data.PerformanceMetrics != nil {
 		fmt.Fprintln(os.Stderr, console.FormatSectionHeader("Performance Metrics"))
@@ -112,6 +119,17 @@ func renderConsole(data AuditData, logsPath string) {
 		fmt.Fprintln(os.Stderr)
 	}
 
+	// Patch Rejections Section
+	if len(data.PatchRejections) > 0 {
+		fmt.Fprintln(os.Stderr, console.FormatSectionHeader("Rejected Patch Paths"))
+		fmt.Fprintln(os.Stderr)
+		for _, rejection := range data.PatchRejections {
+			fmt.Fprintf(os.Stderr, "  • %s (%s)\n", rejection.Path, rejection.SafeOutput)
+			fmt.Fprintf(os.Stderr, "    Reason: %s\n", rejection.Reason)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
 	// Firewall Analysis Section
 	if data.FirewallAnalysis != nil && data.FirewallAnalysis.TotalRequests > 0 {
 		fmt.Fprintln(os.Stderr, console.FormatSectionHeader("Firewall Analysis"))
@@ -539,6 +557,34 @@ func renderFailureAnalysis(analysis *FailureAnalysis) {
 	}
 }
 
+// renderBudget renders consumption against each budget limit
+func renderBudget(budget *BudgetData) {
+	config := console.TableConfig{
+		Headers: []string{"Limit", "Used", "Max", "% Used", "Status"},
+		Rows:    make([][]string, 0, len(budget.Limits)),
+	}
+
+	for _, limit := range budget.Limits {
+		status := "ok"
+		if limit.Exceeded {
+			status = "exceeded"
+		}
+		config.Rows = append(config.Rows, []string{
+			limit.Limit,
+			formatBudgetValue(limit.Limit, limit.Used),
+			formatBudgetValue(limit.Limit, limit.Max),
+			fmt.Sprintf("%.0f%%", limit.PercentUsed),
+			status,
+		})
+	}
+
+	fmt.Fprint(os.Stderr, console.RenderTable(config))
+	if budget.StoppedEarly {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("The budget watcher stopped the agent during the run"))
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
 // renderPerformanceMetrics renders performance metrics
 func renderPerformanceMetrics(metrics *PerformanceMetrics) {
 	if metrics.TokensPerMinute > 0 {
@@ -16,6 +16,8 @@
 //
 // Compiler Creation:
 //   - createAndConfigureCompiler() - Creates compiler with full configuration
+//   - newInMemoryCompiler() - Creates compiler for analyses that don't write lock files
+//   - compileInMemory() - Compiles a workflow with an in-memory compiler
 //
 // Configuration:
 //   - configureCompilerFlags() - Sets validation, strict mode, trial mode flags
@@ -29,6 +31,7 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -179,6 +182,41 @@ func setupActionMode(compiler *workflow.Compiler, actionMode string, actionTag s
 	}
 }
 
+// newInMemoryCompiler creates a compiler that doesn't write lock files, for commands that analyze
+// the compiled workflows of a directory. Fuzzy schedules are scattered with the same
+// per-directory plan as compile.
+func newInMemoryCompiler(gitRoot string, verbose bool) *workflow.Compiler {
+	compiler := workflow.NewCompiler(
+		workflow.WithNoEmit(true),
+		workflow.WithGitRoot(gitRoot),
+		workflow.WithVerbose(verbose),
+	)
+	compiler.SetQuiet(true)
+	compiler.SetSkipValidation(true)
+	return compiler
+}
+
+// compileInMemory compiles a workflow with an in-memory compiler. Shared workflows return nil data.
+func compileInMemory(compiler *workflow.Compiler, gitRoot, file string) (*workflow.WorkflowData, string, error) {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return nil, "", err
+	}
+	if relPath, err := filepath.Rel(gitRoot, absPath); err == nil {
+		compiler.SetWorkflowIdentifier(filepath.ToSlash(relPath))
+	}
+
+	data, lockYAML, err := compiler.CompileWorkflowToYAML(absPath)
+	if err != nil {
+		var sharedErr *workflow.SharedWorkflowError
+		if errors.As(err, &sharedErr) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return data, lockYAML, nil
+}
+
 // setupRepositoryContext sets the repository slug for schedule scattering
 func setupRepositoryContext(compiler *workflow.Compiler) {
 	compileCompilerSetupLog.Print("Setting up repository context")
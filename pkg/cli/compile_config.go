@@ -33,6 +33,8 @@ type CompileConfig struct {
 	ActionTag              string   // Override action SHA or tag for actions/setup (overrides action-mode to release)
 	Stats                  bool     // Display statistics table sorted by file size
 	FailFast               bool     // Stop at first error instead of collecting all errors
+	ExplainTrigger         string   // Event payload file to simulate job conditions against
+	SARIFFile              string   // Write compile-time findings and security scan results to a SARIF file
 }
 
 // WorkflowFailure represents a failed workflow with its error count
@@ -0,0 +1,219 @@
+// This file implements compile --explain-trigger, which shows for a sample webhook payload which
+// jobs of the compiled workflows would run or be skipped, and why.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/sliceutil"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/workflow"
+)
+
+var compileExplainTriggerLog = logger.New("cli:compile_explain_trigger")
+
+// loadTriggerEvent reads an event file. The file is either a webhook payload, whose event name is
+// inferred from its shape, or an object of the form {"event_name": "...", "event": {...}}.
+func loadTriggerEvent(path string) (workflow.TriggerEvent, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return workflow.TriggerEvent{}, fmt.Errorf("failed to read event file: %w", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(content, &payload); err != nil {
+		return workflow.TriggerEvent{}, fmt.Errorf("failed to parse event file %s: %w", path, err)
+	}
+
+	if name, ok := payload["event_name"].(string); ok {
+		if event, ok := payload["event"].(map[string]any); ok {
+			return workflow.TriggerEvent{Name: name, Payload: event}, nil
+		}
+	}
+
+	name := inferEventName(payload)
+	if name == "" {
+		return workflow.TriggerEvent{}, fmt.Errorf(`cannot infer the event name from %s; wrap the payload as {"event_name": "issues", "event": {...}}`, path)
+	}
+	compileExplainTriggerLog.Printf("Inferred event name %s from payload", name)
+	return workflow.TriggerEvent{Name: name, Payload: payload}, nil
+}
+
+// inferEventName infers the event name of a webhook payload from its top-level keys
+func inferEventName(payload map[string]any) string {
+	has := func(key string) bool {
+		_, ok := payload[key]
+		return ok
+	}
+	switch {
+	case has("comment") && has("discussion"):
+		return "discussion_comment"
+	case has("comment") && has("pull_request"):
+		return "pull_request_review_comment"
+	case has("comment") && has("issue"):
+		return "issue_comment"
+	case has("review") && has("pull_request"):
+		return "pull_request_review"
+	case has("pull_request"):
+		return "pull_request"
+	case has("issue"):
+		return "issues"
+	case has("discussion"):
+		return "discussion"
+	case has("release"):
+		return "release"
+	case has("workflow_run"):
+		return "workflow_run"
+	case has("check_run"):
+		return "check_run"
+	case has("check_suite"):
+		return "check_suite"
+	case has("inputs"):
+		return "workflow_dispatch"
+	case has("schedule"):
+		return "schedule"
+	case has("ref") && (has("commits") || has("head_commit")):
+		return "push"
+	}
+	return ""
+}
+
+// explainTriggerLockFiles returns the lock files to explain: those of the given workflows, or all
+// lock files in the workflow directory. With --no-emit, all workflows in the directory are explained
+// whether or not they have a lock file yet.
+func explainTriggerLockFiles(config CompileConfig) ([]string, error) {
+	if len(config.MarkdownFiles) > 0 {
+		var lockFiles []string
+		for _, file := range config.MarkdownFiles {
+			resolvedFile, err := resolveWorkflowFile(file, false)
+			if err != nil {
+				continue // Resolution errors are reported by compilation
+			}
+			lockFiles = append(lockFiles, stringutil.MarkdownToLockFile(resolvedFile))
+		}
+		return lockFiles, nil
+	}
+
+	workflowDir := config.WorkflowDir
+	if workflowDir == "" {
+		workflowDir = ".github/workflows"
+	}
+	gitRoot, err := findGitRoot()
+	if err != nil {
+		return nil, err
+	}
+	if !config.NoEmit {
+		return filepath.Glob(filepath.Join(gitRoot, workflowDir, "*.lock.yml"))
+	}
+
+	mdFiles, err := filepath.Glob(filepath.Join(gitRoot, workflowDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	var lockFiles []string
+	for _, file := range filterWorkflowFiles(mdFiles) {
+		lockFiles = append(lockFiles, stringutil.MarkdownToLockFile(file))
+	}
+	return lockFiles, nil
+}
+
+// explainTriggerLockYAML returns the compiled workflow of a lock file. Without a compiler the lock
+// file is read from disk; with --no-emit the workflow is compiled in memory from its markdown file.
+// Shared workflows return nil content.
+func explainTriggerLockYAML(compiler *workflow.Compiler, gitRoot, lockFile string) ([]byte, error) {
+	if compiler == nil {
+		return os.ReadFile(lockFile)
+	}
+	_, lockYAML, err := compileInMemory(compiler, gitRoot, stringutil.LockFileToMarkdown(lockFile))
+	if err != nil || lockYAML == "" {
+		return nil, err
+	}
+	return []byte(lockYAML), nil
+}
+
+// explainTrigger simulates the compiled workflows against the event file and displays which jobs
+// would run
+func explainTrigger(config CompileConfig) error {
+	event, err := loadTriggerEvent(config.ExplainTrigger)
+	if err != nil {
+		return err
+	}
+	lockFiles, err := explainTriggerLockFiles(config)
+	if err != nil {
+		return err
+	}
+	compileExplainTriggerLog.Printf("Explaining %s event for %d lock files (no-emit=%v)", event.Name, len(lockFiles), config.NoEmit)
+
+	var compiler *workflow.Compiler
+	var gitRoot string
+	if config.NoEmit {
+		if gitRoot, err = findGitRoot(); err != nil {
+			return err
+		}
+		compiler = newInMemoryCompiler(gitRoot, config.Verbose)
+	}
+
+	for _, lockFile := range lockFiles {
+		content, err := explainTriggerLockYAML(compiler, gitRoot, lockFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Skipping %s: %v", filepath.Base(lockFile), err)))
+			continue
+		}
+		if content == nil {
+			continue
+		}
+		simulation, err := workflow.SimulateTrigger(content, event)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Skipping %s: %v", filepath.Base(lockFile), err)))
+			continue
+		}
+		displayTriggerSimulation(filepath.Base(lockFile), simulation, config.Verbose)
+	}
+	return nil
+}
+
+// displayTriggerSimulation renders the simulated jobs of one workflow
+func displayTriggerSimulation(lockFile string, simulation *workflow.TriggerSimulation, verbose bool) {
+	fmt.Fprintln(os.Stderr, "")
+	if !simulation.Triggered {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("%s: not triggered by %s (%s)", lockFile, simulation.Event, simulation.Reason)))
+		return
+	}
+
+	rows := make([][]string, 0, len(simulation.Jobs))
+	for _, job := range simulation.Jobs {
+		reasons := job.Reasons
+		if !verbose && len(reasons) > 3 {
+			reasons = append(reasons[:3:3], fmt.Sprintf("... %d more (use --verbose)", len(job.Reasons)-3))
+		}
+		rows = append(rows, []string{job.Job, string(job.Outcome), strings.Join(reasons, "\n")})
+	}
+	fmt.Fprint(os.Stderr, console.RenderTable(console.TableConfig{
+		Title:   fmt.Sprintf("%s: %s event", lockFile, simulation.Event),
+		Headers: []string{"JOB", "OUTCOME", "WHY"},
+		Rows:    rows,
+	}))
+
+	// Explain the job outputs that outcomes depend on, e.g. the role check of the pre-activation job
+	jobOutputs := make(map[string]string)
+	for _, job := range simulation.Jobs {
+		for output, runtime := range job.Outputs {
+			jobOutputs[fmt.Sprintf("needs.%s.outputs.%s", job.Job, output)] = runtime
+		}
+	}
+	var explained []string
+	for _, job := range simulation.Jobs {
+		for _, reference := range job.Runtime {
+			if runtime, ok := jobOutputs[reference]; ok && !sliceutil.Contains(explained, reference) {
+				explained = append(explained, reference)
+				fmt.Fprintln(os.Stderr, console.FormatListItem(fmt.Sprintf("%s is decided at runtime by %s", reference, runtime)))
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferEventName(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  map[string]any
+		expected string
+	}{
+		{name: "issue comment", payload: map[string]any{"comment": map[string]any{}, "issue": map[string]any{}}, expected: "issue_comment"},
+		{name: "review comment", payload: map[string]any{"comment": map[string]any{}, "pull_request": map[string]any{}}, expected: "pull_request_review_comment"},
+		{name: "discussion comment", payload: map[string]any{"comment": map[string]any{}, "discussion": map[string]any{}}, expected: "discussion_comment"},
+		{name: "review", payload: map[string]any{"review": map[string]any{}, "pull_request": map[string]any{}}, expected: "pull_request_review"},
+		{name: "pull request", payload: map[string]any{"pull_request": map[string]any{}}, expected: "pull_request"},
+		{name: "issues", payload: map[string]any{"issue": map[string]any{}}, expected: "issues"},
+		{name: "workflow dispatch", payload: map[string]any{"inputs": map[string]any{}}, expected: "workflow_dispatch"},
+		{name: "push", payload: map[string]any{"ref": "refs/heads/main", "commits": []any{}}, expected: "push"},
+		{name: "unknown", payload: map[string]any{"sender": map[string]any{}}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inferEventName(tt.payload))
+		})
+	}
+}
+
+func TestLoadTriggerEvent(t *testing.T) {
+	dir := t.TempDir()
+
+	payloadFile := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadFile, []byte(`{"action": "opened", "issue": {"number": 1}}`), 0644))
+	event, err := loadTriggerEvent(payloadFile)
+	require.NoError(t, err)
+	assert.Equal(t, "issues", event.Name)
+	assert.Equal(t, "opened", event.Payload["action"])
+
+	wrappedFile := filepath.Join(dir, "wrapped.json")
+	require.NoError(t, os.WriteFile(wrappedFile, []byte(`{"event_name": "schedule", "event": {"schedule": "0 9 * * *"}}`), 0644))
+	event, err = loadTriggerEvent(wrappedFile)
+	require.NoError(t, err)
+	assert.Equal(t, "schedule", event.Name)
+	assert.Equal(t, "0 9 * * *", event.Payload["schedule"])
+
+	eventNameFile := filepath.Join(dir, "event_name.json")
+	require.NoError(t, os.WriteFile(eventNameFile, []byte(`{"event_name": "custom", "action": "opened", "issue": {"number": 1}}`), 0644))
+	event, err = loadTriggerEvent(eventNameFile)
+	require.NoError(t, err)
+	assert.Equal(t, "issues", event.Name, "a payload without an event object should not be unwrapped")
+	assert.Equal(t, "opened", event.Payload["action"])
+
+	unknownFile := filepath.Join(dir, "unknown.json")
+	require.NoError(t, os.WriteFile(unknownFile, []byte(`{"sender": {}}`), 0644))
+	_, err = loadTriggerEvent(unknownFile)
+	require.ErrorContains(t, err, "cannot infer the event name")
+
+	_, err = loadTriggerEvent(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestValidateCompileConfigExplainTrigger(t *testing.T) {
+	err := validateCompileConfig(CompileConfig{ExplainTrigger: "event.json", Watch: true})
+	require.ErrorContains(t, err, "--explain-trigger")
+	assert.NoError(t, validateCompileConfig(CompileConfig{ExplainTrigger: "event.json"}))
+	assert.NoError(t, validateCompileConfig(CompileConfig{ExplainTrigger: "event.json", NoEmit: true}))
+}
+
+func TestExplainTriggerLockYAMLNoEmit(t *testing.T) {
+	gitRoot := t.TempDir()
+	workflowPath := filepath.Join(gitRoot, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened]
+permissions:
+  contents: read
+engine: copilot
+---
+
+# Triage
+`), 0644))
+	lockFile := filepath.Join(gitRoot, "triage.lock.yml")
+
+	_, err := explainTriggerLockYAML(nil, gitRoot, lockFile)
+	require.Error(t, err, "without --no-emit the lock file is read from disk")
+
+	content, err := explainTriggerLockYAML(newInMemoryCompiler(gitRoot, false), gitRoot, lockFile)
+	require.NoError(t, err)
+	assert.NoFileExists(t, lockFile, "--no-emit should not write the lock file")
+
+	simulation, err := workflow.SimulateTrigger(content, workflow.TriggerEvent{Name: "issues", Payload: map[string]any{"action": "opened"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, simulation.Jobs)
+}
@@ -498,6 +498,7 @@ func outputResults(
 			statsList = collectWorkflowStatisticsWrapper(config.MarkdownFiles)
 		}
 		formatStatsTable(statsList)
+		displayScheduleHistogram(collectRepositoryScheduleHistogram(config.WorkflowDir))
 	}
 
 	// Output JSON if requested
@@ -517,5 +518,12 @@ func outputResults(
 		formatActionlintOutput()
 	}
 
+	// Simulate the compiled workflows against the event payload if requested
+	if config.ExplainTrigger != "" {
+		if err := explainTrigger(config); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -41,6 +41,12 @@ func CompileWorkflows(ctx context.Context, config CompileConfig) ([]*workflow.Wo
 		initActionlintStats()
 	}
 
+	// Collect findings for SARIF export if requested
+	sarifReport = nil
+	if config.SARIFFile != "" {
+		initSARIFReport()
+	}
+
 	// Track compilation statistics
 	stats := &CompilationStats{}
 
@@ -81,11 +87,22 @@ func CompileWorkflows(ctx context.Context, config CompileConfig) ([]*workflow.Wo
 	}
 
 	// Compile specific files or all files in directory
+	var workflowDataList []*workflow.WorkflowData
+	var err error
 	if len(config.MarkdownFiles) > 0 {
 		// Compile specific workflow files
-		return compileSpecificFiles(compiler, config, stats, &validationResults)
+		workflowDataList, err = compileSpecificFiles(compiler, config, stats, &validationResults)
+	} else {
+		// Compile all workflow files in directory
+		workflowDataList, err = compileAllFilesInDirectory(compiler, config, workflowDir, stats, &validationResults)
+	}
+
+	// Write the SARIF log even when compilation failed, so that the failures are reported too
+	if config.SARIFFile != "" {
+		if sarifErr := writeSARIFReport(config.SARIFFile); sarifErr != nil && err == nil {
+			err = sarifErr
+		}
 	}
 
-	// Compile all workflow files in directory
-	return compileAllFilesInDirectory(compiler, config, workflowDir, stats, &validationResults)
+	return workflowDataList, err
 }
@@ -0,0 +1,559 @@
+// This file provides SARIF export for compile-time validations and security scans.
+//
+// # SARIF Export
+//
+// `gh aw compile --sarif <file>` merges the findings of every compile-time check
+// into a single SARIF 2.1.0 log that can be uploaded to GitHub code scanning:
+//
+//   - Compilation errors (expression safety, template injection, strict mode,
+//     organization policy and all other validation errors) and compiler warnings
+//   - Markdown security scan findings (ScanMarkdownSecurity)
+//   - zizmor, poutine and actionlint findings on the generated lock files
+//
+// Findings are recorded while compiling through recordSARIFFinding, which is a
+// no-op unless initSARIFReport was called, in the same way actionlint statistics
+// are tracked. Findings in a .lock.yml file are mapped back to the source
+// markdown file when the offending line also appears there (for example custom
+// steps copied from frontmatter), and to the top of the markdown file otherwise.
+// The lock file position is kept as a related location.
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/workflow"
+)
+
+var compileSARIFLog = logger.New("cli:compile_sarif")
+
+const (
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+	sarifToolName  = "gh-aw"
+	sarifToolURI   = "https://github.github.com/gh-aw/"
+)
+
+// sarifLog is the root object of a SARIF 2.1.0 log file
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties    `json:"properties"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifFinding is a single finding from a compile-time check
+type sarifFinding struct {
+	RuleID      string // Rule identifier, prefixed with the tool name for external scanners
+	Description string // Short description of the rule
+	HelpURI     string // Documentation for the rule
+	Level       string // SARIF level: error, warning or note
+	Message     string
+	File        string // Absolute or repository-relative path
+	Line        int
+	Column      int
+	LockFile    string // Original lock file position when the finding was mapped to the markdown source
+	LockLine    int
+}
+
+// sarifFindings accumulates findings across all compiled workflows
+type sarifFindings struct {
+	gitRoot  string
+	findings []sarifFinding
+}
+
+// sarifReport collects findings for --sarif; nil when SARIF export is disabled
+var sarifReport *sarifFindings
+
+// compileSARIFRules describes the rules used for compiler findings
+var compileSARIFRules = map[string]struct{ description, helpURI string }{
+	"gh-aw/compile-error":      {"Workflow compilation error", "https://github.github.com/gh-aw/reference/frontmatter/"},
+	"gh-aw/compile-warning":    {"Workflow compilation warning", "https://github.github.com/gh-aw/reference/frontmatter/"},
+	"gh-aw/expression-safety":  {"Unauthorized expression in workflow markdown", "https://github.github.com/gh-aw/reference/templating/"},
+	"gh-aw/template-injection": {"Untrusted expression used in a shell command", "https://github.github.com/gh-aw/reference/templating/"},
+	"gh-aw/strict-mode":        {"Strict mode violation", "https://github.github.com/gh-aw/reference/frontmatter/#strict-mode-strict"},
+}
+
+// compilerPositionPattern matches the "file:line:column: error:" prefix of formatted compiler errors
+var compilerPositionPattern = regexp.MustCompile(`^(.+?):(\d+):(\d+): (?:error|warning): `)
+
+// aggregatedErrorsPattern matches the header of errors aggregated by ErrorCollector.FormattedError
+var aggregatedErrorsPattern = regexp.MustCompile(`^Found \d+ .+ errors:$`)
+
+// initSARIFReport enables collection of findings for SARIF export
+func initSARIFReport() {
+	gitRoot, err := findGitRoot()
+	if err != nil {
+		compileSARIFLog.Printf("Not in a git repository, using working directory as root: %v", err)
+		gitRoot, _ = os.Getwd()
+	}
+	sarifReport = &sarifFindings{gitRoot: gitRoot}
+}
+
+// recordSARIFFinding records a finding for SARIF export when --sarif is enabled
+func recordSARIFFinding(finding sarifFinding) {
+	if sarifReport == nil {
+		return
+	}
+	if !filepath.IsAbs(finding.File) {
+		finding.File = filepath.Join(sarifReport.gitRoot, finding.File)
+	}
+	if strings.HasSuffix(finding.File, ".lock.yml") {
+		finding = mapLockFileFinding(finding)
+	}
+	compileSARIFLog.Printf("Recording finding: rule=%s, file=%s, line=%d", finding.RuleID, finding.File, finding.Line)
+	sarifReport.findings = append(sarifReport.findings, finding)
+}
+
+// absoluteSARIFPath resolves a path of a compiled workflow, which is relative to the working
+// directory rather than the repository root used by the external scanners
+func absoluteSARIFPath(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// sarifLevel converts a console error type to a SARIF level
+func sarifLevel(errorType string) string {
+	switch errorType {
+	case "error":
+		return "error"
+	case "info":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// recordCompileErrorFindings records a compilation error of a workflow. Joined and aggregated
+// errors and policy violations are recorded individually; other errors are classified by the
+// validation that failed.
+func recordCompileErrorFindings(markdownPath string, err error) {
+	if sarifReport == nil {
+		return
+	}
+	markdownPath = absoluteSARIFPath(markdownPath)
+
+	var joinedErr interface{ Unwrap() []error }
+	if errors.As(err, &joinedErr) {
+		for _, joined := range joinedErr.Unwrap() {
+			recordCompileErrorFindings(markdownPath, joined)
+		}
+		return
+	}
+
+	var policyErr *workflow.PolicyViolationError
+	if errors.As(err, &policyErr) {
+		for _, violation := range policyErr.Violations {
+			recordSARIFFinding(sarifFinding{
+				RuleID:      "gh-aw/policy/" + violation.Rule,
+				Description: fmt.Sprintf("Organization policy rule '%s'", violation.Rule),
+				HelpURI:     "https://github.github.com/gh-aw/reference/policy/",
+				Level:       "error",
+				Message:     violation.Message,
+				File:        markdownPath,
+				Line:        violation.Line,
+				Column:      violation.Column,
+			})
+		}
+		return
+	}
+
+	var validationErr *workflow.WorkflowValidationError
+	if errors.As(err, &validationErr) && validationErr.Field == "expressions" {
+		recordExpressionSafetyFindings(markdownPath, validationErr)
+		return
+	}
+
+	file, line, column, message := parseCompilerErrorPosition(err)
+	if file == "" {
+		file = markdownPath
+	}
+	file = absoluteSARIFPath(file)
+
+	for _, message := range splitAggregatedErrors(message) {
+		ruleID := "gh-aw/compile-error"
+		switch {
+		case strings.Contains(message, "template injection"):
+			ruleID = "gh-aw/template-injection"
+		case strings.HasPrefix(message, "strict mode:"):
+			ruleID = "gh-aw/strict-mode"
+		}
+
+		rule := compileSARIFRules[ruleID]
+		recordSARIFFinding(sarifFinding{
+			RuleID:      ruleID,
+			Description: rule.description,
+			HelpURI:     rule.helpURI,
+			Level:       "error",
+			Message:     message,
+			File:        file,
+			Line:        line,
+			Column:      column,
+		})
+	}
+}
+
+// splitAggregatedErrors returns the individual errors of a message aggregated by
+// ErrorCollector.FormattedError, or the message itself
+func splitAggregatedErrors(message string) []string {
+	header, items, found := strings.Cut(message, "\n")
+	if !found || !aggregatedErrorsPattern.MatchString(header) {
+		return []string{message}
+	}
+	var messages []string
+	for _, item := range strings.Split(strings.TrimPrefix(items, "  • "), "\n  • ") {
+		if item = strings.TrimSpace(item); item != "" {
+			messages = append(messages, item)
+		}
+	}
+	if len(messages) == 0 {
+		return []string{message}
+	}
+	return messages
+}
+
+// recordCompileWarningFindings records the compiler warnings of a workflow at the top of its markdown file
+func recordCompileWarningFindings(markdownPath string, warnings []string) {
+	if sarifReport == nil {
+		return
+	}
+	markdownPath = absoluteSARIFPath(markdownPath)
+	rule := compileSARIFRules["gh-aw/compile-warning"]
+	for _, message := range warnings {
+		recordSARIFFinding(sarifFinding{
+			RuleID:      "gh-aw/compile-warning",
+			Description: rule.description,
+			HelpURI:     rule.helpURI,
+			Level:       "warning",
+			Message:     stringutil.StripANSIEscapeCodes(message),
+			File:        markdownPath,
+			Line:        1,
+			Column:      1,
+		})
+	}
+}
+
+// recordExpressionSafetyFindings records each unauthorized expression at its first use in the markdown
+func recordExpressionSafetyFindings(markdownPath string, validationErr *workflow.WorkflowValidationError) {
+	content, _ := os.ReadFile(markdownPath)
+	rule := compileSARIFRules["gh-aw/expression-safety"]
+
+	for _, location := range locateUnauthorizedExpressions(string(content), validationErr) {
+		recordSARIFFinding(sarifFinding{
+			RuleID:      "gh-aw/expression-safety",
+			Description: rule.description,
+			HelpURI:     rule.helpURI,
+			Level:       "error",
+			Message:     fmt.Sprintf("expression '%s' is not allowed in workflow markdown", location.expression),
+			File:        markdownPath,
+			Line:        location.line,
+			Column:      location.column,
+		})
+	}
+}
+
+// unauthorizedExpression is an expression rejected by expression safety validation and its 1-based position
+type unauthorizedExpression struct {
+	expression string
+	line       int
+	column     int
+}
+
+// locateUnauthorizedExpressions finds where each expression listed by an expression safety
+// error is used in the workflow content. Expressions that cannot be found are placed at line 1.
+func locateUnauthorizedExpressions(content string, validationErr *workflow.WorkflowValidationError) []unauthorizedExpression {
+	lines := strings.Split(content, "\n")
+	var locations []unauthorizedExpression
+	for _, reasonLine := range strings.Split(validationErr.Reason, "\n") {
+		expression, found := strings.CutPrefix(reasonLine, "  - ")
+		if !found {
+			continue
+		}
+		location := unauthorizedExpression{expression: expression, line: 1, column: 1}
+		for i, text := range lines {
+			if index := strings.Index(text, expression); index >= 0 && strings.Contains(text, "${{") {
+				location.line, location.column = i+1, index+1
+				break
+			}
+		}
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+// parseCompilerErrorPosition extracts the position and plain message from a formatted compiler error.
+// Errors without a position are reported at the first line of the workflow.
+func parseCompilerErrorPosition(err error) (file string, line int, column int, message string) {
+	text := stringutil.StripANSIEscapeCodes(err.Error())
+	line, column = 1, 1
+
+	match := compilerPositionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", line, column, strings.TrimSpace(text)
+	}
+
+	file = match[1]
+	line, _ = strconv.Atoi(match[2])
+	column, _ = strconv.Atoi(match[3])
+	message = strings.TrimSpace(text[len(match[0]):])
+
+	// formatCompilerError appends the wrapped cause after the formatted message; prefer the cause alone
+	if cause := errors.Unwrap(err); cause != nil && strings.HasPrefix(message, cause.Error()) {
+		message = cause.Error()
+	}
+	return file, line, column, message
+}
+
+// recordMarkdownSecurityFindings scans a workflow markdown file for hidden or malicious content
+func recordMarkdownSecurityFindings(markdownPath string) {
+	if sarifReport == nil {
+		return
+	}
+	content, err := os.ReadFile(markdownPath)
+	if err != nil {
+		compileSARIFLog.Printf("Failed to read %s for markdown security scan: %v", markdownPath, err)
+		return
+	}
+	markdownPath = absoluteSARIFPath(markdownPath)
+	for _, finding := range workflow.ScanMarkdownSecurity(string(content)) {
+		line := finding.Line
+		if line == 0 {
+			line = 1
+		}
+		recordSARIFFinding(sarifFinding{
+			RuleID:      "gh-aw/markdown-security/" + string(finding.Category),
+			Description: fmt.Sprintf("Suspicious markdown content (%s)", finding.Category),
+			HelpURI:     "https://github.github.com/gh-aw/reference/markdown/",
+			Level:       "warning",
+			Message:     finding.Description,
+			File:        markdownPath,
+			Line:        line,
+		})
+	}
+}
+
+// mapLockFileFinding moves a finding in a lock file to the source markdown file. The finding is
+// placed on the markdown line with the same content when there is exactly one, and on the
+// first line otherwise.
+func mapLockFileFinding(finding sarifFinding) sarifFinding {
+	markdownPath := strings.TrimSuffix(finding.File, ".lock.yml") + ".md"
+	markdownContent, err := os.ReadFile(markdownPath)
+	if err != nil {
+		compileSARIFLog.Printf("No source markdown for %s, keeping lock file location", finding.File)
+		return finding
+	}
+
+	finding.LockFile, finding.LockLine = finding.File, finding.Line
+	finding.File, finding.Line, finding.Column = markdownPath, 1, 0
+
+	lockContent, err := os.ReadFile(finding.LockFile)
+	if err != nil {
+		return finding
+	}
+	lockLines := strings.Split(string(lockContent), "\n")
+	if finding.LockLine < 1 || finding.LockLine > len(lockLines) {
+		return finding
+	}
+	needle := normalizeSARIFSourceLine(lockLines[finding.LockLine-1])
+	if len(needle) < 8 {
+		// Short lines such as "steps:" are too ambiguous to map
+		return finding
+	}
+
+	matchLine, matchColumn := 0, 0
+	for i, markdownLine := range strings.Split(string(markdownContent), "\n") {
+		if normalizeSARIFSourceLine(markdownLine) != needle {
+			continue
+		}
+		if matchLine != 0 {
+			return finding
+		}
+		matchLine, matchColumn = i+1, strings.Index(markdownLine, needle)+1
+	}
+	if matchLine != 0 {
+		finding.Line, finding.Column = matchLine, matchColumn
+	}
+	return finding
+}
+
+// normalizeSARIFSourceLine strips indentation and list markers so YAML lines can be compared
+// between the frontmatter and the lock file
+func normalizeSARIFSourceLine(line string) string {
+	return strings.TrimPrefix(strings.TrimSpace(line), "- ")
+}
+
+// buildSARIFLog converts the recorded findings to a SARIF log with one run and a rule per rule ID
+func buildSARIFLog(findings []sarifFinding, gitRoot string) *sarifLog {
+	rules := make(map[string]sarifRule)
+	results := make([]sarifResult, 0, len(findings))
+
+	for _, finding := range findings {
+		if _, exists := rules[finding.RuleID]; !exists {
+			description := finding.Description
+			if description == "" {
+				description = finding.RuleID
+			}
+			tool := sarifToolName
+			if prefix, _, found := strings.Cut(finding.RuleID, "/"); found && prefix != sarifToolName {
+				tool = prefix
+			}
+			rules[finding.RuleID] = sarifRule{
+				ID:                   finding.RuleID,
+				ShortDescription:     sarifMessage{Text: description},
+				HelpURI:              finding.HelpURI,
+				DefaultConfiguration: sarifRuleConfiguration{Level: finding.Level},
+				Properties:           sarifRuleProperties{Tags: []string{"security", tool}},
+			}
+		}
+
+		result := sarifResult{
+			RuleID:    finding.RuleID,
+			Level:     finding.Level,
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{newSARIFLocation(finding.File, finding.Line, finding.Column, gitRoot)},
+		}
+		if finding.LockFile != "" {
+			related := newSARIFLocation(finding.LockFile, finding.LockLine, 0, gitRoot)
+			related.ID = 1
+			related.Message = &sarifMessage{Text: "Location in the compiled workflow"}
+			result.RelatedLocations = []sarifLocation{related}
+		}
+		results = append(results, result)
+	}
+
+	ruleIDs := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	driverRules := make([]sarifRule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		driverRules = append(driverRules, rules[id])
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           sarifToolName,
+				Version:        GetVersion(),
+				InformationURI: sarifToolURI,
+				Rules:          driverRules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// newSARIFLocation creates a location with a repository-relative URI
+func newSARIFLocation(file string, line int, column int, gitRoot string) sarifLocation {
+	uri := file
+	if relPath, err := filepath.Rel(gitRoot, file); err == nil && !strings.HasPrefix(relPath, "..") {
+		uri = relPath
+	}
+	if line < 1 {
+		line = 1
+	}
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(uri)},
+			Region:           sarifRegion{StartLine: line, StartColumn: column},
+		},
+	}
+}
+
+// writeSARIFReport writes the collected findings to a SARIF file
+func writeSARIFReport(path string) error {
+	if sarifReport == nil {
+		return nil
+	}
+	compileSARIFLog.Printf("Writing %d finding(s) to %s", len(sarifReport.findings), path)
+
+	data, err := json.MarshalIndent(buildSARIFLog(sarifReport.findings, sarifReport.gitRoot), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF log: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF file %s: %w", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Wrote %d finding(s) to %s", len(sarifReport.findings), path)))
+	return nil
+}
@@ -0,0 +1,246 @@
+//go:build !integration
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSARIFReport enables SARIF collection rooted at dir for the duration of a test
+func startSARIFReport(t *testing.T, dir string) {
+	t.Helper()
+	sarifReport = &sarifFindings{gitRoot: dir}
+	t.Cleanup(func() { sarifReport = nil })
+}
+
+func TestRecordCompileErrorFindings(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	startSARIFReport(t, tmpDir)
+
+	tests := []struct {
+		name    string
+		content string
+		ruleID  string
+	}{
+		{
+			name:    "expression safety",
+			content: "---\non: workflow_dispatch\npermissions:\n  contents: read\n---\n\nUse ${{ secrets.TOKEN }}\n",
+			ruleID:  "gh-aw/expression-safety",
+		},
+		{
+			name:    "strict mode",
+			content: "---\non: workflow_dispatch\nstrict: true\npermissions:\n  contents: write\n---\n\n# Strict\n",
+			ruleID:  "gh-aw/strict-mode",
+		},
+		{
+			name:    "schema error",
+			content: "---\non: workflow_dispatch\npermissions:\n  contents: read\nunknown-field: true\n---\n\n# Schema\n",
+			ruleID:  "gh-aw/compile-error",
+		},
+	}
+
+	compiler := workflow.NewCompiler(workflow.WithGitRoot(tmpDir))
+	compiler.SetQuiet(true)
+	for i, tt := range tests {
+		workflowFile := filepath.Join(workflowsDir, tt.name[:6]+".md")
+		require.NoError(t, os.WriteFile(workflowFile, []byte(tt.content), 0644))
+
+		result := compileWorkflowFile(compiler, workflowFile, false, true, true, false, false, false, false, false)
+		require.False(t, result.success, tt.name)
+		require.Len(t, sarifReport.findings, i+1, tt.name)
+
+		finding := sarifReport.findings[i]
+		assert.Equal(t, tt.ruleID, finding.RuleID, tt.name)
+		assert.Equal(t, "error", finding.Level, tt.name)
+		assert.Equal(t, workflowFile, finding.File, tt.name)
+		assert.NotContains(t, finding.Message, "error:", "%s: the position prefix should be stripped", tt.name)
+	}
+
+	assert.Equal(t, 7, sarifReport.findings[0].Line, "unauthorized expressions are reported where they are used")
+	assert.Equal(t, 9, sarifReport.findings[0].Column)
+	assert.Equal(t, "expression 'secrets.TOKEN' is not allowed in workflow markdown", sarifReport.findings[0].Message)
+	assert.Equal(t, 5, sarifReport.findings[2].Line, "schema errors keep their frontmatter position")
+}
+
+func TestRecordAggregatedCompileErrorFindings(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowFile := filepath.Join(tmpDir, "strict.md")
+	startSARIFReport(t, tmpDir)
+
+	aggregated := errors.New(workflowFile + ":1:1: error: Found 2 strict mode errors:\n  • strict mode: write permissions are not allowed\n  • strict mode: network '*' is not allowed")
+	recordCompileErrorFindings(workflowFile, aggregated)
+	recordCompileErrorFindings(workflowFile, errors.Join(errors.New("first error"), errors.New("second error")))
+
+	var messages []string
+	for _, finding := range sarifReport.findings {
+		messages = append(messages, finding.Message)
+	}
+	assert.Equal(t, []string{
+		"strict mode: write permissions are not allowed",
+		"strict mode: network '*' is not allowed",
+		"first error",
+		"second error",
+	}, messages, "every error should be recorded")
+	assert.Equal(t, "gh-aw/strict-mode", sarifReport.findings[1].RuleID)
+	assert.Equal(t, "gh-aw/compile-error", sarifReport.findings[3].RuleID)
+}
+
+func TestRecordCompileWarningFindings(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowFile := filepath.Join(workflowsDir, "oidc.md")
+	require.NoError(t, os.WriteFile(workflowFile, []byte("---\non: workflow_dispatch\npermissions:\n  contents: read\n  id-token: write\n---\n\n# OIDC\n"), 0644))
+	startSARIFReport(t, tmpDir)
+
+	compiler := workflow.NewCompiler(workflow.WithGitRoot(tmpDir), workflow.WithNoEmit(true))
+	compiler.SetQuiet(true)
+	result := compileWorkflowFile(compiler, workflowFile, false, true, true, false, false, false, false, false)
+	require.True(t, result.success)
+
+	var warnings []sarifFinding
+	for _, finding := range sarifReport.findings {
+		if finding.RuleID == "gh-aw/compile-warning" {
+			warnings = append(warnings, finding)
+		}
+	}
+	require.Len(t, warnings, 2, "every compiler warning should be recorded")
+	assert.Contains(t, warnings[0].Message, "Missing required permissions")
+	assert.Contains(t, warnings[1].Message, "id-token: write")
+	for _, warning := range warnings {
+		assert.Equal(t, "warning", warning.Level)
+		assert.Equal(t, workflowFile, warning.File)
+	}
+}
+
+func TestRecordMarkdownSecurityFindings(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowFile := filepath.Join(tmpDir, "hidden.md")
+	require.NoError(t, os.WriteFile(workflowFile, []byte("---\non: workflow_dispatch\n---\n\n# Task\n\n<script>alert(1)</script>\n"), 0644))
+
+	recordMarkdownSecurityFindings(workflowFile)
+	startSARIFReport(t, tmpDir)
+	recordMarkdownSecurityFindings(workflowFile)
+
+	require.NotEmpty(t, sarifReport.findings, "findings are only recorded while SARIF export is enabled")
+	finding := sarifReport.findings[0]
+	assert.Equal(t, "gh-aw/markdown-security/html-abuse", finding.RuleID)
+	assert.Equal(t, "warning", finding.Level)
+	assert.Equal(t, 7, finding.Line)
+}
+
+func TestMapLockFileFinding(t *testing.T) {
+	tmpDir := t.TempDir()
+	markdownFile := filepath.Join(tmpDir, "triage.md")
+	lockFile := filepath.Join(tmpDir, "triage.lock.yml")
+	require.NoError(t, os.WriteFile(markdownFile, []byte(`---
+on: issues
+steps:
+  - name: Echo title
+    run: echo "${{ github.event.issue.title }}"
+---
+
+# Triage
+`), 0644))
+	require.NoError(t, os.WriteFile(lockFile, []byte(`name: "triage"
+jobs:
+  agent:
+    steps:
+      - name: Echo title
+        run: echo "${{ github.event.issue.title }}"
+      - name: Checkout
+`), 0644))
+
+	mapped := mapLockFileFinding(sarifFinding{File: lockFile, Line: 6, Column: 9})
+	assert.Equal(t, markdownFile, mapped.File)
+	assert.Equal(t, 5, mapped.Line, "the finding should move to the matching frontmatter line")
+	assert.Equal(t, 5, mapped.Column)
+	assert.Equal(t, lockFile, mapped.LockFile)
+	assert.Equal(t, 6, mapped.LockLine)
+
+	unmatched := mapLockFileFinding(sarifFinding{File: lockFile, Line: 3, Column: 3})
+	assert.Equal(t, markdownFile, unmatched.File)
+	assert.Equal(t, 1, unmatched.Line, "unmatched findings should point at the top of the markdown file")
+	assert.Equal(t, 0, unmatched.Column)
+
+	orphan := filepath.Join(tmpDir, "orphan.lock.yml")
+	assert.Equal(t, orphan, mapLockFileFinding(sarifFinding{File: orphan, Line: 2}).File, "lock files without markdown keep their location")
+}
+
+func TestZizmorFindingsRecordedForSARIF(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "test.md"), []byte("---\non: push\n---\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "test.lock.yml"), []byte("name: test\n"), 0644))
+	startSARIFReport(t, tmpDir)
+
+	stdout := `[{"ident": "excessive-permissions", "desc": "overly broad permissions", "url": "https://docs.zizmor.sh/audits/#excessive-permissions",
+  "determinations": {"severity": "High"},
+  "locations": [{"symbolic": {"key": {"Local": {"given_path": "./.github/workflows/test.lock.yml"}}, "annotation": "uses write-all permissions"},
+    "concrete": {"location": {"start_point": {"row": 0, "column": 0}}}}]}]`
+	_, err := parseAndDisplayZizmorOutput(stdout, " INFO audit: zizmor: 🌈 completed ./.github/workflows/test.lock.yml\n", false)
+	require.NoError(t, err)
+
+	require.Len(t, sarifReport.findings, 1)
+	assert.Equal(t, sarifFinding{
+		RuleID:      "zizmor/excessive-permissions",
+		Description: "overly broad permissions",
+		HelpURI:     "https://docs.zizmor.sh/audits/#excessive-permissions",
+		Level:       "error",
+		Message:     "overly broad permissions: uses write-all permissions",
+		File:        filepath.Join(workflowsDir, "test.md"),
+		Line:        1,
+		LockFile:    filepath.Join(workflowsDir, "test.lock.yml"),
+		LockLine:    1,
+	}, sarifReport.findings[0])
+}
+
+func TestBuildSARIFLog(t *testing.T) {
+	root := t.TempDir()
+	findings := []sarifFinding{
+		{RuleID: "zizmor/artipacked", Description: "credential persistence", Level: "warning", Message: "first", File: filepath.Join(root, ".github", "workflows", "a.md"), Line: 1, LockFile: filepath.Join(root, ".github", "workflows", "a.lock.yml"), LockLine: 40},
+		{RuleID: "gh-aw/strict-mode", Description: "Strict mode violation", Level: "error", Message: "second", File: filepath.Join(root, ".github", "workflows", "b.md"), Line: 4, Column: 3},
+		{RuleID: "zizmor/artipacked", Description: "credential persistence", Level: "warning", Message: "third", File: filepath.Join(root, ".github", "workflows", "b.md"), Line: 1},
+	}
+
+	data, err := json.Marshal(buildSARIFLog(findings, root))
+	require.NoError(t, err)
+
+	var log map[string]any
+	require.NoError(t, json.Unmarshal(data, &log))
+	assert.Equal(t, "2.1.0", log["version"])
+
+	run := log["runs"].([]any)[0].(map[string]any)
+	driver := run["tool"].(map[string]any)["driver"].(map[string]any)
+	assert.Equal(t, "gh-aw", driver["name"])
+	rules := driver["rules"].([]any)
+	require.Len(t, rules, 2, "rules should be deduplicated")
+	assert.Equal(t, "gh-aw/strict-mode", rules[0].(map[string]any)["id"])
+	assert.Equal(t, []any{"security", "zizmor"}, rules[1].(map[string]any)["properties"].(map[string]any)["tags"])
+
+	results := run["results"].([]any)
+	require.Len(t, results, 3)
+	first := results[0].(map[string]any)
+	location := first["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+	assert.Equal(t, ".github/workflows/a.md", location["artifactLocation"].(map[string]any)["uri"])
+	related := first["relatedLocations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+	assert.Equal(t, ".github/workflows/a.lock.yml", related["artifactLocation"].(map[string]any)["uri"])
+	assert.InDelta(t, 40, related["region"].(map[string]any)["startLine"], 0)
+
+	second := results[1].(map[string]any)
+	region := second["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)["region"].(map[string]any)
+	assert.InDelta(t, 4, region["startLine"], 0)
+	assert.InDelta(t, 3, region["startColumn"], 0)
+	assert.NotContains(t, second, "relatedLocations")
+}
@@ -0,0 +1,75 @@
+//go:build !integration
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScheduledLockFile(t *testing.T, dir, name string, crons ...string) string {
+	t.Helper()
+	content := "name: \"" + name + "\"\n\"on\":\n  workflow_dispatch:\n"
+	if len(crons) > 0 {
+		content += "  schedule:\n"
+		for _, cron := range crons {
+			content += "  - cron: \"" + cron + "\"\n"
+		}
+	}
+	content += "jobs: {}\n"
+	path := filepath.Join(dir, name+".lock.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestCollectScheduleHistogram(t *testing.T) {
+	dir := t.TempDir()
+	lockFiles := []string{
+		writeScheduledLockFile(t, dir, "daily-report", "5 9 * * *"),
+		writeScheduledLockFile(t, dir, "weekly-digest", "10 9 * * 1"),
+		writeScheduledLockFile(t, dir, "hourly-sync", "40 */6 * * *", "0 0 * * MON"),
+		writeScheduledLockFile(t, dir, "on-push"),
+	}
+
+	histogram := collectScheduleHistogram(lockFiles)
+	require.NotNil(t, histogram)
+	assert.Equal(t, 3, histogram.Workflows, "workflows without schedules should be skipped")
+	assert.InDelta(t, 7+1+28, histogram.RunsPerWeek, 1e-9)
+	assert.InDelta(t, 1+1.0/7, histogram.Hours[9], 1e-9)
+	assert.InDelta(t, 1, histogram.Hours[6], 1e-9)
+	assert.Equal(t, map[string]string{"hourly-sync": "0 0 * * MON"}, histogram.Unparsed)
+
+	// Monday 09:00-09:15 is shared by the daily and weekly workflows
+	mondayNine := (24*60 + 9*60) / 15
+	assert.ElementsMatch(t, []string{"daily-report", "weekly-digest"}, histogram.WindowOwners[mondayNine])
+	assert.Equal(t, "Mon 09:00", formatScheduleWindow(mondayNine))
+}
+
+func TestDisplayScheduleHistogram(t *testing.T) {
+	dir := t.TempDir()
+	histogram := collectScheduleHistogram([]string{
+		writeScheduledLockFile(t, dir, "a", "5 9 * * *"),
+		writeScheduledLockFile(t, dir, "b", "7 9 * * *"),
+	})
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	displayScheduleHistogram(histogram)
+	w.Close()
+	os.Stderr = oldStderr
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	output := buf.String()
+
+	assert.Contains(t, output, "Schedule Histogram")
+	assert.Contains(t, output, "Scheduled workflows: 2")
+	assert.Contains(t, output, "7 15-minute window(s) are shared by more than one workflow")
+	assert.Contains(t, output, "Sun 09:00  a, b")
+}
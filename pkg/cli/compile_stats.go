@@ -5,9 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/github/gh-aw/pkg/console"
 	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/sliceutil"
 	"github.com/github/gh-aw/pkg/styles"
 	"github.com/github/gh-aw/pkg/tty"
 	"github.com/goccy/go-yaml"
@@ -177,3 +182,149 @@ func displayStatsTable(statsList []*WorkflowStats) {
 	fmt.Fprintf(os.Stderr, "  Total steps:     %d\n", totalSteps)
 	fmt.Fprintf(os.Stderr, "  Total scripts:   %d (%s)\n", totalScripts, console.FormatFileSize(int64(totalScriptSize)))
 }
+
+// ScheduleHistogram summarizes when the scheduled workflows of a repository run
+type ScheduleHistogram struct {
+	Workflows    int               // Number of workflows with a schedule
+	RunsPerWeek  float64           // Scheduled runs in an average week
+	Hours        [24]float64       // Average runs per day starting in each UTC hour
+	WindowOwners map[int][]string  // Workflows running in each 15-minute window
+	Unparsed     map[string]string // Cron expressions that could not be expanded, by workflow
+}
+
+// collectRepositoryScheduleHistogram builds the schedule histogram of all lock files in the
+// workflow directory, since all of a repository's scheduled workflows live there
+func collectRepositoryScheduleHistogram(workflowDir string) *ScheduleHistogram {
+	if workflowDir == "" {
+		workflowDir = ".github/workflows"
+	}
+	gitRoot, err := findGitRoot()
+	if err != nil {
+		return nil
+	}
+	lockFiles, err := filepath.Glob(filepath.Join(gitRoot, workflowDir, "*.lock.yml"))
+	if err != nil {
+		return nil
+	}
+	return collectScheduleHistogram(lockFiles)
+}
+
+// collectScheduleHistogram reads the cron schedules of compiled lock files
+func collectScheduleHistogram(lockFiles []string) *ScheduleHistogram {
+	compileStatsLog.Printf("Collecting schedule histogram: files=%d", len(lockFiles))
+	histogram := &ScheduleHistogram{
+		WindowOwners: make(map[int][]string),
+		Unparsed:     make(map[string]string),
+	}
+
+	for _, lockFile := range lockFiles {
+		content, err := os.ReadFile(lockFile)
+		if err != nil {
+			continue
+		}
+		var lockYAML struct {
+			On struct {
+				Schedule []struct {
+					Cron string `yaml:"cron"`
+				} `yaml:"schedule"`
+			} `yaml:"on"`
+		}
+		if err := yaml.Unmarshal(content, &lockYAML); err != nil || len(lockYAML.On.Schedule) == 0 {
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(lockFile), ".lock.yml")
+		histogram.Workflows++
+		for _, schedule := range lockYAML.On.Schedule {
+			occurrences, ok := parser.CronWeeklyOccurrences(schedule.Cron)
+			if !ok {
+				histogram.Unparsed[name] = schedule.Cron
+				continue
+			}
+			for minute, weight := range occurrences {
+				window := minute / parser.ScheduleBucketMinutes
+				histogram.RunsPerWeek += weight
+				histogram.Hours[(minute/60)%24] += weight / 7
+				if !sliceutil.Contains(histogram.WindowOwners[window], name) {
+					histogram.WindowOwners[window] = append(histogram.WindowOwners[window], name)
+				}
+			}
+		}
+	}
+
+	return histogram
+}
+
+// displayScheduleHistogram prints the runs per UTC hour and the most crowded 15-minute windows
+func displayScheduleHistogram(histogram *ScheduleHistogram) {
+	if histogram == nil || histogram.Workflows == 0 {
+		return
+	}
+	compileStatsLog.Printf("Displaying schedule histogram: workflows=%d", histogram.Workflows)
+
+	maxHour := 0.0
+	for _, runs := range histogram.Hours {
+		maxHour = max(maxHour, runs)
+	}
+
+	const barWidth = 30
+	rows := make([][]string, 0, 24)
+	for hour, runs := range histogram.Hours {
+		bar := ""
+		if maxHour > 0 {
+			bar = strings.Repeat("█", int(runs/maxHour*barWidth+0.5))
+		}
+		rows = append(rows, []string{fmt.Sprintf("%02d:00", hour), strconv.FormatFloat(runs, 'f', 1, 64), bar})
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprint(os.Stderr, console.RenderTable(console.TableConfig{
+		Title:   "Schedule Histogram (UTC, average runs per day)",
+		Headers: []string{"HOUR", "RUNS", ""},
+		Rows:    rows,
+	}))
+
+	// Windows shared by several workflows, most crowded first
+	var crowded []int
+	for window, owners := range histogram.WindowOwners {
+		if len(owners) > 1 {
+			crowded = append(crowded, window)
+		}
+	}
+	sort.Slice(crowded, func(i, j int) bool {
+		a, b := len(histogram.WindowOwners[crowded[i]]), len(histogram.WindowOwners[crowded[j]])
+		if a != b {
+			return a > b
+		}
+		return crowded[i] < crowded[j]
+	})
+
+	fmt.Fprintf(os.Stderr, "  Scheduled workflows: %d\n", histogram.Workflows)
+	fmt.Fprintf(os.Stderr, "  Runs per week:       %.0f\n", histogram.RunsPerWeek)
+	if len(crowded) == 0 {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage("No 15-minute window is shared by more than one workflow"))
+	} else {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("%d 15-minute window(s) are shared by more than one workflow", len(crowded))))
+		const maxCrowded = 5
+		for i, window := range crowded {
+			if i >= maxCrowded {
+				break
+			}
+			owners := histogram.WindowOwners[window]
+			sort.Strings(owners)
+			fmt.Fprintf(os.Stderr, "  %s  %s\n", formatScheduleWindow(window), strings.Join(owners, ", "))
+		}
+	}
+
+	for name, cron := range histogram.Unparsed {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Schedule %q of %s is not included in the histogram", cron, name)))
+	}
+}
+
+// formatScheduleWindow formats a 15-minute window of the week, e.g. "Mon 09:15"
+func formatScheduleWindow(window int) string {
+	minute := window * parser.ScheduleBucketMinutes
+	day := time.Weekday(minute / (24 * 60))
+	minute %= 24 * 60
+	return fmt.Sprintf("%s %02d:%02d", day.String()[:3], minute/60, minute%60)
+}
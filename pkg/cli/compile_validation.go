@@ -211,6 +211,18 @@ func validateCompileConfig(config CompileConfig) error {
 		return fmt.Errorf("--purge flag can only be used when compiling all markdown files (no specific files specified)")
 	}
 
+	// Validate explain-trigger flag usage
+	if config.ExplainTrigger != "" && config.Watch {
+		compileValidationLog.Print("Config validation failed: explain-trigger with watch")
+		return fmt.Errorf("--explain-trigger cannot be used with --watch")
+	}
+
+	// Validate sarif flag usage
+	if config.SARIFFile != "" && config.Watch {
+		compileValidationLog.Print("Config validation failed: sarif with watch")
+		return fmt.Errorf("--sarif cannot be used with --watch")
+	}
+
 	// Validate workflow directory path
 	if config.WorkflowDir != "" && filepath.IsAbs(config.WorkflowDir) {
 		compileValidationLog.Printf("Config validation failed: absolute path in workflowDir: %s", config.WorkflowDir)
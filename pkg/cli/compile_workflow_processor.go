@@ -22,6 +22,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -94,6 +95,13 @@ func compileWorkflowFile(
 		compileWorkflowProcessorLog.Printf("Repository slug for file set: %s", fileRepoSlug)
 	}
 
+	// Scan the markdown for hidden or malicious content when exporting SARIF
+	recordMarkdownSecurityFindings(resolvedFile)
+
+	// Record the compiler warnings of this workflow when exporting SARIF
+	warningsBefore := len(compiler.GetWarnings())
+	defer func() { recordCompileWarningFindings(resolvedFile, compiler.GetWarnings()[warningsBefore:]) }()
+
 	// Parse the workflow
 	workflowData, err := compiler.ParseWorkflowFile(resolvedFile)
 	if err != nil {
@@ -115,6 +123,7 @@ func compileWorkflowFile(
 
 		// Don't print error here - it will be displayed in the compilation summary
 		// The error is stored in ValidationResult for JSON output and summary display
+		recordCompileErrorFindings(resolvedFile, err)
 		result.validationResult.Valid = false
 		result.validationResult.Errors = append(result.validationResult.Errors, CompileValidationError{
 			Type:    "parse_error",
@@ -131,7 +140,24 @@ func compileWorkflowFile(
 	if err := CompileWorkflowDataWithValidation(compiler, workflowData, resolvedFile, verbose && !jsonOutput, zizmor && !noEmit, poutine && !noEmit, false, strict, validate && !noEmit); err != nil {
 		// Don't print error here - it will be displayed in the compilation summary
 		// The error is stored in ValidationResult for JSON output and summary display
+		recordCompileErrorFindings(resolvedFile, err)
 		result.validationResult.Valid = false
+		var policyErr *workflow.PolicyViolationError
+		if errors.As(err, &policyErr) {
+			// Report each policy violation separately; the console summary shows it with its source line
+			for i, violation := range policyErr.Violations {
+				message := violation.Message
+				if !jsonOutput {
+					message = policyErr.FormatViolation(i)
+				}
+				result.validationResult.Errors = append(result.validationResult.Errors, CompileValidationError{
+					Type:    "policy_violation",
+					Message: message,
+					Line:    violation.Line,
+				})
+			}
+			return result
+		}
 		result.validationResult.Errors = append(result.validationResult.Errors, CompileValidationError{
 			Type:    "compilation_error",
 			Message: err.Error(),
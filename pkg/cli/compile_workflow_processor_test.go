@@ -0,0 +1,49 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileWorkflowFilePolicyViolations(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	policyDir := filepath.Join(tmpDir, ".github", "aw")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.MkdirAll(policyDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "policy.yml"), []byte("engines: [copilot]\nroles: [admin]\n"), 0644))
+
+	workflowFile := filepath.Join(workflowsDir, "test.md")
+	require.NoError(t, os.WriteFile(workflowFile, []byte(`---
+on: workflow_dispatch
+engine: claude
+permissions:
+  contents: read
+roles: [admin, write]
+---
+
+# Test
+`), 0644))
+
+	compiler := workflow.NewCompiler(workflow.WithGitRoot(tmpDir))
+	compiler.SetQuiet(true)
+	result := compileWorkflowFile(compiler, workflowFile, false, true, true, false, false, false, false, false)
+
+	assert.False(t, result.success)
+	assert.False(t, result.validationResult.Valid)
+	require.Len(t, result.validationResult.Errors, 2, "each violation should be reported separately")
+	assert.Equal(t, CompileValidationError{
+		Type:    "policy_violation",
+		Message: "policy: engine 'claude' is not allowed. Allowed engines: copilot (" + filepath.Join(policyDir, "policy.yml") + ")",
+		Line:    3,
+	}, result.validationResult.Errors[0])
+	assert.Equal(t, "policy_violation", result.validationResult.Errors[1].Type)
+	assert.Equal(t, 6, result.validationResult.Errors[1].Line)
+}
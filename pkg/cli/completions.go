@@ -66,7 +66,7 @@ func getWorkflowDescription(filePath string) string {
 
 // ValidEngineNames returns the list of valid AI engine names for shell completion
 func ValidEngineNames() []string {
-	registry := workflow.GetGlobalEngineRegistry()
+	registry := workflow.GetRepositoryEngineRegistry()
 	return registry.GetSupportedEngines()
 }
 
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+var diffCommandLog = logger.New("cli:diff_command")
+
+// DiffConfig holds configuration for diff command execution
+type DiffConfig struct {
+	Workflows  []string // Restrict to these workflow IDs (all workflows when empty)
+	Base       string   // Git ref of the base version
+	JSONOutput bool
+	Verbose    bool
+}
+
+// NewDiffCommand creates the diff command
+func NewDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [workflow]...",
+		Short: "Show the security-relevant changes of workflows compared to a git ref",
+		Long: `Compile the workflows at a base git ref and in the working tree and report the
+changes that matter for a security review, instead of the full lock file diff:
+
+- Permission escalations per job
+- Added and removed network.allowed entries
+- Newly enabled safe outputs and raised max values
+- New secrets referenced by the compiled workflow
+- New tools and MCP servers, and newly allowed tools
+- Added, removed and modified triggers
+
+The report is Markdown suitable for a pull request comment. Use --json for policy bots.
+
+` + WorkflowIDExplanation + `
+
+Examples:
+  ` + string(constants.CLIExtensionPrefix) + ` diff                                # Compare the working tree with HEAD
+  ` + string(constants.CLIExtensionPrefix) + ` diff --base origin/main             # Compare with the main branch
+  ` + string(constants.CLIExtensionPrefix) + ` diff issue-triage --base v1.2.0     # Compare one workflow
+  ` + string(constants.CLIExtensionPrefix) + ` diff --base origin/main --json      # Output the diff as JSON`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base, _ := cmd.Flags().GetString("base")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+
+			return RunDiff(DiffConfig{
+				Workflows:  args,
+				Base:       base,
+				JSONOutput: jsonOutput,
+				Verbose:    verbose,
+			})
+		},
+	}
+
+	cmd.Flags().String("base", "HEAD", "Git ref of the base version to compare against")
+	addJSONFlag(cmd)
+
+	cmd.ValidArgsFunction = CompleteWorkflowNames
+
+	return cmd
+}
+
+// RunDiff executes the diff command with the given configuration
+func RunDiff(config DiffConfig) error {
+	diffCommandLog.Printf("Running diff: workflows=%v, base=%s", config.Workflows, config.Base)
+
+	gitRoot, err := findGitRoot()
+	if err != nil {
+		return errors.New("the diff command must be run inside a git repository")
+	}
+
+	baseDir, cleanup, err := checkoutGitRef(gitRoot, config.Base)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if config.Verbose {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Comparing the working tree with %s", config.Base)))
+	}
+
+	diffs, err := diffWorkflowDirs(baseDir, gitRoot, config.Workflows, config.Verbose)
+	if err != nil {
+		return err
+	}
+
+	if config.JSONOutput {
+		jsonBytes, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	fmt.Print(renderWorkflowDiffsMarkdown(diffs, config.Base))
+	return nil
+}
+
+// checkoutGitRef checks out a git ref into a temporary worktree. The returned function removes it.
+func checkoutGitRef(gitRoot, ref string) (string, func(), error) {
+	if err := exec.Command("git", "-C", gitRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run(); err != nil {
+		return "", nil, fmt.Errorf("unknown git ref: %s", ref)
+	}
+
+	tempDir, err := os.MkdirTemp("", "gh-aw-diff-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	worktree := filepath.Join(tempDir, "base")
+	if output, err := exec.Command("git", "-C", gitRoot, "worktree", "add", "--detach", worktree, ref).CombinedOutput(); err != nil {
+		os.RemoveAll(tempDir)
+		return "", nil, fmt.Errorf("failed to check out %s: %s", ref, strings.TrimSpace(string(output)))
+	}
+	diffCommandLog.Printf("Checked out %s into %s", ref, worktree)
+
+	cleanup := func() {
+		if output, err := exec.Command("git", "-C", gitRoot, "worktree", "remove", "--force", worktree).CombinedOutput(); err != nil {
+			diffCommandLog.Printf("Failed to remove worktree %s: %s", worktree, strings.TrimSpace(string(output)))
+		}
+		os.RemoveAll(tempDir)
+	}
+	return worktree, cleanup, nil
+}
+
+// diffWorkflowDirs compiles the workflows of the base and head repository roots and compares them
+func diffWorkflowDirs(baseRoot, headRoot string, filter []string, verbose bool) ([]*workflow.WorkflowDiff, error) {
+	baseSnapshots, err := compileWorkflowSnapshots(baseRoot, filter, verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile base workflows: %w", err)
+	}
+	headSnapshots, err := compileWorkflowSnapshots(headRoot, filter, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range baseSnapshots {
+		names = append(names, name)
+	}
+	for name := range headSnapshots {
+		if _, exists := baseSnapshots[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	diffs := make([]*workflow.WorkflowDiff, 0, len(names))
+	for _, name := range names {
+		diffs = append(diffs, workflow.DiffWorkflowSnapshots(name, baseSnapshots[name], headSnapshots[name]))
+	}
+	return diffs, nil
+}
+
+// compileWorkflowSnapshots compiles the workflows of a repository root without writing lock files
+func compileWorkflowSnapshots(root string, filter []string, verbose bool) (map[string]*workflow.WorkflowSnapshot, error) {
+	snapshots := make(map[string]*workflow.WorkflowSnapshot)
+
+	workflowsDir := filepath.Join(root, getWorkflowsDir())
+	if _, err := os.Stat(workflowsDir); os.IsNotExist(err) {
+		return snapshots, nil
+	}
+	files, err := getMarkdownWorkflowFiles(workflowsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := workflow.NewCompiler(
+		workflow.WithGitRoot(root),
+		workflow.WithVerbose(verbose),
+		workflow.WithRepositorySlug(getRepositorySlugFromRemote()),
+	)
+	compiler.SetQuiet(true)
+	compiler.SetSkipValidation(true)
+
+	for _, file := range files {
+		name := normalizeWorkflowID(file)
+		if len(filter) > 0 && !slices.ContainsFunc(filter, func(f string) bool { return normalizeWorkflowID(f) == name }) {
+			continue
+		}
+		relPath, err := filepath.Rel(root, file)
+		if err != nil {
+			relPath = filepath.Base(file)
+		}
+		compiler.SetWorkflowIdentifier(filepath.ToSlash(relPath))
+
+		data, lockYAML, err := compiler.CompileWorkflowToYAML(file)
+		if err != nil {
+			var sharedErr *workflow.SharedWorkflowError
+			if errors.As(err, &sharedErr) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to compile %s: %w", name, err)
+		}
+		snapshot, err := workflow.NewWorkflowSnapshot(data, lockYAML)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze %s: %w", name, err)
+		}
+		snapshots[name] = snapshot
+	}
+	diffCommandLog.Printf("Compiled %d workflows in %s", len(snapshots), root)
+	return snapshots, nil
+}
+
+// renderWorkflowDiffsMarkdown renders the workflow diffs as a Markdown report
+func renderWorkflowDiffsMarkdown(diffs []*workflow.WorkflowDiff, base string) string {
+	var sb strings.Builder
+	sb.WriteString("## Agentic workflow changes\n\n")
+	fmt.Fprintf(&sb, "Security-relevant changes compared to `%s`.\n\n", base)
+
+	changed := 0
+	for _, diff := range diffs {
+		if !diff.HasChanges() {
+			continue
+		}
+		changed++
+		fmt.Fprintf(&sb, "### `%s` (%s)\n\n", diff.Workflow, diff.Status)
+		if diff.Status == "removed" {
+			continue
+		}
+
+		if len(diff.PermissionEscalations) > 0 {
+			sb.WriteString("**Permission escalations**\n\n| Job | Scope | From | To |\n| --- | --- | --- | --- |\n")
+			for _, escalation := range diff.PermissionEscalations {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", escalation.Job, escalation.Scope, escalation.From, escalation.To)
+			}
+			sb.WriteString("\n")
+		}
+
+		var items []string
+		for _, domain := range diff.AddedDomains {
+			items = append(items, fmt.Sprintf("Network: allows `%s`", domain))
+		}
+		for _, domain := range diff.RemovedDomains {
+			items = append(items, fmt.Sprintf("Network: no longer allows `%s`", domain))
+		}
+		for _, output := range diff.SafeOutputs {
+			items = append(items, "Safe output: "+formatSafeOutputChange(output))
+		}
+		for _, secret := range diff.AddedSecrets {
+			items = append(items, fmt.Sprintf("Secret: uses `%s`", secret))
+		}
+		for _, tool := range diff.Tools {
+			items = append(items, formatToolChange(tool))
+		}
+		for _, trigger := range diff.Triggers {
+			items = append(items, fmt.Sprintf("Trigger: `%s` %s", trigger.Event, trigger.Change))
+		}
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+		if len(items) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+
+	if changed == 0 {
+		sb.WriteString("No security-relevant changes.\n")
+	}
+	return sb.String()
+}
+
+// formatSafeOutputChange describes a safe output change
+func formatSafeOutputChange(change workflow.SafeOutputChange) string {
+	formatMax := func(value int) string {
+		if value == 0 {
+			return "unlimited"
+		}
+		return fmt.Sprintf("%d", value)
+	}
+	if change.OldMax == nil {
+		return fmt.Sprintf("`%s` enabled (max %s)", change.Type, formatMax(change.NewMax))
+	}
+	return fmt.Sprintf("`%s` max raised from %s to %s", change.Type, formatMax(*change.OldMax), formatMax(change.NewMax))
+}
+
+// formatToolChange describes a tool change
+func formatToolChange(change workflow.ToolChange) string {
+	kind := "Tool"
+	if change.MCP {
+		kind = "MCP server"
+	}
+	allowed := make([]string, 0, len(change.Added))
+	for _, entry := range change.Added {
+		allowed = append(allowed, "`"+entry+"`")
+	}
+	if change.New {
+		return fmt.Sprintf("%s: `%s` added (allows %s)", kind, change.Tool, strings.Join(allowed, ", "))
+	}
+	return fmt.Sprintf("%s: `%s` now also allows %s", kind, change.Tool, strings.Join(allowed, ", "))
+}
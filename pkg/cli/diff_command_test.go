@@ -0,0 +1,132 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDiffWorkflow(t *testing.T, root, name, content string) {
+	t.Helper()
+	workflowsDir := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, name), []byte(content), 0644))
+}
+
+func TestDiffWorkflowDirs(t *testing.T) {
+	baseRoot := t.TempDir()
+	headRoot := t.TempDir()
+
+	writeDiffWorkflow(t, baseRoot, "triage.md", `---
+on:
+  issues:
+    types: [opened]
+permissions:
+  contents: read
+safe-outputs:
+  add-comment:
+---
+
+# Triage
+`)
+	writeDiffWorkflow(t, baseRoot, "report.md", `---
+on: workflow_dispatch
+permissions:
+  contents: read
+---
+
+# Report
+`)
+	writeDiffWorkflow(t, headRoot, "triage.md", `---
+on:
+  issues:
+    types: [opened]
+  workflow_dispatch:
+permissions:
+  contents: read
+  issues: read
+network:
+  allowed:
+    - defaults
+    - api.example.com
+safe-outputs:
+  add-comment:
+    max: 3
+  create-issue:
+---
+
+# Triage
+`)
+	writeDiffWorkflow(t, headRoot, "report.md", `---
+on: workflow_dispatch
+permissions:
+  contents: read
+---
+
+# Report, reworded
+`)
+	writeDiffWorkflow(t, headRoot, "shared.md", "---\ntools:\n  bash: [\"ls\"]\n---\n\nShared instructions\n")
+
+	diffs, err := diffWorkflowDirs(baseRoot, headRoot, nil, false)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2, "shared components should be skipped")
+
+	assert.Equal(t, "report", diffs[0].Workflow)
+	assert.False(t, diffs[0].HasChanges(), "prompt-only changes are not security relevant")
+
+	triage := diffs[1]
+	assert.Equal(t, "modified", triage.Status)
+	assert.Contains(t, triage.PermissionEscalations, workflow.PermissionEscalation{Job: "agent", Scope: "issues", From: "none", To: "read"})
+	assert.Equal(t, []string{"api.example.com"}, triage.AddedDomains)
+	assert.Contains(t, triage.Triggers, workflow.TriggerChange{Event: "workflow_dispatch", Change: "added"})
+	require.Len(t, triage.SafeOutputs, 2)
+	assert.Equal(t, "add_comment", triage.SafeOutputs[0].Type)
+	assert.Equal(t, "create_issue", triage.SafeOutputs[1].Type)
+
+	filtered, err := diffWorkflowDirs(baseRoot, headRoot, []string{"report.md"}, false)
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "report", filtered[0].Workflow)
+}
+
+func TestRenderWorkflowDiffsMarkdown(t *testing.T) {
+	oldMax := 1
+	diffs := []*workflow.WorkflowDiff{
+		{Workflow: "report", Status: "unchanged"},
+		{Workflow: "legacy", Status: "removed"},
+		{
+			Workflow:              "triage",
+			Status:                "modified",
+			PermissionEscalations: []workflow.PermissionEscalation{{Job: "agent", Scope: "issues", From: "none", To: "read"}},
+			AddedDomains:          []string{"api.example.com"},
+			SafeOutputs: []workflow.SafeOutputChange{
+				{Type: "add_comment", OldMax: &oldMax, NewMax: 0},
+				{Type: "create_issue", NewMax: 2},
+			},
+			AddedSecrets: []string{"TRIAGE_TOKEN"},
+			Tools:        []workflow.ToolChange{{Tool: "notion", MCP: true, New: true, Added: []string{"search"}}},
+			Triggers:     []workflow.TriggerChange{{Event: "workflow_dispatch", Change: "added"}},
+		},
+	}
+
+	markdown := renderWorkflowDiffsMarkdown(diffs, "origin/main")
+
+	assert.Contains(t, markdown, "compared to `origin/main`")
+	assert.NotContains(t, markdown, "`report`", "unchanged workflows should be omitted")
+	assert.Contains(t, markdown, "### `legacy` (removed)")
+	assert.Contains(t, markdown, "| agent | issues | none | read |")
+	assert.Contains(t, markdown, "- Network: allows `api.example.com`")
+	assert.Contains(t, markdown, "- Safe output: `add_comment` max raised from 1 to unlimited")
+	assert.Contains(t, markdown, "- Safe output: `create_issue` enabled (max 2)")
+	assert.Contains(t, markdown, "- Secret: uses `TRIAGE_TOKEN`")
+	assert.Contains(t, markdown, "- MCP server: `notion` added (allows `search`)")
+	assert.Contains(t, markdown, "- Trigger: `workflow_dispatch` added")
+
+	assert.Contains(t, renderWorkflowDiffsMarkdown(diffs[:1], "HEAD"), "No security-relevant changes.")
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,10 +22,15 @@ type FixConfig struct {
 	Write       bool
 	Verbose     bool
 	WorkflowDir string // Custom workflow directory
+	Permissions bool   // Reduce permissions to the minimal set instead of applying codemods
+	Report      bool   // With Permissions, only report over-granted scopes
 }
 
 // RunFix runs the fix command with the given configuration
 func RunFix(config FixConfig) error {
+	if config.Permissions {
+		return runFixPermissions(config.WorkflowIDs, config.Write, config.Report, config.Verbose, config.WorkflowDir)
+	}
 	return runFixCommand(config.WorkflowIDs, config.Write, config.Verbose, config.WorkflowDir)
 }
 
@@ -50,6 +56,13 @@ Available codemods:
 
 If no workflows are specified, all Markdown files in .github/workflows will be processed.
 
+With --permissions, the codemods are replaced by a least-privilege analysis. Each workflow is
+compiled (without writing lock files) to compute the minimal permissions its agent job needs:
+read access for the enabled GitHub MCP toolsets, contents: read for the checkout, and scopes that
+custom steps might use. Write scopes are removed when safe-output jobs perform the writes. With
+--write, the permissions block is rewritten with the reason for each scope as a comment.
+Add --report to only list over-granted scopes across every workflow in the repository.
+
 The command will:
   1. Scan workflow files for deprecated fields
   2. Apply relevant codemods to fix issues
@@ -68,23 +81,40 @@ Examples:
   ` + string(constants.CLIExtensionPrefix) + ` fix my-workflow         # Check specific workflow
   ` + string(constants.CLIExtensionPrefix) + ` fix my-workflow --write # Fix specific workflow
   ` + string(constants.CLIExtensionPrefix) + ` fix --dir custom/workflows # Fix workflows in custom directory
-  ` + string(constants.CLIExtensionPrefix) + ` fix --list-codemods     # List available codemods`,
+  ` + string(constants.CLIExtensionPrefix) + ` fix --list-codemods     # List available codemods
+  ` + string(constants.CLIExtensionPrefix) + ` fix --permissions       # Show permissions that can be reduced
+  ` + string(constants.CLIExtensionPrefix) + ` fix --permissions --write  # Rewrite permissions to the minimal set
+  ` + string(constants.CLIExtensionPrefix) + ` fix --permissions --report # Report over-granted scopes of all workflows`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			listCodemods, _ := cmd.Flags().GetBool("list-codemods")
 			write, _ := cmd.Flags().GetBool("write")
 			verbose, _ := cmd.Flags().GetBool("verbose")
 			dir, _ := cmd.Flags().GetString("dir")
+			permissions, _ := cmd.Flags().GetBool("permissions")
+			report, _ := cmd.Flags().GetBool("report")
 
 			if listCodemods {
 				return listAvailableCodemods()
 			}
 
+			if report && !permissions {
+				return errors.New("--report requires --permissions")
+			}
+			if report && write {
+				return errors.New("--report cannot be combined with --write")
+			}
+			if permissions {
+				return runFixPermissions(args, write, report, verbose, dir)
+			}
+
 			return runFixCommand(args, write, verbose, dir)
 		},
 	}
 
 	cmd.Flags().Bool("write", false, "Write changes to files (default is dry-run)")
 	cmd.Flags().Bool("list-codemods", false, "List all available codemods and exit")
+	cmd.Flags().Bool("permissions", false, "Reduce workflow permissions to the minimal set the agent job needs")
+	cmd.Flags().Bool("report", false, "With --permissions, only report over-granted scopes across all workflows")
 	cmd.Flags().StringP("dir", "d", "", "Workflow directory (default: .github/workflows)")
 
 	// Register completions
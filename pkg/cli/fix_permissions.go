@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/workflow"
+)
+
+var fixPermissionsLog = logger.New("cli:fix_permissions")
+
+// permissionsFixResult is the least-privilege analysis of one workflow
+type permissionsFixResult struct {
+	File      string
+	Inference *workflow.PermissionsInference
+	Missing   []workflow.PermissionScope // Required scopes not granted at the required level
+	Content   string                     // Workflow content with rewritten permissions
+}
+
+// changed reports whether the workflow permissions differ from the inferred minimum
+func (r *permissionsFixResult) changed() bool {
+	return len(r.Inference.OverGranted) > 0 || len(r.Missing) > 0
+}
+
+// runFixPermissions rewrites the permissions of workflows to the minimal set their agent job
+// needs. In report mode, it only lists the over-granted scopes of every workflow.
+func runFixPermissions(workflowIDs []string, write bool, report bool, verbose bool, workflowDir string) error {
+	fixPermissionsLog.Printf("Running permissions fix: workflowIDs=%v, write=%v, report=%v", workflowIDs, write, report)
+
+	if workflowDir == "" {
+		workflowDir = ".github/workflows"
+	} else {
+		workflowDir = filepath.Clean(workflowDir)
+	}
+
+	var files []string
+	if len(workflowIDs) > 0 {
+		for _, workflowID := range workflowIDs {
+			file, err := resolveWorkflowFileInDir(workflowID, verbose, workflowDir)
+			if err != nil {
+				return err
+			}
+			files = append(files, file)
+		}
+	} else {
+		var err error
+		files, err = getMarkdownWorkflowFiles(workflowDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage("No workflow files found."))
+		return nil
+	}
+
+	gitRoot, err := findGitRootForPath(files[0])
+	if err != nil {
+		gitRoot = filepath.Dir(files[0])
+	}
+	compiler := newInMemoryCompiler(gitRoot, verbose)
+
+	var results []*permissionsFixResult
+	for _, file := range files {
+		result, err := inferWorkflowPermissions(compiler, gitRoot, file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", console.FormatErrorMessage(fmt.Sprintf("Error processing %s: %v", filepath.Base(file), err)))
+			continue
+		}
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+
+	if report {
+		renderPermissionsReport(results)
+		return nil
+	}
+
+	var totalFixed int
+	for _, result := range results {
+		if !result.changed() {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "%s\n", console.FormatInfoMessage(fmt.Sprintf("  %s - permissions are minimal", filepath.Base(result.File))))
+			}
+			continue
+		}
+		totalFixed++
+
+		fileName := filepath.Base(result.File)
+		if write {
+			if err := os.WriteFile(result.File, []byte(result.Content), 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", fileName, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s\n", console.FormatSuccessMessage(fmt.Sprintf("✓ %s", fileName)))
+		} else {
+			fmt.Fprintf(os.Stderr, "%s\n", console.FormatWarningMessage(fmt.Sprintf("⚠ %s", fileName)))
+		}
+		for _, line := range describePermissionChanges(result) {
+			fmt.Fprintf(os.Stderr, "    • %s\n", line)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+	switch {
+	case totalFixed == 0:
+		fmt.Fprintf(os.Stderr, "%s\n", console.FormatInfoMessage("✓ Permissions are already minimal"))
+	case write:
+		fmt.Fprintf(os.Stderr, "%s\n", console.FormatSuccessMessage(fmt.Sprintf("✓ Reduced permissions of %d of %d workflow files", totalFixed, len(results))))
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n", console.FormatInfoMessage(fmt.Sprintf("Would reduce permissions of %d of %d workflow files", totalFixed, len(results))))
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage("To apply these changes, run:"))
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "  gh aw fix --permissions --write")
+	}
+	return nil
+}
+
+// inferWorkflowPermissions compiles a workflow without writing its lock file and infers the
+// minimal permissions of its agent job. Shared workflows return nil.
+func inferWorkflowPermissions(compiler *workflow.Compiler, gitRoot, file string) (*permissionsFixResult, error) {
+	data, lockYAML, err := compileInMemory(compiler, gitRoot, file)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	inference, err := workflow.InferAgentPermissions(data, lockYAML)
+	if err != nil {
+		return nil, err
+	}
+	result := &permissionsFixResult{File: file, Inference: inference}
+	for scope, level := range inference.Required {
+		if granted := inference.Granted[scope]; granted != level && granted != workflow.PermissionWrite {
+			result.Missing = append(result.Missing, scope)
+		}
+	}
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i] < result.Missing[j] })
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	result.Content = string(content)
+	if result.changed() {
+		result.Content, err = SetPermissionsInFrontmatter(result.Content, renderInferredPermissions(inference))
+		if err != nil {
+			return nil, err
+		}
+	}
+	fixPermissionsLog.Printf("Inferred permissions for %s: %d over-granted, %d missing", file, len(inference.OverGranted), len(result.Missing))
+	return result, nil
+}
+
+// renderInferredPermissions renders the permissions block with the reason for each scope
+func renderInferredPermissions(inference *workflow.PermissionsInference) []string {
+	if len(inference.Required) == 0 {
+		return []string{"permissions: {}"}
+	}
+
+	scopes := make([]string, 0, len(inference.Required))
+	for scope := range inference.Required {
+		scopes = append(scopes, string(scope))
+	}
+	sort.Strings(scopes)
+
+	lines := []string{"permissions:"}
+	for _, scope := range scopes {
+		permissionScope := workflow.PermissionScope(scope)
+		lines = append(lines, fmt.Sprintf("  %s: %s # %s", scope, inference.Required[permissionScope], inference.Reasons[permissionScope]))
+	}
+	return lines
+}
+
+// describePermissionChanges lists the permission changes of a workflow for display
+func describePermissionChanges(result *permissionsFixResult) []string {
+	var lines []string
+	for _, overGranted := range result.Inference.OverGranted {
+		if overGranted.Required == workflow.PermissionNone {
+			lines = append(lines, fmt.Sprintf("remove %s: %s (%s)", overGranted.Scope, overGranted.Granted, overGranted.Reason))
+		} else {
+			lines = append(lines, fmt.Sprintf("reduce %s: %s to %s (%s)", overGranted.Scope, overGranted.Granted, overGranted.Required, overGranted.Reason))
+		}
+	}
+	for _, scope := range result.Missing {
+		lines = append(lines, fmt.Sprintf("add %s: %s (%s)", scope, result.Inference.Required[scope], result.Inference.Reasons[scope]))
+	}
+	return lines
+}
+
+// renderPermissionsReport prints the over-granted scopes of every workflow as a table
+func renderPermissionsReport(results []*permissionsFixResult) {
+	var rows [][]string
+	workflows := 0
+	for _, result := range results {
+		if len(result.Inference.OverGranted) == 0 {
+			continue
+		}
+		workflows++
+		for _, overGranted := range result.Inference.OverGranted {
+			rows = append(rows, []string{
+				strings.TrimSuffix(filepath.Base(result.File), ".md"),
+				string(overGranted.Scope),
+				string(overGranted.Granted),
+				string(overGranted.Required),
+				overGranted.Reason,
+			})
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", console.FormatSuccessMessage(fmt.Sprintf("✓ No over-granted permissions in %d workflows", len(results))))
+		return
+	}
+
+	fmt.Fprint(os.Stderr, console.RenderTable(console.TableConfig{
+		Title:   "Over-granted permissions",
+		Headers: []string{"Workflow", "Scope", "Granted", "Needed", "Reason"},
+		Rows:    rows,
+	}))
+	fmt.Fprintf(os.Stderr, "%s\n", console.FormatWarningMessage(fmt.Sprintf("%d over-granted scopes in %d of %d workflows", len(rows), workflows, len(results))))
+}
@@ -0,0 +1,112 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFixPermissions(t *testing.T) {
+	workflowsDir := filepath.Join(t.TempDir(), ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	triagePath := filepath.Join(workflowsDir, "triage.md")
+	triage := `---
+on:
+  issues:
+    types: [opened]
+strict: false
+features:
+  dangerous-permissions-write: true
+permissions:
+  contents: read
+  issues: write
+  discussions: read
+tools:
+  github:
+    toolsets: [issues]
+safe-outputs:
+  add-comment:
+---
+
+# Triage
+`
+	require.NoError(t, os.WriteFile(triagePath, []byte(triage), 0644))
+	minimalPath := filepath.Join(workflowsDir, "minimal.md")
+	minimal := `---
+on: workflow_dispatch
+permissions:
+  contents: read
+tools:
+  github:
+    toolsets: [context]
+---
+
+# Minimal
+`
+	require.NoError(t, os.WriteFile(minimalPath, []byte(minimal), 0644))
+
+	// Dry-run and report modes leave the files untouched
+	require.NoError(t, runFixPermissions(nil, false, false, false, workflowsDir))
+	require.NoError(t, runFixPermissions(nil, false, true, false, workflowsDir))
+	content, err := os.ReadFile(triagePath)
+	require.NoError(t, err)
+	assert.Equal(t, triage, string(content))
+
+	require.NoError(t, runFixPermissions(nil, true, false, false, workflowsDir))
+	content, err = os.ReadFile(triagePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `permissions:
+  contents: read # repository checkout
+  issues: read # github toolsets: issues
+tools:`)
+
+	content, err = os.ReadFile(minimalPath)
+	require.NoError(t, err)
+	assert.Equal(t, minimal, string(content), "minimal permissions should not be rewritten")
+}
+
+func TestDescribePermissionChanges(t *testing.T) {
+	result := &permissionsFixResult{
+		Inference: &workflow.PermissionsInference{
+			Required: map[workflow.PermissionScope]workflow.PermissionLevel{workflow.PermissionActions: workflow.PermissionRead},
+			Reasons:  map[workflow.PermissionScope]string{workflow.PermissionActions: "agentic-workflows tool"},
+			OverGranted: []workflow.OverGrantedPermission{
+				{Scope: workflow.PermissionIssues, Granted: workflow.PermissionWrite, Required: workflow.PermissionRead, Reason: "the agent job only reads"},
+				{Scope: workflow.PermissionPackages, Granted: workflow.PermissionRead, Required: workflow.PermissionNone, Reason: "not used by the agent job"},
+			},
+		},
+		Missing: []workflow.PermissionScope{workflow.PermissionActions},
+	}
+
+	assert.Equal(t, []string{
+		"reduce issues: write to read (the agent job only reads)",
+		"remove packages: read (not used by the agent job)",
+		"add actions: read (agentic-workflows tool)",
+	}, describePermissionChanges(result))
+}
+
+func TestRenderInferredPermissions(t *testing.T) {
+	inference := &workflow.PermissionsInference{
+		Required: map[workflow.PermissionScope]workflow.PermissionLevel{
+			workflow.PermissionIssues:   workflow.PermissionRead,
+			workflow.PermissionContents: workflow.PermissionRead,
+		},
+		Reasons: map[workflow.PermissionScope]string{
+			workflow.PermissionIssues:   "github toolsets: issues",
+			workflow.PermissionContents: "repository checkout",
+		},
+	}
+	assert.Equal(t, []string{
+		"permissions:",
+		"  contents: read # repository checkout",
+		"  issues: read # github toolsets: issues",
+	}, renderInferredPermissions(inference))
+
+	assert.Equal(t, []string{"permissions: {}"}, renderInferredPermissions(&workflow.PermissionsInference{}))
+}
@@ -453,3 +453,162 @@ func SetFieldInOnTrigger(content, fieldName, fieldValue string) (string, error)
 	frontmatterEditorLog.Printf("No raw frontmatter lines available")
 	return "", fmt.Errorf("no frontmatter lines available to modify")
 }
+
+// SetNetworkAllowedInFrontmatter replaces the 'allowed' list of the 'network' block in the
+// frontmatter, or adds a network block when the workflow has none. A scalar network value
+// such as "network: defaults" is replaced by a block. Other network settings and the rest
+// of the frontmatter keep their original formatting.
+func SetNetworkAllowedInFrontmatter(content string, allowed []string) (string, error) {
+	frontmatterEditorLog.Printf("Setting network.allowed to %d entries", len(allowed))
+
+	frontmatterLines, markdown, err := parseFrontmatterLines(content)
+	if err != nil {
+		return "", err
+	}
+
+	// reconstructContent trims the trailing newline of the markdown body; keep it
+	reconstruct := func(lines []string) string {
+		updated := reconstructContent(lines, markdown)
+		if strings.HasSuffix(content, "\n") && !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		return updated
+	}
+
+	networkIndex := -1
+	for i, line := range frontmatterLines {
+		if isTopLevelKey(line) && strings.HasPrefix(line, "network:") {
+			networkIndex = i
+			break
+		}
+	}
+
+	allowedLines := func(indent string) []string {
+		lines := []string{indent + "allowed:"}
+		for _, entry := range allowed {
+			// Wildcard domains must be quoted, since "*" starts a YAML alias
+			if strings.HasPrefix(entry, "*") {
+				entry = fmt.Sprintf("%q", entry)
+			}
+			lines = append(lines, indent+"  - "+entry)
+		}
+		return lines
+	}
+
+	// No network block: add one at the end of the frontmatter
+	if networkIndex < 0 {
+		frontmatterLines = append(frontmatterLines, "network:")
+		frontmatterLines = append(frontmatterLines, allowedLines("  ")...)
+		return reconstruct(frontmatterLines), nil
+	}
+
+	// Scalar network value: replace it with a block
+	_, value, _ := strings.Cut(frontmatterLines[networkIndex], ":")
+	value = strings.TrimSpace(value)
+	if value != "" && !strings.HasPrefix(value, "#") {
+		if strings.HasPrefix(value, "{") {
+			return "", fmt.Errorf("network is written as a flow mapping; rewrite it as a block to update network.allowed")
+		}
+		result := append([]string{}, frontmatterLines[:networkIndex]...)
+		result = append(result, "network:")
+		result = append(result, allowedLines("  ")...)
+		result = append(result, frontmatterLines[networkIndex+1:]...)
+		return reconstruct(result), nil
+	}
+
+	// Find the indentation of the network settings and the existing allowed list
+	childIndent := ""
+	allowedIndex := -1
+	for i := networkIndex + 1; i < len(frontmatterLines); i++ {
+		line := frontmatterLines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if getIndentation(line) == "" {
+			break
+		}
+		if childIndent == "" {
+			childIndent = getIndentation(line)
+		}
+		if getIndentation(line) == childIndent && (trimmed == "allowed:" || strings.HasPrefix(trimmed, "allowed:")) {
+			allowedIndex = i
+			break
+		}
+	}
+
+	if childIndent == "" {
+		childIndent = "  "
+	}
+	if allowedIndex < 0 {
+		result := append([]string{}, frontmatterLines[:networkIndex+1]...)
+		result = append(result, allowedLines(childIndent)...)
+		result = append(result, frontmatterLines[networkIndex+1:]...)
+		return reconstruct(result), nil
+	}
+
+	// Skip the existing list items, which are indented further or written as "- " at the same level
+	end := allowedIndex + 1
+	for end < len(frontmatterLines) {
+		line := frontmatterLines[end]
+		trimmed := strings.TrimSpace(line)
+		indent := getIndentation(line)
+		if trimmed == "" || !(len(indent) > len(childIndent) || (indent == childIndent && strings.HasPrefix(trimmed, "- "))) {
+			break
+		}
+		end++
+	}
+
+	result := append([]string{}, frontmatterLines[:allowedIndex]...)
+	result = append(result, allowedLines(childIndent)...)
+	result = append(result, frontmatterLines[end:]...)
+	return reconstruct(result), nil
+}
+
+// SetPermissionsInFrontmatter replaces the top-level 'permissions' block of the frontmatter with
+// the given lines, or appends them when the workflow has no permissions. Comments directly
+// inside the old block are dropped along with it.
+func SetPermissionsInFrontmatter(content string, permissionsLines []string) (string, error) {
+	frontmatterEditorLog.Printf("Setting permissions block with %d lines", len(permissionsLines))
+
+	frontmatterLines, markdown, err := parseFrontmatterLines(content)
+	if err != nil {
+		return "", err
+	}
+
+	start := -1
+	for i, line := range frontmatterLines {
+		if isTopLevelKey(line) && strings.HasPrefix(line, "permissions:") {
+			start = i
+			break
+		}
+	}
+
+	var result []string
+	if start < 0 {
+		result = append(append(result, frontmatterLines...), permissionsLines...)
+	} else {
+		end := start + 1
+		for end < len(frontmatterLines) {
+			line := frontmatterLines[end]
+			if strings.TrimSpace(line) != "" && getIndentation(line) == "" {
+				break
+			}
+			end++
+		}
+		// Keep blank lines that separate the block from the next key
+		for end > start+1 && strings.TrimSpace(frontmatterLines[end-1]) == "" {
+			end--
+		}
+		result = append(result, frontmatterLines[:start]...)
+		result = append(result, permissionsLines...)
+		result = append(result, frontmatterLines[end:]...)
+	}
+
+	updated := reconstructContent(result, markdown)
+	// reconstructContent trims the trailing newline of the markdown body; keep it
+	if strings.HasSuffix(content, "\n") && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	return updated, nil
+}
@@ -212,3 +212,100 @@ permissions:
 		})
 	}
 }
+
+func TestSetNetworkAllowedInFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		allowed     []string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "adds network block when missing",
+			content:  "---\non: push\n---\n\n# Test\n",
+			allowed:  []string{"defaults", "python"},
+			expected: "---\non: push\nnetwork:\n  allowed:\n    - defaults\n    - python\n---\n\n# Test\n",
+		},
+		{
+			name:     "replaces scalar network value",
+			content:  "---\non: push\nnetwork: defaults\npermissions: read-all\n---\n\n# Test\n",
+			allowed:  []string{"defaults", "node"},
+			expected: "---\non: push\nnetwork:\n  allowed:\n    - defaults\n    - node\npermissions: read-all\n---\n\n# Test\n",
+		},
+		{
+			name:     "replaces existing allowed list and keeps other settings",
+			content:  "---\nnetwork:\n    allowed:\n    - defaults\n    - example.com\n    blocked:\n      - tracker.example.com\non: push\n---\n\n# Test\n",
+			allowed:  []string{"defaults", "go"},
+			expected: "---\nnetwork:\n    allowed:\n      - defaults\n      - go\n    blocked:\n      - tracker.example.com\non: push\n---\n\n# Test\n",
+		},
+		{
+			name:     "inserts allowed list into network block",
+			content:  "---\nnetwork:\n  firewall: true\n---\n\n# Test\n",
+			allowed:  []string{"*.example.com"},
+			expected: "---\nnetwork:\n  allowed:\n    - \"*.example.com\"\n  firewall: true\n---\n\n# Test\n",
+		},
+		{
+			name:        "flow mapping is rejected",
+			content:     "---\nnetwork: {allowed: [defaults]}\n---\n\n# Test\n",
+			allowed:     []string{"defaults"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SetNetworkAllowedInFrontmatter(tt.content, tt.allowed)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Unexpected result:\n%s\nexpected:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetPermissionsInFrontmatter(t *testing.T) {
+	block := []string{"permissions:", "  contents: read # repository checkout"}
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "replaces existing block",
+			content:  "---\non: push\npermissions:\n  # comment\n  contents: write\n  issues: write\n\ntools:\n  github:\n---\n\n# Test\n",
+			expected: "---\non: push\npermissions:\n  contents: read # repository checkout\n\ntools:\n  github:\n---\n\n# Test\n",
+		},
+		{
+			name:     "replaces shorthand",
+			content:  "---\npermissions: read-all\non: push\n---\n\n# Test\n",
+			expected: "---\npermissions:\n  contents: read # repository checkout\non: push\n---\n\n# Test\n",
+		},
+		{
+			name:     "appends missing block",
+			content:  "---\non: push\n---\n\n# Test\n",
+			expected: "---\non: push\npermissions:\n  contents: read # repository checkout\n---\n\n# Test\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SetPermissionsInFrontmatter(tt.content, block)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Unexpected result:\n%s\nexpected:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ type HealthConfig struct {
 	Verbose      bool
 	JSONOutput   bool
 	RepoOverride string
+	Cached       bool   // Read runs from the local run index instead of the GitHub API
+	OutputDir    string // Logs directory containing the run index (used with Cached)
 }
 
 // NewHealthCommand creates the health command
@@ -49,7 +51,8 @@ Examples:
   ` + string(constants.CLIExtensionPrefix) + ` health --days 30             # Summary for last 30 days
   ` + string(constants.CLIExtensionPrefix) + ` health --threshold 90        # Alert if below 90% success rate
   ` + string(constants.CLIExtensionPrefix) + ` health --json                # Output in JSON format
-  ` + string(constants.CLIExtensionPrefix) + ` health issue-monster --days 90  # 90-day metrics for workflow`,
+  ` + string(constants.CLIExtensionPrefix) + ` health issue-monster --days 90  # 90-day metrics for workflow
+  ` + string(constants.CLIExtensionPrefix) + ` health --cached              # Use runs already downloaded by 'logs'`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			days, _ := cmd.Flags().GetInt("days")
@@ -57,6 +60,8 @@ Examples:
 			verbose, _ := cmd.Flags().GetBool("verbose")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
 			repoOverride, _ := cmd.Flags().GetString("repo")
+			cached, _ := cmd.Flags().GetBool("cached")
+			outputDir, _ := cmd.Flags().GetString("output")
 
 			var workflowName string
 			if len(args) > 0 {
@@ -70,6 +75,8 @@ Examples:
 				Verbose:      verbose,
 				JSONOutput:   jsonOutput,
 				RepoOverride: repoOverride,
+				Cached:       cached,
+				OutputDir:    outputDir,
 			}
 
 			return RunHealth(config)
@@ -79,6 +86,8 @@ Examples:
 	// Add flags
 	cmd.Flags().Int("days", 7, "Number of days to analyze (7, 30, or 90)")
 	cmd.Flags().Float64("threshold", 80.0, "Success rate threshold for warnings (percentage)")
+	cmd.Flags().Bool("cached", false, "Compute health from runs already downloaded by 'logs' (reads the run index, no API calls)")
+	cmd.Flags().StringP("output", "o", defaultLogsOutputDir, "Logs directory to read when using --cached")
 	addRepoFlag(cmd)
 	addJSONFlag(cmd)
 
@@ -100,14 +109,23 @@ func RunHealth(config HealthConfig) error {
 	// Calculate start date
 	startDate := time.Now().AddDate(0, 0, -config.Days).Format("2006-01-02")
 
-	if config.Verbose {
-		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage(fmt.Sprintf("Fetching workflow runs since %s", startDate)))
-	}
+	var runs []WorkflowRun
+	var err error
+	if config.Cached {
+		runs, err = loadCachedWorkflowRuns(config.OutputDir, config.WorkflowName, startDate, config.Verbose)
+		if err != nil {
+			return fmt.Errorf("failed to load cached workflow runs: %w", err)
+		}
+	} else {
+		if config.Verbose {
+			fmt.Fprintln(os.Stderr, console.FormatVerboseMessage(fmt.Sprintf("Fetching workflow runs since %s", startDate)))
+		}
 
-	// Fetch workflow runs from GitHub
-	runs, err := fetchWorkflowRuns(config.WorkflowName, startDate, config.RepoOverride, config.Verbose)
-	if err != nil {
-		return fmt.Errorf("failed to fetch workflow runs: %w", err)
+		// Fetch workflow runs from GitHub
+		runs, err = fetchWorkflowRuns(config.WorkflowName, startDate, config.RepoOverride, config.Verbose)
+		if err != nil {
+			return fmt.Errorf("failed to fetch workflow runs: %w", err)
+		}
 	}
 
 	if len(runs) == 0 {
@@ -187,6 +205,36 @@ func fetchWorkflowRuns(workflowName, startDate, repoOverride string, verbose boo
 	return allRuns, nil
 }
 
+// loadCachedWorkflowRuns reads the runs created since startDate from the run index of a logs
+// directory. The workflow name matches either the workflow's display name or its workflow ID.
+func loadCachedWorkflowRuns(outputDir, workflowName, startDate string, verbose bool) ([]WorkflowRun, error) {
+	healthLog.Printf("Loading cached workflow runs: dir=%s, workflow=%s, startDate=%s", outputDir, workflowName, startDate)
+
+	index, err := OpenRunIndex(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := index.Query(RunIndexFilter{WorkflowName: workflowName, StartDate: startDate})
+	runs := make([]WorkflowRun, 0, len(records))
+	for _, record := range records {
+		run := record.Summary.Run
+		if run.Duration == 0 && !run.StartedAt.IsZero() && !run.UpdatedAt.IsZero() {
+			run.Duration = run.UpdatedAt.Sub(run.StartedAt)
+		}
+		if workflowName != "" {
+			// Group under the requested name so the detailed view matches the argument
+			run.WorkflowName = workflowName
+		}
+		runs = append(runs, run)
+	}
+
+	if verbose {
+		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage(fmt.Sprintf("Loaded %d of %d indexed runs from %s", len(runs), index.Len(), outputDir)))
+	}
+	return runs, nil
+}
+
 // displayHealthSummary displays a summary of health metrics for all workflows
 func displayHealthSummary(runs []WorkflowRun, config HealthConfig) error {
 	healthLog.Printf("Displaying health summary: %d runs", len(runs))
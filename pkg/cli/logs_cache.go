@@ -86,5 +86,14 @@ func saveRunSummary(outputDir string, summary *RunSummary, verbose bool) error {
 		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Saved run summary to %s", summaryPath)))
 	}
 
+	// Record the run in the logs directory's run index; the per-run summary remains the source
+	// of truth, so a failure here only costs a rescan later
+	if err := indexRunSummary(outputDir, summary); err != nil {
+		logsCacheLog.Printf("Failed to update run index: %v", err)
+		if verbose {
+			fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to update run index: %v", err)))
+		}
+	}
+
 	return nil
 }
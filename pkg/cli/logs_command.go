@@ -108,7 +108,9 @@ Examples:
   ` + string(constants.CLIExtensionPrefix) + ` logs --parse                   # Parse logs and generate Markdown reports
   ` + string(constants.CLIExtensionPrefix) + ` logs --json                    # Output metrics in JSON format
   ` + string(constants.CLIExtensionPrefix) + ` logs --parse --json            # Generate both Markdown and JSON
-  ` + string(constants.CLIExtensionPrefix) + ` logs weekly-research --repo owner/repo  # Download logs from specific repository`,
+  ` + string(constants.CLIExtensionPrefix) + ` logs weekly-research --repo owner/repo  # Download logs from specific repository
+  ` + string(constants.CLIExtensionPrefix) + ` logs --report --start-date -1mo --group-by engine  # Cost per engine this month (from cache)
+  ` + string(constants.CLIExtensionPrefix) + ` logs --report --bucket week --format csv         # Weekly usage per workflow as CSV`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			logsCommandLog.Printf("Starting logs command: args=%d", len(args))
 
@@ -169,6 +171,7 @@ Examples:
 			repoOverride, _ := cmd.Flags().GetString("repo")
 			summaryFile, _ := cmd.Flags().GetString("summary-file")
 			safeOutputType, _ := cmd.Flags().GetString("safe-output")
+			report, _ := cmd.Flags().GetBool("report")
 
 			// Resolve relative dates to absolute dates for GitHub CLI
 			now := time.Now()
@@ -201,6 +204,24 @@ Examples:
 				}
 			}
 
+			if report {
+				groupBy, _ := cmd.Flags().GetString("group-by")
+				bucket, _ := cmd.Flags().GetString("bucket")
+				format, _ := cmd.Flags().GetString("format")
+				if jsonOutput && !cmd.Flags().Changed("format") {
+					format = "json"
+				}
+				return RunLogsUsageReport(outputDir, UsageReportOptions{
+					GroupBy:      groupBy,
+					Bucket:       bucket,
+					Format:       format,
+					WorkflowName: workflowName,
+					Engine:       engine,
+					StartDate:    startDate,
+					EndDate:      endDate,
+				}, verbose)
+			}
+
 			logsCommandLog.Printf("Executing logs download: workflow=%s, count=%d, engine=%s", workflowName, count, engine)
 
 			return DownloadWorkflowLogs(cmd.Context(), workflowName, count, startDate, endDate, outputDir, engine, ref, beforeRunID, afterRunID, repoOverride, verbose, toolGraph, noStaged, firewallOnly, noFirewall, parse, jsonOutput, timeout, summaryFile, safeOutputType)
@@ -226,6 +247,10 @@ Examples:
 	addJSONFlag(logsCmd)
 	logsCmd.Flags().Int("timeout", 0, "Download timeout in seconds (0 = no timeout)")
 	logsCmd.Flags().String("summary-file", "summary.json", "Path to write the summary JSON file relative to output directory (use empty string to disable)")
+	logsCmd.Flags().Bool("report", false, "Build a cost/usage report from the cached run summaries in the output directory instead of downloading runs")
+	logsCmd.Flags().String("group-by", "workflow", "Group the usage report by: workflow, engine, model, actor, event")
+	logsCmd.Flags().String("bucket", "day", "Time bucket for usage report trends: day, week")
+	logsCmd.Flags().String("format", "markdown", "Usage report output format: markdown, csv, json")
 	logsCmd.MarkFlagsMutuallyExclusive("firewall", "no-firewall")
 
 	// Register completions for logs command
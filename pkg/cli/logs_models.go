@@ -76,6 +76,7 @@ type ProcessedRun struct {
 	Noops                   []NoopReport
 	MCPFailures             []MCPFailureReport
 	MCPToolUsage            *MCPToolUsageData
+	PatchRejections         []PatchRejectionReport
 	JobDetails              []JobInfoWithDuration
 }
 
@@ -245,6 +246,8 @@ type AwInfo struct {
 	RunID      any    `json:"run_id,omitempty"`
 	RunNumber  any    `json:"run_number,omitempty"`
 	Repository string `json:"repository,omitempty"`
+	Actor      string `json:"actor,omitempty"`
+	EventName  string `json:"event_name,omitempty"`
 }
 
 // GetFirewallVersion returns the AWF firewall version, preferring the new field name
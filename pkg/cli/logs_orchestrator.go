@@ -13,7 +13,6 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -196,6 +195,13 @@ func DownloadWorkflowLogs(ctx context.Context, workflowName string, count int, s
 
 			downloadResults := downloadRunArtifactsConcurrent(ctx, chunk, outputDir, verbose, remainingNeeded)
 
+			// Downloaded and cached runs are recorded in the run index, so the engine and safe
+			// output filters are answered from the index instead of reading every run folder
+			var indexedRuns map[int64]bool
+			if engine != "" || safeOutputType != "" {
+				indexedRuns = selectIndexedRuns(outputDir, RunIndexFilter{Engine: engine, SafeOutputType: safeOutputType})
+			}
+
 			for _, result := range downloadResults {
 				if result.Skipped {
 					if verbose {
@@ -221,8 +227,17 @@ func DownloadWorkflowLogs(ctx context.Context, workflowName string, count int, s
 					awInfo, awInfoErr = parseAwInfo(awInfoPath, verbose)
 				}
 
-				// Apply engine filtering if specified
-				if engine != "" {
+				// Apply the engine and safe output filters of indexed runs
+				matchesIndex, indexed := indexedRuns[result.Run.DatabaseID]
+				if indexed && !matchesIndex {
+					if verbose {
+						fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Skipping run %d: does not match the engine or safe output filter", result.Run.DatabaseID)))
+					}
+					continue
+				}
+
+				// Apply engine filtering to runs missing from the index
+				if engine != "" && !indexed {
 					// Check if the run's engine matches the filter
 					detectedEngine := extractEngineFromAwInfo(awInfoPath, verbose)
 
@@ -295,8 +310,8 @@ func DownloadWorkflowLogs(ctx context.Context, workflowName string, count int, s
 					}
 				}
 
-				// Apply safe output type filtering if --safe-output flag is specified
-				if safeOutputType != "" {
+				// Apply safe output type filtering to runs missing from the index
+				if safeOutputType != "" && !indexed {
 					hasSafeOutputType, checkErr := runContainsSafeOutputType(result.LogsPath, safeOutputType, verbose)
 					if checkErr != nil && verbose {
 						fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to check safe output type for run %d: %v", result.Run.DatabaseID, checkErr)))
@@ -808,57 +823,9 @@ func normalizeSafeOutputType(safeOutputType string) string {
 
 // runContainsSafeOutputType checks if a run's agent_output.json contains a specific safe output type
 func runContainsSafeOutputType(runDir string, safeOutputType string, verbose bool) (bool, error) {
-	// Normalize the type for comparison (convert dashes to underscores)
-	normalizedType := normalizeSafeOutputType(safeOutputType)
-
-	// Look for agent_output.json in the run directory
-	agentOutputPath := filepath.Join(runDir, constants.AgentOutputFilename)
-
-	// Support both new flattened form and old directory form
-	if stat, err := os.Stat(agentOutputPath); err != nil || stat.IsDir() {
-		// Try old structure
-		oldPath := filepath.Join(runDir, constants.AgentOutputArtifactName, constants.AgentOutputArtifactName)
-		if _, err := os.Stat(oldPath); err == nil {
-			agentOutputPath = oldPath
-		} else {
-			// No agent_output.json found
-			return false, nil
-		}
-	}
-
-	// Read the file
-	content, err := os.ReadFile(agentOutputPath)
+	counts, err := loadSafeOutputTypeCounts(runDir)
 	if err != nil {
-		// File doesn't exist or can't be read
-		return false, nil
-	}
-
-	// Parse the JSON
-	var safeOutput struct {
-		Items []json.RawMessage `json:"items"`
-	}
-
-	if err := json.Unmarshal(content, &safeOutput); err != nil {
-		return false, fmt.Errorf("failed to parse agent_output.json: %w", err)
-	}
-
-	// Check each item for the specified type
-	for _, itemRaw := range safeOutput.Items {
-		var item struct {
-			Type string `json:"type"`
-		}
-
-		if err := json.Unmarshal(itemRaw, &item); err != nil {
-			continue // Skip malformed items
-		}
-
-		// Normalize the item type for comparison
-		normalizedItemType := normalizeSafeOutputType(item.Type)
-
-		if normalizedItemType == normalizedType {
-			return true, nil
-		}
+		return false, err
 	}
-
-	return false, nil
+	return counts[normalizeSafeOutputType(safeOutputType)] > 0, nil
 }
@@ -94,7 +94,7 @@ func extractEngineFromAwInfo(infoFilePath string, verbose bool) workflow.CodingA
 		return nil
 	}
 
-	registry := workflow.GetGlobalEngineRegistry()
+	registry := workflow.GetRepositoryEngineRegistry()
 	engine, err := registry.GetEngine(info.EngineID)
 	if err != nil {
 		logsParsingCoreLog.Printf("Unknown engine: %s", info.EngineID)
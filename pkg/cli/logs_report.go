@@ -1,16 +1,21 @@
 package cli
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
 	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/sliceutil"
 	"github.com/github/gh-aw/pkg/timeutil"
 	"github.com/github/gh-aw/pkg/workflow"
 )
@@ -925,3 +930,394 @@ func renderLogsConsole(data LogsData) {
 			len(data.ToolUsage))
 	}
 }
+
+// Usage report group-by dimensions, time buckets, and output formats
+var (
+	usageReportGroupBys = []string{"workflow", "engine", "model", "actor", "event"}
+	usageReportBuckets  = []string{"day", "week"}
+	usageReportFormats  = []string{"markdown", "csv", "json"}
+)
+
+// usageReportUnknownKey is the group key used when a run lacks the grouped attribute
+const usageReportUnknownKey = "(unknown)"
+
+// sparklineLevels are the block characters used to render trends, lowest to highest
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// UsageReportOptions configures the cross-run cost and usage report
+type UsageReportOptions struct {
+	GroupBy      string // workflow, engine, model, actor, or event
+	Bucket       string // day or week
+	Format       string // markdown, csv, or json
+	WorkflowName string // Only include runs of this workflow (GitHub Actions workflow name)
+	Engine       string // Only include runs of this engine
+	StartDate    string // Only include runs created on or after this date (YYYY-MM-DD)
+	EndDate      string // Only include runs created on or before this date (YYYY-MM-DD)
+}
+
+// UsageReport aggregates token usage and cost across cached runs, grouped by a dimension and time bucket
+type UsageReport struct {
+	GroupBy string             `json:"group_by"`
+	Bucket  string             `json:"bucket"`
+	Buckets []string           `json:"buckets"` // Bucket start dates (YYYY-MM-DD), oldest first
+	Groups  []UsageReportGroup `json:"groups"`  // Sorted by cost, then tokens, descending
+	Totals  UsageReportTotals  `json:"totals"`
+}
+
+// UsageReportGroup contains the usage of one group with a point per time bucket
+type UsageReportGroup struct {
+	Key           string             `json:"key"`
+	Runs          int                `json:"runs"`
+	TokenUsage    int                `json:"token_usage"`
+	EstimatedCost float64            `json:"estimated_cost"`
+	Turns         int                `json:"turns"`
+	Trend         string             `json:"trend"` // Sparkline of cost per bucket (tokens when no cost is reported)
+	Series        []UsageReportPoint `json:"series"`
+}
+
+// UsageReportPoint is the usage of a group within one time bucket
+type UsageReportPoint struct {
+	Bucket        string  `json:"bucket"`
+	Runs          int     `json:"runs"`
+	TokenUsage    int     `json:"token_usage"`
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// UsageReportTotals contains the totals across all groups
+type UsageReportTotals struct {
+	Runs          int     `json:"runs"`
+	TokenUsage    int     `json:"token_usage"`
+	EstimatedCost float64 `json:"estimated_cost"`
+	Turns         int     `json:"turns"`
+}
+
+// usageReportEntry is a single cached run reduced to the fields the report needs
+type usageReportEntry struct {
+	CreatedAt     time.Time
+	Workflow      string
+	Engine        string
+	Model         string
+	Actor         string
+	Event         string
+	TokenUsage    int
+	EstimatedCost float64
+	Turns         int
+}
+
+// key returns the entry's value for the group-by dimension
+func (e usageReportEntry) key(groupBy string) string {
+	var key string
+	switch groupBy {
+	case "engine":
+		key = e.Engine
+	case "model":
+		key = e.Model
+	case "actor":
+		key = e.Actor
+	case "event":
+		key = e.Event
+	default:
+		key = e.Workflow
+	}
+	if key == "" {
+		return usageReportUnknownKey
+	}
+	return key
+}
+
+// ValidateUsageReportOptions checks the group-by, bucket, and format values
+func ValidateUsageReportOptions(opts UsageReportOptions) error {
+	if !sliceutil.Contains(usageReportGroupBys, opts.GroupBy) {
+		return fmt.Errorf("invalid --group-by value '%s'. Must be one of: %s", opts.GroupBy, strings.Join(usageReportGroupBys, ", "))
+	}
+	if !sliceutil.Contains(usageReportBuckets, opts.Bucket) {
+		return fmt.Errorf("invalid --bucket value '%s'. Must be one of: %s", opts.Bucket, strings.Join(usageReportBuckets, ", "))
+	}
+	if !sliceutil.Contains(usageReportFormats, opts.Format) {
+		return fmt.Errorf("invalid --format value '%s'. Must be one of: %s", opts.Format, strings.Join(usageReportFormats, ", "))
+	}
+	return nil
+}
+
+// RunLogsUsageReport builds the usage report from the run summaries cached in outputDir and writes it
+// to stdout. Nothing is downloaded: run `gh aw logs` first to populate the cache.
+func RunLogsUsageReport(outputDir string, opts UsageReportOptions, verbose bool) error {
+	reportLog.Printf("Building usage report: dir=%s, group_by=%s, bucket=%s, format=%s", outputDir, opts.GroupBy, opts.Bucket, opts.Format)
+
+	if err := ValidateUsageReportOptions(opts); err != nil {
+		return err
+	}
+
+	entries, err := loadUsageReportEntries(outputDir, verbose)
+	if err != nil {
+		return err
+	}
+	entries = filterUsageReportEntries(entries, opts)
+
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("No cached runs found in %s matching the filters. Run '%s logs' first to download runs.", outputDir, string(constants.CLIExtensionPrefix))))
+	} else if verbose {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Building usage report from %d cached runs", len(entries))))
+	}
+
+	report := buildUsageReport(entries, opts.GroupBy, opts.Bucket)
+
+	switch opts.Format {
+	case "csv":
+		return renderUsageReportCSV(os.Stdout, report)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		_, err := fmt.Fprint(os.Stdout, renderUsageReportMarkdown(report))
+		return err
+	}
+}
+
+// loadUsageReportEntries reads the cached runs below outputDir from the run index. Unlike
+// loadRunSummary, summaries written by other CLI versions are accepted because the report only
+// needs the run metadata and usage metrics.
+func loadUsageReportEntries(outputDir string, verbose bool) ([]usageReportEntry, error) {
+	index, err := OpenRunIndex(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := index.Query(RunIndexFilter{})
+	entries := make([]usageReportEntry, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		summary := record.Summary
+		entry := usageReportEntry{
+			CreatedAt:     summary.Run.CreatedAt,
+			Workflow:      summary.Run.WorkflowName,
+			Engine:        record.EngineID,
+			Model:         record.Model,
+			Actor:         record.Actor,
+			Event:         summary.Run.Event,
+			TokenUsage:    summary.Run.TokenUsage,
+			EstimatedCost: summary.Run.EstimatedCost,
+			Turns:         summary.Run.Turns,
+		}
+		if entry.Event == "" {
+			entry.Event = record.EventName
+		}
+		if entry.TokenUsage == 0 {
+			entry.TokenUsage = summary.Metrics.TokenUsage
+		}
+		if entry.EstimatedCost == 0 {
+			entry.EstimatedCost = summary.Metrics.EstimatedCost
+		}
+		if entry.Turns == 0 {
+			entry.Turns = summary.Metrics.Turns
+		}
+		entries = append(entries, entry)
+	}
+
+	reportLog.Printf("Loaded %d cached runs for usage report", len(entries))
+	if verbose {
+		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage(fmt.Sprintf("Loaded %d runs from the run index", len(entries))))
+	}
+	return entries, nil
+}
+
+// filterUsageReportEntries applies the workflow, engine, and date filters
+func filterUsageReportEntries(entries []usageReportEntry, opts UsageReportOptions) []usageReportEntry {
+	var filtered []usageReportEntry
+	for _, entry := range entries {
+		if opts.WorkflowName != "" && entry.Workflow != opts.WorkflowName {
+			continue
+		}
+		if opts.Engine != "" && entry.Engine != opts.Engine {
+			continue
+		}
+		day := entry.CreatedAt.UTC().Format("2006-01-02")
+		if opts.StartDate != "" && day < opts.StartDate {
+			continue
+		}
+		if opts.EndDate != "" && day > opts.EndDate {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// usageReportBucket returns the start date (YYYY-MM-DD, UTC) of the bucket containing t.
+// Weeks start on Monday.
+func usageReportBucket(t time.Time, bucket string) string {
+	day := time.Date(t.UTC().Year(), t.UTC().Month(), t.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == "week" {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day.Format("2006-01-02")
+}
+
+// buildUsageReport groups entries by the dimension and time bucket. Every group has a point for
+// every bucket between the oldest and newest run so that trends line up across groups.
+func buildUsageReport(entries []usageReportEntry, groupBy, bucket string) UsageReport {
+	report := UsageReport{GroupBy: groupBy, Bucket: bucket, Buckets: []string{}, Groups: []UsageReportGroup{}}
+	if len(entries) == 0 {
+		return report
+	}
+
+	oldest, newest := entries[0].CreatedAt, entries[0].CreatedAt
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(oldest) {
+			oldest = entry.CreatedAt
+		}
+		if entry.CreatedAt.After(newest) {
+			newest = entry.CreatedAt
+		}
+	}
+	step := 1
+	if bucket == "week" {
+		step = 7
+	}
+	bucketIndex := make(map[string]int)
+	last := usageReportBucket(newest, bucket)
+	for day, _ := time.Parse("2006-01-02", usageReportBucket(oldest, bucket)); ; day = day.AddDate(0, 0, step) {
+		key := day.Format("2006-01-02")
+		bucketIndex[key] = len(report.Buckets)
+		report.Buckets = append(report.Buckets, key)
+		if key >= last {
+			break
+		}
+	}
+
+	groups := make(map[string]*UsageReportGroup)
+	for _, entry := range entries {
+		key := entry.key(groupBy)
+		group, exists := groups[key]
+		if !exists {
+			group = &UsageReportGroup{Key: key, Series: make([]UsageReportPoint, len(report.Buckets))}
+			for i, b := range report.Buckets {
+				group.Series[i].Bucket = b
+			}
+			groups[key] = group
+		}
+
+		group.Runs++
+		group.TokenUsage += entry.TokenUsage
+		group.EstimatedCost += entry.EstimatedCost
+		group.Turns += entry.Turns
+
+		point := &group.Series[bucketIndex[usageReportBucket(entry.CreatedAt, bucket)]]
+		point.Runs++
+		point.TokenUsage += entry.TokenUsage
+		point.EstimatedCost += entry.EstimatedCost
+
+		report.Totals.Runs++
+		report.Totals.TokenUsage += entry.TokenUsage
+		report.Totals.EstimatedCost += entry.EstimatedCost
+		report.Totals.Turns += entry.Turns
+	}
+
+	for _, group := range groups {
+		group.Trend = usageTrend(group.Series)
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.EstimatedCost != b.EstimatedCost {
+			return a.EstimatedCost > b.EstimatedCost
+		}
+		if a.TokenUsage != b.TokenUsage {
+			return a.TokenUsage > b.TokenUsage
+		}
+		return a.Key < b.Key
+	})
+
+	reportLog.Printf("Built usage report: %d groups, %d buckets", len(report.Groups), len(report.Buckets))
+	return report
+}
+
+// usageTrend renders the cost per bucket as a sparkline, falling back to tokens when no cost is reported
+func usageTrend(series []UsageReportPoint) string {
+	values := make([]float64, len(series))
+	hasCost := false
+	for i, point := range series {
+		values[i] = point.EstimatedCost
+		if point.EstimatedCost > 0 {
+			hasCost = true
+		}
+	}
+	if !hasCost {
+		for i, point := range series {
+			values[i] = float64(point.TokenUsage)
+		}
+	}
+	return renderSparkline(values)
+}
+
+// renderSparkline renders values as a string of block characters scaled to the maximum value.
+// Zero values use the lowest block so that gaps remain visible.
+func renderSparkline(values []float64) string {
+	maxValue := 0.0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if maxValue > 0 && v > 0 {
+			level = int(v / maxValue * float64(len(sparklineLevels)-1))
+			if level == 0 {
+				level = 1
+			}
+		}
+		sb.WriteRune(sparklineLevels[level])
+	}
+	return sb.String()
+}
+
+// renderUsageReportMarkdown renders the report as a Markdown table with one row per group
+func renderUsageReportMarkdown(report UsageReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Usage by %s\n\n", report.GroupBy)
+	if len(report.Buckets) > 0 {
+		fmt.Fprintf(&sb, "%d runs from %s to %s, trend per %s.\n\n", report.Totals.Runs, report.Buckets[0], report.Buckets[len(report.Buckets)-1], report.Bucket)
+	}
+
+	fmt.Fprintf(&sb, "| %s | Runs | Tokens | Cost | Turns | Trend |\n", strings.ToUpper(report.GroupBy[:1])+report.GroupBy[1:])
+	sb.WriteString("|---|---:|---:|---:|---:|---|\n")
+	for _, group := range report.Groups {
+		fmt.Fprintf(&sb, "| %s | %d | %d | $%.4f | %d | `%s` |\n",
+			strings.ReplaceAll(group.Key, "|", "\\|"), group.Runs, group.TokenUsage, group.EstimatedCost, group.Turns, group.Trend)
+	}
+	fmt.Fprintf(&sb, "| **Total** | **%d** | **%d** | **$%.4f** | **%d** | |\n",
+		report.Totals.Runs, report.Totals.TokenUsage, report.Totals.EstimatedCost, report.Totals.Turns)
+	return sb.String()
+}
+
+// renderUsageReportCSV writes the report in long format: one row per group and time bucket
+func renderUsageReportCSV(w io.Writer, report UsageReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{report.GroupBy, "bucket", "runs", "token_usage", "estimated_cost"}); err != nil {
+		return err
+	}
+	for _, group := range report.Groups {
+		for _, point := range group.Series {
+			if point.Runs == 0 {
+				continue
+			}
+			record := []string{
+				group.Key,
+				point.Bucket,
+				strconv.Itoa(point.Runs),
+				strconv.Itoa(point.TokenUsage),
+				strconv.FormatFloat(point.EstimatedCost, 'f', 6, 64),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
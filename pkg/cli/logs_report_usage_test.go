@@ -0,0 +1,144 @@
+//go:build !integration
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCachedRun writes a run folder with run_summary.json and aw_info.json like `gh aw logs` does
+func writeCachedRun(t *testing.T, outputDir string, run WorkflowRun, awInfo string) {
+	t.Helper()
+	runDir := filepath.Join(outputDir, fmt.Sprintf("run-%d", run.DatabaseID))
+	require.NoError(t, os.MkdirAll(runDir, 0755))
+
+	summary := RunSummary{CLIVersion: "old-version", RunID: run.DatabaseID, Run: run}
+	content, err := json.Marshal(summary)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, runSummaryFileName), content, 0644))
+	if awInfo != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(runDir, "aw_info.json"), []byte(awInfo), 0644))
+	}
+}
+
+func TestLoadUsageReportEntries(t *testing.T) {
+	outputDir := testutil.TempDir(t, "usage-report-*")
+	created := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	writeCachedRun(t, outputDir, WorkflowRun{DatabaseID: 1, WorkflowName: "Triage", Event: "issues", CreatedAt: created, TokenUsage: 1000, EstimatedCost: 0.1, Turns: 3},
+		`{"engine_id":"claude","model":"claude-sonnet-4","actor":"octocat"}`)
+	writeCachedRun(t, outputDir, WorkflowRun{DatabaseID: 2, WorkflowName: "Docs", CreatedAt: created}, "")
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "summary.json"), []byte("{}"), 0644))
+
+	entries, err := loadUsageReportEntries(outputDir, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "summaries from other CLI versions should still be used")
+
+	assert.Equal(t, "Triage", entries[0].Workflow)
+	assert.Equal(t, "claude", entries[0].Engine)
+	assert.Equal(t, "claude-sonnet-4", entries[0].Model)
+	assert.Equal(t, "octocat", entries[0].Actor)
+	assert.Equal(t, "issues", entries[0].Event)
+	assert.Equal(t, 1000, entries[0].TokenUsage)
+
+	assert.Equal(t, usageReportUnknownKey, entries[1].key("engine"), "runs without aw_info.json group under unknown")
+}
+
+func TestUsageReportBucket(t *testing.T) {
+	sunday := time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-03-08", usageReportBucket(sunday, "day"))
+	assert.Equal(t, "2026-03-02", usageReportBucket(sunday, "week"), "weeks start on Monday")
+	assert.Equal(t, "2026-03-09", usageReportBucket(sunday.Add(2*time.Hour), "week"))
+}
+
+func TestBuildUsageReport(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	entries := []usageReportEntry{
+		{CreatedAt: day(1), Workflow: "Triage", Engine: "claude", TokenUsage: 100, EstimatedCost: 0.10, Turns: 2},
+		{CreatedAt: day(3), Workflow: "Triage", Engine: "claude", TokenUsage: 300, EstimatedCost: 0.30, Turns: 4},
+		{CreatedAt: day(2), Workflow: "Docs", Engine: "copilot", TokenUsage: 1000, Turns: 1},
+	}
+
+	report := buildUsageReport(entries, "engine", "day")
+
+	assert.Equal(t, []string{"2026-03-01", "2026-03-02", "2026-03-03"}, report.Buckets, "buckets should be contiguous")
+	require.Len(t, report.Groups, 2)
+
+	claude := report.Groups[0]
+	assert.Equal(t, "claude", claude.Key, "groups are sorted by cost")
+	assert.Equal(t, 2, claude.Runs)
+	assert.Equal(t, 400, claude.TokenUsage)
+	assert.InDelta(t, 0.40, claude.EstimatedCost, 0.0001)
+	assert.Equal(t, "▃▁█", claude.Trend)
+	assert.Equal(t, 0, claude.Series[1].Runs)
+
+	copilot := report.Groups[1]
+	assert.Equal(t, "▁█▁", copilot.Trend, "trend falls back to tokens when no cost is reported")
+
+	assert.Equal(t, UsageReportTotals{Runs: 3, TokenUsage: 1400, EstimatedCost: 0.40, Turns: 7}, report.Totals)
+
+	weekly := buildUsageReport(entries, "workflow", "week")
+	assert.Equal(t, []string{"2026-02-23", "2026-03-02"}, weekly.Buckets)
+}
+
+func TestFilterUsageReportEntries(t *testing.T) {
+	entries := []usageReportEntry{
+		{CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Workflow: "Triage", Engine: "claude"},
+		{CreatedAt: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), Workflow: "Triage", Engine: "copilot"},
+		{CreatedAt: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Workflow: "Docs", Engine: "claude"},
+	}
+
+	assert.Len(t, filterUsageReportEntries(entries, UsageReportOptions{WorkflowName: "Triage"}), 2)
+	assert.Len(t, filterUsageReportEntries(entries, UsageReportOptions{Engine: "claude"}), 2)
+	assert.Len(t, filterUsageReportEntries(entries, UsageReportOptions{StartDate: "2026-03-05", EndDate: "2026-03-05"}), 1, "date bounds are inclusive")
+}
+
+func TestRenderSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", renderSparkline([]float64{0, 5, 10}))
+	assert.Equal(t, "▁▁", renderSparkline([]float64{0, 0}))
+	assert.Equal(t, "▂█", renderSparkline([]float64{0.001, 10}), "small non-zero values stay above the baseline")
+}
+
+func TestRenderUsageReportFormats(t *testing.T) {
+	entries := []usageReportEntry{
+		{CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Workflow: "Triage|bot", TokenUsage: 100, EstimatedCost: 0.5, Turns: 2},
+	}
+	report := buildUsageReport(entries, "workflow", "day")
+
+	markdown := renderUsageReportMarkdown(report)
+	assert.Contains(t, markdown, "| Workflow | Runs | Tokens | Cost | Turns | Trend |")
+	assert.Contains(t, markdown, "| Triage\\|bot | 1 | 100 | $0.5000 | 2 | `█` |", "pipes in keys should be escaped")
+
+	var csvOut bytes.Buffer
+	require.NoError(t, renderUsageReportCSV(&csvOut, report))
+	assert.Equal(t, "workflow,bucket,runs,token_usage,estimated_cost\nTriage|bot,2026-03-01,1,100,0.500000\n", csvOut.String())
+}
+
+func TestValidateUsageReportOptions(t *testing.T) {
+	valid := UsageReportOptions{GroupBy: "model", Bucket: "week", Format: "csv"}
+	require.NoError(t, ValidateUsageReportOptions(valid))
+
+	invalid := valid
+	invalid.GroupBy = "repo"
+	err := ValidateUsageReportOptions(invalid)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "workflow, engine, model, actor, event"))
+
+	invalid = valid
+	invalid.Bucket = "month"
+	require.Error(t, ValidateUsageReportOptions(invalid))
+
+	invalid = valid
+	invalid.Format = "xml"
+	require.Error(t, ValidateUsageReportOptions(invalid))
+}
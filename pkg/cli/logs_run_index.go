@@ -0,0 +1,583 @@
+// This file provides the persistent run index for downloaded workflow logs.
+//
+// The run index is a single append-only JSONL file (runs.jsonl) at the root of the logs
+// directory. Every processed run is appended as one record containing its RunSummary
+// (run metadata, LogMetrics, firewall analysis, MCP tool usage) together with the engine,
+// model, actor and safe-output counts. Commands such as `logs --report`, `health --cached`
+// and `audit` query the index instead of walking every run-<id> folder.
+//
+// # File Format
+//
+// The first line is a header with the schema version. Each following line is a record.
+// When a run is indexed again, a newer record is appended and the latest record wins on
+// load. The file is compacted (rewritten without superseded records) when it is opened
+// and contains duplicates, records that were migrated, or records of run folders that
+// were deleted.
+//
+// # Schema Versioning
+//
+// RunIndexSchemaVersion is bumped whenever the record layout changes in a way that plain
+// JSON decoding cannot absorb (renamed or restructured fields). Migrations in
+// runIndexMigrations upgrade raw records one version at a time. Index files written by a
+// newer CLI are opened read-only, and runs are not appended to them. Logs directories without an index (created before the
+// index existed) are imported from their per-run run_summary.json files.
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var runIndexLog = logger.New("cli:logs_run_index")
+
+const (
+	// runIndexFileName is the name of the run index at the root of the logs directory
+	runIndexFileName = "runs.jsonl"
+
+	// runIndexKind identifies the header line of a run index file
+	runIndexKind = "gh-aw-run-index"
+
+	// RunIndexSchemaVersion is the current run index record schema version
+	RunIndexSchemaVersion = 1
+)
+
+// runDirPattern matches run folders created by `logs` and `audit`
+var runDirPattern = regexp.MustCompile(`^run-(\d+)$`)
+
+// runIndexMigrations upgrades a raw record from the keyed schema version to the next one.
+// Add an entry here whenever RunIndexSchemaVersion is bumped.
+var runIndexMigrations = map[int]func(record map[string]any) (map[string]any, error){}
+
+// runIndexMutex serializes writes to run index files, since runs are processed in parallel
+var runIndexMutex sync.Mutex
+
+// runIndexHeader is the first line of a run index file
+type runIndexHeader struct {
+	Kind          string `json:"kind"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// RunIndexRecord is one indexed run
+type RunIndexRecord struct {
+	SchemaVersion    int            `json:"schema_version"`
+	RunID            int64          `json:"run_id"`
+	IndexedAt        time.Time      `json:"indexed_at"`
+	RunDir           string         `json:"run_dir"` // Run folder relative to the logs directory
+	EngineID         string         `json:"engine_id,omitempty"`
+	Model            string         `json:"model,omitempty"`
+	Actor            string         `json:"actor,omitempty"`
+	EventName        string         `json:"event_name,omitempty"`
+	SafeOutputCounts map[string]int `json:"safe_output_counts,omitempty"` // Safe output items by type (underscore form)
+	Summary          RunSummary     `json:"summary"`
+}
+
+// RunIndexFilter selects records from the run index. Empty fields match everything.
+type RunIndexFilter struct {
+	WorkflowName   string // GitHub Actions workflow name or workflow ID (lock file basename)
+	Engine         string // Engine ID from aw_info.json
+	StartDate      string // Runs created on or after this date (YYYY-MM-DD)
+	EndDate        string // Runs created on or before this date (YYYY-MM-DD)
+	SafeOutputType string // Runs that produced at least one safe output of this type
+}
+
+// RunIndex is the loaded run index of a logs directory
+type RunIndex struct {
+	path     string
+	readOnly bool
+	records  map[int64]*RunIndexRecord
+}
+
+// OpenRunIndex loads the run index of a logs directory. A missing index is created from the
+// per-run run_summary.json files; outdated records are migrated, records of deleted run
+// folders are dropped and the file is compacted.
+func OpenRunIndex(logsDir string) (*RunIndex, error) {
+	runIndexMutex.Lock()
+	defer runIndexMutex.Unlock()
+	return openRunIndexLocked(logsDir)
+}
+
+func openRunIndexLocked(logsDir string) (*RunIndex, error) {
+	index := &RunIndex{
+		path:    filepath.Join(logsDir, runIndexFileName),
+		records: make(map[int64]*RunIndexRecord),
+	}
+
+	file, err := os.Open(index.path)
+	if errors.Is(err, os.ErrNotExist) {
+		runIndexLog.Printf("No run index at %s, importing run summaries", index.path)
+		if err := index.importRunSummaries(logsDir); err != nil {
+			return nil, err
+		}
+		if len(index.records) == 0 {
+			return index, nil
+		}
+		return index, index.rewrite()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run index: %w", err)
+	}
+	defer file.Close()
+
+	needsRewrite, err := index.load(file)
+	if err != nil {
+		return nil, err
+	}
+	if index.pruneMissingRuns(logsDir) {
+		needsRewrite = true
+	}
+	if needsRewrite && !index.readOnly {
+		if err := index.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// load reads the header and records, migrating outdated records.
+// Returns true if the file should be rewritten (duplicates, migrations, or invalid lines).
+func (idx *RunIndex) load(file *os.File) (bool, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !scanner.Scan() {
+		return false, scanner.Err()
+	}
+	header, err := parseRunIndexHeader(idx.path, scanner.Bytes())
+	if err != nil {
+		return false, err
+	}
+	if header.SchemaVersion > RunIndexSchemaVersion {
+		runIndexLog.Printf("Run index schema %d is newer than %d, opening read-only", header.SchemaVersion, RunIndexSchemaVersion)
+		idx.readOnly = true
+	}
+
+	needsRewrite := header.SchemaVersion < RunIndexSchemaVersion
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		record, migrated, err := decodeRunIndexRecord(scanner.Bytes())
+		if err != nil {
+			runIndexLog.Printf("Skipping invalid run index line %d: %v", lines+1, err)
+			needsRewrite = true
+			continue
+		}
+		if record == nil {
+			// Record written by a newer CLI; keep the file untouched
+			idx.readOnly = true
+			continue
+		}
+		needsRewrite = needsRewrite || migrated
+		idx.records[record.RunID] = record
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read run index: %w", err)
+	}
+
+	if lines > len(idx.records) {
+		needsRewrite = true
+	}
+	runIndexLog.Printf("Loaded run index: %d records from %d lines, rewrite=%v", len(idx.records), lines, needsRewrite)
+	return needsRewrite, nil
+}
+
+// parseRunIndexHeader decodes the header line of the run index at path
+func parseRunIndexHeader(path string, line []byte) (runIndexHeader, error) {
+	var header runIndexHeader
+	if err := json.Unmarshal(line, &header); err != nil || header.Kind != runIndexKind {
+		return runIndexHeader{}, fmt.Errorf("%s is not a run index (invalid header)", path)
+	}
+	return header, nil
+}
+
+// readRunIndexHeader reads the header line of the run index at path
+func readRunIndexHeader(path string) (runIndexHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return runIndexHeader{}, fmt.Errorf("failed to open run index: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return runIndexHeader{}, fmt.Errorf("failed to read run index: %w", err)
+		}
+		return runIndexHeader{}, fmt.Errorf("%s is not a run index (invalid header)", path)
+	}
+	return parseRunIndexHeader(path, scanner.Bytes())
+}
+
+// pruneMissingRuns drops the records of run folders that no longer exist in the logs directory.
+// Returns true if any record was dropped.
+func (idx *RunIndex) pruneMissingRuns(logsDir string) bool {
+	pruned := 0
+	for runID, record := range idx.records {
+		if record.RunDir == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(logsDir, record.RunDir)); errors.Is(err, os.ErrNotExist) {
+			delete(idx.records, runID)
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		runIndexLog.Printf("Dropped %d run index records of deleted run folders", pruned)
+	}
+	return pruned > 0
+}
+
+// decodeRunIndexRecord decodes one record line, applying migrations to outdated records.
+// Returns a nil record for records from a newer schema version.
+func decodeRunIndexRecord(line []byte) (*RunIndexRecord, bool, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return nil, false, err
+	}
+
+	version := 0
+	if value, ok := raw["schema_version"].(float64); ok {
+		version = int(value)
+	}
+	if version > RunIndexSchemaVersion {
+		return nil, false, nil
+	}
+
+	migrated := false
+	for version < RunIndexSchemaVersion {
+		migrate, ok := runIndexMigrations[version]
+		if !ok {
+			return nil, false, fmt.Errorf("no migration from run index schema version %d", version)
+		}
+		var err error
+		if raw, err = migrate(raw); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate run index record from version %d: %w", version, err)
+		}
+		version++
+		raw["schema_version"] = version
+		migrated = true
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	var record RunIndexRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, false, err
+	}
+	if record.RunID == 0 {
+		return nil, false, errors.New("record has no run_id")
+	}
+	return &record, migrated, nil
+}
+
+// importRunSummaries indexes the run_summary.json files of a logs directory created before
+// the run index existed
+func (idx *RunIndex) importRunSummaries(logsDir string) error {
+	entries, err := os.ReadDir(logsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read logs directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !runDirPattern.MatchString(entry.Name()) {
+			continue
+		}
+		runDir := filepath.Join(logsDir, entry.Name())
+		content, err := os.ReadFile(filepath.Join(runDir, runSummaryFileName))
+		if err != nil {
+			continue
+		}
+		var summary RunSummary
+		if err := json.Unmarshal(content, &summary); err != nil {
+			runIndexLog.Printf("Skipping invalid run summary in %s: %v", runDir, err)
+			continue
+		}
+		record := newRunIndexRecord(runDir, &summary)
+		idx.records[record.RunID] = record
+	}
+
+	runIndexLog.Printf("Imported %d run summaries into the run index", len(idx.records))
+	return nil
+}
+
+// rewrite writes the header and the latest record of every run to a temporary file and
+// atomically replaces the index with it
+func (idx *RunIndex) rewrite() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	tmpPath := idx.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write run index: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	writeErr := encoder.Encode(runIndexHeader{Kind: runIndexKind, SchemaVersion: RunIndexSchemaVersion})
+	for _, record := range idx.sortedRecords() {
+		if writeErr != nil {
+			break
+		}
+		writeErr = encoder.Encode(record)
+	}
+	if writeErr == nil {
+		writeErr = writer.Flush()
+	}
+	if closeErr := file.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write run index: %w", writeErr)
+	}
+
+	if err := os.Rename(tmpPath, idx.path); err != nil {
+		return fmt.Errorf("failed to replace run index: %w", err)
+	}
+	runIndexLog.Printf("Rewrote run index with %d records", len(idx.records))
+	return nil
+}
+
+// sortedRecords returns the records ordered by run ID
+func (idx *RunIndex) sortedRecords() []*RunIndexRecord {
+	records := make([]*RunIndexRecord, 0, len(idx.records))
+	for _, record := range idx.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].RunID < records[j].RunID })
+	return records
+}
+
+// Len returns the number of indexed runs
+func (idx *RunIndex) Len() int {
+	return len(idx.records)
+}
+
+// Get returns the record of a run
+func (idx *RunIndex) Get(runID int64) (*RunIndexRecord, bool) {
+	record, ok := idx.records[runID]
+	return record, ok
+}
+
+// Query returns the records matching the filter, newest run first
+func (idx *RunIndex) Query(filter RunIndexFilter) []*RunIndexRecord {
+	var results []*RunIndexRecord
+	for _, record := range idx.records {
+		if filter.matches(record) {
+			results = append(results, record)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].Summary.Run.CreatedAt, results[j].Summary.Run.CreatedAt
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return results[i].RunID > results[j].RunID
+	})
+	return results
+}
+
+// matches reports whether the record satisfies every set filter field
+func (f RunIndexFilter) matches(record *RunIndexRecord) bool {
+	run := record.Summary.Run
+	if f.WorkflowName != "" && run.WorkflowName != f.WorkflowName && workflowIDFromPath(run.WorkflowPath) != f.WorkflowName {
+		return false
+	}
+	if f.Engine != "" && record.EngineID != f.Engine {
+		return false
+	}
+	day := run.CreatedAt.UTC().Format("2006-01-02")
+	if f.StartDate != "" && day < f.StartDate {
+		return false
+	}
+	if f.EndDate != "" && day > f.EndDate {
+		return false
+	}
+	if f.SafeOutputType != "" && record.SafeOutputCounts[normalizeSafeOutputType(f.SafeOutputType)] == 0 {
+		return false
+	}
+	return true
+}
+
+// selectIndexedRuns opens the run index of a logs directory and reports, for every indexed run,
+// whether it matches the filter. Runs missing from the result are not indexed and have to be
+// inspected in their run folder. Returns nil if the index cannot be opened.
+func selectIndexedRuns(logsDir string, filter RunIndexFilter) map[int64]bool {
+	index, err := OpenRunIndex(logsDir)
+	if err != nil {
+		runIndexLog.Printf("Failed to open run index: %v", err)
+		return nil
+	}
+
+	selection := make(map[int64]bool, index.Len())
+	for runID := range index.records {
+		selection[runID] = false
+	}
+	for _, record := range index.Query(filter) {
+		selection[record.RunID] = true
+	}
+	return selection
+}
+
+// lookupIndexedRun returns the run metadata recorded in the run index of a logs directory
+func lookupIndexedRun(logsDir string, runID int64) (WorkflowRun, bool) {
+	index, err := OpenRunIndex(logsDir)
+	if err != nil {
+		runIndexLog.Printf("Failed to open run index: %v", err)
+		return WorkflowRun{}, false
+	}
+	record, ok := index.Get(runID)
+	if !ok || record.Summary.Run.DatabaseID == 0 {
+		return WorkflowRun{}, false
+	}
+	return record.Summary.Run, true
+}
+
+// workflowIDFromPath returns the workflow ID for a workflow path such as
+// .github/workflows/issue-triage.lock.yml
+func workflowIDFromPath(workflowPath string) string {
+	base := filepath.Base(workflowPath)
+	base = strings.TrimSuffix(base, ".lock.yml")
+	return strings.TrimSuffix(base, ".yml")
+}
+
+// newRunIndexRecord builds the index record for a run folder and its summary
+func newRunIndexRecord(runDir string, summary *RunSummary) *RunIndexRecord {
+	record := &RunIndexRecord{
+		SchemaVersion: RunIndexSchemaVersion,
+		RunID:         summary.RunID,
+		IndexedAt:     time.Now().UTC(),
+		RunDir:        filepath.Base(runDir),
+		Summary:       *summary,
+	}
+	if record.RunID == 0 {
+		record.RunID = summary.Run.DatabaseID
+	}
+
+	infoPath := filepath.Join(runDir, "aw_info.json")
+	if _, err := os.Stat(infoPath); err == nil {
+		if info, err := parseAwInfo(infoPath, false); err == nil {
+			record.EngineID = info.EngineID
+			record.Model = info.Model
+			record.Actor = info.Actor
+			record.EventName = info.EventName
+		}
+	}
+
+	if counts, err := loadSafeOutputTypeCounts(runDir); err == nil && len(counts) > 0 {
+		record.SafeOutputCounts = counts
+	}
+	return record
+}
+
+// indexRunSummary appends a run summary to the run index of the logs directory containing
+// runDir. Folders that are not run-<id> folders of a logs directory are not indexed. An index
+// with an older schema version is migrated first; an index written by a newer CLI is read-only
+// and left untouched.
+func indexRunSummary(runDir string, summary *RunSummary) error {
+	if !runDirPattern.MatchString(filepath.Base(runDir)) {
+		return nil
+	}
+	logsDir := filepath.Dir(runDir)
+
+	runIndexMutex.Lock()
+	defer runIndexMutex.Unlock()
+
+	indexPath := filepath.Join(logsDir, runIndexFileName)
+	if _, err := os.Stat(indexPath); errors.Is(err, os.ErrNotExist) {
+		// Create the index, importing existing run summaries (including this run's)
+		_, err := openRunIndexLocked(logsDir)
+		return err
+	}
+
+	header, err := readRunIndexHeader(indexPath)
+	if err != nil {
+		return err
+	}
+	if header.SchemaVersion != RunIndexSchemaVersion {
+		index, err := openRunIndexLocked(logsDir)
+		if err != nil {
+			return err
+		}
+		if index.readOnly {
+			runIndexLog.Printf("Run index %s is read-only (schema %d), not indexing run %s", indexPath, header.SchemaVersion, filepath.Base(runDir))
+			return nil
+		}
+	}
+
+	record := newRunIndexRecord(runDir, summary)
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run index record: %w", err)
+	}
+
+	file, err := os.OpenFile(indexPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open run index: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append to run index: %w", err)
+	}
+	runIndexLog.Printf("Indexed run %d in %s", record.RunID, indexPath)
+	return nil
+}
+
+// loadSafeOutputTypeCounts counts the items in a run's agent_output.json by normalized type
+func loadSafeOutputTypeCounts(runDir string) (map[string]int, error) {
+	agentOutputPath := filepath.Join(runDir, constants.AgentOutputFilename)
+
+	// Support both new flattened form and old directory form
+	if stat, err := os.Stat(agentOutputPath); err != nil || stat.IsDir() {
+		oldPath := filepath.Join(runDir, constants.AgentOutputArtifactName, constants.AgentOutputArtifactName)
+		if _, err := os.Stat(oldPath); err != nil {
+			return nil, nil
+		}
+		agentOutputPath = oldPath
+	}
+
+	content, err := os.ReadFile(agentOutputPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	var safeOutput struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(content, &safeOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse agent_output.json: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, itemRaw := range safeOutput.Items {
+		var item struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(itemRaw, &item); err != nil || item.Type == "" {
+			continue // Skip malformed items
+		}
+		counts[normalizeSafeOutputType(item.Type)]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,238 @@
+//go:build !integration
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRunIndexImportsRunSummaries(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 7, WorkflowName: "Triage", CreatedAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		`{"engine_id":"copilot","model":"gpt-5","actor":"octocat","event_name":"issues"}`)
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "run-7", "agent_output.json"),
+		[]byte(`{"items":[{"type":"create-issue"},{"type":"create_issue"},{"type":"add_comment"}]}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(logsDir, "not-a-run"), 0755))
+
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, index.Len(), "run folders should be imported when the index does not exist")
+
+	record, ok := index.Get(7)
+	require.True(t, ok)
+	assert.Equal(t, "run-7", record.RunDir)
+	assert.Equal(t, "copilot", record.EngineID)
+	assert.Equal(t, "gpt-5", record.Model)
+	assert.Equal(t, "octocat", record.Actor)
+	assert.Equal(t, "issues", record.EventName)
+	assert.Equal(t, map[string]int{"create_issue": 2, "add_comment": 1}, record.SafeOutputCounts)
+
+	content, err := os.ReadFile(filepath.Join(logsDir, runIndexFileName))
+	require.NoError(t, err, "the imported index should be written to disk")
+	assert.True(t, strings.HasPrefix(string(content), `{"kind":"gh-aw-run-index","schema_version":1}`), "index should start with its header")
+}
+
+func TestSaveRunSummaryAppendsToRunIndex(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	for _, id := range []int64{1, 2} {
+		runDir := filepath.Join(logsDir, fmt.Sprintf("run-%d", id))
+		require.NoError(t, os.MkdirAll(runDir, 0755))
+		require.NoError(t, saveRunSummary(runDir, &RunSummary{RunID: id, Run: WorkflowRun{DatabaseID: id, Conclusion: "failure"}}, false))
+	}
+	// Reprocessing a run appends a newer record that wins on load
+	require.NoError(t, saveRunSummary(filepath.Join(logsDir, "run-1"), &RunSummary{RunID: 1, Run: WorkflowRun{DatabaseID: 1, Conclusion: "success"}}, false))
+
+	content, err := os.ReadFile(filepath.Join(logsDir, runIndexFileName))
+	require.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(content), "\n"), "header plus three appended records")
+
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, index.Len())
+	record, _ := index.Get(1)
+	assert.Equal(t, "success", record.Summary.Run.Conclusion, "the latest record should win")
+
+	content, err = os.ReadFile(filepath.Join(logsDir, runIndexFileName))
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(content), "\n"), "superseded records should be compacted on open")
+}
+
+func TestSaveRunSummaryOutsideLogsDirectory(t *testing.T) {
+	dir := testutil.TempDir(t, "run-index-*")
+	require.NoError(t, saveRunSummary(dir, &RunSummary{RunID: 1}, false))
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), runIndexFileName), "only run-<id> folders should be indexed")
+}
+
+func TestRunIndexQuery(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 1, WorkflowName: "Issue Triage", WorkflowPath: ".github/workflows/issue-triage.lock.yml", Conclusion: "success", CreatedAt: day(1)}, `{"engine_id":"claude"}`)
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 2, WorkflowName: "Issue Triage", WorkflowPath: ".github/workflows/issue-triage.lock.yml", Conclusion: "failure", CreatedAt: day(3)}, `{"engine_id":"copilot"}`)
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 3, WorkflowName: "Docs", Conclusion: "success", CreatedAt: day(2)}, `{"engine_id":"claude"}`)
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "run-3", "agent_output.json"), []byte(`{"items":[{"type":"missing_tool"}]}`), 0644))
+
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+
+	ids := func(records []*RunIndexRecord) []int64 {
+		var result []int64
+		for _, record := range records {
+			result = append(result, record.RunID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []int64{2, 3, 1}, ids(index.Query(RunIndexFilter{})), "records should be newest first")
+	assert.Equal(t, []int64{2, 1}, ids(index.Query(RunIndexFilter{WorkflowName: "Issue Triage"})))
+	assert.Equal(t, []int64{2, 1}, ids(index.Query(RunIndexFilter{WorkflowName: "issue-triage"})), "workflow IDs should match too")
+	assert.Equal(t, []int64{3, 1}, ids(index.Query(RunIndexFilter{Engine: "claude"})))
+	assert.Equal(t, []int64{3}, ids(index.Query(RunIndexFilter{StartDate: "2026-03-02", EndDate: "2026-03-02"})))
+	assert.Equal(t, []int64{3}, ids(index.Query(RunIndexFilter{SafeOutputType: "missing-tool"})))
+}
+
+func TestSelectIndexedRuns(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 1, WorkflowName: "Issue Triage"}, `{"engine_id":"claude"}`)
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 2, WorkflowName: "Issue Triage"}, `{"engine_id":"copilot"}`)
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "run-2", "agent_output.json"), []byte(`{"items":[{"type":"create_issue"}]}`), 0644))
+
+	assert.Equal(t, map[int64]bool{1: true, 2: false}, selectIndexedRuns(logsDir, RunIndexFilter{Engine: "claude"}))
+	assert.Equal(t, map[int64]bool{1: false, 2: true}, selectIndexedRuns(logsDir, RunIndexFilter{Engine: "copilot", SafeOutputType: "create-issue"}))
+
+	run, ok := lookupIndexedRun(logsDir, 2)
+	require.True(t, ok, "indexed run should be found")
+	assert.Equal(t, "Issue Triage", run.WorkflowName)
+	_, ok = lookupIndexedRun(logsDir, 3)
+	assert.False(t, ok, "runs missing from the index should not be found")
+}
+
+func TestRunIndexMigration(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	indexPath := filepath.Join(logsDir, runIndexFileName)
+	require.NoError(t, os.WriteFile(indexPath, []byte(`{"kind":"gh-aw-run-index","schema_version":0}
+{"schema_version":0,"run_id":5,"engine":"codex","summary":{"run_id":5}}
+`), 0644))
+
+	original := runIndexMigrations
+	t.Cleanup(func() { runIndexMigrations = original })
+	runIndexMigrations = map[int]func(map[string]any) (map[string]any, error){
+		0: func(record map[string]any) (map[string]any, error) {
+			record["engine_id"] = record["engine"]
+			delete(record, "engine")
+			return record, nil
+		},
+	}
+
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	record, ok := index.Get(5)
+	require.True(t, ok)
+	assert.Equal(t, "codex", record.EngineID, "migration should rename the field")
+	assert.Equal(t, RunIndexSchemaVersion, record.SchemaVersion)
+
+	content, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"schema_version":1}`, "migrated index should be rewritten with the current header")
+	assert.NotContains(t, string(content), `"engine":"codex"`)
+}
+
+func TestRunIndexFromNewerVersionIsReadOnly(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	indexPath := filepath.Join(logsDir, runIndexFileName)
+	original := `{"kind":"gh-aw-run-index","schema_version":99}
+{"schema_version":1,"run_id":1,"summary":{"run_id":1}}
+{"schema_version":1,"run_id":1,"summary":{"run_id":1}}
+{"schema_version":99,"run_id":2,"future":true}
+`
+	require.NoError(t, os.WriteFile(indexPath, []byte(original), 0644))
+
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index.Len(), "records from newer versions should be skipped")
+
+	content, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "an index from a newer CLI must not be rewritten")
+}
+
+func TestSaveRunSummaryChecksRunIndexSchema(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	indexPath := filepath.Join(logsDir, runIndexFileName)
+	runDir := filepath.Join(logsDir, "run-3")
+	require.NoError(t, os.MkdirAll(runDir, 0755))
+
+	newer := `{"kind":"gh-aw-run-index","schema_version":99}
+{"schema_version":99,"run_id":2,"future":true}
+`
+	require.NoError(t, os.WriteFile(indexPath, []byte(newer), 0644))
+	require.NoError(t, saveRunSummary(runDir, &RunSummary{RunID: 3}, false))
+	content, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, newer, string(content), "runs must not be appended to an index from a newer CLI")
+
+	require.NoError(t, os.WriteFile(indexPath, []byte(`{"kind":"gh-aw-run-index","schema_version":0}
+{"schema_version":0,"run_id":5,"summary":{"run_id":5}}
+`), 0644))
+	original := runIndexMigrations
+	t.Cleanup(func() { runIndexMigrations = original })
+	runIndexMigrations = map[int]func(map[string]any) (map[string]any, error){
+		0: func(record map[string]any) (map[string]any, error) { return record, nil },
+	}
+	require.NoError(t, saveRunSummary(runDir, &RunSummary{RunID: 3}, false))
+	content, err = os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), `{"kind":"gh-aw-run-index","schema_version":1}`), "an outdated index should be migrated before appending")
+	assert.Equal(t, 3, strings.Count(string(content), "\n"), "header, migrated record and appended record")
+}
+
+func TestOpenRunIndexPrunesDeletedRuns(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 1, WorkflowName: "Triage"}, `{"engine_id":"claude"}`)
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 2, WorkflowName: "Triage"}, `{"engine_id":"claude"}`)
+	index, err := OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	require.Equal(t, 2, index.Len())
+
+	require.NoError(t, os.RemoveAll(filepath.Join(logsDir, "run-1")))
+	index, err = OpenRunIndex(logsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index.Len(), "records of deleted run folders should be dropped")
+	_, ok := index.Get(1)
+	assert.False(t, ok)
+
+	content, err := os.ReadFile(filepath.Join(logsDir, runIndexFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), `"run_dir":"run-1"`, "the index should be compacted without the deleted run")
+}
+
+func TestOpenRunIndexRejectsInvalidHeader(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, runIndexFileName), []byte("{\"run_id\":1}\n"), 0644))
+
+	_, err := OpenRunIndex(logsDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a run index")
+}
+
+func TestLoadCachedWorkflowRuns(t *testing.T) {
+	logsDir := testutil.TempDir(t, "run-index-*")
+	now := time.Now().UTC()
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 1, WorkflowName: "Issue Triage", WorkflowPath: ".github/workflows/issue-triage.lock.yml", Conclusion: "success", CreatedAt: now,
+		StartedAt: now, UpdatedAt: now.Add(2 * time.Minute)}, "")
+	writeCachedRun(t, logsDir, WorkflowRun{DatabaseID: 2, WorkflowName: "Issue Triage", Conclusion: "success", CreatedAt: now.AddDate(0, 0, -30)}, "")
+
+	runs, err := loadCachedWorkflowRuns(logsDir, "issue-triage", now.AddDate(0, 0, -7).Format("2006-01-02"), false)
+	require.NoError(t, err)
+	require.Len(t, runs, 1, "runs before the start date should be excluded")
+	assert.Equal(t, "issue-triage", runs[0].WorkflowName, "runs should be grouped under the requested name")
+	assert.Equal(t, 2*time.Minute, runs[0].Duration)
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var lspLog = logger.New("cli:lsp_command")
+
+// NewLSPCommand creates the lsp command
+func NewLSPCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server for agentic workflow markdown files",
+		Long: `Run a Language Server Protocol (LSP) server over stdio for agentic workflow
+markdown files (.github/workflows/*.md and shared files under .github).
+
+The language server provides:
+  - Diagnostics from YAML parsing and JSON schema validation of the frontmatter as you type,
+    and from the compiler when a file is opened or saved (no lock files are written)
+  - Completion for frontmatter keys, engines, safe-output types, toolsets, network
+    ecosystems and other values defined by the workflow schema
+  - Hover documentation for frontmatter keys from the workflow schema
+  - Go to definition for 'imports:' entries and {{#import}} directives
+
+Configure your editor to start 'gh aw lsp' for markdown files in .github.
+
+Examples:
+  gh aw lsp                      # Run the language server on stdio
+  DEBUG=cli:lsp* gh aw lsp       # Run with debug logging to stderr`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The protocol owns stdout; anything else written by the compiler goes to stderr
+			stdout := os.Stdout
+			os.Stdout = os.Stderr
+			defer func() { os.Stdout = stdout }()
+			return runLSPServer(os.Stdin, stdout)
+		},
+	}
+	return cmd
+}
+
+// lspServer holds the state of a language server session
+type lspServer struct {
+	conn        *lspConn
+	documents   map[string]string
+	initialized bool
+	shutdown    bool
+}
+
+// errLSPExit is returned by handle when the client asks the server to exit after shutdown
+var errLSPExit = errors.New("exit")
+
+// runLSPServer serves the language server protocol until the client exits or closes the stream
+func runLSPServer(in io.Reader, out io.Writer) error {
+	server := &lspServer{
+		conn:      newLSPConn(in, out),
+		documents: make(map[string]string),
+	}
+	lspLog.Print("Starting language server")
+
+	for {
+		msg, err := server.conn.read()
+		if err == io.EOF {
+			lspLog.Print("Client closed the connection")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := server.handle(msg); err != nil {
+			if errors.Is(err, errLSPExit) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification
+func (s *lspServer) handle(msg *lspMessage) error {
+	if msg.Method == "exit" {
+		if !s.shutdown {
+			return fmt.Errorf("language server received exit before shutdown")
+		}
+		return errLSPExit
+	}
+
+	isRequest := msg.ID != nil
+	if !s.initialized && msg.Method != "initialize" {
+		if isRequest {
+			return s.conn.reply(msg.ID, nil, &lspError{Code: lspServerNotInitialized, Message: "server not initialized"})
+		}
+		return nil
+	}
+
+	switch msg.Method {
+	case "initialize":
+		s.initialized = true
+		return s.conn.reply(msg.ID, s.capabilities(), nil)
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil
+	case "shutdown":
+		s.shutdown = true
+		return s.conn.reply(msg.ID, nil, nil)
+	case "textDocument/didOpen":
+		var params lspDidOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return s.publishDiagnostics(params.TextDocument.URI, true)
+	case "textDocument/didChange":
+		var params lspDidChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.publishDiagnostics(params.TextDocument.URI, false)
+	case "textDocument/didSave":
+		var params lspDidSaveParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		if params.Text != nil {
+			s.documents[params.TextDocument.URI] = *params.Text
+		}
+		return s.publishDiagnostics(params.TextDocument.URI, true)
+	case "textDocument/didClose":
+		var params lspDidOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.conn.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []lspDiagnostic{}})
+	case "textDocument/completion", "textDocument/hover", "textDocument/definition":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.conn.reply(msg.ID, nil, &lspError{Code: lspInvalidParams, Message: err.Error()})
+		}
+		return s.conn.reply(msg.ID, s.positionResult(msg.Method, params), nil)
+	}
+
+	if isRequest {
+		return s.conn.reply(msg.ID, nil, &lspError{Code: lspMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)})
+	}
+	return nil
+}
+
+// capabilities returns the result of the initialize request
+func (s *lspServer) capabilities() map[string]any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    lspTextDocumentSyncFull,
+				"save":      map[string]any{"includeText": true},
+			},
+			"completionProvider": map[string]any{"triggerCharacters": []string{":", " ", "-"}},
+			"hoverProvider":      true,
+			"definitionProvider": true,
+		},
+		"serverInfo": map[string]any{
+			"name":    "gh-aw",
+			"version": GetVersion(),
+		},
+	}
+}
+
+// positionResult answers completion, hover and definition requests
+func (s *lspServer) positionResult(method string, params lspTextDocumentPositionParams) any {
+	content, open := s.documents[params.TextDocument.URI]
+	path := lspURIToPath(params.TextDocument.URI)
+	if !open || !isLSPWorkflowPath(path) {
+		return nil
+	}
+
+	switch method {
+	case "textDocument/completion":
+		items := computeLSPCompletions(content, params.Position)
+		if items == nil {
+			items = []lspCompletionItem{}
+		}
+		return lspCompletionList{Items: items}
+	case "textDocument/hover":
+		if hover := computeLSPHover(content, params.Position); hover != nil {
+			return hover
+		}
+	case "textDocument/definition":
+		if location := computeLSPDefinition(path, content, params.Position); location != nil {
+			return location
+		}
+	}
+	return nil
+}
+
+// publishDiagnostics computes and sends the diagnostics of an open document
+func (s *lspServer) publishDiagnostics(uri string, compile bool) error {
+	content, open := s.documents[uri]
+	path := lspURIToPath(uri)
+	if !open || !isLSPWorkflowPath(path) {
+		return nil
+	}
+
+	diagnostics := computeLSPDiagnostics(path, content, compile)
+	if diagnostics == nil {
+		diagnostics = []lspDiagnostic{}
+	}
+	lspLog.Printf("Publishing %d diagnostics for %s", len(diagnostics), path)
+	return s.conn.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// isLSPWorkflowPath reports whether a path is a markdown file inside a .github directory
+func isLSPWorkflowPath(path string) bool {
+	if path == "" || !strings.EqualFold(filepath.Ext(path), ".md") {
+		return false
+	}
+	return slices.Contains(strings.Split(filepath.ToSlash(path), "/"), ".github")
+}
+
+// lspURIToPath converts a file:// URI to a local path, or returns "" for other schemes
+func lspURIToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return ""
+	}
+	path := parsed.Path
+	// Windows URIs have the form file:///C:/path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// lspPathToURI converts a local path to a file:// URI
+func lspPathToURI(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
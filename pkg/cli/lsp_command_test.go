@@ -0,0 +1,111 @@
+//go:build !integration
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLSPServerSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowFile := filepath.Join(workflowsDir, "test.md")
+	uri := lspPathToURI(workflowFile)
+	content := "---\non: push\nunknown-field: true\n---\n\n# Test\n"
+
+	input := strings.Join([]string{
+		lspFrame(t, map[string]any{"id": 1, "method": "textDocument/hover", "params": map[string]any{}}),
+		lspFrame(t, map[string]any{"id": 2, "method": "initialize", "params": map[string]any{}}),
+		lspFrame(t, map[string]any{"method": "initialized", "params": map[string]any{}}),
+		lspFrame(t, map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "markdown", "version": 1, "text": content},
+		}}),
+		lspFrame(t, map[string]any{"id": 3, "method": "textDocument/completion", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": 1, "character": 0},
+		}}),
+		lspFrame(t, map[string]any{"method": "textDocument/didClose", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}}),
+		lspFrame(t, map[string]any{"id": 4, "method": "workspace/symbol", "params": map[string]any{}}),
+		lspFrame(t, map[string]any{"id": 5, "method": "shutdown"}),
+		lspFrame(t, map[string]any{"method": "exit"}),
+	}, "")
+
+	var out bytes.Buffer
+	require.NoError(t, runLSPServer(strings.NewReader(input), &out))
+	reader := bufio.NewReader(&out)
+
+	notInitialized := readLSPFrame(t, reader)
+	assert.InDelta(t, lspServerNotInitialized, notInitialized["error"].(map[string]any)["code"], 0)
+
+	initialize := readLSPFrame(t, reader)
+	capabilities := initialize["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Equal(t, true, capabilities["hoverProvider"])
+	assert.Equal(t, true, capabilities["definitionProvider"])
+
+	diagnostics := readLSPFrame(t, reader)
+	assert.Equal(t, "textDocument/publishDiagnostics", diagnostics["method"])
+	published := diagnostics["params"].(map[string]any)["diagnostics"].([]any)
+	require.Len(t, published, 1)
+	assert.Contains(t, published[0].(map[string]any)["message"], "unknown-field")
+
+	completion := readLSPFrame(t, reader)
+	items := completion["result"].(map[string]any)["items"].([]any)
+	assert.NotEmpty(t, items)
+
+	closed := readLSPFrame(t, reader)
+	assert.Empty(t, closed["params"].(map[string]any)["diagnostics"], "closing a document clears its diagnostics")
+
+	unknown := readLSPFrame(t, reader)
+	assert.InDelta(t, lspMethodNotFound, unknown["error"].(map[string]any)["code"], 0)
+
+	shutdown := readLSPFrame(t, reader)
+	assert.InDelta(t, 5, shutdown["id"], 0)
+	_, err := reader.Peek(1)
+	assert.Error(t, err, "no messages should follow the shutdown response")
+}
+
+func TestRunLSPServerExitWithoutShutdown(t *testing.T) {
+	input := lspFrame(t, map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}}) +
+		lspFrame(t, map[string]any{"method": "exit"})
+
+	var out bytes.Buffer
+	err := runLSPServer(strings.NewReader(input), &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit before shutdown")
+}
+
+func TestIsLSPWorkflowPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/repo/.github/workflows/triage.md", want: true},
+		{path: "/repo/.github/workflows/shared/tools.md", want: true},
+		{path: "/repo/.github/agents/helper.md", want: true},
+		{path: "/repo/docs/readme.md", want: false},
+		{path: "/repo/.github/workflows/triage.lock.yml", want: false},
+		{path: "", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isLSPWorkflowPath(filepath.FromSlash(tt.path)), tt.path)
+	}
+}
+
+func TestLSPURIConversion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".github", "workflows", "my workflow.md")
+	uri := lspPathToURI(path)
+	assert.True(t, strings.HasPrefix(uri, "file:///"))
+	assert.Contains(t, uri, "my%20workflow.md", "paths should be escaped")
+	assert.Equal(t, path, lspURIToPath(uri))
+	assert.Empty(t, lspURIToPath("untitled:Untitled-1"), "only file URIs map to paths")
+}
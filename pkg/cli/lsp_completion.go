@@ -0,0 +1,274 @@
+// This file provides completion, hover and go-to-definition for `gh aw lsp`.
+//
+// Completion offers frontmatter keys and enum values from the main workflow schema,
+// plus values the schema cannot enumerate: the engines of the repository engine
+// registry and the network ecosystem identifiers. Hover shows the schema description
+// of the key under the cursor, and go-to-definition resolves `imports:` entries and
+// `{{#import}}` directives to the imported file.
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/workflow"
+)
+
+var lspCompletionLog = logger.New("cli:lsp_completion")
+
+// lspKeyContextPattern matches a line prefix where a mapping key is being typed
+var lspKeyContextPattern = regexp.MustCompile(`^(\s*)(-\s+)?([\w-]*)$`)
+
+// lspValueContextPattern matches a line prefix where the value of a key is being typed,
+// including items of a flow sequence such as "allowed: [defaults, pyt"
+var lspValueContextPattern = regexp.MustCompile(`^(\s*)(-\s+)?([\w.$/-]+):\s+(\[(?:[^\]]*,)?\s*)?["']?[\w.*-]*$`)
+
+// lspImportDirectivePattern matches an {{#import}} directive in the markdown body
+var lspImportDirectivePattern = regexp.MustCompile(`\{\{#import\??\s+([^}]+?)\s*\}\}`)
+
+// lspFrontmatterLines returns the lines of the document and the frontmatter bounds. An
+// unclosed frontmatter extends to the end of the document so that completion keeps
+// working while the frontmatter is being written.
+func lspFrontmatterLines(content string) ([]string, int, int) {
+	lines := strings.Split(content, "\n")
+	start, end := lspFrontmatterBounds(lines)
+	if start < 0 && len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		return lines, 0, len(lines)
+	}
+	return lines, start, end
+}
+
+// computeLSPCompletions returns the completion items at a position
+func computeLSPCompletions(content string, position lspPosition) []lspCompletionItem {
+	lines, start, end := lspFrontmatterLines(content)
+	if start < 0 || position.Line <= start || position.Line >= end {
+		return nil
+	}
+	line := lines[position.Line]
+	prefix := line[:lspCharacterToByte(line, position.Character)]
+
+	if match := lspKeyContextPattern.FindStringSubmatch(prefix); match != nil {
+		path := lspYAMLParentPath(lines, position.Line, len(match[1]))
+		if match[2] == "" {
+			return lspKeyCompletions(lspSchemaNodes(path))
+		}
+		// A list item can be a scalar value or a mapping
+		path = append(path, lspArrayItem)
+		nodes := lspSchemaNodes(path)
+		return append(lspValueCompletions(path, nodes), lspKeyCompletions(nodes)...)
+	}
+
+	if match := lspValueContextPattern.FindStringSubmatch(prefix); match != nil {
+		var path []string
+		if match[2] == "" {
+			path = lspYAMLParentPath(lines, position.Line, len(match[1]))
+		} else {
+			path = append(lspYAMLParentPath(lines, position.Line, len(match[1])), lspArrayItem)
+		}
+		path = append(path, strings.Trim(match[3], `"'`))
+		if match[4] != "" {
+			path = append(path, lspArrayItem)
+		}
+		return lspValueCompletions(path, lspSchemaNodes(path))
+	}
+	return nil
+}
+
+// lspKeyCompletions returns the property keys of the schema nodes
+func lspKeyCompletions(nodes []map[string]any) []lspCompletionItem {
+	properties := lspSchemaProperties(nodes)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]lspCompletionItem, 0, len(names))
+	for _, name := range names {
+		item := lspCompletionItem{
+			Label:      name,
+			Kind:       lspCompletionKindProperty,
+			InsertText: name + ": ",
+		}
+		if description := properties[name]; description != "" {
+			item.Documentation = &lspMarkupContent{Kind: "markdown", Value: description}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// lspValueCompletions returns the values that can be used at path
+func lspValueCompletions(path []string, nodes []map[string]any) []lspCompletionItem {
+	var items []lspCompletionItem
+	seen := make(map[string]bool)
+	add := func(value, detail string) {
+		if seen[value] {
+			return
+		}
+		seen[value] = true
+		items = append(items, lspCompletionItem{Label: value, Kind: lspCompletionKindValue, Detail: detail})
+	}
+
+	switch {
+	case slices.Equal(path, []string{"engine"}) || slices.Equal(path, []string{"engine", "id"}):
+		registry := workflow.GetRepositoryEngineRegistry()
+		engines := registry.GetSupportedEngines()
+		sort.Strings(engines)
+		for _, id := range engines {
+			detail := "engine"
+			if engine, err := registry.GetEngine(id); err == nil {
+				detail = engine.GetDisplayName()
+			}
+			add(id, detail)
+		}
+	case slices.Equal(path, []string{"network", "allowed", lspArrayItem}):
+		for _, ecosystem := range workflow.GetEcosystemIdentifiers() {
+			add(ecosystem, "network ecosystem")
+		}
+	}
+
+	for _, value := range lspSchemaEnumValues(nodes) {
+		add(value, "")
+	}
+	for _, node := range nodes {
+		if lspSchemaHasType(node, "boolean") {
+			add("true", "boolean")
+			add("false", "boolean")
+		}
+	}
+	lspCompletionLog.Printf("Found %d value completions for %s", len(items), strings.Join(path, "."))
+	return items
+}
+
+// lspSchemaHasType reports whether a schema node accepts the given JSON type
+func lspSchemaHasType(node map[string]any, typeName string) bool {
+	switch t := node["type"].(type) {
+	case string:
+		return t == typeName
+	case []any:
+		return slices.Contains(t, any(typeName))
+	}
+	return false
+}
+
+// computeLSPHover returns the schema documentation of the frontmatter key at a position
+func computeLSPHover(content string, position lspPosition) *lspHover {
+	lines, start, end := lspFrontmatterLines(content)
+	if start < 0 || position.Line <= start || position.Line >= end {
+		return nil
+	}
+	line := lines[position.Line]
+	indent := lspLineIndent(line)
+	keyStart := indent
+	isItem := strings.HasPrefix(line[indent:], "- ")
+	if isItem {
+		keyStart += 2
+	}
+
+	key, ok := lspYAMLKey(line[keyStart:])
+	if !ok {
+		return nil
+	}
+	keyEnd := keyStart + strings.Index(line[keyStart:], ":")
+	cursor := lspCharacterToByte(line, position.Character)
+	if cursor < keyStart || cursor > keyEnd {
+		return nil
+	}
+
+	path := lspYAMLParentPath(lines, position.Line, indent)
+	if isItem {
+		path = append(path, lspArrayItem)
+	}
+	path = append(path, key)
+	nodes := lspSchemaNodes(path)
+	description := lspSchemaDescription(nodes)
+	if description == "" {
+		return nil
+	}
+
+	var value strings.Builder
+	fmt.Fprintf(&value, "**%s**\n\n%s", strings.Join(path, "."), description)
+	if values := lspSchemaEnumValues(nodes); len(values) > 0 && len(values) <= 20 {
+		fmt.Fprintf(&value, "\n\nAllowed values: `%s`", strings.Join(values, "`, `"))
+	}
+	return &lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: value.String()},
+		Range: &lspRange{
+			Start: lspPosition{Line: position.Line, Character: lspByteToCharacter(line, keyStart)},
+			End:   lspPosition{Line: position.Line, Character: lspByteToCharacter(line, keyEnd)},
+		},
+	}
+}
+
+// computeLSPDefinition resolves the import at a position to the imported file
+func computeLSPDefinition(path string, content string, position lspPosition) *lspLocation {
+	importPath := lspImportPathAt(content, position)
+	if importPath == "" {
+		return nil
+	}
+
+	// Sections select part of the imported file; the definition is the file itself
+	importPath, _, _ = strings.Cut(importPath, "#")
+	resolved, err := parser.ResolveIncludePath(importPath, filepath.Dir(path), nil)
+	if err != nil {
+		lspCompletionLog.Printf("Failed to resolve import %s: %v", importPath, err)
+		return nil
+	}
+	return &lspLocation{URI: lspPathToURI(resolved)}
+}
+
+// lspImportPathAt returns the import path referenced on the line of a position, from an
+// `imports:` list item or an {{#import}} directive in the markdown body
+func lspImportPathAt(content string, position lspPosition) string {
+	lines, start, end := lspFrontmatterLines(content)
+	if position.Line < 0 || position.Line >= len(lines) {
+		return ""
+	}
+	line := lines[position.Line]
+
+	if start < 0 || position.Line >= end {
+		cursor := lspCharacterToByte(line, position.Character)
+		for _, match := range lspImportDirectivePattern.FindAllStringSubmatchIndex(line, -1) {
+			if cursor >= match[0] && cursor <= match[1] {
+				return line[match[2]:match[3]]
+			}
+		}
+		return ""
+	}
+	if position.Line <= start {
+		return ""
+	}
+
+	indent := lspLineIndent(line)
+	item, isItem := strings.CutPrefix(line[indent:], "- ")
+	parent := lspYAMLParentPath(lines, position.Line, indent)
+	switch {
+	case isItem && slices.Equal(parent, []string{"imports"}):
+		// "- shared/tools.md" or "- path: shared/tools.md"
+		if key, ok := lspYAMLKey(item); ok {
+			if key != "path" {
+				return ""
+			}
+			_, item, _ = strings.Cut(item, ":")
+		}
+	case slices.Equal(parent, []string{"imports", lspArrayItem}):
+		key, ok := lspYAMLKey(line[indent:])
+		if !ok || key != "path" {
+			return ""
+		}
+		_, item, _ = strings.Cut(line[indent:], ":")
+	default:
+		return ""
+	}
+
+	item, _, _ = strings.Cut(item, " #")
+	return strings.Trim(strings.TrimSpace(item), `"'`)
+}
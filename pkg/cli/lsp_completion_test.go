@@ -0,0 +1,178 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lspCursor returns the content without the "|" cursor marker and the position of the marker
+func lspCursor(t *testing.T, content string) (string, lspPosition) {
+	t.Helper()
+	before, after, found := strings.Cut(content, "|")
+	require.True(t, found, "content should contain a | cursor marker")
+	lines := strings.Split(before, "\n")
+	last := lines[len(lines)-1]
+	return before + after, lspPosition{Line: len(lines) - 1, Character: lspByteToCharacter(last, len(last))}
+}
+
+func lspCompletionLabels(items []lspCompletionItem) []string {
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestComputeLSPCompletions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "top-level keys",
+			content:  "---\non: push\nsaf|\n---\n",
+			contains: []string{"safe-outputs", "engine", "network", "tools"},
+		},
+		{
+			name:     "engines",
+			content:  "---\non: push\nengine: |\n---\n",
+			contains: []string{"copilot", "claude", "codex"},
+		},
+		{
+			name:     "engine id",
+			content:  "---\non: push\nengine:\n  id: c|\n---\n",
+			contains: []string{"copilot", "claude"},
+		},
+		{
+			name:     "safe output types",
+			content:  "---\non: push\nsafe-outputs:\n  |\n---\n",
+			contains: []string{"create-issue", "add-comment", "create-pull-request"},
+			excludes: []string{"on"},
+		},
+		{
+			name:     "toolsets in flow sequence",
+			content:  "---\non: push\ntools:\n  github:\n    toolsets: [default, |]\n---\n",
+			contains: []string{"repos", "issues", "pull_requests"},
+		},
+		{
+			name:     "network ecosystems",
+			content:  "---\non: push\nnetwork:\n  allowed:\n    - defaults\n    - |\n---\n",
+			contains: []string{"defaults", "python", "node"},
+		},
+		{
+			name:     "boolean values",
+			content:  "---\non: push\nstrict: |\n---\n",
+			contains: []string{"true", "false"},
+		},
+		{
+			name:     "unclosed frontmatter",
+			content:  "---\non: push\npermis|",
+			contains: []string{"permissions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, position := lspCursor(t, tt.content)
+			labels := lspCompletionLabels(computeLSPCompletions(content, position))
+			for _, label := range tt.contains {
+				assert.Contains(t, labels, label)
+			}
+			for _, label := range tt.excludes {
+				assert.NotContains(t, labels, label)
+			}
+		})
+	}
+}
+
+func TestComputeLSPCompletionsOutsideFrontmatter(t *testing.T) {
+	content, position := lspCursor(t, "---\non: push\n---\n\nengine: |\n")
+	assert.Empty(t, computeLSPCompletions(content, position), "the markdown body has no completions")
+}
+
+func TestComputeLSPCompletionsKeyItem(t *testing.T) {
+	content, position := lspCursor(t, "---\non: push\nsafe-outputs:\n  create-iss|\n---\n")
+	items := computeLSPCompletions(content, position)
+
+	var item *lspCompletionItem
+	for i := range items {
+		if items[i].Label == "create-issue" {
+			item = &items[i]
+		}
+	}
+	require.NotNil(t, item)
+	assert.Equal(t, lspCompletionKindProperty, item.Kind)
+	assert.Equal(t, "create-issue: ", item.InsertText)
+	require.NotNil(t, item.Documentation, "keys should be documented from the schema")
+	assert.NotEmpty(t, item.Documentation.Value)
+}
+
+func TestComputeLSPHover(t *testing.T) {
+	content := "---\non: push\ntools:\n  github:\n    toolsets: [default]\n---\n"
+
+	hover := computeLSPHover(content, lspPosition{Line: 4, Character: 6})
+	require.NotNil(t, hover)
+	assert.Equal(t, "markdown", hover.Contents.Kind)
+	assert.Contains(t, hover.Contents.Value, "**tools.github.toolsets**")
+	assert.Contains(t, hover.Contents.Value, "toolset")
+	assert.Equal(t, lspRange{Start: lspPosition{Line: 4, Character: 4}, End: lspPosition{Line: 4, Character: 12}}, *hover.Range)
+
+	assert.Nil(t, computeLSPHover(content, lspPosition{Line: 4, Character: 16}), "values have no hover")
+	assert.Nil(t, computeLSPHover(content, lspPosition{Line: 0, Character: 0}), "delimiters have no hover")
+}
+
+func TestComputeLSPDefinition(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	sharedDir := filepath.Join(workflowsDir, "shared")
+	require.NoError(t, os.MkdirAll(sharedDir, 0755))
+	for _, name := range []string{"tools.md", "reporting.md", "body.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(sharedDir, name), []byte("---\n---\n"), 0644))
+	}
+	workflowFile := filepath.Join(workflowsDir, "test.md")
+	content := `---
+on: push
+imports:
+  - shared/tools.md
+  - path: "shared/reporting.md"
+    inputs:
+      count: 3
+---
+
+{{#import shared/body.md}}
+`
+
+	tests := []struct {
+		name     string
+		position lspPosition
+		want     string
+	}{
+		{name: "string item", position: lspPosition{Line: 3, Character: 8}, want: filepath.Join(sharedDir, "tools.md")},
+		{name: "path key", position: lspPosition{Line: 4, Character: 14}, want: filepath.Join(sharedDir, "reporting.md")},
+		{name: "import directive", position: lspPosition{Line: 9, Character: 12}, want: filepath.Join(sharedDir, "body.md")},
+		{name: "import input", position: lspPosition{Line: 6, Character: 8}},
+		{name: "other key", position: lspPosition{Line: 1, Character: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := computeLSPDefinition(workflowFile, content, tt.position)
+			if tt.want == "" {
+				assert.Nil(t, location)
+				return
+			}
+			require.NotNil(t, location)
+			assert.Equal(t, tt.want, lspURIToPath(location.URI))
+			assert.Equal(t, lspRange{}, location.Range)
+		})
+	}
+}
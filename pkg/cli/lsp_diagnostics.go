@@ -0,0 +1,217 @@
+// This file provides the diagnostics published by `gh aw lsp`.
+//
+// Diagnostics are computed in two stages:
+//   - Frontmatter checks run on every change of the editor buffer: unclosed frontmatter,
+//     YAML syntax errors and JSON schema validation, positioned with the JSON path locator.
+//   - The compiler runs when a document is opened or saved and the buffer matches the
+//     file on disk, so that imports and other files are resolved exactly as `gh aw compile`
+//     would. The compiler never writes lock files in this mode.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+var lspDiagnosticsLog = logger.New("cli:lsp_diagnostics")
+
+// lspDiagnosticSource is the source reported with every diagnostic
+const lspDiagnosticSource = "gh-aw"
+
+// lspYAMLErrorPattern matches the "[line:column]" position prefix of YAML parser errors
+var lspYAMLErrorPattern = regexp.MustCompile(`^\[(\d+):(\d+)\]\s*(.*)`)
+
+// lspSchemaErrorPrefixPattern matches the "at '/path': " prefix of schema validation messages
+var lspSchemaErrorPrefixPattern = regexp.MustCompile(`^at '[^']*': `)
+
+// computeLSPDiagnostics returns the diagnostics of a workflow document. The compiler
+// only runs when compile is set and the content matches the file on disk.
+func computeLSPDiagnostics(path string, content string, compile bool) []lspDiagnostic {
+	lines := strings.Split(content, "\n")
+	diagnostics, frontmatterValid := lspFrontmatterDiagnostics(lines)
+	if !compile || !frontmatterValid {
+		return diagnostics
+	}
+
+	onDisk, err := os.ReadFile(path)
+	if err != nil || string(onDisk) != content {
+		lspDiagnosticsLog.Printf("Skipping compiler diagnostics for %s: buffer differs from file on disk", path)
+		return diagnostics
+	}
+	return append(diagnostics, lspCompilerDiagnostics(path, lines)...)
+}
+
+// lspFrontmatterDiagnostics validates the YAML syntax and schema of the frontmatter.
+// It reports whether the frontmatter is valid.
+func lspFrontmatterDiagnostics(lines []string) ([]lspDiagnostic, bool) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, true
+	}
+	start, end := lspFrontmatterBounds(lines)
+	if start < 0 {
+		return []lspDiagnostic{newLSPLineDiagnostic(lines, 0, 0, lspSeverityError, "frontmatter not properly closed: add a '---' line after the frontmatter")}, false
+	}
+
+	frontmatterYAML := strings.Join(lines[start+1:end], "\n")
+	var frontmatter map[string]any
+	if err := yaml.Unmarshal([]byte(frontmatterYAML), &frontmatter); err != nil {
+		message := strings.Split(err.Error(), "\n")[0]
+		line, column := 1, 1
+		if match := lspYAMLErrorPattern.FindStringSubmatch(message); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			column, _ = strconv.Atoi(match[2])
+			message = match[3]
+		}
+		return []lspDiagnostic{newLSPLineDiagnostic(lines, start+line, column-1, lspSeverityError, message)}, false
+	}
+	if frontmatter == nil {
+		frontmatter = make(map[string]any)
+	}
+
+	// Workflows without a trigger are shared components validated like imports
+	var err error
+	if _, hasOn := frontmatter["on"]; hasOn {
+		err = parser.ValidateMainWorkflowFrontmatterWithSchema(frontmatter)
+	} else {
+		err = parser.ValidateIncludedFileFrontmatterWithSchema(frontmatter)
+	}
+	if err == nil {
+		return nil, true
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []lspDiagnostic{newLSPLineDiagnostic(lines, start, 0, lspSeverityError, err.Error())}, false
+	}
+
+	var diagnostics []lspDiagnostic
+	for _, cause := range validationErr.Causes {
+		// Report the most specific failure of oneOf/anyOf alternatives
+		leaf := deepestLSPSchemaCause(cause)
+		message := lspSchemaErrorPrefixPattern.ReplaceAllString(leaf.Error(), "")
+		line, column := start, 0
+		// Flow sequence items cannot be located, so fall back to the enclosing keys
+		for segments := leaf.InstanceLocation; ; segments = segments[:len(segments)-1] {
+			jsonPath := ""
+			if len(segments) > 0 {
+				jsonPath = "/" + strings.Join(segments, "/")
+			}
+			if location := parser.LocateJSONPathInYAMLWithAdditionalProperties(frontmatterYAML, jsonPath, leaf.Error()); location.Found {
+				// Frontmatter line 1 is the line after the opening delimiter
+				line, column = start+location.Line, max(location.Column-1, 0)
+				break
+			}
+			if len(segments) == 0 {
+				break
+			}
+		}
+		diagnostics = append(diagnostics, newLSPLineDiagnostic(lines, line, column, lspSeverityError, message))
+	}
+	if len(diagnostics) == 0 {
+		diagnostics = append(diagnostics, newLSPLineDiagnostic(lines, start, 0, lspSeverityError, err.Error()))
+	}
+	return diagnostics, false
+}
+
+// deepestLSPSchemaCause returns the leaf cause with the deepest instance location
+func deepestLSPSchemaCause(cause *jsonschema.ValidationError) *jsonschema.ValidationError {
+	deepest := cause
+	for _, child := range cause.Causes {
+		if leaf := deepestLSPSchemaCause(child); len(leaf.InstanceLocation) > len(deepest.InstanceLocation) || deepest == cause {
+			deepest = leaf
+		}
+	}
+	return deepest
+}
+
+// lspCompilerDiagnostics compiles the workflow file without emitting a lock file and
+// converts the compiler error into diagnostics
+func lspCompilerDiagnostics(path string, lines []string) []lspDiagnostic {
+	gitRoot, err := findGitRootForPath(path)
+	if err != nil {
+		gitRoot = filepath.Dir(path)
+	}
+	compiler := workflow.NewCompiler(
+		workflow.WithNoEmit(true),
+		workflow.WithGitRoot(gitRoot),
+	)
+	compiler.SetQuiet(true)
+	if relPath, err := filepath.Rel(gitRoot, path); err == nil {
+		compiler.SetWorkflowIdentifier(filepath.ToSlash(relPath))
+	}
+
+	workflowData, err := compiler.ParseWorkflowFile(path)
+	if err == nil {
+		err = compiler.CompileWorkflowData(workflowData, path)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var sharedErr *workflow.SharedWorkflowError
+	if errors.As(err, &sharedErr) {
+		return nil
+	}
+	lspDiagnosticsLog.Printf("Compilation of %s failed: %v", path, err)
+
+	var policyErr *workflow.PolicyViolationError
+	if errors.As(err, &policyErr) {
+		var diagnostics []lspDiagnostic
+		for _, violation := range policyErr.Violations {
+			diagnostics = append(diagnostics, newLSPLineDiagnostic(lines, max(violation.Line-1, 0), max(violation.Column-1, 0), lspSeverityError, violation.Message))
+		}
+		return diagnostics
+	}
+
+	var validationErr *workflow.WorkflowValidationError
+	if errors.As(err, &validationErr) && validationErr.Field == "expressions" {
+		var diagnostics []lspDiagnostic
+		for _, location := range locateUnauthorizedExpressions(strings.Join(lines, "\n"), validationErr) {
+			message := fmt.Sprintf("expression '%s' is not allowed in workflow markdown", location.expression)
+			diagnostics = append(diagnostics, newLSPLineDiagnostic(lines, location.line-1, location.column-1, lspSeverityError, message))
+		}
+		return diagnostics
+	}
+
+	file, line, column, message := parseCompilerErrorPosition(err)
+	if file != "" && filepath.Clean(file) != filepath.Clean(path) {
+		// Errors in imported files are reported at the top of the workflow
+		return []lspDiagnostic{newLSPLineDiagnostic(lines, 0, 0, lspSeverityError, fmt.Sprintf("%s:%d:%d: %s", file, line, column, message))}
+	}
+	return []lspDiagnostic{newLSPLineDiagnostic(lines, line-1, column-1, lspSeverityError, message)}
+}
+
+// newLSPLineDiagnostic creates a diagnostic from a 0-based line and byte column to the end of the line
+func newLSPLineDiagnostic(lines []string, line int, column int, severity int, message string) lspDiagnostic {
+	line = min(max(line, 0), max(len(lines)-1, 0))
+	text := ""
+	if line < len(lines) {
+		text = lines[line]
+	}
+	column = min(max(column, 0), len(text))
+	end := len(strings.TrimRight(text, " \t\r"))
+	if end <= column {
+		end = len(text)
+	}
+	return lspDiagnostic{
+		Range: lspRange{
+			Start: lspPosition{Line: line, Character: lspByteToCharacter(text, column)},
+			End:   lspPosition{Line: line, Character: lspByteToCharacter(text, end)},
+		},
+		Severity: severity,
+		Source:   lspDiagnosticSource,
+		Message:  message,
+	}
+}
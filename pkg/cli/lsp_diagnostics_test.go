@@ -0,0 +1,121 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSPFrontmatterDiagnostics(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLine  int
+		wantStart int
+		contains  string
+	}{
+		{
+			name:     "unclosed frontmatter",
+			content:  "---\non: push\n",
+			wantLine: 0,
+			contains: "not properly closed",
+		},
+		{
+			name:      "yaml syntax error",
+			content:   "---\non: push\npermissions:\n  contents: read\n bad: [\n---\n",
+			wantLine:  4,
+			wantStart: 1,
+		},
+		{
+			name:      "unknown property",
+			content:   "---\non: push\npermissions:\n  contents: read\nunknown-field: true\n---\n",
+			wantLine:  4,
+			wantStart: 0,
+			contains:  "unknown-field",
+		},
+		{
+			name:      "nested invalid value",
+			content:   "---\non: push\ntools:\n  github:\n    toolsets: [not-a-toolset]\n---\n",
+			wantLine:  4,
+			wantStart: 13,
+			contains:  "value must be one of",
+		},
+		{
+			name:     "shared workflow field",
+			content:  "---\ntools:\n  github:\nunknown-field: true\n---\n",
+			wantLine: 3,
+			contains: "unknown-field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, valid := lspFrontmatterDiagnostics(splitLSPLines(tt.content))
+			assert.False(t, valid)
+			require.NotEmpty(t, diagnostics)
+			diagnostic := diagnostics[0]
+			assert.Equal(t, tt.wantLine, diagnostic.Range.Start.Line, "diagnostic: %s", diagnostic.Message)
+			assert.Equal(t, tt.wantStart, diagnostic.Range.Start.Character)
+			assert.Equal(t, lspSeverityError, diagnostic.Severity)
+			assert.Equal(t, "gh-aw", diagnostic.Source)
+			assert.NotContains(t, diagnostic.Message, "at '", "the JSON pointer prefix should be stripped")
+			if tt.contains != "" {
+				assert.Contains(t, diagnostic.Message, tt.contains)
+			}
+		})
+	}
+}
+
+func TestLSPFrontmatterDiagnosticsValid(t *testing.T) {
+	for _, content := range []string{
+		"---\non: push\npermissions:\n  contents: read\n---\n\n# Task\n",
+		"---\ntools:\n  github:\n---\n",
+		"# No frontmatter\n",
+	} {
+		diagnostics, valid := lspFrontmatterDiagnostics(splitLSPLines(content))
+		assert.True(t, valid, content)
+		assert.Empty(t, diagnostics, content)
+	}
+}
+
+func TestComputeLSPDiagnosticsCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowFile := filepath.Join(workflowsDir, "test.md")
+	content := "---\non: workflow_dispatch\npermissions:\n  contents: read\n---\n\nUse ${{ secrets.TOKEN }}\n"
+	require.NoError(t, os.WriteFile(workflowFile, []byte(content), 0644))
+
+	assert.Empty(t, computeLSPDiagnostics(workflowFile, content, false), "the compiler only runs on open and save")
+
+	diagnostics := computeLSPDiagnostics(workflowFile, content, true)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "expression 'secrets.TOKEN' is not allowed in workflow markdown", diagnostics[0].Message)
+	assert.Equal(t, lspPosition{Line: 6, Character: 8}, diagnostics[0].Range.Start)
+
+	assert.Empty(t, computeLSPDiagnostics(workflowFile, content+"\nEdited\n", true), "unsaved buffers are not compiled")
+	_, err := os.Stat(filepath.Join(workflowsDir, "test.lock.yml"))
+	assert.True(t, os.IsNotExist(err), "diagnostics must not write lock files")
+}
+
+func TestNewLSPLineDiagnostic(t *testing.T) {
+	lines := []string{"---", "on: push  ", "---"}
+
+	diagnostic := newLSPLineDiagnostic(lines, 1, 0, lspSeverityError, "message")
+	assert.Equal(t, lspRange{Start: lspPosition{Line: 1}, End: lspPosition{Line: 1, Character: 8}}, diagnostic.Range, "trailing spaces are not highlighted")
+
+	diagnostic = newLSPLineDiagnostic(lines, 10, 40, lspSeverityError, "message")
+	assert.Equal(t, 2, diagnostic.Range.Start.Line, "lines are clamped to the document")
+	assert.Equal(t, 3, diagnostic.Range.Start.Character)
+}
+
+// splitLSPLines splits content the way the language server does
+func splitLSPLines(content string) []string {
+	lines, _, _ := lspFrontmatterLines(content)
+	return lines
+}
@@ -0,0 +1,258 @@
+// This file provides the Language Server Protocol transport used by `gh aw lsp`.
+//
+// Messages are JSON-RPC 2.0 objects framed with a Content-Length header, as
+// described in the LSP base protocol. Only the subset of the protocol types
+// that the agentic workflow language server needs is defined here.
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var lspProtocolLog = logger.New("cli:lsp_protocol")
+
+// JSON-RPC error codes used by the language server
+const (
+	lspMethodNotFound       = -32601
+	lspInvalidParams        = -32602
+	lspServerNotInitialized = -32002
+)
+
+// LSP enumerations
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+
+	lspTextDocumentSyncFull = 1
+
+	lspCompletionKindValue    = 12
+	lspCompletionKindProperty = 10
+)
+
+// lspMessage is an incoming JSON-RPC request or notification
+type lspMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// lspResponse is an outgoing JSON-RPC response
+type lspResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *lspError       `json:"error,omitempty"`
+}
+
+// lspNotification is an outgoing JSON-RPC notification
+type lspNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// lspError is a JSON-RPC error object
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *lspError) Error() string {
+	return e.Message
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspTextDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type lspDidOpenParams struct {
+	TextDocument lspTextDocumentItem `json:"textDocument"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument   lspTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type lspDidSaveParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Text         *string                   `json:"text,omitempty"`
+}
+
+type lspTextDocumentPositionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Position     lspPosition               `json:"position"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspCompletionItem struct {
+	Label         string            `json:"label"`
+	Kind          int               `json:"kind"`
+	Detail        string            `json:"detail,omitempty"`
+	Documentation *lspMarkupContent `json:"documentation,omitempty"`
+	InsertText    string            `json:"insertText,omitempty"`
+}
+
+type lspCompletionList struct {
+	IsIncomplete bool                `json:"isIncomplete"`
+	Items        []lspCompletionItem `json:"items"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    *lspRange        `json:"range,omitempty"`
+}
+
+// lspConn reads and writes framed JSON-RPC messages
+type lspConn struct {
+	reader *bufio.Reader
+	writer io.Writer
+	mu     sync.Mutex
+}
+
+func newLSPConn(in io.Reader, out io.Writer) *lspConn {
+	return &lspConn{reader: bufio.NewReader(in), writer: out}
+}
+
+// read returns the next message, or io.EOF when the client closed the stream
+func (c *lspConn) read() (*lspMessage, error) {
+	headers, err := textproto.NewReader(c.reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || (len(headers) == 0 && strings.Contains(err.Error(), "EOF")) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", headers.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	lspProtocolLog.Printf("Received message: method=%s, request=%t", msg.Method, msg.ID != nil)
+	return &msg, nil
+}
+
+// write sends a message with its Content-Length header
+func (c *lspConn) write(v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(body)
+	return err
+}
+
+// reply sends the response to a request
+func (c *lspConn) reply(id json.RawMessage, result any, err error) error {
+	response := lspResponse{JSONRPC: "2.0", ID: id}
+	if err != nil {
+		lspErr, ok := err.(*lspError)
+		if !ok {
+			lspErr = &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		response.Error = lspErr
+		return c.write(response)
+	}
+
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal result: %w", marshalErr)
+	}
+	response.Result = data
+	return c.write(response)
+}
+
+// notify sends a notification to the client
+func (c *lspConn) notify(method string, params any) error {
+	return c.write(lspNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// lspCharacterToByte converts an LSP character offset, counted in UTF-16 code units, to a byte offset in line
+func lspCharacterToByte(line string, character int) int {
+	units := 0
+	for offset, r := range line {
+		if units >= character {
+			return offset
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// lspByteToCharacter converts a byte offset in line to an LSP character offset
+func lspByteToCharacter(line string, offset int) int {
+	offset = min(offset, len(line))
+	units := 0
+	for i := 0; i < offset; {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		units += utf16.RuneLen(r)
+		i += size
+	}
+	return units
+}
@@ -0,0 +1,104 @@
+//go:build !integration
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLSPFrame reads one framed JSON-RPC message written by the language server
+func readLSPFrame(t *testing.T, reader *bufio.Reader) map[string]any {
+	t.Helper()
+	headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+	require.NoError(t, err)
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	require.NoError(t, err)
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	require.NoError(t, err)
+
+	var msg map[string]any
+	require.NoError(t, json.Unmarshal(body, &msg))
+	return msg
+}
+
+// lspFrame frames a JSON-RPC message the way a client sends it
+func lspFrame(t *testing.T, msg map[string]any) string {
+	t.Helper()
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	require.NoError(t, err)
+	return "Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + string(body)
+}
+
+func TestLSPConnWrite(t *testing.T) {
+	var buf bytes.Buffer
+	conn := newLSPConn(strings.NewReader(""), &buf)
+	require.NoError(t, conn.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{URI: "file:///a.md", Diagnostics: []lspDiagnostic{}}))
+	require.NoError(t, conn.reply(json.RawMessage(`7`), map[string]string{"ok": "yes"}, nil))
+	require.NoError(t, conn.reply(json.RawMessage(`"x"`), nil, &lspError{Code: lspMethodNotFound, Message: "method not found"}))
+	require.NoError(t, conn.reply(json.RawMessage(`8`), nil, nil))
+
+	reader := bufio.NewReader(&buf)
+	notification := readLSPFrame(t, reader)
+	assert.Equal(t, "textDocument/publishDiagnostics", notification["method"])
+	assert.NotContains(t, notification, "id")
+
+	result := readLSPFrame(t, reader)
+	assert.InDelta(t, 7, result["id"], 0)
+	assert.Equal(t, map[string]any{"ok": "yes"}, result["result"])
+
+	failure := readLSPFrame(t, reader)
+	assert.Equal(t, "x", failure["id"])
+	assert.InDelta(t, lspMethodNotFound, failure["error"].(map[string]any)["code"], 0)
+
+	null := readLSPFrame(t, reader)
+	assert.Contains(t, null, "result", "a null result must still be sent")
+	assert.Nil(t, null["result"])
+}
+
+func TestLSPConnRead(t *testing.T) {
+	input := lspFrame(t, map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}}) +
+		lspFrame(t, map[string]any{"method": "initialized"})
+	conn := newLSPConn(strings.NewReader(input), io.Discard)
+
+	msg, err := conn.read()
+	require.NoError(t, err)
+	assert.Equal(t, "initialize", msg.Method)
+	assert.JSONEq(t, "1", string(msg.ID))
+
+	msg, err = conn.read()
+	require.NoError(t, err)
+	assert.Equal(t, "initialized", msg.Method)
+	assert.Nil(t, msg.ID, "notifications have no id")
+
+	_, err = conn.read()
+	assert.Equal(t, io.EOF, err, "a closed stream should end the session")
+
+	_, err = newLSPConn(strings.NewReader("Content-Length: abc\r\n\r\n{}"), io.Discard).read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Content-Length")
+}
+
+func TestLSPCharacterOffsets(t *testing.T) {
+	line := "title: héllo 🚀 world"
+	accent := strings.Index(line, "é")
+	rocket := strings.Index(line, "🚀")
+	after := rocket + len("🚀")
+
+	assert.Equal(t, accent+1, lspByteToCharacter(line, accent+len("é")), "two-byte runes are one UTF-16 code unit")
+	assert.Equal(t, lspByteToCharacter(line, rocket)+2, lspByteToCharacter(line, after), "non-BMP runes are two UTF-16 code units")
+	assert.Equal(t, rocket, lspCharacterToByte(line, lspByteToCharacter(line, rocket)))
+	assert.Equal(t, after, lspCharacterToByte(line, lspByteToCharacter(line, after)))
+	assert.Equal(t, len(line), lspCharacterToByte(line, 1000), "offsets past the end are clamped")
+}
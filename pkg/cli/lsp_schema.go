@@ -0,0 +1,214 @@
+// This file provides the frontmatter schema lookups and YAML position analysis used by
+// completion and hover in `gh aw lsp`.
+//
+// The frontmatter of a workflow is analyzed line by line, so that completion also
+// works while the YAML is incomplete. A position is described by the path of the
+// enclosing mappings (e.g. ["tools", "github"]), where "[]" stands for an array item.
+// The path is looked up in the main workflow JSON schema, following $ref, oneOf,
+// anyOf and allOf, to find the properties, enum values and descriptions.
+
+package cli
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+)
+
+var lspSchemaLog = logger.New("cli:lsp_schema")
+
+// lspArrayItem is the path segment for an array item
+const lspArrayItem = "[]"
+
+// lspMainSchema returns the parsed main workflow schema
+var lspMainSchema = sync.OnceValue(func() map[string]any {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(parser.GetMainWorkflowSchema()), &schema); err != nil {
+		lspSchemaLog.Printf("Failed to parse main workflow schema: %v", err)
+		return map[string]any{}
+	}
+	return schema
+})
+
+// lspYAMLKeyPattern matches a mapping key at the start of a (trimmed) YAML line
+var lspYAMLKeyPattern = regexp.MustCompile(`^("[^"]*"|'[^']*'|[A-Za-z0-9_.$/\-]+)\s*:(\s|$)`)
+
+// lspSchemaNodes returns the schema alternatives describing the value at path
+func lspSchemaNodes(path []string) []map[string]any {
+	root := lspMainSchema()
+	nodes := expandLSPSchemaNode(root, root, 0)
+	for _, segment := range path {
+		var children []map[string]any
+		for _, node := range nodes {
+			for _, child := range lspSchemaChildren(node, segment) {
+				children = append(children, expandLSPSchemaNode(root, child, 0)...)
+			}
+		}
+		nodes = children
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// expandLSPSchemaNode resolves $ref and flattens oneOf, anyOf and allOf alternatives
+func expandLSPSchemaNode(root, node map[string]any, depth int) []map[string]any {
+	if depth > 8 {
+		return nil
+	}
+	nodes := []map[string]any{node}
+	if ref, ok := node["$ref"].(string); ok {
+		if name, found := strings.CutPrefix(ref, "#/$defs/"); found {
+			if defs, ok := root["$defs"].(map[string]any); ok {
+				if def, ok := defs[name].(map[string]any); ok {
+					nodes = append(nodes, expandLSPSchemaNode(root, def, depth+1)...)
+				}
+			}
+		}
+	}
+	for _, keyword := range []string{"oneOf", "anyOf", "allOf"} {
+		alternatives, _ := node[keyword].([]any)
+		for _, alternative := range alternatives {
+			if alternativeNode, ok := alternative.(map[string]any); ok {
+				nodes = append(nodes, expandLSPSchemaNode(root, alternativeNode, depth+1)...)
+			}
+		}
+	}
+	return nodes
+}
+
+// lspSchemaChildren returns the schemas of a property or of the array items of a node
+func lspSchemaChildren(node map[string]any, segment string) []map[string]any {
+	if segment == lspArrayItem {
+		if items, ok := node["items"].(map[string]any); ok {
+			return []map[string]any{items}
+		}
+		return nil
+	}
+	if properties, ok := node["properties"].(map[string]any); ok {
+		if property, ok := properties[segment].(map[string]any); ok {
+			return []map[string]any{property}
+		}
+	}
+	if additional, ok := node["additionalProperties"].(map[string]any); ok {
+		return []map[string]any{additional}
+	}
+	return nil
+}
+
+// lspSchemaProperties returns the documented property names of the nodes with their descriptions
+func lspSchemaProperties(nodes []map[string]any) map[string]string {
+	properties := make(map[string]string)
+	for _, node := range nodes {
+		nodeProperties, _ := node["properties"].(map[string]any)
+		for name, property := range nodeProperties {
+			if properties[name] == "" {
+				properties[name] = lspSchemaDescription(expandLSPSchemaNode(lspMainSchema(), property.(map[string]any), 0))
+			}
+		}
+	}
+	return properties
+}
+
+// lspSchemaEnumValues returns the sorted string enum and const values of the nodes
+func lspSchemaEnumValues(nodes []map[string]any) []string {
+	seen := make(map[string]bool)
+	var values []string
+	add := func(value any) {
+		if s, ok := value.(string); ok && !seen[s] {
+			seen[s] = true
+			values = append(values, s)
+		}
+	}
+	for _, node := range nodes {
+		enum, _ := node["enum"].([]any)
+		for _, value := range enum {
+			add(value)
+		}
+		add(node["const"])
+	}
+	sort.Strings(values)
+	return values
+}
+
+// lspSchemaDescription returns the first description of the nodes
+func lspSchemaDescription(nodes []map[string]any) string {
+	for _, node := range nodes {
+		if description, ok := node["description"].(string); ok && description != "" {
+			return description
+		}
+	}
+	return ""
+}
+
+// lspFrontmatterBounds returns the 0-based line indexes of the opening and closing
+// frontmatter delimiters, or -1 when the document has no closed frontmatter
+func lspFrontmatterBounds(lines []string) (int, int) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return -1, -1
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return 0, i
+		}
+	}
+	return -1, -1
+}
+
+// lspLineIndent returns the number of leading spaces of a line
+func lspLineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// lspYAMLKey returns the mapping key of a trimmed YAML line without quotes
+func lspYAMLKey(trimmed string) (string, bool) {
+	match := lspYAMLKeyPattern.FindStringSubmatch(trimmed)
+	if match == nil {
+		return "", false
+	}
+	return strings.Trim(match[1], `"'`), true
+}
+
+// lspYAMLParentPath returns the path of the mapping that contains a node at the given
+// indentation on lines[lineIndex], by walking up to the lines with a smaller indentation
+func lspYAMLParentPath(lines []string, lineIndex int, indent int) []string {
+	var path []string
+	for i := lineIndex - 1; i >= 0 && indent > 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lineIndent := lspLineIndent(lines[i])
+		if lineIndent >= indent {
+			continue
+		}
+
+		if item, isItem := strings.CutPrefix(trimmed, "- "); isItem || trimmed == "-" {
+			// A list item: its inline key is the parent when the node is indented below it
+			if key, ok := lspYAMLKey(strings.TrimSpace(item)); ok && lineIndent+2 < indent && lspYAMLValueIsEmpty(item) {
+				path = append([]string{key}, path...)
+			}
+			path = append([]string{lspArrayItem}, path...)
+		} else if key, ok := lspYAMLKey(trimmed); ok {
+			path = append([]string{key}, path...)
+		}
+		indent = lineIndent
+	}
+	return path
+}
+
+// lspYAMLValueIsEmpty reports whether a "key:" line opens a nested block rather than holding a value
+func lspYAMLValueIsEmpty(trimmed string) bool {
+	_, value, found := strings.Cut(trimmed, ":")
+	if !found {
+		return false
+	}
+	value = strings.TrimSpace(value)
+	return value == "" || strings.HasPrefix(value, "#") || value == "|" || value == ">"
+}
@@ -0,0 +1,52 @@
+//go:build !integration
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLSPYAMLParentPath(t *testing.T) {
+	lines := strings.Split(`---
+on:
+  issues:
+    types: [opened]
+tools:
+  github:
+    # comment
+    toolsets:
+      - default
+imports:
+  - path: shared/a.md
+    inputs:
+      count: 1
+---`, "\n")
+
+	tests := []struct {
+		name   string
+		line   int
+		indent int
+		want   []string
+	}{
+		{name: "top level", line: 1, indent: 0, want: nil},
+		{name: "nested key", line: 3, indent: 4, want: []string{"on", "issues"}},
+		{name: "skips comments", line: 7, indent: 4, want: []string{"tools", "github"}},
+		{name: "sequence item", line: 8, indent: 6, want: []string{"tools", "github", "toolsets"}},
+		{name: "mapping item key", line: 11, indent: 4, want: []string{"imports", lspArrayItem}},
+		{name: "below item key", line: 12, indent: 6, want: []string{"imports", lspArrayItem, "inputs"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, lspYAMLParentPath(lines, tt.line, tt.indent), tt.name)
+	}
+}
+
+func TestLSPSchemaNodes(t *testing.T) {
+	assert.Contains(t, lspSchemaProperties(lspSchemaNodes(nil)), "safe-outputs")
+	assert.Contains(t, lspSchemaProperties(lspSchemaNodes([]string{"tools", "github"})), "toolsets", "oneOf alternatives should be followed")
+	assert.Contains(t, lspSchemaEnumValues(lspSchemaNodes([]string{"tools", "github", "toolsets", lspArrayItem})), "pull_requests")
+	assert.NotEmpty(t, lspSchemaDescription(lspSchemaNodes([]string{"engine"})), "$ref targets keep the property description")
+	assert.Empty(t, lspSchemaNodes([]string{"no-such-key", "child"}))
+}
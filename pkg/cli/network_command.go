@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var networkCommandLog = logger.New("cli:network_command")
+
+// NewNetworkCommand creates the network command with its subcommands
+func NewNetworkCommand() *cobra.Command {
+	networkCommandLog.Print("Creating network command with subcommands")
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Analyze and tune the network allow-list of agentic workflows",
+		Long: `Analyze the network access of agentic workflows and tune their 'network:' configuration.
+
+Available subcommands:
+  • suggest - Suggest a minimal network allow-list from downloaded firewall logs
+
+Examples:
+  gh aw network suggest issue-triage          # Suggest a network block from cached runs
+  gh aw network suggest issue-triage --write  # Apply the suggestion to the workflow`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewNetworkSuggestSubcommand())
+
+	return cmd
+}
@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+var networkSuggestLog = logger.New("cli:network_suggest_command")
+
+// NetworkSuggestConfig holds configuration for the network suggest command
+type NetworkSuggestConfig struct {
+	WorkflowName string
+	OutputDir    string // Logs directory containing the run index
+	Write        bool   // Rewrite network.allowed in the workflow frontmatter
+	JSONOutput   bool
+	Verbose      bool
+}
+
+// NetworkSuggestion is the network allow-list suggested for a workflow
+type NetworkSuggestion struct {
+	Workflow         string                      `json:"workflow"`
+	RunsAnalyzed     int                         `json:"runs_analyzed"`
+	CurrentAllowed   []string                    `json:"current_allowed"`
+	SuggestedAllowed []string                    `json:"suggested_allowed"`
+	Additions        []NetworkSuggestionAddition `json:"additions,omitempty"`
+	Unused           []string                    `json:"unused,omitempty"`
+	Changed          bool                        `json:"changed"`
+	Written          bool                        `json:"written,omitempty"`
+}
+
+// NetworkSuggestionAddition is an allow-list entry suggested for blocked domains
+type NetworkSuggestionAddition struct {
+	Entry           string   `json:"entry"`
+	Ecosystem       bool     `json:"ecosystem"`
+	BlockedDomains  []string `json:"blocked_domains"`
+	BlockedRequests int      `json:"blocked_requests"`
+}
+
+// NewNetworkSuggestSubcommand creates the network suggest subcommand
+func NewNetworkSuggestSubcommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest <workflow>",
+		Short: "Suggest a minimal network allow-list from downloaded firewall logs",
+		Long: `Suggest a minimal 'network:' configuration for a workflow from the firewall logs of
+its runs already downloaded with the 'logs' command.
+
+Domains blocked by the firewall are mapped to ecosystem identifiers (for example
+'python' or 'node') whenever possible, and added as raw domains otherwise. Domains
+listed in network.blocked are never suggested. Entries of network.allowed that no run
+contacted are flagged and left out of the suggestion; 'defaults' is always kept.
+
+The suggested block is printed to stdout. Use --write to update network.allowed in
+the workflow frontmatter; other network settings are preserved.
+
+` + WorkflowIDExplanation + `
+
+Examples:
+  ` + string(constants.CLIExtensionPrefix) + ` logs issue-triage -c 20                 # Download recent runs first
+  ` + string(constants.CLIExtensionPrefix) + ` network suggest issue-triage            # Print the suggested network block
+  ` + string(constants.CLIExtensionPrefix) + ` network suggest issue-triage --write    # Apply the suggestion
+  ` + string(constants.CLIExtensionPrefix) + ` network suggest issue-triage --json     # Output the analysis as JSON`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputDir, _ := cmd.Flags().GetString("output")
+			write, _ := cmd.Flags().GetBool("write")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+
+			return RunNetworkSuggest(NetworkSuggestConfig{
+				WorkflowName: args[0],
+				OutputDir:    outputDir,
+				Write:        write,
+				JSONOutput:   jsonOutput,
+				Verbose:      verbose,
+			})
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", defaultLogsOutputDir, "Logs directory containing runs downloaded by 'logs'")
+	cmd.Flags().Bool("write", false, "Update network.allowed in the workflow frontmatter")
+	addJSONFlag(cmd)
+
+	cmd.ValidArgsFunction = CompleteWorkflowNames
+
+	return cmd
+}
+
+// RunNetworkSuggest executes the network suggest command
+func RunNetworkSuggest(config NetworkSuggestConfig) error {
+	networkSuggestLog.Printf("Suggesting network allow-list: workflow=%s, dir=%s, write=%t", config.WorkflowName, config.OutputDir, config.Write)
+
+	workflowPath, err := resolveWorkflowFile(config.WorkflowName, config.Verbose)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	result, err := parser.ExtractFrontmatterFromContent(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse workflow frontmatter: %w", err)
+	}
+
+	workflowID := normalizeWorkflowID(workflowPath)
+	analysis, runs, err := loadCachedFirewallAnalysis(config.OutputDir, workflowID, config.Verbose)
+	if err != nil {
+		return fmt.Errorf("failed to load cached runs: %w", err)
+	}
+	if runs == 0 {
+		return fmt.Errorf("no downloaded runs with firewall logs found for workflow '%s' in %s. Run '%s logs %s' first", workflowID, config.OutputDir, constants.CLIExtensionPrefix, workflowID)
+	}
+
+	current, blocked := networkConfigFromFrontmatter(result.Frontmatter)
+	suggestion := buildNetworkSuggestion(workflowID, current, blocked, analysis, runs)
+
+	if config.Write && suggestion.Changed {
+		updated, err := SetNetworkAllowedInFrontmatter(string(content), suggestion.SuggestedAllowed)
+		if err != nil {
+			return fmt.Errorf("failed to update network configuration: %w", err)
+		}
+		if err := os.WriteFile(workflowPath, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write workflow file: %w", err)
+		}
+		suggestion.Written = true
+	}
+
+	if config.JSONOutput {
+		jsonBytes, err := json.MarshalIndent(suggestion, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	renderNetworkSuggestion(suggestion, workflowPath)
+	return nil
+}
+
+// loadCachedFirewallAnalysis combines the firewall analyses of the indexed runs of a workflow.
+// Runs indexed without a firewall analysis are analyzed from their run folder.
+func loadCachedFirewallAnalysis(outputDir, workflowID string, verbose bool) (*FirewallAnalysis, int, error) {
+	index, err := OpenRunIndex(outputDir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	combined := &FirewallAnalysis{RequestsByDomain: make(map[string]DomainRequestStats)}
+	runs := 0
+	for _, record := range index.Query(RunIndexFilter{WorkflowName: workflowID}) {
+		analysis := record.Summary.FirewallAnalysis
+		if analysis == nil {
+			analysis, err = analyzeFirewallLogs(filepath.Join(outputDir, record.RunDir), verbose)
+			if err != nil || analysis == nil {
+				networkSuggestLog.Printf("No firewall analysis for run %d: %v", record.RunID, err)
+				continue
+			}
+		}
+		runs++
+
+		combined.AddMetrics(analysis)
+		// Domains listed without request statistics still count as contacted or blocked
+		for _, domain := range analysis.AllowedDomains {
+			if _, exists := analysis.RequestsByDomain[domain]; !exists {
+				stats := combined.RequestsByDomain[domain]
+				stats.Allowed++
+				combined.RequestsByDomain[domain] = stats
+			}
+		}
+		for _, domain := range analysis.BlockedDomains {
+			if _, exists := analysis.RequestsByDomain[domain]; !exists {
+				stats := combined.RequestsByDomain[domain]
+				stats.Blocked++
+				combined.RequestsByDomain[domain] = stats
+			}
+		}
+	}
+
+	if verbose {
+		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage(fmt.Sprintf("Found firewall logs for %d runs of %s in %s", runs, workflowID, outputDir)))
+	}
+	return combined, runs, nil
+}
+
+// networkConfigFromFrontmatter returns the network.allowed and network.blocked entries of a
+// workflow. A workflow without network configuration uses the defaults.
+func networkConfigFromFrontmatter(frontmatter map[string]any) ([]string, []string) {
+	network, exists := frontmatter["network"]
+	if !exists {
+		return []string{"defaults"}, nil
+	}
+
+	toStrings := func(value any) []string {
+		items, _ := value.([]any)
+		var result []string
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	switch network := network.(type) {
+	case string:
+		return []string{network}, nil
+	case map[string]any:
+		return toStrings(network["allowed"]), toStrings(network["blocked"])
+	}
+	return nil, nil
+}
+
+// buildNetworkSuggestion computes the minimal allow-list covering the observed network access
+func buildNetworkSuggestion(workflowID string, current, blocked []string, analysis *FirewallAnalysis, runs int) *NetworkSuggestion {
+	suggestion := &NetworkSuggestion{
+		Workflow:       workflowID,
+		RunsAnalyzed:   runs,
+		CurrentAllowed: current,
+	}
+	if suggestion.CurrentAllowed == nil {
+		suggestion.CurrentAllowed = []string{}
+	}
+
+	contacted := make(map[string]bool)
+	blockedRequests := make(map[string]int)
+	for domain, stats := range analysis.RequestsByDomain {
+		host := firewallDomainHost(domain)
+		if host == "" {
+			continue
+		}
+		if stats.Allowed > 0 {
+			contacted[host] = true
+		}
+		if stats.Blocked > 0 {
+			blockedRequests[host] += stats.Blocked
+		}
+	}
+
+	// Keep the entries that were used; "defaults" covers infrastructure domains and is always kept
+	for _, entry := range current {
+		used := entry == "defaults"
+		for host := range contacted {
+			used = used || networkEntryCovers(entry, host)
+		}
+		if used {
+			suggestion.SuggestedAllowed = append(suggestion.SuggestedAllowed, entry)
+		} else {
+			suggestion.Unused = append(suggestion.Unused, entry)
+		}
+	}
+
+	// Group blocked domains by the ecosystem that allows them, or by domain
+	additions := make(map[string]*NetworkSuggestionAddition)
+	for host, count := range blockedRequests {
+		if slices.ContainsFunc(blocked, func(entry string) bool { return networkEntryCovers(entry, host) }) ||
+			slices.ContainsFunc(current, func(entry string) bool { return networkEntryCovers(entry, host) }) {
+			continue
+		}
+		entry := workflow.GetDomainEcosystem(host)
+		isEcosystem := entry != ""
+		if !isEcosystem {
+			entry = host
+		}
+		addition, exists := additions[entry]
+		if !exists {
+			addition = &NetworkSuggestionAddition{Entry: entry, Ecosystem: isEcosystem}
+			additions[entry] = addition
+		}
+		addition.BlockedDomains = append(addition.BlockedDomains, host)
+		addition.BlockedRequests += count
+	}
+
+	for _, addition := range additions {
+		sort.Strings(addition.BlockedDomains)
+		suggestion.Additions = append(suggestion.Additions, *addition)
+	}
+	// Ecosystem identifiers first, then raw domains
+	sort.Slice(suggestion.Additions, func(i, j int) bool {
+		a, b := suggestion.Additions[i], suggestion.Additions[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem
+		}
+		return a.Entry < b.Entry
+	})
+	for _, addition := range suggestion.Additions {
+		suggestion.SuggestedAllowed = append(suggestion.SuggestedAllowed, addition.Entry)
+	}
+	if suggestion.SuggestedAllowed == nil {
+		suggestion.SuggestedAllowed = []string{}
+	}
+
+	suggestion.Changed = !slices.Equal(suggestion.CurrentAllowed, suggestion.SuggestedAllowed)
+	networkSuggestLog.Printf("Suggestion for %s: %d additions, %d unused entries", workflowID, len(suggestion.Additions), len(suggestion.Unused))
+	return suggestion
+}
+
+// firewallDomainHost returns the host of a firewall log domain such as "api.github.com:443"
+func firewallDomainHost(domain string) string {
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	if domain == "-" {
+		return ""
+	}
+	return strings.ToLower(domain)
+}
+
+// networkEntryCovers reports whether a network.allowed entry (ecosystem identifier or domain)
+// allows a host. Like the firewall, a domain also allows its subdomains.
+func networkEntryCovers(entry, host string) bool {
+	for _, domain := range workflow.GetAllowedDomains(&workflow.NetworkPermissions{Allowed: []string{entry}}) {
+		domain = strings.TrimPrefix(strings.ToLower(domain), "*.")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderNetworkSuggestion prints the suggestion; the suggested network block goes to stdout
+func renderNetworkSuggestion(suggestion *NetworkSuggestion, workflowPath string) {
+	fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Analyzed firewall logs of %d runs of %s", suggestion.RunsAnalyzed, suggestion.Workflow)))
+
+	if len(suggestion.Additions) > 0 {
+		rows := make([][]string, 0, len(suggestion.Additions))
+		for _, addition := range suggestion.Additions {
+			kind := "domain"
+			if addition.Ecosystem {
+				kind = "ecosystem"
+			}
+			rows = append(rows, []string{addition.Entry, kind, strings.Join(addition.BlockedDomains, ", "), strconv.Itoa(addition.BlockedRequests)})
+		}
+		fmt.Fprint(os.Stderr, console.RenderTable(console.TableConfig{
+			Title:   "Blocked domains to allow",
+			Headers: []string{"Entry", "Type", "Blocked Domains", "Blocked Requests"},
+			Rows:    rows,
+		}))
+	}
+
+	if len(suggestion.Unused) > 0 {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Allowed but never contacted in %d runs: %s", suggestion.RunsAnalyzed, strings.Join(suggestion.Unused, ", "))))
+	}
+
+	if !suggestion.Changed {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage("network.allowed already matches the observed network access"))
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, console.FormatInfoMessage("Suggested network configuration:"))
+	fmt.Println("network:")
+	fmt.Println("  allowed:")
+	for _, entry := range suggestion.SuggestedAllowed {
+		if strings.HasPrefix(entry, "*") {
+			entry = fmt.Sprintf("%q", entry)
+		}
+		fmt.Println("    - " + entry)
+	}
+
+	if suggestion.Written {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage(fmt.Sprintf("Updated network.allowed in %s", workflowPath)))
+	}
+}
@@ -0,0 +1,156 @@
+//go:build !integration
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCachedFirewallRun writes a downloaded run of a workflow with the given firewall statistics
+func writeCachedFirewallRun(t *testing.T, logsDir string, runID int64, workflowID string, requests map[string]DomainRequestStats) {
+	t.Helper()
+	runDir := filepath.Join(logsDir, fmt.Sprintf("run-%d", runID))
+	require.NoError(t, os.MkdirAll(runDir, 0755))
+
+	summary := RunSummary{
+		RunID: runID,
+		Run:   WorkflowRun{DatabaseID: runID, WorkflowName: workflowID, WorkflowPath: ".github/workflows/" + workflowID + ".lock.yml"},
+	}
+	if requests != nil {
+		summary.FirewallAnalysis = &FirewallAnalysis{RequestsByDomain: requests}
+	}
+	content, err := json.Marshal(summary)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, runSummaryFileName), content, 0644))
+}
+
+func TestBuildNetworkSuggestion(t *testing.T) {
+	analysis := &FirewallAnalysis{RequestsByDomain: map[string]DomainRequestStats{
+		"raw.githubusercontent.com:443": {Allowed: 12},
+		"pypi.org:443":                  {Blocked: 3},
+		"files.pythonhosted.org:443":    {Blocked: 2},
+		"example.com:443":               {Blocked: 1},
+		"tracker.internal.net:443":      {Blocked: 4},
+		"-":                             {Blocked: 9},
+	}}
+	current := []string{"defaults", "github", "node"}
+	blocked := []string{"internal.net"}
+
+	suggestion := buildNetworkSuggestion("triage", current, blocked, analysis, 2)
+
+	assert.Equal(t, []string{"defaults", "github", "python", "example.com"}, suggestion.SuggestedAllowed, "ecosystems should come before raw domains")
+	assert.Equal(t, []string{"node"}, suggestion.Unused, "entries no run contacted should be flagged")
+	assert.True(t, suggestion.Changed)
+
+	require.Len(t, suggestion.Additions, 2, "domains in network.blocked should never be suggested")
+	assert.Equal(t, NetworkSuggestionAddition{Entry: "python", Ecosystem: true, BlockedDomains: []string{"files.pythonhosted.org", "pypi.org"}, BlockedRequests: 5}, suggestion.Additions[0])
+	assert.Equal(t, NetworkSuggestionAddition{Entry: "example.com", BlockedDomains: []string{"example.com"}, BlockedRequests: 1}, suggestion.Additions[1])
+}
+
+func TestBuildNetworkSuggestionUnchanged(t *testing.T) {
+	analysis := &FirewallAnalysis{RequestsByDomain: map[string]DomainRequestStats{
+		"registry.npmjs.org:443": {Allowed: 5},
+	}}
+
+	suggestion := buildNetworkSuggestion("docs", []string{"defaults", "node"}, nil, analysis, 1)
+
+	assert.Equal(t, []string{"defaults", "node"}, suggestion.SuggestedAllowed)
+	assert.Empty(t, suggestion.Additions)
+	assert.Empty(t, suggestion.Unused, "defaults is kept even without matching requests")
+	assert.False(t, suggestion.Changed)
+}
+
+func TestNetworkConfigFromFrontmatter(t *testing.T) {
+	allowed, blocked := networkConfigFromFrontmatter(map[string]any{"on": "push"})
+	assert.Equal(t, []string{"defaults"}, allowed, "workflows without network use the defaults")
+	assert.Nil(t, blocked)
+
+	allowed, _ = networkConfigFromFrontmatter(map[string]any{"network": "defaults"})
+	assert.Equal(t, []string{"defaults"}, allowed)
+
+	allowed, blocked = networkConfigFromFrontmatter(map[string]any{"network": map[string]any{
+		"allowed": []any{"defaults", "python"},
+		"blocked": []any{"tracker.example.com"},
+	}})
+	assert.Equal(t, []string{"defaults", "python"}, allowed)
+	assert.Equal(t, []string{"tracker.example.com"}, blocked)
+}
+
+func TestFirewallDomainHost(t *testing.T) {
+	assert.Equal(t, "api.github.com", firewallDomainHost("API.github.com:443"))
+	assert.Equal(t, "example.com", firewallDomainHost("example.com"))
+	assert.Empty(t, firewallDomainHost("-"), "placeholder domains should be skipped")
+}
+
+func TestNetworkEntryCovers(t *testing.T) {
+	assert.True(t, networkEntryCovers("python", "pypi.org"))
+	assert.True(t, networkEntryCovers("example.com", "api.example.com"), "domains allow their subdomains")
+	assert.True(t, networkEntryCovers("*.example.com", "cdn.example.com"))
+	assert.False(t, networkEntryCovers("example.com", "notexample.com"))
+	assert.False(t, networkEntryCovers("node", "pypi.org"))
+}
+
+func TestLoadCachedFirewallAnalysis(t *testing.T) {
+	logsDir := testutil.TempDir(t, "network-suggest-*")
+	writeCachedFirewallRun(t, logsDir, 1, "triage", map[string]DomainRequestStats{"pypi.org:443": {Blocked: 2}})
+	writeCachedFirewallRun(t, logsDir, 2, "triage", map[string]DomainRequestStats{"pypi.org:443": {Blocked: 1, Allowed: 1}})
+	writeCachedFirewallRun(t, logsDir, 3, "triage", nil)
+	writeCachedFirewallRun(t, logsDir, 4, "docs", map[string]DomainRequestStats{"example.com:443": {Blocked: 1}})
+
+	analysis, runs, err := loadCachedFirewallAnalysis(logsDir, "triage", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs, "runs without firewall logs and runs of other workflows should be skipped")
+	assert.Equal(t, DomainRequestStats{Allowed: 1, Blocked: 3}, analysis.RequestsByDomain["pypi.org:443"])
+	assert.NotContains(t, analysis.RequestsByDomain, "example.com:443")
+}
+
+func TestRunNetworkSuggestWrite(t *testing.T) {
+	tmpDir := testutil.TempDir(t, "network-suggest-*")
+	logsDir := filepath.Join(tmpDir, "logs")
+	writeCachedFirewallRun(t, logsDir, 1, "triage", map[string]DomainRequestStats{
+		"pypi.org:443":       {Blocked: 2},
+		"api.github.com:443": {Allowed: 3},
+	})
+
+	workflowPath := filepath.Join(tmpDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: issues
+network:
+  allowed:
+    - defaults
+    - node
+  firewall: true
+---
+
+# Triage
+`), 0644))
+
+	err := RunNetworkSuggest(NetworkSuggestConfig{WorkflowName: workflowPath, OutputDir: logsDir, Write: true, JSONOutput: true})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(workflowPath)
+	require.NoError(t, err)
+	assert.Equal(t, `---
+on: issues
+network:
+  allowed:
+    - defaults
+    - python
+  firewall: true
+---
+
+# Triage
+`, string(content))
+
+	err = RunNetworkSuggest(NetworkSuggestConfig{WorkflowName: workflowPath, OutputDir: filepath.Join(tmpDir, "empty")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no downloaded runs")
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var patchRejectionsLog = logger.New("cli:patch_rejections")
+
+// patchRejectionPattern matches the log line the safe output job writes for every path that
+// create-pull-request or push-to-pull-request-branch rejected because of path restrictions
+var patchRejectionPattern = regexp.MustCompile(`Patch path rejected for ([a-z_]+): (.+) \(([^()]*)\)\s*$`)
+
+// PatchRejectionReport is a path of an agent patch rejected by allowed-paths, blocked-paths or
+// the protected paths
+type PatchRejectionReport struct {
+	SafeOutput string `json:"safe_output"`
+	Path       string `json:"path"`
+	Reason     string `json:"reason"`
+}
+
+// extractPatchRejectionsFromRun scans the job logs of a run for rejected patch paths
+func extractPatchRejectionsFromRun(runDir string) ([]PatchRejectionReport, error) {
+	workflowLogsDir := filepath.Join(runDir, "workflow-logs")
+	if _, err := os.Stat(workflowLogsDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var rejections []PatchRejectionReport
+	seen := make(map[PatchRejectionReport]bool)
+	err := filepath.Walk(workflowLogsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".txt") {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			patchRejectionsLog.Printf("Skipping %s: %v", path, err)
+			return nil
+		}
+		defer file.Close()
+
+		// Job logs repeat the step logs, so the same rejection can appear in several files
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			match := patchRejectionPattern.FindStringSubmatch(scanner.Text())
+			if match == nil {
+				continue
+			}
+			rejection := PatchRejectionReport{SafeOutput: match[1], Path: match[2], Reason: match[3]}
+			if !seen[rejection] {
+				seen[rejection] = true
+				rejections = append(rejections, rejection)
+			}
+		}
+		return scanner.Err()
+	})
+
+	patchRejectionsLog.Printf("Found %d rejected patch paths in %s", len(rejections), workflowLogsDir)
+	return rejections, err
+}
@@ -0,0 +1,50 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPatchRejectionsFromRun(t *testing.T) {
+	runDir := testutil.TempDir(t, "patch-rejections-*")
+
+	rejections, err := extractPatchRejectionsFromRun(runDir)
+	require.NoError(t, err)
+	assert.Empty(t, rejections, "runs without job logs have no rejections")
+
+	jobLog := `2026-10-16T10:00:00.0000000Z Patch size validation passed
+2026-10-16T10:00:00.1000000Z ##[warning]Patch path rejected for create_pull_request: .github/workflows/ci.yml (matches protected path .github/workflows/**)
+2026-10-16T10:00:00.2000000Z ##[warning]Patch path rejected for create_pull_request: src/main (copy).go (does not match allowed-paths)
+2026-10-16T10:00:00.3000000Z ##[error]✗ Message 1 (create_pull_request) failed: Patch modifies 2 path(s) that are not allowed
+`
+	stepDir := filepath.Join(runDir, "workflow-logs", "safe_outputs")
+	require.NoError(t, os.MkdirAll(stepDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "workflow-logs", "3_safe_outputs.txt"), []byte(jobLog), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stepDir, "5_Process Safe Outputs.txt"), []byte(jobLog), 0644))
+
+	rejections, err = extractPatchRejectionsFromRun(runDir)
+	require.NoError(t, err)
+	assert.Equal(t, []PatchRejectionReport{
+		{SafeOutput: "create_pull_request", Path: ".github/workflows/ci.yml", Reason: "matches protected path .github/workflows/**"},
+		{SafeOutput: "create_pull_request", Path: "src/main (copy).go", Reason: "does not match allowed-paths"},
+	}, rejections, "rejections repeated in job and step logs are reported once")
+
+	processedRun := ProcessedRun{Run: WorkflowRun{Conclusion: "success"}, PatchRejections: rejections}
+	findings := generateFindings(processedRun, MetricsData{}, nil, nil)
+	var titles []string
+	for _, finding := range findings {
+		titles = append(titles, finding.Title)
+	}
+	assert.Contains(t, titles, "Patch Rejected by Path Restrictions")
+
+	recommendations := generateRecommendations(processedRun, MetricsData{}, findings)
+	require.NotEmpty(t, recommendations)
+	assert.Equal(t, "Rejected .github/workflows/ci.yml: matches protected path .github/workflows/**", recommendations[0].Example)
+}
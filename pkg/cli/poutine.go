@@ -368,6 +368,7 @@ func parseAndDisplayPoutineOutput(stdout, targetFile string, verbose bool) (int,
 		}
 
 		fmt.Fprint(os.Stderr, console.FormatError(compilerErr))
+		recordPoutineSARIFFinding(finding, title, sarifLevel(errorType), lineNum)
 	}
 
 	return totalWarnings, nil
@@ -511,8 +512,25 @@ func parseAndDisplayPoutineOutputForDirectory(stdout string, verbose bool, gitRo
 			}
 
 			fmt.Fprint(os.Stderr, console.FormatError(compilerErr))
+			recordPoutineSARIFFinding(finding, title, sarifLevel(errorType), lineNum)
 		}
 	}
 
 	return totalWarnings, nil
 }
+
+// recordPoutineSARIFFinding records a poutine finding for SARIF export
+func recordPoutineSARIFFinding(finding poutineFinding, title string, level string, lineNum int) {
+	message := title
+	if finding.Meta.Details != "" {
+		message = fmt.Sprintf("%s - %s", title, finding.Meta.Details)
+	}
+	recordSARIFFinding(sarifFinding{
+		RuleID:      "poutine/" + finding.RuleID,
+		Description: title,
+		Level:       level,
+		Message:     message,
+		File:        finding.Meta.Path,
+		Line:        lineNum,
+	})
+}
@@ -0,0 +1,830 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/goccy/go-yaml"
+)
+
+var localRunLog = logger.New("cli:run_local")
+
+// Phases of a compiled workflow that the local runner knows how to execute
+const (
+	localPhaseActivation  = "activation"
+	localPhaseAgent       = "agent"
+	localPhaseDetection   = "detection"
+	localPhaseSafeOutputs = "safe-outputs"
+	localPhaseOther       = "other"
+)
+
+// Job statuses reported by the local runner
+const (
+	localJobCompleted = "completed"
+	localJobSkipped   = "skipped"
+	localJobFailed    = "failed"
+)
+
+// LocalRunConfig holds configuration for running a compiled workflow on the developer's machine
+type LocalRunConfig struct {
+	WorkflowID   string   // Workflow ID or path to the workflow markdown file
+	Transcript   string   // Path to a recorded safe output transcript (JSONL or agent_output.json)
+	AgentCommand string   // Local command that stands in for the agentic engine
+	OutputDir    string   // Directory for local run artifacts (default: .github/aw/logs/local/<workflow-id>)
+	Inputs       []string // workflow_dispatch inputs in key=value format
+	Verbose      bool
+}
+
+// LocalJobResult records the outcome of a single lock file job during a local run
+type LocalJobResult struct {
+	Name   string `json:"name"`
+	Phase  string `json:"phase"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LocalSafeOutput is a safe output item produced by the engine stand-in
+type LocalSafeOutput struct {
+	Type   string         `json:"type"`
+	Item   map[string]any `json:"item"`
+	Reason string         `json:"reason,omitempty"` // Rejection reason (empty for staged items)
+}
+
+// LocalRunResult summarizes a local workflow run
+type LocalRunResult struct {
+	WorkflowID string            `json:"workflow_id"`
+	LockFile   string            `json:"lock_file"`
+	OutputDir  string            `json:"output_dir"`
+	Engine     string            `json:"engine"`
+	Jobs       []LocalJobResult  `json:"jobs"`
+	Staged     []LocalSafeOutput `json:"staged,omitempty"`
+	Rejected   []LocalSafeOutput `json:"rejected,omitempty"`
+}
+
+// LocalAgentEnv describes the files shared between the local runner and an engine stand-in
+type LocalAgentEnv struct {
+	PromptPath      string // Rendered prompt for the agent
+	SafeOutputsPath string // JSONL file the agent appends safe output items to
+	WorkDir         string // Working directory for the agent
+	LogPath         string // Session log written by stand-ins that record one
+}
+
+// LocalEngineStandIn replaces the agentic engine when running a workflow locally.
+// Implementations produce the safe output items the agent would have emitted.
+type LocalEngineStandIn interface {
+	// Name returns a short description of the stand-in for reports
+	Name() string
+	// Execute runs the stand-in and returns the safe output items it produced
+	Execute(ctx context.Context, env LocalAgentEnv) ([]map[string]any, error)
+}
+
+// transcriptStandIn replays safe output items from a recorded transcript file
+type transcriptStandIn struct {
+	path string
+}
+
+func (s *transcriptStandIn) Name() string {
+	return "transcript: " + s.path
+}
+
+func (s *transcriptStandIn) Execute(ctx context.Context, env LocalAgentEnv) ([]map[string]any, error) {
+	localRunLog.Printf("Replaying transcript: %s", s.path)
+	return loadSafeOutputItems(s.path)
+}
+
+// commandStandIn runs a local command in place of the engine. The command receives the
+// same GH_AW_PROMPT and GH_AW_SAFE_OUTPUTS environment variables as the agent job.
+type commandStandIn struct {
+	command string
+}
+
+func (s *commandStandIn) Name() string {
+	return "command: " + s.command
+}
+
+func (s *commandStandIn) Execute(ctx context.Context, env LocalAgentEnv) ([]map[string]any, error) {
+	localRunLog.Printf("Running local agent command: %s", s.command)
+
+	// Start with an empty safe outputs file so the command can append to it
+	if err := os.WriteFile(env.SafeOutputsPath, nil, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create safe outputs file: %w", err)
+	}
+
+	// #nosec G204 -- the command is provided explicitly by the local user via --agent-command
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	cmd.Dir = env.WorkDir
+	cmd.Env = append(os.Environ(),
+		"GH_AW_PROMPT="+env.PromptPath,
+		"GH_AW_SAFE_OUTPUTS="+env.SafeOutputsPath,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("agent command failed: %w", err)
+	}
+
+	return loadSafeOutputItems(env.SafeOutputsPath)
+}
+
+// replayStandIn replays the fixture configured for the replay engine (engine.fixture)
+type replayStandIn struct {
+	fixturePath string
+}
+
+func (s *replayStandIn) Name() string {
+	return "replay: " + s.fixturePath
+}
+
+func (s *replayStandIn) Execute(ctx context.Context, env LocalAgentEnv) ([]map[string]any, error) {
+	localRunLog.Printf("Replaying fixture: %s", s.fixturePath)
+
+	fixture, err := workflow.LoadReplayFixture(s.fixturePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if env.LogPath != "" {
+		sessionLog, err := fixture.RenderSessionLog()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(env.LogPath, []byte(sessionLog), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write session log: %w", err)
+		}
+	}
+
+	return fixture.SafeOutputs, nil
+}
+
+// newLocalEngineStandIn selects the engine stand-in from the run configuration. Workflows
+// using the replay engine fall back to their recorded fixture when no stand-in is given; the
+// fixture is resolved against repoRoot the same way the compiler validates it.
+func newLocalEngineStandIn(config LocalRunConfig, engineConfig *workflow.EngineConfig, repoRoot, markdownPath string) (LocalEngineStandIn, error) {
+	switch {
+	case config.Transcript != "" && config.AgentCommand != "":
+		return nil, fmt.Errorf("--transcript and --agent-command cannot be used together")
+	case config.Transcript != "":
+		if _, err := os.Stat(config.Transcript); err != nil {
+			return nil, fmt.Errorf("transcript file not found: %s", config.Transcript)
+		}
+		return &transcriptStandIn{path: config.Transcript}, nil
+	case config.AgentCommand != "":
+		return &commandStandIn{command: config.AgentCommand}, nil
+	case engineConfig != nil && engineConfig.ID == string(constants.ReplayEngine) && engineConfig.Fixture != "":
+		fixturePath, err := workflow.ResolveReplayFixturePath(repoRoot, markdownPath, engineConfig.Fixture)
+		if err != nil {
+			return nil, err
+		}
+		return &replayStandIn{fixturePath: fixturePath}, nil
+	default:
+		return nil, fmt.Errorf("local runs require an engine stand-in: use --transcript <file>, --agent-command <command>, or a workflow with engine: replay")
+	}
+}
+
+// loadSafeOutputItems reads safe output items from either a JSONL file (one item per line,
+// as written to GH_AW_SAFE_OUTPUTS) or an agent_output.json file ({"items": [...]}).
+func loadSafeOutputItems(path string) ([]map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read safe outputs from %s: %w", path, err)
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	// agent_output.json format
+	var agentOutput struct {
+		Items []map[string]any `json:"items"`
+	}
+	if bytes.HasPrefix(trimmed, []byte("{")) && json.Unmarshal(trimmed, &agentOutput) == nil && agentOutput.Items != nil {
+		return agentOutput.Items, nil
+	}
+
+	// JSONL format
+	var items []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item map[string]any
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("invalid safe output item at %s:%d: %w", path, lineNumber, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read safe outputs from %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// lockFileJob is a job definition read from a compiled .lock.yml file
+type lockFileJob struct {
+	Name  string
+	Needs []string
+	Steps int
+}
+
+// parseLockFileJobs parses the jobs of a compiled lock file and returns them in execution
+// order (dependencies first, ties broken alphabetically for deterministic output).
+func parseLockFileJobs(content []byte) ([]lockFileJob, error) {
+	var lockYAML map[string]any
+	if err := yaml.Unmarshal(content, &lockYAML); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+
+	jobsMap, ok := lockYAML["jobs"].(map[string]any)
+	if !ok || len(jobsMap) == 0 {
+		return nil, fmt.Errorf("lock file does not define any jobs")
+	}
+
+	jobs := make(map[string]lockFileJob, len(jobsMap))
+	for name, raw := range jobsMap {
+		job := lockFileJob{Name: name}
+		if jobConfig, ok := raw.(map[string]any); ok {
+			switch needs := jobConfig["needs"].(type) {
+			case string:
+				job.Needs = []string{needs}
+			case []any:
+				for _, need := range needs {
+					if needStr, ok := need.(string); ok {
+						job.Needs = append(job.Needs, needStr)
+					}
+				}
+			}
+			if steps, ok := jobConfig["steps"].([]any); ok {
+				job.Steps = len(steps)
+			}
+		}
+		jobs[name] = job
+	}
+
+	// Kahn's algorithm with alphabetical tie-breaking
+	inDegree := make(map[string]int, len(jobs))
+	dependents := make(map[string][]string, len(jobs))
+	for name := range jobs {
+		inDegree[name] = 0
+	}
+	for name, job := range jobs {
+		for _, need := range job.Needs {
+			if _, exists := jobs[need]; !exists {
+				return nil, fmt.Errorf("job '%s' needs unknown job '%s'", name, need)
+			}
+			inDegree[name]++
+			dependents[need] = append(dependents[need], name)
+		}
+	}
+
+	var ready []string
+	for name, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	ordered := make([]lockFileJob, 0, len(jobs))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, jobs[name])
+		for _, dependent := range dependents[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) != len(jobs) {
+		return nil, fmt.Errorf("lock file jobs contain a dependency cycle")
+	}
+
+	localRunLog.Printf("Parsed %d jobs from lock file", len(ordered))
+	return ordered, nil
+}
+
+// classifyLocalJob maps a lock file job to the phase the local runner executes it in
+func classifyLocalJob(jobName string, data *workflow.WorkflowData) string {
+	switch jobName {
+	case string(constants.PreActivationJobName), string(constants.ActivationJobName):
+		return localPhaseActivation
+	case string(constants.AgentJobName):
+		return localPhaseAgent
+	case string(constants.DetectionJobName):
+		return localPhaseDetection
+	case "safe_outputs":
+		return localPhaseSafeOutputs
+	}
+
+	if data != nil && data.SafeOutputs != nil {
+		for _, toolName := range workflow.GetEnabledSafeOutputToolNames(data.SafeOutputs) {
+			if stringutil.NormalizeSafeOutputIdentifier(toolName) == jobName {
+				return localPhaseSafeOutputs
+			}
+		}
+	}
+
+	return localPhaseOther
+}
+
+// inputExpressionPattern matches ${{ inputs.NAME }} and ${{ github.event.inputs.NAME }}
+var inputExpressionPattern = regexp.MustCompile(`\$\{\{\s*(?:github\.event\.)?inputs\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// renderLocalPrompt substitutes workflow_dispatch inputs into the workflow prompt.
+// Other expressions are left as-is since there is no GitHub event context locally.
+func renderLocalPrompt(markdown string, inputs []string) string {
+	values := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		parts := strings.SplitN(input, "=", 2)
+		if len(parts) == 2 {
+			values[parts[0]] = parts[1]
+		}
+	}
+
+	return inputExpressionPattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		name := inputExpressionPattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// stageLocalSafeOutputs validates safe output items against the workflow's safe-outputs
+// configuration, the same way the safe output jobs do, and splits them into staged and
+// rejected items.
+func stageLocalSafeOutputs(safeOutputs *workflow.SafeOutputsConfig, items []map[string]any) (staged []LocalSafeOutput, rejected []LocalSafeOutput) {
+	enabled := make(map[string]bool)
+	for _, toolName := range workflow.GetEnabledSafeOutputToolNames(safeOutputs) {
+		enabled[stringutil.NormalizeSafeOutputIdentifier(toolName)] = true
+	}
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		itemType, _ := item["type"].(string)
+		itemType = stringutil.NormalizeSafeOutputIdentifier(itemType)
+		output := LocalSafeOutput{Type: itemType, Item: item}
+
+		if itemType == "" {
+			output.Reason = "missing 'type' field"
+			rejected = append(rejected, output)
+			continue
+		}
+
+		if !enabled[itemType] {
+			output.Reason = fmt.Sprintf("safe output type '%s' is not enabled in safe-outputs", itemType)
+			rejected = append(rejected, output)
+			continue
+		}
+
+		if missing := missingRequiredSafeOutputFields(itemType, item); len(missing) > 0 {
+			output.Reason = fmt.Sprintf("missing required field(s): %s", strings.Join(missing, ", "))
+			rejected = append(rejected, output)
+			continue
+		}
+
+		counts[itemType]++
+		if maxItems, ok := workflow.GetSafeOutputMax(safeOutputs, itemType); ok && maxItems > 0 && counts[itemType] > maxItems {
+			output.Reason = fmt.Sprintf("exceeds max of %d for %s", maxItems, itemType)
+			rejected = append(rejected, output)
+			continue
+		}
+
+		staged = append(staged, output)
+	}
+
+	localRunLog.Printf("Staged %d safe output item(s), rejected %d", len(staged), len(rejected))
+	return staged, rejected
+}
+
+// missingRequiredSafeOutputFields returns the required fields of a safe output type that are absent from the item
+func missingRequiredSafeOutputFields(itemType string, item map[string]any) []string {
+	validation, ok := workflow.GetValidationConfigForType(itemType)
+	if !ok {
+		return nil
+	}
+
+	var missing []string
+	for field, rules := range validation.Fields {
+		if !rules.Required {
+			continue
+		}
+		if value, exists := item[field]; !exists || value == nil || value == "" {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// renderLocalStagedPreview renders the staged preview for all items of one safe output type,
+// using the workflow's staged-title and staged-description message templates.
+func renderLocalStagedPreview(messages *workflow.SafeOutputMessagesConfig, itemType string, items []LocalSafeOutput) string {
+	operation := workflow.SafeOutputOperationName(itemType)
+
+	var sb strings.Builder
+	sb.WriteString(workflow.RenderStagedTitle(messages, operation))
+	sb.WriteString("\n\n")
+	sb.WriteString(workflow.RenderStagedDescription(messages, operation))
+	sb.WriteString("\n\n")
+
+	for i, output := range items {
+		fmt.Fprintf(&sb, "### %s %d\n\n", operation, i+1)
+		if title, ok := output.Item["title"].(string); ok && title != "" {
+			fmt.Fprintf(&sb, "**Title:** %s\n\n", title)
+		}
+		if body, ok := output.Item["body"].(string); ok && body != "" {
+			fmt.Fprintf(&sb, "**Body:**\n%s\n\n", body)
+		}
+
+		keys := make([]string, 0, len(output.Item))
+		for key := range output.Item {
+			if key == "type" || key == "title" || key == "body" {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, "**%s:** %s\n\n", key, formatLocalFieldValue(output.Item[key]))
+		}
+		sb.WriteString("---\n\n")
+	}
+
+	return sb.String()
+}
+
+// formatLocalFieldValue formats a safe output field value for a staged preview
+func formatLocalFieldValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, element := range v {
+			parts = append(parts, formatLocalFieldValue(element))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
+
+// writeLocalStagedOutputs writes one staged preview per safe output type plus the raw items
+func writeLocalStagedOutputs(stagedDir string, messages *workflow.SafeOutputMessagesConfig, staged []LocalSafeOutput) error {
+	if err := os.MkdirAll(stagedDir, 0755); err != nil {
+		return fmt.Errorf("failed to create staged directory: %w", err)
+	}
+
+	byType := make(map[string][]LocalSafeOutput)
+	var types []string
+	for _, output := range staged {
+		if _, seen := byType[output.Type]; !seen {
+			types = append(types, output.Type)
+		}
+		byType[output.Type] = append(byType[output.Type], output)
+	}
+
+	for _, itemType := range types {
+		previewPath := filepath.Join(stagedDir, itemType+".md")
+		preview := renderLocalStagedPreview(messages, itemType, byType[itemType])
+		if err := os.WriteFile(previewPath, []byte(preview), 0644); err != nil {
+			return fmt.Errorf("failed to write staged preview %s: %w", previewPath, err)
+		}
+		localRunLog.Printf("Wrote staged preview: %s (%d items)", previewPath, len(byType[itemType]))
+	}
+
+	return nil
+}
+
+// writeJSONFile writes a value as indented JSON
+func writeJSONFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// localRunMarkerFile marks an output directory created by a local run, so that later runs may clean it
+const localRunMarkerFile = ".gh-aw-local-run"
+
+// prepareLocalOutputDir creates an empty output directory for a local run. An existing directory
+// is only cleaned when it is empty or was created by a previous local run; any other directory is
+// refused so that a mistyped --output-dir (such as ".") never deletes user files.
+func prepareLocalOutputDir(outputDir string) error {
+	entries, err := os.ReadDir(outputDir)
+	switch {
+	case os.IsNotExist(err):
+		// Nothing to clean
+	case err != nil:
+		return fmt.Errorf("failed to read output directory: %w", err)
+	case len(entries) > 0:
+		if _, err := os.Stat(filepath.Join(outputDir, localRunMarkerFile)); err != nil {
+			return fmt.Errorf("output directory %s is not empty and was not created by a local run: choose an empty or new directory", outputDir)
+		}
+		localRunLog.Printf("Cleaning previous local run output: %s", outputDir)
+		if err := os.RemoveAll(outputDir); err != nil {
+			return fmt.Errorf("failed to clean output directory: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, localRunMarkerFile), []byte("Created by gh aw run --local; this directory is replaced on the next local run.\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write output directory marker: %w", err)
+	}
+	return nil
+}
+
+// RunWorkflowLocally executes a compiled workflow on the developer's machine. It reads the
+// jobs from the workflow's .lock.yml and runs the activation, agent and safe output phases
+// in order. The engine is replaced by a stand-in (recorded transcript, local command or replay fixture) and
+// safe outputs are written as staged previews to a local directory instead of calling the
+// GitHub API.
+func RunWorkflowLocally(ctx context.Context, config LocalRunConfig) (*LocalRunResult, error) {
+	localRunLog.Printf("Starting local run: workflow=%s, transcript=%s, command=%s", config.WorkflowID, config.Transcript, config.AgentCommand)
+
+	for _, input := range config.Inputs {
+		parts := strings.SplitN(input, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid input format '%s': expected key=value", input)
+		}
+	}
+
+	markdownPath, err := resolveWorkflowFile(config.WorkflowID, config.Verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWorkflowInputs(markdownPath, config.Inputs); err != nil {
+		return nil, err
+	}
+
+	lockFilePath := stringutil.MarkdownToLockFile(markdownPath)
+	lockContent, err := os.ReadFile(lockFilePath)
+	if err != nil {
+		workflowID := normalizeWorkflowID(markdownPath)
+		return nil, fmt.Errorf("%s", console.FormatErrorWithSuggestions(
+			fmt.Sprintf("workflow lock file '%s' not found", filepath.Base(lockFilePath)),
+			[]string{fmt.Sprintf("Run '%s compile %s' to compile this workflow", string(constants.CLIExtensionPrefix), workflowID)},
+		))
+	}
+
+	jobs, err := parseLockFileJobs(lockContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs from %s: %w", filepath.Base(lockFilePath), err)
+	}
+
+	// Parse the workflow source to get the prompt and safe-outputs configuration
+	compiler := workflow.NewCompiler(
+		workflow.WithVerbose(config.Verbose),
+		workflow.WithNoEmit(true),
+	)
+	workflowData, err := compiler.ParseWorkflowFile(markdownPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	workDir, err := findGitRoot()
+	if err != nil {
+		workDir = "."
+	}
+
+	// Replay fixtures are relative to the root of the repository that contains the workflow
+	repoRoot, err := findGitRootForPath(markdownPath)
+	if err != nil {
+		repoRoot = ""
+	}
+	standIn, err := newLocalEngineStandIn(config, workflowData.EngineConfig, repoRoot, markdownPath)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowID := normalizeWorkflowID(markdownPath)
+	outputDir := config.OutputDir
+	if outputDir == "" {
+		if err := ensureLogsGitignore(); err != nil {
+			localRunLog.Printf("Failed to ensure logs .gitignore: %v", err)
+		}
+		outputDir = filepath.Join(defaultLogsOutputDir, "local", workflowID)
+	}
+	if err := prepareLocalOutputDir(outputDir); err != nil {
+		return nil, err
+	}
+
+	result := &LocalRunResult{
+		WorkflowID: workflowID,
+		LockFile:   lockFilePath,
+		OutputDir:  outputDir,
+		Engine:     standIn.Name(),
+	}
+
+	fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Running %s locally (%s)", filepath.Base(lockFilePath), standIn.Name())))
+
+	promptPath := filepath.Join(outputDir, "prompt.md")
+	safeOutputsPath := filepath.Join(outputDir, "safe_output.jsonl")
+	var items []map[string]any
+	promptRendered := false
+	agentRan := false
+	safeOutputsStaged := false
+	var runErr error
+
+	for _, job := range jobs {
+		phase := classifyLocalJob(job.Name, workflowData)
+		jobResult := LocalJobResult{Name: job.Name, Phase: phase}
+
+		if config.Verbose {
+			fmt.Fprintln(os.Stderr, console.FormatProgressMessage(fmt.Sprintf("Job %s (%s, %d steps)", job.Name, phase, job.Steps)))
+		}
+
+		if runErr != nil && phase != localPhaseOther {
+			jobResult.Status = localJobSkipped
+			jobResult.Reason = "a previous job failed"
+			result.Jobs = append(result.Jobs, jobResult)
+			continue
+		}
+
+		switch phase {
+		case localPhaseActivation:
+			if !promptRendered {
+				prompt := renderLocalPrompt(workflowData.MarkdownContent, config.Inputs)
+				if err := os.WriteFile(promptPath, []byte(prompt), 0644); err != nil {
+					runErr = fmt.Errorf("failed to write prompt: %w", err)
+					jobResult.Status = localJobFailed
+					jobResult.Reason = runErr.Error()
+					break
+				}
+				promptRendered = true
+				jobResult.Reason = "prompt rendered to " + filepath.Base(promptPath)
+			} else {
+				jobResult.Reason = "permission and trigger checks are not evaluated locally"
+			}
+			jobResult.Status = localJobCompleted
+
+		case localPhaseAgent:
+			env := LocalAgentEnv{
+				PromptPath:      promptPath,
+				SafeOutputsPath: safeOutputsPath,
+				WorkDir:         workDir,
+				LogPath:         filepath.Join(outputDir, "agent-stdio.log"),
+			}
+			agentItems, err := standIn.Execute(ctx, env)
+			if err != nil {
+				runErr = err
+				jobResult.Status = localJobFailed
+				jobResult.Reason = err.Error()
+				break
+			}
+			items = agentItems
+			agentRan = true
+			if err := writeSafeOutputsJSONL(safeOutputsPath, items); err != nil {
+				runErr = err
+				jobResult.Status = localJobFailed
+				jobResult.Reason = err.Error()
+				break
+			}
+			if err := writeJSONFile(filepath.Join(outputDir, constants.AgentOutputFilename), map[string]any{"items": items, "errors": []string{}}); err != nil {
+				runErr = err
+				jobResult.Status = localJobFailed
+				jobResult.Reason = err.Error()
+				break
+			}
+			jobResult.Status = localJobCompleted
+			jobResult.Reason = fmt.Sprintf("%d safe output item(s) produced", len(items))
+
+		case localPhaseSafeOutputs:
+			if !agentRan {
+				jobResult.Status = localJobSkipped
+				jobResult.Reason = "agent job did not run"
+				break
+			}
+			if !safeOutputsStaged {
+				result.Staged, result.Rejected = stageLocalSafeOutputs(workflowData.SafeOutputs, items)
+				var messages *workflow.SafeOutputMessagesConfig
+				if workflowData.SafeOutputs != nil {
+					messages = workflowData.SafeOutputs.Messages
+				}
+				if err := writeLocalStagedOutputs(filepath.Join(outputDir, "staged"), messages, result.Staged); err != nil {
+					runErr = err
+					jobResult.Status = localJobFailed
+					jobResult.Reason = err.Error()
+					break
+				}
+				safeOutputsStaged = true
+				jobResult.Reason = fmt.Sprintf("%d staged, %d rejected", len(result.Staged), len(result.Rejected))
+			} else {
+				jobResult.Reason = "staged together with earlier safe output jobs"
+			}
+			jobResult.Status = localJobCompleted
+
+		case localPhaseDetection:
+			jobResult.Status = localJobSkipped
+			jobResult.Reason = "threat detection is not run locally"
+
+		default:
+			jobResult.Status = localJobSkipped
+			jobResult.Reason = "not supported in local mode"
+		}
+
+		result.Jobs = append(result.Jobs, jobResult)
+	}
+
+	if err := writeJSONFile(filepath.Join(outputDir, "local_run.json"), result); err != nil {
+		return result, err
+	}
+
+	return result, runErr
+}
+
+// writeSafeOutputsJSONL writes safe output items as JSONL
+func writeSafeOutputsJSONL(path string, items []map[string]any) error {
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode safe output item: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// renderLocalRunResult prints the outcome of a local run
+func renderLocalRunResult(result *LocalRunResult) {
+	rows := make([][]string, 0, len(result.Jobs))
+	for _, job := range result.Jobs {
+		rows = append(rows, []string{job.Name, job.Phase, job.Status, job.Reason})
+	}
+	fmt.Fprint(os.Stderr, console.RenderTable(console.TableConfig{
+		Title:   "Local Run: " + result.WorkflowID,
+		Headers: []string{"Job", "Phase", "Status", "Details"},
+		Rows:    rows,
+	}))
+
+	for _, rejected := range result.Rejected {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Rejected %s: %s", rejected.Type, rejected.Reason)))
+	}
+
+	fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("Staged outputs written to %s", result.OutputDir)))
+}
+
+// RunWorkflowsLocally runs each workflow locally in turn
+func RunWorkflowsLocally(ctx context.Context, workflowNames []string, config LocalRunConfig) error {
+	if len(workflowNames) == 0 {
+		return fmt.Errorf("at least one workflow name or ID is required for local runs")
+	}
+	if len(workflowNames) > 1 && config.OutputDir != "" {
+		return fmt.Errorf("--output-dir can only be used when running a single workflow locally")
+	}
+
+	for _, workflowName := range workflowNames {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, console.FormatWarningMessage("Operation cancelled"))
+			return ctx.Err()
+		default:
+		}
+
+		runConfig := config
+		runConfig.WorkflowID = workflowName
+		result, err := RunWorkflowLocally(ctx, runConfig)
+		if result != nil {
+			renderLocalRunResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("local run of workflow '%s' failed: %w", workflowName, err)
+		}
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage(fmt.Sprintf("Local run completed: %s", workflowName)))
+	}
+
+	return nil
+}
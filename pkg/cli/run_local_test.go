@@ -0,0 +1,323 @@
+//go:build !integration
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLockFileJobsOrder(t *testing.T) {
+	content := []byte(`
+jobs:
+  safe_outputs:
+    needs: [agent, detection]
+    steps:
+      - run: echo safe
+  agent:
+    needs: activation
+    steps:
+      - run: echo agent
+      - run: echo again
+  detection:
+    needs: agent
+  activation:
+    needs: pre_activation
+  pre_activation:
+    steps: []
+  conclusion:
+    needs:
+      - agent
+      - safe_outputs
+`)
+
+	jobs, err := parseLockFileJobs(content)
+	require.NoError(t, err, "lock file jobs should parse")
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	assert.Equal(t, []string{"pre_activation", "activation", "agent", "detection", "safe_outputs", "conclusion"}, names, "jobs should be in dependency order")
+	assert.Equal(t, 2, jobs[2].Steps, "agent job should have two steps")
+}
+
+func TestParseLockFileJobsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "no jobs", content: "name: test\n", errMsg: "does not define any jobs"},
+		{name: "unknown dependency", content: "jobs:\n  agent:\n    needs: missing\n", errMsg: "unknown job 'missing'"},
+		{name: "cycle", content: "jobs:\n  a:\n    needs: b\n  b:\n    needs: a\n", errMsg: "dependency cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseLockFileJobs([]byte(tt.content))
+			require.Error(t, err, "parse should fail")
+			assert.Contains(t, err.Error(), tt.errMsg, "error should explain the problem")
+		})
+	}
+}
+
+func TestLoadSafeOutputItems(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	jsonlPath := filepath.Join(tmpDir, "outputs.jsonl")
+	require.NoError(t, os.WriteFile(jsonlPath, []byte(`{"type":"create_issue","title":"A","body":"B"}
+
+{"type":"noop","message":"done"}
+`), 0644))
+	items, err := loadSafeOutputItems(jsonlPath)
+	require.NoError(t, err, "JSONL transcript should load")
+	require.Len(t, items, 2, "blank lines should be ignored")
+	assert.Equal(t, "create_issue", items[0]["type"])
+
+	agentOutputPath := filepath.Join(tmpDir, "agent_output.json")
+	require.NoError(t, os.WriteFile(agentOutputPath, []byte(`{"items":[{"type":"add_comment","body":"hi"}],"errors":[]}`), 0644))
+	items, err = loadSafeOutputItems(agentOutputPath)
+	require.NoError(t, err, "agent_output.json should load")
+	require.Len(t, items, 1)
+	assert.Equal(t, "add_comment", items[0]["type"])
+
+	invalidPath := filepath.Join(tmpDir, "invalid.jsonl")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("{\"type\":\"noop\"}\nnot json\n"), 0644))
+	_, err = loadSafeOutputItems(invalidPath)
+	require.Error(t, err, "invalid JSONL should fail")
+	assert.Contains(t, err.Error(), "invalid.jsonl:2", "error should include the line number")
+}
+
+func TestStageLocalSafeOutputs(t *testing.T) {
+	safeOutputs := &workflow.SafeOutputsConfig{
+		CreateIssues: &workflow.CreateIssuesConfig{BaseSafeOutputConfig: workflow.BaseSafeOutputConfig{Max: 1}},
+		NoOp:         &workflow.NoOpConfig{},
+	}
+
+	items := []map[string]any{
+		{"type": "create-issue", "title": "First", "body": "Body"},
+		{"type": "create_issue", "title": "Second", "body": "Body"},
+		{"type": "create_issue", "title": "No body"},
+		{"type": "add_comment", "body": "Not enabled"},
+		{"body": "No type"},
+		{"type": "noop", "message": "done"},
+	}
+
+	staged, rejected := stageLocalSafeOutputs(safeOutputs, items)
+
+	require.Len(t, staged, 2, "first issue and noop should be staged")
+	assert.Equal(t, "create_issue", staged[0].Type, "dashes should be normalized")
+	assert.Equal(t, "noop", staged[1].Type)
+
+	require.Len(t, rejected, 4)
+	assert.Contains(t, rejected[0].Reason, "exceeds max of 1")
+	assert.Contains(t, rejected[1].Reason, "missing required field(s): body")
+	assert.Contains(t, rejected[2].Reason, "not enabled")
+	assert.Contains(t, rejected[3].Reason, "missing 'type'")
+}
+
+func TestRenderLocalStagedPreview(t *testing.T) {
+	items := []LocalSafeOutput{
+		{Type: "create_issue", Item: map[string]any{"type": "create_issue", "title": "Bug", "body": "Details", "labels": []any{"bug", "triage"}}},
+	}
+
+	t.Run("default templates", func(t *testing.T) {
+		preview := renderLocalStagedPreview(nil, "create_issue", items)
+		assert.Contains(t, preview, "## 🔍 Preview: Create Issue")
+		assert.Contains(t, preview, "would be performed if staged mode was disabled")
+		assert.Contains(t, preview, "**Title:** Bug")
+		assert.Contains(t, preview, "**labels:** bug, triage")
+	})
+
+	t.Run("custom templates", func(t *testing.T) {
+		messages := &workflow.SafeOutputMessagesConfig{
+			StagedTitle:       "# Local {operation}",
+			StagedDescription: "Would run {operation}",
+		}
+		preview := renderLocalStagedPreview(messages, "create_issue", items)
+		assert.True(t, strings.HasPrefix(preview, "# Local Create Issue\n\nWould run Create Issue"), "custom templates should be used, got %q", preview)
+	})
+}
+
+func TestRenderLocalPrompt(t *testing.T) {
+	prompt := renderLocalPrompt("Analyze ${{ inputs.topic }} and ${{ github.event.inputs.depth }} for ${{ github.repository }}", []string{"topic=security", "depth=3"})
+	assert.Equal(t, "Analyze security and 3 for ${{ github.repository }}", prompt, "only inputs should be substituted")
+}
+
+func TestNewLocalEngineStandIn(t *testing.T) {
+	_, err := newLocalEngineStandIn(LocalRunConfig{}, nil, ".", "")
+	require.Error(t, err, "a stand-in is required")
+
+	_, err = newLocalEngineStandIn(LocalRunConfig{Transcript: "a", AgentCommand: "b"}, nil, ".", "")
+	require.Error(t, err, "transcript and command are mutually exclusive")
+
+	standIn, err := newLocalEngineStandIn(LocalRunConfig{AgentCommand: "true"}, nil, ".", "")
+	require.NoError(t, err)
+	assert.Equal(t, "command: true", standIn.Name())
+
+	replayConfig := &workflow.EngineConfig{ID: "replay", Fixture: "fixtures/session.json"}
+	standIn, err = newLocalEngineStandIn(LocalRunConfig{}, replayConfig, "/repo", "/repo/.github/workflows/triage.md")
+	require.NoError(t, err, "replay workflows should use their fixture")
+	assert.Equal(t, "replay: /repo/fixtures/session.json", standIn.Name())
+
+	standIn, err = newLocalEngineStandIn(LocalRunConfig{AgentCommand: "true"}, replayConfig, "/repo", "/repo/.github/workflows/triage.md")
+	require.NoError(t, err)
+	assert.Equal(t, "command: true", standIn.Name(), "explicit stand-ins take precedence over the fixture")
+
+	_, err = newLocalEngineStandIn(LocalRunConfig{}, &workflow.EngineConfig{ID: "replay", Fixture: "../outside.json"}, "/repo", "/repo/.github/workflows/triage.md")
+	require.Error(t, err, "fixtures outside the repository should be rejected")
+	assert.Contains(t, err.Error(), "must be a path relative to the repository root")
+}
+
+func TestPrepareLocalOutputDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	outputDir := filepath.Join(tmpDir, "new")
+	require.NoError(t, prepareLocalOutputDir(outputDir), "a new directory should be created")
+	require.FileExists(t, filepath.Join(outputDir, localRunMarkerFile))
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "prompt.md"), []byte("old"), 0644))
+	require.NoError(t, prepareLocalOutputDir(outputDir), "a directory from a previous local run should be cleaned")
+	assert.NoFileExists(t, filepath.Join(outputDir, "prompt.md"))
+
+	userDir := filepath.Join(tmpDir, "user")
+	require.NoError(t, os.MkdirAll(userDir, 0755))
+	userFile := filepath.Join(userDir, "notes.md")
+	require.NoError(t, os.WriteFile(userFile, []byte("keep"), 0644))
+	err := prepareLocalOutputDir(userDir)
+	require.Error(t, err, "a non-empty directory not created by a local run should be refused")
+	assert.Contains(t, err.Error(), "not created by a local run")
+	assert.FileExists(t, userFile, "user files must not be deleted")
+
+	emptyDir := filepath.Join(tmpDir, "empty")
+	require.NoError(t, os.MkdirAll(emptyDir, 0755))
+	require.NoError(t, prepareLocalOutputDir(emptyDir), "an empty directory should be used")
+}
+
+func TestRunWorkflowLocally(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+
+	workflowPath := filepath.Join(workflowsDir, "local-test.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  workflow_dispatch:
+permissions:
+  contents: read
+engine: copilot
+safe-outputs:
+  create-issue:
+    max: 1
+---
+
+# Local Test
+
+Investigate the repository.
+`), 0644))
+
+	compiler := workflow.NewCompiler(workflow.WithGitRoot(tmpDir))
+	require.NoError(t, compiler.CompileWorkflow(workflowPath), "workflow should compile")
+
+	transcriptPath := filepath.Join(tmpDir, "transcript.jsonl")
+	require.NoError(t, os.WriteFile(transcriptPath, []byte(`{"type":"create_issue","title":"Found a bug","body":"Details"}
+{"type":"create_issue","title":"Second bug","body":"More details"}
+`), 0644))
+
+	outputDir := filepath.Join(tmpDir, "out")
+	result, err := RunWorkflowLocally(context.Background(), LocalRunConfig{
+		WorkflowID: workflowPath,
+		Transcript: transcriptPath,
+		OutputDir:  outputDir,
+	})
+	require.NoError(t, err, "local run should succeed")
+
+	statuses := make(map[string]string)
+	for _, job := range result.Jobs {
+		statuses[job.Name] = job.Status
+	}
+	assert.Equal(t, localJobCompleted, statuses["activation"], "activation should run")
+	assert.Equal(t, localJobCompleted, statuses["agent"], "agent should run")
+	assert.Equal(t, localJobCompleted, statuses["safe_outputs"], "safe outputs should be staged")
+
+	require.Len(t, result.Staged, 1, "max should limit staged issues")
+	require.Len(t, result.Rejected, 1, "second issue should be rejected")
+
+	prompt, err := os.ReadFile(filepath.Join(outputDir, "prompt.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(prompt), "Investigate the repository.")
+
+	preview, err := os.ReadFile(filepath.Join(outputDir, "staged", "create_issue.md"))
+	require.NoError(t, err, "staged preview should be written")
+	assert.Contains(t, string(preview), "Found a bug")
+
+	summary, err := os.ReadFile(filepath.Join(outputDir, "local_run.json"))
+	require.NoError(t, err)
+	var decoded LocalRunResult
+	require.NoError(t, json.Unmarshal(summary, &decoded), "local_run.json should be valid JSON")
+	assert.Equal(t, "local-test", decoded.WorkflowID)
+}
+
+func TestRunWorkflowLocallyWithReplayEngine(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(filepath.Join(workflowsDir, "fixtures"), 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "fixtures", "triage.replay.json"), []byte(`{
+  "version": 1,
+  "tool_calls": [
+    {"server": "github", "name": "get_issue", "input": {"issue_number": 7}, "output": {"title": "Crash on start"}}
+  ],
+  "safe_outputs": [
+    {"type": "add_comment", "body": "Looks like a crash in startup"}
+  ],
+  "usage": {"input_tokens": 900, "output_tokens": 100, "turns": 2}
+}`), 0644))
+
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  workflow_dispatch:
+permissions:
+  contents: read
+engine:
+  id: replay
+  fixture: .github/workflows/fixtures/triage.replay.json
+safe-outputs:
+  add-comment:
+---
+
+# Triage
+
+Triage the issue.
+`), 0644))
+
+	compiler := workflow.NewCompiler(workflow.WithGitRoot(tmpDir))
+	require.NoError(t, compiler.CompileWorkflow(workflowPath), "replay workflow should compile")
+
+	outputDir := filepath.Join(tmpDir, "out")
+	result, err := RunWorkflowLocally(context.Background(), LocalRunConfig{
+		WorkflowID: workflowPath,
+		OutputDir:  outputDir,
+	})
+	require.NoError(t, err, "replay run should succeed without an explicit stand-in")
+
+	require.Len(t, result.Staged, 1, "recorded comment should be staged")
+	assert.Equal(t, "add_comment", result.Staged[0].Type)
+	assert.Equal(t, "Looks like a crash in startup", result.Staged[0].Item["body"])
+
+	sessionLog, err := os.ReadFile(filepath.Join(outputDir, "agent-stdio.log"))
+	require.NoError(t, err, "replay should write the session log")
+	metrics := workflow.NewReplayEngine().ParseLogMetrics(string(sessionLog), false)
+	assert.Equal(t, 1000, metrics.TokenUsage, "session log should be parseable like a real run")
+	assert.Equal(t, 2, metrics.Turns)
+}
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+var scheduleCommandLog = logger.New("cli:schedule_command")
+
+// Schedule views
+const (
+	scheduleViewHeatmap  = "heatmap"
+	scheduleViewCalendar = "calendar"
+)
+
+// maxScheduleDays bounds the simulated period
+const maxScheduleDays = 90
+
+// ScheduleConfig holds configuration for schedule command execution
+type ScheduleConfig struct {
+	Workflows  []string // Restrict to these workflow IDs (all scheduled workflows when empty)
+	Days       int
+	Timezone   string
+	Blackouts  []string
+	View       string
+	JSONOutput bool
+	Verbose    bool
+}
+
+// NewScheduleCommand creates the schedule command
+func NewScheduleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule [workflow]...",
+		Short: "Visualize and simulate the upcoming runs of scheduled workflows",
+		Long: `Visualize and simulate the upcoming runs of all scheduled workflows in the repository.
+
+Every workflow's on.schedule is resolved the same way compile resolves it, including
+natural-language schedules ("every 6h", "daily around 14:00") whose fuzzy times are
+scattered together with the other workflows of the directory. The upcoming runs are shown as a heatmap
+(runs per hour of each day) or as a calendar listing every run.
+
+The simulation flags:
+- Overlaps: runs of different workflows in the same 15-minute window
+- Blackouts: runs that fall into a window given with --blackout
+- Expiring: workflows whose stop-after deadline passes within the simulated period
+
+Blackout windows are evaluated in the display timezone and use the format
+"[DAYS] [HH:MM-HH:MM]", for example "sat,sun", "mon-fri 12:00-13:00" or "22:00-06:00".
+
+` + WorkflowIDExplanation + `
+
+Examples:
+  ` + string(constants.CLIExtensionPrefix) + ` schedule                                  # Heatmap of the next 7 days (UTC)
+  ` + string(constants.CLIExtensionPrefix) + ` schedule --days 14 --timezone Europe/Berlin
+  ` + string(constants.CLIExtensionPrefix) + ` schedule --view calendar daily-report     # List every run of one workflow
+  ` + string(constants.CLIExtensionPrefix) + ` schedule --blackout sat,sun --blackout "mon-fri 22:00-06:00"
+  ` + string(constants.CLIExtensionPrefix) + ` schedule --json                           # Output the simulation as JSON`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			days, _ := cmd.Flags().GetInt("days")
+			timezone, _ := cmd.Flags().GetString("timezone")
+			blackouts, _ := cmd.Flags().GetStringArray("blackout")
+			view, _ := cmd.Flags().GetString("view")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+
+			return RunSchedule(ScheduleConfig{
+				Workflows:  args,
+				Days:       days,
+				Timezone:   timezone,
+				Blackouts:  blackouts,
+				View:       view,
+				JSONOutput: jsonOutput,
+				Verbose:    verbose,
+			})
+		},
+	}
+
+	cmd.Flags().Int("days", 7, fmt.Sprintf("Number of days to simulate (1-%d)", maxScheduleDays))
+	cmd.Flags().String("timezone", "UTC", "IANA timezone used to display runs and evaluate blackout windows (e.g. Europe/Berlin)")
+	cmd.Flags().StringArray("blackout", nil, "Blackout window \"[DAYS] [HH:MM-HH:MM]\" in which runs are flagged (can be repeated)")
+	cmd.Flags().String("view", scheduleViewHeatmap, "Output view: heatmap or calendar")
+	addJSONFlag(cmd)
+
+	cmd.ValidArgsFunction = CompleteWorkflowNames
+
+	return cmd
+}
+
+// RunSchedule executes the schedule command with the given configuration
+func RunSchedule(config ScheduleConfig) error {
+	scheduleCommandLog.Printf("Running schedule: workflows=%v, days=%d, timezone=%s, view=%s", config.Workflows, config.Days, config.Timezone, config.View)
+
+	if config.Days < 1 || config.Days > maxScheduleDays {
+		return fmt.Errorf("invalid days value: %d. Must be between 1 and %d", config.Days, maxScheduleDays)
+	}
+	if config.View != scheduleViewHeatmap && config.View != scheduleViewCalendar {
+		return fmt.Errorf("invalid view %q. Must be %s or %s", config.View, scheduleViewHeatmap, scheduleViewCalendar)
+	}
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+	}
+	var blackouts []*blackoutWindow
+	for _, spec := range config.Blackouts {
+		blackout, err := parseBlackoutWindow(spec)
+		if err != nil {
+			return err
+		}
+		blackouts = append(blackouts, blackout)
+	}
+
+	gitRoot, err := findGitRoot()
+	if err != nil {
+		return errors.New("the schedule command must be run inside a git repository")
+	}
+	sources, err := workflow.LoadScheduleSources(gitRoot, filepath.Join(gitRoot, getWorkflowsDir()))
+	if err != nil {
+		return fmt.Errorf("failed to read workflows: %w", err)
+	}
+
+	// Resolve fuzzy schedules exactly like compile does
+	compiler := workflow.NewCompiler(workflow.WithRepositorySlug(getRepositorySlugFromRemote()))
+	if err := compiler.PlanRepositorySchedules(sources); err != nil && config.Verbose {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Repository-wide schedule scattering disabled: %v", err)))
+	}
+
+	filter := make([]string, 0, len(config.Workflows))
+	for _, name := range config.Workflows {
+		filter = append(filter, normalizeWorkflowID(name))
+	}
+	workflows := buildScheduledWorkflows(compiler, sources, func(identifier string) string {
+		return filepath.Join(gitRoot, stringutil.MarkdownToLockFile(filepath.FromSlash(identifier)))
+	}, filter)
+
+	simulation, err := simulateSchedules(workflows, time.Now().UTC().Truncate(time.Minute), config.Days, location, blackouts)
+	if err != nil {
+		return err
+	}
+
+	if config.JSONOutput {
+		jsonBytes, err := json.MarshalIndent(simulation, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	if len(workflows) == 0 {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage("No scheduled workflows found"))
+		return nil
+	}
+
+	if config.View == scheduleViewCalendar {
+		fmt.Fprint(os.Stderr, renderScheduleCalendar(simulation))
+	} else {
+		fmt.Fprint(os.Stderr, renderScheduleHeatmap(simulation))
+	}
+	displayScheduleFindings(simulation)
+	return nil
+}
+
+// heatmapGlyphs shade heatmap cells from no runs to the busiest hour
+var heatmapGlyphs = []string{"·", "░", "▒", "▓", "█"}
+
+// renderScheduleHeatmap renders the number of runs per hour of each simulated day
+func renderScheduleHeatmap(simulation *ScheduleSimulation) string {
+	location := simulation.From.Location()
+	firstDay := time.Date(simulation.From.Year(), simulation.From.Month(), simulation.From.Day(), 0, 0, 0, 0, location)
+
+	var days []time.Time
+	for day := firstDay; day.Before(simulation.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	counts := make([][24]int, len(days))
+	maxCount := 0
+	for _, run := range simulation.Runs {
+		runDay := time.Date(run.Time.Year(), run.Time.Month(), run.Time.Day(), 0, 0, 0, 0, location)
+		for i, day := range days {
+			if day.Equal(runDay) {
+				counts[i][run.Time.Hour()]++
+				maxCount = max(maxCount, counts[i][run.Time.Hour()])
+				break
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nScheduled runs per hour (%s, %s to %s)\n\n", simulation.Timezone, simulation.From.Format("2006-01-02 15:04"), simulation.To.Format("2006-01-02 15:04"))
+	sb.WriteString("          ")
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&sb, "%-6s", fmt.Sprintf("%02d", hour))
+	}
+	sb.WriteString("\n")
+	for i, day := range days {
+		fmt.Fprintf(&sb, "%-10s", day.Format("Mon 01-02"))
+		total := 0
+		for hour := range 24 {
+			count := counts[i][hour]
+			total += count
+			glyph := heatmapGlyphs[0]
+			if count > 0 {
+				level := (count*(len(heatmapGlyphs)-1) + maxCount - 1) / maxCount
+				glyph = heatmapGlyphs[level]
+			}
+			sb.WriteString(glyph + glyph)
+		}
+		fmt.Fprintf(&sb, "  %d\n", total)
+	}
+	fmt.Fprintf(&sb, "\nLegend: %s none  %s-%s busier (max %d runs per hour)\n\n", heatmapGlyphs[0], heatmapGlyphs[1], heatmapGlyphs[len(heatmapGlyphs)-1], maxCount)
+	return sb.String()
+}
+
+// renderScheduleCalendar lists every simulated run grouped by day
+func renderScheduleCalendar(simulation *ScheduleSimulation) string {
+	overlapping := make(map[int64]bool)
+	for _, overlap := range simulation.Overlaps {
+		overlapping[overlap.Window.Unix()] = true
+	}
+	windowSize := 15 * time.Minute
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nScheduled runs (%s, %s to %s)\n", simulation.Timezone, simulation.From.Format("2006-01-02 15:04"), simulation.To.Format("2006-01-02 15:04"))
+	currentDay := ""
+	for _, run := range simulation.Runs {
+		if day := run.Time.Format("Monday 2006-01-02"); day != currentDay {
+			currentDay = day
+			fmt.Fprintf(&sb, "\n%s\n", day)
+		}
+		var markers []string
+		if overlapping[run.Time.UTC().Truncate(windowSize).Unix()] {
+			markers = append(markers, "overlap")
+		}
+		if run.Blackout != "" {
+			markers = append(markers, "blackout: "+run.Blackout)
+		}
+		line := fmt.Sprintf("  %s  %-30s %s", run.Time.Format("15:04"), run.Workflow, run.Cron)
+		if len(markers) > 0 {
+			line += "  [" + strings.Join(markers, ", ") + "]"
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// displayScheduleFindings prints overlaps, blackout runs, and expiring stop-after deadlines
+func displayScheduleFindings(simulation *ScheduleSimulation) {
+	fmt.Fprintf(os.Stderr, "  Scheduled workflows: %d\n", len(simulation.Workflows))
+	fmt.Fprintf(os.Stderr, "  Simulated runs:      %d\n\n", len(simulation.Runs))
+
+	const maxListed = 10
+	if len(simulation.Overlaps) == 0 {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage("No overlapping runs"))
+	} else {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("%d 15-minute window(s) with runs of more than one workflow", len(simulation.Overlaps))))
+		for i, overlap := range simulation.Overlaps {
+			if i == maxListed {
+				fmt.Fprintf(os.Stderr, "    ... and %d more\n", len(simulation.Overlaps)-maxListed)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "    %s  %s\n", overlap.Window.Format("Mon 01-02 15:04"), strings.Join(overlap.Workflows, ", "))
+		}
+	}
+
+	if len(simulation.Blackouts) > 0 {
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("%d run(s) fall into blackout windows", len(simulation.Blackouts))))
+		for i, run := range simulation.Blackouts {
+			if i == maxListed {
+				fmt.Fprintf(os.Stderr, "    ... and %d more\n", len(simulation.Blackouts)-maxListed)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "    %s  %s (%s)\n", run.Time.Format("Mon 01-02 15:04"), run.Workflow, run.Blackout)
+		}
+	}
+
+	for _, expiry := range simulation.Expiring {
+		if expiry.Expired {
+			fmt.Fprintln(os.Stderr, console.FormatErrorMessage(fmt.Sprintf("%s: stop-after deadline passed on %s; scheduled runs are skipped", expiry.Workflow, expiry.StopTime.Format("2006-01-02 15:04"))))
+		} else {
+			fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("%s: stop-after deadline expires on %s", expiry.Workflow, expiry.StopTime.Format("2006-01-02 15:04"))))
+		}
+	}
+}
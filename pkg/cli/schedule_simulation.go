@@ -0,0 +1,252 @@
+// This file simulates the upcoming runs of all scheduled workflows for the schedule command.
+//
+// The simulation resolves every workflow's schedules the same way compile does (natural-language
+// schedules, fuzzy schedules with the per-directory scattering plan, and plain cron), expands
+// them into concrete run times for the next N days, and flags:
+//   - overlaps: runs of different workflows in the same 15-minute window
+//   - blackouts: runs that fall into a blackout window given with --blackout
+//   - expiring: workflows whose stop-after deadline passes within the simulated period
+
+package cli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/sliceutil"
+	"github.com/github/gh-aw/pkg/workflow"
+)
+
+var scheduleSimulationLog = logger.New("cli:schedule_simulation")
+
+// stopTimeLayout is the format of resolved stop-after times in lock files
+const stopTimeLayout = "2006-01-02 15:04:05"
+
+// ScheduledWorkflow is a workflow with at least one schedule
+type ScheduledWorkflow struct {
+	Workflow  string                      `json:"workflow"`
+	Schedules []workflow.ResolvedSchedule `json:"schedules"`
+	StopTime  *time.Time                  `json:"stop_time,omitempty"`
+}
+
+// ScheduledRun is one simulated run
+type ScheduledRun struct {
+	Workflow string    `json:"workflow"`
+	Cron     string    `json:"cron"`
+	Time     time.Time `json:"time"`
+	Blackout string    `json:"blackout,omitempty"` // Blackout window the run falls into
+}
+
+// ScheduleOverlap lists the workflows running in the same 15-minute window
+type ScheduleOverlap struct {
+	Window    time.Time `json:"window"`
+	Workflows []string  `json:"workflows"`
+}
+
+// ScheduleExpiry is a workflow whose stop-after deadline passes within the simulated period
+type ScheduleExpiry struct {
+	Workflow string    `json:"workflow"`
+	StopTime time.Time `json:"stop_time"`
+	Expired  bool      `json:"expired"` // The deadline has already passed
+}
+
+// ScheduleSimulation is the result of simulating the schedules of a repository
+type ScheduleSimulation struct {
+	Timezone  string              `json:"timezone"`
+	From      time.Time           `json:"from"`
+	To        time.Time           `json:"to"`
+	Workflows []ScheduledWorkflow `json:"workflows"`
+	Runs      []ScheduledRun      `json:"runs"`
+	Overlaps  []ScheduleOverlap   `json:"overlaps,omitempty"`
+	Blackouts []ScheduledRun      `json:"blackouts,omitempty"`
+	Expiring  []ScheduleExpiry    `json:"expiring,omitempty"`
+}
+
+// blackoutWindow is a recurring period in which scheduled runs should not happen
+type blackoutWindow struct {
+	label      string
+	days       map[time.Weekday]bool // nil means every day
+	start, end int                   // Minutes since midnight; end is exclusive and may be before start (wraps midnight)
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseBlackoutWindow parses "[DAYS] [HH:MM-HH:MM]", e.g. "sat,sun", "mon-fri 12:00-13:00", or
+// "22:00-06:00". Days are comma-separated weekday names or ranges.
+func parseBlackoutWindow(spec string) (*blackoutWindow, error) {
+	window := &blackoutWindow{label: strings.TrimSpace(spec), start: 0, end: 24 * 60}
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid blackout window %q: expected [DAYS] [HH:MM-HH:MM]", spec)
+	}
+
+	timeRange := fields[len(fields)-1]
+	if strings.Contains(timeRange, ":") {
+		startStr, endStr, ok := strings.Cut(timeRange, "-")
+		start, errStart := parseClockMinutes(startStr)
+		end, errEnd := parseClockMinutes(endStr)
+		if !ok || errStart != nil || errEnd != nil || start == end {
+			return nil, fmt.Errorf("invalid blackout time range %q: expected HH:MM-HH:MM", timeRange)
+		}
+		window.start, window.end = start, end
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields) == 1 {
+		window.days = make(map[time.Weekday]bool)
+		for _, part := range strings.Split(fields[0], ",") {
+			first, last, isRange := strings.Cut(part, "-")
+			from, ok := weekdayNames[first[:min(3, len(first))]]
+			if !ok {
+				return nil, fmt.Errorf("invalid weekday %q in blackout window %q", first, spec)
+			}
+			to := from
+			if isRange {
+				if to, ok = weekdayNames[last[:min(3, len(last))]]; !ok {
+					return nil, fmt.Errorf("invalid weekday %q in blackout window %q", last, spec)
+				}
+			}
+			for day := from; ; day = (day + 1) % 7 {
+				window.days[day] = true
+				if day == to {
+					break
+				}
+			}
+		}
+	}
+
+	return window, nil
+}
+
+// parseClockMinutes parses HH:MM into minutes since midnight (24:00 is allowed as end of day)
+func parseClockMinutes(value string) (int, error) {
+	hourStr, minuteStr, ok := strings.Cut(value, ":")
+	hour, errHour := strconv.Atoi(hourStr)
+	minute, errMinute := strconv.Atoi(minuteStr)
+	if !ok || errHour != nil || errMinute != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// contains reports whether a time (already in the display timezone) is inside the window.
+// For windows that wrap midnight, the part after midnight belongs to the previous day's window.
+func (w *blackoutWindow) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end && (w.days == nil || w.days[t.Weekday()])
+	}
+	if minute >= w.start {
+		return w.days == nil || w.days[t.Weekday()]
+	}
+	return minute < w.end && (w.days == nil || w.days[(t.Weekday()+6)%7])
+}
+
+// simulateSchedules expands the schedules of all workflows into runs in [from, from+days)
+func simulateSchedules(workflows []ScheduledWorkflow, from time.Time, days int, location *time.Location, blackouts []*blackoutWindow) (*ScheduleSimulation, error) {
+	to := from.AddDate(0, 0, days)
+	scheduleSimulationLog.Printf("Simulating %d scheduled workflows from %s for %d days in %s", len(workflows), from.Format(time.RFC3339), days, location)
+
+	simulation := &ScheduleSimulation{
+		Timezone:  location.String(),
+		From:      from.In(location),
+		To:        to.In(location),
+		Workflows: workflows,
+	}
+
+	for _, wf := range workflows {
+		if wf.StopTime != nil && wf.StopTime.Before(to) {
+			simulation.Expiring = append(simulation.Expiring, ScheduleExpiry{Workflow: wf.Workflow, StopTime: wf.StopTime.In(location), Expired: wf.StopTime.Before(from)})
+		}
+
+		for _, schedule := range wf.Schedules {
+			runTimes, err := parser.CronRunsBetween(schedule.Cron, from, to)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s: %w", wf.Workflow, err)
+			}
+			for _, runTime := range runTimes {
+				if wf.StopTime != nil && !runTime.Before(*wf.StopTime) {
+					break // The activation job skips runs after the deadline
+				}
+				run := ScheduledRun{Workflow: wf.Workflow, Cron: schedule.Cron, Time: runTime.In(location)}
+				for _, blackout := range blackouts {
+					if blackout.contains(run.Time) {
+						run.Blackout = blackout.label
+						simulation.Blackouts = append(simulation.Blackouts, run)
+						break
+					}
+				}
+				simulation.Runs = append(simulation.Runs, run)
+			}
+		}
+	}
+
+	sort.SliceStable(simulation.Runs, func(i, j int) bool {
+		if !simulation.Runs[i].Time.Equal(simulation.Runs[j].Time) {
+			return simulation.Runs[i].Time.Before(simulation.Runs[j].Time)
+		}
+		return simulation.Runs[i].Workflow < simulation.Runs[j].Workflow
+	})
+	sort.SliceStable(simulation.Blackouts, func(i, j int) bool { return simulation.Blackouts[i].Time.Before(simulation.Blackouts[j].Time) })
+
+	// Group runs of different workflows by 15-minute window
+	windowSize := time.Duration(parser.ScheduleBucketMinutes) * time.Minute
+	windows := make(map[int64][]string)
+	var windowOrder []int64
+	for _, run := range simulation.Runs {
+		key := run.Time.UTC().Truncate(windowSize).Unix()
+		if _, seen := windows[key]; !seen {
+			windowOrder = append(windowOrder, key)
+		}
+		if !sliceutil.Contains(windows[key], run.Workflow) {
+			windows[key] = append(windows[key], run.Workflow)
+		}
+	}
+	for _, key := range windowOrder {
+		if len(windows[key]) > 1 {
+			simulation.Overlaps = append(simulation.Overlaps, ScheduleOverlap{Window: time.Unix(key, 0).In(location), Workflows: windows[key]})
+		}
+	}
+
+	scheduleSimulationLog.Printf("Simulated %d runs: %d overlaps, %d blackout runs, %d expiring", len(simulation.Runs), len(simulation.Overlaps), len(simulation.Blackouts), len(simulation.Expiring))
+	return simulation, nil
+}
+
+// buildScheduledWorkflows resolves the schedules of the given workflow sources. The stop time of a
+// workflow is read from its lock file, where relative stop-after values are resolved.
+func buildScheduledWorkflows(compiler *workflow.Compiler, sources []workflow.ScheduleSource, lockFileFor func(identifier string) string, filter []string) []ScheduledWorkflow {
+	var workflows []ScheduledWorkflow
+	for _, source := range sources {
+		name := strings.TrimSuffix(path.Base(source.Identifier), ".md")
+		if len(filter) > 0 && !sliceutil.Contains(filter, name) {
+			continue
+		}
+
+		schedules := compiler.ResolveSchedules(source)
+		if len(schedules) == 0 {
+			continue
+		}
+
+		wf := ScheduledWorkflow{Workflow: name, Schedules: schedules}
+		if stopTime := workflow.ExtractStopTimeFromLockFile(lockFileFor(source.Identifier)); stopTime != "" {
+			if parsed, err := time.ParseInLocation(stopTimeLayout, stopTime, time.UTC); err == nil {
+				wf.StopTime = &parsed
+			} else {
+				scheduleSimulationLog.Printf("Ignoring unparseable stop time %q for %s: %v", stopTime, name, err)
+			}
+		}
+		workflows = append(workflows, wf)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].Workflow < workflows[j].Workflow })
+	return workflows
+}
@@ -0,0 +1,170 @@
+//go:build !integration
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlackoutWindow(t *testing.T) {
+	// Saturday 2026-03-07
+	saturday := func(hour, minute int) time.Time { return time.Date(2026, 3, 7, hour, minute, 0, 0, time.UTC) }
+	monday := func(hour, minute int) time.Time { return time.Date(2026, 3, 9, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		spec    string
+		inside  []time.Time
+		outside []time.Time
+	}{
+		{spec: "sat,sun", inside: []time.Time{saturday(0, 0), saturday(23, 59)}, outside: []time.Time{monday(12, 0)}},
+		{spec: "mon-fri 12:00-13:00", inside: []time.Time{monday(12, 0), monday(12, 59)}, outside: []time.Time{monday(13, 0), saturday(12, 30)}},
+		{spec: "22:00-06:00", inside: []time.Time{monday(22, 0), monday(5, 59)}, outside: []time.Time{monday(6, 0), monday(21, 59)}},
+		// The part after midnight belongs to Friday's window
+		{spec: "fri 22:00-06:00", inside: []time.Time{saturday(2, 0)}, outside: []time.Time{saturday(22, 30), monday(2, 0)}},
+		{spec: "Saturday", inside: []time.Time{saturday(8, 0)}, outside: []time.Time{monday(8, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			window, err := parseBlackoutWindow(tt.spec)
+			require.NoError(t, err)
+			for _, inside := range tt.inside {
+				assert.True(t, window.contains(inside), "%s should be inside %q", inside, tt.spec)
+			}
+			for _, outside := range tt.outside {
+				assert.False(t, window.contains(outside), "%s should be outside %q", outside, tt.spec)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "someday", "mon 25:00-26:00", "12:00-12:00", "mon tue 12:00-13:00"} {
+		_, err := parseBlackoutWindow(spec)
+		assert.Error(t, err, "spec %q should be rejected", spec)
+	}
+}
+
+func TestSimulateSchedules(t *testing.T) {
+	// Monday 2026-03-02 00:00 UTC
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	stopTime := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	expiredStopTime := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	workflows := []ScheduledWorkflow{
+		{Workflow: "daily-report", Schedules: []workflow.ResolvedSchedule{{Expression: "0 9 * * *", Cron: "0 9 * * *"}}},
+		{Workflow: "triage", Schedules: []workflow.ResolvedSchedule{{Expression: "0 9 * * 1-5", Cron: "5 9 * * 1-5"}}, StopTime: &stopTime},
+		{Workflow: "nightly", Schedules: []workflow.ResolvedSchedule{{Expression: "0 23 * * *", Cron: "0 23 * * *"}}},
+		{Workflow: "retired", Schedules: []workflow.ResolvedSchedule{{Expression: "0 12 * * *", Cron: "0 12 * * *"}}, StopTime: &expiredStopTime},
+	}
+	blackout, err := parseBlackoutWindow("22:00-06:00")
+	require.NoError(t, err)
+
+	simulation, err := simulateSchedules(workflows, from, 7, time.UTC, []*blackoutWindow{blackout})
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	for _, run := range simulation.Runs {
+		counts[run.Workflow]++
+	}
+	assert.Equal(t, map[string]int{"daily-report": 7, "triage": 2, "nightly": 7}, counts, "runs after the stop-after deadline should be excluded")
+	for i := 1; i < len(simulation.Runs); i++ {
+		assert.False(t, simulation.Runs[i].Time.Before(simulation.Runs[i-1].Time), "runs should be sorted by time")
+	}
+
+	require.Len(t, simulation.Overlaps, 2, "triage overlaps daily-report on the days it still runs")
+	assert.Equal(t, []string{"daily-report", "triage"}, simulation.Overlaps[0].Workflows)
+	assert.Equal(t, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), simulation.Overlaps[0].Window)
+
+	assert.Len(t, simulation.Blackouts, 7)
+	for _, run := range simulation.Blackouts {
+		assert.Equal(t, "nightly", run.Workflow)
+		assert.Equal(t, "22:00-06:00", run.Blackout)
+	}
+
+	assert.Equal(t, []ScheduleExpiry{
+		{Workflow: "triage", StopTime: stopTime},
+		{Workflow: "retired", StopTime: expiredStopTime, Expired: true},
+	}, simulation.Expiring)
+}
+
+func TestSimulateSchedulesTimezone(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	workflows := []ScheduledWorkflow{{Workflow: "nightly", Schedules: []workflow.ResolvedSchedule{{Cron: "0 22 * * *"}}}}
+	blackout, err := parseBlackoutWindow("sat,sun")
+	require.NoError(t, err)
+
+	// Friday 2026-03-06 22:00 UTC is Friday 23:00 in Berlin
+	simulation, err := simulateSchedules(workflows, time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), 1, location, []*blackoutWindow{blackout})
+	require.NoError(t, err)
+	require.Len(t, simulation.Runs, 1)
+	assert.Equal(t, 23, simulation.Runs[0].Time.Hour(), "runs should be displayed in the chosen timezone")
+	assert.Empty(t, simulation.Blackouts, "Friday 23:00 in Berlin is outside a weekend blackout")
+}
+
+func TestRenderScheduleViews(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	workflows := []ScheduledWorkflow{
+		{Workflow: "daily-report", Schedules: []workflow.ResolvedSchedule{{Cron: "0 9 * * *"}}},
+		{Workflow: "triage", Schedules: []workflow.ResolvedSchedule{{Cron: "10 9 * * *"}}},
+	}
+	simulation, err := simulateSchedules(workflows, from, 2, time.UTC, nil)
+	require.NoError(t, err)
+
+	heatmap := renderScheduleHeatmap(simulation)
+	lines := strings.Split(heatmap, "\n")
+	var dayLines []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Mon ") || strings.HasPrefix(line, "Tue ") {
+			dayLines = append(dayLines, line)
+		}
+	}
+	require.Len(t, dayLines, 2)
+	assert.True(t, strings.HasSuffix(dayLines[0], "  2"), "each day should end with its run count: %q", dayLines[0])
+	assert.Contains(t, dayLines[0], "██", "the busiest hour should use the darkest glyph")
+
+	calendar := renderScheduleCalendar(simulation)
+	assert.Contains(t, calendar, "Monday 2026-03-02")
+	assert.Contains(t, calendar, "Tuesday 2026-03-03")
+	assert.Contains(t, calendar, "09:10  triage")
+	assert.Contains(t, calendar, "[overlap]")
+}
+
+func TestBuildScheduledWorkflows(t *testing.T) {
+	tmpDir := t.TempDir()
+	lockFile := filepath.Join(tmpDir, "report.lock.yml")
+	require.NoError(t, os.WriteFile(lockFile, []byte("env:\n  GH_AW_STOP_TIME: 2026-03-04 00:00:00\n"), 0644))
+
+	compiler := workflow.NewCompiler(workflow.WithRepositorySlug("octo/repo"))
+	sources := []workflow.ScheduleSource{
+		{Identifier: ".github/workflows/report.md", Frontmatter: map[string]any{"on": map[string]any{"schedule": []any{map[string]any{"cron": "0 9 * * *"}}}}},
+		{Identifier: ".github/workflows/ci.md", Frontmatter: map[string]any{"on": "push"}},
+		{Identifier: ".github/workflows/audit.md", Frontmatter: map[string]any{"on": map[string]any{"schedule": []any{map[string]any{"cron": "0 3 * * 0"}}}}},
+	}
+	lockFileFor := func(identifier string) string {
+		if strings.HasSuffix(identifier, "report.md") {
+			return lockFile
+		}
+		return filepath.Join(tmpDir, "missing.lock.yml")
+	}
+
+	workflows := buildScheduledWorkflows(compiler, sources, lockFileFor, nil)
+	require.Len(t, workflows, 2, "workflows without schedules should be skipped")
+	assert.Equal(t, "audit", workflows[0].Workflow)
+	assert.Nil(t, workflows[0].StopTime)
+	assert.Equal(t, "report", workflows[1].Workflow)
+	assert.Equal(t, "0 9 * * *", workflows[1].Schedules[0].Cron)
+	require.NotNil(t, workflows[1].StopTime)
+	assert.Equal(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), *workflows[1].StopTime)
+
+	filtered := buildScheduledWorkflows(compiler, sources, lockFileFor, []string{"audit"})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "audit", filtered[0].Workflow)
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+var testCommandLog = logger.New("cli:test_command")
+
+// workflowTestFileSuffix is the file name suffix of workflow test files
+const workflowTestFileSuffix = ".test.yml"
+
+// workflowTestsDir holds test files that should not live in .github/workflows, where GitHub
+// Actions would parse them as workflows
+const workflowTestsDir = ".github/aw/tests"
+
+// TestConfig holds configuration for test command execution
+type TestConfig struct {
+	Workflows   []string // Restrict to the tests of these workflow IDs (all test files when empty)
+	WorkflowDir string   // Custom workflow directory
+	JUnitFile   string   // Path of the JUnit XML report to write
+	JSONOutput  bool
+	Verbose     bool
+}
+
+// WorkflowTestResult is the outcome of the assertions of one test file
+type WorkflowTestResult struct {
+	Workflow   string            `json:"workflow"`
+	TestFile   string            `json:"test_file"`
+	Error      string            `json:"error,omitempty"` // Set when the test file or workflow could not be loaded
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+}
+
+// AssertionResult is the outcome of a single assertion
+type AssertionResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// failures returns the number of failed assertions, counting a load error as one failure
+func (r *WorkflowTestResult) failures() int {
+	if r.Error != "" {
+		return 1
+	}
+	failed := 0
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// NewTestCommand creates the test command
+func NewTestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [workflow]...",
+		Short: "Check assertions on the compiled output of workflows",
+		Long: `Compile workflows in memory and check the assertions of their test files.
+
+A test file named <workflow>.test.yml lists assertions on the compiled workflow. Test files are
+read from the workflow directory and from ` + workflowTestsDir + `; the optional workflow: key names the
+workflow when it differs from the file name.
+
+  assertions:
+    - name: agent cannot write contents
+      permission: {job: agent, scope: contents, max: read}
+    - network: {only: [defaults, python]}
+    - safe-output: {type: create-issue, max: 1}
+    - trigger: {event: issues, types: [opened]}
+    - lock-file: {not-contains: ["secrets.ADMIN_TOKEN"]}
+
+No lock files are written. The command fails when an assertion fails, and --junit writes a
+JUnit XML report for CI systems.
+
+` + WorkflowIDExplanation + `
+
+Examples:
+  ` + string(constants.CLIExtensionPrefix) + ` test                           # Run all workflow tests
+  ` + string(constants.CLIExtensionPrefix) + ` test issue-triage              # Run the tests of one workflow
+  ` + string(constants.CLIExtensionPrefix) + ` test --junit test-results.xml  # Write a JUnit XML report
+  ` + string(constants.CLIExtensionPrefix) + ` test --json                    # Output the results as JSON`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			junitFile, _ := cmd.Flags().GetString("junit")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+
+			return RunTest(TestConfig{
+				Workflows:   args,
+				WorkflowDir: dir,
+				JUnitFile:   junitFile,
+				JSONOutput:  jsonOutput,
+				Verbose:     verbose,
+			})
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "", "Workflow directory (default: .github/workflows)")
+	cmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	addJSONFlag(cmd)
+
+	cmd.ValidArgsFunction = CompleteWorkflowNames
+	RegisterDirFlagCompletion(cmd, "dir")
+
+	return cmd
+}
+
+// RunTest executes the test command with the given configuration
+func RunTest(config TestConfig) error {
+	testCommandLog.Printf("Running workflow tests: workflows=%v, dir=%s", config.Workflows, config.WorkflowDir)
+
+	workflowDir := config.WorkflowDir
+	if workflowDir == "" {
+		workflowDir = getWorkflowsDir()
+	} else {
+		workflowDir = filepath.Clean(workflowDir)
+	}
+
+	gitRoot, err := findGitRootForPath(workflowDir)
+	if err != nil {
+		gitRoot = filepath.Dir(workflowDir)
+	}
+
+	testFiles, err := findWorkflowTestFiles([]string{workflowDir, filepath.Join(gitRoot, workflowTestsDir)})
+	if err != nil {
+		return err
+	}
+
+	compiler := newInMemoryCompiler(gitRoot, config.Verbose)
+	var results []*WorkflowTestResult
+	for _, testFile := range testFiles {
+		result, tests := loadWorkflowTestFile(gitRoot, testFile)
+		if len(config.Workflows) > 0 && !slices.ContainsFunc(config.Workflows, func(w string) bool { return normalizeWorkflowID(w) == result.Workflow }) {
+			continue
+		}
+		if tests != nil {
+			runWorkflowTests(compiler, gitRoot, workflowDir, result, tests)
+		}
+		results = append(results, result)
+	}
+	for _, workflowID := range config.Workflows {
+		id := normalizeWorkflowID(workflowID)
+		if !slices.ContainsFunc(results, func(r *WorkflowTestResult) bool { return r.Workflow == id }) {
+			return fmt.Errorf("no test file found for workflow %s (expected %s%s)", id, id, workflowTestFileSuffix)
+		}
+	}
+
+	if config.JUnitFile != "" {
+		if err := writeJUnitReport(config.JUnitFile, results); err != nil {
+			return err
+		}
+		testCommandLog.Printf("Wrote JUnit report to %s", config.JUnitFile)
+	}
+
+	failed := 0
+	total := 0
+	for _, result := range results {
+		failed += result.failures()
+		total += max(len(result.Assertions), result.failures())
+	}
+
+	if config.JSONOutput {
+		if results == nil {
+			results = []*WorkflowTestResult{}
+		}
+		jsonBytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal test results: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		renderWorkflowTestResults(results, config.Verbose)
+	}
+
+	if len(results) == 0 {
+		if !config.JSONOutput {
+			fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("No workflow test files (*%s) found.", workflowTestFileSuffix)))
+		}
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d workflow assertions failed", failed, total)
+	}
+	if !config.JSONOutput {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage(fmt.Sprintf("%d assertions passed in %d test files", total, len(results))))
+	}
+	return nil
+}
+
+// findWorkflowTestFiles returns the test files of the given directories, sorted by path
+func findWorkflowTestFiles(dirs []string) ([]string, error) {
+	var files []string
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(filepath.Join(absDir, "*"+workflowTestFileSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list test files in %s: %w", dir, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return slices.Compact(files), nil
+}
+
+// loadWorkflowTestFile parses a test file. On failure, the returned result holds the error and
+// the assertions are nil.
+func loadWorkflowTestFile(gitRoot, testFile string) (*WorkflowTestResult, *workflow.WorkflowTestFile) {
+	result := &WorkflowTestResult{
+		Workflow: strings.TrimSuffix(filepath.Base(testFile), workflowTestFileSuffix),
+		TestFile: testFile,
+	}
+	if relPath, err := filepath.Rel(gitRoot, testFile); err == nil && !strings.HasPrefix(relPath, "..") {
+		result.TestFile = filepath.ToSlash(relPath)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read test file: %v", err)
+		return result, nil
+	}
+	tests, err := workflow.ParseWorkflowTestFile(content, result.TestFile)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if tests.Workflow != "" {
+		result.Workflow = normalizeWorkflowID(tests.Workflow)
+	}
+	return result, tests
+}
+
+// runWorkflowTests compiles the workflow of a test file and records the outcome of its assertions
+func runWorkflowTests(compiler *workflow.Compiler, gitRoot, workflowDir string, result *WorkflowTestResult, tests *workflow.WorkflowTestFile) {
+	workflowFile := filepath.Join(workflowDir, result.Workflow+".md")
+	if _, err := os.Stat(workflowFile); err != nil {
+		result.Error = fmt.Sprintf("workflow %s not found in %s", result.Workflow, workflowDir)
+		return
+	}
+	data, lockYAML, err := compileInMemory(compiler, gitRoot, workflowFile)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to compile %s: %v", result.Workflow, err)
+		return
+	}
+	if data == nil {
+		result.Error = fmt.Sprintf("%s is a shared workflow and cannot be compiled on its own", result.Workflow)
+		return
+	}
+	snapshot, err := workflow.NewWorkflowSnapshot(data, lockYAML)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to analyze %s: %v", result.Workflow, err)
+		return
+	}
+
+	for i := range tests.Assertions {
+		assertion := &tests.Assertions[i]
+		assertionResult := AssertionResult{Name: assertion.Description(), Passed: true}
+		if err := assertion.Evaluate(snapshot, lockYAML); err != nil {
+			assertionResult.Passed = false
+			assertionResult.Message = err.Error()
+		}
+		result.Assertions = append(result.Assertions, assertionResult)
+	}
+	testCommandLog.Printf("Ran %d assertions of %s: %d failed", len(result.Assertions), result.TestFile, result.failures())
+}
+
+// renderWorkflowTestResults prints the result of every assertion
+func renderWorkflowTestResults(results []*WorkflowTestResult, verbose bool) {
+	for _, result := range results {
+		fmt.Fprintln(os.Stderr, console.FormatInfoMessage(fmt.Sprintf("%s (%s)", result.Workflow, result.TestFile)))
+		if result.Error != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", console.FormatErrorMessage(result.Error))
+			continue
+		}
+		for _, assertion := range result.Assertions {
+			if assertion.Passed {
+				if verbose || result.failures() > 0 {
+					fmt.Fprintf(os.Stderr, "  %s\n", console.FormatSuccessMessage(assertion.Name))
+				}
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "  %s\n", console.FormatErrorMessage(fmt.Sprintf("%s: %s", assertion.Name, assertion.Message)))
+		}
+		if result.failures() == 0 && !verbose {
+			fmt.Fprintf(os.Stderr, "  %s\n", console.FormatSuccessMessage(fmt.Sprintf("%d assertions passed", len(result.Assertions))))
+		}
+	}
+}
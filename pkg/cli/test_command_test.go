@@ -0,0 +1,122 @@
+//go:build !integration
+
+package cli
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWorkflowTestRepo creates a git repository with a triage workflow
+func setupWorkflowTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmpDir := testutil.TempDir(t, "workflow-test-*")
+	require.NoError(t, initTestGitRepo(tmpDir))
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "triage.md"), []byte(`---
+on:
+  issues:
+    types: [opened]
+permissions:
+  contents: read
+  issues: read
+network:
+  allowed: [defaults, python]
+safe-outputs:
+  create-issue:
+---
+
+# Triage
+`), 0644))
+	return tmpDir, workflowsDir
+}
+
+func TestRunTest(t *testing.T) {
+	tmpDir, workflowsDir := setupWorkflowTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "triage.test.yml"), []byte(`assertions:
+  - name: agent cannot write contents
+    permission: {scope: contents, max: read}
+  - network: {only: [defaults, python]}
+  - safe-output: {type: create-issue, max: 1}
+  - trigger: {event: issues, types: [opened]}
+  - lock-file: {not-contains: ["secrets.ADMIN_TOKEN"]}
+`), 0644))
+
+	junitPath := filepath.Join(tmpDir, "reports", "junit.xml")
+	err := RunTest(TestConfig{WorkflowDir: workflowsDir, JUnitFile: junitPath})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(content, &report))
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 0, report.Failures)
+	require.Len(t, report.Suites, 1)
+	assert.Equal(t, "triage", report.Suites[0].Name)
+	assert.Equal(t, "agent cannot write contents", report.Suites[0].TestCases[0].Name)
+}
+
+func TestRunTestFailures(t *testing.T) {
+	tmpDir, workflowsDir := setupWorkflowTestRepo(t)
+	testsDir := filepath.Join(tmpDir, workflowTestsDir)
+	require.NoError(t, os.MkdirAll(testsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "guard.test.yml"), []byte(`workflow: triage
+assertions:
+  - network: {only: [defaults]}
+  - permission: {scope: issues, max: read}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(testsDir, "missing.test.yml"), []byte(`assertions:
+  - trigger: {event: push}
+`), 0644))
+
+	junitPath := filepath.Join(tmpDir, "junit.xml")
+	err := RunTest(TestConfig{WorkflowDir: workflowsDir, JUnitFile: junitPath})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 3 workflow assertions failed", "a missing workflow counts as one failure")
+
+	content, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(content, &report))
+	require.Len(t, report.Suites, 2)
+	assert.Equal(t, "triage", report.Suites[0].Name, "the workflow key names the tested workflow")
+	assert.Equal(t, ".github/aw/tests/guard.test.yml", report.Suites[0].File)
+	assert.Equal(t, 1, report.Suites[0].Failures)
+	assert.Contains(t, report.Suites[0].TestCases[0].Failure.Message, "network also allows python")
+	assert.Equal(t, 1, report.Suites[1].Errors)
+	assert.Contains(t, report.Suites[1].TestCases[0].Error.Message, "workflow missing not found")
+
+	err = RunTest(TestConfig{WorkflowDir: workflowsDir, Workflows: []string{"triage"}})
+	require.Error(t, err, "workflow filters select test files by their tested workflow")
+	assert.Contains(t, err.Error(), "1 of 2 workflow assertions failed")
+
+	err = RunTest(TestConfig{WorkflowDir: workflowsDir, Workflows: []string{"docs"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no test file found for workflow docs")
+}
+
+func TestBuildJUnitReport(t *testing.T) {
+	report := buildJUnitReport([]*WorkflowTestResult{
+		{Workflow: "triage", TestFile: "triage.test.yml", Assertions: []AssertionResult{
+			{Name: "a", Passed: true},
+			{Name: "b", Message: "failed"},
+		}},
+		{Workflow: "docs", TestFile: "docs.test.yml", Error: "invalid test file"},
+	})
+
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	require.Len(t, report.Suites, 2)
+	assert.Nil(t, report.Suites[0].TestCases[0].Failure)
+	assert.Equal(t, "failed", report.Suites[0].TestCases[1].Failure.Message)
+	assert.Equal(t, "load docs.test.yml", report.Suites[1].TestCases[0].Name)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the assertions of one workflow test file
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	File      string          `xml:"file,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single assertion
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+// junitMessage is the failure or error of a test case
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// buildJUnitReport converts workflow test results into a JUnit report with one suite per test
+// file. A test file that could not be loaded is reported as a single errored test case.
+func buildJUnitReport(results []*WorkflowTestResult) junitTestSuites {
+	report := junitTestSuites{Name: "gh-aw workflow tests"}
+	for _, result := range results {
+		suite := junitTestSuite{Name: result.Workflow, File: result.TestFile}
+		if result.Error != "" {
+			suite.Errors = 1
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      "load " + result.TestFile,
+				ClassName: result.Workflow,
+				Error:     &junitMessage{Message: result.Error, Text: result.Error},
+			})
+		}
+		for _, assertion := range result.Assertions {
+			testCase := junitTestCase{Name: assertion.Name, ClassName: result.Workflow}
+			if !assertion.Passed {
+				suite.Failures++
+				testCase.Failure = &junitMessage{Message: assertion.Message, Text: assertion.Message}
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		suite.Tests = len(suite.TestCases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+	}
+	return report
+}
+
+// writeJUnitReport writes the workflow test results as a JUnit XML file
+func writeJUnitReport(path string, results []*WorkflowTestResult) error {
+	content, err := xml.MarshalIndent(buildJUnitReport(results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for JUnit report: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(content, '\n')...), 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
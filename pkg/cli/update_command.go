@@ -29,6 +29,10 @@ The command:
 By default, the update command replaces local workflow files with the latest version from the source
 repository, overriding any local changes. Use the --merge flag to preserve local changes by performing
 a 3-way merge between the base version, your local changes, and the latest upstream version.
+The frontmatter is merged key by key: keys changed only upstream are taken, keys changed only locally
+are kept, and lists such as network.allowed are merged as sets. Keys changed on both sides are marked
+as conflicts; add --interactive to choose the version of each conflicting key. The markdown body is
+merged line by line.
 
 For workflow updates, it fetches the latest version based on the current ref:
 - If the ref is a tag, it updates to the latest release (use --major for major version updates)
@@ -56,6 +60,7 @@ Examples:
   ` + string(constants.CLIExtensionPrefix) + ` update ci-doctor.md      # Check gh aw updates, update actions, and update specific workflow (alternative format)
   ` + string(constants.CLIExtensionPrefix) + ` update ci-doctor --major # Allow major version updates
   ` + string(constants.CLIExtensionPrefix) + ` update --merge           # Update with 3-way merge to preserve local changes
+  ` + string(constants.CLIExtensionPrefix) + ` update --merge --interactive  # Resolve frontmatter conflicts interactively
   ` + string(constants.CLIExtensionPrefix) + ` update --pr              # Create PR with changes
   ` + string(constants.CLIExtensionPrefix) + ` update --force           # Force update even if no changes
   ` + string(constants.CLIExtensionPrefix) + ` update --dir custom/workflows  # Update workflows in custom directory
@@ -71,6 +76,7 @@ Examples:
 			noStopAfter, _ := cmd.Flags().GetBool("no-stop-after")
 			stopAfter, _ := cmd.Flags().GetString("stop-after")
 			mergeFlag, _ := cmd.Flags().GetBool("merge")
+			interactiveFlag, _ := cmd.Flags().GetBool("interactive")
 			noActions, _ := cmd.Flags().GetBool("no-actions")
 			auditFlag, _ := cmd.Flags().GetBool("audit")
 			dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
@@ -80,6 +86,10 @@ Examples:
 				return err
 			}
 
+			if interactiveFlag && !mergeFlag {
+				return fmt.Errorf("--interactive requires --merge")
+			}
+
 			// Handle audit mode
 			if auditFlag {
 				return runDependencyAudit(verbose, jsonOutput)
@@ -91,7 +101,7 @@ Examples:
 				return fmt.Errorf("--dry-run mode not yet implemented for workflow updates")
 			}
 
-			return UpdateWorkflowsWithExtensionCheck(args, majorFlag, forceFlag, verbose, engineOverride, prFlag, workflowDir, noStopAfter, stopAfter, mergeFlag, noActions)
+			return UpdateWorkflowsWithExtensionCheck(args, majorFlag, forceFlag, verbose, engineOverride, prFlag, workflowDir, noStopAfter, stopAfter, mergeFlag, interactiveFlag, noActions)
 		},
 	}
 
@@ -103,6 +113,7 @@ Examples:
 	cmd.Flags().Bool("no-stop-after", false, "Remove any stop-after field from the workflow")
 	cmd.Flags().String("stop-after", "", "Override stop-after value in the workflow (e.g., '+48h', '2025-12-31 23:59:59')")
 	cmd.Flags().Bool("merge", false, "Merge local changes with upstream updates instead of overriding")
+	cmd.Flags().Bool("interactive", false, "Choose the version of each conflicting frontmatter key (requires --merge)")
 	cmd.Flags().Bool("no-actions", false, "Skip updating GitHub Actions versions")
 	cmd.Flags().Bool("audit", false, "Check dependency health without performing updates (implies --dry-run)")
 	cmd.Flags().Bool("dry-run", false, "Show what would be updated without making changes")
@@ -141,8 +152,8 @@ func runDependencyAudit(verbose bool, jsonOutput bool) error {
 // 3. Update workflows from source repositories (compiles each workflow after update)
 // 4. Apply automatic fixes to updated workflows
 // 5. Optionally create a PR
-func UpdateWorkflowsWithExtensionCheck(workflowNames []string, allowMajor, force, verbose bool, engineOverride string, createPR bool, workflowsDir string, noStopAfter bool, stopAfter string, merge bool, noActions bool) error {
-	updateLog.Printf("Starting update process: workflows=%v, allowMajor=%v, force=%v, createPR=%v, merge=%v, noActions=%v", workflowNames, allowMajor, force, createPR, merge, noActions)
+func UpdateWorkflowsWithExtensionCheck(workflowNames []string, allowMajor, force, verbose bool, engineOverride string, createPR bool, workflowsDir string, noStopAfter bool, stopAfter string, merge bool, interactive bool, noActions bool) error {
+	updateLog.Printf("Starting update process: workflows=%v, allowMajor=%v, force=%v, createPR=%v, merge=%v, interactive=%v, noActions=%v", workflowNames, allowMajor, force, createPR, merge, interactive, noActions)
 
 	// Step 1: Check for gh-aw extension updates
 	if err := checkExtensionUpdate(verbose); err != nil {
@@ -158,7 +169,7 @@ func UpdateWorkflowsWithExtensionCheck(workflowNames []string, allowMajor, force
 
 	// Step 3: Update workflows from source repositories
 	// Note: Each workflow is compiled immediately after update
-	if err := UpdateWorkflows(workflowNames, allowMajor, force, verbose, engineOverride, workflowsDir, noStopAfter, stopAfter, merge); err != nil {
+	if err := UpdateWorkflows(workflowNames, allowMajor, force, verbose, engineOverride, workflowsDir, noStopAfter, stopAfter, merge, interactive); err != nil {
 		return fmt.Errorf("workflow update failed: %w", err)
 	}
 
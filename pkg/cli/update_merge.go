@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/github/gh-aw/pkg/console"
 	"github.com/github/gh-aw/pkg/logger"
@@ -80,13 +82,20 @@ func hasLocalModifications(sourceContent, localContent, sourceSpec string, verbo
 	return hasModifications
 }
 
-// MergeWorkflowContent performs a 3-way merge of workflow content using git merge-file
+// MergeWorkflowContent performs a 3-way merge of workflow content. The frontmatter is merged
+// structurally (see update_merge_frontmatter.go) and the markdown body line-wise using git merge-file.
 // It returns the merged content, whether conflicts exist, and any error
 func MergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, verbose bool) (string, bool, error) {
+	return mergeWorkflowContent(base, current, new, oldSourceSpec, newRef, nil, verbose)
+}
+
+// mergeWorkflowContent performs the 3-way merge of MergeWorkflowContent, asking the resolver to
+// decide conflicting frontmatter keys when it is set
+func mergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, resolve frontmatterConflictResolver, verbose bool) (string, bool, error) {
 	updateMergeLog.Printf("Starting 3-way merge: old_ref=%s, new_ref=%s", oldSourceSpec, newRef)
 
 	if verbose {
-		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage("Performing 3-way merge using git merge-file"))
+		fmt.Fprintln(os.Stderr, console.FormatVerboseMessage("Performing 3-way merge of frontmatter keys and markdown lines"))
 	}
 
 	// Parse the old source spec to get the current ref
@@ -108,6 +117,12 @@ func MergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, verb
 		baseWithSource = base
 	}
 
+	// The source field is managed by update, so the local version always takes the upstream one
+	currentWithSource, err := UpdateFieldInFrontmatter(current, "source", currentSourceSpec)
+	if err != nil {
+		currentWithSource = current
+	}
+
 	// Update the source field in the new content with the new ref
 	newWithUpdatedSource, err := UpdateFieldInFrontmatter(new, "source", fmt.Sprintf("%s/%s@%s", sourceSpec.Repo, sourceSpec.Path, newRef))
 	if err != nil {
@@ -120,9 +135,93 @@ func MergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, verb
 
 	// Normalize whitespace in all three versions to reduce spurious conflicts
 	baseNormalized := stringutil.NormalizeWhitespace(baseWithSource)
-	currentNormalized := stringutil.NormalizeWhitespace(current)
+	currentNormalized := stringutil.NormalizeWhitespace(currentWithSource)
 	newNormalized := stringutil.NormalizeWhitespace(newWithUpdatedSource)
 
+	mergedStr, hasConflicts, err := mergeWorkflowSections(baseNormalized, currentNormalized, newNormalized, resolve, verbose)
+	if err != nil {
+		return "", false, err
+	}
+
+	updateMergeLog.Printf("Merge completed: has_conflicts=%v", hasConflicts)
+
+	// Process @include directives if present and no conflicts
+	// Skip include processing if there are conflicts to avoid errors
+	if !hasConflicts {
+		sourceSpec, err := parseSourceSpec(oldSourceSpec)
+		if err == nil {
+			workflow := &WorkflowSpec{
+				RepoSpec: RepoSpec{
+					RepoSlug: sourceSpec.Repo,
+					Version:  newRef,
+				},
+				WorkflowPath: sourceSpec.Path,
+			}
+
+			processedContent, err := processIncludesInContent(mergedStr, workflow, newRef, verbose)
+			if err != nil {
+				if verbose {
+					fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to process includes: %v", err)))
+				}
+				// Return unprocessed content on error
+			} else {
+				mergedStr = processedContent
+			}
+		}
+	}
+
+	return mergedStr, hasConflicts, nil
+}
+
+// mergeWorkflowSections merges the frontmatter and the markdown body of a workflow separately.
+// Workflows without valid frontmatter in any version are merged line-wise as a whole.
+func mergeWorkflowSections(base, current, new string, resolve frontmatterConflictResolver, verbose bool) (string, bool, error) {
+	var frontmatters [3][]string
+	var bodies [3]string
+	for i, content := range []string{base, current, new} {
+		if !strings.HasPrefix(content, "---") {
+			return mergeTextWithGit(base, current, new, verbose)
+		}
+		lines, body, err := parseFrontmatterLines(content)
+		if err != nil {
+			updateMergeLog.Printf("Falling back to line-wise merge: %v", err)
+			return mergeTextWithGit(base, current, new, verbose)
+		}
+		frontmatters[i], bodies[i] = lines, body
+	}
+
+	frontmatter, conflicts, err := mergeFrontmatterLines(frontmatters[0], frontmatters[1], frontmatters[2], resolve)
+	frontmatterConflicts := len(conflicts) > 0
+	if errors.Is(err, errFrontmatterComments) {
+		updateMergeLog.Printf("Merging frontmatter line-wise to keep its comments: %v", err)
+		var text string
+		if text, frontmatterConflicts, err = mergeTextWithGit(strings.Join(frontmatters[0], "\n")+"\n", strings.Join(frontmatters[1], "\n")+"\n", strings.Join(frontmatters[2], "\n")+"\n", verbose); err != nil {
+			return "", false, err
+		}
+		frontmatter = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	} else if err != nil {
+		updateMergeLog.Printf("Falling back to line-wise merge: %v", err)
+		return mergeTextWithGit(base, current, new, verbose)
+	}
+	if len(conflicts) > 0 {
+		paths := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			paths = append(paths, conflict.Path)
+		}
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Frontmatter keys changed both locally and upstream: %s", strings.Join(paths, ", "))))
+	}
+
+	body, bodyConflicts, err := mergeTextWithGit(bodies[0]+"\n", bodies[1]+"\n", bodies[2]+"\n", verbose)
+	if err != nil {
+		return "", false, err
+	}
+
+	merged := stringutil.NormalizeWhitespace(reconstructContent(frontmatter, strings.TrimSpace(body)))
+	return merged, frontmatterConflicts || bodyConflicts, nil
+}
+
+// mergeTextWithGit performs a line-wise 3-way merge using git merge-file
+func mergeTextWithGit(base, current, new string, verbose bool) (string, bool, error) {
 	// Create temporary directory for merge files
 	tmpDir, err := os.MkdirTemp("", "gh-aw-merge-*")
 	if err != nil {
@@ -135,13 +234,13 @@ func MergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, verb
 	currentFile := filepath.Join(tmpDir, "current.md")
 	newFile := filepath.Join(tmpDir, "new.md")
 
-	if err := os.WriteFile(baseFile, []byte(baseNormalized), 0644); err != nil {
+	if err := os.WriteFile(baseFile, []byte(base), 0644); err != nil {
 		return "", false, fmt.Errorf("failed to write base file: %w", err)
 	}
-	if err := os.WriteFile(currentFile, []byte(currentNormalized), 0644); err != nil {
+	if err := os.WriteFile(currentFile, []byte(current), 0644); err != nil {
 		return "", false, fmt.Errorf("failed to write current file: %w", err)
 	}
-	if err := os.WriteFile(newFile, []byte(newNormalized), 0644); err != nil {
+	if err := os.WriteFile(newFile, []byte(new), 0644); err != nil {
 		return "", false, fmt.Errorf("failed to write new file: %w", err)
 	}
 
@@ -182,40 +281,26 @@ func MergeWorkflowContent(base, current, new, oldSourceSpec, newRef string, verb
 		}
 	}
 
-	updateMergeLog.Printf("Merge completed: has_conflicts=%v", hasConflicts)
+	updateMergeLog.Printf("Line-wise merge completed: has_conflicts=%v", hasConflicts)
 
 	// Read the merged content from the current file (git merge-file updates it in-place)
 	mergedContent, err := os.ReadFile(currentFile)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to read merged content: %w", err)
 	}
+	return string(mergedContent), hasConflicts, nil
+}
 
-	mergedStr := string(mergedContent)
-
-	// Process @include directives if present and no conflicts
-	// Skip include processing if there are conflicts to avoid errors
-	if !hasConflicts {
-		sourceSpec, err := parseSourceSpec(oldSourceSpec)
-		if err == nil {
-			workflow := &WorkflowSpec{
-				RepoSpec: RepoSpec{
-					RepoSlug: sourceSpec.Repo,
-					Version:  newRef,
-				},
-				WorkflowPath: sourceSpec.Path,
-			}
-
-			processedContent, err := processIncludesInContent(mergedStr, workflow, newRef, verbose)
-			if err != nil {
-				if verbose {
-					fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Failed to process includes: %v", err)))
-				}
-				// Return unprocessed content on error
-			} else {
-				mergedStr = processedContent
-			}
-		}
-	}
-
-	return mergedStr, hasConflicts, nil
+// promptFrontmatterConflict asks which version of a conflicting frontmatter key to keep
+func promptFrontmatterConflict(conflict *FrontmatterConflict) (string, error) {
+	return console.PromptSelect(
+		fmt.Sprintf("Conflict in %s", conflict.Path),
+		"This key was changed both locally and upstream",
+		[]console.SelectOption{
+			{Label: "Keep local: " + formatConflictValue(conflict.Local), Value: mergeSideLocal},
+			{Label: "Take upstream: " + formatConflictValue(conflict.Upstream), Value: mergeSideUpstream},
+			{Label: "Revert to base: " + formatConflictValue(conflict.Base), Value: mergeSideBase},
+			{Label: "Leave conflict markers", Value: ""},
+		},
+	)
 }
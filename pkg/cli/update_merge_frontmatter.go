@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/goccy/go-yaml"
+)
+
+var updateMergeFrontmatterLog = logger.New("cli:update_merge_frontmatter")
+
+// Sides of a frontmatter conflict, as chosen by a conflict resolver
+const (
+	mergeSideLocal    = "local"
+	mergeSideUpstream = "upstream"
+	mergeSideBase     = "base"
+)
+
+// missingKey marks a frontmatter key that is absent from one version of the workflow
+type missingKey struct{}
+
+// FrontmatterConflict is a frontmatter key changed differently by the local and upstream versions.
+// Values are missingKey{} when the key is absent from a version.
+type FrontmatterConflict struct {
+	Path     string // Dotted path of the key, such as tools.github.toolsets
+	Key      string // Last element of the path
+	Base     any
+	Local    any
+	Upstream any
+}
+
+// Value returns the value of the conflicting key in the given side
+func (c *FrontmatterConflict) Value(side string) any {
+	switch side {
+	case mergeSideUpstream:
+		return c.Upstream
+	case mergeSideBase:
+		return c.Base
+	default:
+		return c.Local
+	}
+}
+
+// frontmatterConflictResolver chooses the side of a conflict to keep. An empty side leaves the
+// conflict markers in the merged workflow.
+type frontmatterConflictResolver func(conflict *FrontmatterConflict) (string, error)
+
+// errFrontmatterComments reports that a frontmatter key merged from both sides has comments that
+// rendering the merged value would drop; the frontmatter is then merged line-wise instead
+var errFrontmatterComments = errors.New("frontmatter key changed on both sides has comments")
+
+// conflictPlaceholderPattern matches the placeholder rendered in place of a conflicting key
+var conflictPlaceholderPattern = regexp.MustCompile(`^([ \t]*)\S.*: __gh_aw_conflict_(\d+)__$`)
+
+// mergeFrontmatterLines merges the frontmatter of three versions of a workflow. Keys changed only
+// upstream are taken, keys changed only locally are kept, lists of scalars are merged as sets,
+// and keys changed on both sides are reported as conflicts. Conflicts the resolver doesn't decide
+// are rendered with conflict markers around the key.
+func mergeFrontmatterLines(base, local, upstream []string, resolve frontmatterConflictResolver) ([]string, []*FrontmatterConflict, error) {
+	baseText := strings.Join(base, "\n")
+	localText := strings.Join(local, "\n")
+	upstreamText := strings.Join(upstream, "\n")
+
+	// Keep the text, with its comments and formatting, when only one side changed
+	switch {
+	case localText == upstreamText || upstreamText == baseText:
+		return local, nil, nil
+	case localText == baseText:
+		return upstream, nil, nil
+	}
+
+	var baseMap, localMap, upstreamMap yaml.MapSlice
+	for _, version := range []struct {
+		text   string
+		target *yaml.MapSlice
+		name   string
+	}{{baseText, &baseMap, "base"}, {localText, &localMap, "local"}, {upstreamText, &upstreamMap, "upstream"}} {
+		if err := yaml.UnmarshalWithOptions([]byte(version.text), version.target, yaml.UseOrderedMap()); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s frontmatter: %w", version.name, err)
+		}
+	}
+
+	merged, conflicts := mergeFrontmatterMaps(baseMap, localMap, upstreamMap, "")
+	updateMergeFrontmatterLog.Printf("Merged frontmatter structurally: %d conflicts", len(conflicts))
+
+	var unresolved []*FrontmatterConflict
+	for _, conflict := range conflicts {
+		side := ""
+		if resolve != nil {
+			var err error
+			if side, err = resolve(conflict); err != nil {
+				return nil, nil, err
+			}
+		}
+		if side == "" {
+			setFrontmatterValue(merged, conflict.Path, fmt.Sprintf("__gh_aw_conflict_%d__", len(unresolved)))
+			unresolved = append(unresolved, conflict)
+			continue
+		}
+		setFrontmatterValue(merged, conflict.Path, conflict.Value(side))
+	}
+
+	rendered, err := renderMergedFrontmatter(merged, localMap, upstreamMap, base, local, upstream)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lines []string
+	for _, line := range rendered {
+		match := conflictPlaceholderPattern.FindStringSubmatch(line)
+		if match == nil {
+			lines = append(lines, line)
+			continue
+		}
+		index, _ := strconv.Atoi(match[2])
+		conflictLines, err := renderConflictMarkers(unresolved[index], match[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		lines = append(lines, conflictLines...)
+	}
+	return lines, unresolved, nil
+}
+
+// mergeFrontmatterMaps merges the keys of three versions of a YAML mapping. Keys keep the local
+// order, followed by keys added upstream.
+func mergeFrontmatterMaps(base, local, upstream yaml.MapSlice, path string) (yaml.MapSlice, []*FrontmatterConflict) {
+	keys := make([]string, 0, len(local)+len(upstream))
+	for _, items := range []yaml.MapSlice{local, upstream, base} {
+		for _, item := range items {
+			if key := fmt.Sprint(item.Key); !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var merged yaml.MapSlice
+	var conflicts []*FrontmatterConflict
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		baseValue, localValue, upstreamValue := lookupMapItem(base, key), lookupMapItem(local, key), lookupMapItem(upstream, key)
+
+		value, keyConflicts := mergeFrontmatterValue(baseValue, localValue, upstreamValue, keyPath)
+		if len(keyConflicts) > 0 {
+			conflicts = append(conflicts, keyConflicts...)
+			if value == nil {
+				// Keep a slot for the conflict, filled in when it is resolved
+				value = localValue
+				if _, absent := value.(missingKey); absent {
+					value = upstreamValue
+				}
+			}
+		}
+		if _, absent := value.(missingKey); absent {
+			continue
+		}
+		merged = append(merged, yaml.MapItem{Key: key, Value: value})
+	}
+	return merged, conflicts
+}
+
+// mergeFrontmatterValue merges three versions of a frontmatter value. A conflict on the value
+// itself is returned with a nil value.
+func mergeFrontmatterValue(base, local, upstream any, path string) (any, []*FrontmatterConflict) {
+	switch {
+	case frontmatterValuesEqual(local, upstream), frontmatterValuesEqual(upstream, base):
+		return local, nil
+	case frontmatterValuesEqual(local, base):
+		return upstream, nil
+	}
+
+	localMap, localIsMap := local.(yaml.MapSlice)
+	upstreamMap, upstreamIsMap := upstream.(yaml.MapSlice)
+	if localIsMap && upstreamIsMap {
+		baseMap, _ := base.(yaml.MapSlice)
+		return mergeFrontmatterMaps(baseMap, localMap, upstreamMap, path)
+	}
+
+	localList, localIsList := local.([]any)
+	upstreamList, upstreamIsList := upstream.([]any)
+	if localIsList && upstreamIsList && isScalarList(localList) && isScalarList(upstreamList) {
+		baseList, _ := base.([]any)
+		return mergeScalarSets(baseList, localList, upstreamList), nil
+	}
+
+	key := path[strings.LastIndex(path, ".")+1:]
+	return nil, []*FrontmatterConflict{{Path: path, Key: key, Base: base, Local: local, Upstream: upstream}}
+}
+
+// mergeScalarSets merges lists such as network.allowed as sets: items added on either side are
+// kept and items removed on either side are dropped
+func mergeScalarSets(base, local, upstream []any) []any {
+	merged := make([]any, 0, len(local)+len(upstream))
+	for _, item := range local {
+		if slices.Contains(base, item) && !slices.Contains(upstream, item) {
+			continue
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range upstream {
+		if slices.Contains(merged, item) || (slices.Contains(base, item) && !slices.Contains(local, item)) {
+			continue
+		}
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// isScalarList returns true if no item of the list is a mapping or a list
+func isScalarList(list []any) bool {
+	for _, item := range list {
+		switch item.(type) {
+		case yaml.MapSlice, []any, map[string]any:
+			return false
+		}
+	}
+	return true
+}
+
+// lookupMapItem returns the value of a key in a YAML mapping, or missingKey{} when it is absent
+func lookupMapItem(items yaml.MapSlice, key string) any {
+	for _, item := range items {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return missingKey{}
+}
+
+// setFrontmatterValue sets the value at a dotted path of the merged frontmatter. A missingKey{}
+// value removes the key.
+func setFrontmatterValue(items yaml.MapSlice, path string, value any) {
+	key, rest, nested := strings.Cut(path, ".")
+	for i := range items {
+		if fmt.Sprint(items[i].Key) != key {
+			continue
+		}
+		if nested {
+			if child, ok := items[i].Value.(yaml.MapSlice); ok {
+				setFrontmatterValue(child, rest, value)
+			}
+			return
+		}
+		items[i].Value = value
+		return
+	}
+}
+
+// frontmatterValuesEqual compares YAML values regardless of the order of mapping keys
+func frontmatterValuesEqual(a, b any) bool {
+	return reflect.DeepEqual(unorderedFrontmatterValue(a), unorderedFrontmatterValue(b))
+}
+
+// unorderedFrontmatterValue converts ordered mappings into Go maps for comparison
+func unorderedFrontmatterValue(value any) any {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		result := make(map[string]any, len(v))
+		for _, item := range v {
+			result[fmt.Sprint(item.Key)] = unorderedFrontmatterValue(item.Value)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = unorderedFrontmatterValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// renderMergedFrontmatter renders the merged frontmatter. Top-level keys whose merged value is the
+// local or upstream value keep the text of that version, with its comments, block scalars and
+// quoting; only keys merged from both sides are rendered again. Returns errFrontmatterComments when
+// comments would be lost: a key merged from both sides has comments, or the side whose text is not
+// kept changed the comments of the key.
+func renderMergedFrontmatter(merged, localMap, upstreamMap yaml.MapSlice, base, local, upstream []string) ([]string, error) {
+	baseBlocks, _ := splitFrontmatterBlocks(base)
+	localBlocks, trailing := splitFrontmatterBlocks(local)
+	upstreamBlocks, _ := splitFrontmatterBlocks(upstream)
+
+	// changedComments reports whether a block that is not kept carries comments not in base
+	changedComments := func(block []string, key string) bool {
+		return !slices.Equal(block, baseBlocks[key]) && hasFrontmatterComments(block)
+	}
+
+	var lines []string
+	for _, item := range dropMissingKeys(merged) {
+		key := fmt.Sprint(item.Key)
+		localBlock, inLocal := localBlocks[key]
+		upstreamBlock, inUpstream := upstreamBlocks[key]
+		switch {
+		case inLocal && frontmatterValuesEqual(item.Value, lookupMapItem(localMap, key)) && !changedComments(upstreamBlock, key):
+			lines = append(lines, localBlock...)
+		case inUpstream && frontmatterValuesEqual(item.Value, lookupMapItem(upstreamMap, key)) && !changedComments(localBlock, key):
+			lines = append(lines, upstreamBlock...)
+		case hasFrontmatterComments(localBlock) || hasFrontmatterComments(upstreamBlock):
+			return nil, fmt.Errorf("%w: %s", errFrontmatterComments, key)
+		default:
+			rendered, err := renderFrontmatter(yaml.MapSlice{item})
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, strings.Split(rendered, "\n")...)
+		}
+	}
+	return append(lines, trailing...), nil
+}
+
+// splitFrontmatterBlocks splits frontmatter lines into the text of each top-level key, including
+// the comments and blank lines above it. Comments and blank lines after the last key are returned
+// separately.
+func splitFrontmatterBlocks(lines []string) (map[string][]string, []string) {
+	blocks := make(map[string][]string)
+	var key string
+	var pending []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(line, "#"):
+			// Attached to the next key, or to the current one if indented lines follow
+			pending = append(pending, line)
+		case line[0] != ' ' && line[0] != '\t' && line[0] != '-':
+			name, _, _ := strings.Cut(line, ":")
+			key = strings.Trim(strings.TrimSpace(name), `"'`)
+			blocks[key] = append(pending, line)
+			pending = nil
+		default:
+			blocks[key] = append(append(blocks[key], pending...), line)
+			pending = nil
+		}
+	}
+	return blocks, pending
+}
+
+// hasFrontmatterComments returns true if the YAML text has comments. Text that doesn't parse on
+// its own is assumed to have comments.
+func hasFrontmatterComments(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	comments := yaml.CommentMap{}
+	var value any
+	if err := yaml.UnmarshalWithOptions([]byte(strings.Join(lines, "\n")), &value, yaml.CommentToMap(comments)); err != nil {
+		return true
+	}
+	return len(comments) > 0
+}
+
+// renderFrontmatter renders merged frontmatter as YAML without a trailing newline. Keys removed by
+// a resolved conflict are dropped.
+func renderFrontmatter(items yaml.MapSlice) (string, error) {
+	content, err := yaml.MarshalWithOptions(dropMissingKeys(items), workflow.DefaultMarshalOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to render merged frontmatter: %w", err)
+	}
+	return strings.TrimSuffix(workflow.UnquoteYAMLKey(string(content), "on"), "\n"), nil
+}
+
+// dropMissingKeys removes the keys whose value is missingKey{}
+func dropMissingKeys(items yaml.MapSlice) yaml.MapSlice {
+	result := make(yaml.MapSlice, 0, len(items))
+	for _, item := range items {
+		switch v := item.Value.(type) {
+		case missingKey:
+			continue
+		case yaml.MapSlice:
+			item.Value = dropMissingKeys(v)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// renderConflictMarkers renders the three versions of a conflicting key between conflict markers,
+// using the same labels as the line-wise merge of the markdown body
+func renderConflictMarkers(conflict *FrontmatterConflict, indent string) ([]string, error) {
+	lines := []string{"<<<<<<< current (local changes)"}
+	for _, section := range []struct {
+		value  any
+		marker string
+	}{{conflict.Local, "||||||| base (original)"}, {conflict.Base, "======="}, {conflict.Upstream, ">>>>>>> new (upstream)"}} {
+		if _, absent := section.value.(missingKey); !absent {
+			rendered, err := renderFrontmatter(yaml.MapSlice{{Key: conflict.Key, Value: section.value}})
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range strings.Split(rendered, "\n") {
+				lines = append(lines, indent+line)
+			}
+		}
+		lines = append(lines, section.marker)
+	}
+	return lines, nil
+}
+
+// formatConflictValue renders a side of a conflict on one line for display
+func formatConflictValue(value any) string {
+	if _, absent := value.(missingKey); absent {
+		return "(removed)"
+	}
+	content, err := yaml.MarshalWithOptions(value, yaml.Flow(true))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,237 @@
+//go:build !integration
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frontmatterLines(content string) []string {
+	return strings.Split(strings.TrimPrefix(content, "\n"), "\n")
+}
+
+func TestMergeFrontmatterLines(t *testing.T) {
+	base := frontmatterLines(`
+on:
+  issues:
+    types: [opened]
+engine: claude
+timeout-minutes: 10
+network:
+  allowed:
+    - defaults
+    - node
+tools:
+  github:
+    toolsets: [issues]`)
+
+	// Local raises the timeout, allows python and drops node
+	local := frontmatterLines(`
+on:
+  issues:
+    types: [opened]
+engine: claude
+timeout-minutes: 20
+network:
+  allowed:
+    - defaults
+    - python
+tools:
+  github:
+    toolsets: [issues]`)
+
+	// Upstream switches engine, allows go and enables the bash tool
+	upstream := frontmatterLines(`
+on:
+  issues:
+    types: [opened]
+engine: copilot
+timeout-minutes: 10
+network:
+  allowed:
+    - defaults
+    - node
+    - go
+tools:
+  github:
+    toolsets: [issues]
+  bash: [ls]`)
+
+	merged, conflicts, err := mergeFrontmatterLines(base, local, upstream, nil)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, `on:
+  issues:
+    types: [opened]
+engine: copilot
+timeout-minutes: 20
+network:
+  allowed:
+  - defaults
+  - python
+  - go
+tools:
+  github:
+    toolsets: [issues]
+  bash: [ls]`, strings.Join(merged, "\n"), "keys taken from one side keep their text")
+}
+
+func TestMergeFrontmatterLinesKeepsTextWhenOneSideChanged(t *testing.T) {
+	base := frontmatterLines("on: push\nengine: claude")
+	local := frontmatterLines("on: push # keep\nengine: claude")
+
+	merged, conflicts, err := mergeFrontmatterLines(base, local, base, nil)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, local, merged, "comments should be kept when upstream did not change")
+}
+
+func TestMergeFrontmatterLinesConflicts(t *testing.T) {
+	base := frontmatterLines("on: push\nengine: claude\ntools:\n  github:\n    toolsets: [issues]\n    read-only: true")
+	local := frontmatterLines("on: push\nengine: copilot\ntools:\n  github:\n    toolsets: [issues]\n    read-only: false")
+	upstream := frontmatterLines("on: push\nengine: codex\ntools:\n  github:\n    toolsets: [issues]")
+
+	merged, conflicts, err := mergeFrontmatterLines(base, local, upstream, nil)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, "engine", conflicts[0].Path)
+	assert.Equal(t, "tools.github.read-only", conflicts[1].Path)
+	assert.Equal(t, `on: push
+<<<<<<< current (local changes)
+engine: copilot
+||||||| base (original)
+engine: claude
+=======
+engine: codex
+>>>>>>> new (upstream)
+tools:
+  github:
+    toolsets:
+    - issues
+<<<<<<< current (local changes)
+    read-only: false
+||||||| base (original)
+    read-only: true
+=======
+>>>>>>> new (upstream)`, strings.Join(merged, "\n"), "conflicts are marked per key, with removed keys shown as empty")
+
+	resolve := func(conflict *FrontmatterConflict) (string, error) {
+		if conflict.Path == "engine" {
+			return mergeSideUpstream, nil
+		}
+		return mergeSideLocal, nil
+	}
+	merged, conflicts, err = mergeFrontmatterLines(base, local, upstream, resolve)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "resolved conflicts are not reported")
+	assert.Equal(t, "on: push\nengine: codex\ntools:\n  github:\n    toolsets: [issues]\n    read-only: false", strings.Join(merged, "\n"))
+
+	merged, _, err = mergeFrontmatterLines(base, local, upstream, func(*FrontmatterConflict) (string, error) { return mergeSideUpstream, nil })
+	require.NoError(t, err)
+	assert.NotContains(t, strings.Join(merged, "\n"), "read-only", "choosing a side without the key removes it")
+}
+
+func TestMergeFrontmatterLinesKeepsComments(t *testing.T) {
+	base := frontmatterLines(`
+# Triage new issues
+on:
+  issues:
+    types: [opened]
+engine: claude # fast enough
+timeout-minutes: 10
+description: |
+  Triage
+  issues`)
+
+	// Local raises the timeout and explains why
+	local := frontmatterLines(`
+# Triage new issues
+on:
+  issues:
+    types: [opened]
+engine: claude # fast enough
+# Large repositories need more time
+timeout-minutes: 20
+description: |
+  Triage
+  issues`)
+
+	// Upstream changes the trigger comment and the engine
+	upstream := frontmatterLines(`
+# Triage new and reopened issues
+on:
+  issues:
+    types: [opened, reopened] # reopened issues need triage too
+engine: copilot # cheaper
+timeout-minutes: 10
+description: |
+  Triage
+  issues`)
+
+	merged, conflicts, err := mergeFrontmatterLines(base, local, upstream, nil)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, `# Triage new and reopened issues
+on:
+  issues:
+    types: [opened, reopened] # reopened issues need triage too
+engine: copilot # cheaper
+# Large repositories need more time
+timeout-minutes: 20
+description: |
+  Triage
+  issues`, strings.Join(merged, "\n"), "keys taken from one side keep their comments and block scalars")
+}
+
+func TestMergeFrontmatterLinesCommentsOnMergedKey(t *testing.T) {
+	base := frontmatterLines("on: push\nengine: claude\nnetwork:\n  allowed:\n    - defaults")
+	local := frontmatterLines("on: push\nengine: claude\nnetwork:\n  allowed:\n    - defaults\n    - python # for pip")
+	upstream := frontmatterLines("on: push\nengine: claude\nnetwork:\n  allowed:\n    - defaults\n    - node # for npm")
+
+	_, _, err := mergeFrontmatterLines(base, local, upstream, nil)
+	require.ErrorIs(t, err, errFrontmatterComments, "rendering a key merged from both sides would drop its comments")
+
+	commented := frontmatterLines("on: push\n# Claude handles our labels best\nengine: claude\nnetwork:\n  allowed:\n    - defaults")
+	switched := frontmatterLines("on: push\nengine: copilot\nnetwork:\n  allowed:\n    - defaults")
+	_, _, err = mergeFrontmatterLines(base, commented, switched, nil)
+	require.ErrorIs(t, err, errFrontmatterComments, "keeping the upstream engine would drop the local comment")
+
+	merged, hasConflicts, err := mergeWorkflowSections(
+		"---\n"+strings.Join(base, "\n")+"\n---\n\n# Body\n",
+		"---\n"+strings.Join(local, "\n")+"\n---\n\n# Body\n",
+		"---\n"+strings.Join(upstream, "\n")+"\n---\n\n# Body\n",
+		nil, false)
+	require.NoError(t, err)
+	assert.True(t, hasConflicts, "both sides appended to the list, so the line-wise merge conflicts")
+	assert.Contains(t, merged, "- python # for pip", "local comments are kept")
+	assert.Contains(t, merged, "- node # for npm", "upstream comments are kept")
+}
+
+func TestMergeScalarSets(t *testing.T) {
+	merged := mergeScalarSets(
+		[]any{"defaults", "node"},
+		[]any{"defaults", "node", "python"},
+		[]any{"defaults", "go"},
+	)
+	assert.Equal(t, []any{"defaults", "python", "go"}, merged, "additions on either side are kept and removals applied")
+}
+
+func TestMergeWorkflowContentStructuralFrontmatter(t *testing.T) {
+	body := "# Workflow\n\nDo the task.\n\n## Steps\n\n1. Read the issue\n2. Reply\n"
+	base := "---\non: push\nengine: claude\nnetwork:\n  allowed: [defaults]\n---\n\n" + body
+	current := "---\non: push\nengine: claude\nnetwork:\n  allowed: [defaults, python]\nsource: test/repo/workflow.md@v1.0.0\n---\n\n" + body + "\n## Local notes\n"
+	upstream := "---\non: push\nengine: copilot\nnetwork:\n  allowed: [defaults, node]\n---\n\n" + strings.Replace(body, "Do the task.", "Do the task carefully.", 1)
+
+	merged, hasConflicts, err := MergeWorkflowContent(base, current, upstream, "test/repo/workflow.md@v1.0.0", "v1.1.0", false)
+	require.NoError(t, err)
+	assert.False(t, hasConflicts, "merged content:\n%s", merged)
+	assert.Contains(t, merged, "engine: copilot")
+	assert.Contains(t, merged, "  - python\n  - node\n", "network.allowed is merged as a set")
+	assert.Contains(t, merged, "source: test/repo/workflow.md@v1.1.0")
+	assert.Contains(t, merged, "Do the task carefully.")
+	assert.Contains(t, merged, "## Local notes")
+}
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+var verifyCommandLog = logger.New("cli:verify_command")
+
+// Signature states reported by the verify command
+const (
+	signatureValid   = "valid"
+	signatureInvalid = "invalid"
+	signatureMissing = "missing"
+	signatureSigned  = "signed"
+)
+
+// VerifyConfig holds configuration for verify command execution
+type VerifyConfig struct {
+	Workflows   []string // Restrict to these workflow IDs (all workflows when empty)
+	WorkflowDir string   // Custom workflow directory
+	PublicKey   string   // PEM public key checking the detached signature of each lock file
+	SignKey     string   // PEM private key signing the lock files that verify
+	JSONOutput  bool
+	Verbose     bool
+}
+
+// VerifyResult is the outcome of verifying one lock file
+type VerifyResult struct {
+	Workflow    string                   `json:"workflow"`
+	LockFile    string                   `json:"lock_file"`
+	Verified    bool                     `json:"verified"`
+	Divergences []string                 `json:"divergences,omitempty"`
+	Signature   string                   `json:"signature,omitempty"` // valid, invalid, missing or signed
+	Provenance  *workflow.LockProvenance `json:"provenance,omitempty"`
+
+	lockPath string // Lock file path on disk
+}
+
+// NewVerifyCommand creates the verify command
+func NewVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [workflow]...",
+		Short: "Verify that lock files were compiled from their workflow sources",
+		Long: `Recompile workflows in memory and check that each committed lock file is byte-for-byte
+the output of compiling its source.
+
+Lock files embed a provenance block listing the compiler version (released builds only), a
+digest of every source file and the commit SHA of every pinned action. When a lock file differs
+from the recompiled output, verify uses the provenance to explain each divergence: an edited
+source, a different compiler version, a re-pinned action, or a lock file edited by hand.
+
+Lock files can also carry a detached signature in <workflow>.lock.yml.sig, in the format of
+cosign sign-blob. --public-key checks the signatures offline with a local ECDSA P-256 or Ed25519
+public key, and --sign writes signatures for the lock files that verify.
+
+` + WorkflowIDExplanation + `
+
+Examples:
+  ` + string(constants.CLIExtensionPrefix) + ` verify                               # Verify all lock files
+  ` + string(constants.CLIExtensionPrefix) + ` verify issue-triage                  # Verify one lock file
+  ` + string(constants.CLIExtensionPrefix) + ` verify --public-key cosign.pub       # Also check detached signatures
+  ` + string(constants.CLIExtensionPrefix) + ` verify --sign signing-key.pem        # Sign the lock files that verify
+  ` + string(constants.CLIExtensionPrefix) + ` verify --json                        # Output the results as JSON`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			publicKey, _ := cmd.Flags().GetString("public-key")
+			signKey, _ := cmd.Flags().GetString("sign")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+
+			return RunVerify(VerifyConfig{
+				Workflows:   args,
+				WorkflowDir: dir,
+				PublicKey:   publicKey,
+				SignKey:     signKey,
+				JSONOutput:  jsonOutput,
+				Verbose:     verbose,
+			})
+		},
+	}
+
+	cmd.Flags().StringP("dir", "d", "", "Workflow directory (default: .github/workflows)")
+	cmd.Flags().String("public-key", "", "PEM public key to check the detached signature of each lock file")
+	cmd.Flags().String("sign", "", "PEM private key to sign the lock files that verify")
+	addJSONFlag(cmd)
+
+	cmd.ValidArgsFunction = CompleteWorkflowNames
+	RegisterDirFlagCompletion(cmd, "dir")
+
+	return cmd
+}
+
+// RunVerify executes the verify command with the given configuration
+func RunVerify(config VerifyConfig) error {
+	verifyCommandLog.Printf("Running verify: workflows=%v, dir=%s", config.Workflows, config.WorkflowDir)
+
+	workflowDir := config.WorkflowDir
+	if workflowDir == "" {
+		workflowDir = getWorkflowsDir()
+	} else {
+		workflowDir = filepath.Clean(workflowDir)
+	}
+	gitRoot, err := findGitRootForPath(workflowDir)
+	if err != nil {
+		gitRoot = filepath.Dir(workflowDir)
+	}
+
+	var publicKey crypto.PublicKey
+	if config.PublicKey != "" {
+		if publicKey, err = loadSignaturePublicKey(config.PublicKey); err != nil {
+			return err
+		}
+	}
+	var signKey crypto.Signer
+	if config.SignKey != "" {
+		if signKey, err = loadSignaturePrivateKey(config.SignKey); err != nil {
+			return err
+		}
+	}
+
+	files, err := findVerifyWorkflowFiles(workflowDir, config.Workflows)
+	if err != nil {
+		return err
+	}
+
+	compiler := createAndConfigureCompiler(CompileConfig{NoEmit: true, WorkflowDir: workflowDir, Verbose: config.Verbose})
+	compiler.SetQuiet(true)
+
+	var results []*VerifyResult
+	for _, file := range files {
+		result := verifyLockFile(compiler, gitRoot, file)
+		if result == nil {
+			continue // Shared workflows have no lock file
+		}
+		if result.Verified && publicKey != nil {
+			checkLockFileSignature(result, publicKey)
+		}
+		if result.Verified && signKey != nil {
+			if err := writeLockFileSignature(result, signKey); err != nil {
+				return err
+			}
+		}
+		results = append(results, result)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Verified {
+			failed++
+		}
+	}
+
+	if config.JSONOutput {
+		if results == nil {
+			results = []*VerifyResult{}
+		}
+		jsonBytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal verify results: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		renderVerifyResults(results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lock files failed verification", failed, len(results))
+	}
+	if !config.JSONOutput {
+		fmt.Fprintln(os.Stderr, console.FormatSuccessMessage(fmt.Sprintf("Verified %d lock files", len(results))))
+	}
+	return nil
+}
+
+// findVerifyWorkflowFiles returns the workflow files to verify: the given workflows, or every
+// workflow of the directory
+func findVerifyWorkflowFiles(workflowDir string, workflows []string) ([]string, error) {
+	if len(workflows) == 0 {
+		files, err := filepath.Glob(filepath.Join(workflowDir, "*.md"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflows in %s: %w", workflowDir, err)
+		}
+		return filterWorkflowFiles(files), nil
+	}
+
+	files := make([]string, 0, len(workflows))
+	for _, workflowID := range workflows {
+		file := filepath.Join(workflowDir, normalizeWorkflowID(workflowID)+".md")
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("workflow %s not found in %s", normalizeWorkflowID(workflowID), workflowDir)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// verifyLockFile recompiles a workflow and compares the output with its committed lock file.
+// Shared workflows return a nil result.
+func verifyLockFile(compiler *workflow.Compiler, gitRoot, file string) *VerifyResult {
+	lockFile := stringutil.MarkdownToLockFile(file)
+	result := &VerifyResult{Workflow: normalizeWorkflowID(file), LockFile: lockFile, lockPath: lockFile}
+	if relPath, err := filepath.Rel(gitRoot, lockFile); err == nil && !strings.HasPrefix(relPath, "..") {
+		result.LockFile = filepath.ToSlash(relPath)
+	}
+
+	if slug := getRepositorySlugFromRemoteForPath(file); slug != "" {
+		compiler.SetRepositorySlug(slug)
+	}
+	data, recompiled, err := compileInMemory(compiler, gitRoot, file)
+	if err != nil {
+		result.Divergences = []string{fmt.Sprintf("failed to compile %s: %v", result.Workflow, err)}
+		return result
+	}
+	if data == nil {
+		return nil
+	}
+
+	committed, err := os.ReadFile(lockFile)
+	if err != nil {
+		result.Divergences = []string{fmt.Sprintf("lock file %s not found, run '%s compile %s'", result.LockFile, string(constants.CLIExtensionPrefix), result.Workflow)}
+		return result
+	}
+
+	result.Provenance = workflow.ParseLockProvenance(string(committed))
+	if bytes.Equal(committed, []byte(recompiled)) {
+		result.Verified = true
+		return result
+	}
+	result.Divergences = explainLockFileDivergence(string(committed), recompiled, result.Provenance)
+	verifyCommandLog.Printf("Lock file %s diverges: %v", result.LockFile, result.Divergences)
+	return result
+}
+
+// explainLockFileDivergence describes why a committed lock file differs from the recompiled
+// output, from their provenance blocks, followed by the first differing line
+func explainLockFileDivergence(committed, recompiled string, committedProvenance *workflow.LockProvenance) []string {
+	var divergences []string
+	if committedProvenance == nil {
+		divergences = append(divergences, "lock file has no provenance block: it was compiled by an older version or edited by hand")
+	} else if recompiledProvenance := workflow.ParseLockProvenance(recompiled); recompiledProvenance != nil {
+		divergences = append(divergences, committedProvenance.Diff(recompiledProvenance)...)
+	}
+	if len(divergences) == 0 {
+		divergences = append(divergences, "lock file content was edited after compilation")
+	}
+
+	committedLines := strings.Split(committed, "\n")
+	recompiledLines := strings.Split(recompiled, "\n")
+	for i := 0; i < max(len(committedLines), len(recompiledLines)); i++ {
+		committedLine, recompiledLine := lineAt(committedLines, i), lineAt(recompiledLines, i)
+		if committedLine != recompiledLine {
+			divergences = append(divergences, fmt.Sprintf("first difference at line %d: committed %q, recompiled %q", i+1, committedLine, recompiledLine))
+			break
+		}
+	}
+	return divergences
+}
+
+// lineAt returns the line at the given index, or an empty string past the end
+func lineAt(lines []string, index int) string {
+	if index < len(lines) {
+		return lines[index]
+	}
+	return ""
+}
+
+// checkLockFileSignature checks the detached signature of a verified lock file. A missing or
+// invalid signature fails the verification.
+func checkLockFileSignature(result *VerifyResult, key crypto.PublicKey) {
+	lockFile := result.lockPath
+	signature, err := os.ReadFile(lockFile + lockSignatureSuffix)
+	if err != nil {
+		result.Verified = false
+		result.Signature = signatureMissing
+		result.Divergences = append(result.Divergences, fmt.Sprintf("signature %s%s not found", result.LockFile, lockSignatureSuffix))
+		return
+	}
+	content, err := os.ReadFile(lockFile)
+	if err == nil {
+		err = verifyLockFileSignature(content, string(signature), key)
+	}
+	if err != nil {
+		result.Verified = false
+		result.Signature = signatureInvalid
+		result.Divergences = append(result.Divergences, err.Error())
+		return
+	}
+	result.Signature = signatureValid
+}
+
+// writeLockFileSignature writes the detached signature of a verified lock file
+func writeLockFileSignature(result *VerifyResult, key crypto.Signer) error {
+	lockFile := result.lockPath
+	content, err := os.ReadFile(lockFile)
+	if err != nil {
+		return fmt.Errorf("failed to read lock file: %w", err)
+	}
+	signature, err := signLockFile(content, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(lockFile+lockSignatureSuffix, []byte(signature+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	result.Signature = signatureSigned
+	verifyCommandLog.Printf("Signed %s", lockFile)
+	return nil
+}
+
+// renderVerifyResults prints the outcome of every lock file with its divergences
+func renderVerifyResults(results []*VerifyResult) {
+	for _, result := range results {
+		if result.Verified {
+			message := result.LockFile + " matches its source"
+			switch result.Signature {
+			case signatureValid:
+				message += " and its signature"
+			case signatureSigned:
+				message += " and was signed"
+			}
+			fmt.Fprintln(os.Stderr, console.FormatSuccessMessage(message))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, console.FormatErrorMessage(result.LockFile+" failed verification"))
+		for _, divergence := range result.Divergences {
+			fmt.Fprintf(os.Stderr, "  - %s\n", divergence)
+		}
+	}
+}
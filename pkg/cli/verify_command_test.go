@@ -0,0 +1,126 @@
+//go:build !integration
+
+package cli
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupVerifyTestRepo creates a git repository with a compiled triage workflow
+func setupVerifyTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmpDir, workflowsDir := setupWorkflowTestRepo(t)
+	t.Chdir(tmpDir)
+	_, err := CompileWorkflows(context.Background(), CompileConfig{})
+	require.NoError(t, err)
+	return tmpDir, workflowsDir
+}
+
+func TestRunVerify(t *testing.T) {
+	_, workflowsDir := setupVerifyTestRepo(t)
+	lockFile := filepath.Join(workflowsDir, "triage.lock.yml")
+
+	require.NoError(t, RunVerify(VerifyConfig{}), "a freshly compiled lock file verifies")
+
+	// A lock file edited by hand is reported with its first differing line
+	committed, err := os.ReadFile(lockFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lockFile, []byte(strings.Replace(string(committed), "permissions: {}", "permissions: write-all", 1)), 0644))
+	result := verifyLockFile(createAndConfigureCompiler(CompileConfig{NoEmit: true}), ".", filepath.Join(workflowsDir, "triage.md"))
+	require.NotNil(t, result)
+	assert.False(t, result.Verified)
+	require.Len(t, result.Divergences, 2)
+	assert.Equal(t, "lock file content was edited after compilation", result.Divergences[0])
+	assert.Contains(t, result.Divergences[1], `committed "permissions: write-all", recompiled "permissions: {}"`)
+
+	// An edited source is reported from the provenance block
+	require.NoError(t, os.WriteFile(lockFile, committed, 0644))
+	source, err := os.ReadFile(filepath.Join(workflowsDir, "triage.md"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "triage.md"), []byte(strings.Replace(string(source), "python", "node", 1)), 0644))
+	err = RunVerify(VerifyConfig{Workflows: []string{"triage"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 1 lock files failed verification")
+	result = verifyLockFile(createAndConfigureCompiler(CompileConfig{NoEmit: true}), ".", filepath.Join(workflowsDir, "triage.md"))
+	require.NotNil(t, result)
+	assert.Equal(t, "source triage.md changed since the lock file was compiled", result.Divergences[0])
+
+	err = RunVerify(VerifyConfig{Workflows: []string{"docs"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workflow docs not found")
+}
+
+func TestRunVerifySignatures(t *testing.T) {
+	tmpDir, workflowsDir := setupVerifyTestRepo(t)
+	lockFile := filepath.Join(workflowsDir, "triage.lock.yml")
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for name, key := range map[string]any{"ecdsa": ecdsaKey, "ed25519": ed25519Key} {
+		t.Run(name, func(t *testing.T) {
+			privatePath, publicPath := writeTestKeyPair(t, tmpDir, name, key)
+			_ = os.Remove(lockFile + lockSignatureSuffix)
+			require.Error(t, RunVerify(VerifyConfig{PublicKey: publicPath}), "a missing signature fails verification")
+
+			require.NoError(t, RunVerify(VerifyConfig{SignKey: privatePath}))
+			require.FileExists(t, lockFile+lockSignatureSuffix)
+			require.NoError(t, RunVerify(VerifyConfig{PublicKey: publicPath}))
+
+			signature, err := os.ReadFile(lockFile + lockSignatureSuffix)
+			require.NoError(t, err)
+			content, err := os.ReadFile(lockFile)
+			require.NoError(t, err)
+			publicKey, err := loadSignaturePublicKey(publicPath)
+			require.NoError(t, err)
+			err = verifyLockFileSignature(append(content, '\n'), string(signature), publicKey)
+			require.Error(t, err, "the signature covers the exact lock file bytes")
+			assert.Contains(t, err.Error(), "signature does not match")
+		})
+	}
+}
+
+// writeTestKeyPair writes a private key as PKCS #8 and its public key as PKIX PEM files
+func writeTestKeyPair(t *testing.T, dir, name string, key any) (string, string) {
+	t.Helper()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	var public any
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		public = k.Public()
+	case ed25519.PrivateKey:
+		public = k.Public()
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	privatePath := filepath.Join(dir, name+".key")
+	publicPath := filepath.Join(dir, name+".pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644))
+	return privatePath, publicPath
+}
+
+func TestLoadSignaturePrivateKeyEncrypted(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "cosign.key")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: []byte("x")}), 0600))
+	_, err := loadSignaturePrivateKey(keyPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is encrypted")
+}
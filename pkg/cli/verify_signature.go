@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var verifySignatureLog = logger.New("cli:verify_signature")
+
+// lockSignatureSuffix is appended to the lock file path to name its detached signature
+const lockSignatureSuffix = ".sig"
+
+// Lock file signatures are base64-encoded detached signatures of the lock file bytes, as written
+// by cosign sign-blob: ECDSA P-256 keys sign the SHA-256 digest of the file (ASN.1 encoded) and
+// Ed25519 keys sign the file itself. Keys are PEM files, so signatures can be checked offline.
+
+// loadSignaturePublicKey reads a PEM-encoded ECDSA or Ed25519 public key
+func loadSignaturePublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T in %s: use an ECDSA or Ed25519 key", key, path)
+	}
+}
+
+// loadSignaturePrivateKey reads a PEM-encoded, unencrypted ECDSA or Ed25519 private key
+func loadSignaturePrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(block.Type, "ENCRYPTED") {
+		return nil, fmt.Errorf("private key %s is encrypted: export an unencrypted PKCS #8 key", path)
+	}
+
+	var key any
+	if block.Type == "EC PRIVATE KEY" {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T in %s: use an ECDSA or Ed25519 key", key, path)
+	}
+}
+
+// readPEMFile reads the first PEM block of a file
+func readPEMFile(path string) (*pem.Block, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
+
+// signLockFile returns the base64-encoded detached signature of the lock file content
+func signLockFile(content []byte, key crypto.Signer) (string, error) {
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, content)
+	default:
+		digest := sha256.Sum256(content)
+		signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign lock file: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// verifyLockFileSignature checks a base64-encoded detached signature of the lock file content
+func verifyLockFileSignature(content []byte, encodedSignature string, key crypto.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedSignature))
+	if err != nil {
+		return fmt.Errorf("signature is not valid base64: %w", err)
+	}
+
+	valid := false
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, content, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(content)
+		valid = ecdsa.VerifyASN1(k, digest[:], signature)
+	}
+	verifySignatureLog.Printf("Verified %T signature: valid=%v", key, valid)
+	if !valid {
+		return errors.New("signature does not match the lock file")
+	}
+	return nil
+}
@@ -288,6 +288,21 @@ func parseAndDisplayZizmorOutput(stdout, stderr string, verbose bool) (int, erro
 				}
 
 				fmt.Fprint(os.Stderr, console.FormatError(compilerErr))
+
+				sarifMessage := desc
+				if annotation := loc.Symbolic.Annotation; annotation != "" {
+					sarifMessage = fmt.Sprintf("%s: %s", desc, annotation)
+				}
+				recordSARIFFinding(sarifFinding{
+					RuleID:      "zizmor/" + ident,
+					Description: desc,
+					HelpURI:     url,
+					Level:       sarifLevel(errorType),
+					Message:     sarifMessage,
+					File:        filePath,
+					Line:        lineNum,
+					Column:      colNum,
+				})
 			}
 		}
 	}
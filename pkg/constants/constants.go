@@ -633,6 +633,8 @@ const (
 	CodexEngine EngineName = "codex"
 	// CustomEngine is the custom engine identifier
 	CustomEngine EngineName = "custom"
+	// ReplayEngine is the recorded session replay engine identifier
+	ReplayEngine EngineName = "replay"
 )
 
 // AgenticEngines lists all supported agentic engine names
@@ -825,6 +827,18 @@ func GetWorkflowDir() string {
 	return filepath.Join(".github", "workflows")
 }
 
+// GetEngineDefinitionsDir returns the directory, relative to the repository root, from which
+// declarative engine definitions are loaded
+func GetEngineDefinitionsDir() string {
+	return filepath.Join(".github", "aw", "engines")
+}
+
+// GetPolicyFilePath returns the path, relative to the repository root, of the organization policy
+// file enforced at compile time
+func GetPolicyFilePath() string {
+	return filepath.Join(".github", "aw", "policy.yml")
+}
+
 // DefaultAllowedMemoryExtensions is the default list of allowed file extensions for cache-memory and repo-memory storage.
 // An empty slice means all file extensions are allowed. When this is empty, the validation step is not emitted.
 var DefaultAllowedMemoryExtensions = []string{}
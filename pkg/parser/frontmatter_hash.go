@@ -286,6 +286,29 @@ func ComputeFrontmatterHashFromFileWithReader(filePath string, cache *ImportCach
 	return computeFrontmatterHashTextBasedWithReader(frontmatterText, markdown, baseDir, cache, relevantExpressions, fileReader)
 }
 
+// ComputeSourceDigest computes a SHA-256 digest of the parts of a single source file that the
+// compiler reads. For markdown files these are the normalized frontmatter and the template
+// expressions of the body, since markdown bodies are loaded at runtime; other files (such as YAML
+// imports) are covered whole, with line endings normalized. Unlike the frontmatter hash, imports
+// are not followed. The digest is returned as "sha256:<hex>".
+func ComputeSourceDigest(filePath string, content string) string {
+	digestInput := strings.ReplaceAll(content, "\r\n", "\n")
+	if strings.EqualFold(filepath.Ext(filePath), ".md") {
+		if frontmatterText, markdown, err := extractFrontmatterAndBodyText(digestInput); err == nil {
+			var sb strings.Builder
+			sb.WriteString(normalizeFrontmatterText(frontmatterText))
+			for _, expression := range extractRelevantTemplateExpressions(markdown) {
+				sb.WriteString("\n")
+				sb.WriteString(expression)
+			}
+			digestInput = sb.String()
+		}
+	}
+
+	hash := sha256.Sum256([]byte(digestInput))
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
 // ComputeFrontmatterHashWithExpressions computes the hash including template expressions
 func ComputeFrontmatterHashWithExpressions(frontmatter map[string]any, baseDir string, cache *ImportCache, expressions []string) (string, error) {
 	frontmatterHashLog.Print("Computing frontmatter hash with template expressions")
@@ -5,6 +5,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -450,3 +451,19 @@ tools:
 	assert.Len(t, hash, 64, "Hash should be 64 characters")
 	assert.Regexp(t, "^[a-f0-9]{64}$", hash, "Hash should be lowercase hex")
 }
+
+func TestComputeSourceDigest(t *testing.T) {
+	content := "---\non: push\nengine: copilot\n---\n\n# Task\n\nTriage the issue.\n"
+
+	digest := ComputeSourceDigest("triage.md", content)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, digest)
+
+	assert.Equal(t, digest, ComputeSourceDigest("triage.md", strings.ReplaceAll(content, "\n", "\r\n")), "Line endings are normalized")
+	assert.Equal(t, digest, ComputeSourceDigest("triage.md", strings.Replace(content, "Triage the issue.", "Label the issue.", 1)), "Markdown text is loaded at runtime and not part of the digest")
+	assert.NotEqual(t, digest, ComputeSourceDigest("triage.md", strings.Replace(content, "copilot", "claude", 1)), "Frontmatter changes the digest")
+	assert.NotEqual(t, digest, ComputeSourceDigest("triage.md", content+"Run ${{ env.MODE }}.\n"), "Template expressions change the digest")
+
+	yamlImport := "tools:\n  bash: [ls]\n"
+	assert.NotEqual(t, ComputeSourceDigest("shared/tools.yml", yamlImport), ComputeSourceDigest("shared/tools.yml", "tools:\n  bash: [ls, cat]\n"), "YAML imports are covered whole")
+	assert.Equal(t, ComputeSourceDigest("shared/tools.yml", yamlImport), ComputeSourceDigest("shared/tools.yml", strings.ReplaceAll(yamlImport, "\n", "\r\n")), "Line endings are normalized")
+}
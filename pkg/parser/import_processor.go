@@ -39,11 +39,20 @@ type ImportsResult struct {
 	AgentFile           string   // Path to custom agent file (if imported)
 	AgentImportSpec     string   // Original import specification for agent file (e.g., "owner/repo/path@ref")
 	RepositoryImports   []string // List of repository imports (format: "owner/repo@ref") for .github folder merging
+	EngineDefinitions   []string // Paths of imported engine definition files (see .github/aw/engines)
 	// ImportInputs uses map[string]any because input values can be different types (string, number, boolean).
 	// This is parsed from YAML frontmatter where the structure is dynamic and not known at compile time.
 	// This is an appropriate use of 'any' for dynamic YAML/JSON data.
 	// See scratchpad/go-type-patterns.md for guidance on when to use map[string]any.
-	ImportInputs map[string]any // Aggregated input values from all imports (key = input name, value = input value)
+	ImportInputs      map[string]any     // Aggregated input values from all imports (key = input name, value = input value)
+	ImportsWithInputs []ImportWithInputs // Imports that pass inputs, in processing order
+}
+
+// ImportWithInputs is an import that passes input values to the imported workflow
+type ImportWithInputs struct {
+	ImportPath string         // Import path as written in the importing workflow
+	FullPath   string         // Resolved path of the imported file
+	Inputs     map[string]any // Input values passed to the imported workflow
 }
 
 // ImportInputDefinition defines an input parameter for a shared workflow import.
@@ -192,7 +201,9 @@ func processImportsFromFrontmatterWithManifestAndSource(frontmatter map[string]a
 	var agentFile string                 // Track custom agent file
 	var agentImportSpec string           // Track agent import specification for remote imports
 	var repositoryImports []string       // Track repository-only imports for .github folder merging
+	var engineDefinitions []string       // Track imported engine definition files
 	importInputs := make(map[string]any) // Aggregated input values from all imports
+	var importsWithInputs []ImportWithInputs
 
 	// Seed the queue with initial imports
 	for _, importSpec := range importSpecs {
@@ -279,6 +290,9 @@ func processImportsFromFrontmatterWithManifestAndSource(frontmatter map[string]a
 		for k, v := range item.inputs {
 			importInputs[k] = v
 		}
+		if len(item.inputs) > 0 {
+			importsWithInputs = append(importsWithInputs, ImportWithInputs{ImportPath: item.importPath, FullPath: item.fullPath, Inputs: item.inputs})
+		}
 
 		// Add to processing order
 		processedOrder = append(processedOrder, item.importPath)
@@ -342,6 +356,13 @@ func processImportsFromFrontmatterWithManifestAndSource(frontmatter map[string]a
 
 		// Check if this is a YAML workflow file (not .lock.yml)
 		if isYAMLWorkflowFile(item.fullPath) {
+			// Engine definitions are YAML files too, but declare an engine instead of jobs
+			if isEngineDefinitionFile(item.fullPath) {
+				log.Printf("Detected engine definition file: %s", item.fullPath)
+				engineDefinitions = append(engineDefinitions, item.fullPath)
+				continue
+			}
+
 			log.Printf("Detected YAML workflow file: %s", item.fullPath)
 
 			// Process YAML workflow import to extract jobs/steps and services
@@ -671,7 +692,9 @@ func processImportsFromFrontmatterWithManifestAndSource(frontmatter map[string]a
 		AgentFile:           agentFile,
 		AgentImportSpec:     agentImportSpec,
 		RepositoryImports:   repositoryImports,
+		EngineDefinitions:   engineDefinitions,
 		ImportInputs:        importInputs,
+		ImportsWithInputs:   importsWithInputs,
 	}, nil
 }
 
@@ -138,6 +138,10 @@ func ExtractMCPConfigurations(frontmatter map[string]any, serverFilter string) (
 						config.Allowed = append(config.Allowed, "create-pull-request-review-comment")
 					case "create-code-scanning-alert":
 						config.Allowed = append(config.Allowed, "create-code-scanning-alert")
+					case "create-check-run":
+						config.Allowed = append(config.Allowed, "create-check-run")
+					case "notify-webhook":
+						config.Allowed = append(config.Allowed, "notify-webhook")
 					case "add-labels":
 						config.Allowed = append(config.Allowed, "add-labels")
 					case "update-issue":
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var scheduleCronRunsLog = logger.New("parser:schedule_cron_runs")
+
+// CronRunsBetween returns the times in [from, to) at which a five-field cron expression fires.
+// Cron expressions are evaluated in UTC, like GitHub Actions schedules. When both day-of-month
+// and day-of-week are restricted, a day matches if either field matches (standard cron semantics).
+func CronRunsBetween(cron string, from, to time.Time) ([]time.Time, error) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s': must have exactly 5 fields", cron)
+	}
+
+	minutes, okMinutes := expandCronField(fields[0], 0, 59)
+	hours, okHours := expandCronField(fields[1], 0, 23)
+	daysOfMonth, okDays := expandCronField(fields[2], 1, 31)
+	months, okMonths := expandCronField(fields[3], 1, 12)
+	weekdayValues, okWeekdays := expandCronField(fields[4], 0, 7)
+	if !okMinutes || !okHours || !okDays || !okMonths || !okWeekdays {
+		return nil, fmt.Errorf("unsupported cron expression '%s'", cron)
+	}
+
+	monthSet := toSet(months)
+	domSet := toSet(daysOfMonth)
+	dowSet := make(map[int]bool)
+	for _, day := range weekdayValues {
+		dowSet[day%7] = true // 7 is an alias for Sunday
+	}
+	domRestricted := fields[2] != "*"
+	dowRestricted := fields[4] != "*"
+
+	from, to = from.UTC(), to.UTC()
+	var runs []time.Time
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !monthSet[int(day.Month())] {
+			continue
+		}
+		domMatch := domSet[day.Day()]
+		dowMatch := dowSet[int(day.Weekday())]
+		matches := domMatch && dowMatch
+		if domRestricted != dowRestricted {
+			matches = (domRestricted && domMatch) || (dowRestricted && dowMatch)
+		} else if domRestricted {
+			matches = domMatch || dowMatch
+		}
+		if !matches {
+			continue
+		}
+
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				run := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+				if !run.Before(from) && run.Before(to) {
+					runs = append(runs, run)
+				}
+			}
+		}
+	}
+
+	scheduleCronRunsLog.Printf("Cron %s fires %d times between %s and %s", cron, len(runs), from.Format(time.RFC3339), to.Format(time.RFC3339))
+	return runs, nil
+}
+
+// toSet converts a list of values to a lookup set
+func toSet(values []int) map[int]bool {
+	set := make(map[int]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,60 @@
+//go:build !integration
+
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronRunsBetween(t *testing.T) {
+	// Monday 2026-03-02 00:00 UTC
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name     string
+		cron     string
+		expected int
+		first    time.Time
+	}{
+		{name: "daily", cron: "30 9 * * *", expected: 7, first: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)},
+		{name: "weekdays", cron: "0 14 * * 1-5", expected: 5, first: time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)},
+		{name: "sunday alias", cron: "0 0 * * 7", expected: 1, first: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{name: "every six hours", cron: "0 */6 * * *", expected: 28, first: from},
+		{name: "day of month or weekday", cron: "0 12 5 * 1", expected: 2, first: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)},
+		{name: "other month", cron: "0 12 * 4 *", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := CronRunsBetween(tt.cron, from, to)
+			require.NoError(t, err)
+			assert.Len(t, runs, tt.expected)
+			if tt.expected > 0 {
+				assert.Equal(t, tt.first, runs[0])
+			}
+			for _, run := range runs {
+				assert.False(t, run.Before(from) || !run.Before(to), "run %s should be in range", run)
+			}
+		})
+	}
+}
+
+func TestCronRunsBetweenPartialRange(t *testing.T) {
+	from := time.Date(2026, 3, 2, 9, 31, 0, 0, time.UTC)
+	runs, err := CronRunsBetween("30 9 * * *", from, from.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2026, 3, 3, 9, 30, 0, 0, time.UTC)}, runs)
+}
+
+func TestCronRunsBetweenInvalid(t *testing.T) {
+	from := time.Now()
+	for _, cron := range []string{"0 9 * *", "61 9 * * *", "daily"} {
+		_, err := CronRunsBetween(cron, from, from.Add(time.Hour))
+		assert.Error(t, err, "cron %q should be rejected", cron)
+	}
+}
@@ -0,0 +1,412 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var scheduleFuzzyPlanLog = logger.New("parser:schedule_fuzzy_plan")
+
+// This file contains the combined scattering pass for fuzzy schedules.
+//
+// ScatterSchedule places each workflow independently by hashing its identifier, so
+// repositories with many daily workflows still see several runs land in the same
+// 15-minute window. PlanScatteredSchedules looks at a set of fuzzy schedules
+// together (plus the fixed cron schedules next to them) and places each one in the
+// least loaded window its fuzzy expression allows. Ties are broken by the distance
+// to the hash-based window, and within the chosen window the hash-based minute is
+// kept, so a workflow without competition keeps exactly the time ScatterSchedule
+// would give it, moved workflows do not pile up on window edges, and the plan is
+// deterministic for a given set of workflows.
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+
+	// ScheduleBucketMinutes is the size of the windows used to measure schedule load
+	ScheduleBucketMinutes = 15
+
+	scheduleBucketsPerWeek = minutesPerWeek / ScheduleBucketMinutes
+)
+
+// FuzzyScheduleRequest is one fuzzy schedule to place in a combined scattering pass
+type FuzzyScheduleRequest struct {
+	FuzzyCron string // Fuzzy cron expression from ParseSchedule (e.g. "FUZZY:DAILY * * *")
+	Seed      string // Scattering seed, as passed to ScatterSchedule
+}
+
+// scatterWindow describes the candidate times of a fuzzy schedule
+type scatterWindow struct {
+	size      int                  // Number of candidate positions
+	preferred int                  // Position chosen by ScatterSchedule
+	weight    float64              // Runs per occurrence and week
+	cron      func(pos int) string // Cron expression for a position
+	minutes   func(pos int) []int  // Minutes of the week (0 = Sunday 00:00 UTC) at which a position runs
+}
+
+// PlanScatteredSchedules scatters a set of fuzzy schedules together, spreading them evenly over
+// the 15-minute windows of the week while honoring the window each fuzzy expression allows.
+// fixedCrons are the repository's other cron schedules; they are counted as existing load.
+// The returned cron expressions are in the same order as the requests.
+func PlanScatteredSchedules(requests []FuzzyScheduleRequest, fixedCrons []string) ([]string, error) {
+	scheduleFuzzyPlanLog.Printf("Planning %d fuzzy schedules with %d fixed schedules", len(requests), len(fixedCrons))
+
+	load := make([]float64, scheduleBucketsPerWeek)
+	for _, cron := range fixedCrons {
+		occurrences, ok := CronWeeklyOccurrences(cron)
+		if !ok {
+			scheduleFuzzyPlanLog.Printf("Ignoring unsupported fixed cron in load: %s", cron)
+			continue
+		}
+		for minute, weight := range occurrences {
+			load[minute/ScheduleBucketMinutes] += weight
+		}
+	}
+
+	windows := make([]*scatterWindow, len(requests))
+	for i, request := range requests {
+		window, err := fuzzyScatterWindow(request.FuzzyCron, request.Seed)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = window
+	}
+
+	// Place the most constrained schedules first so that wide windows fill the gaps
+	order := make([]int, len(requests))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		wa, wb := windows[order[a]], windows[order[b]]
+		if wa.size != wb.size {
+			return wa.size < wb.size
+		}
+		ra, rb := requests[order[a]], requests[order[b]]
+		if ra.Seed != rb.Seed {
+			return ra.Seed < rb.Seed
+		}
+		return ra.FuzzyCron < rb.FuzzyCron
+	})
+
+	results := make([]string, len(requests))
+	for _, i := range order {
+		window := windows[i]
+		preferredMinute := window.minutes(window.preferred)[0]
+		best, bestCost, bestDistance, bestOffset := -1, math.MaxFloat64, 0, 0
+		for pos := 0; pos < window.size; pos++ {
+			minutes := window.minutes(pos)
+			cost := 0.0
+			for _, minute := range minutes {
+				cost += load[minute/ScheduleBucketMinutes] * window.weight
+			}
+			// Prefer the nearest bucket to the hash-based one, then the hash-based minute within it.
+			// Positions are consecutive minutes, so the bucket distance follows from the position
+			// distance, also for windows that wrap around midnight.
+			shift := preferredMinute%ScheduleBucketMinutes + pos - window.preferred
+			distance := absInt(floorDiv(shift, ScheduleBucketMinutes))
+			offset := absInt(minutes[0]%ScheduleBucketMinutes - preferredMinute%ScheduleBucketMinutes)
+			tied := math.Abs(cost-bestCost) <= 1e-9
+			if best == -1 || cost < bestCost-1e-9 || (tied && (distance < bestDistance || (distance == bestDistance && offset < bestOffset))) {
+				best, bestCost, bestDistance, bestOffset = pos, cost, distance, offset
+			}
+		}
+
+		for _, minute := range window.minutes(best) {
+			load[minute/ScheduleBucketMinutes] += window.weight
+		}
+		results[i] = window.cron(best)
+		scheduleFuzzyPlanLog.Printf("Planned %s for seed %s: %s (hash position %d, chosen %d)", requests[i].FuzzyCron, requests[i].Seed, results[i], window.preferred, best)
+	}
+
+	return results, nil
+}
+
+// absInt returns the absolute value of n
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// floorDiv divides a by b (b > 0), rounding towards negative infinity
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+// fuzzyScatterWindow returns the candidate times of a fuzzy cron expression. The preferred
+// position is the one ScatterSchedule picks for the seed, so the hash-based placement is
+// computed in one place only; an error is returned if that time is not part of the window.
+func fuzzyScatterWindow(fuzzyCron, seed string) (*scatterWindow, error) {
+	window, err := fuzzyCandidateWindow(fuzzyCron)
+	if err != nil {
+		return nil, err
+	}
+	scattered, err := ScatterSchedule(fuzzyCron, seed)
+	if err != nil {
+		return nil, err
+	}
+	for pos := 0; pos < window.size; pos++ {
+		if window.cron(pos) == scattered {
+			window.preferred = pos
+			return window, nil
+		}
+	}
+	return nil, fmt.Errorf("scattered schedule %q is outside the window of %s", scattered, fuzzyCron)
+}
+
+// fuzzyCandidateWindow returns the window of candidate times a fuzzy cron expression allows
+func fuzzyCandidateWindow(fuzzyCron string) (*scatterWindow, error) {
+	if !IsFuzzyCron(fuzzyCron) {
+		return nil, fmt.Errorf("not a fuzzy schedule: %s", fuzzyCron)
+	}
+	spec := strings.Fields(fuzzyCron)[0]
+
+	switch {
+	case strings.HasPrefix(spec, "FUZZY:DAILY_AROUND:"):
+		values, err := parseFuzzyTimeFields(fuzzyCron, strings.TrimPrefix(spec, "FUZZY:DAILY_AROUND:"), 2)
+		if err != nil {
+			return nil, err
+		}
+		return dailyScatterWindow(values[0]*60+values[1]-60, 120, "*"), nil
+
+	case strings.HasPrefix(spec, "FUZZY:DAILY_BETWEEN:"):
+		values, err := parseFuzzyTimeFields(fuzzyCron, strings.TrimPrefix(spec, "FUZZY:DAILY_BETWEEN:"), 4)
+		if err != nil {
+			return nil, err
+		}
+		start, end := values[0]*60+values[1], values[2]*60+values[3]
+		size := end - start
+		if end <= start {
+			size = minutesPerDay - start + end
+		}
+		return dailyScatterWindow(start, size, "*"), nil
+
+	case spec == "FUZZY:DAILY":
+		return dailyScatterWindow(0, minutesPerDay, "*"), nil
+
+	case strings.HasPrefix(spec, "FUZZY:HOURLY/"):
+		interval, err := strconv.Atoi(strings.TrimPrefix(spec, "FUZZY:HOURLY/"))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid interval in fuzzy hourly pattern: %s", fuzzyCron)
+		}
+		return &scatterWindow{
+			size:   60,
+			weight: 1,
+			cron:   func(pos int) string { return fmt.Sprintf("%d */%d * * *", pos, interval) },
+			minutes: func(pos int) []int {
+				var minutes []int
+				for day := 0; day < 7; day++ {
+					for hour := 0; hour < 24; hour += interval {
+						minutes = append(minutes, day*minutesPerDay+hour*60+pos)
+					}
+				}
+				return minutes
+			},
+		}, nil
+
+	case strings.HasPrefix(spec, "FUZZY:WEEKLY_AROUND:"):
+		parts := strings.SplitN(strings.TrimPrefix(spec, "FUZZY:WEEKLY_AROUND:"), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid format in fuzzy weekly around pattern: %s", fuzzyCron)
+		}
+		values, err := parseFuzzyTimeFields(fuzzyCron, parts[1], 2)
+		if err != nil {
+			return nil, err
+		}
+		return dailyScatterWindow(values[0]*60+values[1]-60, 120, parts[0]), nil
+
+	case strings.HasPrefix(spec, "FUZZY:WEEKLY:"):
+		return dailyScatterWindow(0, minutesPerDay, strings.TrimPrefix(spec, "FUZZY:WEEKLY:")), nil
+
+	case spec == "FUZZY:WEEKLY":
+		return &scatterWindow{
+			size:   minutesPerWeek,
+			weight: 1,
+			cron: func(pos int) string {
+				minute := pos % minutesPerDay
+				return fmt.Sprintf("%d %d * * %d", minute%60, minute/60, pos/minutesPerDay)
+			},
+			minutes: func(pos int) []int { return []int{pos} },
+		}, nil
+
+	case spec == "FUZZY:BI_WEEKLY":
+		return intervalScatterWindow(14), nil
+
+	case spec == "FUZZY:TRI_WEEKLY":
+		return intervalScatterWindow(21), nil
+	}
+
+	return nil, fmt.Errorf("unsupported fuzzy schedule type: %s", fuzzyCron)
+}
+
+// parseFuzzyTimeFields parses count colon-separated HH:MM values of a fuzzy expression
+func parseFuzzyTimeFields(fuzzyCron, fields string, count int) ([]int, error) {
+	parts := strings.Split(fields, ":")
+	if len(parts) != count {
+		return nil, fmt.Errorf("invalid time format in fuzzy pattern: %s", fuzzyCron)
+	}
+	values := make([]int, count)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		limit := 59
+		if i%2 == 0 {
+			limit = 23
+		}
+		if err != nil || value < 0 || value > limit {
+			return nil, fmt.Errorf("invalid time in fuzzy pattern: %s", fuzzyCron)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// dailyScatterWindow returns a window of size minutes starting at start (minutes since
+// midnight, wrapping around) on every day, or on one weekday when dow is not "*"
+func dailyScatterWindow(start, size int, dow string) *scatterWindow {
+	minuteOfDay := func(pos int) int {
+		return ((start+pos)%minutesPerDay + minutesPerDay) % minutesPerDay
+	}
+	days := []int{0, 1, 2, 3, 4, 5, 6}
+	if dow != "*" {
+		if day, err := strconv.Atoi(dow); err == nil {
+			days = []int{day % 7}
+		}
+	}
+	return &scatterWindow{
+		size:   size,
+		weight: 1,
+		cron: func(pos int) string {
+			minute := minuteOfDay(pos)
+			return fmt.Sprintf("%d %d * * %s", minute%60, minute/60, dow)
+		},
+		minutes: func(pos int) []int {
+			minutes := make([]int, 0, len(days))
+			for _, day := range days {
+				minutes = append(minutes, day*minutesPerDay+minuteOfDay(pos))
+			}
+			return minutes
+		},
+	}
+}
+
+// intervalScatterWindow returns the window of schedules that run every N days
+func intervalScatterWindow(days int) *scatterWindow {
+	return &scatterWindow{
+		size:   minutesPerDay,
+		weight: 1 / float64(days),
+		cron:   func(pos int) string { return fmt.Sprintf("%d %d */%d * *", pos%60, pos/60, days) },
+		minutes: func(pos int) []int {
+			minutes := make([]int, 0, 7)
+			for day := 0; day < 7; day++ {
+				minutes = append(minutes, day*minutesPerDay+pos)
+			}
+			return minutes
+		},
+	}
+}
+
+// CronWeeklyOccurrences returns the minutes of the week (0 = Sunday 00:00 UTC) at which a
+// five-field cron expression runs, weighted by how many of those runs happen in an average
+// week. Day-of-month and month restrictions lower the weight instead of picking dates.
+// Returns false if the expression cannot be expanded.
+func CronWeeklyOccurrences(cron string) (map[int]float64, bool) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, false
+	}
+
+	minutes, ok := expandCronField(fields[0], 0, 59)
+	if !ok {
+		return nil, false
+	}
+	hours, ok := expandCronField(fields[1], 0, 23)
+	if !ok {
+		return nil, false
+	}
+	daysOfMonth, ok := expandCronField(fields[2], 1, 31)
+	if !ok {
+		return nil, false
+	}
+	months, ok := expandCronField(fields[3], 1, 12)
+	if !ok {
+		return nil, false
+	}
+	weekdayValues, ok := expandCronField(fields[4], 0, 7)
+	if !ok {
+		return nil, false
+	}
+	weekdays := make(map[int]bool)
+	for _, day := range weekdayValues {
+		weekdays[day%7] = true // 7 is an alias for Sunday
+	}
+
+	weight := float64(len(months)) / 12
+	if fields[4] == "*" {
+		weight *= float64(len(daysOfMonth)) / 31
+	}
+
+	occurrences := make(map[int]float64)
+	for day := range weekdays {
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				occurrences[day*minutesPerDay+hour*60+minute] += weight
+			}
+		}
+	}
+	return occurrences, true
+}
+
+// expandCronField expands one cron field (*, N, A-B, */S, A-B/S, and comma lists) to its values
+func expandCronField(field string, minValue, maxValue int) ([]int, bool) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, found := strings.Cut(part, "/"); found {
+			value, err := strconv.Atoi(stepPart)
+			if err != nil || value <= 0 {
+				return nil, false
+			}
+			part, step = rangePart, value
+		}
+
+		low, high := minValue, maxValue
+		if part != "*" {
+			lowStr, highStr, isRange := strings.Cut(part, "-")
+			var err error
+			if low, err = strconv.Atoi(lowStr); err != nil {
+				return nil, false
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highStr); err != nil {
+					return nil, false
+				}
+			} else if step > 1 {
+				high = maxValue
+			}
+		}
+		if low < minValue || high > maxValue || low > high {
+			return nil, false
+		}
+		for value := low; value <= high; value += step {
+			seen[value] = true
+		}
+	}
+
+	values := make([]int, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	return values, true
+}
@@ -0,0 +1,164 @@
+//go:build !integration
+
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanScatteredSchedulesMatchesScatterScheduleWithoutContention(t *testing.T) {
+	fuzzyCrons := []string{
+		"FUZZY:DAILY * * *",
+		"FUZZY:DAILY_AROUND:0:30 * * *",
+		"FUZZY:DAILY_BETWEEN:22:00:02:00 * * *",
+		"FUZZY:HOURLY/4 * * *",
+		"FUZZY:WEEKLY * * *",
+		"FUZZY:WEEKLY:1 * * *",
+		"FUZZY:WEEKLY_AROUND:5:09:00 * * *",
+		"FUZZY:BI_WEEKLY * * *",
+		"FUZZY:TRI_WEEKLY * * *",
+	}
+
+	for _, fuzzyCron := range fuzzyCrons {
+		t.Run(fuzzyCron, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				seed := fmt.Sprintf("owner/repo/.github/workflows/workflow-%d.md", i)
+				expected, err := ScatterSchedule(fuzzyCron, seed)
+				require.NoError(t, err)
+
+				planned, err := PlanScatteredSchedules([]FuzzyScheduleRequest{{FuzzyCron: fuzzyCron, Seed: seed}}, nil)
+				require.NoError(t, err)
+				assert.Equal(t, []string{expected}, planned, "a schedule without competition should keep its hash-based time (seed %s)", seed)
+			}
+		})
+	}
+}
+
+func TestPlanScatteredSchedulesSpreadsDailyWorkflows(t *testing.T) {
+	var requests []FuzzyScheduleRequest
+	for i := 0; i < 48; i++ {
+		requests = append(requests, FuzzyScheduleRequest{FuzzyCron: "FUZZY:DAILY * * *", Seed: fmt.Sprintf("owner/repo/.github/workflows/workflow-%d.md", i)})
+	}
+
+	planned, err := PlanScatteredSchedules(requests, nil)
+	require.NoError(t, err)
+	require.Len(t, planned, len(requests))
+
+	buckets := make(map[int]int)
+	for _, cron := range planned {
+		occurrences, ok := CronWeeklyOccurrences(cron)
+		require.True(t, ok, "planned cron should be expandable: %s", cron)
+		for minute := range occurrences {
+			if minute < minutesPerDay {
+				buckets[minute/ScheduleBucketMinutes]++
+			}
+		}
+	}
+	for bucket, count := range buckets {
+		assert.Equal(t, 1, count, "15-minute window %d should hold a single run", bucket)
+	}
+
+	again, err := PlanScatteredSchedules(requests, nil)
+	require.NoError(t, err)
+	assert.Equal(t, planned, again, "plan should be deterministic")
+}
+
+func TestPlanScatteredSchedulesKeepsHashMinuteInBucket(t *testing.T) {
+	seed := "owner/repo/.github/workflows/daily-report.md"
+	expected, err := ScatterSchedule("FUZZY:DAILY * * *", seed)
+	require.NoError(t, err)
+	expectedMinutes, ok := CronWeeklyOccurrences(expected)
+	require.True(t, ok)
+	preferred := minutesPerDay
+	for minute := range expectedMinutes {
+		preferred = min(preferred, minute)
+	}
+
+	// A fixed schedule occupies the hash-based window, so the workflow moves to another one
+	fixed := fmt.Sprintf("%d %d * * *", preferred%60, preferred/60)
+	planned, err := PlanScatteredSchedules([]FuzzyScheduleRequest{{FuzzyCron: "FUZZY:DAILY * * *", Seed: seed}}, []string{fixed})
+	require.NoError(t, err)
+	plannedMinutes, ok := CronWeeklyOccurrences(planned[0])
+	require.True(t, ok)
+	moved := minutesPerDay
+	for minute := range plannedMinutes {
+		moved = min(moved, minute)
+	}
+
+	assert.NotEqual(t, preferred/ScheduleBucketMinutes, moved/ScheduleBucketMinutes, "schedule should leave the occupied window")
+	assert.Equal(t, 1, absInt(moved/ScheduleBucketMinutes-preferred/ScheduleBucketMinutes), "schedule should move to the nearest free window")
+	assert.Equal(t, preferred%ScheduleBucketMinutes, moved%ScheduleBucketMinutes, "schedule should keep its hash-based minute within the window")
+}
+
+func TestPlanScatteredSchedulesRespectsWindows(t *testing.T) {
+	requests := []FuzzyScheduleRequest{
+		{FuzzyCron: "FUZZY:DAILY_AROUND:09:00 * * *", Seed: "a"},
+		{FuzzyCron: "FUZZY:DAILY_AROUND:09:00 * * *", Seed: "b"},
+		{FuzzyCron: "FUZZY:DAILY_AROUND:09:00 * * *", Seed: "c"},
+	}
+	// An existing fixed schedule occupies the 09:00 window
+	planned, err := PlanScatteredSchedules(requests, []string{"0 9 * * *"})
+	require.NoError(t, err)
+
+	seen := make(map[int]bool)
+	for _, cron := range planned {
+		occurrences, ok := CronWeeklyOccurrences(cron)
+		require.True(t, ok)
+		for minute := range occurrences {
+			if minute >= minutesPerDay {
+				continue
+			}
+			assert.GreaterOrEqual(t, minute, 8*60, "%s should stay within an hour of 09:00", cron)
+			assert.Less(t, minute, 10*60, "%s should stay within an hour of 09:00", cron)
+			bucket := minute / ScheduleBucketMinutes
+			assert.NotEqual(t, 9*60/ScheduleBucketMinutes, bucket, "%s should avoid the window used by the fixed schedule", cron)
+			assert.False(t, seen[bucket], "%s should not share a window", cron)
+			seen[bucket] = true
+		}
+	}
+}
+
+func TestPlanScatteredSchedulesInvalid(t *testing.T) {
+	_, err := PlanScatteredSchedules([]FuzzyScheduleRequest{{FuzzyCron: "0 9 * * *", Seed: "a"}}, nil)
+	require.Error(t, err)
+	_, err = PlanScatteredSchedules([]FuzzyScheduleRequest{{FuzzyCron: "FUZZY:UNKNOWN * * *", Seed: "a"}}, nil)
+	require.Error(t, err)
+}
+
+func TestCronWeeklyOccurrences(t *testing.T) {
+	tests := []struct {
+		cron        string
+		occurrences map[int]float64
+		ok          bool
+	}{
+		{cron: "30 9 * * 1", occurrences: map[int]float64{1*minutesPerDay + 9*60 + 30: 1}, ok: true},
+		{cron: "0 */12 * * 0", occurrences: map[int]float64{0: 1, 12 * 60: 1}, ok: true},
+		{cron: "0 0 * * 1-7", occurrences: map[int]float64{0: 1, 1 * minutesPerDay: 1, 2 * minutesPerDay: 1, 3 * minutesPerDay: 1, 4 * minutesPerDay: 1, 5 * minutesPerDay: 1, 6 * minutesPerDay: 1}, ok: true},
+		{cron: "15 6 * 1,7 6", occurrences: map[int]float64{6*minutesPerDay + 6*60 + 15: 2.0 / 12}, ok: true},
+		{cron: "0 9 * * MON", ok: false},
+		{cron: "0 25 * * *", ok: false},
+		{cron: "daily", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			occurrences, ok := CronWeeklyOccurrences(tt.cron)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.InDeltaMapValues(t, tt.occurrences, occurrences, 1e-9)
+			}
+		})
+	}
+
+	daily, ok := CronWeeklyOccurrences("5 4 * * *")
+	require.True(t, ok)
+	assert.Len(t, daily, 7, "a daily schedule runs once per weekday")
+
+	biWeekly, ok := CronWeeklyOccurrences("0 3 */14 * *")
+	require.True(t, ok)
+	assert.InDelta(t, 3.0/31, biWeekly[3*60], 1e-9, "day-of-month steps lower the weight")
+}
@@ -325,7 +325,7 @@ func (p *ScheduleParser) parseBase() (string, error) {
 				return fmt.Sprintf("FUZZY:DAILY_AROUND:%s:%s * * *", hour, minute), nil
 			}
 			// Reject "daily at TIME" pattern - use cron directly for fixed times
-			return "", fmt.Errorf("'daily at <time>' syntax is not supported. Use fuzzy schedules like 'daily' (scattered), 'daily around <time>', or 'daily between <start> and <end>' for load distribution. For fixed times, use standard cron syntax (e.g., '0 14 * * *')")
+			return "", p.dailyAtError()
 		}
 
 	case "hourly":
@@ -421,6 +421,21 @@ func (p *ScheduleParser) parseBase() (string, error) {
 	return fmt.Sprintf("%s %s %s %s %s", minute, hour, day, month, weekday), nil
 }
 
+// dailyAtError explains that "daily at TIME" is not supported and suggests the fuzzy and cron
+// forms for the requested time. Both forms accept a trailing IANA timezone.
+func (p *ScheduleParser) dailyAtError() error {
+	const unsupported = "'daily at <time>' syntax is not supported"
+	timeStr, err := p.extractTime(1)
+	if err != nil || strings.Contains(timeStr, "utc") {
+		return fmt.Errorf("%s. Use fuzzy schedules like 'daily' (scattered), 'daily around <time>', or 'daily between <start> and <end>' for load distribution. For fixed times, use standard cron syntax (e.g., '0 14 * * *'). Both forms can be followed by an IANA timezone (e.g., 'daily around 14:00 Europe/Berlin' or '0 14 * * * Europe/Berlin')", unsupported)
+	}
+	minute, hour := parseTime(timeStr)
+	hourValue, _ := strconv.Atoi(hour)
+	minuteValue, _ := strconv.Atoi(minute)
+	return fmt.Errorf("%s. Use 'daily around %s' to run near %s (scattered for load distribution), or the cron expression '%d %d * * *' to run at exactly %s. Both forms can be followed by an IANA timezone (e.g., 'daily around %s Europe/Berlin' or '%d %d * * * Europe/Berlin')",
+		unsupported, timeStr, timeStr, minuteValue, hourValue, timeStr, timeStr, minuteValue, hourValue)
+}
+
 // extractTime extracts the time specification from tokens starting at startPos
 // Returns the time string (HH:MM, midnight, or noon) with optional UTC offset
 func (p *ScheduleParser) extractTime(startPos int) (string, error) {
@@ -28,6 +28,12 @@ func TestParseSchedule(t *testing.T) {
 			shouldError:    true,
 			errorSubstring: "'daily at <time>' syntax is not supported",
 		},
+		{
+			name:           "daily at suggests around and cron for the time",
+			input:          "daily at 09:00",
+			shouldError:    true,
+			errorSubstring: "Use 'daily around 09:00' to run near 09:00 (scattered for load distribution), or the cron expression '0 9 * * *'",
+		},
 		{
 			name:           "daily at midnight",
 			input:          "daily at midnight",
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var scheduleTimezoneLog = logger.New("parser:schedule_timezone")
+
+// ianaTimezonePattern matches IANA timezone names such as Europe/Berlin or America/Argentina/Buenos_Aires.
+// Cron fields never start with a letter, so this does not match step values like */5.
+var ianaTimezonePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+\-]*(/[A-Za-z0-9_+\-]+)+$`)
+
+// SplitScheduleTimezone splits a trailing IANA timezone name off a schedule expression,
+// e.g. "daily around 09:00 Europe/Berlin" -> ("daily around 09:00", "Europe/Berlin").
+// Returns the expression unchanged and an empty timezone when there is none.
+func SplitScheduleTimezone(expression string) (schedule string, timezone string) {
+	expression = strings.TrimSpace(expression)
+	index := strings.LastIndexAny(expression, " \t")
+	if index == -1 {
+		return expression, ""
+	}
+	candidate := expression[index+1:]
+	if !ianaTimezonePattern.MatchString(candidate) {
+		return expression, ""
+	}
+	return strings.TrimSpace(expression[:index]), candidate
+}
+
+// HasUTCOffset reports whether a natural-language schedule names a UTC offset ("utc+9") or a
+// timezone abbreviation ("pst"), which the parser applies itself
+func HasUTCOffset(expression string) bool {
+	for _, token := range strings.Fields(strings.ToLower(expression)) {
+		if _, isAbbreviation := normalizeTimezoneAbbreviation(token); isAbbreviation || strings.HasPrefix(token, "utc") {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleReferenceYear is the year whose daylight saving time rules split schedules into offset
+// periods. It is fixed so that compiling the same workflow in a different year gives the same lock file.
+const scheduleReferenceYear = 2026
+
+// ConvertCronToUTC converts a cron expression whose times are in the given location to UTC cron
+// expressions. GitHub Actions evaluates schedules in UTC, so when the location's UTC offset changes
+// during the year (daylight saving time), one cron expression is returned per offset period, using
+// the month field to select the period. Each month uses the offset in effect for most of its days
+// in scheduleReferenceYear, so runs in the days around a DST transition are one hour early or late.
+//
+// The minute field must be a single value. Runs that move to the previous or next day in UTC get a
+// shifted day-of-week field; shifting a day-of-month field is not supported.
+func ConvertCronToUTC(cron string, location *time.Location) ([]string, error) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s': must have exactly 5 fields", cron)
+	}
+	minuteField, hourField, dayField, monthField, weekdayField := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	minute, err := strconv.Atoi(minuteField)
+	if err != nil || minute < 0 || minute > 59 {
+		return nil, fmt.Errorf("cannot convert '%s' to UTC: the minute field must be a single value", cron)
+	}
+	months, okMonths := expandCronField(monthField, 1, 12)
+	if !okMonths {
+		return nil, fmt.Errorf("invalid month field '%s' in cron expression '%s'", monthField, cron)
+	}
+
+	// Group the months by the UTC offset (in minutes) in effect for most of the month
+	var offsets []int
+	monthsByOffset := make(map[int][]int)
+	for _, month := range months {
+		offset := monthlyUTCOffset(location, scheduleReferenceYear, time.Month(month))
+		if _, seen := monthsByOffset[offset]; !seen {
+			offsets = append(offsets, offset)
+		}
+		monthsByOffset[offset] = append(monthsByOffset[offset], month)
+	}
+
+	if hourField == "*" {
+		// Every hour: only the minute moves, and only for offsets that are not whole hours
+		for _, offset := range offsets {
+			if offset%60 != 0 {
+				return nil, fmt.Errorf("cannot convert '%s' to UTC: %s has a UTC offset that is not a whole hour", cron, location)
+			}
+		}
+		return []string{cron}, nil
+	}
+	hours, okHours := expandCronField(hourField, 0, 23)
+	if !okHours {
+		return nil, fmt.Errorf("invalid hour field '%s' in cron expression '%s'", hourField, cron)
+	}
+
+	var result []string
+	for _, offset := range offsets {
+		periodMonthField := monthField
+		if len(offsets) > 1 {
+			periodMonthField = formatCronValues(monthsByOffset[offset])
+		}
+
+		// Convert each hour to UTC, grouping by the day shift (-1, 0, +1)
+		utcMinute := 0
+		hoursByShift := make(map[int][]int)
+		for _, hour := range hours {
+			total := hour*60 + minute - offset
+			shift := 0
+			if total < 0 {
+				total += minutesPerDay
+				shift = -1
+			} else if total >= minutesPerDay {
+				total -= minutesPerDay
+				shift = 1
+			}
+			utcMinute = total % 60
+			hoursByShift[shift] = append(hoursByShift[shift], total/60)
+		}
+
+		for _, shift := range []int{-1, 0, 1} {
+			utcHours, ok := hoursByShift[shift]
+			if !ok {
+				continue
+			}
+			periodWeekdayField := weekdayField
+			if shift != 0 {
+				if dayField != "*" {
+					return nil, fmt.Errorf("cannot convert '%s' to UTC: the run moves to another day in UTC, which cannot be expressed for a day-of-month schedule", cron)
+				}
+				if weekdayField != "*" {
+					weekdays, ok := expandCronField(weekdayField, 0, 7)
+					if !ok {
+						return nil, fmt.Errorf("invalid day-of-week field '%s' in cron expression '%s'", weekdayField, cron)
+					}
+					shifted := make(map[int]bool)
+					for _, weekday := range weekdays {
+						shifted[(weekday+shift+7)%7] = true
+					}
+					periodWeekdayField = formatCronValues(sortedKeys(shifted))
+				}
+			}
+			sort.Ints(utcHours)
+			result = append(result, fmt.Sprintf("%d %s %s %s %s", utcMinute, formatCronValues(utcHours), dayField, periodMonthField, periodWeekdayField))
+		}
+	}
+
+	scheduleTimezoneLog.Printf("Converted %s in %s to UTC: %v", cron, location, result)
+	return result, nil
+}
+
+// monthlyUTCOffset returns the UTC offset in minutes that is in effect for most days of the month
+func monthlyUTCOffset(location *time.Location, year int, month time.Month) int {
+	counts := make(map[int]int)
+	best, bestCount := 0, 0
+	for day := time.Date(year, month, 1, 12, 0, 0, 0, location); day.Month() == month; day = day.AddDate(0, 0, 1) {
+		_, seconds := day.Zone()
+		offset := seconds / 60
+		counts[offset]++
+		if counts[offset] > bestCount {
+			best, bestCount = offset, counts[offset]
+		}
+	}
+	return best
+}
+
+// formatCronValues formats sorted values as a cron list, collapsing consecutive values into ranges
+func formatCronValues(values []int) string {
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", values[i], values[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
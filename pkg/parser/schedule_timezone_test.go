@@ -0,0 +1,159 @@
+//go:build !integration
+
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitScheduleTimezone(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedSchedule string
+		expectedTimezone string
+	}{
+		{input: "daily around 09:00 Europe/Berlin", expectedSchedule: "daily around 09:00", expectedTimezone: "Europe/Berlin"},
+		{input: "0 9 * * 1-5 America/Los_Angeles", expectedSchedule: "0 9 * * 1-5", expectedTimezone: "America/Los_Angeles"},
+		{input: "weekly on monday America/Argentina/Buenos_Aires", expectedSchedule: "weekly on monday", expectedTimezone: "America/Argentina/Buenos_Aires"},
+		{input: "daily around 14:00 utc+9", expectedSchedule: "daily around 14:00 utc+9"},
+		{input: "*/5 * * * *", expectedSchedule: "*/5 * * * *"},
+		{input: "0 9 * * */2", expectedSchedule: "0 9 * * */2"},
+		{input: "daily", expectedSchedule: "daily"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			schedule, timezone := SplitScheduleTimezone(tt.input)
+			assert.Equal(t, tt.expectedSchedule, schedule)
+			assert.Equal(t, tt.expectedTimezone, timezone)
+		})
+	}
+}
+
+func TestHasUTCOffset(t *testing.T) {
+	assert.True(t, HasUTCOffset("daily around 14:00 utc+9"))
+	assert.True(t, HasUTCOffset("daily around 9am PST"))
+	assert.False(t, HasUTCOffset("daily around 14:00"))
+	assert.False(t, HasUTCOffset("0 9 * * 1"))
+}
+
+func TestConvertCronToUTC(t *testing.T) {
+	load := func(name string) *time.Location {
+		location, err := time.LoadLocation(name)
+		require.NoError(t, err)
+		return location
+	}
+
+	tests := []struct {
+		name     string
+		cron     string
+		location *time.Location
+		expected []string
+	}{
+		{
+			name:     "DST split in Europe",
+			cron:     "0 9 * * *",
+			location: load("Europe/Berlin"),
+			expected: []string{"0 8 * 1-3,11-12 *", "0 7 * 4-10 *"},
+		},
+		{
+			name:     "DST split in the US",
+			cron:     "30 8 * * 1-5",
+			location: load("America/Los_Angeles"),
+			expected: []string{"30 16 * 1-2,11-12 1-5", "30 15 * 3-10 1-5"},
+		},
+		{
+			name:     "no DST",
+			cron:     "15 10 * * 1",
+			location: load("Asia/Tokyo"),
+			expected: []string{"15 1 * * 1"},
+		},
+		{
+			name:     "previous day in UTC shifts weekdays",
+			cron:     "30 7 * * 1",
+			location: load("Asia/Tokyo"),
+			expected: []string{"30 22 * * 0"},
+		},
+		{
+			name:     "next day in UTC shifts weekdays",
+			cron:     "0 20 * * 5",
+			location: load("America/Denver"),
+			expected: []string{"0 3 * 1-2,11-12 6", "0 2 * 3-10 6"},
+		},
+		{
+			name:     "hours split across days",
+			cron:     "0 6,12 * * 1-5",
+			location: load("Asia/Tokyo"),
+			expected: []string{"0 21 * * 0-4", "0 3 * * 1-5"},
+		},
+		{
+			name:     "half-hour offset",
+			cron:     "0 9 * * *",
+			location: load("Asia/Kolkata"),
+			expected: []string{"30 3 * * *"},
+		},
+		{
+			name:     "restricted months",
+			cron:     "0 9 1 6 *",
+			location: load("Europe/Berlin"),
+			expected: []string{"0 7 1 6 *"},
+		},
+		{
+			name:     "every hour",
+			cron:     "5 * * * *",
+			location: load("Europe/Berlin"),
+			expected: []string{"5 * * * *"},
+		},
+		{
+			name:     "UTC",
+			cron:     "0 9 * * *",
+			location: time.UTC,
+			expected: []string{"0 9 * * *"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crons, err := ConvertCronToUTC(tt.cron, tt.location)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, crons)
+		})
+	}
+}
+
+func TestConvertCronToUTCErrors(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		cron     string
+		location *time.Location
+		errorMsg string
+	}{
+		{name: "minute list", cron: "0,30 9 * * *", location: tokyo, errorMsg: "minute field must be a single value"},
+		{name: "day of month across midnight", cron: "0 7 1 * *", location: tokyo, errorMsg: "day-of-month"},
+		{name: "every hour with half-hour offset", cron: "0 * * * *", location: kolkata, errorMsg: "not a whole hour"},
+		{name: "invalid field count", cron: "0 9 * *", location: tokyo, errorMsg: "exactly 5 fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConvertCronToUTC(tt.cron, tt.location)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestFormatCronValues(t *testing.T) {
+	assert.Equal(t, "1-3,11-12", formatCronValues([]int{1, 2, 3, 11, 12}))
+	assert.Equal(t, "0,2,4", formatCronValues([]int{0, 2, 4}))
+	assert.Equal(t, "5", formatCronValues([]int{5}))
+}
@@ -112,25 +112,33 @@ func TestValidateMainWorkflowFrontmatterWithSchema(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "defined engine string format",
+			frontmatter: map[string]any{
+				"on":     "push",
+				"engine": "acme-agent",
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid engine string format",
 			frontmatter: map[string]any{
 				"on":     "push",
-				"engine": "invalid-engine",
+				"engine": "Invalid Engine",
 			},
 			wantErr:     true,
-			errContains: "value must be one of 'claude', 'codex'",
+			errContains: "does not match pattern",
 		},
 		{
 			name: "invalid engine object format - invalid id",
 			frontmatter: map[string]any{
 				"on": "push",
 				"engine": map[string]any{
-					"id": "invalid-engine",
+					"id": "invalid_engine",
 				},
 			},
 			wantErr:     true,
-			errContains: "value must be one of 'claude', 'codex'",
+			errContains: "does not match pattern",
 		},
 		{
 			name: "invalid engine object format - missing id",
@@ -58,6 +58,24 @@ func isActionDefinitionFile(filePath string, content []byte) (bool, error) {
 	return false, nil
 }
 
+// isEngineDefinitionFile checks if a YAML file is an engine definition rather than a workflow.
+// Engine definitions have an 'execute' section and no 'jobs'.
+func isEngineDefinitionFile(filePath string) bool {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+
+	_, hasExecute := doc["execute"]
+	_, hasJobs := doc["jobs"]
+	return hasExecute && !hasJobs
+}
+
 // isCopilotSetupStepsFile checks if a file is the special copilot-setup-steps file
 // This file receives special handling - only steps are extracted from the setup job
 // Supports both .yml and .yaml extensions for consistency with GitHub Actions
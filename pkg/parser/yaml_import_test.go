@@ -240,6 +240,29 @@ This imports a YAML workflow.`
 	assert.Contains(t, importsResult.MergedJobs, "test", "Should contain test job from YAML workflow")
 }
 
+func TestImportEngineDefinition(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	engineDefinition := `id: acme
+execute:
+  command: acme run --prompt-file {prompt-file}`
+
+	engineFile := filepath.Join(tmpDir, "acme.yml")
+	err := os.WriteFile(engineFile, []byte(engineDefinition), 0644)
+	require.NoError(t, err, "Should create engine definition file")
+
+	frontmatter := map[string]any{
+		"on":      "issue_comment",
+		"imports": []any{"acme.yml"},
+	}
+
+	importsResult, err := ProcessImportsFromFrontmatterWithManifest(frontmatter, tmpDir, nil)
+	require.NoError(t, err, "Should process imports")
+
+	assert.Equal(t, []string{engineFile}, importsResult.EngineDefinitions, "Should collect the engine definition")
+	assert.Empty(t, importsResult.MergedJobs, "Engine definitions should not contribute jobs")
+}
+
 func TestRejectLockYMLImport(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"maps"
 	"strings"
 	"sync"
 
@@ -303,6 +304,7 @@ func NewEngineRegistry() *EngineRegistry {
 	registry.Register(NewCodexEngine())
 	registry.Register(NewCopilotEngine())
 	registry.Register(NewCustomEngine())
+	registry.Register(NewReplayEngine())
 
 	agenticEngineLog.Printf("Registered %d engines", len(registry.engines))
 	return registry
@@ -322,6 +324,12 @@ func (r *EngineRegistry) Register(engine CodingAgentEngine) {
 	r.engines[engine.GetID()] = engine
 }
 
+// Clone returns a registry with the same engines, so that engines can be added without
+// affecting the original (e.g. the global registry)
+func (r *EngineRegistry) Clone() *EngineRegistry {
+	return &EngineRegistry{engines: maps.Clone(r.engines)}
+}
+
 // GetEngine retrieves an engine by ID
 func (r *EngineRegistry) GetEngine(id string) (CodingAgentEngine, error) {
 	agenticEngineLog.Printf("Looking up engine: id=%s", id)
@@ -354,10 +362,14 @@ func (r *EngineRegistry) GetDefaultEngine() CodingAgentEngine {
 	return r.engines["copilot"]
 }
 
-// GetEngineByPrefix returns an engine that matches the given prefix
-// This is useful for backward compatibility with strings like "codex-experimental"
+// GetEngineByPrefix returns a built-in engine that matches the given prefix
+// This is useful for backward compatibility with strings like "codex-experimental".
+// Defined engines only match by exact id.
 func (r *EngineRegistry) GetEngineByPrefix(prefix string) (CodingAgentEngine, error) {
 	for id, engine := range r.engines {
+		if _, defined := engine.(*DefinedEngine); defined {
+			continue
+		}
 		if strings.HasPrefix(prefix, id) {
 			return engine, nil
 		}
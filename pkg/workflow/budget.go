@@ -0,0 +1,264 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var budgetLog = logger.New("workflow:budget")
+
+// BudgetExceededClassification is the failure classification reported when a run exceeds its budget
+const BudgetExceededClassification = "budget_exceeded"
+
+// BudgetReportPath is where the budget enforcement step writes its report in the agent job.
+// The file is uploaded with the agent artifacts so that `audit` can show consumption against the limits.
+const BudgetReportPath = "/tmp/gh-aw/budget.json"
+
+// BudgetWatcherLogPath is where the budget watcher logs the usage it measured while the agent ran
+const BudgetWatcherLogPath = "/tmp/gh-aw/budget-watcher.log"
+
+// BudgetConfig represents the top-level 'budget' frontmatter section.
+// Zero values mean the corresponding limit is not set.
+type BudgetConfig struct {
+	MaxTokens  int     `json:"max-tokens,omitempty"`   // Maximum total tokens (input + output + cache)
+	MaxCostUSD float64 `json:"max-cost-usd,omitempty"` // Maximum estimated cost in US dollars
+	MaxTurns   int     `json:"max-turns,omitempty"`    // Maximum number of agent turns
+}
+
+// BudgetUsage is the usage measured for a run
+type BudgetUsage struct {
+	Tokens  int     `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+	Turns   int     `json:"turns"`
+}
+
+// BudgetReport is the result of checking a run's usage against its budget.
+// It is written by check_agent_budget.cjs to BudgetReportPath.
+type BudgetReport struct {
+	Limits       BudgetConfig `json:"limits"`
+	Usage        BudgetUsage  `json:"usage"`
+	Exceeded     []string     `json:"exceeded,omitempty"`      // Names of the exceeded limits (e.g. "max-tokens")
+	StoppedEarly bool         `json:"stopped_early,omitempty"` // The budget watcher stopped the agent during the run
+}
+
+// IsExceeded returns true if any limit in the report was exceeded
+func (r *BudgetReport) IsExceeded() bool {
+	return r != nil && len(r.Exceeded) > 0
+}
+
+// extractBudgetConfig extracts and validates the 'budget' field from frontmatter
+func (c *Compiler) extractBudgetConfig(frontmatter map[string]any) (*BudgetConfig, error) {
+	budgetValue, exists := frontmatter["budget"]
+	if !exists || budgetValue == nil {
+		return nil, nil
+	}
+
+	budgetMap, ok := budgetValue.(map[string]any)
+	if !ok {
+		return nil, errors.New("budget must be an object with max-tokens, max-cost-usd, or max-turns")
+	}
+
+	config := &BudgetConfig{}
+
+	if value, ok := budgetMap["max-tokens"]; ok {
+		maxTokens, ok := parseIntValue(value)
+		if !ok || maxTokens <= 0 {
+			return nil, fmt.Errorf("budget.max-tokens must be a positive integer, got %v", value)
+		}
+		config.MaxTokens = maxTokens
+	}
+
+	if value, ok := budgetMap["max-cost-usd"]; ok {
+		maxCost := ConvertToFloat(value)
+		if intValue, isInt := parseIntValue(value); isInt && maxCost == 0 {
+			maxCost = float64(intValue)
+		}
+		if maxCost <= 0 {
+			return nil, fmt.Errorf("budget.max-cost-usd must be a positive number, got %v", value)
+		}
+		config.MaxCostUSD = maxCost
+	}
+
+	if value, ok := budgetMap["max-turns"]; ok {
+		maxTurns, ok := parseIntValue(value)
+		if !ok || maxTurns <= 0 {
+			return nil, fmt.Errorf("budget.max-turns must be a positive integer, got %v", value)
+		}
+		config.MaxTurns = maxTurns
+	}
+
+	if config.MaxTokens == 0 && config.MaxCostUSD == 0 && config.MaxTurns == 0 {
+		return nil, errors.New("budget must set at least one of max-tokens, max-cost-usd, or max-turns")
+	}
+
+	budgetLog.Printf("Extracted budget: max-tokens=%d, max-cost-usd=%.4f, max-turns=%d", config.MaxTokens, config.MaxCostUSD, config.MaxTurns)
+	return config, nil
+}
+
+// withBudgetMaxTurns returns the workflow data the agent step is rendered from, with
+// budget.max-turns passed to engines that enforce max-turns natively. The shared engine
+// config is copied rather than modified so the limit does not reach the threat detection
+// job. An explicit engine.max-turns always takes precedence. Engines without native support
+// rely on the budget watcher and the post-run budget enforcement step.
+func withBudgetMaxTurns(data *WorkflowData, engine CodingAgentEngine) *WorkflowData {
+	if data.Budget == nil || data.Budget.MaxTurns == 0 || !engine.SupportsMaxTurns() {
+		return data
+	}
+	engineConfig := &EngineConfig{ID: engine.GetID()}
+	if data.EngineConfig != nil {
+		if data.EngineConfig.MaxTurns != "" {
+			budgetLog.Printf("engine.max-turns=%s takes precedence over budget.max-turns=%d", data.EngineConfig.MaxTurns, data.Budget.MaxTurns)
+			return data
+		}
+		configCopy := *data.EngineConfig
+		engineConfig = &configCopy
+	}
+	engineConfig.MaxTurns = strconv.Itoa(data.Budget.MaxTurns)
+	budgetLog.Printf("Passing budget.max-turns=%d to engine %s", data.Budget.MaxTurns, engine.GetID())
+
+	agentData := *data
+	agentData.EngineConfig = engineConfig
+	return &agentData
+}
+
+// budgetEnv returns the environment shared by the budget watcher and the budget enforcement step
+func budgetEnv(data *WorkflowData, engine CodingAgentEngine) map[string]string {
+	env := map[string]string{
+		"GH_AW_AGENT_OUTPUT": engine.GetLogFileForParsing(),
+	}
+	if data.Budget.MaxTokens > 0 {
+		env["GH_AW_BUDGET_MAX_TOKENS"] = fmt.Sprintf("%q", strconv.Itoa(data.Budget.MaxTokens))
+	}
+	if data.Budget.MaxCostUSD > 0 {
+		env["GH_AW_BUDGET_MAX_COST_USD"] = fmt.Sprintf("%q", strconv.FormatFloat(data.Budget.MaxCostUSD, 'f', -1, 64))
+	}
+	if data.Budget.MaxTurns > 0 {
+		env["GH_AW_BUDGET_MAX_TURNS"] = fmt.Sprintf("%q", strconv.Itoa(data.Budget.MaxTurns))
+	}
+	return env
+}
+
+// budgetAgentProcess returns the name of the process the budget watcher stops when the run goes
+// over budget. Firewalled agents run inside awf; otherwise the engine CLI is stopped. Only
+// processes started by the agent step are signalled (see recordAgentStepPID). Custom
+// engines run arbitrary steps, so their agent cannot be identified and is not stopped early.
+func budgetAgentProcess(data *WorkflowData, engine CodingAgentEngine) string {
+	if engine.SupportsFirewall() && isFirewallEnabled(data) {
+		return "awf"
+	}
+	if data.EngineConfig != nil {
+		if fields := strings.Fields(data.EngineConfig.Command); len(fields) > 0 {
+			return filepath.Base(fields[0])
+		}
+	}
+	switch engine.GetID() {
+	case "claude", "codex", "copilot":
+		return engine.GetID()
+	}
+	return ""
+}
+
+// budgetAgentStepPIDPath is where the agent execution step records the PID of its shell, so that
+// the budget watcher only signals the agent process started by that step
+const budgetAgentStepPIDPath = "/tmp/gh-aw/agent-step.pid"
+
+// recordAgentStepPID makes the execution step that launches the agent process write the PID of its
+// shell before running anything else. The agent step is the last step whose run script mentions
+// the agent process; steps are left unchanged when there is none.
+func recordAgentStepPID(steps []GitHubActionStep, agentProcess string) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		runIndex := slices.Index(steps[i], "        run: |")
+		if runIndex < 0 {
+			continue
+		}
+		launchesAgent := false
+		for _, line := range steps[i][runIndex+1:] {
+			if line != "" && !strings.HasPrefix(line, "          ") {
+				break // End of the run script
+			}
+			if strings.Contains(line, agentProcess) {
+				launchesAgent = true
+				break
+			}
+		}
+		if !launchesAgent {
+			continue
+		}
+		step := append(GitHubActionStep{}, steps[i][:runIndex+1]...)
+		step = append(step, "          echo $$ > "+budgetAgentStepPIDPath)
+		steps[i] = append(step, steps[i][runIndex+1:]...)
+		budgetLog.Printf("Agent step records its PID in %s", budgetAgentStepPIDPath)
+		return
+	}
+}
+
+// writeBudgetEnv writes the env block of a budget step with its keys sorted
+func writeBudgetEnv(yaml *strings.Builder, env map[string]string) {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	yaml.WriteString("        env:\n")
+	for _, key := range keys {
+		fmt.Fprintf(yaml, "          %s: %s\n", key, env[key])
+	}
+}
+
+// generateBudgetWatcherStep generates the step that starts the budget watcher before the agent runs.
+// The watcher polls the agent log in the background and stops the agent as soon as its usage goes
+// over a limit, so that a runaway run does not keep spending until it finishes on its own.
+func (c *Compiler) generateBudgetWatcherStep(yaml *strings.Builder, data *WorkflowData, engine CodingAgentEngine) {
+	if data.Budget == nil {
+		return
+	}
+
+	agentProcess := budgetAgentProcess(data, engine)
+	budgetLog.Printf("Generating budget watcher step for engine %s (agent process: %q)", engine.GetID(), agentProcess)
+
+	env := budgetEnv(data, engine)
+	if agentProcess != "" {
+		env["GH_AW_BUDGET_AGENT_PROCESS"] = agentProcess
+	}
+
+	yaml.WriteString("      - name: Start agent budget watcher\n")
+	writeBudgetEnv(yaml, env)
+	yaml.WriteString("        run: bash " + SetupActionDestination + "/start_budget_watcher.sh\n")
+}
+
+// generateBudgetEnforcementStep generates the step that checks the agent's token, cost, and turn
+// usage against the budget. It runs after the agent and before the safe outputs are collected, so a
+// run that went over budget fails with the budget_exceeded classification and none of its safe
+// outputs are applied. It also stops the budget watcher and reports whether it stopped the agent.
+func (c *Compiler) generateBudgetEnforcementStep(yaml *strings.Builder, data *WorkflowData, engine CodingAgentEngine) {
+	if data.Budget == nil {
+		return
+	}
+
+	budgetLog.Printf("Generating budget enforcement step for engine %s", engine.GetID())
+
+	env := budgetEnv(data, engine)
+	if data.SafeOutputs != nil {
+		env["GH_AW_SAFE_OUTPUTS"] = "${{ env.GH_AW_SAFE_OUTPUTS }}"
+	}
+
+	yaml.WriteString("      - name: Enforce agent budget\n")
+	yaml.WriteString("        id: budget\n")
+	yaml.WriteString("        if: always()\n")
+	fmt.Fprintf(yaml, "        uses: %s\n", GetActionPin("actions/github-script"))
+	writeBudgetEnv(yaml, env)
+	yaml.WriteString("        with:\n")
+	yaml.WriteString("          script: |\n")
+	yaml.WriteString("            const { setupGlobals } = require('" + SetupActionDestination + "/setup_globals.cjs');\n")
+	yaml.WriteString("            setupGlobals(core, github, context, exec, io);\n")
+	yaml.WriteString("            const { main } = require('" + SetupActionDestination + "/check_agent_budget.cjs');\n")
+	yaml.WriteString("            await main();\n")
+}
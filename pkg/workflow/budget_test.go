@@ -0,0 +1,211 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractBudgetConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter map[string]any
+		expected    *BudgetConfig
+		errMsg      string
+	}{
+		{name: "no budget", frontmatter: map[string]any{}},
+		{
+			name:        "all limits",
+			frontmatter: map[string]any{"budget": map[string]any{"max-tokens": uint64(500000), "max-cost-usd": 2.5, "max-turns": 30}},
+			expected:    &BudgetConfig{MaxTokens: 500000, MaxCostUSD: 2.5, MaxTurns: 30},
+		},
+		{
+			name:        "integer cost",
+			frontmatter: map[string]any{"budget": map[string]any{"max-cost-usd": uint64(3)}},
+			expected:    &BudgetConfig{MaxCostUSD: 3},
+		},
+		{name: "not an object", frontmatter: map[string]any{"budget": "cheap"}, errMsg: "budget must be an object"},
+		{name: "empty object", frontmatter: map[string]any{"budget": map[string]any{}}, errMsg: "at least one of"},
+		{name: "zero tokens", frontmatter: map[string]any{"budget": map[string]any{"max-tokens": 0}}, errMsg: "max-tokens must be a positive integer"},
+		{name: "negative cost", frontmatter: map[string]any{"budget": map[string]any{"max-cost-usd": -1.0}}, errMsg: "max-cost-usd must be a positive number"},
+		{name: "string turns", frontmatter: map[string]any{"budget": map[string]any{"max-turns": "ten"}}, errMsg: "max-turns must be a positive integer"},
+	}
+
+	compiler := NewCompiler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget, err := compiler.extractBudgetConfig(tt.frontmatter)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, budget)
+		})
+	}
+}
+
+func TestWithBudgetMaxTurns(t *testing.T) {
+	tests := []struct {
+		name         string
+		engine       CodingAgentEngine
+		engineConfig *EngineConfig
+		expected     string
+	}{
+		{name: "native support", engine: NewClaudeEngine(), engineConfig: &EngineConfig{ID: "claude"}, expected: "12"},
+		{name: "engine max-turns wins", engine: NewClaudeEngine(), engineConfig: &EngineConfig{ID: "claude", MaxTurns: "5"}, expected: "5"},
+		{name: "no native support", engine: NewCopilotEngine(), engineConfig: &EngineConfig{ID: "copilot"}, expected: ""},
+		{name: "missing engine config", engine: NewClaudeEngine(), expected: "12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflowData := &WorkflowData{EngineConfig: tt.engineConfig, Budget: &BudgetConfig{MaxTurns: 12}}
+			var originalMaxTurns string
+			if tt.engineConfig != nil {
+				originalMaxTurns = tt.engineConfig.MaxTurns
+			}
+
+			agentData := withBudgetMaxTurns(workflowData, tt.engine)
+			if tt.expected == "" {
+				assert.Empty(t, agentData.EngineConfig.MaxTurns, "budget should not set max-turns for engines without native support")
+			} else {
+				require.NotNil(t, agentData.EngineConfig)
+				assert.Equal(t, tt.expected, agentData.EngineConfig.MaxTurns)
+			}
+
+			assert.Same(t, tt.engineConfig, workflowData.EngineConfig, "the shared engine config should not be replaced")
+			if tt.engineConfig != nil {
+				assert.Equal(t, originalMaxTurns, tt.engineConfig.MaxTurns, "the shared engine config should not be modified")
+			}
+		})
+	}
+}
+
+func TestBudgetAgentProcess(t *testing.T) {
+	noSandbox := &SandboxConfig{Agent: &AgentSandboxConfig{Disabled: true}}
+	tests := []struct {
+		name     string
+		engine   CodingAgentEngine
+		data     *WorkflowData
+		expected string
+	}{
+		{
+			name:     "firewalled agent runs inside awf",
+			engine:   NewClaudeEngine(),
+			data:     &WorkflowData{NetworkPermissions: &NetworkPermissions{Firewall: &FirewallConfig{Enabled: true}}},
+			expected: "awf",
+		},
+		{
+			name:     "engine CLI without the firewall",
+			engine:   NewCodexEngine(),
+			data:     &WorkflowData{SandboxConfig: noSandbox},
+			expected: "codex",
+		},
+		{
+			name:     "custom engine command",
+			engine:   NewClaudeEngine(),
+			data:     &WorkflowData{EngineConfig: &EngineConfig{ID: "claude", Command: "/usr/local/bin/my-claude --verbose"}, SandboxConfig: noSandbox},
+			expected: "my-claude",
+		},
+		{
+			name:     "custom engine steps cannot be stopped",
+			engine:   NewCustomEngine(),
+			data:     &WorkflowData{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, budgetAgentProcess(tt.data, tt.engine))
+		})
+	}
+}
+
+func TestRecordAgentStepPID(t *testing.T) {
+	steps := []GitHubActionStep{
+		{"      - name: Setup", "        run: |", "          echo claude"},
+		{"      - name: Execute Claude Code CLI", "        run: |", "          set -o pipefail", "          claude --print 2>&1 | tee log", "        env:", "          CLAUDE: 1"},
+		{"      - name: Cleanup", "        run: |", "          rm -f log", "        env:", "          NAME: claude"},
+	}
+
+	recordAgentStepPID(steps, "claude")
+
+	assert.Equal(t, GitHubActionStep{"      - name: Setup", "        run: |", "          echo claude"}, steps[0], "only the last step that runs the agent records its PID")
+	assert.Equal(t, []string{"        run: |", "          echo $$ > " + budgetAgentStepPIDPath, "          set -o pipefail"}, []string(steps[1][1:4]))
+	assert.Len(t, steps[2], 5, "env values are not part of the run script")
+}
+
+func TestBudgetCompileWorkflow(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "budgeted.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+engine: claude
+budget:
+  max-tokens: 200000
+  max-cost-usd: 1.5
+  max-turns: 20
+safe-outputs:
+  create-issue:
+---
+
+# Budgeted workflow
+`), 0644))
+
+	compiler := NewCompiler()
+	require.NoError(t, compiler.CompileWorkflow(workflowPath), "workflow with a budget should compile")
+
+	lockContent, err := os.ReadFile(filepath.Join(tmpDir, "budgeted.lock.yml"))
+	require.NoError(t, err)
+	lock := string(lockContent)
+
+	assert.Contains(t, lock, "- name: Start agent budget watcher")
+	assert.Contains(t, lock, "start_budget_watcher.sh")
+	assert.Contains(t, lock, "- name: Enforce agent budget")
+	assert.Contains(t, lock, `GH_AW_BUDGET_MAX_TOKENS: "200000"`)
+	assert.Contains(t, lock, `GH_AW_BUDGET_MAX_COST_USD: "1.5"`)
+	assert.Contains(t, lock, `GH_AW_BUDGET_MAX_TURNS: "20"`)
+	assert.Contains(t, lock, "check_agent_budget.cjs")
+	assert.Contains(t, lock, "--max-turns 20", "budget.max-turns should be passed to Claude natively")
+	assert.Equal(t, 1, strings.Count(lock, "--max-turns 20"), "budget.max-turns should not reach the threat detection job")
+	assert.Contains(t, lock, BudgetReportPath, "budget report should be uploaded with the agent artifacts")
+	assert.Contains(t, lock, "echo $$ > "+budgetAgentStepPIDPath, "the agent step should record its PID for the watcher")
+
+	watcherIdx := strings.Index(lock, "- name: Start agent budget watcher")
+	agentIdx := strings.Index(lock, "- name: Execute Claude Code CLI")
+	require.NotEqual(t, -1, agentIdx)
+	assert.Less(t, watcherIdx, agentIdx, "the budget watcher must start before the agent runs")
+
+	budgetIdx := strings.Index(lock, "- name: Enforce agent budget")
+	collectIdx := strings.Index(lock, "- name: Ingest agent output")
+	require.NotEqual(t, -1, collectIdx)
+	assert.Less(t, budgetIdx, collectIdx, "budget must be enforced before safe outputs are collected")
+}
+
+func TestBudgetCompileWorkflowInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "invalid-budget.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+engine: copilot
+budget:
+  max-tokens: 0
+---
+
+# Invalid budget
+`), 0644))
+
+	err := NewCompiler().CompileWorkflow(workflowPath)
+	require.Error(t, err, "non-positive budget limits should be rejected")
+}
@@ -156,12 +156,24 @@ func (c *Compiler) validateWorkflowData(workflowData *WorkflowData, markdownPath
 		return formatCompilerError(markdownPath, "error", err.Error(), err)
 	}
 
+	// Validate the workflow against the repository policy
+	log.Printf("Validating repository policy")
+	if err := c.validateWorkflowPolicy(workflowData, markdownPath); err != nil {
+		return err
+	}
+
 	// Validate agent file exists if specified in engine config
 	log.Printf("Validating agent file if specified")
 	if err := c.validateAgentFile(workflowData, markdownPath); err != nil {
 		return err
 	}
 
+	// Validate replay fixture when using the replay engine
+	log.Printf("Validating replay fixture if specified")
+	if err := c.validateReplayFixture(workflowData, markdownPath); err != nil {
+		return err
+	}
+
 	// Validate sandbox configuration
 	log.Printf("Validating sandbox configuration")
 	if err := validateSandboxConfig(workflowData); err != nil {
@@ -174,12 +186,39 @@ func (c *Compiler) validateWorkflowData(workflowData *WorkflowData, markdownPath
 		return formatCompilerError(markdownPath, "error", err.Error(), err)
 	}
 
+	// Validate safe-outputs patch path restrictions
+	log.Printf("Validating safe-outputs patch paths")
+	if err := validateSafeOutputsPatchPaths(workflowData.SafeOutputs); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+
+	// Validate safe-outputs create-check-run configuration
+	log.Printf("Validating safe-outputs create-check-run")
+	if err := validateCreateCheckRunConfig(workflowData.SafeOutputs); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+
 	// Validate safe-outputs allowed-domains configuration
 	log.Printf("Validating safe-outputs allowed-domains")
 	if err := c.validateSafeOutputsAllowedDomains(workflowData.SafeOutputs); err != nil {
 		return formatCompilerError(markdownPath, "error", err.Error(), err)
 	}
 
+	// Validate safe-outputs require-approval gates
+	log.Printf("Validating safe-outputs require-approval")
+	if err := validateSafeOutputsApproval(workflowData.SafeOutputs); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+
+	// Validate safe-outputs notify-webhook endpoints and allowed-domains
+	log.Printf("Validating safe-outputs notify-webhook")
+	if err := validateNotifyWebhookConfig(workflowData.SafeOutputs); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+	if err := c.validateNotifyWebhookAllowedDomains(workflowData.SafeOutputs); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+
 	// Validate network allowed domains configuration
 	log.Printf("Validating network allowed domains")
 	if err := c.validateNetworkAllowedDomains(workflowData.NetworkPermissions); err != nil {
@@ -226,32 +265,37 @@ func (c *Compiler) validateWorkflowData(workflowData *WorkflowData, markdownPath
 
 	// Emit experimental warning for sandbox-runtime feature
 	if isSRTEnabled(workflowData) {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("Using experimental feature: sandbox-runtime firewall"))
-		c.IncrementWarningCount()
+		warningMsg := "Using experimental feature: sandbox-runtime firewall"
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Emit warning for sandbox.agent: false (disables agent sandbox firewall)
 	if isAgentSandboxDisabled(workflowData) {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("⚠️  WARNING: Agent sandbox disabled (sandbox.agent: false). This removes firewall protection. The AI agent will have direct network access without firewall filtering. The MCP gateway remains enabled. Only use this for testing or in controlled environments where you trust the AI agent completely."))
-		c.IncrementWarningCount()
+		warningMsg := "⚠️  WARNING: Agent sandbox disabled (sandbox.agent: false). This removes firewall protection. The AI agent will have direct network access without firewall filtering. The MCP gateway remains enabled. Only use this for testing or in controlled environments where you trust the AI agent completely."
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Emit experimental warning for safe-inputs feature
 	if IsSafeInputsEnabled(workflowData.SafeInputs, workflowData) {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("Using experimental feature: safe-inputs"))
-		c.IncrementWarningCount()
+		warningMsg := "Using experimental feature: safe-inputs"
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Emit experimental warning for plugins feature
 	if workflowData.PluginInfo != nil && len(workflowData.PluginInfo.Plugins) > 0 {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("Using experimental feature: plugins"))
-		c.IncrementWarningCount()
+		warningMsg := "Using experimental feature: plugins"
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Emit experimental warning for rate-limit feature
 	if workflowData.RateLimit != nil {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage("Using experimental feature: rate-limit"))
-		c.IncrementWarningCount()
+		warningMsg := "Using experimental feature: rate-limit"
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Validate workflow_run triggers have branch restrictions
@@ -281,7 +325,7 @@ func (c *Compiler) validateWorkflowData(workflowData *WorkflowData, markdownPath
 				} else {
 					// In non-strict mode, missing permissions are warnings
 					fmt.Fprintln(os.Stderr, formatCompilerMessage(markdownPath, "warning", message))
-					c.IncrementWarningCount()
+					c.recordWarning(message)
 				}
 			}
 		}
@@ -298,7 +342,7 @@ func (c *Compiler) validateWorkflowData(workflowData *WorkflowData, markdownPath
 OIDC tokens can authenticate to cloud providers (AWS, Azure, GCP).
 Ensure proper audience validation and trust policies are configured.`
 				fmt.Fprintln(os.Stderr, formatCompilerMessage(markdownPath, "warning", warningMsg))
-				c.IncrementWarningCount()
+				c.recordWarning(warningMsg)
 			}
 		}
 	}
@@ -393,6 +437,12 @@ func (c *Compiler) generateAndValidateYAML(workflowData *WorkflowData, markdownP
 		return "", formattedErr
 	}
 
+	// Report template variables that will render as empty strings at runtime
+	log.Print("Validating template variables")
+	if err := c.validateTemplateVariables(workflowData, markdownPath); err != nil {
+		return "", err
+	}
+
 	// Validate against GitHub Actions schema (unless skipped)
 	if !c.skipValidation {
 		log.Print("Validating workflow against GitHub Actions schema")
@@ -412,8 +462,9 @@ func (c *Compiler) generateAndValidateYAML(workflowData *WorkflowData, markdownP
 		if err := c.validateContainerImages(workflowData); err != nil {
 			// Treat container image validation failures as warnings, not errors
 			// This is because validation may fail due to auth issues locally (e.g., private registries)
-			fmt.Fprintln(os.Stderr, formatCompilerMessage(markdownPath, "warning", fmt.Sprintf("container image validation failed: %v", err)))
-			c.IncrementWarningCount()
+			warningMsg := fmt.Sprintf("container image validation failed: %v", err)
+			fmt.Fprintln(os.Stderr, formatCompilerMessage(markdownPath, "warning", warningMsg))
+			c.recordWarning(warningMsg)
 		}
 
 		// Validate runtime packages (npx, uv)
@@ -511,6 +562,35 @@ func (c *Compiler) writeWorkflowOutput(lockFile, yamlContent string, markdownPat
 // making it efficient for scenarios where the same workflow is compiled multiple times
 // or when workflow data comes from a non-file source.
 func (c *Compiler) CompileWorkflowData(workflowData *WorkflowData, markdownPath string) error {
+	yamlContent, lockFile, err := c.compileWorkflowYAML(workflowData, markdownPath)
+	if err != nil {
+		return err
+	}
+
+	// Write output
+	return c.writeWorkflowOutput(lockFile, yamlContent, markdownPath)
+}
+
+// CompileWorkflowToYAML compiles a workflow markdown file and returns the parsed workflow data
+// and the generated YAML without writing the lock file. It is used to compare compiled workflows.
+func (c *Compiler) CompileWorkflowToYAML(markdownPath string) (*WorkflowData, string, error) {
+	c.markdownPath = markdownPath
+
+	workflowData, err := c.ParseWorkflowFile(markdownPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	yamlContent, _, err := c.compileWorkflowYAML(workflowData, markdownPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return workflowData, yamlContent, nil
+}
+
+// compileWorkflowYAML validates the workflow data and generates the lock file YAML.
+// It returns the YAML content and the lock file path the content belongs to.
+func (c *Compiler) compileWorkflowYAML(workflowData *WorkflowData, markdownPath string) (string, string, error) {
 	// Store markdownPath for use in dynamic tool generation and prompt generation
 	c.markdownPath = markdownPath
 
@@ -543,7 +623,7 @@ func (c *Compiler) CompileWorkflowData(workflowData *WorkflowData, markdownPath
 
 	// Validate workflow data
 	if err := c.validateWorkflowData(workflowData, markdownPath); err != nil {
-		return err
+		return "", "", err
 	}
 
 	// Note: Markdown content size is now handled by splitting into multiple steps in generatePrompt
@@ -555,11 +635,9 @@ func (c *Compiler) CompileWorkflowData(workflowData *WorkflowData, markdownPath
 	// Generate and validate YAML
 	yamlContent, err := c.generateAndValidateYAML(workflowData, markdownPath, lockFile)
 	if err != nil {
-		return err
+		return "", "", err
 	}
-
-	// Write output
-	return c.writeWorkflowOutput(lockFile, yamlContent, markdownPath)
+	return yamlContent, lockFile, nil
 }
 
 // ParseWorkflowFile parses a markdown workflow file and extracts all necessary data
@@ -151,6 +151,12 @@ func (c *Compiler) setupEngineAndImports(result *parser.FrontmatterResult, clean
 		}
 	}
 
+	// Make the repository's engine definitions and imported ones available before resolving the engine
+	if err := c.loadEngineDefinitions(importsResult.EngineDefinitions); err != nil {
+		orchestratorEngineLog.Printf("Failed to load engine definitions: %v", err)
+		return nil, err
+	}
+
 	// Process @include directives to extract engine configurations and check for conflicts
 	orchestratorEngineLog.Printf("Expanding includes for engine configurations")
 	includedEngines, err := parser.ExpandIncludesForEngines(result.Markdown, markdownDir)
@@ -213,8 +219,9 @@ func (c *Compiler) setupEngineAndImports(result *parser.FrontmatterResult, clean
 
 	log.Printf("AI engine: %s (%s)", agenticEngine.GetDisplayName(), engineSetting)
 	if agenticEngine.IsExperimental() && c.verbose {
-		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(fmt.Sprintf("Using experimental engine: %s", agenticEngine.GetDisplayName())))
-		c.IncrementWarningCount()
+		warningMsg := fmt.Sprintf("Using experimental engine: %s", agenticEngine.GetDisplayName())
+		fmt.Fprintln(os.Stderr, console.FormatWarningMessage(warningMsg))
+		c.recordWarning(warningMsg)
 	}
 
 	// Enable firewall by default for copilot engine when network restrictions are present
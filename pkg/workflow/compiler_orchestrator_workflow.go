@@ -119,6 +119,7 @@ func (c *Compiler) buildInitialWorkflowData(
 		MainWorkflowMarkdown: toolsResult.mainWorkflowMarkdown,
 		IncludedFiles:        toolsResult.allIncludedFiles,
 		ImportInputs:         importsResult.ImportInputs,
+		ImportsWithInputs:    importsResult.ImportsWithInputs,
 		Tools:                toolsResult.tools,
 		ParsedTools:          NewTools(toolsResult.tools),
 		Runtimes:             toolsResult.runtimes,
@@ -421,6 +422,12 @@ func (c *Compiler) extractAdditionalConfigurations(
 	workflowData.Bots = c.extractBots(frontmatter)
 	workflowData.RateLimit = c.extractRateLimitConfig(frontmatter)
 
+	budget, err := c.extractBudgetConfig(frontmatter)
+	if err != nil {
+		return err
+	}
+	workflowData.Budget = budget
+
 	// Use the already extracted output configuration
 	workflowData.SafeOutputs = safeOutputs
 
@@ -514,6 +521,9 @@ func (c *Compiler) processOnSectionAndFilters(
 		return err
 	}
 
+	// Keep the schedule timezone conversions made during schedule preprocessing for the lock file header
+	workflowData.ScheduleConversions = c.scheduleConversions
+
 	// Parse the "on" section for command triggers, reactions, and other events
 	if err := c.parseOnSection(frontmatter, workflowData, cleanPath); err != nil {
 		return err
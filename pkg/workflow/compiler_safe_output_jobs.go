@@ -39,8 +39,31 @@ func (c *Compiler) buildSafeOutputsJobs(data *WorkflowData, jobName, markdownPat
 	// Track safe output job names to establish dependencies for conclusion job
 	var safeOutputJobNames []string
 
+	// Safe outputs with require-approval run in their own jobs that wait for environment approval.
+	// In staged mode nothing is written, so they stay in the consolidated job and the approval_gates
+	// job only previews which outputs would have required approval.
+	approvalGates := collectApprovalGates(data.SafeOutputs)
+	approvalJobsEnabled := len(approvalGates) > 0 && !c.isSafeOutputsStaged(data)
+	if len(approvalGates) > 0 {
+		compilerSafeOutputJobsLog.Printf("Building approval_gates job for %d gated safe outputs", len(approvalGates))
+		approvalGatesJob, err := c.buildApprovalGatesJob(data, approvalGates, jobName, threatDetectionEnabled)
+		if err != nil {
+			return fmt.Errorf("failed to build approval_gates job: %w", err)
+		}
+		if err := c.jobManager.AddJob(approvalGatesJob); err != nil {
+			return fmt.Errorf("failed to add approval_gates job: %w", err)
+		}
+	}
+
+	consolidatedData := data
+	if approvalJobsEnabled {
+		withoutGated := *data
+		withoutGated.SafeOutputs = safeOutputsAwaitingApproval(data.SafeOutputs, approvalGates)
+		consolidatedData = &withoutGated
+	}
+
 	// Build consolidated safe outputs job containing all safe output operations as steps
-	consolidatedJob, consolidatedStepNames, err := c.buildConsolidatedSafeOutputsJob(data, jobName, markdownPath)
+	consolidatedJob, consolidatedStepNames, err := c.buildConsolidatedSafeOutputsJob(consolidatedData, jobName, markdownPath)
 	if err != nil {
 		return fmt.Errorf("failed to build consolidated safe outputs job: %w", err)
 	}
@@ -52,6 +75,25 @@ func (c *Compiler) buildSafeOutputsJobs(data *WorkflowData, jobName, markdownPat
 		compilerSafeOutputJobsLog.Printf("Added consolidated safe outputs job with %d steps: %v", len(consolidatedStepNames), consolidatedStepNames)
 	}
 
+	// Build one approval-gated job per gated safe output type
+	if approvalJobsEnabled {
+		var consolidatedJobName string
+		if consolidatedJob != nil {
+			consolidatedJobName = consolidatedJob.Name
+		}
+		for _, gate := range approvalGates {
+			gatedJob, err := c.buildApprovalGatedJob(data, gate, jobName, consolidatedJobName, markdownPath)
+			if err != nil {
+				return fmt.Errorf("failed to build %s job: %w", gate.jobName(), err)
+			}
+			if err := c.jobManager.AddJob(gatedJob); err != nil {
+				return fmt.Errorf("failed to add %s job: %w", gate.jobName(), err)
+			}
+			safeOutputJobNames = append(safeOutputJobNames, gatedJob.Name)
+			compilerSafeOutputJobsLog.Printf("Added approval-gated job %s (environment=%s)", gatedJob.Name, gate.Config.Environment)
+		}
+	}
+
 	// Build safe-jobs if configured
 	// Safe-jobs should depend on agent job (always) AND detection job (if threat detection is enabled)
 	// These custom safe-jobs should also be included in the conclusion job's dependencies
@@ -81,6 +123,37 @@ func (c *Compiler) buildSafeOutputsJobs(data *WorkflowData, jobName, markdownPat
 		compilerSafeOutputJobsLog.Printf("Added separate upload_assets job")
 	}
 
+	// Build create_check_run job as a separate job if configured
+	// This is separate from the consolidated safe_outputs job so that it only gets checks: write
+	if data.SafeOutputs.CreateCheckRuns != nil {
+		compilerSafeOutputJobsLog.Print("Building separate create_check_run job")
+		createCheckRunJob, err := c.buildCreateCheckRunJob(data, jobName, threatDetectionEnabled)
+		if err != nil {
+			return fmt.Errorf("failed to build create_check_run job: %w", err)
+		}
+		if err := c.jobManager.AddJob(createCheckRunJob); err != nil {
+			return fmt.Errorf("failed to add create_check_run job: %w", err)
+		}
+		safeOutputJobNames = append(safeOutputJobNames, createCheckRunJob.Name)
+		compilerSafeOutputJobsLog.Printf("Added separate create_check_run job")
+	}
+
+	// Build notify_webhook job as a separate job if configured
+	// This is separate from the consolidated safe_outputs job so that the webhook secrets are only
+	// exposed to the job that posts the notifications
+	if data.SafeOutputs.NotifyWebhook != nil {
+		compilerSafeOutputJobsLog.Print("Building separate notify_webhook job")
+		notifyWebhookJob, err := c.buildNotifyWebhookJob(data, jobName, threatDetectionEnabled)
+		if err != nil {
+			return fmt.Errorf("failed to build notify_webhook job: %w", err)
+		}
+		if err := c.jobManager.AddJob(notifyWebhookJob); err != nil {
+			return fmt.Errorf("failed to add notify_webhook job: %w", err)
+		}
+		safeOutputJobNames = append(safeOutputJobNames, notifyWebhookJob.Name)
+		compilerSafeOutputJobsLog.Printf("Added separate notify_webhook job")
+	}
+
 	// Build conclusion job if add-comment is configured OR if command trigger is configured with reactions
 	// This job runs last, after all safe output jobs (and push_repo_memory if configured), to update the activation comment on failure
 	// The buildConclusionJob function itself will decide whether to create the job based on the configuration
@@ -336,7 +336,10 @@ var handlerRegistry = map[string]handlerBuilder{
 			AddStringSlice("allowed_repos", c.AllowedRepos).
 			AddDefault("max_patch_size", maxPatchSize).
 			AddBoolPtr("footer", getEffectiveFooter(c.Footer, cfg.Footer)).
-			AddBoolPtr("fallback_as_issue", c.FallbackAsIssue)
+			AddBoolPtr("fallback_as_issue", c.FallbackAsIssue).
+			AddStringSlice("allowed_paths", c.AllowedPaths).
+			AddStringSlice("blocked_paths", c.BlockedPaths).
+			AddStringSlice("protected_paths", protectedPatchPaths)
 		// Add base_branch - use custom value if specified, otherwise use github.ref_name
 		if c.BaseBranch != "" {
 			builder.AddDefault("base_branch", c.BaseBranch)
@@ -363,6 +366,9 @@ var handlerRegistry = map[string]handlerBuilder{
 			AddIfNotEmpty("commit_title_suffix", c.CommitTitleSuffix).
 			AddDefault("base_branch", "${{ github.ref_name }}").
 			AddDefault("max_patch_size", maxPatchSize).
+			AddStringSlice("allowed_paths", c.AllowedPaths).
+			AddStringSlice("blocked_paths", c.BlockedPaths).
+			AddStringSlice("protected_paths", protectedPatchPaths).
 			Build()
 	},
 	"update_pull_request": func(cfg *SafeOutputsConfig) map[string]any {
@@ -395,6 +395,13 @@ func (c *Compiler) buildConsolidatedSafeOutputsJob(data *WorkflowData, mainJobNa
 	if threatDetectionEnabled {
 		jobCondition = BuildAnd(agentNotSkipped, buildDetectionSuccessCondition())
 	}
+	// Gated types must never be processed here without a decision from the approval_gates job
+	if data.SafeOutputs.awaitingApprovalFrom != "" {
+		jobCondition = BuildAnd(jobCondition, BuildEquals(
+			BuildPropertyAccess(fmt.Sprintf("needs.%s.result", data.SafeOutputs.awaitingApprovalFrom)),
+			BuildStringLiteral("success"),
+		))
+	}
 
 	// Build dependencies
 	needs := []string{mainJobName}
@@ -405,6 +412,9 @@ func (c *Compiler) buildConsolidatedSafeOutputsJob(data *WorkflowData, mainJobNa
 	if data.SafeOutputs.CreatePullRequests != nil || data.SafeOutputs.PushToPullRequestBranch != nil || data.LockForAgent {
 		needs = append(needs, string(constants.ActivationJobName))
 	}
+	if data.SafeOutputs.awaitingApprovalFrom != "" {
+		needs = append(needs, data.SafeOutputs.awaitingApprovalFrom)
+	}
 
 	// Extract workflow ID from markdown path for GH_AW_WORKFLOW_ID
 	workflowID := GetWorkflowIDFromPath(markdownPath)
@@ -169,6 +169,17 @@ func (c *Compiler) buildHandlerManagerStep(data *WorkflowData) []string {
 	// Add handler manager config as JSON
 	c.addHandlerManagerConfigEnvVar(&steps, data)
 
+	// Route messages gated by require-approval to their approval-gated jobs
+	if data.SafeOutputs.approvalScope != "" {
+		steps = append(steps, fmt.Sprintf("          GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE: %q\n", data.SafeOutputs.approvalScope))
+		// Resolve temporary IDs (aw_...) of entities created by the safe_outputs job
+		if data.SafeOutputs.temporaryIDMapFrom != "" {
+			steps = append(steps, fmt.Sprintf("          GH_AW_TEMPORARY_ID_MAP: ${{ needs.%s.outputs.process_safe_outputs_temporary_id_map }}\n", data.SafeOutputs.temporaryIDMapFrom))
+		}
+	} else if data.SafeOutputs.awaitingApprovalFrom != "" {
+		steps = append(steps, fmt.Sprintf("          GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL: ${{ needs.%s.outputs.awaiting_approval }}\n", data.SafeOutputs.awaitingApprovalFrom))
+	}
+
 	// Add all safe output configuration env vars (still needed by individual handlers)
 	c.addAllSafeOutputConfigEnvVars(&steps, data)
 
@@ -118,8 +118,13 @@ type Compiler struct {
 	actionTag               string              // Override action SHA or tag for actions/setup (when set, overrides actionMode to release)
 	jobManager              *JobManager         // Manages jobs and dependencies
 	engineRegistry          *EngineRegistry     // Registry of available agentic engines
+	engineDefinitionsLoaded bool                // Tracks if the repository engine definitions were added to engineRegistry
+	workflowEngines         *EngineRegistry     // Engines defined by the current workflow's imports (nil if none)
+	policyLoaded            bool                // Tracks if the repository policy file was loaded
+	policy                  *WorkflowPolicy     // Repository policy enforced on every workflow (nil if none)
 	fileTracker             FileTracker         // Optional file tracker for tracking created files
 	warningCount            int                 // Number of warnings encountered during compilation
+	warnings                []string            // Messages of the counted warnings that concern the workflow being compiled
 	stepOrderTracker        *StepOrderTracker   // Tracks step ordering for validation
 	actionCache             *ActionCache        // Shared cache for action pin resolutions across all workflows
 	actionResolver          *ActionResolver     // Shared resolver for action pins across all workflows
@@ -130,6 +135,10 @@ type Compiler struct {
 	repositorySlug          string              // Repository slug (owner/repo) used as seed for scattering
 	artifactManager         *ArtifactManager    // Tracks artifact uploads/downloads for validation
 	scheduleFriendlyFormats map[int]string      // Maps schedule item index to friendly format string for current workflow
+	scheduleConversions     []string            // Schedules converted from a timezone to UTC for current workflow (noted in the lock file header)
+	schedulePlan            map[string]string   // Scattered crons of the workflow directory by seed and fuzzy cron (see PlanRepositorySchedules)
+	schedulePlanScope       string              // Repository slug, workflow directory and workflow file state the schedule plan was made for
+	scheduleMarkdownPath    string              // Path of the workflow whose schedules are being preprocessed (its directory is planned together)
 	gitRoot                 string              // Git repository root directory (if set, used for action cache path)
 }
 
@@ -253,14 +262,26 @@ func (c *Compiler) IncrementWarningCount() {
 	c.warningCount++
 }
 
+// recordWarning counts a warning about the workflow being compiled and keeps its message
+func (c *Compiler) recordWarning(message string) {
+	c.warningCount++
+	c.warnings = append(c.warnings, message)
+}
+
 // GetWarningCount returns the current warning count
 func (c *Compiler) GetWarningCount() int {
 	return c.warningCount
 }
 
-// ResetWarningCount resets the warning counter to zero
+// GetWarnings returns the messages of the workflow warnings recorded since the last ResetWarningCount
+func (c *Compiler) GetWarnings() []string {
+	return c.warnings
+}
+
+// ResetWarningCount resets the warning counter to zero and clears the recorded warnings
 func (c *Compiler) ResetWarningCount() {
 	c.warningCount = 0
+	c.warnings = nil
 }
 
 // SetWorkflowIdentifier sets the identifier for the current workflow being compiled
@@ -417,6 +438,7 @@ type WorkflowData struct {
 	SkipIfMatch          *SkipIfMatchConfig   // skip-if-match configuration with query and max threshold
 	SkipIfNoMatch        *SkipIfNoMatchConfig // skip-if-no-match configuration with query and min threshold
 	ManualApproval       string               // environment name for manual approval from on: section
+	ScheduleConversions  []string             // schedules converted from a timezone to UTC (noted in the lock file header)
 	Command              []string             // for /command trigger support - multiple command names
 	CommandEvents        []string             // events where command should be active (nil = all events)
 	CommandOtherEvents   map[string]any       // for merging command with other events
@@ -432,6 +454,7 @@ type WorkflowData struct {
 	Roles                []string             // permission levels required to trigger workflow
 	Bots                 []string             // allow list of bot identifiers that can trigger workflow
 	RateLimit            *RateLimitConfig     // rate limiting configuration for workflow triggers
+	Budget               *BudgetConfig        // token, cost, and turn budget enforced at runtime
 	CacheMemoryConfig    *CacheMemoryConfig   // parsed cache-memory configuration
 	RepoMemoryConfig     *RepoMemoryConfig    // parsed repo-memory configuration
 	Runtimes             map[string]any       // runtime version overrides from frontmatter
@@ -447,12 +470,16 @@ type WorkflowData struct {
 	ParsedFrontmatter    *FrontmatterConfig   // cached parsed frontmatter configuration (for performance optimization)
 	ActionPinWarnings    map[string]bool      // cache of already-warned action pin failures (key: "repo@version")
 	ActionMode           ActionMode           // action mode for workflow compilation (dev, release, script)
+
+	// ImportsWithInputs lists the imports that pass inputs, for template variable validation
+	ImportsWithInputs []parser.ImportWithInputs
 }
 
 // BaseSafeOutputConfig holds common configuration fields for all safe output types
 type BaseSafeOutputConfig struct {
-	Max         int    `yaml:"max,omitempty"`          // Maximum number of items to create
-	GitHubToken string `yaml:"github-token,omitempty"` // GitHub token for this specific output type
+	Max             int                    `yaml:"max,omitempty"`              // Maximum number of items to create
+	GitHubToken     string                 `yaml:"github-token,omitempty"`     // GitHub token for this specific output type
+	RequireApproval *RequireApprovalConfig `yaml:"require-approval,omitempty"` // Environment approval required before this output type runs
 }
 
 // SafeOutputsConfig holds configuration for automatic output routes
@@ -469,6 +496,7 @@ type SafeOutputsConfig struct {
 	CreatePullRequestReviewComments *CreatePullRequestReviewCommentsConfig `yaml:"create-pull-request-review-comments,omitempty"`
 	SubmitPullRequestReview         *SubmitPullRequestReviewConfig         `yaml:"submit-pull-request-review,omitempty"` // Submit a PR review with status (APPROVE, REQUEST_CHANGES, COMMENT)
 	CreateCodeScanningAlerts        *CreateCodeScanningAlertsConfig        `yaml:"create-code-scanning-alerts,omitempty"`
+	CreateCheckRuns                 *CreateCheckRunConfig                  `yaml:"create-check-run,omitempty"` // Publish check runs with annotations on pull request head commits
 	AutofixCodeScanningAlert        *AutofixCodeScanningAlertConfig        `yaml:"autofix-code-scanning-alert,omitempty"`
 	AddLabels                       *AddLabelsConfig                       `yaml:"add-labels,omitempty"`
 	RemoveLabels                    *RemoveLabelsConfig                    `yaml:"remove-labels,omitempty"`
@@ -489,6 +517,7 @@ type SafeOutputsConfig struct {
 	LinkSubIssue                    *LinkSubIssueConfig                    `yaml:"link-sub-issue,omitempty"`               // Link issues as sub-issues
 	HideComment                     *HideCommentConfig                     `yaml:"hide-comment,omitempty"`                 // Hide comments
 	DispatchWorkflow                *DispatchWorkflowConfig                `yaml:"dispatch-workflow,omitempty"`            // Dispatch workflow_dispatch events to other workflows
+	NotifyWebhook                   *NotifyWebhookConfig                   `yaml:"notify-webhook,omitempty"`               // Post notifications to chat webhooks (Slack, Teams, raw JSON)
 	MissingTool                     *MissingToolConfig                     `yaml:"missing-tool,omitempty"`                 // Optional for reporting missing functionality
 	MissingData                     *MissingDataConfig                     `yaml:"missing-data,omitempty"`                 // Optional for reporting missing data required to achieve goals
 	NoOp                            *NoOpConfig                            `yaml:"noop,omitempty"`                         // No-op output for logging only (always available as fallback)
@@ -505,6 +534,11 @@ type SafeOutputsConfig struct {
 	Messages                        *SafeOutputMessagesConfig              `yaml:"messages,omitempty"`                  // Custom message templates for footer and notifications
 	Mentions                        *MentionsConfig                        `yaml:"mentions,omitempty"`                  // Configuration for @mention filtering in safe outputs
 	Footer                          *bool                                  `yaml:"footer,omitempty"`                    // Global footer control - when false, omits visible footer from all safe outputs (XML markers still included)
+
+	// Approval routing for the copies built by the require-approval jobs (not parsed from frontmatter)
+	awaitingApprovalFrom string // Job whose awaiting_approval output lists the types left to approval-gated jobs
+	approvalScope        string // Only type processed by an approval-gated job
+	temporaryIDMapFrom   string // Job whose temporary ID map seeds an approval-gated job
 }
 
 // SafeOutputMessagesConfig holds custom message templates for safe-output footer and notification messages
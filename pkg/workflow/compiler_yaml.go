@@ -45,9 +45,10 @@ func (c *Compiler) buildJobsAndValidate(data *WorkflowData, markdownPath string)
 }
 
 // generateWorkflowHeader generates the YAML header section including comments
-// for description, source, imports/includes, frontmatter-hash, stop-time, and manual-approval.
+// for description, source, imports/includes, frontmatter-hash, provenance, stop-time, schedule
+// timezone conversions, and manual-approval.
 // All ANSI escape codes are stripped from the output.
-func (c *Compiler) generateWorkflowHeader(yaml *strings.Builder, data *WorkflowData, frontmatterHash string) {
+func (c *Compiler) generateWorkflowHeader(yaml *strings.Builder, data *WorkflowData, frontmatterHash string, provenance *LockProvenance) {
 	// Add workflow header with logo and instructions
 	sourceFile := "the corresponding .md file"
 	if data.Source != "" {
@@ -108,6 +109,11 @@ func (c *Compiler) generateWorkflowHeader(yaml *strings.Builder, data *WorkflowD
 		fmt.Fprintf(yaml, "# frontmatter-hash: %s\n", frontmatterHash)
 	}
 
+	// Add provenance so that gh aw verify can explain how a recompiled lock file diverges
+	if provenance != nil {
+		writeLockProvenance(yaml, provenance)
+	}
+
 	// Add stop-time comment if configured
 	if data.StopTime != "" {
 		yaml.WriteString("#\n")
@@ -115,6 +121,15 @@ func (c *Compiler) generateWorkflowHeader(yaml *strings.Builder, data *WorkflowD
 		fmt.Fprintf(yaml, "# Effective stop-time: %s\n", cleanStopTime)
 	}
 
+	// Add schedule timezone conversions, since the lock file only contains UTC cron expressions
+	if len(data.ScheduleConversions) > 0 {
+		yaml.WriteString("#\n")
+		yaml.WriteString("# Schedule timezone conversion (local cron -> UTC cron):\n")
+		for _, conversion := range data.ScheduleConversions {
+			fmt.Fprintf(yaml, "#   %s\n", stringutil.StripANSIEscapeCodes(conversion))
+		}
+	}
+
 	// Add manual-approval comment if configured
 	if data.ManualApproval != "" {
 		yaml.WriteString("#\n")
@@ -181,14 +196,18 @@ func (c *Compiler) generateYAML(data *WorkflowData, markdownPath string) (string
 
 	// Pre-allocate builder capacity based on estimated workflow size
 	// Average workflow generates ~200KB, allocate 256KB to minimize reallocations
-	var yaml strings.Builder
-	yaml.Grow(256 * 1024)
+	var body strings.Builder
+	body.Grow(256 * 1024)
 
-	// Generate workflow header comments (including hash)
-	c.generateWorkflowHeader(&yaml, data, frontmatterHash)
+	// Generate workflow body structure first, since the provenance lists its pinned actions
+	c.generateWorkflowBody(&body, data)
+	provenance := c.buildLockProvenance(data, markdownPath, body.String())
 
-	// Generate workflow body structure
-	c.generateWorkflowBody(&yaml, data)
+	// Generate workflow header comments (including hash and provenance)
+	var yaml strings.Builder
+	yaml.Grow(body.Len() + 8*1024)
+	c.generateWorkflowHeader(&yaml, data, frontmatterHash, provenance)
+	yaml.WriteString(body.String())
 
 	yamlContent := yaml.String()
 
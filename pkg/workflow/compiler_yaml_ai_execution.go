@@ -8,7 +8,12 @@ import (
 // generateEngineExecutionSteps generates the GitHub Actions steps for executing the AI engine
 func (c *Compiler) generateEngineExecutionSteps(yaml *strings.Builder, data *WorkflowData, engine CodingAgentEngine, logFile string) {
 
-	steps := engine.GetExecutionSteps(data, logFile)
+	steps := engine.GetExecutionSteps(withBudgetMaxTurns(data, engine), logFile)
+	if data.Budget != nil {
+		if agentProcess := budgetAgentProcess(data, engine); agentProcess != "" {
+			recordAgentStepPID(steps, agentProcess)
+		}
+	}
 
 	for _, step := range steps {
 		for _, line := range step {
@@ -242,6 +242,9 @@ func (c *Compiler) generateMainJobSteps(yaml *strings.Builder, data *WorkflowDat
 		yaml.WriteString(line)
 	}
 
+	// Start the budget watcher so that a run going over budget is stopped while the agent runs
+	c.generateBudgetWatcherStep(yaml, data, engine)
+
 	// Add AI execution step using the agentic engine
 	compilerYamlLog.Printf("Generating engine execution steps for %s", engine.GetID())
 	c.generateEngineExecutionSteps(yaml, data, engine, logFileFull)
@@ -301,6 +304,14 @@ func (c *Compiler) generateMainJobSteps(yaml *strings.Builder, data *WorkflowDat
 	// This ensures all artifacts are scanned for secrets before being uploaded
 	c.generateSecretRedactionStep(yaml, yaml.String(), data)
 
+	// Enforce the frontmatter budget BEFORE safe outputs are collected
+	// A run that went over budget fails here and its safe outputs are discarded
+	c.generateBudgetEnforcementStep(yaml, data, engine)
+	if data.Budget != nil {
+		artifactPaths = append(artifactPaths, BudgetReportPath)
+		artifactPaths = append(artifactPaths, BudgetWatcherLogPath)
+	}
+
 	// Add output collection step only if safe-outputs feature is used (GH_AW_SAFE_OUTPUTS functionality)
 	if data.SafeOutputs != nil {
 		c.generateOutputCollectionStep(yaml, data)
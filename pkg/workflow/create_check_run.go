@@ -0,0 +1,163 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var createCheckRunLog = logger.New("workflow:create_check_run")
+
+// validCheckRunConclusions are the check run conclusions an agent may report. action_required is
+// left out because GitHub requires a details URL and actions for it.
+var validCheckRunConclusions = []string{"success", "failure", "neutral", "cancelled", "skipped", "timed_out"}
+
+// defaultCheckRunMaxAnnotations matches the number of annotations GitHub accepts per request
+const defaultCheckRunMaxAnnotations = 50
+
+// CreateCheckRunConfig holds configuration for publishing check runs with annotations from agent output
+type CreateCheckRunConfig struct {
+	BaseSafeOutputConfig `yaml:",inline"`
+	Name                 string   `yaml:"name,omitempty"`            // Check run name (default: workflow name); the agent cannot choose it
+	Target               string   `yaml:"target,omitempty"`          // Target pull request: "triggering" (default), "*" (any PR), or explicit number
+	MaxAnnotations       int      `yaml:"max-annotations,omitempty"` // Maximum annotations per check run (default: 50)
+	Conclusions          []string `yaml:"conclusions,omitempty"`     // Conclusions the agent may report (default: all valid conclusions)
+}
+
+// parseCreateCheckRunConfig handles create-check-run configuration
+func (c *Compiler) parseCreateCheckRunConfig(outputMap map[string]any) *CreateCheckRunConfig {
+	configData, exists := outputMap["create-check-run"]
+	if !exists {
+		return nil
+	}
+
+	createCheckRunLog.Print("Parsing create-check-run configuration")
+	config := &CreateCheckRunConfig{MaxAnnotations: defaultCheckRunMaxAnnotations}
+
+	configMap, ok := configData.(map[string]any)
+	if !ok {
+		// "create-check-run:" with no value uses the defaults
+		config.Max = 1
+		return config
+	}
+
+	if name, ok := configMap["name"].(string); ok {
+		config.Name = name
+	}
+	if target, ok := configMap["target"].(string); ok {
+		config.Target = target
+	}
+	if maxAnnotations, exists := configMap["max-annotations"]; exists {
+		if maxAnnotationsInt, ok := parseIntValue(maxAnnotations); ok {
+			config.MaxAnnotations = maxAnnotationsInt
+		}
+	}
+	config.Conclusions = ParseStringArrayFromConfig(configMap, "conclusions", createCheckRunLog)
+
+	// Parse common base fields with default max of 1
+	c.parseBaseSafeOutputConfig(configMap, &config.BaseSafeOutputConfig, 1)
+
+	createCheckRunLog.Printf("Parsed create-check-run config: name=%q, max=%d, max_annotations=%d, conclusions=%v",
+		config.Name, config.Max, config.MaxAnnotations, config.Conclusions)
+	return config
+}
+
+// allowedConclusions returns the conclusions the agent may report
+func (config *CreateCheckRunConfig) allowedConclusions() []string {
+	if len(config.Conclusions) > 0 {
+		return config.Conclusions
+	}
+	return validCheckRunConclusions
+}
+
+// validateCreateCheckRunConfig checks the create-check-run limits and allowed conclusions
+func validateCreateCheckRunConfig(config *SafeOutputsConfig) error {
+	if config == nil || config.CreateCheckRuns == nil {
+		return nil
+	}
+	checkRun := config.CreateCheckRuns
+
+	if checkRun.MaxAnnotations < 1 {
+		return fmt.Errorf("invalid safe-outputs.create-check-run.max-annotations %d: must be at least 1", checkRun.MaxAnnotations)
+	}
+	for _, conclusion := range checkRun.Conclusions {
+		if !slices.Contains(validCheckRunConclusions, conclusion) {
+			return fmt.Errorf("invalid safe-outputs.create-check-run.conclusions entry %q: must be one of %s", conclusion, strings.Join(validCheckRunConclusions, ", "))
+		}
+	}
+	return nil
+}
+
+// buildCreateCheckRunJob creates the create_check_run job
+func (c *Compiler) buildCreateCheckRunJob(data *WorkflowData, mainJobName string, threatDetectionEnabled bool) (*Job, error) {
+	createCheckRunLog.Printf("Building create_check_run job: workflow=%s, main_job=%s, threat_detection=%v", data.Name, mainJobName, threatDetectionEnabled)
+
+	if data.SafeOutputs == nil || data.SafeOutputs.CreateCheckRuns == nil {
+		return nil, fmt.Errorf("safe-outputs.create-check-run configuration is required")
+	}
+	config := data.SafeOutputs.CreateCheckRuns
+
+	var preSteps []string
+
+	// Add setup step to copy scripts
+	setupActionRef := c.resolveActionReference("./actions/setup", data)
+	if setupActionRef != "" || c.actionMode.IsScript() {
+		// For dev mode (local action path), checkout the actions folder first
+		preSteps = append(preSteps, c.generateCheckoutActionsFolder(data)...)
+
+		// Create check run job doesn't need project support
+		preSteps = append(preSteps, c.generateSetupStep(setupActionRef, SetupActionDestination, false)...)
+	}
+
+	checkName := config.Name
+	if checkName == "" {
+		checkName = data.Name
+	}
+
+	// Build custom environment variables specific to create-check-run
+	var customEnvVars []string
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_CHECK_RUN_NAME: %q\n", checkName))
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_CHECK_RUN_MAX: %d\n", config.Max))
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_CHECK_RUN_MAX_ANNOTATIONS: %d\n", config.MaxAnnotations))
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_CHECK_RUN_CONCLUSIONS: %q\n", strings.Join(config.allowedConclusions(), ",")))
+	if config.Target != "" {
+		customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_CHECK_RUN_TARGET: %q\n", config.Target))
+	}
+
+	// Add standard environment variables (metadata + staged/target repo)
+	customEnvVars = append(customEnvVars, c.buildStandardSafeOutputEnvVars(data, "")...) // Check runs are created in the current repository
+
+	// Create outputs for the job
+	outputs := map[string]string{
+		"check_run_id":  "${{ steps.create_check_run.outputs.check_run_id }}",
+		"check_run_url": "${{ steps.create_check_run.outputs.check_run_url }}",
+	}
+
+	// Build job dependencies; agent output flagged by threat detection must not be published
+	needs := []string{mainJobName}
+	condition := BuildSafeOutputType("create_check_run")
+	if threatDetectionEnabled {
+		needs = append(needs, string(constants.DetectionJobName))
+		condition = BuildAnd(condition, buildDetectionSuccessCondition())
+		createCheckRunLog.Printf("Added detection job dependency for create_check_run")
+	}
+
+	// Use the shared builder function to create the job
+	return c.buildSafeOutputJob(data, SafeOutputJobConfig{
+		JobName:       "create_check_run",
+		StepName:      "Create Check Run",
+		StepID:        "create_check_run",
+		ScriptName:    "create_check_run",
+		MainJobName:   mainJobName,
+		CustomEnvVars: customEnvVars,
+		Permissions:   NewPermissionsChecksWrite(),
+		Outputs:       outputs,
+		Condition:     condition,
+		PreSteps:      preSteps,
+		Token:         config.GitHubToken,
+		Needs:         needs,
+	})
+}
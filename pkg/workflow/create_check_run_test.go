@@ -0,0 +1,130 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreateCheckRunConfig(t *testing.T) {
+	c := &Compiler{}
+
+	tests := []struct {
+		name     string
+		input    map[string]any
+		expected *CreateCheckRunConfig
+	}{
+		{
+			name:     "no create-check-run config",
+			input:    map[string]any{},
+			expected: nil,
+		},
+		{
+			name:  "create-check-run with no value uses defaults",
+			input: map[string]any{"create-check-run": nil},
+			expected: &CreateCheckRunConfig{
+				BaseSafeOutputConfig: BaseSafeOutputConfig{Max: 1},
+				MaxAnnotations:       50,
+			},
+		},
+		{
+			name: "create-check-run with custom values",
+			input: map[string]any{
+				"create-check-run": map[string]any{
+					"name":            "AI Review",
+					"target":          "*",
+					"max":             2,
+					"max-annotations": 200,
+					"conclusions":     []any{"success", "neutral"},
+					"github-token":    "${{ secrets.CHECKS_TOKEN }}",
+				},
+			},
+			expected: &CreateCheckRunConfig{
+				BaseSafeOutputConfig: BaseSafeOutputConfig{Max: 2, GitHubToken: "${{ secrets.CHECKS_TOKEN }}"},
+				Name:                 "AI Review",
+				Target:               "*",
+				MaxAnnotations:       200,
+				Conclusions:          []string{"success", "neutral"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.parseCreateCheckRunConfig(tt.input))
+		})
+	}
+}
+
+func TestValidateCreateCheckRunConfig(t *testing.T) {
+	require.NoError(t, validateCreateCheckRunConfig(nil))
+	require.NoError(t, validateCreateCheckRunConfig(&SafeOutputsConfig{
+		CreateCheckRuns: &CreateCheckRunConfig{MaxAnnotations: 50, Conclusions: []string{"success", "failure"}},
+	}))
+
+	err := validateCreateCheckRunConfig(&SafeOutputsConfig{
+		CreateCheckRuns: &CreateCheckRunConfig{MaxAnnotations: 50, Conclusions: []string{"success", "action_required"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `conclusions entry "action_required"`)
+
+	err = validateCreateCheckRunConfig(&SafeOutputsConfig{CreateCheckRuns: &CreateCheckRunConfig{MaxAnnotations: 0}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max-annotations 0")
+}
+
+func TestCreateCheckRunJob(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "review.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: pull_request
+permissions:
+  contents: read
+  issues: read
+  pull-requests: read
+safe-outputs:
+  create-check-run:
+    name: AI Review
+    max-annotations: 100
+    conclusions: [success, failure, neutral]
+---
+
+# Review
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	jobSection := extractJobSection(lockYAML, "create_check_run")
+	require.NotEmpty(t, jobSection, "create_check_run job should be generated")
+	assert.Contains(t, jobSection, "    permissions:\n      checks: write\n    timeout-minutes: 10", "job should only get checks: write")
+	assert.Contains(t, jobSection, "- detection", "job should wait for threat detection")
+	assert.Contains(t, jobSection, `    if: >
+      (((!cancelled()) && (needs.agent.result != 'skipped')) && (contains(needs.agent.outputs.output_types, 'create_check_run'))) &&
+      (needs.detection.outputs.success == 'true')
+`, "job should not run when threat detection flags the output")
+	assert.Contains(t, jobSection, `GH_AW_CHECK_RUN_NAME: "AI Review"`)
+	assert.Contains(t, jobSection, "GH_AW_CHECK_RUN_MAX_ANNOTATIONS: 100")
+	assert.Contains(t, jobSection, `GH_AW_CHECK_RUN_CONCLUSIONS: "success,failure,neutral"`)
+	assert.Contains(t, jobSection, "require('/opt/gh-aw/actions/create_check_run.cjs')")
+	assert.Contains(t, lockYAML, "      - create_check_run\n", "conclusion job should depend on the check run job")
+
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: pull_request
+safe-outputs:
+  create-check-run:
+    max-annotations: 0
+---
+
+# Review
+`), 0644))
+	_, _, err = compiler.CompileWorkflowToYAML(workflowPath)
+	require.Error(t, err)
+}
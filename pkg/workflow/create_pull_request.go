@@ -34,6 +34,8 @@ type CreatePullRequestsConfig struct {
 	BaseBranch           string   `yaml:"base-branch,omitempty"`       // Base branch for the pull request (defaults to github.ref_name if not specified)
 	Footer               *bool    `yaml:"footer,omitempty"`            // Controls whether AI-generated footer is added. When false, visible footer is omitted but XML markers are kept.
 	FallbackAsIssue      *bool    `yaml:"fallback-as-issue,omitempty"` // When true (default), creates an issue if PR creation fails. When false, no fallback occurs and issues: write permission is not requested.
+	AllowedPaths         []string `yaml:"allowed-paths,omitempty"`     // Globs of the paths the patch may modify. Protected paths must be listed exactly, without wildcards.
+	BlockedPaths         []string `yaml:"blocked-paths,omitempty"`     // Globs of the paths the patch may never modify
 }
 
 // buildCreateOutputPullRequestJob creates the create_pull_request job
@@ -124,6 +124,16 @@ func getEcosystemDomains(category string) []string {
 	return result
 }
 
+// GetEcosystemIdentifiers returns the sorted ecosystem identifiers that can be used in network.allowed
+func GetEcosystemIdentifiers() []string {
+	identifiers := make([]string, 0, len(ecosystemDomains))
+	for category := range ecosystemDomains {
+		identifiers = append(identifiers, category)
+	}
+	SortStrings(identifiers)
+	return identifiers
+}
+
 // runtimeToEcosystem maps runtime IDs to their corresponding ecosystem categories in ecosystem_domains.json
 // Some runtimes share ecosystems (e.g., bun and deno use node ecosystem domains)
 var runtimeToEcosystem = map[string]string{
@@ -271,8 +281,8 @@ func GetAllowedDomains(network *NetworkPermissions) []string {
 
 // GetDomainEcosystem returns the ecosystem identifier for a given domain, or empty string if not found
 func GetDomainEcosystem(domain string) string {
-	// Check each ecosystem for domain match
-	for ecosystem := range ecosystemDomains {
+	// Check each ecosystem for domain match, in a stable order
+	for _, ecosystem := range GetEcosystemIdentifiers() {
 		domains := getEcosystemDomains(ecosystem)
 		for _, ecosystemDomain := range domains {
 			if matchesDomain(domain, ecosystemDomain) {
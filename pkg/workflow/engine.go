@@ -25,6 +25,7 @@ type EngineConfig struct {
 	Args        []string
 	Firewall    *FirewallConfig // AWF firewall configuration
 	Agent       string          // Agent identifier for copilot --agent flag (copilot engine only)
+	Fixture     string          // Recorded session fixture path relative to the repository root (replay engine only)
 }
 
 // NetworkPermissions represents network access permissions for workflow execution
@@ -209,6 +210,14 @@ func (c *Compiler) ExtractEngineConfig(frontmatter map[string]any) (string, *Eng
 				}
 			}
 
+			// Extract optional 'fixture' field (string - replay engine only)
+			if fixture, hasFixture := engineObj["fixture"]; hasFixture {
+				if fixtureStr, ok := fixture.(string); ok {
+					config.Fixture = fixtureStr
+					engineLog.Printf("Extracted replay fixture: %s", fixtureStr)
+				}
+			}
+
 			// Extract optional 'firewall' field (object format)
 			if firewall, hasFirewall := engineObj["firewall"]; hasFirewall {
 				if firewallObj, ok := firewall.(map[string]any); ok {
@@ -268,6 +277,12 @@ func (c *Compiler) getAgenticEngine(engineSetting string) (CodingAgentEngine, er
 
 	engineLog.Printf("Getting agentic engine for setting: %s", engineSetting)
 
+	// Engines imported by the current workflow take precedence over the registry
+	if c.workflowEngines != nil && c.workflowEngines.IsValidEngine(engineSetting) {
+		engineLog.Printf("Found imported engine: %s", engineSetting)
+		return c.workflowEngines.GetEngine(engineSetting)
+	}
+
 	// First try exact match
 	if c.engineRegistry.IsValidEngine(engineSetting) {
 		engine, err := c.engineRegistry.GetEngine(engineSetting)
@@ -277,7 +292,7 @@ func (c *Compiler) getAgenticEngine(engineSetting string) (CodingAgentEngine, er
 		return engine, err
 	}
 
-	// Try prefix match for backward compatibility
+	// Try prefix match for backward compatibility (built-in engines only)
 	engine, err := c.engineRegistry.GetEngineByPrefix(engineSetting)
 	if err == nil {
 		engineLog.Printf("Found engine by prefix match: %s", engine.GetID())
@@ -0,0 +1,489 @@
+// This file implements declarative engine definitions. An engine definition is a YAML file that
+// describes how to install and run an agent CLI, so that teams can add engines without writing Go:
+//
+//	id: acme
+//	display-name: Acme Agent
+//	secrets: [ACME_API_KEY]
+//	install:
+//	  - name: Install Acme CLI
+//	    run: npm install -g @acme/cli@{version}
+//	execute:
+//	  command: acme run --prompt-file {prompt-file} --mcp-config {mcp-config}
+//	mcp:
+//	  format: json
+//	log-parser: claude
+//	capabilities:
+//	  max-turns: true
+//
+// Definitions are loaded from .github/aw/engines/*.yml and from workflow imports.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/goccy/go-yaml"
+)
+
+var engineDefinitionLog = logger.New("workflow:engine_definition")
+
+var (
+	engineDefinitionIDPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	secretNamePattern         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretReferencePattern    = regexp.MustCompile(`secrets\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// builtinEngineIDs lists the engines implemented in Go, which definitions cannot replace
+var builtinEngineIDs = []string{"claude", "codex", "copilot", "custom", "replay"}
+
+// EngineDefinition is a declarative engine specification loaded from YAML
+type EngineDefinition struct {
+	ID           string                   `yaml:"id"`
+	DisplayName  string                   `yaml:"display-name,omitempty"`
+	Description  string                   `yaml:"description,omitempty"`
+	Experimental bool                     `yaml:"experimental,omitempty"`
+	Version      string                   `yaml:"version,omitempty"`    // Default for the {version} placeholder
+	Secrets      []string                 `yaml:"secrets,omitempty"`    // Secrets the engine needs; all are required
+	Install      []map[string]any         `yaml:"install,omitempty"`    // Steps that install the engine CLI
+	Execute      EngineExecuteDefinition  `yaml:"execute"`              // How to run the engine
+	MCP          EngineMCPDefinition      `yaml:"mcp,omitempty"`        // MCP configuration file format
+	LogParser    string                   `yaml:"log-parser,omitempty"` // claude, codex, copilot or custom
+	Capabilities EngineCapabilitiesConfig `yaml:"capabilities,omitempty"`
+	Source       string                   `yaml:"-"` // File the definition was loaded from
+}
+
+// EngineExecuteDefinition describes the command that runs the agent
+type EngineExecuteDefinition struct {
+	Command     string            `yaml:"command"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	OutputFiles []string          `yaml:"output-files,omitempty"` // Files uploaded as artifacts when present
+}
+
+// EngineMCPDefinition describes the MCP configuration file the engine reads
+type EngineMCPDefinition struct {
+	Format string `yaml:"format,omitempty"` // json (default) or toml
+}
+
+// EngineCapabilitiesConfig declares the optional features an engine supports
+type EngineCapabilitiesConfig struct {
+	MaxTurns       bool `yaml:"max-turns,omitempty"`
+	ToolsAllowlist bool `yaml:"tools-allowlist,omitempty"`
+	HTTPTransport  bool `yaml:"http-transport,omitempty"`
+	WebFetch       bool `yaml:"web-fetch,omitempty"`
+	WebSearch      bool `yaml:"web-search,omitempty"`
+}
+
+// ParseEngineDefinition parses and validates an engine definition. The source is used in error
+// messages.
+func ParseEngineDefinition(content []byte, source string) (*EngineDefinition, error) {
+	var definition EngineDefinition
+	if err := yaml.UnmarshalWithOptions(content, &definition, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("invalid engine definition %s: %s", source, yaml.FormatError(err, false, false))
+	}
+	definition.Source = source
+
+	if definition.DisplayName == "" {
+		definition.DisplayName = definition.ID
+	}
+	if definition.MCP.Format == "" {
+		definition.MCP.Format = "json"
+	}
+	if definition.LogParser == "" {
+		definition.LogParser = "custom"
+	}
+
+	if err := definition.validate(); err != nil {
+		return nil, fmt.Errorf("invalid engine definition %s: %w", source, err)
+	}
+	engineDefinitionLog.Printf("Parsed engine definition: id=%s, source=%s", definition.ID, source)
+	return &definition, nil
+}
+
+// validate checks the fields of a parsed definition
+func (d *EngineDefinition) validate() error {
+	if d.ID == "" {
+		return errors.New("missing 'id'")
+	}
+	if !engineDefinitionIDPattern.MatchString(d.ID) {
+		return fmt.Errorf("id %q must start with a lowercase letter and contain only lowercase letters, digits and hyphens", d.ID)
+	}
+	for _, builtin := range builtinEngineIDs {
+		if d.ID == builtin {
+			return fmt.Errorf("id %q is a built-in engine and cannot be redefined", d.ID)
+		}
+	}
+	if strings.TrimSpace(d.Execute.Command) == "" {
+		return errors.New("missing 'execute.command'")
+	}
+	if d.MCP.Format != "json" && d.MCP.Format != "toml" {
+		return fmt.Errorf("mcp.format must be 'json' or 'toml', got %q", d.MCP.Format)
+	}
+	if _, ok := engineDefinitionLogParsers[d.LogParser]; !ok {
+		return fmt.Errorf("log-parser must be one of claude, codex, copilot or custom, got %q", d.LogParser)
+	}
+	for _, secret := range d.Secrets {
+		if !secretNamePattern.MatchString(secret) {
+			return fmt.Errorf("invalid secret name %q", secret)
+		}
+	}
+	for i, step := range d.Install {
+		if err := validateInstallStep(step); err != nil {
+			return fmt.Errorf("install[%d]: %w", i, err)
+		}
+	}
+
+	// Secrets outside the declared list would be filtered out of the execution step, so reject
+	// them early with a clear message
+	for _, key := range slices.Sorted(maps.Keys(d.Execute.Env)) {
+		for _, match := range secretReferencePattern.FindAllStringSubmatch(d.Execute.Env[key], -1) {
+			if !slices.Contains(d.Secrets, match[1]) {
+				return fmt.Errorf("execute.env.%s references secret %s, which is not listed in 'secrets'", key, match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// validateInstallStep checks that an install step runs a command or uses an action, and that it
+// can be written to the lock file
+func validateInstallStep(step map[string]any) error {
+	run, hasRun := step["run"]
+	uses, hasUses := step["uses"]
+	if hasRun == hasUses {
+		return errors.New("step must have either 'run' or 'uses'")
+	}
+	if _, ok := run.(string); hasRun && !ok {
+		return errors.New("'run' must be a string")
+	}
+	if _, ok := uses.(string); hasUses && !ok {
+		return errors.New("'uses' must be a string")
+	}
+	if _, err := MapToStep(step); err != nil {
+		return err
+	}
+	if _, err := ConvertStepToYAML(step); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LoadEngineDefinitionFile reads and parses an engine definition file
+func LoadEngineDefinitionFile(path string) (*EngineDefinition, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read engine definition: %w", err)
+	}
+	return ParseEngineDefinition(content, path)
+}
+
+// LoadEngineDefinitions registers the engine definitions (*.yml and *.yaml) found in dir and
+// returns how many were loaded. A missing directory is not an error.
+func (r *EngineRegistry) LoadEngineDefinitions(dir string) (int, error) {
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		definition, err := LoadEngineDefinitionFile(file)
+		if err != nil {
+			return 0, err
+		}
+		if existing, err := r.GetEngine(definition.ID); err == nil {
+			if defined, ok := existing.(*DefinedEngine); ok {
+				return 0, fmt.Errorf("engine %q is defined in both %s and %s", definition.ID, defined.definition.Source, file)
+			}
+		}
+		r.Register(NewDefinedEngine(definition))
+	}
+	engineDefinitionLog.Printf("Loaded %d engine definitions from %s", len(files), dir)
+	return len(files), nil
+}
+
+// WithRepositoryEngines returns the registry's engines plus the engine definitions of the
+// repository at gitRoot. The registry itself is returned when the repository has none.
+func (r *EngineRegistry) WithRepositoryEngines(gitRoot string) (*EngineRegistry, error) {
+	if gitRoot == "" {
+		return r, nil
+	}
+	dir := filepath.Join(gitRoot, constants.GetEngineDefinitionsDir())
+	if _, err := os.Stat(dir); err != nil {
+		return r, nil
+	}
+
+	registry := r.Clone()
+	if _, err := registry.LoadEngineDefinitions(dir); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// GetRepositoryEngineRegistry returns the global registry plus the engine definitions of the
+// current repository. Invalid definitions are skipped here; compilation reports them.
+func GetRepositoryEngineRegistry() *EngineRegistry {
+	registry, err := GetGlobalEngineRegistry().WithRepositoryEngines(findGitRoot())
+	if err != nil {
+		engineDefinitionLog.Printf("Ignoring repository engine definitions: %v", err)
+		return GetGlobalEngineRegistry()
+	}
+	return registry
+}
+
+// loadEngineDefinitions adds the repository's engine definitions to the compiler's registry on
+// first use, and makes the engine definitions imported by the current workflow available to it.
+// Imported engines are kept per workflow so they don't leak into other workflows.
+func (c *Compiler) loadEngineDefinitions(importedDefinitions []string) error {
+	if !c.engineDefinitionsLoaded {
+		registry, err := c.engineRegistry.WithRepositoryEngines(c.gitRoot)
+		if err != nil {
+			return err
+		}
+		c.engineRegistry = registry
+		c.engineDefinitionsLoaded = true
+	}
+
+	c.workflowEngines = nil
+	for _, path := range importedDefinitions {
+		definition, err := LoadEngineDefinitionFile(path)
+		if err != nil {
+			return err
+		}
+		if c.workflowEngines == nil {
+			c.workflowEngines = &EngineRegistry{engines: make(map[string]CodingAgentEngine)}
+		}
+		engineDefinitionLog.Printf("Using imported engine definition: id=%s, source=%s", definition.ID, path)
+		c.workflowEngines.Register(NewDefinedEngine(definition))
+	}
+	return nil
+}
+
+// engineDefinitionLogParsers maps log-parser values to the engines whose parsers are reused
+var engineDefinitionLogParsers = map[string]func() CodingAgentEngine{
+	"claude":  func() CodingAgentEngine { return NewClaudeEngine() },
+	"codex":   func() CodingAgentEngine { return NewCodexEngine() },
+	"copilot": func() CodingAgentEngine { return NewCopilotEngine() },
+	"custom":  func() CodingAgentEngine { return NewCustomEngine() },
+}
+
+// DefinedEngine is an agentic engine built from an EngineDefinition. MCP configuration and log
+// parsing are delegated to the built-in engine whose format the definition selects.
+type DefinedEngine struct {
+	BaseEngine
+	definition  *EngineDefinition
+	mcpRenderer MCPConfigProvider
+	logParser   LogParser
+}
+
+// NewDefinedEngine creates an engine from a validated definition
+func NewDefinedEngine(definition *EngineDefinition) *DefinedEngine {
+	var mcpRenderer MCPConfigProvider = NewCustomEngine()
+	if definition.MCP.Format == "toml" {
+		mcpRenderer = NewCodexEngine()
+	}
+
+	return &DefinedEngine{
+		BaseEngine: BaseEngine{
+			id:                     definition.ID,
+			displayName:            definition.DisplayName,
+			description:            definition.Description,
+			experimental:           definition.Experimental,
+			supportsToolsAllowlist: definition.Capabilities.ToolsAllowlist,
+			supportsHTTPTransport:  definition.Capabilities.HTTPTransport,
+			supportsMaxTurns:       definition.Capabilities.MaxTurns,
+			supportsWebFetch:       definition.Capabilities.WebFetch,
+			supportsWebSearch:      definition.Capabilities.WebSearch,
+		},
+		definition:  definition,
+		mcpRenderer: mcpRenderer,
+		logParser:   engineDefinitionLogParsers[definition.LogParser](),
+	}
+}
+
+// Definition returns the definition the engine was built from
+func (e *DefinedEngine) Definition() *EngineDefinition {
+	return e.definition
+}
+
+// GetRequiredSecretNames returns the declared secrets plus the MCP gateway key and safe-inputs
+// secrets when those features are used
+func (e *DefinedEngine) GetRequiredSecretNames(workflowData *WorkflowData) []string {
+	secrets := append([]string{}, e.definition.Secrets...)
+
+	if HasMCPServers(workflowData) {
+		secrets = append(secrets, "MCP_GATEWAY_API_KEY")
+	}
+
+	if IsSafeInputsEnabled(workflowData.SafeInputs, workflowData) {
+		for _, varName := range slices.Sorted(maps.Keys(collectSafeInputsSecrets(workflowData.SafeInputs))) {
+			secrets = append(secrets, varName)
+		}
+	}
+
+	return secrets
+}
+
+// GetDeclaredOutputFiles returns the output files declared by the definition
+func (e *DefinedEngine) GetDeclaredOutputFiles() []string {
+	return append([]string{}, e.definition.Execute.OutputFiles...)
+}
+
+// GetInstallationSteps returns a validation step for each declared secret followed by the
+// definition's install steps
+func (e *DefinedEngine) GetInstallationSteps(workflowData *WorkflowData) []GitHubActionStep {
+	engineDefinitionLog.Printf("Generating installation steps for defined engine %s: workflow=%s", e.id, workflowData.Name)
+
+	var steps []GitHubActionStep
+	for _, secret := range e.definition.Secrets {
+		steps = append(steps, GenerateSecretValidationStep(secret, e.displayName, constants.DocsEnginesURL.String()))
+	}
+
+	replacer := strings.NewReplacer("{version}", e.version(workflowData))
+	for _, step := range e.definition.Install {
+		stepMap := substituteStepPlaceholders(step, replacer)
+		if typedStep, err := MapToStep(stepMap); err == nil {
+			stepMap = ApplyActionPinToTypedStep(typedStep, workflowData).ToMap()
+		}
+		// Install steps that cannot be converted are rejected when the definition is parsed
+		stepYAML, _ := e.convertStepToYAML(stepMap)
+		steps = append(steps, GitHubActionStep(strings.Split(strings.TrimRight(stepYAML, "\n"), "\n")))
+	}
+	return steps
+}
+
+// GetExecutionSteps returns the step that runs the definition's command, with its output
+// appended to the agent log
+func (e *DefinedEngine) GetExecutionSteps(workflowData *WorkflowData, logFile string) []GitHubActionStep {
+	engineDefinitionLog.Printf("Generating execution steps for defined engine %s: workflow=%s", e.id, workflowData.Name)
+
+	mcpConfig := "/tmp/gh-aw/mcp-config/mcp-servers.json"
+	if e.definition.MCP.Format == "toml" {
+		mcpConfig = "/tmp/gh-aw/mcp-config/config.toml"
+	}
+
+	var model, maxTurns string
+	var args []string
+	if workflowData.EngineConfig != nil {
+		model = workflowData.EngineConfig.Model
+		maxTurns = workflowData.EngineConfig.MaxTurns
+		args = workflowData.EngineConfig.Args
+	}
+
+	replacer := strings.NewReplacer(
+		"{prompt-file}", "/tmp/gh-aw/aw-prompts/prompt.txt",
+		"{mcp-config}", mcpConfig,
+		"{log-file}", logFile,
+		"{model}", shellEscapeArg(model),
+		"{max-turns}", maxTurns,
+		"{args}", shellJoinArgs(args),
+		"{version}", shellEscapeArg(e.version(workflowData)),
+	)
+	command := fmt.Sprintf("set -o pipefail\n%s 2>&1 | tee -a %s", strings.TrimSpace(replacer.Replace(e.definition.Execute.Command)), logFile)
+
+	env := map[string]string{
+		"GH_AW_PROMPT":        "/tmp/gh-aw/aw-prompts/prompt.txt",
+		"GH_AW_MCP_CONFIG":    mcpConfig,
+		"GITHUB_STEP_SUMMARY": "${{ env.GITHUB_STEP_SUMMARY }}",
+	}
+	for _, secret := range e.definition.Secrets {
+		env[secret] = fmt.Sprintf("${{ secrets.%s }}", secret)
+	}
+	if model != "" {
+		env["GH_AW_MODEL"] = model
+	}
+	if maxTurns != "" {
+		env["GH_AW_MAX_TURNS"] = maxTurns
+	}
+	applySafeOutputEnvToMap(env, workflowData)
+	for key, value := range e.definition.Execute.Env {
+		env[key] = value
+	}
+
+	// Add custom environment variables from engine config
+	if workflowData.EngineConfig != nil {
+		for key, value := range workflowData.EngineConfig.Env {
+			env[key] = value
+		}
+	}
+
+	// Add safe-inputs secrets to env for passthrough to MCP servers
+	if IsSafeInputsEnabled(workflowData.SafeInputs, workflowData) {
+		for varName, secretExpr := range collectSafeInputsSecrets(workflowData.SafeInputs) {
+			if _, exists := env[varName]; !exists {
+				env[varName] = secretExpr
+			}
+		}
+	}
+
+	stepLines := []string{
+		fmt.Sprintf("      - name: Execute %s", e.displayName),
+		"        id: agentic_execution",
+	}
+	if workflowData.TimeoutMinutes != "" {
+		timeoutValue := strings.TrimPrefix(workflowData.TimeoutMinutes, "timeout-minutes: ")
+		stepLines = append(stepLines, fmt.Sprintf("        timeout-minutes: %s", timeoutValue))
+	} else {
+		stepLines = append(stepLines, fmt.Sprintf("        timeout-minutes: %d", int(constants.DefaultAgenticWorkflowTimeout/time.Minute)))
+	}
+
+	// Only declared secrets reach the engine
+	filteredEnv := FilterEnvForSecrets(env, e.GetRequiredSecretNames(workflowData))
+	stepLines = FormatStepWithCommandAndEnv(stepLines, command, filteredEnv)
+
+	return []GitHubActionStep{GitHubActionStep(stepLines)}
+}
+
+// RenderMCPConfig renders the MCP configuration in the definition's format
+func (e *DefinedEngine) RenderMCPConfig(yaml *strings.Builder, tools map[string]any, mcpTools []string, workflowData *WorkflowData) {
+	e.mcpRenderer.RenderMCPConfig(yaml, tools, mcpTools, workflowData)
+}
+
+// ParseLogMetrics parses the agent log with the parser the definition selects
+func (e *DefinedEngine) ParseLogMetrics(logContent string, verbose bool) LogMetrics {
+	return e.logParser.ParseLogMetrics(logContent, verbose)
+}
+
+// GetLogParserScriptId returns the JavaScript log parser the definition selects
+func (e *DefinedEngine) GetLogParserScriptId() string {
+	return e.logParser.GetLogParserScriptId()
+}
+
+// version returns the engine version: engine.version from the workflow, or the definition default
+func (e *DefinedEngine) version(workflowData *WorkflowData) string {
+	if workflowData.EngineConfig != nil && workflowData.EngineConfig.Version != "" {
+		return workflowData.EngineConfig.Version
+	}
+	return e.definition.Version
+}
+
+// substituteStepPlaceholders returns a copy of a step with the placeholders in its string values
+// replaced, including those nested in 'with' and 'env'
+func substituteStepPlaceholders(step map[string]any, replacer *strings.Replacer) map[string]any {
+	result := make(map[string]any, len(step))
+	for key, value := range step {
+		switch v := value.(type) {
+		case string:
+			result[key] = replacer.Replace(v)
+		case map[string]any:
+			result[key] = substituteStepPlaceholders(v, replacer)
+		default:
+			result[key] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const acmeEngineDefinition = `id: acme
+display-name: Acme Agent
+description: Internal Acme coding agent
+version: 1.2.3
+secrets:
+  - ACME_API_KEY
+install:
+  - name: Install Acme CLI
+    run: npm install -g @acme/cli@{version}
+execute:
+  command: acme run --prompt-file {prompt-file} --mcp-config {mcp-config} --model {model} {args}
+  env:
+    ACME_TOKEN: ${{ secrets.ACME_API_KEY }}
+    ACME_TELEMETRY: "off"
+  output-files:
+    - /tmp/gh-aw/acme/
+log-parser: claude
+capabilities:
+  max-turns: true
+  web-fetch: true
+`
+
+func TestParseEngineDefinition(t *testing.T) {
+	definition, err := ParseEngineDefinition([]byte(acmeEngineDefinition), "acme.yml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", definition.ID)
+	assert.Equal(t, "Acme Agent", definition.DisplayName)
+	assert.Equal(t, "json", definition.MCP.Format, "mcp format should default to json")
+	assert.Equal(t, "claude", definition.LogParser)
+	assert.True(t, definition.Capabilities.MaxTurns)
+	assert.Equal(t, "acme.yml", definition.Source)
+
+	minimal, err := ParseEngineDefinition([]byte("id: tiny\nexecute:\n  command: tiny\n"), "tiny.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "tiny", minimal.DisplayName, "display name should default to the id")
+	assert.Equal(t, "custom", minimal.LogParser, "log parser should default to custom")
+}
+
+func TestParseEngineDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{name: "missing id", content: "execute:\n  command: x\n", errContains: "missing 'id'"},
+		{name: "invalid id", content: "id: Acme\nexecute:\n  command: x\n", errContains: "must start with a lowercase letter"},
+		{name: "built-in id", content: "id: claude\nexecute:\n  command: x\n", errContains: "built-in engine"},
+		{name: "missing command", content: "id: acme\nexecute: {}\n", errContains: "missing 'execute.command'"},
+		{name: "unknown mcp format", content: "id: acme\nexecute:\n  command: x\nmcp:\n  format: yaml\n", errContains: "mcp.format"},
+		{name: "unknown log parser", content: "id: acme\nexecute:\n  command: x\nlog-parser: gemini\n", errContains: "log-parser"},
+		{name: "unknown field", content: "id: acme\nexecute:\n  command: x\ninstal: []\n", errContains: "instal"},
+		{name: "install step without run or uses", content: "id: acme\ninstall:\n  - name: Setup\nexecute:\n  command: x\n", errContains: "install[0]: step must have either 'run' or 'uses'"},
+		{name: "install step with a non-string run", content: "id: acme\ninstall:\n  - run: [npm, install]\nexecute:\n  command: x\n", errContains: "install[0]: 'run' must be a string"},
+		{
+			name:        "undeclared secret",
+			content:     "id: acme\nexecute:\n  command: x\n  env:\n    TOKEN: ${{ secrets.OTHER_KEY }}\n",
+			errContains: "execute.env.TOKEN references secret OTHER_KEY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEngineDefinition([]byte(tt.content), "engine.yml")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "engine.yml")
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestDefinedEngineSteps(t *testing.T) {
+	definition, err := ParseEngineDefinition([]byte(acmeEngineDefinition), "acme.yml")
+	require.NoError(t, err)
+	engine := NewDefinedEngine(definition)
+
+	assert.Equal(t, "acme", engine.GetID())
+	assert.True(t, engine.SupportsMaxTurns())
+	assert.True(t, engine.SupportsWebFetch())
+	assert.False(t, engine.SupportsFirewall())
+	assert.Equal(t, "parse_claude_log", engine.GetLogParserScriptId())
+	assert.Equal(t, []string{"/tmp/gh-aw/acme/"}, engine.GetDeclaredOutputFiles())
+
+	workflowData := &WorkflowData{
+		Name:         "test",
+		EngineConfig: &EngineConfig{ID: "acme", Model: "acme-large", Args: []string{"--verbose"}},
+	}
+
+	install := engine.GetInstallationSteps(workflowData)
+	require.Len(t, install, 2, "secret validation plus the install step")
+	assert.Contains(t, strings.Join(install[0], "\n"), "Validate ACME_API_KEY secret")
+	assert.Contains(t, strings.Join(install[1], "\n"), "npm install -g @acme/cli@1.2.3")
+
+	workflowData.EngineConfig.Version = "2.0.0"
+	install = engine.GetInstallationSteps(workflowData)
+	assert.Contains(t, strings.Join(install[1], "\n"), "npm install -g @acme/cli@2.0.0", "engine.version should override the definition default")
+
+	execution := engine.GetExecutionSteps(workflowData, "/tmp/gh-aw/agent-stdio.log")
+	require.Len(t, execution, 1)
+	step := strings.Join(execution[0], "\n")
+	assert.Contains(t, step, "- name: Execute Acme Agent")
+	assert.Contains(t, step, "id: agentic_execution")
+	assert.Contains(t, step, `acme run --prompt-file /tmp/gh-aw/aw-prompts/prompt.txt --mcp-config /tmp/gh-aw/mcp-config/mcp-servers.json --model acme-large --verbose 2>&1 | tee -a /tmp/gh-aw/agent-stdio.log`)
+	assert.Contains(t, step, "ACME_API_KEY: ${{ secrets.ACME_API_KEY }}")
+	assert.Contains(t, step, "ACME_TOKEN: ${{ secrets.ACME_API_KEY }}")
+	assert.Contains(t, step, "ACME_TELEMETRY: off")
+	assert.Contains(t, step, "GH_AW_MODEL: acme-large")
+
+	workflowData.EngineConfig.Model = "acme large; rm -rf /"
+	step = strings.Join(engine.GetExecutionSteps(workflowData, "/tmp/gh-aw/agent-stdio.log")[0], "\n")
+	assert.Contains(t, step, `--model 'acme large; rm -rf /' --verbose`, "{model} should be shell-quoted like {args}")
+}
+
+func TestDefinedEngineTOMLConfig(t *testing.T) {
+	definition, err := ParseEngineDefinition([]byte("id: acme\nexecute:\n  command: acme --config {mcp-config}\nmcp:\n  format: toml\n"), "acme.yml")
+	require.NoError(t, err)
+	engine := NewDefinedEngine(definition)
+
+	step := strings.Join(engine.GetExecutionSteps(&WorkflowData{Name: "test"}, "/tmp/gh-aw/agent-stdio.log")[0], "\n")
+	assert.Contains(t, step, "acme --config /tmp/gh-aw/mcp-config/config.toml")
+
+	var yaml strings.Builder
+	engine.RenderMCPConfig(&yaml, map[string]any{}, nil, &WorkflowData{Name: "test"})
+	assert.Contains(t, yaml.String(), "/tmp/gh-aw/mcp-config/config.toml")
+}
+
+func TestEngineRegistryLoadEngineDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.yml"), []byte(acmeEngineDefinition), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tiny.yaml"), []byte("id: tiny\nexecute:\n  command: tiny\n"), 0644))
+
+	registry := GetGlobalEngineRegistry().Clone()
+	loaded, err := registry.LoadEngineDefinitions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+	assert.True(t, registry.IsValidEngine("acme"))
+	assert.True(t, registry.IsValidEngine("tiny"))
+	assert.False(t, GetGlobalEngineRegistry().IsValidEngine("acme"), "the global registry should be unchanged")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme-copy.yml"), []byte(acmeEngineDefinition), 0644))
+	_, err = GetGlobalEngineRegistry().Clone().LoadEngineDefinitions(dir)
+	assert.ErrorContains(t, err, `engine "acme" is defined in both`)
+}
+
+func TestCompileWorkflowWithEngineDefinitions(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	enginesDir := filepath.Join(tmpDir, ".github", "aw", "engines")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.MkdirAll(enginesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(enginesDir, "acme.yml"), []byte(acmeEngineDefinition), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "beta.yml"), []byte("id: beta\nexecute:\n  command: beta-agent {prompt-file}\n"), 0644))
+
+	repoWorkflow := filepath.Join(workflowsDir, "repo-engine.md")
+	require.NoError(t, os.WriteFile(repoWorkflow, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+engine: acme
+---
+
+# Uses the repository engine
+`), 0644))
+
+	importedWorkflow := filepath.Join(workflowsDir, "imported-engine.md")
+	require.NoError(t, os.WriteFile(importedWorkflow, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+imports:
+  - beta.yml
+engine: beta
+---
+
+# Uses an imported engine
+`), 0644))
+
+	otherWorkflow := filepath.Join(workflowsDir, "other.md")
+	require.NoError(t, os.WriteFile(otherWorkflow, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+engine: beta
+---
+
+# Does not import the engine
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	require.NoError(t, compiler.CompileWorkflow(repoWorkflow), "workflow using a repository engine should compile")
+	lockContent, err := os.ReadFile(filepath.Join(workflowsDir, "repo-engine.lock.yml"))
+	require.NoError(t, err)
+	lock := string(lockContent)
+	assert.Contains(t, lock, "npm install -g @acme/cli@1.2.3")
+	assert.Contains(t, lock, "- name: Execute Acme Agent")
+	assert.Contains(t, lock, `engine_id: "acme"`)
+	assert.Contains(t, lock, "parse_claude_log.cjs")
+
+	require.NoError(t, compiler.CompileWorkflow(importedWorkflow), "workflow importing an engine definition should compile")
+	lockContent, err = os.ReadFile(filepath.Join(workflowsDir, "imported-engine.lock.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(lockContent), "beta-agent /tmp/gh-aw/aw-prompts/prompt.txt")
+
+	err = compiler.CompileWorkflow(otherWorkflow)
+	require.Error(t, err, "imported engines should only be available to the importing workflow")
+	assert.Contains(t, err.Error(), "invalid engine: beta")
+
+	typoWorkflow := filepath.Join(workflowsDir, "typo.md")
+	require.NoError(t, os.WriteFile(typoWorkflow, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+engine: acme-next
+---
+
+# Uses an engine that is not defined
+`), 0644))
+	err = compiler.CompileWorkflow(typoWorkflow)
+	require.Error(t, err, "engine ids should not resolve by prefix to a defined engine")
+	assert.Contains(t, err.Error(), "invalid engine: acme-next")
+}
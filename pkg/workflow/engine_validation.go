@@ -10,6 +10,7 @@
 //
 //   - validateEngine() - Validates that a given engine ID is supported
 //   - validateSingleEngineSpecification() - Validates that only one engine field exists across all files
+//   - validateReplayFixture() - Validates the recorded session fixture used by the replay engine
 //
 // # Validation Pattern: Engine Registry
 //
@@ -36,6 +37,8 @@ package workflow
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/github/gh-aw/pkg/constants"
@@ -54,13 +57,20 @@ func (c *Compiler) validateEngine(engineID string) error {
 
 	engineValidationLog.Printf("Validating engine ID: %s", engineID)
 
+	// Engines imported by the current workflow are valid for it
+	if c.workflowEngines != nil && c.workflowEngines.IsValidEngine(engineID) {
+		engineValidationLog.Printf("Engine ID %s is valid (imported engine definition)", engineID)
+		return nil
+	}
+
 	// First try exact match
 	if c.engineRegistry.IsValidEngine(engineID) {
 		engineValidationLog.Printf("Engine ID %s is valid (exact match)", engineID)
 		return nil
 	}
 
-	// Try prefix match for backward compatibility (e.g., "codex-experimental")
+	// Try prefix match for backward compatibility (e.g., "codex-experimental"). Only built-in
+	// engines match by prefix, so defined engines keep exact-match ids.
 	engine, err := c.engineRegistry.GetEngineByPrefix(engineID)
 	if err == nil {
 		engineValidationLog.Printf("Engine ID %s matched by prefix to: %s", engineID, engine.GetID())
@@ -69,8 +79,13 @@ func (c *Compiler) validateEngine(engineID string) error {
 
 	engineValidationLog.Printf("Engine ID %s not found: %v", engineID, err)
 
-	// Get list of valid engine IDs from the engine registry
+	// Get list of valid engine IDs: built-in, defined in the repository, and imported
 	validEngines := c.engineRegistry.GetSupportedEngines()
+	if c.workflowEngines != nil {
+		validEngines = append(validEngines, c.workflowEngines.GetSupportedEngines()...)
+	}
+	slices.Sort(validEngines)
+	validEngines = slices.Compact(validEngines)
 
 	// Try to find close matches for "did you mean" suggestion
 	suggestions := parser.FindClosestMatches(engineID, validEngines, 1)
@@ -79,16 +94,18 @@ func (c *Compiler) validateEngine(engineID string) error {
 	enginesStr := strings.Join(validEngines, ", ")
 
 	// Build error message with helpful context
-	errMsg := fmt.Sprintf("invalid engine: %s. Valid engines are: %s.\n\nExample:\nengine: copilot\n\nSee: %s",
+	errMsg := fmt.Sprintf("invalid engine: %s. Valid engines are: %s. Other engines must be defined in %s or imported.\n\nExample:\nengine: copilot\n\nSee: %s",
 		engineID,
 		enginesStr,
+		constants.GetEngineDefinitionsDir(),
 		constants.DocsEnginesURL)
 
 	// Add "did you mean" suggestion if we found a close match
 	if len(suggestions) > 0 {
-		errMsg = fmt.Sprintf("invalid engine: %s. Valid engines are: %s.\n\nDid you mean: %s?\n\nExample:\nengine: copilot\n\nSee: %s",
+		errMsg = fmt.Sprintf("invalid engine: %s. Valid engines are: %s. Other engines must be defined in %s or imported.\n\nDid you mean: %s?\n\nExample:\nengine: copilot\n\nSee: %s",
 			engineID,
 			enginesStr,
+			constants.GetEngineDefinitionsDir(),
 			suggestions[0],
 			constants.DocsEnginesURL)
 	}
@@ -192,3 +209,49 @@ func (c *Compiler) validatePluginSupport(pluginInfo *PluginInfo, agenticEngine C
 	engineValidationLog.Printf("Engine %s supports plugins: %d plugins to install", agenticEngine.GetID(), len(pluginInfo.Plugins))
 	return nil
 }
+
+// validateReplayFixture validates that the replay engine has a readable, well-formed fixture
+// and that the fixture field is only used with the replay engine
+func (c *Compiler) validateReplayFixture(workflowData *WorkflowData, markdownPath string) error {
+	engineConfig := workflowData.EngineConfig
+	if engineConfig == nil {
+		return nil
+	}
+
+	isReplay := engineConfig.ID == string(constants.ReplayEngine)
+	if !isReplay {
+		if engineConfig.Fixture != "" {
+			return formatCompilerError(markdownPath, "error",
+				fmt.Sprintf("engine.fixture is only supported by the replay engine, but engine is '%s'.\n\nExample:\nengine:\n  id: replay\n  fixture: .github/workflows/fixtures/my-workflow.replay.json\n\nSee: %s", engineConfig.ID, constants.DocsEnginesURL), nil)
+		}
+		return nil
+	}
+
+	fixture := engineConfig.Fixture
+	engineValidationLog.Printf("Validating replay fixture: %s", fixture)
+	if fixture == "" {
+		return formatCompilerError(markdownPath, "error",
+			fmt.Sprintf("the replay engine requires engine.fixture to point at a recorded session fixture.\n\nExample:\nengine:\n  id: replay\n  fixture: .github/workflows/fixtures/my-workflow.replay.json\n\nSee: %s", constants.DocsEnginesURL), nil)
+	}
+
+	fullPath, err := ResolveReplayFixturePath(c.gitRoot, markdownPath, fixture)
+	if err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), nil)
+	}
+
+	if _, err := os.Stat(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			return formatCompilerError(markdownPath, "error",
+				fmt.Sprintf("replay fixture '%s' does not exist. Commit the fixture to the repository so the agent job can read it.", fixture), nil)
+		}
+		return formatCompilerError(markdownPath, "error",
+			fmt.Sprintf("failed to access replay fixture '%s': %v", fixture, err), err)
+	}
+
+	if _, err := LoadReplayFixture(fullPath); err != nil {
+		return formatCompilerError(markdownPath, "error",
+			fmt.Sprintf("invalid replay fixture '%s': %v", fixture, err), err)
+	}
+
+	return nil
+}
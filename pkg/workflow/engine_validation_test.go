@@ -52,6 +52,11 @@ func TestValidateEngine(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid engine",
 		},
+		{
+			name:        "built-in engine matched by prefix",
+			engineID:    "codex-experimental",
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
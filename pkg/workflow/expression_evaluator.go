@@ -0,0 +1,786 @@
+// This file implements static evaluation of GitHub Actions expressions.
+//
+// Expressions are parsed into the ConditionNode tree used by the compiler and evaluated against
+// context values (github, needs, steps, inputs, env, ...) with the semantics of GitHub Actions:
+// loose equality with case-insensitive string comparison, truthiness, object filters (labels.*.name)
+// and the built-in functions. Values that are only known at runtime (step outputs, secrets, vars)
+// evaluate to an *UnknownValue, which propagates through operators so a condition can be reported
+// as true, false, or depending on specific runtime values.
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var expressionEvaluatorLog = logger.New("workflow:expression_evaluator")
+
+// ExpressionContext holds the values available to an expression during static evaluation.
+// Values maps context names ("github", "needs", "steps", "inputs", "env", ...) to JSON-like values
+// (map[string]any, []any, string, float64, bool or nil). Contexts that are missing from Values, or
+// set to an *UnknownValue, evaluate to unknown; missing properties of known contexts evaluate to null.
+type ExpressionContext struct {
+	Values map[string]any
+	// StatusUnknown lists the runtime values that success(), failure() and cancelled() depend on.
+	// When empty, all previous jobs or steps are assumed to have succeeded.
+	StatusUnknown []string
+}
+
+// UnknownValue is the value of an expression that depends on values only known at runtime
+type UnknownValue struct {
+	References []string // The runtime values the expression depends on, e.g. "steps.check.outputs.ok"
+}
+
+// ConditionResult is the outcome of evaluating a condition statically
+type ConditionResult string
+
+const (
+	ConditionTrue    ConditionResult = "true"
+	ConditionFalse   ConditionResult = "false"
+	ConditionUnknown ConditionResult = "unknown"
+)
+
+// ConditionEvaluation is the result of evaluating a condition, with the sub-conditions that decided it
+type ConditionEvaluation struct {
+	Result   ConditionResult
+	Reasons  []string // e.g. "github.event_name == 'issues' is false"
+	Unknowns []string // Runtime values the result depends on (only for ConditionUnknown)
+}
+
+// nullLiteralNode represents the null literal
+type nullLiteralNode struct{}
+
+func (n *nullLiteralNode) Render() string {
+	return "null"
+}
+
+// statusFunctionPattern matches the status check functions that disable the implicit success() check
+var statusFunctionPattern = regexp.MustCompile(`\b(always|success|failure|cancelled)\s*\(`)
+
+// HasStatusFunction reports whether a job or step condition calls a status check function. Conditions
+// without one are implicitly combined with success() by GitHub Actions.
+func HasStatusFunction(condition string) bool {
+	return statusFunctionPattern.MatchString(condition)
+}
+
+// EvaluateCondition evaluates a condition tree and explains the result
+func EvaluateCondition(node ConditionNode, ctx *ExpressionContext) (*ConditionEvaluation, error) {
+	switch n := node.(type) {
+	case *AndNode:
+		return evaluateConjunction([]ConditionNode{n.Left, n.Right}, ctx)
+	case *OrNode:
+		return evaluateDisjunction([]ConditionNode{n.Left, n.Right}, ctx)
+	case *DisjunctionNode:
+		return evaluateDisjunction(n.Terms, ctx)
+	case *ParenthesesNode:
+		return EvaluateCondition(n.Child, ctx)
+	case *NotNode:
+		child, err := EvaluateCondition(n.Child, ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch child.Result {
+		case ConditionTrue:
+			child.Result = ConditionFalse
+		case ConditionFalse:
+			child.Result = ConditionTrue
+		}
+		return child, nil
+	case *ExpressionNode:
+		// Leaves of trees built by the compiler or ParseExpression are unparsed and may contain logical operators
+		parsed, err := ParseExpressionOperands(n.Expression)
+		if err != nil {
+			return nil, err
+		}
+		switch parsed.(type) {
+		case *AndNode, *OrNode, *NotNode, *ParenthesesNode:
+			return EvaluateCondition(parsed, ctx)
+		}
+	}
+
+	value, err := evaluateNode(node, ctx)
+	if err != nil {
+		return nil, err
+	}
+	rendered := node.Render()
+	if unknown, ok := value.(*UnknownValue); ok {
+		return &ConditionEvaluation{
+			Result:   ConditionUnknown,
+			Reasons:  []string{fmt.Sprintf("%s depends on %s", rendered, strings.Join(unknown.References, ", "))},
+			Unknowns: unknown.References,
+		}, nil
+	}
+	if isTruthy(value) {
+		return &ConditionEvaluation{Result: ConditionTrue, Reasons: []string{rendered + " is true"}}, nil
+	}
+	return &ConditionEvaluation{Result: ConditionFalse, Reasons: []string{rendered + " is false"}}, nil
+}
+
+// evaluateConjunction is false when any term is false, unknown when any term is unknown, and true otherwise
+func evaluateConjunction(terms []ConditionNode, ctx *ExpressionContext) (*ConditionEvaluation, error) {
+	result := &ConditionEvaluation{Result: ConditionTrue}
+	var unknown *ConditionEvaluation
+	for _, term := range terms {
+		evaluation, err := EvaluateCondition(term, ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch evaluation.Result {
+		case ConditionFalse:
+			return evaluation, nil
+		case ConditionUnknown:
+			if unknown == nil {
+				unknown = &ConditionEvaluation{Result: ConditionUnknown}
+			}
+			unknown.Reasons = appendUnique(unknown.Reasons, evaluation.Reasons...)
+			unknown.Unknowns = appendUnique(unknown.Unknowns, evaluation.Unknowns...)
+		default:
+			result.Reasons = appendUnique(result.Reasons, evaluation.Reasons...)
+		}
+	}
+	if unknown != nil {
+		return unknown, nil
+	}
+	return result, nil
+}
+
+// evaluateDisjunction is true when any term is true, unknown when any term is unknown, and false otherwise
+func evaluateDisjunction(terms []ConditionNode, ctx *ExpressionContext) (*ConditionEvaluation, error) {
+	result := &ConditionEvaluation{Result: ConditionFalse}
+	var unknown *ConditionEvaluation
+	for _, term := range terms {
+		evaluation, err := EvaluateCondition(term, ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch evaluation.Result {
+		case ConditionTrue:
+			return evaluation, nil
+		case ConditionUnknown:
+			if unknown == nil {
+				unknown = &ConditionEvaluation{Result: ConditionUnknown}
+			}
+			unknown.Reasons = appendUnique(unknown.Reasons, evaluation.Reasons...)
+			unknown.Unknowns = appendUnique(unknown.Unknowns, evaluation.Unknowns...)
+		default:
+			result.Reasons = appendUnique(result.Reasons, evaluation.Reasons...)
+		}
+	}
+	if unknown != nil {
+		return unknown, nil
+	}
+	return result, nil
+}
+
+// EvaluateConditionString parses and evaluates a condition such as a job's if: field
+func EvaluateConditionString(condition string, ctx *ExpressionContext) (*ConditionEvaluation, error) {
+	expressionEvaluatorLog.Printf("Evaluating condition: %s", condition)
+	node, err := ParseExpressionOperands(stripExpressionWrapper(condition))
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateCondition(node, ctx)
+}
+
+// EvaluateExpression evaluates an expression (without the ${{ }} wrapper) to a value
+func EvaluateExpression(expression string, ctx *ExpressionContext) (any, error) {
+	node, err := ParseExpressionOperands(expression)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateNode(node, ctx)
+}
+
+// evaluateNode evaluates a node to a value
+func evaluateNode(node ConditionNode, ctx *ExpressionContext) (any, error) {
+	switch n := node.(type) {
+	case *ExpressionNode:
+		parsed, err := ParseExpressionOperands(n.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return evaluateNode(parsed, ctx)
+	case *ParenthesesNode:
+		return evaluateNode(n.Child, ctx)
+	case *StringLiteralNode:
+		return n.Value, nil
+	case *BooleanLiteralNode:
+		return n.Value, nil
+	case *NumberLiteralNode:
+		return parseNumber(n.Value), nil
+	case *nullLiteralNode:
+		return nil, nil
+	case *PropertyAccessNode:
+		return resolvePropertyPath(n.PropertyPath, ctx)
+	case *NotNode:
+		value, err := evaluateNode(n.Child, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if unknown, ok := value.(*UnknownValue); ok {
+			return unknown, nil
+		}
+		return !isTruthy(value), nil
+	case *AndNode:
+		return evaluateLogical(n.Left, n.Right, ctx, false)
+	case *OrNode:
+		return evaluateLogical(n.Left, n.Right, ctx, true)
+	case *DisjunctionNode:
+		if len(n.Terms) == 0 {
+			return nil, nil
+		}
+		value, err := evaluateNode(n.Terms[0], ctx)
+		for _, term := range n.Terms[1:] {
+			if err != nil {
+				return nil, err
+			}
+			value, err = combineLogical(value, term, ctx, true)
+		}
+		return value, err
+	case *ComparisonNode:
+		left, err := evaluateNode(n.Left, ctx)
+		if err != nil {
+			return nil, err
+		}
+		right, err := evaluateNode(n.Right, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if unknown := mergeUnknowns(left, right); unknown != nil {
+			return unknown, nil
+		}
+		return compareExpressionValues(left, n.Operator, right)
+	case *TernaryNode:
+		condition, err := evaluateNode(n.Condition, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if unknown, ok := condition.(*UnknownValue); ok {
+			return unknown, nil
+		}
+		if isTruthy(condition) {
+			return evaluateNode(n.TrueValue, ctx)
+		}
+		return evaluateNode(n.FalseValue, ctx)
+	case *ContainsNode:
+		return callFunction("contains", []ConditionNode{n.Array, n.Value}, ctx)
+	case *FunctionCallNode:
+		return callFunction(n.FunctionName, n.Arguments, ctx)
+	default:
+		return nil, fmt.Errorf("cannot evaluate expression node %T", node)
+	}
+}
+
+// evaluateLogical evaluates && (or: false) and || (or: true), which return one of their operands
+func evaluateLogical(left, right ConditionNode, ctx *ExpressionContext, or bool) (any, error) {
+	value, err := evaluateNode(left, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return combineLogical(value, right, ctx, or)
+}
+
+// combineLogical combines an evaluated left operand with the right operand of && or ||. When the left
+// operand is unknown, so is the result: either operand may be returned at runtime.
+func combineLogical(left any, right ConditionNode, ctx *ExpressionContext, or bool) (any, error) {
+	if unknown, ok := left.(*UnknownValue); ok {
+		value, err := evaluateNode(right, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return mergeUnknowns(unknown, value), nil
+	}
+	if isTruthy(left) == or {
+		return left, nil
+	}
+	return evaluateNode(right, ctx)
+}
+
+// mergeUnknowns returns an *UnknownValue with the references of all unknown values, or nil
+func mergeUnknowns(values ...any) *UnknownValue {
+	var merged *UnknownValue
+	for _, value := range values {
+		if unknown, ok := value.(*UnknownValue); ok {
+			if merged == nil {
+				merged = &UnknownValue{}
+			}
+			merged.References = appendUnique(merged.References, unknown.References...)
+		}
+	}
+	return merged
+}
+
+// callFunction evaluates a call to a built-in expression function
+func callFunction(name string, arguments []ConditionNode, ctx *ExpressionContext) (any, error) {
+	lowerName := strings.ToLower(name)
+	switch lowerName {
+	case "always":
+		return true, nil
+	case "success", "failure", "cancelled":
+		if len(ctx.StatusUnknown) > 0 {
+			return &UnknownValue{References: ctx.StatusUnknown}, nil
+		}
+		return lowerName == "success", nil
+	case "hashfiles":
+		return &UnknownValue{References: []string{"hashFiles(...)"}}, nil
+	}
+
+	args := make([]any, 0, len(arguments))
+	for _, argument := range arguments {
+		value, err := evaluateNode(argument, ctx)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	if unknown := mergeUnknowns(args...); unknown != nil {
+		return unknown, nil
+	}
+
+	checkArgs := func(minArgs, maxArgs int) error {
+		if len(args) < minArgs || len(args) > maxArgs {
+			return fmt.Errorf("function %s() called with %d arguments", name, len(args))
+		}
+		return nil
+	}
+
+	switch lowerName {
+	case "contains":
+		if err := checkArgs(2, 2); err != nil {
+			return nil, err
+		}
+		if array, ok := args[0].([]any); ok {
+			for _, element := range array {
+				if looseEqual(element, args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return strings.Contains(strings.ToLower(toExpressionString(args[0])), strings.ToLower(toExpressionString(args[1]))), nil
+	case "startswith":
+		if err := checkArgs(2, 2); err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(strings.ToLower(toExpressionString(args[0])), strings.ToLower(toExpressionString(args[1]))), nil
+	case "endswith":
+		if err := checkArgs(2, 2); err != nil {
+			return nil, err
+		}
+		return strings.HasSuffix(strings.ToLower(toExpressionString(args[0])), strings.ToLower(toExpressionString(args[1]))), nil
+	case "format":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("function %s() called with 0 arguments", name)
+		}
+		return formatExpressionString(toExpressionString(args[0]), args[1:]), nil
+	case "join":
+		if err := checkArgs(1, 2); err != nil {
+			return nil, err
+		}
+		separator := ","
+		if len(args) == 2 {
+			separator = toExpressionString(args[1])
+		}
+		array, ok := args[0].([]any)
+		if !ok {
+			return toExpressionString(args[0]), nil
+		}
+		parts := make([]string, 0, len(array))
+		for _, element := range array {
+			parts = append(parts, toExpressionString(element))
+		}
+		return strings.Join(parts, separator), nil
+	case "tojson":
+		if err := checkArgs(1, 1); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(args[0], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case "fromjson":
+		if err := checkArgs(1, 1); err != nil {
+			return nil, err
+		}
+		var value any
+		if err := json.Unmarshal([]byte(toExpressionString(args[0])), &value); err != nil {
+			return nil, fmt.Errorf("fromJSON(): %w", err)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+}
+
+// formatExpressionString implements format(): {N} is replaced by argument N, {{ and }} are escapes
+func formatExpressionString(format string, args []any) string {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		switch {
+		case strings.HasPrefix(format[i:], "{{"):
+			sb.WriteByte('{')
+			i++
+		case strings.HasPrefix(format[i:], "}}"):
+			sb.WriteByte('}')
+			i++
+		case format[i] == '{':
+			end := strings.IndexByte(format[i:], '}')
+			index, err := strconv.Atoi(format[i+1 : i+max(end, 1)])
+			if end == -1 || err != nil || index < 0 || index >= len(args) {
+				sb.WriteByte(format[i])
+				continue
+			}
+			sb.WriteString(toExpressionString(args[index]))
+			i += end
+		default:
+			sb.WriteByte(format[i])
+		}
+	}
+	return sb.String()
+}
+
+// resolvePropertyPath resolves a property path such as github.event.issue.labels.*.name or
+// needs['pre_activation'].outputs.activated against the context values
+func resolvePropertyPath(path string, ctx *ExpressionContext) (any, error) {
+	segments, err := splitPropertyPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := lookupProperty(ctx.Values, segments[0])
+	if !ok {
+		return &UnknownValue{References: []string{path}}, nil
+	}
+	value := root
+	filtered := false
+	for _, segment := range segments[1:] {
+		if _, isUnknown := value.(*UnknownValue); isUnknown {
+			return &UnknownValue{References: []string{path}}, nil
+		}
+		if segment == "*" {
+			value = filterValues(value, filtered)
+			filtered = true
+			continue
+		}
+		if filtered {
+			array, _ := value.([]any)
+			var next []any
+			for _, element := range array {
+				if property, found := lookupProperty(element, segment); found && property != nil {
+					next = append(next, property)
+				}
+			}
+			value = next
+			continue
+		}
+		value, _ = lookupProperty(value, segment)
+	}
+	if _, isUnknown := value.(*UnknownValue); isUnknown {
+		return &UnknownValue{References: []string{path}}, nil
+	}
+	if filtered && value == nil {
+		return []any{}, nil
+	}
+	return value, nil
+}
+
+// filterValues implements the * object filter: the elements of an array or the values of an object
+func filterValues(value any, filtered bool) []any {
+	var result []any
+	add := func(element any) {
+		if filtered {
+			// A filter applied to the result of a filter flattens one level
+			if nested, ok := element.([]any); ok {
+				result = append(result, nested...)
+				return
+			}
+			if object, ok := element.(map[string]any); ok {
+				for _, key := range sortedMapKeys(object) {
+					result = append(result, object[key])
+				}
+				return
+			}
+			return
+		}
+		result = append(result, element)
+	}
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			add(element)
+		}
+	case map[string]any:
+		if filtered {
+			return nil
+		}
+		for _, key := range sortedMapKeys(v) {
+			add(v[key])
+		}
+	}
+	if result == nil {
+		result = []any{}
+	}
+	return result
+}
+
+// lookupProperty returns a property of an object (case-insensitive) or an element of an array
+func lookupProperty(value any, key string) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if property, ok := v[key]; ok {
+			return property, true
+		}
+		for name, property := range v {
+			if strings.EqualFold(name, key) {
+				return property, true
+			}
+		}
+	case []any:
+		if index, err := strconv.Atoi(key); err == nil && index >= 0 && index < len(v) {
+			return v[index], true
+		}
+	}
+	return nil, false
+}
+
+// splitPropertyPath splits a property path into segments: identifiers, "*", and index values
+func splitPropertyPath(path string) ([]string, error) {
+	var segments []string
+	i := 0
+	for i < len(path) {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated index in '%s'", path)
+			}
+			index := strings.TrimSpace(path[i+1 : i+end])
+			if len(index) >= 2 && index[0] == '\'' && index[len(index)-1] == '\'' {
+				index = strings.ReplaceAll(index[1:len(index)-1], "''", "'")
+			} else if _, err := strconv.Atoi(index); err != nil && index != "*" {
+				return nil, fmt.Errorf("unsupported index '%s' in '%s': only literal indexes can be evaluated statically", index, path)
+			}
+			segments = append(segments, index)
+			i += end + 1
+		default:
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			segments = append(segments, path[start:i])
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty property path")
+	}
+	return segments, nil
+}
+
+// isTruthy converts a value to a boolean: false, 0, NaN, "" and null are falsy
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	default:
+		if number, ok := numericValue(value); ok {
+			return number != 0
+		}
+		return true
+	}
+}
+
+// numericValue converts Go numeric types (as produced by YAML or JSON decoders) to float64
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// toNumber coerces a value to a number as GitHub Actions does for comparisons of different types
+func toNumber(value any) float64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		return parseNumber(v)
+	default:
+		if number, ok := numericValue(value); ok {
+			return number
+		}
+		return math.NaN()
+	}
+}
+
+// parseNumber parses a number literal or numeric string; the empty string is 0 and invalid input is NaN
+func parseNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		if number, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+			return float64(number)
+		}
+		return math.NaN()
+	}
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return number
+}
+
+// toExpressionString converts a value to a string as GitHub Actions does in string functions
+func toExpressionString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		return "Array"
+	case map[string]any:
+		return "Object"
+	default:
+		if number, ok := numericValue(value); ok {
+			return strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// isPrimitive reports whether a value is null, a boolean, a number or a string
+func isPrimitive(value any) bool {
+	switch value.(type) {
+	case nil, bool, string:
+		return true
+	}
+	_, ok := numericValue(value)
+	return ok
+}
+
+// looseEqual implements == : strings compare case-insensitively, values of different types are
+// compared as numbers, and objects and arrays are never equal to anything else
+func looseEqual(left, right any) bool {
+	if !isPrimitive(left) || !isPrimitive(right) {
+		return false
+	}
+	if left == nil && right == nil {
+		return true
+	}
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	if leftIsString && rightIsString {
+		return strings.EqualFold(leftString, rightString)
+	}
+	leftBool, leftIsBool := left.(bool)
+	rightBool, rightIsBool := right.(bool)
+	if leftIsBool && rightIsBool {
+		return leftBool == rightBool
+	}
+	return toNumber(left) == toNumber(right)
+}
+
+// compareExpressionValues evaluates a comparison operator
+func compareExpressionValues(left any, operator string, right any) (any, error) {
+	switch operator {
+	case "==":
+		return looseEqual(left, right), nil
+	case "!=":
+		return !looseEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		var cmp int
+		leftString, leftIsString := left.(string)
+		rightString, rightIsString := right.(string)
+		if leftIsString && rightIsString {
+			cmp = strings.Compare(strings.ToLower(leftString), strings.ToLower(rightString))
+		} else {
+			leftNumber, rightNumber := toNumber(left), toNumber(right)
+			if math.IsNaN(leftNumber) || math.IsNaN(rightNumber) {
+				return false, nil
+			}
+			switch {
+			case leftNumber < rightNumber:
+				cmp = -1
+			case leftNumber > rightNumber:
+				cmp = 1
+			}
+		}
+		switch operator {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported comparison operator '%s'", operator)
+	}
+}
+
+// appendUnique appends the values that are not yet in the slice
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range slice {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
+
+// sortedMapKeys returns the keys of a map in ascending order
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,202 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExpressionContext() *ExpressionContext {
+	return &ExpressionContext{Values: map[string]any{
+		"github": map[string]any{
+			"event_name": "issue_comment",
+			"actor":      "octocat",
+			"event": map[string]any{
+				"action":  "created",
+				"comment": map[string]any{"body": "/scout find docs"},
+				"issue": map[string]any{
+					"number": float64(42),
+					"labels": []any{
+						map[string]any{"name": "bug"},
+						map[string]any{"name": "Needs-Triage"},
+					},
+				},
+			},
+		},
+		"needs": map[string]any{
+			"pre_activation": map[string]any{
+				"result":  "success",
+				"outputs": map[string]any{"activated": &UnknownValue{References: []string{"needs.pre_activation.outputs.activated"}}},
+			},
+		},
+		"steps": &UnknownValue{},
+	}}
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   any
+	}{
+		{name: "property", expression: "github.event_name", expected: "issue_comment"},
+		{name: "case-insensitive equality", expression: "github.event_name == 'ISSUE_COMMENT'", expected: true},
+		{name: "inequality", expression: "github.event.action != 'created'", expected: false},
+		{name: "missing property is null", expression: "github.event.issue.pull_request == null", expected: true},
+		{name: "number coercion", expression: "github.event.issue.number == '42'", expected: true},
+		{name: "comparison", expression: "github.event.issue.number > 10", expected: true},
+		{name: "object filter", expression: "contains(github.event.issue.labels.*.name, 'needs-triage')", expected: true},
+		{name: "object filter no match", expression: "contains(github.event.issue.labels.*.name, 'docs')", expected: false},
+		{name: "startsWith", expression: "startsWith(github.event.comment.body, '/scout ')", expected: true},
+		{name: "endsWith", expression: "endsWith(github.event.comment.body, 'DOCS')", expected: true},
+		{name: "string contains", expression: "contains(github.event.comment.body, 'find')", expected: true},
+		{name: "or returns operand", expression: "github.event.missing || 'default'", expected: "default"},
+		{name: "and returns operand", expression: "github.actor && github.event_name", expected: "issue_comment"},
+		{name: "not", expression: "!github.event.issue.pull_request", expected: true},
+		{name: "format", expression: "format('{0}/{1} {{x}}', github.actor, 'repo')", expected: "octocat/repo {x}"},
+		{name: "join", expression: "join(github.event.issue.labels.*.name, ', ')", expected: "bug, Needs-Triage"},
+		{name: "fromJSON", expression: "fromJSON('[1, 2]')", expected: []any{float64(1), float64(2)}},
+		{name: "index", expression: "github['event_name']", expected: "issue_comment"},
+		{name: "escaped quote", expression: "'it''s'", expected: "it's"},
+		{name: "always", expression: "always()", expected: true},
+		{name: "success", expression: "success()", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := EvaluateExpression(tt.expression, testExpressionContext())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestEvaluateExpressionUnknown(t *testing.T) {
+	ctx := testExpressionContext()
+
+	value, err := EvaluateExpression("steps.check.outputs.ok == 'true'", ctx)
+	require.NoError(t, err)
+	require.IsType(t, &UnknownValue{}, value)
+	assert.Equal(t, []string{"steps.check.outputs.ok"}, value.(*UnknownValue).References)
+
+	// Either operand may be returned at runtime, so the value stays unknown
+	value, err = EvaluateExpression("steps.check.outputs.ok || 'true'", ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &UnknownValue{}, value)
+
+	value, err = EvaluateExpression("vars.ENABLED == 'true'", ctx)
+	require.NoError(t, err)
+	assert.IsType(t, &UnknownValue{}, value)
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []string{
+		"github.event_name ==",
+		"unknownFunction(1)",
+		"'unterminated",
+		"contains(github.event_name)",
+		"github.event_name = 'x'",
+		"github.event[github.actor]",
+	}
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := EvaluateExpression(expression, testExpressionContext())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEvaluateConditionString(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		result    ConditionResult
+		reasons   []string
+		unknowns  []string
+	}{
+		{
+			name:      "true conjunction lists all terms",
+			condition: "github.event_name == 'issue_comment' && startsWith(github.event.comment.body, '/scout')",
+			result:    ConditionTrue,
+			reasons:   []string{"github.event_name == 'issue_comment' is true", "startsWith(github.event.comment.body, '/scout') is true"},
+		},
+		{
+			name:      "false conjunction names the false term",
+			condition: "${{ github.event_name == 'issue_comment' && github.event.issue.pull_request != null }}",
+			result:    ConditionFalse,
+			reasons:   []string{"github.event.issue.pull_request != null is false"},
+		},
+		{
+			name:      "true disjunction names the true term",
+			condition: "github.event_name == 'issues' || github.event_name == 'issue_comment'",
+			result:    ConditionTrue,
+			reasons:   []string{"github.event_name == 'issue_comment' is true"},
+		},
+		{
+			name:      "negation keeps the reasons",
+			condition: "!(github.event_name == 'issue_comment')",
+			result:    ConditionFalse,
+			reasons:   []string{"github.event_name == 'issue_comment' is true"},
+		},
+		{
+			name:      "runtime output",
+			condition: "needs.pre_activation.outputs.activated == 'true'",
+			result:    ConditionUnknown,
+			reasons:   []string{"needs.pre_activation.outputs.activated == 'true' depends on needs.pre_activation.outputs.activated"},
+			unknowns:  []string{"needs.pre_activation.outputs.activated"},
+		},
+		{
+			name:      "false term decides over runtime output",
+			condition: "needs.pre_activation.outputs.activated == 'true' && github.event_name == 'push'",
+			result:    ConditionFalse,
+			reasons:   []string{"github.event_name == 'push' is false"},
+		},
+		{
+			name:      "truthy default decides a condition",
+			condition: "steps.check.outputs.ok || 'true'",
+			result:    ConditionTrue,
+			reasons:   []string{"'true' is true"},
+		},
+		{
+			name:      "status function",
+			condition: "always() && needs.pre_activation.result != 'skipped'",
+			result:    ConditionTrue,
+			reasons:   []string{"always() is true", "needs.pre_activation.result != 'skipped' is true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation, err := EvaluateConditionString(tt.condition, testExpressionContext())
+			require.NoError(t, err)
+			assert.Equal(t, tt.result, evaluation.Result)
+			assert.Equal(t, tt.reasons, evaluation.Reasons)
+			assert.Equal(t, tt.unknowns, evaluation.Unknowns)
+		})
+	}
+}
+
+func TestEvaluateConditionOverBuiltNodes(t *testing.T) {
+	// Conditions built by the compiler evaluate without rendering and re-parsing
+	condition := &DisjunctionNode{Terms: []ConditionNode{
+		BuildEventTypeEquals("issues"),
+		&AndNode{
+			Left:  BuildEventTypeEquals("issue_comment"),
+			Right: &NotNode{Child: &FunctionCallNode{FunctionName: "cancelled"}},
+		},
+	}}
+
+	evaluation, err := EvaluateCondition(condition, testExpressionContext())
+	require.NoError(t, err)
+	assert.Equal(t, ConditionTrue, evaluation.Result)
+}
+
+func TestHasStatusFunction(t *testing.T) {
+	assert.True(t, HasStatusFunction("always() && needs.agent.result != 'skipped'"))
+	assert.True(t, HasStatusFunction("!cancelled()"))
+	assert.False(t, HasStatusFunction("needs.pre_activation.outputs.activated == 'true'"))
+	assert.False(t, HasStatusFunction("contains(github.event.comment.body, 'success')"))
+}
@@ -27,6 +27,9 @@ func stripExpressionWrapper(expression string) string {
 type ExpressionParser struct {
 	tokens []token
 	pos    int
+	// operands enables parsing of the operands themselves (literals, property paths, indexing,
+	// function calls and comparisons) instead of keeping them as ExpressionNode leaves
+	operands bool
 }
 
 type token struct {
@@ -45,6 +48,12 @@ const (
 	tokenLeftParen
 	tokenRightParen
 	tokenEOF
+	// Operand tokens, only produced when parsing operands
+	tokenString
+	tokenNumber
+	tokenIdentifier // identifiers and property paths, including * filters and [index] accessors
+	tokenComparison
+	tokenComma
 )
 
 // ParseExpression parses a string expression into a ConditionNode tree
@@ -81,6 +90,34 @@ func ParseExpression(expression string) (ConditionNode, error) {
 	return result, nil
 }
 
+// ParseExpressionOperands parses a string expression into a ConditionNode tree down to its operands
+// Supports the logical operators of ParseExpression as well as comparisons (==, !=, <, <=, >, >=),
+// string, number, boolean and null literals, property paths and function calls
+// Example: "github.event_name == 'issues' && contains(github.event.issue.labels.*.name, 'bug')"
+func ParseExpressionOperands(expression string) (ConditionNode, error) {
+	expressionsLog.Printf("Parsing expression operands: %s", expression)
+
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	parser := &ExpressionParser{operands: true}
+	tokens, err := parser.tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser.tokens = tokens
+
+	result, err := parser.parseOrExpression()
+	if err != nil {
+		return nil, err
+	}
+	if parser.current().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected token '%s' at position %d", parser.current().value, parser.current().pos)
+	}
+	return result, nil
+}
+
 // tokenize breaks the expression string into tokens
 func (p *ExpressionParser) tokenize(expression string) ([]token, error) {
 	expressionsLog.Printf("Tokenizing expression of length %d", len(expression))
@@ -111,6 +148,13 @@ func (p *ExpressionParser) tokenize(expression string) ([]token, error) {
 		case expression[i] == ')':
 			tokens = append(tokens, token{tokenRightParen, ")", i})
 			i++
+		case p.operands:
+			operand, next, err := tokenizeOperand(expression, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, operand)
+			i = next
 		default:
 			// Parse literal expression - everything until we hit a logical operator or paren
 			start := i
@@ -185,6 +229,66 @@ func (p *ExpressionParser) tokenize(expression string) ([]token, error) {
 	return tokens, nil
 }
 
+// tokenizeOperand reads the operand token starting at position i and returns it with the position after it.
+// Strings are single-quoted, with ” as an escaped quote.
+func tokenizeOperand(expression string, i int) (token, int, error) {
+	ch := expression[i]
+	switch {
+	case ch == ',':
+		return token{tokenComma, ",", i}, i + 1, nil
+	case ch == '\'':
+		var sb strings.Builder
+		for j := i + 1; j < len(expression); j++ {
+			if expression[j] != '\'' {
+				sb.WriteByte(expression[j])
+				continue
+			}
+			if j+1 < len(expression) && expression[j+1] == '\'' {
+				sb.WriteByte('\'')
+				j++
+				continue
+			}
+			return token{tokenString, sb.String(), i}, j + 1, nil
+		}
+		return token{}, 0, fmt.Errorf("unterminated string at position %d", i)
+	case strings.ContainsRune("=!<>&|", rune(ch)):
+		operator := string(ch)
+		if i+1 < len(expression) && expression[i+1] == '=' {
+			operator += "="
+		}
+		if operator == "=" || operator == "&" || operator == "|" {
+			return token{}, 0, fmt.Errorf("unexpected '%s' at position %d", operator, i)
+		}
+		return token{tokenComparison, operator, i}, i + len(operator), nil
+	case ch == '-' || ch == '.' || (ch >= '0' && ch <= '9'):
+		j := i + 1
+		for j < len(expression) && (isIdentifierByte(expression[j]) || expression[j] == '.' ||
+			((expression[j] == '+' || expression[j] == '-') && (expression[j-1] == 'e' || expression[j-1] == 'E'))) {
+			j++
+		}
+		return token{tokenNumber, expression[i:j], i}, j, nil
+	case isIdentifierByte(ch):
+		j := i
+		for j < len(expression) && (isIdentifierByte(expression[j]) || expression[j] == '.' || expression[j] == '*' || expression[j] == '[') {
+			if expression[j] == '[' {
+				end := strings.IndexByte(expression[j:], ']')
+				if end == -1 {
+					return token{}, 0, fmt.Errorf("unterminated index at position %d", j)
+				}
+				j += end
+			}
+			j++
+		}
+		return token{tokenIdentifier, expression[i:j], i}, j, nil
+	default:
+		return token{}, 0, fmt.Errorf("unexpected character '%c' at position %d", ch, i)
+	}
+}
+
+func isIdentifierByte(ch byte) bool {
+	return ch == '_' || ch == '-' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
 // parseOrExpression parses OR expressions (lowest precedence)
 func (p *ExpressionParser) parseOrExpression() (ConditionNode, error) {
 	left, err := p.parseAndExpression()
@@ -206,14 +310,14 @@ func (p *ExpressionParser) parseOrExpression() (ConditionNode, error) {
 
 // parseAndExpression parses AND expressions (higher precedence than OR)
 func (p *ExpressionParser) parseAndExpression() (ConditionNode, error) {
-	left, err := p.parseUnaryExpression()
+	left, err := p.parseComparisonExpression()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.current().kind == tokenAnd {
 		p.advance() // consume &&
-		right, err := p.parseUnaryExpression()
+		right, err := p.parseComparisonExpression()
 		if err != nil {
 			return nil, err
 		}
@@ -223,6 +327,26 @@ func (p *ExpressionParser) parseAndExpression() (ConditionNode, error) {
 	return left, nil
 }
 
+// parseComparisonExpression parses comparisons (higher precedence than AND)
+func (p *ExpressionParser) parseComparisonExpression() (ConditionNode, error) {
+	left, err := p.parseUnaryExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.current().kind == tokenComparison {
+		operator := p.current().value
+		p.advance() // consume the operator
+		right, err := p.parseUnaryExpression()
+		if err != nil {
+			return nil, err
+		}
+		left = &ComparisonNode{Left: left, Operator: operator, Right: right}
+	}
+
+	return left, nil
+}
+
 // parseUnaryExpression parses NOT expressions and primary expressions
 func (p *ExpressionParser) parseUnaryExpression() (ConditionNode, error) {
 	if p.current().kind == tokenNot {
@@ -237,7 +361,7 @@ func (p *ExpressionParser) parseUnaryExpression() (ConditionNode, error) {
 	return p.parsePrimaryExpression()
 }
 
-// parsePrimaryExpression parses literals and parenthesized expressions
+// parsePrimaryExpression parses literals, operands and parenthesized expressions
 func (p *ExpressionParser) parsePrimaryExpression() (ConditionNode, error) {
 	if expressionsLog.Enabled() {
 		expressionsLog.Printf("Parsing primary expression at token: %s", p.current().value)
@@ -253,6 +377,9 @@ func (p *ExpressionParser) parsePrimaryExpression() (ConditionNode, error) {
 			return nil, fmt.Errorf("expected ')' at position %d", p.current().pos)
 		}
 		p.advance() // consume )
+		if p.operands {
+			return &ParenthesesNode{Child: expr}, nil
+		}
 		return expr, nil
 
 	case tokenLiteral:
@@ -260,11 +387,56 @@ func (p *ExpressionParser) parsePrimaryExpression() (ConditionNode, error) {
 		p.advance()
 		return &ExpressionNode{Expression: literal}, nil
 
+	case tokenString:
+		value := p.current().value
+		p.advance()
+		return &StringLiteralNode{Value: value}, nil
+
+	case tokenNumber:
+		value := p.current().value
+		p.advance()
+		return &NumberLiteralNode{Value: value}, nil
+
+	case tokenIdentifier:
+		name := p.current().value
+		p.advance()
+		switch strings.ToLower(name) {
+		case "true", "false":
+			return &BooleanLiteralNode{Value: strings.EqualFold(name, "true")}, nil
+		case "null":
+			return &nullLiteralNode{}, nil
+		}
+		if p.current().kind == tokenLeftParen {
+			return p.parseFunctionCall(name)
+		}
+		return &PropertyAccessNode{PropertyPath: name}, nil
+
 	default:
 		return nil, fmt.Errorf("unexpected token '%s' at position %d", p.current().value, p.current().pos)
 	}
 }
 
+// parseFunctionCall parses the comma-separated arguments of a call to the named function
+func (p *ExpressionParser) parseFunctionCall(name string) (ConditionNode, error) {
+	p.advance() // consume (
+	call := &FunctionCallNode{FunctionName: name}
+	for p.current().kind != tokenRightParen {
+		if len(call.Arguments) > 0 {
+			if p.current().kind != tokenComma {
+				return nil, fmt.Errorf("expected ',' or ')' in call to %s() at position %d", name, p.current().pos)
+			}
+			p.advance() // consume ,
+		}
+		argument, err := p.parseOrExpression()
+		if err != nil {
+			return nil, err
+		}
+		call.Arguments = append(call.Arguments, argument)
+	}
+	p.advance() // consume )
+	return call, nil
+}
+
 // current returns the current token
 func (p *ExpressionParser) current() token {
 	if p.pos >= len(p.tokens) {
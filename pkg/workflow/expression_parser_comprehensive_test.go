@@ -472,6 +472,52 @@ func TestExpressionParserEdgeCases(t *testing.T) {
 	}
 }
 
+// TestParseExpressionOperands tests parsing of the operands that ParseExpression keeps as literals
+func TestParseExpressionOperands(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "comparison", input: "github.event_name == 'issues'", expected: "github.event_name == 'issues'"},
+		{name: "comparison binds tighter than and", input: "a == 'x' && b != 1", expected: "(a == 'x') && (b != 1)"},
+		{name: "not binds tighter than comparison", input: "!a == false", expected: "!(a) == false"},
+		{name: "function call with logical argument", input: "contains(a || 'x', 'y')", expected: "contains((a) || ('x'), 'y')"},
+		{name: "nested calls", input: "startsWith(format('{0}', github.actor), 'bot')", expected: "startsWith(format('{0}', github.actor), 'bot')"},
+		{name: "object filter and index", input: "github.event.issue.labels.*.name[0] >= needs['job-a'].outputs.n", expected: "github.event.issue.labels.*.name[0] >= needs['job-a'].outputs.n"},
+		{name: "parentheses are kept", input: "!(a && b)", expected: "!(((a) && (b)))"},
+		{name: "escaped quote", input: "a == 'it''s'", expected: "a == 'it's'"},
+		{name: "numbers", input: "a < -1.5e3", expected: "a < -1.5e3"},
+		{name: "keywords", input: "TRUE && null", expected: "(true) && (null)"},
+		{name: "single equals", input: "a = 'b'", wantErr: true},
+		{name: "unterminated string", input: "a == 'b", wantErr: true},
+		{name: "unterminated index", input: "needs['a", wantErr: true},
+		{name: "missing comma", input: "contains(a 'b')", wantErr: true},
+		{name: "missing operand", input: "a ==", wantErr: true},
+		{name: "unclosed call", input: "contains(a, 'b'", wantErr: true},
+		{name: "double quotes", input: "a == \"b\"", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseExpressionOperands(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseExpressionOperands(%q) expected error, got %q", tt.input, result.Render())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpressionOperands(%q) unexpected error: %v", tt.input, err)
+			}
+			if rendered := result.Render(); rendered != tt.expected {
+				t.Errorf("ParseExpressionOperands() = %q, want %q", rendered, tt.expected)
+			}
+		})
+	}
+}
+
 // TestExpressionSafetyComprehensive tests comprehensive expression safety validation
 func TestExpressionSafetyComprehensive(t *testing.T) {
 	tests := []struct {
@@ -79,6 +79,63 @@ var (
 func validateExpressionSafety(markdownContent string) error {
 	expressionValidationLog.Print("Validating expression safety in markdown content")
 
+	unauthorizedExpressions, err := collectUnauthorizedExpressions(markdownContent)
+	if err != nil {
+		return err
+	}
+
+	// If we found unauthorized expressions, return an error
+	if len(unauthorizedExpressions) > 0 {
+		expressionValidationLog.Printf("Expression safety validation failed: %d unauthorized expressions found", len(unauthorizedExpressions))
+		// Format unauthorized expressions list with fuzzy match suggestions
+		var unauthorizedList strings.Builder
+		unauthorizedList.WriteString("\n")
+		for _, expr := range unauthorizedExpressions {
+			unauthorizedList.WriteString("  - ")
+			unauthorizedList.WriteString(expr)
+
+			// Find closest matches using fuzzy string matching
+			closestMatches := parser.FindClosestMatches(expr, constants.AllowedExpressions, maxFuzzyMatchSuggestions)
+			if len(closestMatches) > 0 {
+				unauthorizedList.WriteString(" (did you mean: ")
+				unauthorizedList.WriteString(strings.Join(closestMatches, ", "))
+				unauthorizedList.WriteString("?)")
+			}
+
+			unauthorizedList.WriteString("\n")
+		}
+
+		// Format allowed expressions list
+		var allowedList strings.Builder
+		allowedList.WriteString("\n")
+		for _, expr := range constants.AllowedExpressions {
+			allowedList.WriteString("  - ")
+			allowedList.WriteString(expr)
+			allowedList.WriteString("\n")
+		}
+		allowedList.WriteString("  - needs.*\n")
+		allowedList.WriteString("  - steps.*\n")
+		allowedList.WriteString("  - github.event.inputs.*\n")
+		allowedList.WriteString("  - github.aw.inputs.* (shared workflow inputs)\n")
+		allowedList.WriteString("  - inputs.* (workflow_call)\n")
+		allowedList.WriteString("  - env.*\n")
+
+		return NewValidationError(
+			"expressions",
+			fmt.Sprintf("%d unauthorized expressions found", len(unauthorizedExpressions)),
+			fmt.Sprintf("expressions are not in the allowed list:%s", unauthorizedList.String()),
+			fmt.Sprintf("Use only allowed expressions:%s\nFor more details, see the expression security documentation.", allowedList.String()),
+		)
+	}
+
+	expressionValidationLog.Print("Expression safety validation passed")
+	return nil
+}
+
+// collectUnauthorizedExpressions returns the GitHub Actions expressions of the markdown content
+// that are not in the allowed list. An error is returned for expressions that are rejected
+// outright, such as those accessing dangerous properties.
+func collectUnauthorizedExpressions(markdownContent string) ([]string, error) {
 	// Regular expression to match GitHub Actions expressions: ${{ ... }}
 	// Use (?s) flag to enable dotall mode so . matches newlines to capture multiline expressions
 	// Use non-greedy matching with .*? to handle nested braces properly
@@ -118,7 +175,7 @@ func validateExpressionSafety(markdownContent string) error {
 				})
 			})
 			if validationErr != nil {
-				return validationErr
+				return nil, validationErr
 			}
 		} else {
 			// If parsing fails, fall back to validating the whole expression as a literal
@@ -131,57 +188,12 @@ func validateExpressionSafety(markdownContent string) error {
 				UnauthorizedExpressions: &unauthorizedExpressions,
 			})
 			if err != nil {
-				return err
-			}
-		}
-	}
-
-	// If we found unauthorized expressions, return an error
-	if len(unauthorizedExpressions) > 0 {
-		expressionValidationLog.Printf("Expression safety validation failed: %d unauthorized expressions found", len(unauthorizedExpressions))
-		// Format unauthorized expressions list with fuzzy match suggestions
-		var unauthorizedList strings.Builder
-		unauthorizedList.WriteString("\n")
-		for _, expr := range unauthorizedExpressions {
-			unauthorizedList.WriteString("  - ")
-			unauthorizedList.WriteString(expr)
-
-			// Find closest matches using fuzzy string matching
-			closestMatches := parser.FindClosestMatches(expr, constants.AllowedExpressions, maxFuzzyMatchSuggestions)
-			if len(closestMatches) > 0 {
-				unauthorizedList.WriteString(" (did you mean: ")
-				unauthorizedList.WriteString(strings.Join(closestMatches, ", "))
-				unauthorizedList.WriteString("?)")
+				return nil, err
 			}
-
-			unauthorizedList.WriteString("\n")
 		}
-
-		// Format allowed expressions list
-		var allowedList strings.Builder
-		allowedList.WriteString("\n")
-		for _, expr := range constants.AllowedExpressions {
-			allowedList.WriteString("  - ")
-			allowedList.WriteString(expr)
-			allowedList.WriteString("\n")
-		}
-		allowedList.WriteString("  - needs.*\n")
-		allowedList.WriteString("  - steps.*\n")
-		allowedList.WriteString("  - github.event.inputs.*\n")
-		allowedList.WriteString("  - github.aw.inputs.* (shared workflow inputs)\n")
-		allowedList.WriteString("  - inputs.* (workflow_call)\n")
-		allowedList.WriteString("  - env.*\n")
-
-		return NewValidationError(
-			"expressions",
-			fmt.Sprintf("%d unauthorized expressions found", len(unauthorizedExpressions)),
-			fmt.Sprintf("expressions are not in the allowed list:%s", unauthorizedList.String()),
-			fmt.Sprintf("Use only allowed expressions:%s\nFor more details, see the expression security documentation.", allowedList.String()),
-		)
 	}
 
-	expressionValidationLog.Print("Expression safety validation passed")
-	return nil
+	return unauthorizedExpressions, nil
 }
 
 // ExpressionValidationOptions contains the options for validating a single expression
@@ -155,6 +155,7 @@ type FrontmatterConfig struct {
 	Roles     []string         `json:"roles,omitempty"`
 	Bots      []string         `json:"bots,omitempty"`
 	RateLimit *RateLimitConfig `json:"rate-limit,omitempty"`
+	Budget    *BudgetConfig    `json:"budget,omitempty"`
 }
 
 // unmarshalFromMap converts a value from a map[string]any to a destination variable
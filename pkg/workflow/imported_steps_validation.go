@@ -239,7 +239,7 @@ func (c *Compiler) validateImportedStepsNoAgenticSecrets(engineConfig *EngineCon
 	// Non-strict mode: warning only
 	importedStepsValidationLog.Printf("Non-strict mode: emitting warning for agentic secrets in custom steps")
 	fmt.Fprintln(os.Stderr, console.FormatWarningMessage(errorMsg))
-	c.IncrementWarningCount()
+	c.recordWarning(errorMsg)
 	return nil
 }
 
@@ -447,6 +447,10 @@ func hasSafeOutputType(config *SafeOutputsConfig, key string) bool {
 		return config.SubmitPullRequestReview != nil
 	case "create-code-scanning-alert":
 		return config.CreateCodeScanningAlerts != nil
+	case "create-check-run":
+		return config.CreateCheckRuns != nil
+	case "notify-webhook":
+		return config.NotifyWebhook != nil
 	case "add-labels":
 		return config.AddLabels != nil
 	case "remove-labels":
@@ -534,6 +538,12 @@ func mergeSafeOutputConfig(result *SafeOutputsConfig, config map[string]any, c *
 	if result.CreateCodeScanningAlerts == nil && importedConfig.CreateCodeScanningAlerts != nil {
 		result.CreateCodeScanningAlerts = importedConfig.CreateCodeScanningAlerts
 	}
+	if result.CreateCheckRuns == nil && importedConfig.CreateCheckRuns != nil {
+		result.CreateCheckRuns = importedConfig.CreateCheckRuns
+	}
+	if result.NotifyWebhook == nil && importedConfig.NotifyWebhook != nil {
+		result.NotifyWebhook = importedConfig.NotifyWebhook
+	}
 	if result.AutofixCodeScanningAlert == nil && importedConfig.AutofixCodeScanningAlert != nil {
 		result.AutofixCodeScanningAlert = importedConfig.AutofixCodeScanningAlert
 	}
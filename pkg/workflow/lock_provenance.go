@@ -0,0 +1,190 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/stringutil"
+)
+
+var lockProvenanceLog = logger.New("workflow:lock_provenance")
+
+// LockProvenance records how a lock file was produced: the compiler version (released builds
+// only), a digest of every source file the compiler read, and the commit SHA of every pinned
+// action. It is embedded as a comment block in the lock file header.
+type LockProvenance struct {
+	Compiler string            `json:"compiler,omitempty"` // Empty for development builds
+	Sources  map[string]string `json:"sources"`            // Source path -> "sha256:<hex>" digest of what the compiler reads
+	Actions  map[string]string `json:"actions"`            // owner/repo@version -> commit SHA
+}
+
+// pinnedActionPattern matches action references pinned to a commit SHA, with the optional
+// version comment added by the action pinning
+var pinnedActionPattern = regexp.MustCompile(`(?m)^\s*(?:-\s+)?uses:\s+([^@\s]+)@([0-9a-f]{40})(?:\s+#\s+(\S+))?\s*$`)
+
+// buildLockProvenance computes the provenance of a lock file from the workflow data and the
+// generated workflow body. Sources are keyed as in the lock file manifest, relative to the
+// workflow directory. Sources that cannot be read are left out.
+func (c *Compiler) buildLockProvenance(data *WorkflowData, markdownPath string, body string) *LockProvenance {
+	provenance := &LockProvenance{
+		Sources: make(map[string]string),
+		Actions: extractPinnedActions(body),
+	}
+	// Compiler version - only include for released builds, like cli_version in aw_info
+	if IsReleasedVersion(c.version) {
+		provenance.Compiler = "gh-aw " + c.version
+	}
+	if markdownPath == "" {
+		return provenance
+	}
+
+	baseDir := filepath.Dir(markdownPath)
+	c.addProvenanceSource(provenance, filepath.Base(markdownPath), markdownPath)
+	for _, file := range append(append([]string{}, data.ImportedFiles...), data.IncludedFiles...) {
+		importPath, _, _ := strings.Cut(file, "#")
+		fullPath, err := parser.ResolveIncludePath(importPath, baseDir, c.getSharedImportCache())
+		if err != nil {
+			lockProvenanceLog.Printf("Skipping unresolved source %s: %v", file, err)
+			continue
+		}
+		c.addProvenanceSource(provenance, file, fullPath)
+	}
+
+	lockProvenanceLog.Printf("Built provenance: %d sources, %d actions", len(provenance.Sources), len(provenance.Actions))
+	return provenance
+}
+
+// addProvenanceSource records the digest of a source file
+func (c *Compiler) addProvenanceSource(provenance *LockProvenance, key, fullPath string) {
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		lockProvenanceLog.Printf("Skipping unreadable source %s: %v", fullPath, err)
+		return
+	}
+	provenance.Sources[filepath.ToSlash(stringutil.StripANSIEscapeCodes(key))] = parser.ComputeSourceDigest(fullPath, string(content))
+}
+
+// extractPinnedActions returns the commit SHA of every action pinned in a workflow, keyed by
+// owner/repo@version, or by the bare action reference when there is no version comment
+func extractPinnedActions(yamlContent string) map[string]string {
+	actions := make(map[string]string)
+	for _, match := range pinnedActionPattern.FindAllStringSubmatch(yamlContent, -1) {
+		key := match[1]
+		if match[3] != "" {
+			key += "@" + match[3]
+		}
+		actions[key] = match[2]
+	}
+	return actions
+}
+
+// writeLockProvenance writes the provenance as a comment block of the lock file header
+func writeLockProvenance(yaml *strings.Builder, provenance *LockProvenance) {
+	yaml.WriteString("#\n")
+	yaml.WriteString("# Provenance:\n")
+	if provenance.Compiler != "" {
+		fmt.Fprintf(yaml, "#   compiler: %s\n", provenance.Compiler)
+	}
+	writeProvenanceMap(yaml, "sources", provenance.Sources)
+	writeProvenanceMap(yaml, "actions", provenance.Actions)
+}
+
+// writeProvenanceMap writes a section of the provenance block with sorted keys
+func writeProvenanceMap(yaml *strings.Builder, name string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(yaml, "#   %s:\n", name)
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		fmt.Fprintf(yaml, "#     %s: %s\n", key, values[key])
+	}
+}
+
+// ParseLockProvenance reads the provenance block from the header of a lock file. It returns nil
+// when the lock file has no provenance, for example because it was compiled by an older version.
+func ParseLockProvenance(lockYAML string) *LockProvenance {
+	var provenance *LockProvenance
+	var section map[string]string
+	for _, line := range strings.Split(lockYAML, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break // The header ends at the first non-comment line
+		}
+		text := strings.TrimPrefix(line, "#")
+		if strings.TrimSpace(text) == "Provenance:" {
+			provenance = &LockProvenance{Sources: make(map[string]string), Actions: make(map[string]string)}
+			section = nil
+			continue
+		}
+		if provenance == nil {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "     "):
+			if key, value, ok := strings.Cut(strings.TrimSpace(text), ": "); ok && section != nil {
+				section[key] = value
+			}
+		case strings.HasPrefix(text, "   "):
+			key, value, _ := strings.Cut(strings.TrimSpace(text), ":")
+			switch key {
+			case "compiler":
+				provenance.Compiler = strings.TrimSpace(value)
+				section = nil
+			case "sources":
+				section = provenance.Sources
+			case "actions":
+				section = provenance.Actions
+			default:
+				section = nil
+			}
+		default:
+			// Any other header line ends the provenance block
+			return provenance
+		}
+	}
+	return provenance
+}
+
+// Diff explains how the provenance of a recompiled lock file differs from this one. The
+// receiver is the committed provenance and other the provenance of the recompiled lock file.
+// Compiler versions are only compared when both lock files were compiled by released builds.
+func (p *LockProvenance) Diff(other *LockProvenance) []string {
+	var divergences []string
+	if p.Compiler != "" && other.Compiler != "" && p.Compiler != other.Compiler {
+		divergences = append(divergences, fmt.Sprintf("compiled by %s, recompiled with %s", p.Compiler, other.Compiler))
+	}
+	divergences = append(divergences, diffProvenanceMap("source", p.Sources, other.Sources)...)
+	divergences = append(divergences, diffProvenanceMap("action", p.Actions, other.Actions)...)
+	return divergences
+}
+
+// diffProvenanceMap describes the added, removed and changed entries of a provenance section
+func diffProvenanceMap(kind string, committed, recompiled map[string]string) []string {
+	var divergences []string
+	for _, key := range slices.Sorted(maps.Keys(committed)) {
+		value, ok := recompiled[key]
+		switch {
+		case !ok:
+			divergences = append(divergences, fmt.Sprintf("%s %s is no longer used", kind, key))
+		case value != committed[key]:
+			if kind == "source" {
+				divergences = append(divergences, fmt.Sprintf("source %s changed since the lock file was compiled", key))
+			} else {
+				divergences = append(divergences, fmt.Sprintf("action %s is pinned to %s instead of %s", key, value, committed[key]))
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(recompiled)) {
+		if _, ok := committed[key]; !ok {
+			divergences = append(divergences, fmt.Sprintf("%s %s is new", kind, key))
+		}
+	}
+	return divergences
+}
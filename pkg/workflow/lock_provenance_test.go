@@ -0,0 +1,147 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockProvenanceInHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(filepath.Join(workflowsDir, "shared"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "shared", "tools.md"), []byte("---\ntools:\n  bash: [ls]\n---\n\nShared instructions.\n"), 0644))
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened]
+imports:
+  - shared/tools.md
+---
+
+# Triage
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	header, _, _ := strings.Cut(lockYAML, "\nname: ")
+	assert.Contains(t, header, "# Provenance:\n#   sources:\n", "development builds do not record the compiler version")
+	assert.Regexp(t, `#     shared/tools.md: sha256:[0-9a-f]{64}\n`, header)
+	assert.Regexp(t, `#     triage.md: sha256:[0-9a-f]{64}\n`, header)
+	assert.Regexp(t, `#     actions/checkout@\S+: [0-9a-f]{40}\n`, header, "pinned actions are listed with their commit SHA")
+
+	provenance := ParseLockProvenance(lockYAML)
+	require.NotNil(t, provenance)
+	assert.Empty(t, provenance.Compiler)
+	assert.Len(t, provenance.Sources, 2)
+	assert.Equal(t, extractPinnedActions(lockYAML), provenance.Actions, "the header lists every pinned action of the body")
+
+	// Markdown bodies are loaded at runtime, so editing them leaves the provenance unchanged
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "shared", "tools.md"), []byte("---\ntools:\n  bash: [ls]\n---\n\nShared instructions, updated.\n"), 0644))
+	_, recompiled, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+	assert.Empty(t, provenance.Diff(ParseLockProvenance(recompiled)))
+
+	// Editing the shared frontmatter changes its digest, but not the digest of the main workflow
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "shared", "tools.md"), []byte("---\ntools:\n  bash: [ls, cat]\n---\n\nShared instructions.\n"), 0644))
+	_, recompiled, err = compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"source shared/tools.md changed since the lock file was compiled"}, provenance.Diff(ParseLockProvenance(recompiled)))
+}
+
+func TestLockProvenanceCompilerInReleaseBuild(t *testing.T) {
+	originalIsRelease := isReleaseBuild
+	defer func() { isReleaseBuild = originalIsRelease }()
+
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte("---\non: push\n---\n\n# Triage\n"), 0644))
+
+	SetIsRelease(true)
+	compiler := NewCompiler(WithGitRoot(tmpDir), WithVersion("v1.2.3"))
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	provenance := ParseLockProvenance(lockYAML)
+	require.NotNil(t, provenance)
+	assert.Equal(t, "gh-aw v1.2.3", provenance.Compiler, "released builds record the compiler version")
+}
+
+func TestParseLockProvenance(t *testing.T) {
+	assert.Nil(t, ParseLockProvenance("# Source: triage.md\n\nname: triage\n"), "lock files without provenance return nil")
+
+	provenance := ParseLockProvenance(`#
+# frontmatter-hash: abc
+#
+# Provenance:
+#   compiler: gh-aw v1.2.3
+#   sources:
+#     shared/tools.md: sha256:111
+#     triage.md: sha256:222
+#   actions:
+#     actions/checkout@v6.0.2: de0fac2e4500dabe0009e67214ff5f5447ce83dd
+#
+# Effective stop-time: 2026-01-01 00:00:00
+
+name: "triage"
+#   sources:
+`)
+	require.NotNil(t, provenance)
+	assert.Equal(t, &LockProvenance{
+		Compiler: "gh-aw v1.2.3",
+		Sources:  map[string]string{"shared/tools.md": "sha256:111", "triage.md": "sha256:222"},
+		Actions:  map[string]string{"actions/checkout@v6.0.2": "de0fac2e4500dabe0009e67214ff5f5447ce83dd"},
+	}, provenance)
+}
+
+func TestLockProvenanceDiff(t *testing.T) {
+	committed := &LockProvenance{
+		Compiler: "gh-aw v1.0.0",
+		Sources:  map[string]string{"triage.md": "sha256:1", "shared/old.md": "sha256:2"},
+		Actions:  map[string]string{"actions/checkout@v5": "aaa"},
+	}
+	recompiled := &LockProvenance{
+		Compiler: "gh-aw v1.1.0",
+		Sources:  map[string]string{"triage.md": "sha256:3", "shared/new.md": "sha256:4"},
+		Actions:  map[string]string{"actions/checkout@v5": "bbb"},
+	}
+
+	assert.Equal(t, []string{
+		"compiled by gh-aw v1.0.0, recompiled with gh-aw v1.1.0",
+		"source shared/old.md is no longer used",
+		"source triage.md changed since the lock file was compiled",
+		"source shared/new.md is new",
+		"action actions/checkout@v5 is pinned to bbb instead of aaa",
+	}, committed.Diff(recompiled))
+	assert.Empty(t, committed.Diff(committed))
+
+	// Versions are only compared when both lock files were compiled by released builds
+	devBuild := &LockProvenance{Sources: committed.Sources, Actions: committed.Actions}
+	assert.Empty(t, committed.Diff(devBuild))
+	assert.Empty(t, devBuild.Diff(committed))
+}
+
+func TestExtractPinnedActions(t *testing.T) {
+	actions := extractPinnedActions(`    steps:
+      - uses: actions/checkout@de0fac2e4500dabe0009e67214ff5f5447ce83dd # v6.0.2
+      - name: Setup
+        uses: ./actions/setup
+      - uses: github/codeql-action/upload-sarif@0123456789abcdef0123456789abcdef01234567
+      - uses: actions/cache@v4
+`)
+	assert.Equal(t, map[string]string{
+		"actions/checkout@v6.0.2":           "de0fac2e4500dabe0009e67214ff5f5447ce83dd",
+		"github/codeql-action/upload-sarif": "0123456789abcdef0123456789abcdef01234567",
+	}, actions, "only actions pinned to a commit SHA are listed")
+}
@@ -0,0 +1,219 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var notifyWebhookLog = logger.New("workflow:notify_webhook")
+
+// webhookPayloadFormats are the payload templates the notify_webhook job can render
+var webhookPayloadFormats = []string{"slack", "teams", "json"}
+
+// webhookSeverities are the severities an agent may attach to a notification
+var webhookSeverities = []string{"info", "warning", "critical"}
+
+// webhookEndpointNamePattern restricts endpoint names to identifiers that map cleanly to environment variables
+var webhookEndpointNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// WebhookEndpointConfig holds the secret URL and payload template of a webhook endpoint
+type WebhookEndpointConfig struct {
+	URL    string `yaml:"url"`              // Secret expression holding the webhook URL (e.g., ${{ secrets.SLACK_WEBHOOK_URL }})
+	Format string `yaml:"format,omitempty"` // Payload template: "slack", "teams" or "json" (default: "json")
+}
+
+// NotifyWebhookConfig holds configuration for posting agent notifications to webhooks
+type NotifyWebhookConfig struct {
+	BaseSafeOutputConfig `yaml:",inline"`
+	Endpoints            map[string]*WebhookEndpointConfig `yaml:"endpoints,omitempty"`       // Named endpoints the agent can notify
+	AllowedDomains       []string                          `yaml:"allowed-domains,omitempty"` // Domains the webhook URLs must resolve to
+}
+
+// parseNotifyWebhookConfig handles notify-webhook configuration
+func (c *Compiler) parseNotifyWebhookConfig(outputMap map[string]any) *NotifyWebhookConfig {
+	configData, exists := outputMap["notify-webhook"]
+	if !exists {
+		return nil
+	}
+
+	notifyWebhookLog.Print("Parsing notify-webhook configuration")
+	config := &NotifyWebhookConfig{Endpoints: make(map[string]*WebhookEndpointConfig)}
+
+	configMap, ok := configData.(map[string]any)
+	if !ok {
+		// Without endpoints the configuration is rejected by validateNotifyWebhookConfig
+		config.Max = 1
+		return config
+	}
+
+	if endpoints, ok := configMap["endpoints"].(map[string]any); ok {
+		for name, endpointData := range endpoints {
+			endpoint := &WebhookEndpointConfig{}
+			switch value := endpointData.(type) {
+			case string:
+				// Shorthand: endpoint-name: ${{ secrets.WEBHOOK_URL }}
+				endpoint.URL = value
+			case map[string]any:
+				if url, ok := value["url"].(string); ok {
+					endpoint.URL = url
+				}
+				if format, ok := value["format"].(string); ok {
+					endpoint.Format = format
+				}
+			}
+			config.Endpoints[name] = endpoint
+		}
+	}
+	config.AllowedDomains = ParseStringArrayFromConfig(configMap, "allowed-domains", notifyWebhookLog)
+
+	// Parse common base fields with default max of 1
+	c.parseBaseSafeOutputConfig(configMap, &config.BaseSafeOutputConfig, 1)
+
+	notifyWebhookLog.Printf("Parsed notify-webhook config: endpoints=%d, allowed_domains=%d, max=%d", len(config.Endpoints), len(config.AllowedDomains), config.Max)
+	return config
+}
+
+// endpointNames returns the configured endpoint names in a stable order
+func (config *NotifyWebhookConfig) endpointNames() []string {
+	names := make([]string, 0, len(config.Endpoints))
+	for name := range config.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// webhookURLEnvVar returns the environment variable that carries the secret URL of an endpoint
+func webhookURLEnvVar(name string) string {
+	return "GH_AW_WEBHOOK_URL_" + strings.ToUpper(strings.NewReplacer("-", "_").Replace(name))
+}
+
+// validateNotifyWebhookConfig checks the notify-webhook endpoints. Webhook URLs must come from
+// secrets so they never appear in the lock file or the agent prompt.
+func validateNotifyWebhookConfig(config *SafeOutputsConfig) error {
+	if config == nil || config.NotifyWebhook == nil {
+		return nil
+	}
+	notifyWebhook := config.NotifyWebhook
+
+	if len(notifyWebhook.Endpoints) == 0 {
+		return errors.New("safe-outputs.notify-webhook requires at least one endpoint\n\nExample:\nsafe-outputs:\n  notify-webhook:\n    endpoints:\n      ops:\n        url: ${{ secrets.SLACK_WEBHOOK_URL }}\n        format: slack\n    allowed-domains: [hooks.slack.com]")
+	}
+
+	// Endpoint names map to environment variables, where '-' and '_' are the same
+	envVarNames := make(map[string]string, len(notifyWebhook.Endpoints))
+	for _, name := range notifyWebhook.endpointNames() {
+		endpoint := notifyWebhook.Endpoints[name]
+		if !webhookEndpointNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid safe-outputs.notify-webhook endpoint name %q: use lowercase letters, digits, '-' and '_', starting with a letter", name)
+		}
+		envVar := webhookURLEnvVar(name)
+		if other, exists := envVarNames[envVar]; exists {
+			return fmt.Errorf("safe-outputs.notify-webhook endpoint names %q and %q both map to %s: rename one of them", other, name, envVar)
+		}
+		envVarNames[envVar] = name
+		if err := validateSecretsExpression(endpoint.URL); err != nil {
+			return fmt.Errorf("invalid safe-outputs.notify-webhook.endpoints.%s.url: %w", name, err)
+		}
+		if endpoint.Format != "" && !slices.Contains(webhookPayloadFormats, endpoint.Format) {
+			return fmt.Errorf("invalid safe-outputs.notify-webhook.endpoints.%s.format %q: must be one of %s", name, endpoint.Format, strings.Join(webhookPayloadFormats, ", "))
+		}
+	}
+	return nil
+}
+
+// buildNotifyWebhookJob creates the notify_webhook job
+func (c *Compiler) buildNotifyWebhookJob(data *WorkflowData, mainJobName string, threatDetectionEnabled bool) (*Job, error) {
+	notifyWebhookLog.Printf("Building notify_webhook job: workflow=%s, main_job=%s, threat_detection=%v", data.Name, mainJobName, threatDetectionEnabled)
+
+	if data.SafeOutputs == nil || data.SafeOutputs.NotifyWebhook == nil {
+		return nil, fmt.Errorf("safe-outputs.notify-webhook configuration is required")
+	}
+	config := data.SafeOutputs.NotifyWebhook
+
+	var preSteps []string
+
+	// Add setup step to copy scripts
+	setupActionRef := c.resolveActionReference("./actions/setup", data)
+	if setupActionRef != "" || c.actionMode.IsScript() {
+		// For dev mode (local action path), checkout the actions folder first
+		preSteps = append(preSteps, c.generateCheckoutActionsFolder(data)...)
+
+		// Notify webhook job doesn't need project support
+		preSteps = append(preSteps, c.generateSetupStep(setupActionRef, SetupActionDestination, false)...)
+	}
+
+	// The endpoint map only carries names, formats and environment variable names; the URLs
+	// themselves are passed as secrets so they are masked in the job log
+	endpoints := make(map[string]map[string]string, len(config.Endpoints))
+	var urlEnvVars []string
+	for _, name := range config.endpointNames() {
+		endpoint := config.Endpoints[name]
+		format := endpoint.Format
+		if format == "" {
+			format = "json"
+		}
+		envVar := webhookURLEnvVar(name)
+		endpoints[name] = map[string]string{"format": format, "env": envVar}
+		urlEnvVars = append(urlEnvVars, fmt.Sprintf("          %s: %s\n", envVar, endpoint.URL))
+	}
+	endpointsJSON, err := json.Marshal(endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notify-webhook endpoints: %w", err)
+	}
+
+	// Build custom environment variables specific to notify-webhook
+	var customEnvVars []string
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_WEBHOOK_ENDPOINTS: %q\n", string(endpointsJSON)))
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_WEBHOOK_ALLOWED_DOMAINS: %q\n", strings.Join(config.AllowedDomains, ",")))
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_WEBHOOK_MAX: %d\n", config.Max))
+	customEnvVars = append(customEnvVars, urlEnvVars...)
+
+	// Add standard environment variables (metadata + staged/target repo)
+	customEnvVars = append(customEnvVars, c.buildStandardSafeOutputEnvVars(data, "")...) // Webhooks don't target a repository
+
+	// Create outputs for the job
+	outputs := map[string]string{
+		"notifications_sent": "${{ steps.notify_webhook.outputs.notifications_sent }}",
+	}
+
+	// Build job dependencies; agent output flagged by threat detection must not leave the run
+	needs := []string{mainJobName}
+	condition := BuildSafeOutputType("notify_webhook")
+	if threatDetectionEnabled {
+		needs = append(needs, string(constants.DetectionJobName))
+		condition = BuildAnd(condition, buildDetectionSuccessCondition())
+		notifyWebhookLog.Printf("Added detection job dependency for notify_webhook")
+	}
+
+	// The job only talks to the webhook endpoints; checking out the local actions folder is the
+	// only reason to read repository contents
+	permissions := NewPermissionsEmpty()
+	if setupActionRef != "" && len(c.generateCheckoutActionsFolder(data)) > 0 {
+		permissions = NewPermissionsContentsRead()
+	}
+
+	// Use the shared builder function to create the job
+	return c.buildSafeOutputJob(data, SafeOutputJobConfig{
+		JobName:       "notify_webhook",
+		StepName:      "Send Webhook Notifications",
+		StepID:        "notify_webhook",
+		ScriptName:    "notify_webhook",
+		MainJobName:   mainJobName,
+		CustomEnvVars: customEnvVars,
+		Permissions:   permissions,
+		Outputs:       outputs,
+		Condition:     condition,
+		PreSteps:      preSteps,
+		Token:         config.GitHubToken,
+		Needs:         needs,
+	})
+}
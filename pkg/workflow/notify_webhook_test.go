@@ -0,0 +1,181 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotifyWebhookConfig(t *testing.T) {
+	c := &Compiler{}
+
+	assert.Nil(t, c.parseNotifyWebhookConfig(map[string]any{}), "missing notify-webhook should not be parsed")
+
+	config := c.parseNotifyWebhookConfig(map[string]any{
+		"notify-webhook": map[string]any{
+			"endpoints": map[string]any{
+				"ops":       map[string]any{"url": "${{ secrets.SLACK_WEBHOOK_URL }}", "format": "slack"},
+				"incidents": "${{ secrets.PAGER_WEBHOOK_URL }}",
+			},
+			"allowed-domains": []any{"hooks.slack.com", "events.example.com"},
+			"max":             2,
+		},
+	})
+	require.NotNil(t, config)
+	assert.Equal(t, 2, config.Max)
+	assert.Equal(t, []string{"hooks.slack.com", "events.example.com"}, config.AllowedDomains)
+	assert.Equal(t, []string{"incidents", "ops"}, config.endpointNames())
+	assert.Equal(t, &WebhookEndpointConfig{URL: "${{ secrets.SLACK_WEBHOOK_URL }}", Format: "slack"}, config.Endpoints["ops"])
+	assert.Equal(t, &WebhookEndpointConfig{URL: "${{ secrets.PAGER_WEBHOOK_URL }}"}, config.Endpoints["incidents"])
+}
+
+func TestWebhookURLEnvVar(t *testing.T) {
+	assert.Equal(t, "GH_AW_WEBHOOK_URL_OPS", webhookURLEnvVar("ops"))
+	assert.Equal(t, "GH_AW_WEBHOOK_URL_ON_CALL", webhookURLEnvVar("on-call"))
+}
+
+func TestValidateNotifyWebhookConfig(t *testing.T) {
+	valid := func() *NotifyWebhookConfig {
+		return &NotifyWebhookConfig{
+			Endpoints: map[string]*WebhookEndpointConfig{
+				"ops": {URL: "${{ secrets.SLACK_WEBHOOK_URL }}", Format: "slack"},
+			},
+			AllowedDomains: []string{"hooks.slack.com"},
+		}
+	}
+
+	require.NoError(t, validateNotifyWebhookConfig(nil))
+	require.NoError(t, validateNotifyWebhookConfig(&SafeOutputsConfig{NotifyWebhook: valid()}))
+
+	tests := []struct {
+		name        string
+		mutate      func(*NotifyWebhookConfig)
+		errContains string
+	}{
+		{
+			name:        "no endpoints",
+			mutate:      func(c *NotifyWebhookConfig) { c.Endpoints = nil },
+			errContains: "requires at least one endpoint",
+		},
+		{
+			name: "literal URL",
+			mutate: func(c *NotifyWebhookConfig) {
+				c.Endpoints["ops"].URL = "https://hooks.slack.com/services/T000/B000/XXXX"
+			},
+			errContains: "notify-webhook.endpoints.ops.url",
+		},
+		{
+			name:        "unknown format",
+			mutate:      func(c *NotifyWebhookConfig) { c.Endpoints["ops"].Format = "discord" },
+			errContains: `format "discord"`,
+		},
+		{
+			name: "invalid endpoint name",
+			mutate: func(c *NotifyWebhookConfig) {
+				c.Endpoints["Ops Team"] = &WebhookEndpointConfig{URL: "${{ secrets.TEAM_WEBHOOK_URL }}"}
+			},
+			errContains: `endpoint name "Ops Team"`,
+		},
+		{
+			name: "endpoint names with the same env var",
+			mutate: func(c *NotifyWebhookConfig) {
+				c.Endpoints["ops-a"] = &WebhookEndpointConfig{URL: "${{ secrets.OPS_A_WEBHOOK_URL }}"}
+				c.Endpoints["ops_a"] = &WebhookEndpointConfig{URL: "${{ secrets.OPS_A2_WEBHOOK_URL }}"}
+			},
+			errContains: `"ops-a" and "ops_a" both map to GH_AW_WEBHOOK_URL_OPS_A`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.mutate(config)
+			err := validateNotifyWebhookConfig(&SafeOutputsConfig{NotifyWebhook: config})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateNotifyWebhookAllowedDomains(t *testing.T) {
+	c := NewCompiler()
+
+	require.NoError(t, c.validateNotifyWebhookAllowedDomains(&SafeOutputsConfig{
+		NotifyWebhook: &NotifyWebhookConfig{AllowedDomains: []string{"hooks.slack.com", "*.webhook.office.com"}},
+	}))
+
+	err := c.validateNotifyWebhookAllowedDomains(&SafeOutputsConfig{NotifyWebhook: &NotifyWebhookConfig{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires allowed-domains")
+
+	err = c.validateNotifyWebhookAllowedDomains(&SafeOutputsConfig{
+		NotifyWebhook: &NotifyWebhookConfig{AllowedDomains: []string{"hooks.slack.com", "*"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notify-webhook.allowed-domains[1]")
+}
+
+func TestNotifyWebhookJob(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "nightly.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+safe-outputs:
+  notify-webhook:
+    endpoints:
+      ops:
+        url: ${{ secrets.SLACK_WEBHOOK_URL }}
+        format: slack
+      on-call: ${{ secrets.PAGER_WEBHOOK_URL }}
+    allowed-domains: [hooks.slack.com, events.example.com]
+    max: 2
+---
+
+# Nightly
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	jobSection := extractJobSection(lockYAML, "notify_webhook")
+	require.NotEmpty(t, jobSection, "notify_webhook job should be generated")
+	assert.NotContains(t, jobSection, "write", "job should not get write permissions")
+	assert.Contains(t, jobSection, "- detection", "job should wait for threat detection")
+	assert.Contains(t, jobSection, "contains(needs.agent.outputs.output_types, 'notify_webhook')")
+	assert.Contains(t, jobSection, `    if: >
+      (((!cancelled()) && (needs.agent.result != 'skipped')) && (contains(needs.agent.outputs.output_types, 'notify_webhook'))) &&
+      (needs.detection.outputs.success == 'true')
+`, "job should not run when threat detection flags the output")
+	assert.Contains(t, jobSection, "GH_AW_WEBHOOK_URL_OPS: ${{ secrets.SLACK_WEBHOOK_URL }}")
+	assert.Contains(t, jobSection, "GH_AW_WEBHOOK_URL_ON_CALL: ${{ secrets.PAGER_WEBHOOK_URL }}")
+	assert.Contains(t, jobSection, `GH_AW_WEBHOOK_ALLOWED_DOMAINS: "hooks.slack.com,events.example.com"`)
+	assert.Contains(t, jobSection, "GH_AW_WEBHOOK_MAX: 2")
+	assert.Contains(t, jobSection, `\"on-call\":{\"env\":\"GH_AW_WEBHOOK_URL_ON_CALL\",\"format\":\"json\"}`)
+	assert.Contains(t, jobSection, "require('/opt/gh-aw/actions/notify_webhook.cjs')")
+
+	agentSection := extractJobSection(lockYAML, "agent")
+	assert.NotContains(t, agentSection, "GH_AW_WEBHOOK_URL_OPS", "webhook URLs should not be passed to the agent job")
+
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+safe-outputs:
+  notify-webhook:
+    endpoints:
+      ops: https://hooks.slack.com/services/T000/B000/XXXX
+    allowed-domains: [hooks.slack.com]
+---
+
+# Nightly
+`), 0644))
+	_, _, err = compiler.CompileWorkflowToYAML(workflowPath)
+	require.Error(t, err, "literal webhook URLs should be rejected")
+}
@@ -117,6 +117,14 @@ func NewPermissionsActionsWriteContentsWriteIssuesWritePRWrite() *Permissions {
 	})
 }
 
+// NewPermissionsChecksWrite creates permissions with checks: write
+// This is required for publishing check runs with annotations
+func NewPermissionsChecksWrite() *Permissions {
+	return NewPermissionsFromMap(map[PermissionScope]PermissionLevel{
+		PermissionChecks: PermissionWrite,
+	})
+}
+
 // NewPermissionsContentsWrite creates permissions with contents: write
 func NewPermissionsContentsWrite() *Permissions {
 	return NewPermissionsFromMap(map[PermissionScope]PermissionLevel{
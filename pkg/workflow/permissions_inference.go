@@ -0,0 +1,190 @@
+// This file infers the least-privilege permissions of a workflow's agent job.
+//
+// The agent job needs read access for the GitHub MCP toolsets it enables, contents: read to check
+// out the repository, and actions: read for the agentic-workflows tool. Write access is never
+// needed by the agent itself when a safe-output job performs the write, since safe outputs run in
+// separate jobs with their own permissions. Custom steps and tools given the workflow token cannot
+// be analyzed, so the scopes they might use are kept.
+
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/goccy/go-yaml"
+)
+
+var permissionsInferenceLog = logger.New("workflow:permissions_inference")
+
+// PermissionsInference is the least-privilege analysis of the permissions of an agent job
+type PermissionsInference struct {
+	Granted     map[PermissionScope]PermissionLevel // Permissions declared for the agent job
+	Required    map[PermissionScope]PermissionLevel // Minimal permissions the agent job needs
+	Reasons     map[PermissionScope]string          // Why each required scope is needed
+	OverGranted []OverGrantedPermission             // Granted scopes above the required level
+}
+
+// OverGrantedPermission is a permission scope granted above the level the agent job needs
+type OverGrantedPermission struct {
+	Scope    PermissionScope `json:"scope"`
+	Granted  PermissionLevel `json:"granted"`
+	Required PermissionLevel `json:"required"` // "none" when the scope is not needed at all
+	Reason   string          `json:"reason"`
+}
+
+// InferAgentPermissions computes the minimal permissions of the agent job from the parsed workflow
+// data and its compiled YAML, which provides the permissions of the safe-output jobs.
+func InferAgentPermissions(data *WorkflowData, lockYAML string) (*PermissionsInference, error) {
+	coveredBy, err := writeScopesOfSafeOutputJobs(data, lockYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	inference := &PermissionsInference{
+		Granted:  grantedPermissionLevels(NewPermissionsParser(data.Permissions).ToPermissions()),
+		Required: make(map[PermissionScope]PermissionLevel),
+		Reasons:  make(map[PermissionScope]string),
+	}
+	require := func(scope PermissionScope, level PermissionLevel, reason string) {
+		if permissionRank(level) > permissionRank(inference.Required[scope]) {
+			inference.Required[scope] = level
+		}
+		if existing := inference.Reasons[scope]; existing == "" {
+			inference.Reasons[scope] = reason
+		} else if !strings.Contains(existing, reason) {
+			inference.Reasons[scope] = existing + "; " + reason
+		}
+	}
+
+	if _, granted := inference.Granted[PermissionContents]; granted {
+		require(PermissionContents, PermissionRead, "repository checkout")
+	}
+
+	if data.ParsedTools != nil && data.ParsedTools.GitHub != nil {
+		github := data.ParsedTools.GitHub
+		readOnly := github.IsReadOnly()
+		toolsets := ParseGitHubToolsets(github.GetToolsets())
+		toolsetsByScope := make(map[PermissionScope][]string)
+		for _, toolset := range toolsets {
+			for scope := range collectRequiredPermissions([]string{toolset}, readOnly) {
+				toolsetsByScope[scope] = append(toolsetsByScope[scope], toolset)
+			}
+		}
+		for scope, level := range collectRequiredPermissions(toolsets, readOnly) {
+			sort.Strings(toolsetsByScope[scope])
+			require(scope, level, "github toolsets: "+strings.Join(toolsetsByScope[scope], ", "))
+		}
+	}
+
+	if _, hasAgenticWorkflows := data.Tools["agentic-workflows"]; hasAgenticWorkflows {
+		require(PermissionActions, PermissionRead, "agentic-workflows tool")
+	}
+
+	// OIDC tokens are requested by actions the compiler cannot see into
+	if inference.Granted[PermissionIdToken] == PermissionWrite {
+		require(PermissionIdToken, PermissionWrite, "OIDC authentication")
+	}
+
+	// Custom steps and tools given the workflow token use it in ways that cannot be analyzed;
+	// keep what they might use, except writes that a safe-output job already performs
+	if consumers := workflowTokenConsumers(data); len(consumers) > 0 {
+		for scope, granted := range inference.Granted {
+			level := granted
+			if level == PermissionWrite && len(coveredBy[scope]) > 0 {
+				level = PermissionRead
+			}
+			if permissionRank(level) > permissionRank(inference.Required[scope]) {
+				require(scope, level, strings.Join(consumers, ", "))
+			}
+		}
+	}
+
+	for scope, granted := range inference.Granted {
+		required := inference.Required[scope]
+		if permissionRank(granted) <= permissionRank(required) {
+			continue
+		}
+		if required == "" {
+			required = PermissionNone
+		}
+		reason := "not used by the agent job"
+		if granted == PermissionWrite && len(coveredBy[scope]) > 0 {
+			reason = "writes are performed by safe-output jobs: " + strings.Join(coveredBy[scope], ", ")
+		} else if required == PermissionRead {
+			reason = "the agent job only reads"
+		}
+		inference.OverGranted = append(inference.OverGranted, OverGrantedPermission{Scope: scope, Granted: granted, Required: required, Reason: reason})
+	}
+	sort.Slice(inference.OverGranted, func(i, j int) bool { return inference.OverGranted[i].Scope < inference.OverGranted[j].Scope })
+
+	permissionsInferenceLog.Printf("Inferred %d required scopes, %d over-granted", len(inference.Required), len(inference.OverGranted))
+	return inference, nil
+}
+
+// workflowTokenConsumers describes the parts of the agent job that can use the workflow token
+// outside of the GitHub MCP server: custom steps, and safe-input tools or MCP servers whose
+// configuration references the token
+func workflowTokenConsumers(data *WorkflowData) []string {
+	usesToken := func(value string) bool {
+		return strings.Contains(value, "GITHUB_TOKEN") || strings.Contains(value, "github.token")
+	}
+
+	var consumers []string
+	if data.CustomSteps != "" || data.PostSteps != "" {
+		consumers = append(consumers, "custom steps")
+	}
+	if data.SafeInputs != nil {
+		for _, tool := range data.SafeInputs.Tools {
+			if slices.ContainsFunc(slices.Collect(maps.Values(tool.Env)), usesToken) {
+				consumers = append(consumers, "safe-inputs tools")
+				break
+			}
+		}
+	}
+	for name, config := range data.Tools {
+		if name != "github" && usesToken(fmt.Sprint(config)) {
+			consumers = append(consumers, "MCP servers")
+			break
+		}
+	}
+	return consumers
+}
+
+// writeScopesOfSafeOutputJobs returns, for each scope, the safe-output jobs of the compiled
+// workflow with write access to it. Custom jobs from the jobs: section are not counted.
+func writeScopesOfSafeOutputJobs(data *WorkflowData, lockYAML string) (map[PermissionScope][]string, error) {
+	var lock struct {
+		Jobs map[string]struct {
+			Permissions any `yaml:"permissions"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(lockYAML), &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse compiled workflow: %w", err)
+	}
+
+	coveredBy := make(map[PermissionScope][]string)
+	for name, job := range lock.Jobs {
+		switch constants.JobName(name) {
+		case constants.AgentJobName, constants.ActivationJobName, constants.PreActivationJobName, constants.DetectionJobName:
+			continue
+		}
+		if _, isCustomJob := data.Jobs[name]; isCustomJob || job.Permissions == nil {
+			continue
+		}
+		for scope, level := range effectivePermissions(job.Permissions) {
+			if level == PermissionWrite {
+				coveredBy[scope] = append(coveredBy[scope], name)
+			}
+		}
+	}
+	for scope := range coveredBy {
+		sort.Strings(coveredBy[scope])
+	}
+	return coveredBy, nil
+}
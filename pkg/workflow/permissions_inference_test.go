@@ -0,0 +1,112 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileInferenceWorkflow(t *testing.T, frontmatter string) *PermissionsInference {
+	t.Helper()
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte("---\n"+frontmatter+"---\n\n# Triage\n"), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	compiler.SetQuiet(true)
+	data, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	inference, err := InferAgentPermissions(data, lockYAML)
+	require.NoError(t, err)
+	return inference
+}
+
+func TestInferAgentPermissions(t *testing.T) {
+	inference := compileInferenceWorkflow(t, `on:
+  issues:
+    types: [opened]
+strict: false
+features:
+  dangerous-permissions-write: true
+permissions:
+  contents: write
+  issues: write
+  pull-requests: read
+  actions: read
+  id-token: write
+tools:
+  github:
+    toolsets: [repos, issues]
+safe-outputs:
+  add-comment:
+`)
+
+	assert.Equal(t, map[PermissionScope]PermissionLevel{
+		PermissionContents: PermissionRead,
+		PermissionIssues:   PermissionRead,
+		PermissionIdToken:  PermissionWrite,
+	}, inference.Required)
+	assert.Equal(t, "repository checkout; github toolsets: repos", inference.Reasons[PermissionContents])
+	assert.Equal(t, "github toolsets: issues", inference.Reasons[PermissionIssues])
+
+	assert.Equal(t, []OverGrantedPermission{
+		{Scope: PermissionActions, Granted: PermissionRead, Required: PermissionNone, Reason: "not used by the agent job"},
+		{Scope: PermissionContents, Granted: PermissionWrite, Required: PermissionRead, Reason: "the agent job only reads"},
+		{Scope: PermissionIssues, Granted: PermissionWrite, Required: PermissionRead, Reason: "writes are performed by safe-output jobs: conclusion, safe_outputs"},
+		{Scope: PermissionPullRequests, Granted: PermissionRead, Required: PermissionNone, Reason: "not used by the agent job"},
+	}, inference.OverGranted)
+}
+
+func TestInferAgentPermissionsKeepsCustomStepScopes(t *testing.T) {
+	inference := compileInferenceWorkflow(t, `on: workflow_dispatch
+strict: false
+features:
+  dangerous-permissions-write: true
+permissions:
+  contents: read
+  issues: write
+  packages: read
+  actions: read
+tools:
+  github:
+    toolsets: [context]
+  agentic-workflows:
+steps:
+  - run: gh issue list
+safe-outputs:
+  create-issue:
+`)
+
+	assert.Equal(t, map[PermissionScope]PermissionLevel{
+		PermissionContents: PermissionRead,
+		PermissionIssues:   PermissionRead,
+		PermissionPackages: PermissionRead,
+		PermissionActions:  PermissionRead,
+	}, inference.Required, "custom steps keep granted scopes, but not writes done by safe outputs")
+	assert.Equal(t, "custom steps", inference.Reasons[PermissionPackages])
+	assert.Equal(t, "agentic-workflows tool", inference.Reasons[PermissionActions])
+	require.Len(t, inference.OverGranted, 1)
+	assert.Equal(t, PermissionIssues, inference.OverGranted[0].Scope)
+}
+
+func TestWorkflowTokenConsumers(t *testing.T) {
+	assert.Empty(t, workflowTokenConsumers(&WorkflowData{Tools: map[string]any{"github": map[string]any{"github-token": "${{ secrets.GITHUB_TOKEN }}"}}}),
+		"the GitHub MCP server is analyzed through its toolsets")
+
+	data := &WorkflowData{
+		PostSteps: "- run: echo done",
+		SafeInputs: &SafeInputsConfig{Tools: map[string]*SafeInputToolConfig{
+			"gh": {Env: map[string]string{"GH_TOKEN": "${{ secrets.GITHUB_TOKEN }}"}},
+		}},
+		Tools: map[string]any{"notion": map[string]any{"env": map[string]any{"TOKEN": "${{ github.token }}"}}},
+	}
+	assert.Equal(t, []string{"custom steps", "safe-inputs tools", "MCP servers"}, workflowTokenConsumers(data))
+}
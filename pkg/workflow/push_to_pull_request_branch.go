@@ -17,6 +17,8 @@ type PushToPullRequestBranchConfig struct {
 	Labels               []string `yaml:"labels,omitempty"`              // Required labels for pull request validation
 	IfNoChanges          string   `yaml:"if-no-changes,omitempty"`       // Behavior when no changes to push: "warn", "error", or "ignore" (default: "warn")
 	CommitTitleSuffix    string   `yaml:"commit-title-suffix,omitempty"` // Optional suffix to append to generated commit titles
+	AllowedPaths         []string `yaml:"allowed-paths,omitempty"`       // Globs of the paths the patch may modify. Protected paths must be listed exactly, without wildcards.
+	BlockedPaths         []string `yaml:"blocked-paths,omitempty"`       // Globs of the paths the patch may never modify
 }
 
 func buildCheckoutRepository(steps []string, c *Compiler) []string {
@@ -89,6 +91,10 @@ func (c *Compiler) parsePushToPullRequestBranchConfig(outputMap map[string]any)
 				}
 			}
 
+			// Parse path restrictions for the patch (optional)
+			pushToBranchConfig.AllowedPaths = ParseStringArrayFromConfig(configMap, "allowed-paths", pushToPullRequestBranchLog)
+			pushToBranchConfig.BlockedPaths = ParseStringArrayFromConfig(configMap, "blocked-paths", pushToPullRequestBranchLog)
+
 			// Parse common base fields with default max of 0 (no limit)
 			c.parseBaseSafeOutputConfig(configMap, &pushToBranchConfig.BaseSafeOutputConfig, 0)
 		}
@@ -0,0 +1,107 @@
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var replayEngineLog = logger.New("workflow:replay_engine")
+
+// ReplayEngine replays a recorded agent session from a fixture file instead of running an AI model.
+// It is intended for deterministic workflow tests: the recorded safe outputs flow through the
+// regular safe-output pipeline and the session log is written in the Claude stream-json format
+// so that `logs` and `audit` parse replayed runs exactly like real ones.
+type ReplayEngine struct {
+	BaseEngine
+}
+
+// NewReplayEngine creates a new ReplayEngine instance
+func NewReplayEngine() *ReplayEngine {
+	return &ReplayEngine{
+		BaseEngine: BaseEngine{
+			id:                     "replay",
+			displayName:            "Replay",
+			description:            "Replays a recorded agent session from a fixture file",
+			experimental:           true,
+			supportsToolsAllowlist: false,
+			supportsHTTPTransport:  false,
+			supportsMaxTurns:       false,
+			supportsWebFetch:       false,
+			supportsWebSearch:      false,
+		},
+	}
+}
+
+// GetRequiredSecretNames returns an empty list since replayed sessions never call a model
+func (e *ReplayEngine) GetRequiredSecretNames(workflowData *WorkflowData) []string {
+	return []string{}
+}
+
+// GetInstallationSteps returns no installation steps since there is no CLI to install
+func (e *ReplayEngine) GetInstallationSteps(workflowData *WorkflowData) []GitHubActionStep {
+	return []GitHubActionStep{}
+}
+
+// GetExecutionSteps returns the step that replays the fixture into the session log and safe outputs file
+func (e *ReplayEngine) GetExecutionSteps(workflowData *WorkflowData, logFile string) []GitHubActionStep {
+	fixture := ""
+	if workflowData.EngineConfig != nil {
+		fixture = workflowData.EngineConfig.Fixture
+	}
+	replayEngineLog.Printf("Building replay execution step: workflow=%s, fixture=%s", workflowData.Name, fixture)
+
+	env := map[string]string{
+		"GH_AW_REPLAY_FIXTURE": fmt.Sprintf("%q", "${{ github.workspace }}/"+path.Clean(fixture)),
+		"GH_AW_AGENT_LOG":      logFile,
+	}
+	applySafeOutputEnvToMap(env, workflowData)
+
+	stepLines := []string{
+		"      - name: Replay recorded agent session",
+		"        id: agentic_execution",
+		fmt.Sprintf("        uses: %s", GetActionPin("actions/github-script")),
+		"        env:",
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stepLines = append(stepLines, fmt.Sprintf("          %s: %s", key, env[key]))
+	}
+
+	stepLines = append(stepLines,
+		"        with:",
+		"          script: |",
+		"            const { setupGlobals } = require('"+SetupActionDestination+"/setup_globals.cjs');",
+		"            setupGlobals(core, github, context, exec, io);",
+		"            const { main } = require('"+SetupActionDestination+"/replay_agent_session.cjs');",
+		"            await main();",
+	)
+
+	return []GitHubActionStep{GitHubActionStep(stepLines)}
+}
+
+// RenderMCPConfig renders the MCP configuration using the custom engine renderer.
+// Replayed sessions never contact MCP servers, but the configuration is kept so the
+// agent job layout matches a real run.
+func (e *ReplayEngine) RenderMCPConfig(yaml *strings.Builder, tools map[string]any, mcpTools []string, workflowData *WorkflowData) {
+	NewCustomEngine().RenderMCPConfig(yaml, tools, mcpTools, workflowData)
+}
+
+// ParseLogMetrics parses replayed session logs, which use the Claude stream-json format
+func (e *ReplayEngine) ParseLogMetrics(logContent string, verbose bool) LogMetrics {
+	replayEngineLog.Printf("Parsing replay log metrics: log_size=%d bytes", len(logContent))
+	return NewClaudeEngine().ParseLogMetrics(logContent, verbose)
+}
+
+// GetLogParserScriptId returns the JavaScript script name for parsing replayed session logs
+func (e *ReplayEngine) GetLogParserScriptId() string {
+	return "parse_claude_log"
+}
@@ -0,0 +1,153 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayEngineRegistered(t *testing.T) {
+	engine, err := NewEngineRegistry().GetEngine("replay")
+	require.NoError(t, err, "replay engine should be registered")
+	assert.Equal(t, "Replay", engine.GetDisplayName())
+	assert.True(t, engine.IsExperimental(), "replay engine should be experimental")
+	assert.Empty(t, engine.GetRequiredSecretNames(&WorkflowData{}), "replay engine should not need secrets")
+	assert.Equal(t, "parse_claude_log", engine.GetLogParserScriptId(), "replayed logs use the Claude log format")
+}
+
+func TestReplayEngineExecutionSteps(t *testing.T) {
+	engine := NewReplayEngine()
+	workflowData := &WorkflowData{
+		Name:         "triage",
+		EngineConfig: &EngineConfig{ID: "replay", Fixture: ".github/workflows/fixtures/triage.replay.json"},
+		SafeOutputs:  &SafeOutputsConfig{Staged: true},
+	}
+
+	steps := engine.GetExecutionSteps(workflowData, "/tmp/gh-aw/agent-stdio.log")
+	require.Len(t, steps, 1, "replay should use a single execution step")
+
+	stepContent := strings.Join(steps[0], "\n")
+	assert.Contains(t, stepContent, "id: agentic_execution")
+	assert.Contains(t, stepContent, `GH_AW_REPLAY_FIXTURE: "${{ github.workspace }}/.github/workflows/fixtures/triage.replay.json"`)
+	assert.Contains(t, stepContent, "GH_AW_AGENT_LOG: /tmp/gh-aw/agent-stdio.log")
+	assert.Contains(t, stepContent, "GH_AW_SAFE_OUTPUTS: ${{ env.GH_AW_SAFE_OUTPUTS }}")
+	assert.Contains(t, stepContent, `GH_AW_SAFE_OUTPUTS_STAGED: true`)
+	assert.Contains(t, stepContent, "replay_agent_session.cjs")
+}
+
+func TestReplayEngineParseLogMetrics(t *testing.T) {
+	fixture := &ReplayFixture{
+		Version: ReplayFixtureVersion,
+		ToolCalls: []ReplayToolCall{
+			{Name: "Read", Input: map[string]any{"file_path": "README.md"}, Output: "# Project"},
+			{Server: "github", Name: "get_issue", Output: map[string]any{"title": "Bug"}},
+			{Name: "Read", Input: map[string]any{"file_path": "go.mod"}},
+		},
+		Usage: &ReplayUsage{InputTokens: 1000, OutputTokens: 250, Turns: 4, CostUSD: 0.05},
+	}
+
+	sessionLog, err := fixture.RenderSessionLog()
+	require.NoError(t, err, "session log should render")
+
+	metrics := NewReplayEngine().ParseLogMetrics(sessionLog, false)
+	assert.Equal(t, 1250, metrics.TokenUsage)
+	assert.Equal(t, 4, metrics.Turns)
+	assert.InDelta(t, 0.05, metrics.EstimatedCost, 0.0001)
+
+	callCounts := make(map[string]int)
+	for _, call := range metrics.ToolCalls {
+		callCounts[call.Name] = call.CallCount
+	}
+	assert.Equal(t, 2, callCounts["Read"], "tool calls should be counted like a real run")
+	assert.Equal(t, 1, callCounts["github_get_issue"], "MCP calls should be parsed with the Claude naming convention")
+}
+
+func TestValidateReplayFixture(t *testing.T) {
+	tmpDir := t.TempDir()
+	fixturesDir := filepath.Join(tmpDir, "fixtures")
+	require.NoError(t, os.MkdirAll(fixturesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "valid.json"), []byte(`{"version":1,"safe_outputs":[{"type":"noop","message":"done"}]}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "invalid.json"), []byte(`{"version":1,"safe_outputs":[{"message":"no type"}]}`), 0644))
+
+	markdownPath := filepath.Join(tmpDir, ".github", "workflows", "test.md")
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+
+	tests := []struct {
+		name         string
+		engineConfig *EngineConfig
+		errMsg       string
+	}{
+		{name: "non-replay engine without fixture", engineConfig: &EngineConfig{ID: "copilot"}},
+		{name: "valid fixture", engineConfig: &EngineConfig{ID: "replay", Fixture: "fixtures/valid.json"}},
+		{name: "missing fixture field", engineConfig: &EngineConfig{ID: "replay"}, errMsg: "requires engine.fixture"},
+		{name: "fixture on other engine", engineConfig: &EngineConfig{ID: "claude", Fixture: "fixtures/valid.json"}, errMsg: "only supported by the replay engine"},
+		{name: "absolute path", engineConfig: &EngineConfig{ID: "replay", Fixture: "/etc/passwd"}, errMsg: "relative to the repository root"},
+		{name: "path escapes repository", engineConfig: &EngineConfig{ID: "replay", Fixture: "../outside.json"}, errMsg: "relative to the repository root"},
+		{name: "fixture does not exist", engineConfig: &EngineConfig{ID: "replay", Fixture: "fixtures/missing.json"}, errMsg: "does not exist"},
+		{name: "malformed fixture", engineConfig: &EngineConfig{ID: "replay", Fixture: "fixtures/invalid.json"}, errMsg: "safe_outputs[0] is missing 'type'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := compiler.validateReplayFixture(&WorkflowData{EngineConfig: tt.engineConfig}, markdownPath)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestExtractEngineConfigFixture(t *testing.T) {
+	compiler := NewCompiler()
+	_, config := compiler.ExtractEngineConfig(map[string]any{
+		"engine": map[string]any{"id": "replay", "fixture": "fixtures/session.json"},
+	})
+	require.NotNil(t, config)
+	assert.Equal(t, "replay", config.ID)
+	assert.Equal(t, "fixtures/session.json", config.Fixture)
+}
+
+func TestReplayEngineCompileWorkflow(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(filepath.Join(workflowsDir, "fixtures"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workflowsDir, "fixtures", "triage.json"), []byte(`{"version":1,"safe_outputs":[{"type":"add_comment","body":"hi"}]}`), 0644))
+
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened]
+permissions:
+  contents: read
+engine:
+  id: replay
+  fixture: .github/workflows/fixtures/triage.json
+safe-outputs:
+  add-comment:
+---
+
+# Triage
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	require.NoError(t, compiler.CompileWorkflow(workflowPath), "replay workflow should compile")
+
+	lockContent, err := os.ReadFile(filepath.Join(workflowsDir, "triage.lock.yml"))
+	require.NoError(t, err)
+	lock := string(lockContent)
+
+	assert.Equal(t, 1, strings.Count(lock, "- name: Replay recorded agent session"), "only the agent job should replay the fixture")
+	assert.Contains(t, lock, "AI engine not available for threat detection (engine: replay)")
+	assert.Contains(t, lock, `engine_id: "replay"`)
+	assert.Contains(t, lock, "parse_claude_log.cjs")
+}
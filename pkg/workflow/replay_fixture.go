@@ -0,0 +1,264 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var replayFixtureLog = logger.New("workflow:replay_fixture")
+
+// ReplayFixtureVersion is the fixture format version understood by the replay engine
+const ReplayFixtureVersion = 1
+
+// ReplayFixture is a recorded agent session replayed by the replay engine.
+//
+// A fixture holds the ordered tool calls made by the agent (MCP tool calls carry the
+// recorded server response as their output), the final safe outputs the agent wrote
+// to safe_output.jsonl, and optional usage totals reported in the result entry.
+//
+// Example:
+//
+//	{
+//	  "version": 1,
+//	  "model": "claude-sonnet-4",
+//	  "tool_calls": [
+//	    {"name": "Bash", "input": {"command": "ls"}, "output": "README.md"},
+//	    {"server": "github", "name": "get_issue", "input": {"issue_number": 1}, "output": {"title": "Bug"}}
+//	  ],
+//	  "safe_outputs": [
+//	    {"type": "add_comment", "body": "Triaged"}
+//	  ],
+//	  "usage": {"input_tokens": 1200, "output_tokens": 300, "turns": 3, "cost_usd": 0.02}
+//	}
+type ReplayFixture struct {
+	Version     int              `json:"version"`
+	Model       string           `json:"model,omitempty"`
+	ToolCalls   []ReplayToolCall `json:"tool_calls,omitempty"`
+	SafeOutputs []map[string]any `json:"safe_outputs"`
+	Usage       *ReplayUsage     `json:"usage,omitempty"`
+}
+
+// ReplayToolCall is a single recorded tool call and its response
+type ReplayToolCall struct {
+	Name    string         `json:"name"`
+	Server  string         `json:"server,omitempty"` // MCP server name; empty for built-in tools
+	Input   map[string]any `json:"input,omitempty"`
+	Output  any            `json:"output,omitempty"` // Tool result or recorded MCP response
+	IsError bool           `json:"is_error,omitempty"`
+}
+
+// ReplayUsage holds the usage totals reported at the end of a replayed session
+type ReplayUsage struct {
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+	Turns        int     `json:"turns,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+	DurationMs   int     `json:"duration_ms,omitempty"`
+}
+
+// ResolveReplayFixturePath resolves an engine.fixture path against the repository root.
+// The fixture must be relative to the repository root and may not escape it. When repoRoot
+// is empty, the root is derived from the workflow location in .github/workflows.
+func ResolveReplayFixturePath(repoRoot, markdownPath, fixture string) (string, error) {
+	cleanFixture := filepath.Clean(fixture)
+	if filepath.IsAbs(fixture) || cleanFixture == ".." || strings.HasPrefix(cleanFixture, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("engine.fixture '%s' must be a path relative to the repository root", fixture)
+	}
+	if repoRoot == "" {
+		repoRoot = filepath.Join(filepath.Dir(markdownPath), "..", "..")
+	}
+	return filepath.Join(repoRoot, fixture), nil
+}
+
+// LoadReplayFixture reads and validates a replay fixture file
+func LoadReplayFixture(path string) (*ReplayFixture, error) {
+	replayFixtureLog.Printf("Loading replay fixture: %s", path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay fixture: %w", err)
+	}
+
+	return ParseReplayFixture(content)
+}
+
+// ParseReplayFixture parses and validates replay fixture content
+func ParseReplayFixture(content []byte) (*ReplayFixture, error) {
+	var fixture ReplayFixture
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid replay fixture JSON: %w", err)
+	}
+
+	if err := fixture.Validate(); err != nil {
+		return nil, err
+	}
+
+	replayFixtureLog.Printf("Parsed replay fixture: tool_calls=%d, safe_outputs=%d", len(fixture.ToolCalls), len(fixture.SafeOutputs))
+	return &fixture, nil
+}
+
+// Validate checks that the fixture uses a supported version and is well-formed
+func (f *ReplayFixture) Validate() error {
+	if f.Version != ReplayFixtureVersion {
+		return fmt.Errorf("unsupported replay fixture version %d (expected %d)", f.Version, ReplayFixtureVersion)
+	}
+
+	for i, call := range f.ToolCalls {
+		if strings.TrimSpace(call.Name) == "" {
+			return fmt.Errorf("replay fixture tool_calls[%d] is missing 'name'", i)
+		}
+	}
+
+	for i, item := range f.SafeOutputs {
+		itemType, _ := item["type"].(string)
+		if itemType == "" {
+			return fmt.Errorf("replay fixture safe_outputs[%d] is missing 'type'", i)
+		}
+	}
+
+	return nil
+}
+
+// ToolName returns the tool name as it appears in the session log.
+// MCP tool calls use the mcp__<server>__<tool> naming convention.
+func (c ReplayToolCall) ToolName() string {
+	if c.Server == "" {
+		return c.Name
+	}
+	return fmt.Sprintf("mcp__%s__%s", c.Server, c.Name)
+}
+
+// RenderSessionLog renders the fixture as a stream-json session log (one JSON entry per line)
+// in the same format produced by the Claude engine, so the existing log parsers can
+// extract tool calls, turns and token usage from replayed runs.
+//
+// This must stay in sync with actions/setup/js/replay_agent_session.cjs.
+func (f *ReplayFixture) RenderSessionLog() (string, error) {
+	var entries []map[string]any
+
+	entries = append(entries, map[string]any{
+		"type":    "system",
+		"subtype": "init",
+		"model":   f.Model,
+		"tools":   f.toolNames(),
+	})
+
+	for i, call := range f.ToolCalls {
+		toolUseID := fmt.Sprintf("replay_%d", i+1)
+		input := call.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+
+		entries = append(entries, map[string]any{
+			"type": "assistant",
+			"message": map[string]any{
+				"content": []any{
+					map[string]any{"type": "tool_use", "id": toolUseID, "name": call.ToolName(), "input": input},
+				},
+			},
+		})
+
+		output, err := replayToolOutputText(call.Output)
+		if err != nil {
+			return "", fmt.Errorf("failed to render output of tool call %d (%s): %w", i+1, call.ToolName(), err)
+		}
+		entries = append(entries, map[string]any{
+			"type": "user",
+			"message": map[string]any{
+				"content": []any{
+					map[string]any{"type": "tool_result", "tool_use_id": toolUseID, "content": output, "is_error": call.IsError},
+				},
+			},
+		})
+	}
+
+	entries = append(entries, f.resultEntry())
+
+	var sb strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return "", fmt.Errorf("failed to render replay log entry: %w", err)
+		}
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// RenderSafeOutputsJSONL renders the fixture's safe outputs in safe_output.jsonl format
+func (f *ReplayFixture) RenderSafeOutputsJSONL() (string, error) {
+	var sb strings.Builder
+	for i, item := range f.SafeOutputs {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return "", fmt.Errorf("failed to render safe_outputs[%d]: %w", i, err)
+		}
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// toolNames returns the unique tool names used by the fixture in first-use order
+func (f *ReplayFixture) toolNames() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, call := range f.ToolCalls {
+		name := call.ToolName()
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// resultEntry builds the final result entry carrying the recorded usage totals
+func (f *ReplayFixture) resultEntry() map[string]any {
+	usage := ReplayUsage{}
+	if f.Usage != nil {
+		usage = *f.Usage
+	}
+
+	turns := usage.Turns
+	if turns == 0 {
+		// One turn per tool call plus the final answer when no total was recorded
+		turns = len(f.ToolCalls) + 1
+	}
+
+	return map[string]any{
+		"type":           "result",
+		"subtype":        "success",
+		"is_error":       false,
+		"num_turns":      turns,
+		"duration_ms":    usage.DurationMs,
+		"total_cost_usd": usage.CostUSD,
+		"usage": map[string]any{
+			"input_tokens":  usage.InputTokens,
+			"output_tokens": usage.OutputTokens,
+		},
+	}
+}
+
+// replayToolOutputText converts a recorded tool output into the text form used in tool_result entries
+func replayToolOutputText(output any) (string, error) {
+	switch v := output.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
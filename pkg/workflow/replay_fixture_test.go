@@ -0,0 +1,116 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReplayFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "valid", content: `{"version":1,"tool_calls":[{"name":"Bash"}],"safe_outputs":[{"type":"noop"}]}`},
+		{name: "invalid JSON", content: `{"version":`, errMsg: "invalid replay fixture JSON"},
+		{name: "unsupported version", content: `{"version":2,"safe_outputs":[]}`, errMsg: "unsupported replay fixture version 2"},
+		{name: "tool call without name", content: `{"version":1,"tool_calls":[{"input":{}}],"safe_outputs":[]}`, errMsg: "tool_calls[0] is missing 'name'"},
+		{name: "safe output without type", content: `{"version":1,"safe_outputs":[{"body":"x"}]}`, errMsg: "safe_outputs[0] is missing 'type'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture, err := ParseReplayFixture([]byte(tt.content))
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				assert.NotNil(t, fixture)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestResolveReplayFixturePath(t *testing.T) {
+	markdownPath := filepath.Join("/work", ".github", "workflows", "triage.md")
+
+	path, err := ResolveReplayFixturePath("/repo", markdownPath, "fixtures/session.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/repo", "fixtures", "session.json"), path, "fixtures should resolve against the repository root")
+
+	path, err = ResolveReplayFixturePath("", markdownPath, "fixtures/session.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/work", "fixtures", "session.json"), path, "without a repository root the workflow location is used")
+
+	path, err = ResolveReplayFixturePath("/repo", markdownPath, "..fixtures/session.json")
+	require.NoError(t, err, "names starting with '..' stay inside the repository")
+	assert.Equal(t, filepath.Join("/repo", "..fixtures", "session.json"), path)
+
+	for _, fixture := range []string{"/etc/passwd", "..", "../session.json", "fixtures/../../session.json"} {
+		_, err := ResolveReplayFixturePath("/repo", markdownPath, fixture)
+		require.Error(t, err, "fixture %q should be rejected", fixture)
+		assert.Contains(t, err.Error(), "must be a path relative to the repository root")
+	}
+}
+
+func TestReplayFixtureRenderSessionLog(t *testing.T) {
+	fixture := &ReplayFixture{
+		Version: ReplayFixtureVersion,
+		Model:   "claude-sonnet-4",
+		ToolCalls: []ReplayToolCall{
+			{Server: "github", Name: "get_issue", Input: map[string]any{"issue_number": 1}, Output: map[string]any{"title": "Bug"}},
+			{Name: "Bash", Output: "failed", IsError: true},
+		},
+	}
+
+	sessionLog, err := fixture.RenderSessionLog()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(sessionLog), "\n")
+	require.Len(t, lines, 6, "init, two tool_use/tool_result pairs and result")
+
+	var entries []map[string]any
+	for _, line := range lines {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), "each line should be JSON")
+		entries = append(entries, entry)
+	}
+
+	assert.Equal(t, "system", entries[0]["type"])
+	assert.Equal(t, []any{"mcp__github__get_issue", "Bash"}, entries[0]["tools"])
+
+	toolUse := entries[1]["message"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	assert.Equal(t, "mcp__github__get_issue", toolUse["name"])
+	assert.Equal(t, "replay_1", toolUse["id"])
+
+	toolResult := entries[2]["message"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	assert.JSONEq(t, `{"title":"Bug"}`, toolResult["content"].(string), "structured MCP responses should be serialized")
+
+	failedResult := entries[4]["message"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	assert.Equal(t, true, failedResult["is_error"])
+
+	assert.Equal(t, "result", entries[5]["type"])
+	assert.InDelta(t, 3, entries[5]["num_turns"], 0, "turns default to tool calls plus the final answer")
+}
+
+func TestReplayFixtureRenderSafeOutputsJSONL(t *testing.T) {
+	fixture := &ReplayFixture{
+		Version: ReplayFixtureVersion,
+		SafeOutputs: []map[string]any{
+			{"type": "create_issue", "title": "A", "body": "B"},
+			{"type": "noop", "message": "done"},
+		},
+	}
+
+	jsonl, err := fixture.RenderSafeOutputsJSONL()
+	require.NoError(t, err)
+	assert.Equal(t, "{\"body\":\"B\",\"title\":\"A\",\"type\":\"create_issue\"}\n{\"message\":\"done\",\"type\":\"noop\"}\n", jsonl)
+}
@@ -22,4 +22,9 @@ func (c *Compiler) parseBaseSafeOutputConfig(configMap map[string]any, config *B
 			config.GitHubToken = githubTokenStr
 		}
 	}
+
+	// Parse require-approval
+	if requireApproval, exists := configMap["require-approval"]; exists {
+		config.RequireApproval = parseRequireApprovalConfig(requireApproval)
+	}
 }
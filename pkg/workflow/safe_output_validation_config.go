@@ -153,6 +153,26 @@ var ValidationConfig = map[string]TypeValidationConfig{
 			"side":       {Type: "string", Enum: []string{"LEFT", "RIGHT"}},
 		},
 	},
+	"create_check_run": {
+		DefaultMax: 1,
+		Fields: map[string]FieldValidation{
+			"conclusion":          {Required: true, Type: "string", Enum: validCheckRunConclusions},
+			"title":               {Type: "string", Sanitize: true, MaxLength: 256},
+			"summary":             {Required: true, Type: "string", Sanitize: true, MaxLength: MaxBodyLength},
+			"text":                {Type: "string", Sanitize: true, MaxLength: MaxBodyLength},
+			"annotations":         {Type: "array"},
+			"pull_request_number": {IssueOrPRNumber: true},
+		},
+	},
+	"notify_webhook": {
+		DefaultMax: 1,
+		Fields: map[string]FieldValidation{
+			"title":    {Required: true, Type: "string", Sanitize: true, MaxLength: 256},
+			"body":     {Required: true, Type: "string", Sanitize: true, MaxLength: 10000},
+			"severity": {Type: "string", Enum: webhookSeverities},
+			"endpoint": {Type: "string", MaxLength: 64},
+		},
+	},
 	"submit_pull_request_review": {
 		DefaultMax: 1,
 		Fields: map[string]FieldValidation{
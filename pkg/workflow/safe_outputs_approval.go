@@ -0,0 +1,324 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var safeOutputsApprovalLog = logger.New("workflow:safe_outputs_approval")
+
+// approvalGatesJobName is the job that decides which gated safe outputs wait for approval
+const approvalGatesJobName = "approval_gates"
+
+// approvalWhenPattern matches a require-approval condition such as "files-changed > 20"
+var approvalWhenPattern = regexp.MustCompile(`^\s*(items|files-changed|lines-changed)\s*(>=|<=|==|!=|>|<)\s*(\d+)\s*$`)
+
+// approvalPatchMetricTypes are the safe output types whose patch can be measured by files-changed and lines-changed
+var approvalPatchMetricTypes = []string{"create_pull_request", "push_to_pull_request_branch"}
+
+// approvalUngatedTypes are handler-managed types that only report information and cannot be gated
+var approvalUngatedTypes = []string{"missing_tool", "missing_data", "noop"}
+
+// approvalExtraFieldMapping lists safe output fields processed by the safe_outputs job that
+// safeOutputFieldMapping does not cover
+var approvalExtraFieldMapping = map[string]string{
+	"AutofixCodeScanningAlert": "autofix_code_scanning_alert",
+	"MissingData":              "missing_data",
+}
+
+// approvalFieldMapping returns every safe output field that can carry a require-approval configuration
+func approvalFieldMapping() map[string]string {
+	mapping := make(map[string]string, len(safeOutputFieldMapping)+len(approvalExtraFieldMapping))
+	maps.Copy(mapping, safeOutputFieldMapping)
+	maps.Copy(mapping, approvalExtraFieldMapping)
+	return mapping
+}
+
+// RequireApprovalConfig gates a safe output behind a GitHub environment approval
+type RequireApprovalConfig struct {
+	Environment string `yaml:"environment"`    // Environment whose protection rules must approve the output
+	When        string `yaml:"when,omitempty"` // Optional condition (e.g., "files-changed > 20"); approval is always required when empty
+}
+
+// UnmarshalYAML accepts either an environment name or an object with environment and when
+func (r *RequireApprovalConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	var environment string
+	if err := unmarshal(&environment); err == nil {
+		r.Environment = environment
+		return nil
+	}
+	type plain RequireApprovalConfig
+	return unmarshal((*plain)(r))
+}
+
+// parseRequireApprovalConfig parses the require-approval field of a safe output configuration
+func parseRequireApprovalConfig(value any) *RequireApprovalConfig {
+	switch v := value.(type) {
+	case string:
+		// Shorthand: require-approval: prod-bot
+		return &RequireApprovalConfig{Environment: v}
+	case map[string]any:
+		config := &RequireApprovalConfig{}
+		if environment, ok := v["environment"].(string); ok {
+			config.Environment = environment
+		}
+		if when, ok := v["when"].(string); ok {
+			config.When = when
+		}
+		return config
+	}
+	return nil
+}
+
+// approvalGate is a safe output type that waits for environment approval
+type approvalGate struct {
+	ToolName  string // Safe output type (e.g., "create_pull_request")
+	FieldName string // SafeOutputsConfig field holding the type configuration
+	Config    *RequireApprovalConfig
+}
+
+// key returns the frontmatter key of the gated safe output
+func (g approvalGate) key() string {
+	return strings.ReplaceAll(g.ToolName, "_", "-")
+}
+
+// jobName returns the name of the job that processes the gated safe output after approval
+func (g approvalGate) jobName() string {
+	return "safe_outputs_" + g.ToolName
+}
+
+// requiresApprovalOutput returns the approval_gates job output that tells whether the gated output waits for approval
+func (g approvalGate) requiresApprovalOutput() string {
+	return g.ToolName + "_requires_approval"
+}
+
+// collectApprovalGates returns the safe output types configured with require-approval, sorted by type
+func collectApprovalGates(safeOutputs *SafeOutputsConfig) []approvalGate {
+	if safeOutputs == nil {
+		return nil
+	}
+
+	var gates []approvalGate
+	val := reflect.ValueOf(safeOutputs).Elem()
+	for fieldName, toolName := range approvalFieldMapping() {
+		field := val.FieldByName(fieldName)
+		if !field.IsValid() || field.IsNil() {
+			continue
+		}
+		base := field.Elem().FieldByName("BaseSafeOutputConfig")
+		if !base.IsValid() {
+			continue
+		}
+		if config, ok := base.FieldByName("RequireApproval").Interface().(*RequireApprovalConfig); ok && config != nil {
+			gates = append(gates, approvalGate{ToolName: toolName, FieldName: fieldName, Config: config})
+		}
+	}
+
+	sort.Slice(gates, func(i, j int) bool { return gates[i].ToolName < gates[j].ToolName })
+	safeOutputsApprovalLog.Printf("Found %d approval-gated safe outputs", len(gates))
+	return gates
+}
+
+// validateSafeOutputsApproval checks the require-approval configuration of every safe output
+func validateSafeOutputsApproval(config *SafeOutputsConfig) error {
+	for _, gate := range collectApprovalGates(config) {
+		if _, ok := handlerRegistry[gate.ToolName]; !ok || slices.Contains(approvalUngatedTypes, gate.ToolName) {
+			return fmt.Errorf("safe-outputs.%s.require-approval is not supported: only safe outputs processed by the safe_outputs job can require approval", gate.key())
+		}
+		if strings.TrimSpace(gate.Config.Environment) == "" {
+			return fmt.Errorf("safe-outputs.%s.require-approval requires an environment\n\nExample:\nsafe-outputs:\n  %s:\n    require-approval:\n      environment: production", gate.key(), gate.key())
+		}
+		if gate.Config.When == "" {
+			continue
+		}
+		match := approvalWhenPattern.FindStringSubmatch(gate.Config.When)
+		if match == nil {
+			return fmt.Errorf("invalid safe-outputs.%s.require-approval.when %q: expected '<metric> <operator> <number>' where metric is items, files-changed or lines-changed (e.g., \"files-changed > 20\")", gate.key(), gate.Config.When)
+		}
+		if match[1] != "items" && !slices.Contains(approvalPatchMetricTypes, gate.ToolName) {
+			return fmt.Errorf("invalid safe-outputs.%s.require-approval.when %q: %s is only available for create-pull-request and push-to-pull-request-branch", gate.key(), gate.Config.When, match[1])
+		}
+	}
+	return nil
+}
+
+// isSafeOutputsStaged reports whether safe outputs only preview their changes
+func (c *Compiler) isSafeOutputsStaged(data *WorkflowData) bool {
+	return c.trialMode || (data.SafeOutputs != nil && data.SafeOutputs.Staged)
+}
+
+// copySafeOutputsWithout returns a shallow copy of the safe outputs configuration with the given fields disabled
+func copySafeOutputsWithout(safeOutputs *SafeOutputsConfig, fieldNames []string) *SafeOutputsConfig {
+	filtered := *safeOutputs
+	val := reflect.ValueOf(&filtered).Elem()
+	for _, fieldName := range fieldNames {
+		val.FieldByName(fieldName).SetZero()
+	}
+	return &filtered
+}
+
+// safeOutputsAwaitingApproval returns a copy of the safe outputs configuration for the safe_outputs job.
+// Types that always require approval are disabled; types with a when condition stay enabled and are
+// skipped at runtime when the approval_gates job routes them to their approval-gated job.
+func safeOutputsAwaitingApproval(safeOutputs *SafeOutputsConfig, gates []approvalGate) *SafeOutputsConfig {
+	var alwaysGated []string
+	for _, gate := range gates {
+		if gate.Config.When == "" {
+			alwaysGated = append(alwaysGated, gate.FieldName)
+		}
+	}
+	filtered := copySafeOutputsWithout(safeOutputs, alwaysGated)
+	filtered.awaitingApprovalFrom = approvalGatesJobName
+	return filtered
+}
+
+// safeOutputsForApprovalGate returns a copy of the safe outputs configuration that only enables the gated type
+func safeOutputsForApprovalGate(safeOutputs *SafeOutputsConfig, gate approvalGate) *SafeOutputsConfig {
+	var others []string
+	for fieldName := range approvalFieldMapping() {
+		if fieldName != gate.FieldName {
+			others = append(others, fieldName)
+		}
+	}
+	filtered := copySafeOutputsWithout(safeOutputs, others)
+	filtered.approvalScope = gate.ToolName
+	return filtered
+}
+
+// buildApprovalGatesJob creates the approval_gates job, which evaluates the require-approval conditions
+// against the agent output before any gated safe output runs
+func (c *Compiler) buildApprovalGatesJob(data *WorkflowData, gates []approvalGate, mainJobName string, threatDetectionEnabled bool) (*Job, error) {
+	safeOutputsApprovalLog.Printf("Building approval_gates job for %d gated safe outputs", len(gates))
+
+	var preSteps []string
+
+	// Add setup step to copy scripts
+	setupActionRef := c.resolveActionReference("./actions/setup", data)
+	if setupActionRef != "" || c.actionMode.IsScript() {
+		// For dev mode (local action path), checkout the actions folder first
+		preSteps = append(preSteps, c.generateCheckoutActionsFolder(data)...)
+
+		// Approval gates don't need project support
+		preSteps = append(preSteps, c.generateSetupStep(setupActionRef, SetupActionDestination, false)...)
+	}
+
+	// files-changed and lines-changed are measured on the patch produced by the agent
+	for _, gate := range gates {
+		if slices.Contains(approvalPatchMetricTypes, gate.ToolName) && gate.Config.When != "" && !strings.HasPrefix(strings.TrimSpace(gate.Config.When), "items") {
+			preSteps = append(preSteps, buildArtifactDownloadSteps(ArtifactDownloadConfig{
+				ArtifactName: "agent-artifacts",
+				DownloadPath: "/tmp/gh-aw/",
+				SetupEnvStep: false,
+				StepName:     "Download patch artifact",
+			})...)
+			break
+		}
+	}
+
+	gatesConfig := make([]map[string]string, 0, len(gates))
+	outputs := map[string]string{
+		"awaiting_approval": "${{ steps.approval_gates.outputs.awaiting_approval }}",
+	}
+	for _, gate := range gates {
+		gatesConfig = append(gatesConfig, map[string]string{
+			"type":        gate.ToolName,
+			"environment": gate.Config.Environment,
+			"when":        strings.TrimSpace(gate.Config.When),
+		})
+		outputs[gate.requiresApprovalOutput()] = fmt.Sprintf("${{ steps.approval_gates.outputs.%s }}", gate.requiresApprovalOutput())
+	}
+	gatesJSON, err := json.Marshal(gatesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal approval gates: %w", err)
+	}
+
+	var customEnvVars []string
+	customEnvVars = append(customEnvVars, fmt.Sprintf("          GH_AW_APPROVAL_GATES: %q\n", string(gatesJSON)))
+	customEnvVars = append(customEnvVars, c.buildStandardSafeOutputEnvVars(data, "")...)
+
+	// Build job dependencies
+	needs := []string{mainJobName}
+	if threatDetectionEnabled {
+		needs = append(needs, string(constants.DetectionJobName))
+	}
+
+	// Run whenever the safe outputs run, so that the gated types are never processed without a decision
+	condition := BuildAnd(
+		&NotNode{Child: BuildFunctionCall("cancelled")},
+		BuildNotEquals(
+			BuildPropertyAccess(fmt.Sprintf("needs.%s.result", constants.AgentJobName)),
+			BuildStringLiteral("skipped"),
+		),
+	)
+	if threatDetectionEnabled {
+		condition = BuildAnd(condition, buildDetectionSuccessCondition())
+	}
+
+	// The job only reads the agent output; checking out the local actions folder is the only
+	// reason to read repository contents
+	permissions := NewPermissionsEmpty()
+	if setupActionRef != "" && len(c.generateCheckoutActionsFolder(data)) > 0 {
+		permissions = NewPermissionsContentsRead()
+	}
+
+	return c.buildSafeOutputJob(data, SafeOutputJobConfig{
+		JobName:       approvalGatesJobName,
+		StepName:      "Evaluate Approval Gates",
+		StepID:        "approval_gates",
+		ScriptName:    "evaluate_approval_gates",
+		MainJobName:   mainJobName,
+		CustomEnvVars: customEnvVars,
+		Permissions:   permissions,
+		Outputs:       outputs,
+		Condition:     condition,
+		PreSteps:      preSteps,
+		Needs:         needs,
+	})
+}
+
+// buildApprovalGatedJob creates the job that processes a gated safe output once its environment is approved.
+// It reuses the consolidated safe_outputs job with every other safe output type disabled. When the
+// safe_outputs job exists (safeOutputsJobName is not empty), the gated job runs after it and resolves
+// the temporary IDs of the issues, discussions and pull requests it created.
+func (c *Compiler) buildApprovalGatedJob(data *WorkflowData, gate approvalGate, mainJobName, safeOutputsJobName, markdownPath string) (*Job, error) {
+	safeOutputsApprovalLog.Printf("Building approval-gated job for %s (environment=%s)", gate.ToolName, gate.Config.Environment)
+
+	gatedData := *data
+	gatedData.SafeOutputs = safeOutputsForApprovalGate(data.SafeOutputs, gate)
+	gatedData.SafeOutputs.temporaryIDMapFrom = safeOutputsJobName
+	// The issue is unlocked by the safe_outputs job
+	gatedData.LockForAgent = false
+
+	job, _, err := c.buildConsolidatedSafeOutputsJob(&gatedData, mainJobName, markdownPath)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("safe-outputs.%s.require-approval: no safe output steps were generated", gate.key())
+	}
+
+	job.Name = gate.jobName()
+	job.Environment = "environment: " + gate.Config.Environment
+	job.Needs = append(job.Needs, approvalGatesJobName)
+	if safeOutputsJobName != "" {
+		job.Needs = append(job.Needs, safeOutputsJobName)
+	}
+	job.If = BuildAnd(
+		&NotNode{Child: BuildFunctionCall("cancelled")},
+		BuildEquals(
+			BuildPropertyAccess(fmt.Sprintf("needs.%s.outputs.%s", approvalGatesJobName, gate.requiresApprovalOutput())),
+			BuildStringLiteral("true"),
+		),
+	).Render()
+
+	return job, nil
+}
@@ -0,0 +1,205 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequireApprovalConfig(t *testing.T) {
+	assert.Nil(t, parseRequireApprovalConfig(nil))
+	assert.Equal(t, &RequireApprovalConfig{Environment: "production"}, parseRequireApprovalConfig("production"))
+	assert.Equal(t, &RequireApprovalConfig{Environment: "production", When: "files-changed > 20"},
+		parseRequireApprovalConfig(map[string]any{"environment": "production", "when": "files-changed > 20"}))
+}
+
+func TestRequireApprovalParsedForEveryGateableType(t *testing.T) {
+	c := NewCompiler()
+	for toolName := range handlerRegistry {
+		if slices.Contains(approvalUngatedTypes, toolName) {
+			continue
+		}
+		key := strings.ReplaceAll(toolName, "_", "-")
+		t.Run(key, func(t *testing.T) {
+			config := c.extractSafeOutputsConfig(map[string]any{
+				"safe-outputs": map[string]any{
+					key: map[string]any{"require-approval": "production"},
+				},
+			})
+			require.NotNil(t, config)
+			gates := collectApprovalGates(config)
+			require.Len(t, gates, 1, "require-approval should be parsed for %s", key)
+			assert.Equal(t, toolName, gates[0].ToolName)
+			assert.Equal(t, "production", gates[0].Config.Environment)
+		})
+	}
+}
+
+func TestValidateSafeOutputsApproval(t *testing.T) {
+	require.NoError(t, validateSafeOutputsApproval(nil))
+	require.NoError(t, validateSafeOutputsApproval(&SafeOutputsConfig{
+		CreatePullRequests: &CreatePullRequestsConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{
+			RequireApproval: &RequireApprovalConfig{Environment: "production", When: "lines-changed >= 500"},
+		}},
+	}))
+
+	tests := []struct {
+		name        string
+		config      *SafeOutputsConfig
+		errContains string
+	}{
+		{
+			name: "missing environment",
+			config: &SafeOutputsConfig{CreateIssues: &CreateIssuesConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{
+				RequireApproval: &RequireApprovalConfig{When: "items > 1"},
+			}}},
+			errContains: "requires an environment",
+		},
+		{
+			name: "malformed condition",
+			config: &SafeOutputsConfig{CreateIssues: &CreateIssuesConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{
+				RequireApproval: &RequireApprovalConfig{Environment: "triage", When: "items is large"},
+			}}},
+			errContains: `require-approval.when "items is large"`,
+		},
+		{
+			name: "patch metric on issue",
+			config: &SafeOutputsConfig{CreateIssues: &CreateIssuesConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{
+				RequireApproval: &RequireApprovalConfig{Environment: "triage", When: "files-changed > 2"},
+			}}},
+			errContains: "files-changed is only available",
+		},
+		{
+			name: "ungated type",
+			config: &SafeOutputsConfig{MissingTool: &MissingToolConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{
+				RequireApproval: &RequireApprovalConfig{Environment: "triage"},
+			}}},
+			errContains: "safe-outputs.missing-tool.require-approval is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSafeOutputsApproval(tt.config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestApprovalGatedJobs(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "gated.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+safe-outputs:
+  add-comment:
+  create-issue:
+    require-approval: triage
+  create-pull-request:
+    require-approval:
+      environment: production
+      when: files-changed > 20
+---
+
+# Gated
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	gatesSection := extractJobSection(lockYAML, "approval_gates")
+	require.NotEmpty(t, gatesSection, "approval_gates job should be generated")
+	assert.NotContains(t, gatesSection, "write", "approval_gates should not get write permissions")
+	assert.Contains(t, gatesSection, "create_issue_requires_approval:")
+	assert.Contains(t, gatesSection, "create_pull_request_requires_approval:")
+	assert.Contains(t, gatesSection, "name: agent-artifacts", "patch should be downloaded for files-changed conditions")
+	assert.Contains(t, gatesSection, "require('/opt/gh-aw/actions/evaluate_approval_gates.cjs')")
+
+	mainSection := extractJobSection(lockYAML, "safe_outputs")
+	assert.Contains(t, mainSection, "needs.approval_gates.result == 'success'")
+	assert.Contains(t, mainSection, "GH_AW_SAFE_OUTPUTS_AWAITING_APPROVAL: ${{ needs.approval_gates.outputs.awaiting_approval }}")
+	assert.Contains(t, mainSection, `\"create_pull_request\":`, "conditionally gated outputs stay in the main job")
+	assert.NotContains(t, mainSection, `\"create_issue\":`, "always gated outputs are removed from the main job")
+
+	issueSection := extractJobSection(lockYAML, "safe_outputs_create_issue")
+	require.NotEmpty(t, issueSection, "gated create_issue job should be generated")
+	assert.Contains(t, issueSection, "environment: triage")
+	assert.Contains(t, issueSection, "needs.approval_gates.outputs.create_issue_requires_approval == 'true'")
+	assert.Contains(t, issueSection, `GH_AW_SAFE_OUTPUTS_APPROVAL_SCOPE: "create_issue"`)
+	assert.NotContains(t, issueSection, "pull-requests: write")
+
+	prSection := extractJobSection(lockYAML, "safe_outputs_create_pull_request")
+	require.NotEmpty(t, prSection, "gated create_pull_request job should be generated")
+	assert.Contains(t, prSection, "environment: production")
+
+	conclusionSection := extractJobSection(lockYAML, "conclusion")
+	assert.Contains(t, conclusionSection, "- safe_outputs_create_issue")
+	assert.Contains(t, conclusionSection, "- safe_outputs_create_pull_request")
+}
+
+func TestApprovalGatedJobNeedsSafeOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "gated.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+safe-outputs:
+  create-issue:
+  create-pull-request:
+    require-approval: production
+---
+
+# Gated
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	prSection := extractJobSection(lockYAML, "safe_outputs_create_pull_request")
+	require.NotEmpty(t, prSection, "gated create_pull_request job should be generated")
+	needs, _, _ := strings.Cut(prSection, "    if:")
+	assert.Contains(t, needs, "      - safe_outputs\n", "gated jobs should run after the safe_outputs job")
+	assert.Contains(t, prSection, "GH_AW_TEMPORARY_ID_MAP: ${{ needs.safe_outputs.outputs.process_safe_outputs_temporary_id_map }}",
+		"gated jobs should resolve temporary IDs of entities created by the safe_outputs job")
+}
+
+func TestApprovalGatesStaged(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "staged.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+safe-outputs:
+  staged: true
+  create-issue:
+    require-approval: triage
+---
+
+# Staged
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, extractJobSection(lockYAML, "approval_gates"), "approval_gates should preview decisions in staged mode")
+	assert.Empty(t, extractJobSection(lockYAML, "safe_outputs_create_issue"), "staged runs should not wait for approval")
+	assert.Contains(t, extractJobSection(lockYAML, "safe_outputs"), `\"create_issue\":`, "staged runs preview gated outputs in the main job")
+}
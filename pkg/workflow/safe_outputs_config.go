@@ -113,6 +113,12 @@ func (c *Compiler) extractSafeOutputsConfig(frontmatter map[string]any) *SafeOut
 				config.CreateCodeScanningAlerts = securityReportsConfig
 			}
 
+			// Handle create-check-run
+			createCheckRunConfig := c.parseCreateCheckRunConfig(outputMap)
+			if createCheckRunConfig != nil {
+				config.CreateCheckRuns = createCheckRunConfig
+			}
+
 			// Handle autofix-code-scanning-alert
 			autofixCodeScanningAlertConfig := c.parseAutofixCodeScanningAlertConfig(outputMap)
 			if autofixCodeScanningAlertConfig != nil {
@@ -236,6 +242,12 @@ func (c *Compiler) extractSafeOutputsConfig(frontmatter map[string]any) *SafeOut
 				config.HideComment = hideCommentConfig
 			}
 
+			// Handle notify-webhook
+			notifyWebhookConfig := c.parseNotifyWebhookConfig(outputMap)
+			if notifyWebhookConfig != nil {
+				config.NotifyWebhook = notifyWebhookConfig
+			}
+
 			// Handle dispatch-workflow
 			dispatchWorkflowConfig := c.parseDispatchWorkflowConfig(outputMap)
 			if dispatchWorkflowConfig != nil {
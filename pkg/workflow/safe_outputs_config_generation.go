@@ -167,6 +167,18 @@ func generateSafeOutputsConfig(data *WorkflowData) string {
 				0, // default: unlimited
 			)
 		}
+		if data.SafeOutputs.CreateCheckRuns != nil {
+			safeOutputsConfig["create_check_run"] = generateMaxConfig(
+				data.SafeOutputs.CreateCheckRuns.Max,
+				1, // default max
+			)
+		}
+		if data.SafeOutputs.NotifyWebhook != nil {
+			safeOutputsConfig["notify_webhook"] = generateMaxConfig(
+				data.SafeOutputs.NotifyWebhook.Max,
+				1, // default max
+			)
+		}
 		if data.SafeOutputs.AutofixCodeScanningAlert != nil {
 			safeOutputsConfig["autofix_code_scanning_alert"] = generateMaxConfig(
 				data.SafeOutputs.AutofixCodeScanningAlert.Max,
@@ -635,6 +647,12 @@ func generateFilteredToolsJSON(data *WorkflowData, markdownPath string) (string,
 	if data.SafeOutputs.CreateCodeScanningAlerts != nil {
 		enabledTools["create_code_scanning_alert"] = true
 	}
+	if data.SafeOutputs.CreateCheckRuns != nil {
+		enabledTools["create_check_run"] = true
+	}
+	if data.SafeOutputs.NotifyWebhook != nil {
+		enabledTools["notify_webhook"] = true
+	}
 	if data.SafeOutputs.AutofixCodeScanningAlert != nil {
 		enabledTools["autofix_code_scanning_alert"] = true
 	}
@@ -43,3 +43,10 @@ func GetEnabledSafeOutputToolNames(safeOutputs *SafeOutputsConfig) []string {
 
 	return tools
 }
+
+// GetSafeOutputMax returns the configured max for an enabled safe output tool
+// (e.g. "create_issue"). A max of 0 means the tool has no limit. The boolean result
+// is false when the tool is not enabled or does not support a max.
+func GetSafeOutputMax(safeOutputs *SafeOutputsConfig, toolName string) (int, bool) {
+	return getSafeOutputMaxReflection(safeOutputs, toolName)
+}
@@ -23,6 +23,7 @@ var safeOutputFieldMapping = map[string]string{
 	"CreatePullRequestReviewComments": "create_pull_request_review_comment",
 	"SubmitPullRequestReview":         "submit_pull_request_review",
 	"CreateCodeScanningAlerts":        "create_code_scanning_alert",
+	"CreateCheckRuns":                 "create_check_run",
 	"AddLabels":                       "add_labels",
 	"RemoveLabels":                    "remove_labels",
 	"AddReviewer":                     "add_reviewer",
@@ -40,6 +41,7 @@ var safeOutputFieldMapping = map[string]string{
 	"LinkSubIssue":                    "link_sub_issue",
 	"HideComment":                     "hide_comment",
 	"DispatchWorkflow":                "dispatch_workflow",
+	"NotifyWebhook":                   "notify_webhook",
 	"MissingTool":                     "missing_tool",
 	"NoOp":                            "noop",
 	"MarkPullRequestAsReadyForReview": "mark_pull_request_as_ready_for_review",
@@ -103,3 +105,31 @@ func getEnabledSafeOutputToolNamesReflection(safeOutputs *SafeOutputsConfig) []s
 	safeOutputReflectionLog.Printf("Found %d enabled safe output tools", len(tools))
 	return tools
 }
+
+// getSafeOutputMaxReflection uses reflection to read the configured max for a safe output tool.
+// It returns false when the tool is not enabled or its config has no max field.
+func getSafeOutputMaxReflection(safeOutputs *SafeOutputsConfig, toolName string) (int, bool) {
+	if safeOutputs == nil {
+		return 0, false
+	}
+
+	val := reflect.ValueOf(safeOutputs).Elem()
+	for fieldName, mappedToolName := range safeOutputFieldMapping {
+		if mappedToolName != toolName {
+			continue
+		}
+		field := val.FieldByName(fieldName)
+		if !field.IsValid() || field.IsNil() {
+			return 0, false
+		}
+		// Max is promoted from the embedded BaseSafeOutputConfig
+		maxField := field.Elem().FieldByName("Max")
+		if !maxField.IsValid() || maxField.Kind() != reflect.Int {
+			return 0, false
+		}
+		safeOutputReflectionLog.Printf("Found max=%d for safe output tool %s", maxField.Int(), toolName)
+		return int(maxField.Int()), true
+	}
+
+	return 0, false
+}
@@ -76,6 +76,39 @@ func (c *Compiler) validateSafeOutputsAllowedDomains(config *SafeOutputsConfig)
 	return collector.Error()
 }
 
+// validateNotifyWebhookAllowedDomains validates the allowed-domains of notify-webhook. The list is
+// required because the webhook URLs come from secrets and cannot be checked at compile time.
+func (c *Compiler) validateNotifyWebhookAllowedDomains(config *SafeOutputsConfig) error {
+	if config == nil || config.NotifyWebhook == nil {
+		return nil
+	}
+
+	allowedDomains := config.NotifyWebhook.AllowedDomains
+	if len(allowedDomains) == 0 {
+		return NewValidationError(
+			"safe-outputs.notify-webhook.allowed-domains",
+			"",
+			"notify-webhook requires allowed-domains so webhook URLs can be checked before posting",
+			"List the domains of your webhook endpoints. Examples:\n  - Slack: 'hooks.slack.com'\n  - Teams: '*.webhook.office.com'",
+		)
+	}
+
+	safeOutputsDomainsValidationLog.Printf("Validating %d notify-webhook allowed domains", len(allowedDomains))
+
+	collector := NewErrorCollector(c.failFast)
+
+	for i, domain := range allowedDomains {
+		if err := validateDomainPattern(domain); err != nil {
+			wrappedErr := fmt.Errorf("safe-outputs.notify-webhook.allowed-domains[%d]: %w", i, err)
+			if returnErr := collector.Add(wrappedErr); returnErr != nil {
+				return returnErr // Fail-fast mode
+			}
+		}
+	}
+
+	return collector.Error()
+}
+
 // validateDomainPattern validates a single domain pattern
 func validateDomainPattern(domain string) error {
 	// Check for empty domain
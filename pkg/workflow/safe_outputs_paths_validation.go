@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var safeOutputsPathsValidationLog = logger.New("workflow:safe_outputs_paths_validation")
+
+// protectedPatchPaths are the paths that agent patches may only modify when allowed-paths lists
+// them exactly, without wildcards. GitHub refuses workflow file changes pushed with the GITHUB_TOKEN anyway, so
+// rejecting them before the push gives a clearer error.
+var protectedPatchPaths = []string{
+	".github/workflows/**",
+	"CODEOWNERS",
+	".github/CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// unsupportedGlobCharacters are glob and regular expression characters that the patch path
+// matcher treats literally, so patterns using them would silently never match
+const unsupportedGlobCharacters = "?[]{}()!+^$|\\"
+
+// validateSafeOutputsPatchPaths validates the allowed-paths and blocked-paths globs of the
+// safe outputs that apply agent patches
+func validateSafeOutputsPatchPaths(config *SafeOutputsConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	type pathsConfig struct {
+		name    string
+		allowed []string
+		blocked []string
+	}
+
+	var configs []pathsConfig
+	if config.CreatePullRequests != nil {
+		configs = append(configs, pathsConfig{"create-pull-request", config.CreatePullRequests.AllowedPaths, config.CreatePullRequests.BlockedPaths})
+	}
+	if config.PushToPullRequestBranch != nil {
+		configs = append(configs, pathsConfig{"push-to-pull-request-branch", config.PushToPullRequestBranch.AllowedPaths, config.PushToPullRequestBranch.BlockedPaths})
+	}
+
+	for _, cfg := range configs {
+		if err := validatePatchPathGlobs(cfg.name, "allowed-paths", cfg.allowed); err != nil {
+			return err
+		}
+		if err := validatePatchPathGlobs(cfg.name, "blocked-paths", cfg.blocked); err != nil {
+			return err
+		}
+		safeOutputsPathsValidationLog.Printf("Validated %d allowed and %d blocked paths for %s", len(cfg.allowed), len(cfg.blocked), cfg.name)
+	}
+	return nil
+}
+
+// validatePatchPathGlobs validates the globs of one path restriction field
+func validatePatchPathGlobs(configName, field string, patterns []string) error {
+	for i, pattern := range patterns {
+		if err := validatePatchPathGlob(pattern); err != nil {
+			return fmt.Errorf("invalid safe-outputs.%s.%s[%d] %q: %w\n\nPatterns are paths relative to the repository root, where * matches within a directory and ** matches across directories (e.g., \"docs/**\", \"src/*.go\")", configName, field, i, pattern, err)
+		}
+	}
+	return nil
+}
+
+// validatePatchPathGlob checks that a glob is a relative path that the patch path matcher supports
+func validatePatchPathGlob(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("pattern is empty")
+	}
+	if strings.TrimSpace(pattern) != pattern {
+		return errors.New("pattern has leading or trailing whitespace")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return errors.New("pattern must be relative to the repository root")
+	}
+	if strings.HasSuffix(pattern, "/") {
+		return fmt.Errorf("pattern matches a directory, not its files: use %q", pattern+"**")
+	}
+	if index := strings.IndexAny(pattern, unsupportedGlobCharacters); index >= 0 {
+		return fmt.Errorf("unsupported character %q: only * and ** wildcards are supported", pattern[index])
+	}
+
+	for segment := range strings.SplitSeq(pattern, "/") {
+		switch {
+		case segment == "":
+			return errors.New("pattern contains an empty path segment")
+		case segment == "." || segment == "..":
+			return fmt.Errorf("pattern contains a %q path segment", segment)
+		case strings.Contains(segment, "**") && segment != "**":
+			return errors.New("** must be a whole path segment (e.g., \"docs/**\")")
+		}
+	}
+	return nil
+}
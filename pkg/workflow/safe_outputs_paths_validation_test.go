@@ -0,0 +1,100 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		errText string
+	}{
+		{pattern: "docs/**"},
+		{pattern: "src/*.go"},
+		{pattern: "**/README.md"},
+		{pattern: "CODEOWNERS"},
+		{pattern: "", errText: "pattern is empty"},
+		{pattern: " docs/**", errText: "leading or trailing whitespace"},
+		{pattern: "/docs/**", errText: "relative to the repository root"},
+		{pattern: "docs/", errText: `use "docs/**"`},
+		{pattern: "docs/*.{md,mdx}", errText: `unsupported character '{'`},
+		{pattern: "src/[a-z]*.go", errText: `unsupported character '['`},
+		{pattern: "src/?.go", errText: `unsupported character '?'`},
+		{pattern: "docs//guide.md", errText: "empty path segment"},
+		{pattern: "../secrets", errText: `a ".." path segment`},
+		{pattern: "docs/**.md", errText: "** must be a whole path segment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := validatePatchPathGlob(tt.pattern)
+			if tt.errText == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
+
+func TestValidateSafeOutputsPatchPaths(t *testing.T) {
+	require.NoError(t, validateSafeOutputsPatchPaths(nil))
+	require.NoError(t, validateSafeOutputsPatchPaths(&SafeOutputsConfig{
+		CreatePullRequests: &CreatePullRequestsConfig{AllowedPaths: []string{"docs/**"}, BlockedPaths: []string{"docs/api/**"}},
+	}))
+
+	err := validateSafeOutputsPatchPaths(&SafeOutputsConfig{
+		PushToPullRequestBranch: &PushToPullRequestBranchConfig{AllowedPaths: []string{"src/**"}, BlockedPaths: []string{"src/**", "/etc"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid safe-outputs.push-to-pull-request-branch.blocked-paths[1] "/etc"`)
+}
+
+func TestPatchPathsHandlerConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowPath := filepath.Join(tmpDir, "docs-bot.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+permissions:
+  contents: read
+safe-outputs:
+  create-pull-request:
+    allowed-paths: ["docs/**"]
+    blocked-paths: ["docs/api/**"]
+  push-to-pull-request-branch:
+    blocked-paths: ["*.lock"]
+---
+
+# Docs bot
+`), 0644))
+
+	compiler := NewCompiler()
+	compiler.SetQuiet(true)
+	_, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+	assert.Contains(t, lockYAML, `\"allowed_paths\":[\"docs/**\"],`)
+	assert.Contains(t, lockYAML, `\"blocked_paths\":[\"docs/api/**\"],`)
+	assert.Contains(t, lockYAML, `\"blocked_paths\":[\"*.lock\"],`)
+	assert.Contains(t, lockYAML, `\"protected_paths\":[\".github/workflows/**\",\"CODEOWNERS\",\".github/CODEOWNERS\",\"docs/CODEOWNERS\"]`)
+
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on: workflow_dispatch
+safe-outputs:
+  create-pull-request:
+    allowed-paths: ["docs/*.{md,mdx}"]
+---
+
+# Docs bot
+`), 0644))
+	_, _, err = compiler.CompileWorkflowToYAML(workflowPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only * and ** wildcards are supported")
+}
@@ -0,0 +1,286 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// In staged mode, update-issue, update-pull-request, update-project and update-release fetch the
+// entity they would update, and actions/setup/js/staged_update_diff.cjs renders a before/after
+// diff in the step summary. The helpers below are an independent reference rendering used only by
+// these tests: the expected markdown in testdata/staged_update_diff is generated from them
+// (go test -run TestRenderStagedUpdateDiffFixtures -update-staged-diff-fixtures), and
+// staged_update_diff.test.cjs checks the JavaScript renderer against the same files. The
+// long_body_* fixtures sit on either side of maxStagedDiffLines, so the JavaScript test fails when
+// MAX_DIFF_LINES no longer matches it.
+
+// maxStagedDiffLines is the maximum number of lines per side diffed line by line; longer texts are
+// shown as a plain before/after block
+const maxStagedDiffLines = 1000
+
+// stagedEntityState is the part of an issue, pull request, project item or release that a staged
+// update preview compares
+type stagedEntityState struct {
+	Title  string            `json:"title,omitempty"`
+	Body   string            `json:"body,omitempty"`
+	Labels []string          `json:"labels,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"` // Other fields such as state, milestone or project field values
+}
+
+// stagedUpdatePreview describes one update that staged mode did not apply
+type stagedUpdatePreview struct {
+	Target string             `json:"target"`        // Heading for the entity (e.g., "Issue #42")
+	URL    string             `json:"url,omitempty"` // Link to the current entity
+	Before *stagedEntityState `json:"before"`        // Current state; nil when it could not be fetched
+	After  stagedEntityState  `json:"after"`         // State after the update would be applied
+}
+
+// renderStagedUpdateDiff renders the markdown before/after diff of a staged update: a table of the
+// changed title, labels and fields followed by a line diff of the body.
+func renderStagedUpdateDiff(preview stagedUpdatePreview) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", preview.Target)
+	if preview.URL != "" {
+		fmt.Fprintf(&sb, "**URL:** %s\n\n", preview.URL)
+	}
+
+	before := stagedEntityState{}
+	if preview.Before != nil {
+		before = *preview.Before
+	} else {
+		sb.WriteString("_The current state could not be fetched; all values are shown as additions._\n\n")
+	}
+	after := preview.After
+
+	var rows [][3]string
+	if before.Title != after.Title {
+		rows = append(rows, [3]string{"Title", before.Title, after.Title})
+	}
+	if strings.Join(before.Labels, ", ") != strings.Join(after.Labels, ", ") {
+		rows = append(rows, [3]string{"Labels", strings.Join(before.Labels, ", "), strings.Join(after.Labels, ", ")})
+	}
+	fieldNames := slices.Collect(maps.Keys(before.Fields))
+	for name := range after.Fields {
+		if _, exists := before.Fields[name]; !exists {
+			fieldNames = append(fieldNames, name)
+		}
+	}
+	slices.Sort(fieldNames)
+	for _, name := range fieldNames {
+		if before.Fields[name] != after.Fields[name] {
+			rows = append(rows, [3]string{name, before.Fields[name], after.Fields[name]})
+		}
+	}
+
+	bodyChanged := before.Body != after.Body
+	if len(rows) == 0 && !bodyChanged {
+		sb.WriteString("_No changes: the update matches the current state._\n\n")
+		return sb.String()
+	}
+
+	if len(rows) > 0 {
+		sb.WriteString("| Field | Before | After |\n| --- | --- | --- |\n")
+		for _, row := range rows {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", formatStagedDiffCell(row[0]), formatStagedDiffCell(row[1]), formatStagedDiffCell(row[2]))
+		}
+		sb.WriteString("\n")
+	}
+
+	if bodyChanged {
+		diff := strings.Join(diffStagedLines(before.Body, after.Body), "\n")
+		fence := strings.Repeat("`", max(3, longestBacktickRun(diff)+1))
+		fmt.Fprintf(&sb, "**Body:**\n\n%sdiff\n%s\n%s\n\n", fence, diff, fence)
+	}
+
+	return sb.String()
+}
+
+// formatStagedDiffCell formats a value for a markdown table cell
+func formatStagedDiffCell(value string) string {
+	if value == "" {
+		return "_(none)_"
+	}
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// splitStagedDiffLines splits text into lines; empty text has no lines
+func splitStagedDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffStagedLines returns a line diff of two texts using the longest common subsequence.
+// Lines are prefixed with "-" when removed, "+" when added and " " when unchanged. Texts longer
+// than maxStagedDiffLines are shown as all removed followed by all added.
+func diffStagedLines(before, after string) []string {
+	a := splitStagedDiffLines(before)
+	b := splitStagedDiffLines(after)
+	if len(a) > maxStagedDiffLines || len(b) > maxStagedDiffLines {
+		lines := make([]string, 0, len(a)+len(b))
+		for _, line := range a {
+			lines = append(lines, "-"+line)
+		}
+		for _, line := range b {
+			lines = append(lines, "+"+line)
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+"+b[j])
+	}
+	return lines
+}
+
+// longestBacktickRun returns the length of the longest run of backticks in text
+func longestBacktickRun(text string) int {
+	longest, current := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return longest
+}
+
+var updateStagedDiffFixtures = flag.Bool("update-staged-diff-fixtures", false, "regenerate the expected markdown of testdata/staged_update_diff")
+
+// TestRenderStagedUpdateDiffFixtures renders every fixture in testdata/staged_update_diff and compares
+// it with the expected markdown, or regenerates the markdown with -update-staged-diff-fixtures.
+// staged_update_diff.test.cjs checks the JavaScript renderer against the same files.
+func TestRenderStagedUpdateDiffFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "staged_update_diff", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures, "expected staged update diff fixtures")
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(fixture)
+			require.NoError(t, err)
+			var preview stagedUpdatePreview
+			require.NoError(t, json.Unmarshal(input, &preview))
+			rendered := renderStagedUpdateDiff(preview)
+
+			expectedPath := strings.TrimSuffix(fixture, ".json") + ".md"
+			if *updateStagedDiffFixtures {
+				require.NoError(t, os.WriteFile(expectedPath, []byte(rendered), 0644))
+				return
+			}
+			expected, err := os.ReadFile(expectedPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), rendered, "regenerate with -update-staged-diff-fixtures and check staged_update_diff.cjs")
+		})
+	}
+}
+
+func TestDiffStagedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{
+			name:     "both empty",
+			expected: []string{},
+		},
+		{
+			name:     "appended line",
+			before:   "a\nb",
+			after:    "a\nb\nc",
+			expected: []string{" a", " b", "+c"},
+		},
+		{
+			name:     "replaced line",
+			before:   "a\nb\nc",
+			after:    "a\nx\nc",
+			expected: []string{" a", "-b", "+x", " c"},
+		},
+		{
+			name:     "removed everything",
+			before:   "a\r\nb",
+			expected: []string{"-a", "-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diffStagedLines(tt.before, tt.after))
+		})
+	}
+}
+
+func TestDiffStagedLinesLongText(t *testing.T) {
+	var before []string
+	for i := 0; i <= maxStagedDiffLines; i++ {
+		before = append(before, fmt.Sprintf("line %d", i))
+	}
+
+	lines := diffStagedLines(strings.Join(before, "\n"), "line 0")
+	require.Len(t, lines, maxStagedDiffLines+2, "long texts are shown as a plain before/after block")
+	assert.Equal(t, "-line 0", lines[0])
+	assert.Equal(t, "+line 0", lines[len(lines)-1])
+}
+
+func TestRenderStagedUpdateDiffFence(t *testing.T) {
+	preview := stagedUpdatePreview{
+		Target: "Release v1",
+		Before: &stagedEntityState{Body: "old"},
+		After:  stagedEntityState{Body: "````\nnew\n````"},
+	}
+	assert.Contains(t, renderStagedUpdateDiff(preview), "`````diff\n", "fence should be longer than any backtick run in the body")
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+)
+
+var stagedMessagesLog = logger.New("workflow:safe_outputs_staged_messages")
+
+// ========================================
+// Staged Mode Message Templates
+// ========================================
+//
+// These helpers mirror actions/setup/js/messages_staged.cjs and messages_core.cjs so that
+// Go-side tooling (for example `gh aw run --local`) renders staged previews with the same
+// templates and placeholder semantics as the safe-output jobs on GitHub Actions.
+
+const (
+	// defaultStagedTitleTemplate matches the default title in messages_staged.cjs
+	defaultStagedTitleTemplate = "## 🔍 Preview: {operation}"
+	// defaultStagedDescriptionTemplate matches the default description in messages_staged.cjs
+	defaultStagedDescriptionTemplate = "📋 The following operations would be performed if staged mode was disabled:"
+)
+
+// messageTemplatePlaceholder matches {placeholder} tokens in message templates
+var messageTemplatePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// camelCaseBoundary matches the uppercase letters that start a new word in camelCase keys
+var camelCaseBoundary = regexp.MustCompile(`([A-Z])`)
+
+// RenderMessageTemplate replaces {key} placeholders in a safe-output message template.
+// Keys are matched in both their original and snake_case forms, and unknown placeholders
+// are left untouched, matching renderTemplate/toSnakeCase in messages_core.cjs.
+func RenderMessageTemplate(template string, context map[string]string) string {
+	lookup := make(map[string]string, len(context)*2)
+	for key, value := range context {
+		snakeKey := strings.ToLower(camelCaseBoundary.ReplaceAllString(key, "_$1"))
+		lookup[snakeKey] = value
+		lookup[key] = value
+	}
+
+	return messageTemplatePlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		key := match[1 : len(match)-1]
+		if value, ok := lookup[key]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// RenderStagedTitle renders the staged mode title for an operation (e.g. "Create Issue"),
+// using the custom staged-title template when one is configured.
+func RenderStagedTitle(messages *SafeOutputMessagesConfig, operation string) string {
+	template := defaultStagedTitleTemplate
+	if messages != nil && messages.StagedTitle != "" {
+		stagedMessagesLog.Print("Using custom staged-title template")
+		template = messages.StagedTitle
+	}
+	return RenderMessageTemplate(template, map[string]string{"operation": operation})
+}
+
+// RenderStagedDescription renders the staged mode description for an operation,
+// using the custom staged-description template when one is configured.
+func RenderStagedDescription(messages *SafeOutputMessagesConfig, operation string) string {
+	template := defaultStagedDescriptionTemplate
+	if messages != nil && messages.StagedDescription != "" {
+		stagedMessagesLog.Print("Using custom staged-description template")
+		template = messages.StagedDescription
+	}
+	return RenderMessageTemplate(template, map[string]string{"operation": operation})
+}
+
+// SafeOutputOperationName converts a safe output tool name (e.g. "create_issue" or
+// "create-issue") into a human readable operation name (e.g. "Create Issue").
+func SafeOutputOperationName(toolName string) string {
+	words := strings.FieldsFunc(toolName, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
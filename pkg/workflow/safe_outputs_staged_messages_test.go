@@ -0,0 +1,79 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		context  map[string]string
+		expected string
+	}{
+		{
+			name:     "simple placeholder",
+			template: "Preview: {operation}",
+			context:  map[string]string{"operation": "Create Issue"},
+			expected: "Preview: Create Issue",
+		},
+		{
+			name:     "snake_case alias for camelCase key",
+			template: "{workflow_name} and {workflowName}",
+			context:  map[string]string{"workflowName": "Triage"},
+			expected: "Triage and Triage",
+		},
+		{
+			name:     "unknown placeholders are preserved",
+			template: "{operation} in {run_url}",
+			context:  map[string]string{"operation": "Add Comment"},
+			expected: "Add Comment in {run_url}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenderMessageTemplate(tt.template, tt.context))
+		})
+	}
+}
+
+func TestRenderStagedTitleAndDescription(t *testing.T) {
+	assert.Equal(t, "## 🔍 Preview: Create Issue", RenderStagedTitle(nil, "Create Issue"), "default title should match messages_staged.cjs")
+	assert.Equal(t, "📋 The following operations would be performed if staged mode was disabled:", RenderStagedDescription(nil, "Create Issue"))
+
+	messages := &SafeOutputMessagesConfig{StagedTitle: "### {operation} (dry run)", StagedDescription: "Pending {operation}"}
+	assert.Equal(t, "### Add Comment (dry run)", RenderStagedTitle(messages, "Add Comment"))
+	assert.Equal(t, "Pending Add Comment", RenderStagedDescription(messages, "Add Comment"))
+}
+
+func TestSafeOutputOperationName(t *testing.T) {
+	assert.Equal(t, "Create Issue", SafeOutputOperationName("create_issue"))
+	assert.Equal(t, "Push To Pull Request Branch", SafeOutputOperationName("push-to-pull-request-branch"))
+	assert.Equal(t, "Noop", SafeOutputOperationName("noop"))
+}
+
+func TestGetSafeOutputMax(t *testing.T) {
+	safeOutputs := &SafeOutputsConfig{
+		CreateIssues: &CreateIssuesConfig{BaseSafeOutputConfig: BaseSafeOutputConfig{Max: 3}},
+		MissingTool:  &MissingToolConfig{},
+	}
+
+	maxIssues, ok := GetSafeOutputMax(safeOutputs, "create_issue")
+	assert.True(t, ok, "create_issue should be enabled")
+	assert.Equal(t, 3, maxIssues)
+
+	maxMissing, ok := GetSafeOutputMax(safeOutputs, "missing_tool")
+	assert.True(t, ok, "missing_tool should be enabled")
+	assert.Equal(t, 0, maxMissing, "zero means unlimited")
+
+	_, ok = GetSafeOutputMax(safeOutputs, "add_comment")
+	assert.False(t, ok, "add_comment is not enabled")
+
+	_, ok = GetSafeOutputMax(nil, "create_issue")
+	assert.False(t, ok, "nil config has no max")
+}
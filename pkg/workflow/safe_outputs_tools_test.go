@@ -296,6 +296,8 @@ func TestGetSafeOutputsToolsJSON(t *testing.T) {
 		"create_pull_request_review_comment",
 		"submit_pull_request_review",
 		"create_code_scanning_alert",
+		"create_check_run",
+		"notify_webhook",
 		"add_labels",
 		"remove_labels",
 		"add_reviewer",
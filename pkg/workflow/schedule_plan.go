@@ -0,0 +1,290 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+)
+
+var schedulePlanLog = logger.New("workflow:schedule_plan")
+
+// ScheduleSource is a workflow taking part in a combined schedule scattering pass
+type ScheduleSource struct {
+	Identifier  string         // Workflow identifier, as passed to SetWorkflowIdentifier
+	Frontmatter map[string]any // Raw frontmatter of the workflow
+}
+
+// PlanRepositorySchedules scatters the fuzzy schedules of all given workflows together so that
+// they spread evenly across the day instead of each one being hashed independently. The compiler
+// plans the directory of the workflow being compiled (see planWorkflowDirSchedules). GitHub
+// Actions only runs the scheduled workflows of .github/workflows, so for that directory the plan
+// covers the whole repository. The plan is used by ResolveSchedules and by normalizeScheduleString
+// until a workflow from another directory is compiled; workflows that are not part of the plan
+// (or whose repository slug differs) fall back to ScatterSchedule.
+func (c *Compiler) PlanRepositorySchedules(sources []ScheduleSource) error {
+	var requests []parser.FuzzyScheduleRequest
+	var fixedCrons []string
+
+	// Sort for a plan that does not depend on the directory listing order
+	sorted := append([]ScheduleSource(nil), sources...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Identifier < sorted[j].Identifier })
+
+	for _, source := range sorted {
+		seed := c.scheduleScatterSeed(source.Identifier)
+		for _, schedule := range ExtractScheduleExpressions(source.Frontmatter) {
+			if schedule.Timezone != "" {
+				// Schedules in a timezone are scattered in local time before conversion, so
+				// they take part as the fixed UTC crons they compile to
+				crons, err := resolveZonedSchedule(schedule, seed)
+				if err == nil {
+					fixedCrons = append(fixedCrons, crons...)
+				}
+				continue
+			}
+			cron, _, err := parser.ParseSchedule(schedule.Expression)
+			if err != nil {
+				continue
+			}
+			if parser.IsFuzzyCron(cron) {
+				requests = append(requests, parser.FuzzyScheduleRequest{FuzzyCron: cron, Seed: seed})
+			} else {
+				fixedCrons = append(fixedCrons, cron)
+			}
+		}
+	}
+
+	planned, err := parser.PlanScatteredSchedules(requests, fixedCrons)
+	if err != nil {
+		return err
+	}
+
+	c.schedulePlan = make(map[string]string, len(requests))
+	for i, request := range requests {
+		c.schedulePlan[schedulePlanKey(request.Seed, request.FuzzyCron)] = planned[i]
+	}
+	schedulePlanLog.Printf("Planned %d fuzzy schedules across %d workflows (%d fixed schedules)", len(requests), len(sources), len(fixedCrons))
+	return nil
+}
+
+// LoadScheduleSources reads the frontmatter of every workflow in a directory. Identifiers are
+// paths relative to gitRoot, matching the identifiers set by the compile commands.
+func LoadScheduleSources(gitRoot, dir string) ([]ScheduleSource, error) {
+	relDir, err := filepath.Rel(gitRoot, dir)
+	if err != nil {
+		return nil, err
+	}
+	return loadScheduleSourcesInDir(dir, filepath.ToSlash(relDir))
+}
+
+// loadScheduleSourcesInDir reads the frontmatter of every workflow in dir, identifying each one
+// by its file name joined to identifierDir. README.md files are not workflows and are skipped.
+func loadScheduleSourcesInDir(dir, identifierDir string) ([]ScheduleSource, error) {
+	mdFiles, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]ScheduleSource, 0, len(mdFiles))
+	for _, file := range mdFiles {
+		if strings.EqualFold(filepath.Base(file), "readme.md") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		result, err := parser.ExtractFrontmatterFromContent(string(content))
+		if err != nil || len(result.Frontmatter) == 0 {
+			continue
+		}
+		sources = append(sources, ScheduleSource{Identifier: path.Join(identifierDir, filepath.Base(file)), Frontmatter: result.Frontmatter})
+	}
+	return sources, nil
+}
+
+// planWorkflowDirSchedules makes sure the fuzzy schedules of the workflow being compiled are
+// scattered together with the other workflows in its directory, which for .github/workflows are
+// all the scheduled workflows of the repository. The plan is made once per
+// directory and repository slug, so every compile path (compile, add, update, init, MCP) gets
+// the same schedules without having to plan up front, and is made again when a workflow in the
+// directory is added, removed or edited (e.g. between compiles in watch mode). Sibling identifiers are derived from the
+// current workflow identifier so that they match the identifiers the other workflows are
+// compiled with. Failures leave the workflow to per-workflow scattering.
+func (c *Compiler) planWorkflowDirSchedules() {
+	if c.scheduleMarkdownPath == "" || c.workflowIdentifier == "" {
+		return
+	}
+	dir, err := filepath.Abs(filepath.Dir(c.scheduleMarkdownPath))
+	if err != nil {
+		return
+	}
+	scope := c.repositorySlug + "\x00" + dir + "\x00" + workflowDirState(dir)
+	if c.schedulePlanScope == scope {
+		return
+	}
+	c.schedulePlanScope = scope
+
+	sources, err := loadScheduleSourcesInDir(dir, path.Dir(c.workflowIdentifier))
+	if err != nil {
+		schedulePlanLog.Printf("Failed to list workflows in %s for schedule planning: %v", dir, err)
+		return
+	}
+	if err := c.PlanRepositorySchedules(sources); err != nil {
+		schedulePlanLog.Printf("Schedule planning failed for %s: %v", dir, err)
+		c.schedulePlan = nil
+	}
+}
+
+// workflowDirState summarizes the workflow files of a directory by name, size and modification
+// time, so that a schedule plan can tell whether the workflows it was made from have changed
+func workflowDirState(dir string) string {
+	mdFiles, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return ""
+	}
+	var state strings.Builder
+	for _, file := range mdFiles {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&state, "%s\x00%d\x00%d\n", filepath.Base(file), info.Size(), info.ModTime().UnixNano())
+	}
+	return state.String()
+}
+
+// ResolvedSchedule is a schedule expression of a workflow and the cron expression it compiles to
+type ResolvedSchedule struct {
+	Expression string // Schedule as written in the frontmatter
+	Cron       string // Cron expression (UTC) emitted in the lock file
+}
+
+// ResolveSchedules returns the cron expressions that a workflow's schedules compile to, using
+// the plan from PlanRepositorySchedules for fuzzy schedules. Expressions that are not schedules
+// (e.g. trigger shorthands) are skipped.
+func (c *Compiler) ResolveSchedules(source ScheduleSource) []ResolvedSchedule {
+	var resolved []ResolvedSchedule
+	seed := c.scheduleScatterSeed(source.Identifier)
+	for _, schedule := range ExtractScheduleExpressions(source.Frontmatter) {
+		if schedule.Timezone != "" {
+			crons, err := resolveZonedSchedule(schedule, seed)
+			if err != nil {
+				schedulePlanLog.Printf("Failed to resolve %s in %s for %s: %v", schedule.Expression, schedule.Timezone, source.Identifier, err)
+				continue
+			}
+			for _, cron := range crons {
+				resolved = append(resolved, ResolvedSchedule{Expression: schedule.String(), Cron: cron})
+			}
+			continue
+		}
+		expression := schedule.Expression
+		cron, _, err := parser.ParseSchedule(expression)
+		if err != nil {
+			continue
+		}
+		if parser.IsFuzzyCron(cron) {
+			planned, ok := c.schedulePlan[schedulePlanKey(seed, cron)]
+			if !ok {
+				if planned, err = parser.ScatterSchedule(cron, seed); err != nil {
+					schedulePlanLog.Printf("Failed to scatter %s for %s: %v", cron, source.Identifier, err)
+					continue
+				}
+			}
+			cron = planned
+		}
+		resolved = append(resolved, ResolvedSchedule{Expression: expression, Cron: cron})
+	}
+	return resolved
+}
+
+// scheduleScatterSeed returns the scattering seed for a workflow identifier.
+// The repository slug is included so that workflows with the same name in different
+// repositories get different execution times, distributing load across an organization.
+func (c *Compiler) scheduleScatterSeed(identifier string) string {
+	if c.repositorySlug != "" {
+		return c.repositorySlug + "/" + identifier
+	}
+	return identifier
+}
+
+// schedulePlanKey returns the key of a planned fuzzy schedule
+func schedulePlanKey(seed, fuzzyCron string) string {
+	return seed + "\x00" + fuzzyCron
+}
+
+// ScheduleExpression is a schedule of a workflow as written in its frontmatter
+type ScheduleExpression struct {
+	Expression string // Cron or natural-language expression, without a trailing timezone
+	Timezone   string // IANA timezone of the expression's times (empty for UTC)
+}
+
+// String returns the schedule as written, with its timezone
+func (s ScheduleExpression) String() string {
+	if s.Timezone == "" {
+		return s.Expression
+	}
+	return s.Expression + " " + s.Timezone
+}
+
+// ExtractScheduleExpressions returns the schedule expressions (cron or natural language) in a
+// workflow's 'on' field: a schedule shorthand string, 'on.schedule' as a string, or the cron
+// fields of 'on.schedule' items together with their timezone fields
+func ExtractScheduleExpressions(frontmatter map[string]any) []ScheduleExpression {
+	switch on := frontmatter["on"].(type) {
+	case string:
+		return []ScheduleExpression{newScheduleExpression(on, "")}
+	case map[string]any:
+		switch schedule := on["schedule"].(type) {
+		case string:
+			return []ScheduleExpression{newScheduleExpression(schedule, "")}
+		case []any:
+			var expressions []ScheduleExpression
+			for _, item := range schedule {
+				if itemMap, ok := item.(map[string]any); ok {
+					if cron, ok := itemMap["cron"].(string); ok {
+						timezone, _ := itemMap["timezone"].(string)
+						expressions = append(expressions, newScheduleExpression(cron, timezone))
+					}
+				}
+			}
+			return expressions
+		}
+	}
+	return nil
+}
+
+// newScheduleExpression splits a trailing timezone off a schedule expression. The timezone field
+// of a schedule item is used when the expression does not name one.
+func newScheduleExpression(expression, timezone string) ScheduleExpression {
+	schedule, suffix := parser.SplitScheduleTimezone(expression)
+	if suffix != "" {
+		return ScheduleExpression{Expression: schedule, Timezone: suffix}
+	}
+	return ScheduleExpression{Expression: expression, Timezone: timezone}
+}
+
+// resolveZonedSchedule returns the UTC cron expressions of a schedule in a timezone. Fuzzy
+// schedules are scattered in local time, like normalizeSchedule does for schedules outside the plan.
+func resolveZonedSchedule(schedule ScheduleExpression, seed string) ([]string, error) {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	cron, _, err := parser.ParseSchedule(schedule.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if parser.IsFuzzyCron(cron) {
+		if cron, err = parser.ScatterSchedule(cron, seed); err != nil {
+			return nil, err
+		}
+	}
+	return parser.ConvertCronToUTC(cron, location)
+}
@@ -0,0 +1,165 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractScheduleExpressions(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter map[string]any
+		expected    []ScheduleExpression
+	}{
+		{name: "shorthand string", frontmatter: map[string]any{"on": "daily"}, expected: []ScheduleExpression{{Expression: "daily"}}},
+		{name: "schedule string", frontmatter: map[string]any{"on": map[string]any{"schedule": "weekly on monday"}}, expected: []ScheduleExpression{{Expression: "weekly on monday"}}},
+		{
+			name:        "schedule string with timezone",
+			frontmatter: map[string]any{"on": map[string]any{"schedule": "daily around 09:00 Europe/Berlin"}},
+			expected:    []ScheduleExpression{{Expression: "daily around 09:00", Timezone: "Europe/Berlin"}},
+		},
+		{
+			name: "schedule items",
+			frontmatter: map[string]any{"on": map[string]any{"schedule": []any{
+				map[string]any{"cron": "0 9 * * 1"},
+				map[string]any{"cron": "daily around 14:00"},
+				map[string]any{"cron": "30 8 * * 1-5", "timezone": "America/Los_Angeles"},
+				"invalid",
+			}}},
+			expected: []ScheduleExpression{
+				{Expression: "0 9 * * 1"},
+				{Expression: "daily around 14:00"},
+				{Expression: "30 8 * * 1-5", Timezone: "America/Los_Angeles"},
+			},
+		},
+		{name: "no schedule", frontmatter: map[string]any{"on": map[string]any{"push": nil}}},
+		{name: "no on", frontmatter: map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExtractScheduleExpressions(tt.frontmatter))
+		})
+	}
+}
+
+func TestPlanRepositorySchedules(t *testing.T) {
+	compiler := NewCompiler(WithRepositorySlug("octo/repo"))
+
+	var sources []ScheduleSource
+	for _, id := range []string{".github/workflows/a.md", ".github/workflows/b.md", ".github/workflows/c.md"} {
+		sources = append(sources, ScheduleSource{Identifier: id, Frontmatter: map[string]any{"on": map[string]any{"schedule": "daily around 09:00"}}})
+	}
+	sources = append(sources, ScheduleSource{Identifier: ".github/workflows/fixed.md", Frontmatter: map[string]any{"on": map[string]any{"schedule": []any{map[string]any{"cron": "0 9 * * *"}}}}})
+	sources = append(sources, ScheduleSource{Identifier: ".github/workflows/push.md", Frontmatter: map[string]any{"on": "push"}})
+
+	require.NoError(t, compiler.PlanRepositorySchedules(sources))
+	assert.Len(t, compiler.schedulePlan, 3, "only fuzzy schedules should be planned")
+
+	windows := make(map[int]bool)
+	for _, source := range sources[:3] {
+		compiler.SetWorkflowIdentifier(source.Identifier)
+		cron, friendly, err := compiler.normalizeScheduleString("daily around 09:00", -1)
+		require.NoError(t, err)
+		assert.Equal(t, compiler.schedulePlan[schedulePlanKey("octo/repo/"+source.Identifier, "FUZZY:DAILY_AROUND:9:0 * * *")], cron, "compilation should use the plan")
+		assert.Contains(t, friendly, "(scattered)")
+
+		occurrences, ok := parser.CronWeeklyOccurrences(cron)
+		require.True(t, ok)
+		for minute := range occurrences {
+			if minute < 24*60 {
+				window := minute / parser.ScheduleBucketMinutes
+				assert.False(t, windows[window], "planned schedules should not share a 15-minute window")
+				assert.NotEqual(t, 9*60/parser.ScheduleBucketMinutes, window, "the fixed 09:00 schedule should be avoided")
+				windows[window] = true
+			}
+		}
+	}
+
+	// Workflows outside the plan still scatter with the hash
+	compiler.SetWorkflowIdentifier(".github/workflows/other.md")
+	cron, _, err := compiler.normalizeScheduleString("daily around 09:00", -1)
+	require.NoError(t, err)
+	expected, err := parser.ScatterSchedule("FUZZY:DAILY_AROUND:9:0 * * *", "octo/repo/.github/workflows/other.md")
+	require.NoError(t, err)
+	assert.Equal(t, expected, cron)
+}
+
+func TestCompilerPlansWorkflowDirSchedules(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("---\non:\n  schedule: daily around 09:00\n---\n# Report\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fixed.md"), []byte("---\non:\n  schedule:\n    - cron: \"0 9 * * *\"\n---\n# Fixed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Workflows\n"), 0644))
+
+	sources, err := LoadScheduleSources(root, dir)
+	require.NoError(t, err)
+	require.Len(t, sources, 4, "README.md should be skipped")
+	assert.Equal(t, ".github/workflows/a.md", sources[0].Identifier)
+
+	planner := NewCompiler(WithRepositorySlug("octo/repo"))
+	require.NoError(t, planner.PlanRepositorySchedules(sources))
+
+	// Compiling a single workflow plans its directory without an explicit planning pass
+	compiler := NewCompiler(WithRepositorySlug("octo/repo"))
+	for _, name := range []string{"b.md", "a.md"} {
+		compiler.scheduleMarkdownPath = filepath.Join(dir, name)
+		compiler.SetWorkflowIdentifier(".github/workflows/" + name)
+		cron, _, err := compiler.normalizeScheduleString("daily around 09:00", -1)
+		require.NoError(t, err)
+		assert.Equal(t, planner.schedulePlan[schedulePlanKey("octo/repo/.github/workflows/"+name, "FUZZY:DAILY_AROUND:9:0 * * *")], cron, "%s should use the directory plan", name)
+	}
+
+	// Adding a workflow to the directory (e.g. in watch mode) makes the plan again
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "d.md"), []byte("---\non:\n  schedule: daily around 09:00\n---\n# Report\n"), 0644))
+	_, _, err = compiler.normalizeScheduleString("daily around 09:00", -1)
+	require.NoError(t, err)
+	assert.Contains(t, compiler.schedulePlan, schedulePlanKey("octo/repo/.github/workflows/d.md", "FUZZY:DAILY_AROUND:9:0 * * *"), "the plan should include the added workflow")
+}
+
+func TestResolveSchedules(t *testing.T) {
+	compiler := NewCompiler(WithRepositorySlug("octo/repo"))
+	source := ScheduleSource{
+		Identifier: ".github/workflows/report.md",
+		Frontmatter: map[string]any{"on": map[string]any{"schedule": []any{
+			map[string]any{"cron": "0 9 * * 1"},
+			map[string]any{"cron": "daily"},
+		}}},
+	}
+	require.NoError(t, compiler.PlanRepositorySchedules([]ScheduleSource{source}))
+
+	resolved := compiler.ResolveSchedules(source)
+	require.Len(t, resolved, 2)
+	assert.Equal(t, ResolvedSchedule{Expression: "0 9 * * 1", Cron: "0 9 * * 1"}, resolved[0])
+	expected, err := parser.ScatterSchedule("FUZZY:DAILY * * *", "octo/repo/.github/workflows/report.md")
+	require.NoError(t, err)
+	assert.Equal(t, ResolvedSchedule{Expression: "daily", Cron: expected}, resolved[1])
+
+	assert.Empty(t, compiler.ResolveSchedules(ScheduleSource{Identifier: "push.md", Frontmatter: map[string]any{"on": "push"}}))
+}
+
+func TestResolveSchedulesWithTimezone(t *testing.T) {
+	compiler := NewCompiler(WithRepositorySlug("octo/repo"))
+	source := ScheduleSource{
+		Identifier: ".github/workflows/standup.md",
+		Frontmatter: map[string]any{"on": map[string]any{"schedule": []any{
+			map[string]any{"cron": "0 9 * * 1-5", "timezone": "Europe/Berlin"},
+		}}},
+	}
+	require.NoError(t, compiler.PlanRepositorySchedules([]ScheduleSource{source}))
+
+	resolved := compiler.ResolveSchedules(source)
+	require.Len(t, resolved, 2, "Europe/Berlin should resolve to one cron per DST period")
+	assert.Equal(t, ResolvedSchedule{Expression: "0 9 * * 1-5 Europe/Berlin", Cron: "0 8 * 1-3,11-12 1-5"}, resolved[0])
+	assert.Equal(t, ResolvedSchedule{Expression: "0 9 * * 1-5 Europe/Berlin", Cron: "0 7 * 4-10 1-5"}, resolved[1])
+}
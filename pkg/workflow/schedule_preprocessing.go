@@ -3,7 +3,9 @@ package workflow
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"strings"
+	"time"
 
 	"github.com/github/gh-aw/pkg/console"
 	"github.com/github/gh-aw/pkg/logger"
@@ -16,6 +18,62 @@ var schedulePreprocessingLog = logger.New("workflow:schedule_preprocessing")
 // fuzzy scattering, and validation logic. It returns the normalized cron expression
 // and the original friendly format, or an error if validation fails.
 func (c *Compiler) normalizeScheduleString(scheduleStr string, itemIndex int) (parsedCron string, friendlyFormat string, err error) {
+	return c.normalizeScheduleInZone(scheduleStr, "UTC", itemIndex)
+}
+
+// normalizeSchedule normalizes a schedule expression that may carry a timezone, either as a
+// trailing IANA name ("daily around 09:00 Europe/Berlin") or from the timezone field of its
+// schedule item. Times in a timezone are converted to UTC; when the UTC offset changes during
+// the year, one cron expression per offset period is returned. The conversion is recorded for
+// the lock file header.
+func (c *Compiler) normalizeSchedule(scheduleStr string, timezone string, itemIndex int) ([]string, string, error) {
+	expression, suffix := parser.SplitScheduleTimezone(scheduleStr)
+	if suffix != "" {
+		if timezone != "" && timezone != suffix {
+			return nil, "", fmt.Errorf("schedule '%s' names timezone '%s' but its timezone field is '%s'", scheduleStr, suffix, timezone)
+		}
+		timezone = suffix
+	}
+	if timezone == "" {
+		parsedCron, friendly, err := c.normalizeScheduleString(scheduleStr, itemIndex)
+		if err != nil {
+			return nil, "", err
+		}
+		return []string{parsedCron}, friendly, nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid timezone '%s' in schedule '%s': use an IANA timezone name such as 'Europe/Berlin'", timezone, scheduleStr)
+	}
+	if parser.HasUTCOffset(expression) {
+		return nil, "", fmt.Errorf("schedule '%s' combines a UTC offset with timezone '%s'; use only one of them", scheduleStr, timezone)
+	}
+
+	localCron, friendly, err := c.normalizeScheduleInZone(expression, timezone, itemIndex)
+	if err != nil {
+		return nil, "", err
+	}
+	crons, err := parser.ConvertCronToUTC(localCron, location)
+	if err != nil {
+		if itemIndex >= 0 {
+			return nil, "", fmt.Errorf("invalid schedule in item %d: %w", itemIndex, err)
+		}
+		return nil, "", err
+	}
+	schedulePreprocessingLog.Printf("Converted schedule %s in %s to UTC: %v", localCron, timezone, crons)
+
+	if friendly == "" {
+		friendly = expression
+	}
+	friendly = fmt.Sprintf("%s in %s", friendly, timezone)
+	c.scheduleConversions = append(c.scheduleConversions, fmt.Sprintf("%s (%s) -> %s", localCron, timezone, strings.Join(crons, " | ")))
+	return crons, friendly, nil
+}
+
+// normalizeScheduleInZone is normalizeScheduleString for a schedule whose times are in the
+// given timezone, which is named in warnings about fixed times.
+func (c *Compiler) normalizeScheduleInZone(scheduleStr string, zone string, itemIndex int) (parsedCron string, friendlyFormat string, err error) {
 	// Try to parse as a schedule expression
 	parsedCron, original, err := parser.ParseSchedule(scheduleStr)
 	if err != nil {
@@ -29,7 +87,7 @@ func (c *Compiler) normalizeScheduleString(scheduleStr string, itemIndex int) (p
 
 	// Warn if using explicit daily cron pattern
 	if parser.IsDailyCron(parsedCron) && !parser.IsFuzzyCron(parsedCron) {
-		c.addDailyCronWarning(parsedCron)
+		c.addDailyCronWarning(parsedCron, zone)
 	}
 
 	// Warn if using hourly interval with fixed minute
@@ -39,25 +97,28 @@ func (c *Compiler) normalizeScheduleString(scheduleStr string, itemIndex int) (p
 
 	// Warn if using explicit weekly cron pattern with fixed time
 	if parser.IsWeeklyCron(parsedCron) && !parser.IsFuzzyCron(parsedCron) {
-		c.addWeeklyCronWarning(parsedCron)
+		c.addWeeklyCronWarning(parsedCron, zone)
 	}
 
 	// Scatter fuzzy schedules if workflow identifier is set
 	if parser.IsFuzzyCron(parsedCron) && c.workflowIdentifier != "" {
 		// Combine repo slug and workflow identifier for scattering seed
-		// This ensures workflows with the same name in different repositories
-		// get different execution times, distributing load across an organization.
 		// Format: "owner/repo/workflow-path" or just "workflow-path" if no repo slug
-		seed := c.workflowIdentifier
-		if c.repositorySlug != "" {
-			seed = c.repositorySlug + "/" + c.workflowIdentifier
-		} else {
+		seed := c.scheduleScatterSeed(c.workflowIdentifier)
+		if c.repositorySlug == "" {
 			// Warn if repository slug is not available - scattering will not be org-aware
 			schedulePreprocessingLog.Printf("Warning: repository slug not available for fuzzy schedule scattering")
-			c.IncrementWarningCount()
-			c.addScheduleWarning("Fuzzy schedule scattering without repository context. Workflows with the same name in different repositories may collide. Ensure you are in a git repository with a configured remote.")
+			warningMsg := "Fuzzy schedule scattering without repository context. Workflows with the same name in different repositories may collide. Ensure you are in a git repository with a configured remote."
+			c.recordWarning(warningMsg)
+			c.addScheduleWarning(warningMsg)
+		}
+		// Prefer the plan of the workflow directory, which accounts for the other workflows' schedules
+		c.planWorkflowDirSchedules()
+		scatteredCron, planned := c.schedulePlan[schedulePlanKey(seed, parsedCron)]
+		var err error
+		if !planned {
+			scatteredCron, err = parser.ScatterSchedule(parsedCron, seed)
 		}
-		scatteredCron, err := parser.ScatterSchedule(parsedCron, seed)
 		if err != nil {
 			schedulePreprocessingLog.Printf("Warning: failed to scatter fuzzy schedule: %v", err)
 			// Keep the original fuzzy schedule as fallback
@@ -93,6 +154,8 @@ func (c *Compiler) normalizeScheduleString(scheduleStr string, itemIndex int) (p
 // in the frontmatter's "on" section. It modifies the frontmatter map in place.
 func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdownPath string, content string) error {
 	schedulePreprocessingLog.Print("Preprocessing schedule fields in frontmatter")
+	c.scheduleConversions = nil
+	c.scheduleMarkdownPath = markdownPath
 
 	// Check if "on" field exists
 	onValue, exists := frontmatter["on"]
@@ -151,11 +214,11 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 		}
 
 		// Try to parse as a schedule expression (only if not already recognized as another trigger type)
-		parsedCron, original, err := c.normalizeScheduleString(onStr, -1)
+		parsedCrons, original, err := c.normalizeSchedule(onStr, "", -1)
 		if err != nil {
-			// Check if this is an explicit rejection of unsupported syntax
+			// Check if this is an explicit rejection of unsupported syntax or timezone
 			// vs. just not being a valid schedule at all
-			if strings.Contains(err.Error(), "syntax is not supported") {
+			if strings.Contains(err.Error(), "syntax is not supported") || strings.Contains(err.Error(), "timezone") {
 				// This is an explicit rejection - return the error
 				return err
 			}
@@ -167,28 +230,13 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 
 		schedulePreprocessingLog.Printf("Converting shorthand 'on: %s' to schedule + workflow_dispatch", onStr)
 
-		// Create schedule array format with workflow_dispatch
-		scheduleArray := []any{
-			map[string]any{
-				"cron": parsedCron,
-			},
-		}
-
 		// Replace the simple "on: schedule" with expanded format
 		onMap := map[string]any{
-			"schedule":          scheduleArray,
+			"schedule":          c.buildScheduleItems(parsedCrons, original),
 			"workflow_dispatch": nil,
 		}
 		frontmatter["on"] = onMap
 
-		// Store friendly format if it was converted
-		if original != "" {
-			if c.scheduleFriendlyFormats == nil {
-				c.scheduleFriendlyFormats = make(map[int]string)
-			}
-			c.scheduleFriendlyFormats[0] = original
-		}
-
 		return nil
 	}
 
@@ -209,26 +257,13 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 	if scheduleStr, ok := scheduleValue.(string); ok {
 		schedulePreprocessingLog.Printf("Converting shorthand schedule string to array format: %s", scheduleStr)
 		// Convert string to array format with single item
-		parsedCron, original, err := c.normalizeScheduleString(scheduleStr, -1)
+		parsedCrons, original, err := c.normalizeSchedule(scheduleStr, "", -1)
 		if err != nil {
 			return fmt.Errorf("invalid schedule expression: %w", err)
 		}
 
 		// Create array format
-		scheduleArray := []any{
-			map[string]any{
-				"cron": parsedCron,
-			},
-		}
-		onMap["schedule"] = scheduleArray
-
-		// Store friendly format if it was converted
-		if original != "" {
-			if c.scheduleFriendlyFormats == nil {
-				c.scheduleFriendlyFormats = make(map[int]string)
-			}
-			c.scheduleFriendlyFormats[0] = original
-		}
+		onMap["schedule"] = c.buildScheduleItems(parsedCrons, original)
 
 		// Add workflow_dispatch if not already present
 		if _, hasWorkflowDispatch := onMap["workflow_dispatch"]; !hasWorkflowDispatch {
@@ -250,8 +285,10 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 		c.scheduleFriendlyFormats = make(map[int]string)
 	}
 
-	// Process each schedule item
+	// Process each schedule item. Items with a timezone can expand to one item per UTC offset
+	// period, so the output array is rebuilt and friendly formats are indexed by output item.
 	schedulePreprocessingLog.Printf("Processing %d schedule items", len(scheduleArray))
+	var normalizedItems []any
 	for i, item := range scheduleArray {
 		itemMap, ok := item.(map[string]any)
 		if !ok {
@@ -268,21 +305,38 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 			return fmt.Errorf("schedule item %d 'cron' field must be a string", i)
 		}
 
+		timezone := ""
+		if timezoneValue, hasTimezone := itemMap["timezone"]; hasTimezone {
+			if timezone, ok = timezoneValue.(string); !ok {
+				return fmt.Errorf("schedule item %d 'timezone' field must be a string", i)
+			}
+		}
+
 		// Try to parse as human-friendly schedule
-		parsedCron, original, err := c.normalizeScheduleString(cronStr, i)
+		parsedCrons, original, err := c.normalizeSchedule(cronStr, timezone, i)
 		if err != nil {
 			// Error already includes item index from normalizeScheduleString
 			return err
 		}
 
-		// Update the cron field with the parsed cron expression
-		itemMap["cron"] = parsedCron
+		// Update the cron field with the parsed cron expression; GitHub Actions schedules have no
+		// timezone field, so it is dropped after conversion
+		delete(itemMap, "timezone")
+		for j, parsedCron := range parsedCrons {
+			normalizedItem := itemMap
+			if j > 0 {
+				normalizedItem = maps.Clone(itemMap)
+			}
+			normalizedItem["cron"] = parsedCron
 
-		// If there was an original friendly format, store it for later use
-		if original != "" {
-			c.scheduleFriendlyFormats[i] = original
+			// If there was an original friendly format, store it for later use
+			if original != "" {
+				c.scheduleFriendlyFormats[len(normalizedItems)] = original
+			}
+			normalizedItems = append(normalizedItems, normalizedItem)
 		}
 	}
+	onMap["schedule"] = normalizedItems
 
 	// Add workflow_dispatch if not already present
 	if _, hasWorkflowDispatch := onMap["workflow_dispatch"]; !hasWorkflowDispatch {
@@ -293,6 +347,26 @@ func (c *Compiler) preprocessScheduleFields(frontmatter map[string]any, markdown
 	return nil
 }
 
+// buildScheduleItems creates the schedule array for cron expressions normalized from a schedule
+// shorthand string and records their friendly format
+func (c *Compiler) buildScheduleItems(parsedCrons []string, original string) []any {
+	scheduleArray := make([]any, 0, len(parsedCrons))
+	for i, parsedCron := range parsedCrons {
+		scheduleArray = append(scheduleArray, map[string]any{
+			"cron": parsedCron,
+		})
+
+		// Store friendly format if it was converted
+		if original != "" {
+			if c.scheduleFriendlyFormats == nil {
+				c.scheduleFriendlyFormats = make(map[int]string)
+			}
+			c.scheduleFriendlyFormats[i] = original
+		}
+	}
+	return scheduleArray
+}
+
 // createTriggerParseError creates a detailed error for trigger parsing issues with source location
 func (c *Compiler) createTriggerParseError(filePath, content, triggerStr string, err error) error {
 	schedulePreprocessingLog.Printf("Creating trigger parse error for: %s", triggerStr)
@@ -420,7 +494,7 @@ func (c *Compiler) addFriendlyScheduleComments(yamlStr string, frontmatter map[s
 }
 
 // addDailyCronWarning emits a warning when a daily cron pattern with fixed time is detected
-func (c *Compiler) addDailyCronWarning(cronExpr string) {
+func (c *Compiler) addDailyCronWarning(cronExpr string, zone string) {
 	// Extract hour and minute from the cron expression
 	fields := strings.Fields(cronExpr)
 	if len(fields) >= 2 {
@@ -430,13 +504,13 @@ func (c *Compiler) addDailyCronWarning(cronExpr string) {
 
 		// Construct the warning message
 		warningMsg := fmt.Sprintf(
-			"Schedule uses fixed daily time (%s:%s UTC). Consider using fuzzy schedule 'daily' instead to distribute workflow execution times and reduce load spikes.",
-			hour, minute,
+			"Schedule uses fixed daily time (%s:%s %s). Consider using fuzzy schedule 'daily' instead to distribute workflow execution times and reduce load spikes.",
+			hour, minute, zone,
 		)
 
 		// This warning is added to the warning count
 		// It will be collected and displayed by the compilation process
-		c.IncrementWarningCount()
+		c.recordWarning(warningMsg)
 
 		// Store the warning for later display
 		c.addScheduleWarning(warningMsg)
@@ -462,7 +536,7 @@ func (c *Compiler) addHourlyCronWarning(cronExpr string) {
 		)
 
 		// This warning is added to the warning count
-		c.IncrementWarningCount()
+		c.recordWarning(warningMsg)
 
 		// Store the warning for later display
 		c.addScheduleWarning(warningMsg)
@@ -470,7 +544,7 @@ func (c *Compiler) addHourlyCronWarning(cronExpr string) {
 }
 
 // addWeeklyCronWarning emits a warning when a weekly cron pattern with fixed time is detected
-func (c *Compiler) addWeeklyCronWarning(cronExpr string) {
+func (c *Compiler) addWeeklyCronWarning(cronExpr string, zone string) {
 	// Extract minute, hour, and weekday from the cron expression
 	fields := strings.Fields(cronExpr)
 	if len(fields) >= 5 {
@@ -496,12 +570,12 @@ func (c *Compiler) addWeeklyCronWarning(cronExpr string) {
 
 		// Construct the warning message
 		warningMsg := fmt.Sprintf(
-			"Schedule uses fixed weekly time (%s %s:%s UTC). Consider using fuzzy schedule 'weekly on %s' instead to distribute workflow execution times and reduce load spikes.",
-			weekdayName, hour, minute, strings.ToLower(weekdayName),
+			"Schedule uses fixed weekly time (%s %s:%s %s). Consider using fuzzy schedule 'weekly on %s' instead to distribute workflow execution times and reduce load spikes.",
+			weekdayName, hour, minute, zone, strings.ToLower(weekdayName),
 		)
 
 		// This warning is added to the warning count
-		c.IncrementWarningCount()
+		c.recordWarning(warningMsg)
 
 		// Store the warning for later display
 		c.addScheduleWarning(warningMsg)
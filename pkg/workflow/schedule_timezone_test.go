@@ -0,0 +1,158 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/github/gh-aw/pkg/stringutil"
+	"github.com/github/gh-aw/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulePreprocessingWithTimezone(t *testing.T) {
+	tests := []struct {
+		name          string
+		on            any
+		expectedCrons []string
+		errorContains string
+	}{
+		{
+			name: "timezone field on cron item",
+			on: map[string]any{"schedule": []any{
+				map[string]any{"cron": "0 9 * * 1-5", "timezone": "Europe/Berlin"},
+			}},
+			expectedCrons: []string{"0 8 * 1-3,11-12 1-5", "0 7 * 4-10 1-5"},
+		},
+		{
+			name:          "trailing timezone in schedule string",
+			on:            map[string]any{"schedule": "0 9 * * 1-5 Europe/Berlin"},
+			expectedCrons: []string{"0 8 * 1-3,11-12 1-5", "0 7 * 4-10 1-5"},
+		},
+		{
+			name:          "timezone without DST",
+			on:            "30 10 * * 1 Asia/Tokyo",
+			expectedCrons: []string{"30 1 * * 1"},
+		},
+		{
+			name: "items without timezone keep their position",
+			on: map[string]any{"schedule": []any{
+				map[string]any{"cron": "0 6 * * 0"},
+				map[string]any{"cron": "0 9 * * 1-5 Europe/Berlin"},
+				map[string]any{"cron": "0 18 * * 5"},
+			}},
+			expectedCrons: []string{"0 6 * * 0", "0 8 * 1-3,11-12 1-5", "0 7 * 4-10 1-5", "0 18 * * 5"},
+		},
+		{
+			name:          "unknown timezone",
+			on:            map[string]any{"schedule": []any{map[string]any{"cron": "0 9 * * *", "timezone": "Mars/Olympus_Mons"}}},
+			errorContains: "invalid timezone 'Mars/Olympus_Mons'",
+		},
+		{
+			name: "conflicting timezones",
+			on: map[string]any{"schedule": []any{
+				map[string]any{"cron": "0 9 * * * Europe/Berlin", "timezone": "America/Los_Angeles"},
+			}},
+			errorContains: "names timezone 'Europe/Berlin'",
+		},
+		{
+			name:          "UTC offset combined with timezone",
+			on:            map[string]any{"schedule": "daily around 14:00 utc+9 Asia/Tokyo"},
+			errorContains: "combines a UTC offset",
+		},
+		{
+			name:          "still rejects daily at",
+			on:            map[string]any{"schedule": "daily at 09:00 Europe/Berlin"},
+			errorContains: "'daily at <time>' syntax is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiler := NewCompiler()
+			compiler.SetWorkflowIdentifier("standup.md")
+			frontmatter := map[string]any{"on": tt.on}
+
+			err := compiler.preprocessScheduleFields(frontmatter, "standup.md", "")
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+
+			schedule := frontmatter["on"].(map[string]any)["schedule"].([]any)
+			var crons []string
+			for _, item := range schedule {
+				itemMap := item.(map[string]any)
+				assert.NotContains(t, itemMap, "timezone", "the timezone field is not valid in GitHub Actions")
+				crons = append(crons, itemMap["cron"].(string))
+			}
+			assert.Equal(t, tt.expectedCrons, crons)
+		})
+	}
+}
+
+func TestFuzzyScheduleWithTimezoneScattersInLocalTime(t *testing.T) {
+	compiler := NewCompiler(WithRepositorySlug("octo/repo"))
+	compiler.SetWorkflowIdentifier("standup.md")
+	frontmatter := map[string]any{"on": map[string]any{"schedule": "daily around 09:00 Europe/Berlin"}}
+
+	require.NoError(t, compiler.preprocessScheduleFields(frontmatter, "standup.md", ""))
+
+	localCron, err := parser.ScatterSchedule("FUZZY:DAILY_AROUND:9:0 * * *", "octo/repo/standup.md")
+	require.NoError(t, err)
+	fields := strings.Fields(localCron)
+
+	schedule := frontmatter["on"].(map[string]any)["schedule"].([]any)
+	require.Len(t, schedule, 2)
+	winter := strings.Fields(schedule[0].(map[string]any)["cron"].(string))
+	summer := strings.Fields(schedule[1].(map[string]any)["cron"].(string))
+	assert.Equal(t, fields[0], winter[0], "the scattered minute should be kept")
+	assert.Equal(t, fields[0], summer[0], "the scattered minute should be kept")
+	assert.Equal(t, "1-3,11-12", winter[3])
+	assert.Equal(t, "4-10", summer[3])
+
+	assert.Equal(t, "daily around 09:00 (scattered) in Europe/Berlin", compiler.scheduleFriendlyFormats[0])
+	assert.Equal(t, "daily around 09:00 (scattered) in Europe/Berlin", compiler.scheduleFriendlyFormats[1])
+	require.Len(t, compiler.scheduleConversions, 1)
+	assert.Contains(t, compiler.scheduleConversions[0], localCron+" (Europe/Berlin) -> ")
+}
+
+func TestScheduleTimezoneLockFileHeader(t *testing.T) {
+	tmpDir := testutil.TempDir(t, "schedule-timezone-test")
+	content := `---
+on:
+  schedule:
+    - cron: "0 9 * * 1-5"
+      timezone: Europe/Berlin
+permissions:
+  contents: read
+engine: copilot
+---
+
+# Standup
+
+Summarize yesterday's activity.
+`
+	workflowPath := filepath.Join(tmpDir, "standup.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(content), 0644))
+
+	compiler := NewCompiler()
+	require.NoError(t, compiler.CompileWorkflow(workflowPath))
+
+	lockContent, err := os.ReadFile(stringutil.MarkdownToLockFile(workflowPath))
+	require.NoError(t, err)
+	lock := string(lockContent)
+
+	assert.Contains(t, lock, "# Schedule timezone conversion (local cron -> UTC cron):\n#   0 9 * * 1-5 (Europe/Berlin) -> 0 8 * 1-3,11-12 1-5 | 0 7 * 4-10 1-5\n")
+	assert.Contains(t, lock, `- cron: "0 8 * 1-3,11-12 1-5"`)
+	assert.Contains(t, lock, `- cron: "0 7 * 4-10 1-5"`)
+	assert.Contains(t, lock, "# Friendly format: 0 9 * * 1-5 in Europe/Berlin")
+	assert.NotContains(t, lock, "timezone: Europe/Berlin\n  workflow_dispatch", "the timezone field should not be emitted")
+}
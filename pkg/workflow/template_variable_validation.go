@@ -0,0 +1,284 @@
+// This file provides template variable validation across a workflow and its imports.
+//
+// # Template Variable Validation
+//
+// Template variables in the workflow prompt that don't resolve render as empty strings at
+// runtime. This file reports them at compile time:
+//   - github.aw.inputs.* referenced by an imported workflow but not provided by the import
+//   - Import inputs provided but never referenced by the imported workflow
+//   - needs.<job>.outputs.<name> referring to a job or output that the compiled workflow lacks
+//   - Expressions of runtime-imported files that are not in the allowed-expression list
+//
+// Each finding suggests the closest valid name when there is one.
+//
+// # Validation Functions
+//
+//   - validateTemplateVariables() - Reports template variable findings (warnings, errors in strict mode)
+//   - collectTemplateVariableIssues() - Collects the findings of a compiled workflow
+//
+// Expressions of the main workflow and of imports with inputs are already validated against the
+// allowed-expression list by validateExpressionSafety(), so only runtime-imported files are
+// checked against it here. Job outputs are checked after the jobs are built, so this validation
+// runs from generateAndValidateYAML().
+
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+)
+
+var templateVariableValidationLog = logger.New("workflow:template_variable_validation")
+
+// needsOutputPattern matches needs.<job>.outputs.<name> references inside an expression
+var needsOutputPattern = regexp.MustCompile(`\bneeds\.([a-zA-Z0-9_-]+)\.outputs\.([a-zA-Z0-9_-]+)`)
+
+// templateVariableIssue is a template variable that will not resolve at runtime
+type templateVariableIssue struct {
+	Source     string // File the variable is used in, relative to the workflow directory
+	Message    string
+	Suggestion string // Closest valid name, if any
+}
+
+// String formats the issue as a list item of the validation message
+func (i templateVariableIssue) String() string {
+	message := fmt.Sprintf("%s: %s", i.Source, i.Message)
+	if i.Suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %s?)", i.Suggestion)
+	}
+	return message
+}
+
+// templateSource is a markdown file whose body becomes part of the prompt
+type templateSource struct {
+	name        string
+	body        string
+	inputs      map[string]any // Inputs passed by the import (nil for the main workflow and runtime imports)
+	substituted map[string]any // Inputs of all imports, which are substituted together
+	definitions map[string]any // Input definitions of the imported workflow
+	hasInputs   bool           // Whether the body is inlined with github.aw.inputs.* substitution
+	runtime     bool           // Whether the body is loaded by a runtime-import macro
+}
+
+// validateTemplateVariables reports template variables that will not resolve at runtime.
+// Findings are warnings, and errors in strict mode.
+func (c *Compiler) validateTemplateVariables(data *WorkflowData, markdownPath string) error {
+	issues := c.collectTemplateVariableIssues(data, markdownPath)
+	if len(issues) == 0 {
+		return nil
+	}
+	templateVariableValidationLog.Printf("Found %d template variable issues", len(issues))
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "%d template variables will not resolve at runtime and render as empty strings:", len(issues))
+	for _, issue := range issues {
+		message.WriteString("\n  - ")
+		message.WriteString(issue.String())
+	}
+
+	if c.strictMode {
+		return formatCompilerError(markdownPath, "error", message.String(), nil)
+	}
+	fmt.Fprintln(os.Stderr, formatCompilerMessage(markdownPath, "warning", message.String()))
+	c.recordWarning(message.String())
+	return nil
+}
+
+// collectTemplateVariableIssues checks the template variables of the main workflow, of the
+// imports with inputs and of the runtime-imported files against the import inputs and the jobs
+// of the compiled workflow
+func (c *Compiler) collectTemplateVariableIssues(data *WorkflowData, markdownPath string) []templateVariableIssue {
+	var issues []templateVariableIssue
+	for _, source := range loadTemplateSources(data, markdownPath) {
+		issues = append(issues, checkImportInputs(source)...)
+		issues = append(issues, c.checkJobOutputs(source)...)
+		if source.runtime {
+			issues = append(issues, checkAllowedExpressions(source)...)
+		}
+	}
+	return issues
+}
+
+// loadTemplateSources returns the markdown bodies that make up the prompt. Files that cannot be
+// read are skipped; missing imports are reported by the import processing.
+func loadTemplateSources(data *WorkflowData, markdownPath string) []templateSource {
+	mainBody := data.MainWorkflowMarkdown
+	if mainBody == "" {
+		mainBody = data.MarkdownContent
+	}
+	sources := []templateSource{{name: filepath.Base(markdownPath), body: mainBody}}
+
+	for _, imported := range data.ImportsWithInputs {
+		result, err := readTemplateSource(imported.FullPath)
+		if err != nil {
+			templateVariableValidationLog.Printf("Skipping import %s: %v", imported.ImportPath, err)
+			continue
+		}
+		definitions, _ := result.Frontmatter["inputs"].(map[string]any)
+		sources = append(sources, templateSource{
+			name:        imported.ImportPath,
+			body:        result.Markdown,
+			inputs:      imported.Inputs,
+			substituted: data.ImportInputs,
+			definitions: definitions,
+			hasInputs:   true,
+		})
+	}
+
+	// Imports without inputs are loaded at runtime from their repository path
+	workspaceDir := filepath.Dir(filepath.Dir(filepath.Dir(markdownPath)))
+	for _, importPath := range data.ImportPaths {
+		if !strings.HasPrefix(importPath, ".github/") {
+			continue
+		}
+		result, err := readTemplateSource(filepath.Join(workspaceDir, filepath.FromSlash(importPath)))
+		if err != nil {
+			templateVariableValidationLog.Printf("Skipping runtime import %s: %v", importPath, err)
+			continue
+		}
+		name := importPath
+		if relPath, err := filepath.Rel(filepath.Dir(markdownPath), filepath.Join(workspaceDir, importPath)); err == nil && !strings.HasPrefix(relPath, "..") {
+			name = filepath.ToSlash(relPath)
+		}
+		sources = append(sources, templateSource{name: name, body: result.Markdown, runtime: true})
+	}
+	return sources
+}
+
+// readTemplateSource reads the frontmatter and markdown body of a workflow file
+func readTemplateSource(path string) (*parser.FrontmatterResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ExtractFrontmatterFromContent(string(content))
+}
+
+// checkImportInputs compares the github.aw.inputs.* references of a source with the inputs
+// passed to it. Only imports with inputs are substituted, so references anywhere else never
+// resolve.
+func checkImportInputs(source templateSource) []templateVariableIssue {
+	var referenced []string
+	for _, match := range AWInputsExpressionPattern.FindAllStringSubmatch(source.body, -1) {
+		if !slices.Contains(referenced, match[1]) {
+			referenced = append(referenced, match[1])
+		}
+	}
+
+	var issues []templateVariableIssue
+	if !source.hasInputs {
+		for _, name := range referenced {
+			issues = append(issues, templateVariableIssue{
+				Source:  source.name,
+				Message: fmt.Sprintf("github.aw.inputs.%s is referenced, but only imports with inputs are substituted", name),
+			})
+		}
+		return issues
+	}
+
+	provided := slices.Sorted(maps.Keys(source.inputs))
+	known := append(slices.Clone(provided), slices.Sorted(maps.Keys(source.definitions))...)
+	for _, name := range referenced {
+		if _, ok := source.substituted[name]; ok {
+			continue
+		}
+		issues = append(issues, templateVariableIssue{
+			Source:     source.name,
+			Message:    fmt.Sprintf("github.aw.inputs.%s is referenced but never provided by the import", name),
+			Suggestion: closestName(name, known, "github.aw.inputs."),
+		})
+	}
+	for _, name := range provided {
+		if slices.Contains(referenced, name) {
+			continue
+		}
+		issues = append(issues, templateVariableIssue{
+			Source:     source.name,
+			Message:    fmt.Sprintf("input %s is provided but never used", name),
+			Suggestion: closestName(name, referenced, ""),
+		})
+	}
+	return issues
+}
+
+// checkJobOutputs checks the needs.<job>.outputs.<name> references of a source against the
+// jobs of the compiled workflow
+func (c *Compiler) checkJobOutputs(source templateSource) []templateVariableIssue {
+	if c.jobManager == nil || len(c.jobManager.GetAllJobs()) == 0 {
+		return nil
+	}
+	jobNames := slices.Sorted(maps.Keys(c.jobManager.GetAllJobs()))
+
+	var issues []templateVariableIssue
+	seen := make(map[string]bool)
+	for _, expression := range expressionRegex.FindAllStringSubmatch(source.body, -1) {
+		for _, match := range needsOutputPattern.FindAllStringSubmatch(expression[1], -1) {
+			reference, jobName, output := match[0], match[1], match[2]
+			if seen[reference] {
+				continue
+			}
+			seen[reference] = true
+
+			job, exists := c.jobManager.GetJob(jobName)
+			if !exists {
+				issues = append(issues, templateVariableIssue{
+					Source:     source.name,
+					Message:    fmt.Sprintf("%s refers to job %s, which is not part of the workflow", reference, jobName),
+					Suggestion: closestName(jobName, jobNames, "needs."),
+				})
+				continue
+			}
+			if _, ok := job.Outputs[output]; !ok {
+				outputs := slices.Sorted(maps.Keys(job.Outputs))
+				if jobName == string(constants.ActivationJobName) && !slices.Contains(outputs, "text") {
+					// The text output is added when the prompt references it
+					outputs = append(outputs, "text")
+				}
+				issues = append(issues, templateVariableIssue{
+					Source:     source.name,
+					Message:    fmt.Sprintf("%s refers to an output that job %s does not define", reference, jobName),
+					Suggestion: closestName(output, outputs, "needs."+jobName+".outputs."),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// checkAllowedExpressions reports the expressions of a runtime-imported source that are not in
+// the allowed-expression list. They are rejected when the prompt is rendered at runtime.
+func checkAllowedExpressions(source templateSource) []templateVariableIssue {
+	unauthorized, err := collectUnauthorizedExpressions(source.body)
+	if err != nil {
+		return []templateVariableIssue{{Source: source.name, Message: err.Error()}}
+	}
+
+	issues := make([]templateVariableIssue, 0, len(unauthorized))
+	for _, expression := range unauthorized {
+		issues = append(issues, templateVariableIssue{
+			Source:     source.name,
+			Message:    fmt.Sprintf("${{ %s }} is not in the allowed-expression list", expression),
+			Suggestion: closestName(expression, constants.AllowedExpressions, ""),
+		})
+	}
+	return issues
+}
+
+// closestName returns the closest candidate to a misspelled name, with the given prefix, or an
+// empty string when no candidate is close enough
+func closestName(name string, candidates []string, prefix string) string {
+	matches := parser.FindClosestMatches(name, candidates, 1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return prefix + matches[0]
+}
@@ -0,0 +1,109 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTemplateVariableTest writes a workflow with an import with inputs and a runtime import
+func setupTemplateVariableTest(t *testing.T, body string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	sharedDir := filepath.Join(tmpDir, ".github", "workflows", "shared")
+	require.NoError(t, os.MkdirAll(sharedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDir, "data-fetch.md"), []byte(`---
+inputs:
+  count:
+    type: number
+  category:
+    type: string
+  label:
+    type: string
+---
+
+Fetch ${{ github.aw.inputs.count }} items from the ${{ github.aw.inputs.categroy }} category.
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDir, "reporting.md"), []byte(`---
+tools:
+  bash: [ls]
+---
+
+Report on ${{ github.event.issue.titel }} from ${{ github.aw.inputs.count }}.
+`), 0644))
+
+	workflowPath := filepath.Join(tmpDir, ".github", "workflows", "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened]
+imports:
+  - path: shared/data-fetch.md
+    inputs:
+      count: 50
+      category: technology
+  - shared/reporting.md
+---
+
+`+body), 0644))
+	return workflowPath
+}
+
+func TestTemplateVariableIssues(t *testing.T) {
+	workflowPath := setupTemplateVariableTest(t, "Triage ${{ needs.activation.outputs.txt }} and ${{ needs.agnet.outputs.text }}.\n")
+
+	compiler := NewCompiler(WithGitRoot(filepath.Dir(filepath.Dir(filepath.Dir(workflowPath)))))
+	compiler.SetQuiet(true)
+	_, _, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err, "template variable findings are warnings outside strict mode")
+	assert.Positive(t, compiler.GetWarningCount())
+
+	workflowData, err := compiler.ParseWorkflowFile(workflowPath)
+	require.NoError(t, err)
+	issues := make([]string, 0)
+	for _, issue := range compiler.collectTemplateVariableIssues(workflowData, workflowPath) {
+		issues = append(issues, issue.String())
+	}
+	assert.Equal(t, []string{
+		"triage.md: needs.activation.outputs.txt refers to an output that job activation does not define (did you mean needs.activation.outputs.text?)",
+		"triage.md: needs.agnet.outputs.text refers to job agnet, which is not part of the workflow (did you mean needs.agent?)",
+		"shared/data-fetch.md: github.aw.inputs.categroy is referenced but never provided by the import (did you mean github.aw.inputs.category?)",
+		"shared/data-fetch.md: input category is provided but never used (did you mean categroy?)",
+		"shared/reporting.md: github.aw.inputs.count is referenced, but only imports with inputs are substituted",
+		"shared/reporting.md: ${{ github.event.issue.titel }} is not in the allowed-expression list (did you mean github.event.issue.title?)",
+	}, issues)
+}
+
+func TestTemplateVariableIssuesStrictMode(t *testing.T) {
+	workflowPath := setupTemplateVariableTest(t, "Triage ${{ needs.activation.outputs.text }}.\n")
+	content, err := os.ReadFile(workflowPath)
+	require.NoError(t, err)
+	strictFrontmatter := "---\npermissions:\n  contents: read\n  issues: read\n  pull-requests: read\n"
+	require.NoError(t, os.WriteFile(workflowPath, []byte(strictFrontmatter+string(content)[len("---\n"):]), 0644))
+
+	compiler := NewCompiler(WithGitRoot(filepath.Dir(filepath.Dir(filepath.Dir(workflowPath)))))
+	compiler.SetQuiet(true)
+	compiler.SetStrictMode(true)
+	_, _, err = compiler.CompileWorkflowToYAML(workflowPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4 template variables will not resolve at runtime and render as empty strings:")
+	assert.Contains(t, err.Error(), "\n  - shared/data-fetch.md: github.aw.inputs.categroy is referenced but never provided by the import")
+}
+
+func TestTemplateVariableIssuesClean(t *testing.T) {
+	compiler := NewCompiler()
+	issues := checkImportInputs(templateSource{
+		name:        "shared/data-fetch.md",
+		body:        "Fetch ${{ github.aw.inputs.count }} items.",
+		inputs:      map[string]any{"count": 50},
+		substituted: map[string]any{"count": 50},
+		hasInputs:   true,
+	})
+	assert.Empty(t, issues)
+	assert.Empty(t, compiler.checkJobOutputs(templateSource{name: "triage.md", body: "${{ needs.activation.outputs.text }}"}), "jobs are only checked once they are built")
+}
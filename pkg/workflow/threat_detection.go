@@ -315,6 +315,11 @@ func (c *Compiler) buildEngineSteps(data *WorkflowData) []string {
 		engineSetting = "claude"
 	}
 
+	// Replayed sessions have no model to run threat detection with
+	if engineSetting == string(constants.ReplayEngine) {
+		return []string{"      # AI engine not available for threat detection (engine: replay)\n"}
+	}
+
 	// Get the engine instance
 	engine, err := c.getAgenticEngine(engineSetting)
 	if err != nil {
@@ -172,6 +172,27 @@ func enhanceToolDescription(toolName, baseDescription string, safeOutputs *SafeO
 			}
 		}
 
+	case "create_check_run":
+		if config := safeOutputs.CreateCheckRuns; config != nil {
+			if config.Max > 0 {
+				constraints = append(constraints, fmt.Sprintf("Maximum %d check run(s) can be created.", config.Max))
+			}
+			if config.MaxAnnotations > 0 {
+				constraints = append(constraints, fmt.Sprintf("Maximum %d annotation(s) per check run.", config.MaxAnnotations))
+			}
+			constraints = append(constraints, fmt.Sprintf("Allowed conclusions: %v.", config.allowedConclusions()))
+		}
+
+	case "notify_webhook":
+		if config := safeOutputs.NotifyWebhook; config != nil {
+			if config.Max > 0 {
+				constraints = append(constraints, fmt.Sprintf("Maximum %d notification(s) can be sent.", config.Max))
+			}
+			if len(config.Endpoints) > 0 {
+				constraints = append(constraints, fmt.Sprintf("Available endpoints: %v.", config.endpointNames()))
+			}
+		}
+
 	case "add_labels":
 		if config := safeOutputs.AddLabels; config != nil {
 			if config.Max > 0 {
@@ -0,0 +1,409 @@
+// This file simulates which jobs of a compiled workflow run for a webhook event.
+//
+// The simulation reads the jobs of a lock file and evaluates their if: conditions in dependency
+// order with the expression evaluator. The github context is built from the event payload; the
+// results and outputs of needed jobs come from the simulation of those jobs. Step outputs are only
+// known at runtime (for example the role check and skip-if-match results of the pre-activation job),
+// so conditions that depend on them are reported as depending on those values.
+
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/goccy/go-yaml"
+)
+
+var triggerSimulationLog = logger.New("workflow:trigger_simulation")
+
+// TriggerEvent is a webhook event to simulate a compiled workflow against
+type TriggerEvent struct {
+	Name    string         // Event name, e.g. "issue_comment"
+	Payload map[string]any // Webhook payload (github.event)
+}
+
+// JobOutcome is the simulated outcome of a job
+type JobOutcome string
+
+const (
+	JobRuns    JobOutcome = "run"
+	JobSkipped JobOutcome = "skip"
+	JobDepends JobOutcome = "depends" // Depends on values only known at runtime
+)
+
+// JobSimulation explains whether a job runs for an event
+type JobSimulation struct {
+	Job       string            `json:"job"`
+	Needs     []string          `json:"needs,omitempty"`
+	Condition string            `json:"condition,omitempty"`
+	Outcome   JobOutcome        `json:"outcome"`
+	Reasons   []string          `json:"reasons,omitempty"`
+	Runtime   []string          `json:"runtime,omitempty"` // Runtime values the outcome depends on
+	Outputs   map[string]string `json:"outputs,omitempty"` // Outputs that depend on runtime values, with those values
+}
+
+// TriggerSimulation is the result of simulating a compiled workflow against an event
+type TriggerSimulation struct {
+	Event     string          `json:"event"`
+	Triggered bool            `json:"triggered"` // The workflow's on: section accepts the event
+	Reason    string          `json:"reason,omitempty"`
+	Jobs      []JobSimulation `json:"jobs"`
+}
+
+// lockJob is the part of a compiled job that affects whether it runs
+type lockJob struct {
+	Needs   any               `yaml:"needs"`
+	If      string            `yaml:"if"`
+	Outputs map[string]string `yaml:"outputs"`
+	Steps   []lockStep        `yaml:"steps"`
+}
+
+// lockStep is the part of a compiled step that affects its outputs
+type lockStep struct {
+	ID string `yaml:"id"`
+	If string `yaml:"if"`
+}
+
+// SimulateTrigger simulates which jobs of a compiled workflow run for an event
+func SimulateTrigger(lockYAML []byte, event TriggerEvent) (*TriggerSimulation, error) {
+	var workflow struct {
+		Name string             `yaml:"name"`
+		On   any                `yaml:"on"`
+		Env  map[string]any     `yaml:"env"`
+		Jobs map[string]lockJob `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(lockYAML, &workflow); err != nil {
+		return nil, fmt.Errorf("failed to parse compiled workflow: %w", err)
+	}
+	triggerSimulationLog.Printf("Simulating %s event against workflow %q with %d jobs", event.Name, workflow.Name, len(workflow.Jobs))
+
+	simulation := &TriggerSimulation{Event: event.Name}
+	simulation.Triggered, simulation.Reason = matchesTrigger(workflow.On, event)
+
+	order, err := orderJobs(workflow.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	github := buildGitHubContext(event)
+	github["workflow"] = workflow.Name
+	values := map[string]any{
+		"github":  github,
+		"env":     knownStrings(workflow.Env),
+		"inputs":  map[string]any{},
+		"vars":    &UnknownValue{},
+		"secrets": &UnknownValue{},
+		"steps":   map[string]any{},
+	}
+	if inputs, ok := event.Payload["inputs"].(map[string]any); ok {
+		values["inputs"] = inputs
+	}
+
+	needsResults := make(map[string]map[string]any)
+	for _, name := range order {
+		job := workflow.Jobs[name]
+		needs := jobNeeds(job.Needs)
+		result := JobSimulation{Job: name, Needs: needs, Condition: NormalizeExpressionForComparison(stripExpressionWrapper(job.If))}
+
+		if !simulation.Triggered {
+			result.Outcome = JobSkipped
+			result.Reasons = []string{"workflow is not triggered"}
+			needsResults[name] = simulatedJobContext(JobSkipped, name, job, nil)
+			simulation.Jobs = append(simulation.Jobs, result)
+			continue
+		}
+
+		needsContext := make(map[string]any)
+		var skippedNeeds, unknownNeeds []string
+		for _, need := range needs {
+			needResult := needsResults[need]
+			needsContext[need] = needResult
+			switch needResult["result"].(type) {
+			case *UnknownValue:
+				unknownNeeds = append(unknownNeeds, "needs."+need+".result")
+			default:
+				if needResult["result"] != "success" {
+					skippedNeeds = append(skippedNeeds, need)
+				}
+			}
+		}
+		values["needs"] = needsContext
+		ctx := &ExpressionContext{Values: values, StatusUnknown: unknownNeeds}
+
+		condition := stripExpressionWrapper(job.If)
+		if !HasStatusFunction(condition) && len(skippedNeeds) > 0 {
+			// Without a status check function, a job is skipped when a job it needs did not succeed
+			result.Outcome = JobSkipped
+			result.Reasons = []string{fmt.Sprintf("needs %s, which is skipped", strings.Join(skippedNeeds, ", "))}
+		} else {
+			evaluation := &ConditionEvaluation{Result: ConditionTrue}
+			if condition != "" {
+				evaluation, err = EvaluateConditionString(condition, ctx)
+				if err != nil {
+					return nil, fmt.Errorf("job %s: failed to evaluate condition: %w", name, err)
+				}
+			}
+			if !HasStatusFunction(condition) && len(unknownNeeds) > 0 && evaluation.Result == ConditionTrue {
+				evaluation = &ConditionEvaluation{
+					Result:   ConditionUnknown,
+					Reasons:  []string{fmt.Sprintf("needs %s, which may be skipped", strings.Join(unknownNeedNames(unknownNeeds), ", "))},
+					Unknowns: unknownNeeds,
+				}
+			}
+			result.Reasons = evaluation.Reasons
+			switch evaluation.Result {
+			case ConditionTrue:
+				result.Outcome = JobRuns
+				if condition == "" && len(needs) > 0 {
+					result.Reasons = []string{"all needed jobs succeed"}
+				} else if condition == "" {
+					result.Reasons = []string{"no condition"}
+				}
+			case ConditionFalse:
+				result.Outcome = JobSkipped
+			default:
+				result.Outcome = JobDepends
+				result.Runtime = evaluation.Unknowns
+			}
+		}
+
+		var outputs map[string]any
+		if result.Outcome != JobSkipped {
+			outputs, result.Outputs = simulateJobOutputs(job, ctx)
+		}
+		needsResults[name] = simulatedJobContext(result.Outcome, name, job, outputs)
+		simulation.Jobs = append(simulation.Jobs, result)
+	}
+
+	triggerSimulationLog.Printf("Simulated %d jobs (triggered=%v)", len(simulation.Jobs), simulation.Triggered)
+	return simulation, nil
+}
+
+// unknownNeedNames returns the job names of needs.<job>.result references
+func unknownNeedNames(references []string) []string {
+	names := make([]string, 0, len(references))
+	for _, reference := range references {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(reference, "needs."), ".result"))
+	}
+	return names
+}
+
+// simulateJobOutputs evaluates the outputs of a job that runs. Steps are evaluated in order: the
+// outputs of steps whose condition is false are empty, all other step outputs are runtime values.
+// Returns the output values and, for outputs that depend on runtime values, those values.
+func simulateJobOutputs(job lockJob, ctx *ExpressionContext) (map[string]any, map[string]string) {
+	steps := make(map[string]any)
+	stepCtx := &ExpressionContext{Values: maps.Clone(ctx.Values)}
+	stepCtx.Values["steps"] = steps
+
+	for _, step := range job.Steps {
+		if step.ID == "" {
+			continue
+		}
+		steps[step.ID] = &UnknownValue{}
+		if step.If == "" {
+			continue
+		}
+		evaluation, err := EvaluateConditionString(step.If, stepCtx)
+		if err == nil && evaluation.Result == ConditionFalse {
+			steps[step.ID] = map[string]any{"outputs": map[string]any{}, "outcome": "skipped", "conclusion": "skipped"}
+		}
+	}
+
+	values := make(map[string]any, len(job.Outputs))
+	var runtime map[string]string
+	for name, expression := range job.Outputs {
+		values[name] = evaluateOutputValue(expression, stepCtx)
+		if unknown, ok := values[name].(*UnknownValue); ok {
+			if runtime == nil {
+				runtime = make(map[string]string)
+			}
+			runtime[name] = strings.Join(unknown.References, ", ")
+		}
+	}
+	return values, runtime
+}
+
+// evaluateOutputValue evaluates a job output, which is a string with ${{ }} expressions
+func evaluateOutputValue(expression string, ctx *ExpressionContext) any {
+	trimmed := strings.TrimSpace(expression)
+	if !strings.Contains(trimmed, "${{") {
+		return trimmed
+	}
+	if strings.HasPrefix(trimmed, "${{") && strings.HasSuffix(trimmed, "}}") && strings.Count(trimmed, "${{") == 1 {
+		value, err := EvaluateExpression(stripExpressionWrapper(trimmed), ctx)
+		if err == nil {
+			return value
+		}
+	}
+	return &UnknownValue{References: []string{trimmed}}
+}
+
+// simulatedJobContext builds the needs.<job> context of a simulated job. Outputs of skipped jobs are
+// empty; outputs of jobs that may or may not run, and runtime outputs, are unknown.
+func simulatedJobContext(outcome JobOutcome, name string, job lockJob, values map[string]any) map[string]any {
+	outputs := make(map[string]any, len(job.Outputs))
+	for output := range job.Outputs {
+		value, known := values[output]
+		if _, unknown := value.(*UnknownValue); unknown || !known {
+			value = &UnknownValue{References: []string{fmt.Sprintf("needs.%s.outputs.%s", name, output)}}
+		}
+		if outcome == JobSkipped {
+			value = ""
+		}
+		outputs[output] = value
+	}
+	var result any = "success"
+	switch outcome {
+	case JobSkipped:
+		result = "skipped"
+	case JobDepends:
+		result = &UnknownValue{References: []string{fmt.Sprintf("needs.%s.result", name)}}
+		for output := range outputs {
+			outputs[output] = &UnknownValue{References: []string{fmt.Sprintf("needs.%s.outputs.%s", name, output)}}
+		}
+	}
+	return map[string]any{"result": result, "outputs": outputs}
+}
+
+// matchesTrigger reports whether the on: section accepts the event, checking the event name and
+// the activity types. Branch, path and other filters are not evaluated.
+func matchesTrigger(on any, event TriggerEvent) (bool, string) {
+	var config any
+	found := false
+	switch triggers := on.(type) {
+	case string:
+		found = triggers == event.Name
+	case []any:
+		for _, trigger := range triggers {
+			if trigger == event.Name {
+				found = true
+			}
+		}
+	case map[string]any:
+		config, found = triggers[event.Name]
+	}
+	if !found {
+		return false, fmt.Sprintf("the workflow has no %s trigger", event.Name)
+	}
+
+	settings, _ := config.(map[string]any)
+	types, _ := settings["types"].([]any)
+	if len(types) == 0 {
+		return true, ""
+	}
+	action, _ := event.Payload["action"].(string)
+	for _, activityType := range types {
+		if activityType == action {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("activity type %q is not in the %s types %v", action, event.Name, types)
+}
+
+// buildGitHubContext builds the github context from an event payload
+func buildGitHubContext(event TriggerEvent) map[string]any {
+	github := map[string]any{
+		"event_name": event.Name,
+		"event":      event.Payload,
+	}
+	if sender, ok := event.Payload["sender"].(map[string]any); ok {
+		github["actor"] = sender["login"]
+		github["triggering_actor"] = sender["login"]
+	}
+	if repository, ok := event.Payload["repository"].(map[string]any); ok {
+		github["repository"] = repository["full_name"]
+		github["repository_id"] = repository["id"]
+		if owner, ok := repository["owner"].(map[string]any); ok {
+			github["repository_owner"] = owner["login"]
+		}
+		if defaultBranch, ok := repository["default_branch"].(string); ok {
+			github["ref"] = "refs/heads/" + defaultBranch
+			github["ref_name"] = defaultBranch
+		}
+	}
+	if ref, ok := event.Payload["ref"].(string); ok && strings.HasPrefix(ref, "refs/") {
+		github["ref"] = ref
+		github["ref_name"] = ref[strings.LastIndex(ref, "/")+1:]
+	}
+	if pullRequest, ok := event.Payload["pull_request"].(map[string]any); ok {
+		if head, ok := pullRequest["head"].(map[string]any); ok {
+			github["head_ref"] = head["ref"]
+		}
+		if base, ok := pullRequest["base"].(map[string]any); ok {
+			github["base_ref"] = base["ref"]
+		}
+	}
+	return github
+}
+
+// knownStrings returns the string values of a map that do not contain expressions; others are unknown
+func knownStrings(values map[string]any) map[string]any {
+	result := make(map[string]any, len(values))
+	for key, value := range values {
+		if s, ok := value.(string); ok && strings.Contains(s, "${{") {
+			result[key] = &UnknownValue{References: []string{"env." + key}}
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// jobNeeds returns the needs of a job, which is a job name or a list of job names
+func jobNeeds(needs any) []string {
+	switch v := needs.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var result []string
+		for _, need := range v {
+			if name, ok := need.(string); ok {
+				result = append(result, name)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// orderJobs sorts jobs so that every job comes after the jobs it needs (ties in name order)
+func orderJobs(jobs map[string]lockJob) ([]string, error) {
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var order []string
+	done := make(map[string]bool)
+	for len(order) < len(names) {
+		progress := false
+		for _, name := range names {
+			if done[name] {
+				continue
+			}
+			ready := true
+			for _, need := range jobNeeds(jobs[name].Needs) {
+				if _, exists := jobs[need]; exists && !done[need] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, name)
+				done[name] = true
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			return nil, fmt.Errorf("jobs have circular needs")
+		}
+	}
+	return order, nil
+}
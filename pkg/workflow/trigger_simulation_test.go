@@ -0,0 +1,176 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const triggerSimulationLockYAML = `name: "Triage"
+"on":
+  issues:
+    types:
+    - opened
+  issue_comment:
+    types:
+    - created
+jobs:
+  pre_activation:
+    if: github.event_name == 'issues' || startsWith(github.event.comment.body, '/triage')
+    runs-on: ubuntu-latest
+    outputs:
+      activated: ${{ steps.check_membership.outputs.is_team_member == 'true' }}
+      skipped: ${{ steps.skip.outputs.skip || 'false' }}
+    steps:
+      - id: check_membership
+        run: echo
+      - id: skip
+        if: github.event_name == 'issue_comment'
+        run: echo
+  activation:
+    needs: pre_activation
+    if: needs.pre_activation.outputs.activated == 'true'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  label_check:
+    needs: pre_activation
+    if: needs.pre_activation.outputs.skipped == 'false'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  agent:
+    needs: activation
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  conclusion:
+    needs:
+    - agent
+    - activation
+    if: always() && needs.agent.result != 'skipped'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  bug_only:
+    if: contains(github.event.issue.labels.*.name, 'bug')
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+
+func simulatedJobs(t *testing.T, event TriggerEvent) map[string]JobSimulation {
+	t.Helper()
+	simulation, err := SimulateTrigger([]byte(triggerSimulationLockYAML), event)
+	require.NoError(t, err)
+	jobs := make(map[string]JobSimulation)
+	for _, job := range simulation.Jobs {
+		jobs[job.Job] = job
+	}
+	return jobs
+}
+
+func TestSimulateTriggerOrdersJobsByNeeds(t *testing.T) {
+	simulation, err := SimulateTrigger([]byte(triggerSimulationLockYAML), TriggerEvent{Name: "issues", Payload: map[string]any{"action": "opened"}})
+	require.NoError(t, err)
+	require.True(t, simulation.Triggered)
+
+	var order []string
+	for _, job := range simulation.Jobs {
+		order = append(order, job.Job)
+	}
+	assert.Equal(t, []string{"bug_only", "pre_activation", "activation", "agent", "conclusion", "label_check"}, order)
+}
+
+func TestSimulateTriggerIssueComment(t *testing.T) {
+	jobs := simulatedJobs(t, TriggerEvent{Name: "issue_comment", Payload: map[string]any{
+		"action":  "created",
+		"comment": map[string]any{"body": "/triage please"},
+		"issue":   map[string]any{"labels": []any{map[string]any{"name": "bug"}}},
+	}})
+
+	assert.Equal(t, JobRuns, jobs["pre_activation"].Outcome)
+	assert.Equal(t, []string{"startsWith(github.event.comment.body, '/triage') is true"}, jobs["pre_activation"].Reasons)
+	assert.Equal(t, map[string]string{"activated": "steps.check_membership.outputs.is_team_member", "skipped": "steps.skip.outputs.skip"}, jobs["pre_activation"].Outputs)
+
+	assert.Equal(t, JobDepends, jobs["activation"].Outcome)
+	assert.Equal(t, []string{"needs.pre_activation.outputs.activated"}, jobs["activation"].Runtime)
+
+	assert.Equal(t, JobDepends, jobs["agent"].Outcome)
+	assert.Equal(t, []string{"needs activation, which may be skipped"}, jobs["agent"].Reasons)
+
+	assert.Equal(t, JobDepends, jobs["conclusion"].Outcome)
+	assert.Equal(t, JobRuns, jobs["bug_only"].Outcome)
+}
+
+func TestSimulateTriggerSkippedStepOutputs(t *testing.T) {
+	// The skip step does not run for issues events, so its output is empty and the default applies
+	jobs := simulatedJobs(t, TriggerEvent{Name: "issues", Payload: map[string]any{"action": "opened"}})
+
+	assert.Equal(t, JobRuns, jobs["pre_activation"].Outcome)
+	assert.NotContains(t, jobs["pre_activation"].Outputs, "skipped")
+	assert.Equal(t, JobRuns, jobs["label_check"].Outcome)
+	assert.Equal(t, JobSkipped, jobs["bug_only"].Outcome)
+	assert.Equal(t, []string{"contains(github.event.issue.labels.*.name, 'bug') is false"}, jobs["bug_only"].Reasons)
+}
+
+func TestSimulateTriggerSkippedNeeds(t *testing.T) {
+	jobs := simulatedJobs(t, TriggerEvent{Name: "issue_comment", Payload: map[string]any{
+		"action":  "created",
+		"comment": map[string]any{"body": "thanks!"},
+	}})
+
+	assert.Equal(t, JobSkipped, jobs["pre_activation"].Outcome)
+	assert.Equal(t, JobSkipped, jobs["activation"].Outcome)
+	assert.Equal(t, []string{"needs pre_activation, which is skipped"}, jobs["activation"].Reasons)
+	assert.Equal(t, JobSkipped, jobs["agent"].Outcome)
+	// always() runs the condition, which is false because the agent was skipped
+	assert.Equal(t, JobSkipped, jobs["conclusion"].Outcome)
+	assert.Equal(t, []string{"needs.agent.result != 'skipped' is false"}, jobs["conclusion"].Reasons)
+}
+
+func TestSimulateTriggerNotTriggered(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  TriggerEvent
+		reason string
+	}{
+		{
+			name:   "event not in on section",
+			event:  TriggerEvent{Name: "push", Payload: map[string]any{}},
+			reason: "the workflow has no push trigger",
+		},
+		{
+			name:   "activity type not listed",
+			event:  TriggerEvent{Name: "issues", Payload: map[string]any{"action": "closed"}},
+			reason: `activity type "closed" is not in the issues types [opened]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulation, err := SimulateTrigger([]byte(triggerSimulationLockYAML), tt.event)
+			require.NoError(t, err)
+			assert.False(t, simulation.Triggered)
+			assert.Equal(t, tt.reason, simulation.Reason)
+			for _, job := range simulation.Jobs {
+				assert.Equal(t, JobSkipped, job.Outcome, "job %s", job.Job)
+			}
+		})
+	}
+}
+
+func TestSimulateTriggerCircularNeeds(t *testing.T) {
+	lockYAML := `"on": push
+jobs:
+  a:
+    needs: b
+  b:
+    needs: a
+`
+	_, err := SimulateTrigger([]byte(lockYAML), TriggerEvent{Name: "push"})
+	assert.ErrorContains(t, err, "circular")
+}
@@ -0,0 +1,321 @@
+// This file implements declarative assertions on the compiled output of a workflow.
+//
+// # Workflow Tests
+//
+// A workflow test file lists assertions that a compiled workflow must satisfy, so that platform
+// teams can guard workflows against regressions introduced through imports:
+//
+//	assertions:
+//	  - name: agent cannot write contents
+//	    permission: {job: agent, scope: contents, max: read}
+//	  - network: {only: [defaults, python]}
+//	  - safe-output: {type: create-issue, max: 1}
+//	  - trigger: {event: issues, types: [opened]}
+//	  - lock-file: {not-contains: ["secrets.ADMIN_TOKEN"]}
+//
+// Each assertion checks exactly one aspect of the workflow. Permissions and triggers are read from
+// the lock file, network and safe outputs from the workflow snapshot (see workflow_diff.go).
+
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/goccy/go-yaml"
+)
+
+var workflowAssertionsLog = logger.New("workflow:workflow_assertions")
+
+// WorkflowTestFile is a set of assertions on one compiled workflow
+type WorkflowTestFile struct {
+	Workflow   string              `yaml:"workflow,omitempty"` // Workflow ID; defaults to the test file name
+	Assertions []WorkflowAssertion `yaml:"assertions"`
+}
+
+// WorkflowAssertion is a single check on a compiled workflow. Exactly one kind must be set.
+type WorkflowAssertion struct {
+	Name       string               `yaml:"name,omitempty"`
+	Permission *PermissionAssertion `yaml:"permission,omitempty"`
+	Network    *NetworkAssertion    `yaml:"network,omitempty"`
+	SafeOutput *SafeOutputAssertion `yaml:"safe-output,omitempty"`
+	Trigger    *TriggerAssertion    `yaml:"trigger,omitempty"`
+	LockFile   *LockFileAssertion   `yaml:"lock-file,omitempty"`
+}
+
+// PermissionAssertion bounds the level of a permission scope in a job
+type PermissionAssertion struct {
+	Job   string `yaml:"job,omitempty"` // Defaults to the agent job
+	Scope string `yaml:"scope"`
+	Max   string `yaml:"max"` // none, read or write
+}
+
+// NetworkAssertion checks the network.allowed entries of the workflow
+type NetworkAssertion struct {
+	Only     []string `yaml:"only,omitempty"`     // Exact set of allowed entries
+	Includes []string `yaml:"includes,omitempty"` // Entries that must be allowed
+	Excludes []string `yaml:"excludes,omitempty"` // Entries that must not be allowed
+}
+
+// SafeOutputAssertion checks whether a safe output is enabled and its max
+type SafeOutputAssertion struct {
+	Type    string `yaml:"type"`
+	Enabled *bool  `yaml:"enabled,omitempty"` // Defaults to true
+	Max     *int   `yaml:"max,omitempty"`     // 0 means unlimited
+}
+
+// TriggerAssertion checks that the workflow runs on an event, optionally for activity types
+type TriggerAssertion struct {
+	Event string   `yaml:"event"`
+	Types []string `yaml:"types,omitempty"`
+}
+
+// LockFileAssertion checks the text of the compiled lock file
+type LockFileAssertion struct {
+	Contains    []string `yaml:"contains,omitempty"`
+	NotContains []string `yaml:"not-contains,omitempty"`
+}
+
+// ParseWorkflowTestFile parses and validates a workflow test file. The source is used in error messages.
+func ParseWorkflowTestFile(content []byte, source string) (*WorkflowTestFile, error) {
+	var testFile WorkflowTestFile
+	if err := yaml.UnmarshalWithOptions(content, &testFile, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("invalid test file %s: %s", source, yaml.FormatError(err, false, false))
+	}
+	if len(testFile.Assertions) == 0 {
+		return nil, fmt.Errorf("invalid test file %s: no assertions", source)
+	}
+	for i := range testFile.Assertions {
+		if err := testFile.Assertions[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid test file %s: assertion %d: %w", source, i+1, err)
+		}
+	}
+	workflowAssertionsLog.Printf("Parsed test file %s with %d assertions", source, len(testFile.Assertions))
+	return &testFile, nil
+}
+
+// validate checks that exactly one kind of check is set and that its fields are valid
+func (a *WorkflowAssertion) validate() error {
+	kinds := 0
+	for _, set := range []bool{a.Permission != nil, a.Network != nil, a.SafeOutput != nil, a.Trigger != nil, a.LockFile != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("must set exactly one of permission, network, safe-output, trigger or lock-file")
+	}
+
+	switch {
+	case a.Permission != nil:
+		if convertStringToPermissionScope(a.Permission.Scope) == "" {
+			return fmt.Errorf("unknown permission scope %q", a.Permission.Scope)
+		}
+		switch PermissionLevel(a.Permission.Max) {
+		case PermissionNone, PermissionRead, PermissionWrite:
+		default:
+			return fmt.Errorf("permission max must be none, read or write, got %q", a.Permission.Max)
+		}
+	case a.Network != nil:
+		if a.Network.Only == nil && len(a.Network.Includes) == 0 && len(a.Network.Excludes) == 0 {
+			return fmt.Errorf("network must set only, includes or excludes")
+		}
+	case a.SafeOutput != nil:
+		if a.SafeOutput.Type == "" {
+			return fmt.Errorf("safe-output type is required")
+		}
+		if a.SafeOutput.Max != nil && *a.SafeOutput.Max < 0 {
+			return fmt.Errorf("safe-output max must not be negative")
+		}
+	case a.Trigger != nil:
+		if a.Trigger.Event == "" {
+			return fmt.Errorf("trigger event is required")
+		}
+	case a.LockFile != nil:
+		if len(a.LockFile.Contains) == 0 && len(a.LockFile.NotContains) == 0 {
+			return fmt.Errorf("lock-file must set contains or not-contains")
+		}
+	}
+	return nil
+}
+
+// Description returns the name of the assertion, or a description of what it checks
+func (a *WorkflowAssertion) Description() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	switch {
+	case a.Permission != nil:
+		return fmt.Sprintf("job %s has at most %s: %s", a.Permission.job(), a.Permission.Scope, a.Permission.Max)
+	case a.Network != nil:
+		var parts []string
+		if a.Network.Only != nil {
+			parts = append(parts, "allows only "+strings.Join(a.Network.Only, ", "))
+		}
+		if len(a.Network.Includes) > 0 {
+			parts = append(parts, "allows "+strings.Join(a.Network.Includes, ", "))
+		}
+		if len(a.Network.Excludes) > 0 {
+			parts = append(parts, "does not allow "+strings.Join(a.Network.Excludes, ", "))
+		}
+		return "network " + strings.Join(parts, " and ")
+	case a.SafeOutput != nil:
+		if !a.SafeOutput.enabled() {
+			return fmt.Sprintf("safe output %s is disabled", a.SafeOutput.Type)
+		}
+		if a.SafeOutput.Max != nil {
+			return fmt.Sprintf("safe output %s max is %d", a.SafeOutput.Type, *a.SafeOutput.Max)
+		}
+		return fmt.Sprintf("safe output %s is enabled", a.SafeOutput.Type)
+	case a.Trigger != nil:
+		if len(a.Trigger.Types) > 0 {
+			return fmt.Sprintf("trigger includes %s.%s", a.Trigger.Event, strings.Join(a.Trigger.Types, ", "))
+		}
+		return "trigger includes " + a.Trigger.Event
+	case a.LockFile != nil:
+		var parts []string
+		if len(a.LockFile.Contains) > 0 {
+			parts = append(parts, "contains "+strings.Join(a.LockFile.Contains, ", "))
+		}
+		if len(a.LockFile.NotContains) > 0 {
+			parts = append(parts, "does not contain "+strings.Join(a.LockFile.NotContains, ", "))
+		}
+		return "lock file " + strings.Join(parts, " and ")
+	}
+	return ""
+}
+
+// Evaluate checks the assertion against the snapshot and lock file of a compiled workflow. The
+// returned error describes why the assertion failed.
+func (a *WorkflowAssertion) Evaluate(snapshot *WorkflowSnapshot, lockYAML string) error {
+	switch {
+	case a.Permission != nil:
+		return a.Permission.evaluate(snapshot)
+	case a.Network != nil:
+		return a.Network.evaluate(snapshot)
+	case a.SafeOutput != nil:
+		return a.SafeOutput.evaluate(snapshot)
+	case a.Trigger != nil:
+		return a.Trigger.evaluate(snapshot)
+	case a.LockFile != nil:
+		return a.LockFile.evaluate(lockYAML)
+	}
+	return nil
+}
+
+func (p *PermissionAssertion) job() string {
+	if p.Job == "" {
+		return "agent"
+	}
+	return p.Job
+}
+
+func (p *PermissionAssertion) evaluate(snapshot *WorkflowSnapshot) error {
+	permissions, ok := snapshot.JobPermissions[p.job()]
+	if !ok {
+		return fmt.Errorf("job %s not found in the compiled workflow", p.job())
+	}
+	scope := convertStringToPermissionScope(p.Scope)
+	granted := permissions[scope]
+	if permissionRank(granted) > permissionRank(PermissionLevel(p.Max)) {
+		return fmt.Errorf("job %s has %s: %s", p.job(), p.Scope, granted)
+	}
+	return nil
+}
+
+func (n *NetworkAssertion) evaluate(snapshot *WorkflowSnapshot) error {
+	var problems []string
+	if n.Only != nil {
+		extra := slices.DeleteFunc(slices.Clone(snapshot.Network), func(entry string) bool { return slices.Contains(n.Only, entry) })
+		missing := slices.DeleteFunc(slices.Clone(n.Only), func(entry string) bool { return slices.Contains(snapshot.Network, entry) })
+		if len(extra) > 0 {
+			problems = append(problems, "also allows "+strings.Join(extra, ", "))
+		}
+		if len(missing) > 0 {
+			problems = append(problems, "does not allow "+strings.Join(missing, ", "))
+		}
+	}
+	if missing := slices.DeleteFunc(slices.Clone(n.Includes), func(entry string) bool { return slices.Contains(snapshot.Network, entry) }); len(missing) > 0 {
+		problems = append(problems, "does not allow "+strings.Join(missing, ", "))
+	}
+	if present := slices.DeleteFunc(slices.Clone(n.Excludes), func(entry string) bool { return !slices.Contains(snapshot.Network, entry) }); len(present) > 0 {
+		problems = append(problems, "allows "+strings.Join(present, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("network %s (allowed: %s)", strings.Join(problems, " and "), strings.Join(snapshot.Network, ", "))
+	}
+	return nil
+}
+
+func (s *SafeOutputAssertion) enabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+func (s *SafeOutputAssertion) evaluate(snapshot *WorkflowSnapshot) error {
+	maxValue, enabled := snapshot.SafeOutputs[strings.ReplaceAll(s.Type, "-", "_")]
+	if !s.enabled() {
+		if enabled {
+			return fmt.Errorf("safe output %s is enabled", s.Type)
+		}
+		return nil
+	}
+	if !enabled {
+		return fmt.Errorf("safe output %s is not enabled", s.Type)
+	}
+	if s.Max != nil && maxValue != *s.Max {
+		if maxValue == 0 {
+			return fmt.Errorf("safe output %s max is unlimited", s.Type)
+		}
+		return fmt.Errorf("safe output %s max is %d", s.Type, maxValue)
+	}
+	return nil
+}
+
+func (t *TriggerAssertion) evaluate(snapshot *WorkflowSnapshot) error {
+	config, ok := snapshot.Triggers[t.Event]
+	if !ok {
+		events := make([]string, 0, len(snapshot.Triggers))
+		for event := range snapshot.Triggers {
+			events = append(events, event)
+		}
+		slices.Sort(events)
+		return fmt.Errorf("workflow does not run on %s (triggers: %s)", t.Event, strings.Join(events, ", "))
+	}
+
+	// Events without activity types run for all of them
+	eventConfig, _ := config.(map[string]any)
+	configuredTypes, hasTypes := eventConfig["types"].([]any)
+	if len(t.Types) == 0 || !hasTypes {
+		return nil
+	}
+	var missing []string
+	for _, activityType := range t.Types {
+		if !slices.Contains(configuredTypes, any(activityType)) {
+			missing = append(missing, activityType)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s trigger does not include types %s", t.Event, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (l *LockFileAssertion) evaluate(lockYAML string) error {
+	var problems []string
+	for _, text := range l.Contains {
+		if !strings.Contains(lockYAML, text) {
+			problems = append(problems, fmt.Sprintf("does not contain %q", text))
+		}
+	}
+	for _, text := range l.NotContains {
+		if strings.Contains(lockYAML, text) {
+			problems = append(problems, fmt.Sprintf("contains %q", text))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("lock file %s", strings.Join(problems, " and "))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkflowTestFile(t *testing.T) {
+	testFile, err := ParseWorkflowTestFile([]byte(`workflow: triage
+assertions:
+  - name: agent cannot write contents
+    permission: {scope: contents, max: read}
+  - safe-output: {type: create-issue, max: 1}
+`), "triage.test.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "triage", testFile.Workflow)
+	require.Len(t, testFile.Assertions, 2)
+	assert.Equal(t, "agent cannot write contents", testFile.Assertions[0].Description())
+	assert.Equal(t, "safe output create-issue max is 1", testFile.Assertions[1].Description())
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "no assertions", content: "workflow: triage\n", wantErr: "no assertions"},
+		{name: "unknown field", content: "assertions:\n  - permissions: {scope: contents, max: read}\n", wantErr: "unknown field"},
+		{name: "two kinds", content: "assertions:\n  - trigger: {event: push}\n    lock-file: {contains: [x]}\n", wantErr: "exactly one of"},
+		{name: "unknown scope", content: "assertions:\n  - permission: {scope: code, max: read}\n", wantErr: `unknown permission scope "code"`},
+		{name: "invalid level", content: "assertions:\n  - permission: {scope: issues, max: admin}\n", wantErr: "must be none, read or write"},
+		{name: "empty network", content: "assertions:\n  - network: {}\n", wantErr: "only, includes or excludes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWorkflowTestFile([]byte(tt.content), "test.yml")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestWorkflowAssertionEvaluate(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened, reopened]
+permissions:
+  contents: read
+  issues: read
+network:
+  allowed: [defaults, python]
+safe-outputs:
+  create-issue:
+  add-comment:
+    max: 3
+---
+
+# Triage
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	compiler.SetQuiet(true)
+	data, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+	snapshot, err := NewWorkflowSnapshot(data, lockYAML)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "permission within bound", content: "permission: {scope: contents, max: read}"},
+		{name: "permission above bound", content: "permission: {scope: issues, max: none}", wantErr: "job agent has issues: read"},
+		{name: "unknown job", content: "permission: {job: deploy, scope: issues, max: none}", wantErr: "job deploy not found"},
+		{name: "network only", content: "network: {only: [defaults, python]}"},
+		{name: "network only mismatch", content: "network: {only: [defaults]}", wantErr: "network also allows python"},
+		{name: "network excludes", content: "network: {excludes: [node], includes: [python]}"},
+		{name: "network excludes allowed entry", content: "network: {excludes: [python]}", wantErr: "network allows python"},
+		{name: "safe output default max", content: "safe-output: {type: create-issue, max: 1}"},
+		{name: "safe output max mismatch", content: "safe-output: {type: add_comment, max: 1}", wantErr: "safe output add_comment max is 3"},
+		{name: "safe output not enabled", content: "safe-output: {type: create-pull-request}", wantErr: "is not enabled"},
+		{name: "safe output disabled", content: "safe-output: {type: create-pull-request, enabled: false}"},
+		{name: "trigger with type", content: "trigger: {event: issues, types: [opened]}"},
+		{name: "trigger missing type", content: "trigger: {event: issues, types: [closed]}", wantErr: "does not include types closed"},
+		{name: "trigger missing event", content: "trigger: {event: push}", wantErr: "workflow does not run on push"},
+		{name: "lock file", content: "lock-file: {not-contains: [secrets.ADMIN_TOKEN], contains: [\"issues:\"]}"},
+		{name: "lock file secret", content: "lock-file: {not-contains: [secrets.GITHUB_TOKEN]}", wantErr: `lock file contains "secrets.GITHUB_TOKEN"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile, err := ParseWorkflowTestFile([]byte("assertions:\n  - "+tt.content+"\n"), "test.yml")
+			require.NoError(t, err)
+			err = testFile.Assertions[0].Evaluate(snapshot, lockYAML)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
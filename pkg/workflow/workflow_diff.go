@@ -0,0 +1,333 @@
+// This file compares two compiled versions of a workflow and reports the changes that matter for a
+// security review: permission escalations per job, new network domains, newly enabled safe outputs
+// or higher limits, new secrets, new MCP servers and tools, and trigger changes.
+//
+// A snapshot is taken from the parsed workflow data and the generated lock file. Permissions and
+// triggers come from the lock file, since that is what GitHub Actions runs; network, tools and safe
+// outputs come from the workflow data, which keeps the names the author wrote.
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/goccy/go-yaml"
+)
+
+var workflowDiffLog = logger.New("workflow:workflow_diff")
+
+// WorkflowSnapshot is the security-relevant surface of a compiled workflow
+type WorkflowSnapshot struct {
+	Triggers       map[string]any                                 // Events of the lock file's on: section
+	JobPermissions map[string]map[PermissionScope]PermissionLevel // Effective permissions of each job
+	Network        []string                                       // network.allowed entries (ecosystems or domains)
+	SafeOutputs    map[string]int                                 // Enabled safe output types and their max (0 when unlimited)
+	Secrets        []string                                       // Secrets referenced by the lock file
+	Tools          map[string]ToolSnapshot                        // Enabled tools and MCP servers
+}
+
+// ToolSnapshot describes an enabled tool or MCP server
+type ToolSnapshot struct {
+	MCP     bool
+	Allowed []string // Allowed tools or commands; "*" when all are allowed
+}
+
+// PermissionEscalation is a permission scope that a job gained or raised
+type PermissionEscalation struct {
+	Job   string `json:"job"`
+	Scope string `json:"scope"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// SafeOutputChange is a safe output that was enabled or whose max was raised
+type SafeOutputChange struct {
+	Type   string `json:"type"`
+	OldMax *int   `json:"old_max,omitempty"` // nil when the safe output is newly enabled
+	NewMax int    `json:"new_max"`           // 0 when unlimited
+}
+
+// ToolChange is a tool or MCP server that was added or gained allowed tools
+type ToolChange struct {
+	Tool  string   `json:"tool"`
+	MCP   bool     `json:"mcp"`
+	New   bool     `json:"new"`
+	Added []string `json:"added,omitempty"`
+}
+
+// TriggerChange is an event that was added, removed or reconfigured
+type TriggerChange struct {
+	Event  string `json:"event"`
+	Change string `json:"change"` // added, removed or modified
+}
+
+// WorkflowDiff is the security-focused difference between two versions of a workflow
+type WorkflowDiff struct {
+	Workflow              string                 `json:"workflow"`
+	Status                string                 `json:"status"` // added, removed, modified or unchanged
+	PermissionEscalations []PermissionEscalation `json:"permission_escalations,omitempty"`
+	AddedDomains          []string               `json:"added_domains,omitempty"`
+	RemovedDomains        []string               `json:"removed_domains,omitempty"`
+	SafeOutputs           []SafeOutputChange     `json:"safe_outputs,omitempty"`
+	AddedSecrets          []string               `json:"added_secrets,omitempty"`
+	Tools                 []ToolChange           `json:"tools,omitempty"`
+	Triggers              []TriggerChange        `json:"triggers,omitempty"`
+}
+
+// HasChanges returns true if the diff reports any change
+func (d *WorkflowDiff) HasChanges() bool {
+	return d.Status != "unchanged"
+}
+
+// NewWorkflowSnapshot builds the snapshot of a workflow from its parsed data and compiled YAML
+func NewWorkflowSnapshot(data *WorkflowData, lockYAML string) (*WorkflowSnapshot, error) {
+	var lock struct {
+		On          any `yaml:"on"`
+		Permissions any `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Permissions any `yaml:"permissions"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(lockYAML), &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse compiled workflow: %w", err)
+	}
+
+	snapshot := &WorkflowSnapshot{
+		Triggers:       normalizeTriggers(lock.On),
+		JobPermissions: make(map[string]map[PermissionScope]PermissionLevel),
+		SafeOutputs:    make(map[string]int),
+		Secrets:        CollectSecretReferences(lockYAML),
+		Tools:          make(map[string]ToolSnapshot),
+	}
+
+	for name, job := range lock.Jobs {
+		permissions := job.Permissions
+		if permissions == nil {
+			// Jobs without permissions inherit the workflow-level permissions
+			permissions = lock.Permissions
+		}
+		snapshot.JobPermissions[name] = effectivePermissions(permissions)
+	}
+
+	if data.NetworkPermissions == nil {
+		snapshot.Network = []string{"defaults"}
+	} else {
+		snapshot.Network = slices.Clone(data.NetworkPermissions.Allowed)
+	}
+	sort.Strings(snapshot.Network)
+
+	if config := generateSafeOutputsConfig(data); config != "" {
+		var safeOutputs map[string]any
+		if err := json.Unmarshal([]byte(config), &safeOutputs); err != nil {
+			return nil, fmt.Errorf("failed to parse safe outputs configuration: %w", err)
+		}
+		for outputType, value := range safeOutputs {
+			outputConfig, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			maxValue, _ := outputConfig["max"].(float64)
+			snapshot.SafeOutputs[outputType] = int(maxValue)
+		}
+	}
+
+	for name, value := range data.Tools {
+		if value == false {
+			continue
+		}
+		mcp := name == "github" || name == "playwright" || name == "serena" || name == "cache-memory" || name == "agentic-workflows"
+		if config, ok := value.(map[string]any); ok && !mcp {
+			mcp, _ = hasMCPConfig(config)
+		}
+		snapshot.Tools[name] = ToolSnapshot{MCP: mcp, Allowed: toolAllowedEntries(value)}
+	}
+
+	workflowDiffLog.Printf("Snapshot: %d triggers, %d jobs, %d safe outputs, %d secrets, %d tools",
+		len(snapshot.Triggers), len(snapshot.JobPermissions), len(snapshot.SafeOutputs), len(snapshot.Secrets), len(snapshot.Tools))
+	return snapshot, nil
+}
+
+// normalizeTriggers converts the on: section (a string, list or map) into a map keyed by event
+func normalizeTriggers(on any) map[string]any {
+	triggers := make(map[string]any)
+	switch v := on.(type) {
+	case string:
+		triggers[v] = nil
+	case []any:
+		for _, event := range v {
+			if name, ok := event.(string); ok {
+				triggers[name] = nil
+			}
+		}
+	case map[string]any:
+		maps.Copy(triggers, v)
+	}
+	return triggers
+}
+
+// effectivePermissions returns the granted (read or write) level of each permission scope
+func effectivePermissions(value any) map[PermissionScope]PermissionLevel {
+	return grantedPermissionLevels(NewPermissionsParserFromValue(value).ToPermissions())
+}
+
+// grantedPermissionLevels returns the scopes granted at read or write level
+func grantedPermissionLevels(permissions *Permissions) map[PermissionScope]PermissionLevel {
+	levels := make(map[PermissionScope]PermissionLevel)
+	for _, scope := range GetAllPermissionScopes() {
+		if level, ok := permissions.Get(scope); ok && level != PermissionNone {
+			levels[scope] = level
+		}
+	}
+	return levels
+}
+
+// permissionRank orders permission levels from none to write
+func permissionRank(level PermissionLevel) int {
+	switch level {
+	case PermissionRead:
+		return 1
+	case PermissionWrite:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// toolAllowedEntries returns the allowed tools, commands or toolsets of a tool configuration
+func toolAllowedEntries(value any) []string {
+	var entries []string
+	switch v := value.(type) {
+	case []any:
+		for _, entry := range v {
+			entries = append(entries, fmt.Sprint(entry))
+		}
+	case map[string]any:
+		if allowed, ok := v["allowed"].([]any); ok {
+			for _, entry := range allowed {
+				entries = append(entries, fmt.Sprint(entry))
+			}
+		}
+		if len(entries) == 0 {
+			entries = append(entries, "*")
+		}
+		if toolsets, ok := v["toolsets"].([]any); ok {
+			for _, toolset := range toolsets {
+				entries = append(entries, fmt.Sprintf("toolset:%v", toolset))
+			}
+		}
+	default:
+		entries = append(entries, "*")
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// DiffWorkflowSnapshots compares the base and head snapshots of a workflow. A nil base means the
+// workflow was added and a nil head means it was removed.
+func DiffWorkflowSnapshots(name string, base, head *WorkflowSnapshot) *WorkflowDiff {
+	diff := &WorkflowDiff{Workflow: name, Status: "modified"}
+	if head == nil {
+		diff.Status = "removed"
+		return diff
+	}
+	if base == nil {
+		diff.Status = "added"
+		base = &WorkflowSnapshot{}
+	}
+
+	for _, job := range slices.Sorted(maps.Keys(head.JobPermissions)) {
+		for _, scope := range GetAllPermissionScopes() {
+			to := head.JobPermissions[job][scope]
+			from := base.JobPermissions[job][scope]
+			if permissionRank(to) > permissionRank(from) {
+				if from == "" {
+					from = PermissionNone
+				}
+				diff.PermissionEscalations = append(diff.PermissionEscalations, PermissionEscalation{
+					Job: job, Scope: string(scope), From: string(from), To: string(to),
+				})
+			}
+		}
+	}
+
+	diff.AddedDomains = missingFrom(head.Network, base.Network)
+	diff.RemovedDomains = missingFrom(base.Network, head.Network)
+	if diff.Status == "added" {
+		diff.RemovedDomains = nil
+	}
+
+	for _, outputType := range slices.Sorted(maps.Keys(head.SafeOutputs)) {
+		newMax := head.SafeOutputs[outputType]
+		oldMax, existed := base.SafeOutputs[outputType]
+		switch {
+		case !existed:
+			diff.SafeOutputs = append(diff.SafeOutputs, SafeOutputChange{Type: outputType, NewMax: newMax})
+		case oldMax != 0 && (newMax == 0 || newMax > oldMax):
+			diff.SafeOutputs = append(diff.SafeOutputs, SafeOutputChange{Type: outputType, OldMax: &oldMax, NewMax: newMax})
+		}
+	}
+
+	diff.AddedSecrets = missingFrom(head.Secrets, base.Secrets)
+
+	for _, tool := range slices.Sorted(maps.Keys(head.Tools)) {
+		headTool := head.Tools[tool]
+		baseTool, existed := base.Tools[tool]
+		if !existed {
+			diff.Tools = append(diff.Tools, ToolChange{Tool: tool, MCP: headTool.MCP, New: true, Added: headTool.Allowed})
+			continue
+		}
+		added := missingFrom(headTool.Allowed, baseTool.Allowed)
+		if slices.Contains(baseTool.Allowed, "*") {
+			// Everything was already allowed; only added toolsets widen access
+			added = slices.DeleteFunc(added, func(entry string) bool { return !strings.HasPrefix(entry, "toolset:") })
+		}
+		if len(added) > 0 {
+			diff.Tools = append(diff.Tools, ToolChange{Tool: tool, MCP: headTool.MCP, Added: added})
+		}
+	}
+
+	for _, event := range slices.Sorted(maps.Keys(head.Triggers)) {
+		baseConfig, existed := base.Triggers[event]
+		if !existed {
+			diff.Triggers = append(diff.Triggers, TriggerChange{Event: event, Change: "added"})
+		} else if !reflect.DeepEqual(baseConfig, head.Triggers[event]) {
+			diff.Triggers = append(diff.Triggers, TriggerChange{Event: event, Change: "modified"})
+		}
+	}
+	if diff.Status != "added" {
+		for _, event := range slices.Sorted(maps.Keys(base.Triggers)) {
+			if _, exists := head.Triggers[event]; !exists {
+				diff.Triggers = append(diff.Triggers, TriggerChange{Event: event, Change: "removed"})
+			}
+		}
+	}
+
+	if diff.Status == "modified" && len(diff.PermissionEscalations) == 0 && len(diff.AddedDomains) == 0 &&
+		len(diff.RemovedDomains) == 0 && len(diff.SafeOutputs) == 0 && len(diff.AddedSecrets) == 0 &&
+		len(diff.Tools) == 0 && len(diff.Triggers) == 0 {
+		diff.Status = "unchanged"
+	}
+
+	workflowDiffLog.Printf("Diff of %s: status=%s, escalations=%d, domains=+%d/-%d, safe outputs=%d, secrets=%d, tools=%d, triggers=%d",
+		name, diff.Status, len(diff.PermissionEscalations), len(diff.AddedDomains), len(diff.RemovedDomains),
+		len(diff.SafeOutputs), len(diff.AddedSecrets), len(diff.Tools), len(diff.Triggers))
+	return diff
+}
+
+// missingFrom returns the values of a that are not in b
+func missingFrom(a, b []string) []string {
+	var missing []string
+	for _, value := range a {
+		if !slices.Contains(b, value) {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,122 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffWorkflowSnapshots(t *testing.T) {
+	one := 1
+	base := &WorkflowSnapshot{
+		Triggers: map[string]any{"issues": map[string]any{"types": []any{"opened"}}, "schedule": nil},
+		JobPermissions: map[string]map[PermissionScope]PermissionLevel{
+			"agent":        {PermissionContents: PermissionRead},
+			"safe_outputs": {PermissionIssues: PermissionWrite},
+		},
+		Network:     []string{"defaults", "old.example.com"},
+		SafeOutputs: map[string]int{"add_comment": 1, "create_issue": 0},
+		Secrets:     []string{"GITHUB_TOKEN"},
+		Tools:       map[string]ToolSnapshot{"github": {MCP: true, Allowed: []string{"*"}}, "bash": {Allowed: []string{"ls"}}},
+	}
+	head := &WorkflowSnapshot{
+		Triggers: map[string]any{"issues": map[string]any{"types": []any{"opened", "edited"}}, "pull_request_target": nil},
+		JobPermissions: map[string]map[PermissionScope]PermissionLevel{
+			"agent":        {PermissionContents: PermissionWrite, PermissionIssues: PermissionRead},
+			"safe_outputs": {PermissionIssues: PermissionRead},
+		},
+		Network:     []string{"api.example.com", "defaults"},
+		SafeOutputs: map[string]int{"add_comment": 3, "create_issue": 5, "create_pull_request": 1},
+		Secrets:     []string{"GITHUB_TOKEN", "DEPLOY_KEY"},
+		Tools: map[string]ToolSnapshot{
+			"github": {MCP: true, Allowed: []string{"*", "toolset:actions"}},
+			"bash":   {Allowed: []string{"git push", "ls"}},
+			"notion": {MCP: true, Allowed: []string{"search"}},
+		},
+	}
+
+	diff := DiffWorkflowSnapshots("triage", base, head)
+
+	assert.Equal(t, "modified", diff.Status)
+	assert.Equal(t, []PermissionEscalation{
+		{Job: "agent", Scope: "contents", From: "read", To: "write"},
+		{Job: "agent", Scope: "issues", From: "none", To: "read"},
+	}, diff.PermissionEscalations, "only raised permissions should be reported")
+	assert.Equal(t, []string{"api.example.com"}, diff.AddedDomains)
+	assert.Equal(t, []string{"old.example.com"}, diff.RemovedDomains)
+	assert.Equal(t, []SafeOutputChange{
+		{Type: "add_comment", OldMax: &one, NewMax: 3},
+		{Type: "create_pull_request", NewMax: 1},
+	}, diff.SafeOutputs, "create_issue was already unlimited")
+	assert.Equal(t, []string{"DEPLOY_KEY"}, diff.AddedSecrets)
+	assert.Equal(t, []ToolChange{
+		{Tool: "bash", Added: []string{"git push"}},
+		{Tool: "github", MCP: true, Added: []string{"toolset:actions"}},
+		{Tool: "notion", MCP: true, New: true, Added: []string{"search"}},
+	}, diff.Tools)
+	assert.Equal(t, []TriggerChange{
+		{Event: "issues", Change: "modified"},
+		{Event: "pull_request_target", Change: "added"},
+		{Event: "schedule", Change: "removed"},
+	}, diff.Triggers)
+
+	assert.False(t, DiffWorkflowSnapshots("triage", base, base).HasChanges())
+	assert.Equal(t, "removed", DiffWorkflowSnapshots("triage", base, nil).Status)
+
+	added := DiffWorkflowSnapshots("triage", nil, head)
+	assert.Equal(t, "added", added.Status)
+	assert.Len(t, added.Triggers, 2)
+	assert.Empty(t, added.RemovedDomains)
+}
+
+func TestNewWorkflowSnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	workflowPath := filepath.Join(workflowsDir, "triage.md")
+	require.NoError(t, os.WriteFile(workflowPath, []byte(`---
+on:
+  issues:
+    types: [opened]
+permissions:
+  contents: read
+  issues: read
+network:
+  allowed:
+    - defaults
+    - api.example.com
+tools:
+  notion:
+    command: npx
+    args: ["notion-mcp"]
+    allowed: [search]
+safe-outputs:
+  add-comment:
+    max: 2
+  github-token: ${{ secrets.TRIAGE_TOKEN }}
+---
+
+# Triage
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	data, lockYAML, err := compiler.CompileWorkflowToYAML(workflowPath)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(workflowsDir, "triage.lock.yml"))
+	assert.True(t, os.IsNotExist(err), "CompileWorkflowToYAML should not write the lock file")
+
+	snapshot, err := NewWorkflowSnapshot(data, lockYAML)
+	require.NoError(t, err)
+
+	assert.Contains(t, snapshot.Triggers, "issues")
+	assert.Equal(t, PermissionRead, snapshot.JobPermissions["agent"][PermissionIssues])
+	assert.Equal(t, []string{"api.example.com", "defaults"}, snapshot.Network)
+	assert.Equal(t, 2, snapshot.SafeOutputs["add_comment"])
+	assert.Contains(t, snapshot.Secrets, "TRIAGE_TOKEN")
+	assert.Equal(t, ToolSnapshot{MCP: true, Allowed: []string{"search"}}, snapshot.Tools["notion"])
+}
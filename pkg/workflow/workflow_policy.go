@@ -0,0 +1,379 @@
+// This file implements the organization policy file enforced at compile time.
+//
+// # Workflow Policy
+//
+// Strict mode (see strict_mode_validation.go) hard-codes one set of security constraints. A policy
+// file lets a security team declare its own constraints in .github/aw/policy.yml:
+//
+//	extends: acme-org/security/policies/agentic.yml@main
+//	engines: [copilot, claude]
+//	models: [gpt-5, claude-sonnet-4]
+//	max-permissions:
+//	  all: read
+//	  pull-requests: none
+//	network:
+//	  ecosystems: [defaults, node]
+//	  domains: ["*.acme.com"]
+//	require-threat-detection: true
+//	forbidden-safe-outputs: [create-pull-request]
+//	roles: [admin, maintainer]
+//	allow-sandbox-disabled: false
+//
+// A policy can extend a local or remote (owner/repo/path@ref) policy; fields set in the extending
+// policy replace those of the extended one. Every workflow compiled in the repository is checked
+// against the policy, and violations are reported with the position of the offending frontmatter
+// field.
+
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-aw/pkg/console"
+	"github.com/github/gh-aw/pkg/constants"
+	"github.com/github/gh-aw/pkg/logger"
+	"github.com/github/gh-aw/pkg/parser"
+	"github.com/goccy/go-yaml"
+)
+
+var workflowPolicyLog = logger.New("workflow:workflow_policy")
+
+// maxPolicyExtendsDepth bounds the chain of extended policies
+const maxPolicyExtendsDepth = 5
+
+// WorkflowPolicy is an organization policy that every workflow of a repository must satisfy.
+// Unset fields don't restrict workflows.
+type WorkflowPolicy struct {
+	Extends                string            `yaml:"extends,omitempty"`                  // Local path or owner/repo/path@ref of a base policy
+	Engines                []string          `yaml:"engines,omitempty"`                  // Allowed engine IDs
+	Models                 []string          `yaml:"models,omitempty"`                   // Allowed values of engine.model
+	MaxPermissions         map[string]string `yaml:"max-permissions,omitempty"`          // Maximum level per scope; "all" applies to unlisted scopes
+	Network                *PolicyNetwork    `yaml:"network,omitempty"`                  // Allowed network.allowed entries
+	RequireThreatDetection *bool             `yaml:"require-threat-detection,omitempty"` // Safe outputs must run threat detection
+	ForbiddenSafeOutputs   []string          `yaml:"forbidden-safe-outputs,omitempty"`   // Safe output types that may not be enabled
+	Roles                  []string          `yaml:"roles,omitempty"`                    // Roles that workflows may allow to trigger them
+	AllowSandboxDisabled   *bool             `yaml:"allow-sandbox-disabled,omitempty"`   // Whether sandbox.agent: false is allowed
+	Source                 string            `yaml:"-"`                                  // File the policy was loaded from
+}
+
+// PolicyNetwork restricts the entries of network.allowed
+type PolicyNetwork struct {
+	Ecosystems []string `yaml:"ecosystems,omitempty"` // Allowed ecosystem identifiers (e.g. defaults, python)
+	Domains    []string `yaml:"domains,omitempty"`    // Allowed domains; supports *.example.com wildcards
+}
+
+// PolicyViolation is a workflow setting that the policy doesn't allow
+type PolicyViolation struct {
+	Rule    string `json:"rule"` // Policy field that was violated
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// PolicyViolationError reports the policy violations of a workflow
+type PolicyViolationError struct {
+	File       string
+	Violations []PolicyViolation
+	context    [][]string // Source line of each violation
+}
+
+// Error implements the error interface and formats each violation like a compiler error
+func (e *PolicyViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for i := range e.Violations {
+		messages = append(messages, e.FormatViolation(i))
+	}
+	return strings.Join(messages, "\n")
+}
+
+// FormatViolation formats the i-th violation like a compiler error, with its position and source line
+func (e *PolicyViolationError) FormatViolation(i int) string {
+	violation := e.Violations[i]
+	compilerErr := console.CompilerError{
+		Position: console.ErrorPosition{File: e.File, Line: violation.Line, Column: violation.Column},
+		Type:     "error",
+		Message:  violation.Message,
+	}
+	if i < len(e.context) {
+		compilerErr.Context = e.context[i]
+	}
+	return console.FormatError(compilerErr)
+}
+
+// LoadWorkflowPolicy reads a policy file and the policies it extends
+func LoadWorkflowPolicy(path string, cache *parser.ImportCache) (*WorkflowPolicy, error) {
+	return loadWorkflowPolicy(path, cache, nil)
+}
+
+func loadWorkflowPolicy(path string, cache *parser.ImportCache, visited []string) (*WorkflowPolicy, error) {
+	if slices.Contains(visited, path) {
+		return nil, fmt.Errorf("policy %s extends itself", path)
+	}
+	if len(visited) >= maxPolicyExtendsDepth {
+		return nil, fmt.Errorf("policy %s exceeds the maximum extends depth of %d", path, maxPolicyExtendsDepth)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	policy, err := ParseWorkflowPolicy(content, path)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Extends == "" {
+		return policy, nil
+	}
+
+	basePath, err := parser.ResolveIncludePath(policy.Extends, filepath.Dir(path), cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve extends %q of policy %s: %w", policy.Extends, path, err)
+	}
+	base, err := loadWorkflowPolicy(basePath, cache, append(visited, path))
+	if err != nil {
+		return nil, err
+	}
+	workflowPolicyLog.Printf("Policy %s extends %s", path, policy.Extends)
+	return policy.mergeOnto(base), nil
+}
+
+// ParseWorkflowPolicy parses and validates a policy file. The source is used in error messages.
+func ParseWorkflowPolicy(content []byte, source string) (*WorkflowPolicy, error) {
+	var policy WorkflowPolicy
+	if err := yaml.UnmarshalWithOptions(content, &policy, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %s", source, yaml.FormatError(err, false, false))
+	}
+	policy.Source = source
+
+	for scope, level := range policy.MaxPermissions {
+		if scope != "all" && convertStringToPermissionScope(scope) == "" {
+			return nil, fmt.Errorf("invalid policy %s: unknown permission scope %q in max-permissions", source, scope)
+		}
+		if level != string(PermissionRead) && level != string(PermissionWrite) && level != string(PermissionNone) {
+			return nil, fmt.Errorf("invalid policy %s: max-permissions.%s must be read, write or none, got %q", source, scope, level)
+		}
+	}
+	for i, outputType := range policy.ForbiddenSafeOutputs {
+		policy.ForbiddenSafeOutputs[i] = strings.ReplaceAll(outputType, "_", "-")
+	}
+
+	workflowPolicyLog.Printf("Parsed policy %s", source)
+	return &policy, nil
+}
+
+// mergeOnto returns the base policy with the fields set in p replacing those of the base
+func (p *WorkflowPolicy) mergeOnto(base *WorkflowPolicy) *WorkflowPolicy {
+	merged := *base
+	merged.Extends = p.Extends
+	merged.Source = p.Source
+	if p.Engines != nil {
+		merged.Engines = p.Engines
+	}
+	if p.Models != nil {
+		merged.Models = p.Models
+	}
+	if p.MaxPermissions != nil {
+		merged.MaxPermissions = p.MaxPermissions
+	}
+	if p.Network != nil {
+		merged.Network = p.Network
+	}
+	if p.RequireThreatDetection != nil {
+		merged.RequireThreatDetection = p.RequireThreatDetection
+	}
+	if p.ForbiddenSafeOutputs != nil {
+		merged.ForbiddenSafeOutputs = p.ForbiddenSafeOutputs
+	}
+	if p.Roles != nil {
+		merged.Roles = p.Roles
+	}
+	if p.AllowSandboxDisabled != nil {
+		merged.AllowSandboxDisabled = p.AllowSandboxDisabled
+	}
+	return &merged
+}
+
+// policyFinding is a violation with the frontmatter path used to locate it
+type policyFinding struct {
+	rule    string
+	path    string // JSON path of the frontmatter field, e.g. /permissions/contents
+	message string
+}
+
+// check returns the violations of the policy by the workflow, without positions
+func (p *WorkflowPolicy) check(data *WorkflowData) []policyFinding {
+	var findings []policyFinding
+	add := func(rule, path, format string, args ...any) {
+		findings = append(findings, policyFinding{rule: rule, path: path, message: "policy: " + fmt.Sprintf(format, args...) + " (" + console.ToRelativePath(p.Source) + ")"})
+	}
+
+	engineID := data.AI
+	if data.EngineConfig != nil && data.EngineConfig.ID != "" {
+		engineID = data.EngineConfig.ID
+	}
+	if engineID == "" {
+		engineID = GetGlobalEngineRegistry().GetDefaultEngine().GetID()
+	}
+	if p.Engines != nil && !slices.Contains(p.Engines, engineID) {
+		add("engines", "/engine", "engine '%s' is not allowed. Allowed engines: %s", engineID, strings.Join(p.Engines, ", "))
+	}
+	if p.Models != nil && data.EngineConfig != nil && data.EngineConfig.Model != "" && !slices.Contains(p.Models, data.EngineConfig.Model) {
+		add("models", "/engine/model", "model '%s' is not allowed. Allowed models: %s", data.EngineConfig.Model, strings.Join(p.Models, ", "))
+	}
+
+	if p.MaxPermissions != nil {
+		permissions := NewPermissionsParser(data.Permissions).ToPermissions()
+		for _, scope := range GetAllPermissionScopes() {
+			level, ok := permissions.Get(scope)
+			if !ok {
+				continue
+			}
+			maxLevel, limited := p.MaxPermissions[string(scope)]
+			if !limited {
+				maxLevel, limited = p.MaxPermissions["all"]
+			}
+			if limited && permissionRank(level) > permissionRank(PermissionLevel(maxLevel)) {
+				add("max-permissions", "/permissions/"+string(scope), "permission '%s: %s' exceeds the maximum '%s: %s'", scope, level, scope, maxLevel)
+			}
+		}
+	}
+
+	if p.Network != nil {
+		allowed := []string{"defaults"}
+		if data.NetworkPermissions != nil {
+			allowed = data.NetworkPermissions.Allowed
+		}
+		for i, entry := range allowed {
+			path := fmt.Sprintf("/network/allowed/%d", i)
+			if len(getEcosystemDomains(entry)) > 0 {
+				if p.Network.Ecosystems != nil && !slices.Contains(p.Network.Ecosystems, entry) {
+					add("network", path, "network ecosystem '%s' is not allowed. Allowed ecosystems: %s", entry, strings.Join(p.Network.Ecosystems, ", "))
+				}
+				continue
+			}
+			if p.Network.Domains != nil && !slices.ContainsFunc(p.Network.Domains, func(pattern string) bool {
+				return pattern == "*" || matchesDomain(entry, pattern)
+			}) {
+				add("network", path, "network domain '%s' is not allowed. Allowed domains: %s", entry, strings.Join(p.Network.Domains, ", "))
+			}
+		}
+	}
+
+	if data.SafeOutputs != nil {
+		if p.RequireThreatDetection != nil && *p.RequireThreatDetection && data.SafeOutputs.ThreatDetection == nil {
+			add("require-threat-detection", "/safe-outputs/threat-detection", "threat detection is required for workflows with safe outputs")
+		}
+		if len(p.ForbiddenSafeOutputs) > 0 {
+			if config := generateSafeOutputsConfig(data); config != "" {
+				var enabled map[string]any
+				if err := json.Unmarshal([]byte(config), &enabled); err == nil {
+					for _, outputType := range sortedMapKeys(enabled) {
+						name := strings.ReplaceAll(outputType, "_", "-")
+						if slices.Contains(p.ForbiddenSafeOutputs, name) {
+							add("forbidden-safe-outputs", "/safe-outputs/"+name, "safe output '%s' is forbidden", name)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if p.Roles != nil {
+		for _, role := range data.Roles {
+			if !slices.Contains(p.Roles, role) {
+				add("roles", "/roles", "role '%s' is not allowed to trigger workflows. Allowed roles: %s", role, strings.Join(p.Roles, ", "))
+			}
+		}
+	}
+
+	if p.AllowSandboxDisabled != nil && !*p.AllowSandboxDisabled && isAgentSandboxDisabled(data) {
+		add("allow-sandbox-disabled", "/sandbox/agent", "'sandbox.agent: false' is not allowed")
+	}
+
+	return findings
+}
+
+// loadPolicy loads the repository's policy file on first use. Workflows are not restricted when
+// the repository has no policy file.
+func (c *Compiler) loadPolicy() error {
+	if c.policyLoaded || c.gitRoot == "" {
+		return nil
+	}
+	path := filepath.Join(c.gitRoot, constants.GetPolicyFilePath())
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		c.policyLoaded = true
+		return nil
+	}
+	policy, err := LoadWorkflowPolicy(path, c.getSharedImportCache())
+	if err != nil {
+		return err
+	}
+	workflowPolicyLog.Printf("Loaded policy from %s", path)
+	c.policy = policy
+	c.policyLoaded = true
+	return nil
+}
+
+// validateWorkflowPolicy checks the workflow against the repository's policy and returns a
+// PolicyViolationError listing all violations
+func (c *Compiler) validateWorkflowPolicy(workflowData *WorkflowData, markdownPath string) error {
+	if err := c.loadPolicy(); err != nil {
+		return formatCompilerError(markdownPath, "error", err.Error(), err)
+	}
+	if c.policy == nil {
+		return nil
+	}
+
+	findings := c.policy.check(workflowData)
+	workflowPolicyLog.Printf("Policy check of %s: %d violations", markdownPath, len(findings))
+	if len(findings) == 0 {
+		return nil
+	}
+
+	var frontmatterContent string
+	var frontmatterStart int
+	var fileLines []string
+	if content, err := os.ReadFile(markdownPath); err == nil {
+		fileLines = strings.Split(string(content), "\n")
+		if result, err := parser.ExtractFrontmatterFromContent(string(content)); err == nil {
+			frontmatterContent = strings.Join(result.FrontmatterLines, "\n")
+			frontmatterStart = result.FrontmatterStart
+		}
+	}
+
+	policyErr := &PolicyViolationError{File: markdownPath}
+	for _, finding := range findings {
+		violation := PolicyViolation{Rule: finding.rule, Message: finding.message, Line: 1, Column: 1}
+		if frontmatterContent != "" {
+			if location := locateFrontmatterPath(frontmatterContent, finding.path); location.Found {
+				violation.Line = location.Line + frontmatterStart - 1
+				violation.Column = location.Column
+			}
+		}
+		var context []string
+		if violation.Line >= 1 && violation.Line <= len(fileLines) {
+			context = []string{fileLines[violation.Line-1]}
+		}
+		policyErr.Violations = append(policyErr.Violations, violation)
+		policyErr.context = append(policyErr.context, context)
+	}
+	return policyErr
+}
+
+// locateFrontmatterPath finds a JSON path in the frontmatter, falling back to its closest
+// parent when the field itself is not written in the file (e.g. it comes from a default)
+func locateFrontmatterPath(frontmatterContent, path string) parser.JSONPathLocation {
+	for path != "" {
+		if location := parser.LocateJSONPathInYAML(frontmatterContent, path); location.Found {
+			return location
+		}
+		path = path[:strings.LastIndex(path, "/")]
+	}
+	return parser.JSONPathLocation{}
+}
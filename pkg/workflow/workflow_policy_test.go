@@ -0,0 +1,137 @@
+//go:build !integration
+
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkflowPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{name: "unknown field", content: "engine: [copilot]\n", errContains: "engine"},
+		{name: "unknown scope", content: "max-permissions:\n  code: read\n", errContains: `unknown permission scope "code"`},
+		{name: "invalid level", content: "max-permissions:\n  contents: admin\n", errContains: "must be read, write or none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWorkflowPolicy([]byte(tt.content), "policy.yml")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "policy.yml")
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestLoadWorkflowPolicyExtends(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".github", "aw")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "org.yml"), []byte("engines: [copilot]\nforbidden-safe-outputs: [create_pull_request]\nrequire-threat-detection: true\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policy.yml"), []byte("extends: org.yml\nengines: [copilot, claude]\n"), 0644))
+
+	policy, err := LoadWorkflowPolicy(filepath.Join(dir, "policy.yml"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"copilot", "claude"}, policy.Engines, "the extending policy should replace engines")
+	assert.Equal(t, []string{"create-pull-request"}, policy.ForbiddenSafeOutputs, "fields from the extended policy should be kept")
+	require.NotNil(t, policy.RequireThreatDetection)
+	assert.True(t, *policy.RequireThreatDetection)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "org.yml"), []byte("extends: policy.yml\n"), 0644))
+	_, err = LoadWorkflowPolicy(filepath.Join(dir, "policy.yml"), nil)
+	assert.ErrorContains(t, err, "extends itself")
+}
+
+func TestCompileWorkflowWithPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowsDir := filepath.Join(tmpDir, ".github", "workflows")
+	policyDir := filepath.Join(tmpDir, ".github", "aw")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	require.NoError(t, os.MkdirAll(policyDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "policy.yml"), []byte(`engines: [copilot, claude]
+models: [gpt-5]
+max-permissions:
+  all: read
+  issues: none
+network:
+  ecosystems: [defaults]
+  domains: ["*.example.com"]
+require-threat-detection: true
+forbidden-safe-outputs: [create-pull-request]
+roles: [admin, maintainer]
+allow-sandbox-disabled: false
+`), 0644))
+
+	compliant := filepath.Join(workflowsDir, "compliant.md")
+	require.NoError(t, os.WriteFile(compliant, []byte(`---
+on: workflow_dispatch
+engine:
+  id: copilot
+  model: gpt-5
+permissions:
+  contents: read
+network:
+  allowed:
+    - defaults
+    - api.example.com
+roles: [admin]
+safe-outputs:
+  add-comment:
+---
+
+# Compliant
+`), 0644))
+
+	violating := filepath.Join(workflowsDir, "violating.md")
+	require.NoError(t, os.WriteFile(violating, []byte(`---
+on: workflow_dispatch
+engine: codex
+permissions:
+  contents: read
+  issues: read
+network:
+  allowed:
+    - defaults
+    - python
+    - evil.com
+roles: [admin, write]
+safe-outputs:
+  threat-detection: false
+  create-pull-request:
+---
+
+# Violating
+`), 0644))
+
+	compiler := NewCompiler(WithGitRoot(tmpDir))
+	require.NoError(t, compiler.CompileWorkflow(compliant), "a workflow that satisfies the policy should compile")
+
+	err := compiler.CompileWorkflow(violating)
+	require.Error(t, err)
+	var policyErr *PolicyViolationError
+	require.True(t, errors.As(err, &policyErr), "violations should be reported as a PolicyViolationError")
+
+	lines := make(map[string][]int)
+	for _, violation := range policyErr.Violations {
+		lines[violation.Rule] = append(lines[violation.Rule], violation.Line)
+	}
+	assert.Equal(t, map[string][]int{
+		"engines":                  {3},
+		"max-permissions":          {6},
+		"network":                  {10, 11},
+		"roles":                    {12},
+		"require-threat-detection": {14},
+		"forbidden-safe-outputs":   {15},
+	}, lines)
+	assert.Contains(t, err.Error(), "violating.md:3:")
+	assert.Contains(t, err.Error(), "policy: engine 'codex' is not allowed")
+}